@@ -2,17 +2,22 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/tools/cache"
@@ -22,14 +27,18 @@ import (
 	"k8s.io/client-go/transport"
 	_ "k8s.io/code-generator/cmd/client-gen/generators"
 
+	"github.com/weaveworks/flagger/pkg/backup"
 	"github.com/weaveworks/flagger/pkg/canary"
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 	informers "github.com/weaveworks/flagger/pkg/client/informers/externalversions"
+	flaggerinformers "github.com/weaveworks/flagger/pkg/client/informers/externalversions/flagger/v1beta1"
 	"github.com/weaveworks/flagger/pkg/controller"
 	"github.com/weaveworks/flagger/pkg/logger"
 	"github.com/weaveworks/flagger/pkg/metrics/observers"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
 	"github.com/weaveworks/flagger/pkg/notifier"
 	"github.com/weaveworks/flagger/pkg/router"
+	"github.com/weaveworks/flagger/pkg/secrets"
 	"github.com/weaveworks/flagger/pkg/server"
 	"github.com/weaveworks/flagger/pkg/signals"
 	"github.com/weaveworks/flagger/pkg/version"
@@ -39,9 +48,16 @@ var (
 	masterURL                string
 	kubeconfig               string
 	metricsServer            string
+	metricsServerTokenFile   string
+	providerTimeout          time.Duration
+	providerIdleConnTimeout  time.Duration
+	providerMaxIdleConns     int
+	metricsTokenDir          string
+	providerHealthInterval   time.Duration
 	controlLoopInterval      time.Duration
 	logLevel                 string
 	port                     string
+	grpcPort                 string
 	msteamsURL               string
 	slackURL                 string
 	slackUser                string
@@ -57,17 +73,39 @@ var (
 	enableLeaderElection     bool
 	leaderElectionNamespace  string
 	enableConfigTracking     bool
+	enableMonitorCloning     bool
+	aesGCMKeyFile            string
+	decryptionCommand        string
+	vaultAddress             string
+	vaultAuthMount           string
+	vaultRolePrefix          string
 	ver                      bool
 	kubeconfigServiceMesh    string
+	conformanceCanary        string
+	bundleCanary             string
+	bundleFile               string
+	stateStore               string
+	stateCheckpointInterval  time.Duration
+	policyMetadataPrefixes   string
+	policyExemptionLabels    string
+	analysisJitter           float64
+	analysisSpread           bool
 )
 
 func init() {
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
 	flag.StringVar(&masterURL, "master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	flag.StringVar(&metricsServer, "metrics-server", "http://prometheus:9090", "Prometheus URL.")
+	flag.StringVar(&metricsServerTokenFile, "metrics-server-token-file", "", "Path to a bearer token file used to authenticate to the Prometheus server, the token is reloaded when the file changes.")
+	flag.DurationVar(&providerTimeout, "provider-timeout", providers.DefaultClientOptions.Timeout, "Timeout of the metric provider requests.")
+	flag.DurationVar(&providerIdleConnTimeout, "provider-idle-conn-timeout", providers.DefaultClientOptions.IdleConnTimeout, "Duration of the idle metric provider connections in the pool.")
+	flag.IntVar(&providerMaxIdleConns, "provider-max-idle-conns", providers.DefaultClientOptions.MaxIdleConnsPerHost, "Maximum number of idle connections kept in the pool per metric provider address.")
+	flag.StringVar(&metricsTokenDir, "metrics-token-dir", "", "Directory of the bearer token files that the metric templates can reference with tokenFile, the files are read on every request.")
+	flag.DurationVar(&providerHealthInterval, "provider-health-check-interval", time.Minute, "Interval of the metric providers health checks, disabled if zero.")
 	flag.DurationVar(&controlLoopInterval, "control-loop-interval", 10*time.Second, "Kubernetes API sync interval.")
 	flag.StringVar(&logLevel, "log-level", "debug", "Log level can be: debug, info, warning, error.")
 	flag.StringVar(&port, "port", "8080", "Port to listen on.")
+	flag.StringVar(&grpcPort, "grpc-port", "", "Port to serve the read-only canaries gRPC API on, disabled if empty.")
 	flag.StringVar(&slackURL, "slack-url", "", "Slack hook URL.")
 	flag.StringVar(&slackUser, "slack-user", "flagger", "Slack user name.")
 	flag.StringVar(&slackChannel, "slack-channel", "", "Slack channel.")
@@ -77,19 +115,41 @@ func init() {
 	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
 	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
 	flag.StringVar(&namespace, "namespace", "", "Namespace that flagger would watch canary object.")
-	flag.StringVar(&meshProvider, "mesh-provider", "istio", "Service mesh provider, can be istio, linkerd, appmesh, supergloo, nginx or smi.")
+	flag.StringVar(&meshProvider, "mesh-provider", "istio", "Service mesh provider, can be istio, linkerd, appmesh, osm, kuma, supergloo, nginx, haproxy, alb, kong, ambassador, apisix or smi, the SMI traffic split version can be set with linkerd:v1alpha2 or smi:v1alpha3.")
 	flag.StringVar(&selectorLabels, "selector-labels", "app,name,app.kubernetes.io/name", "List of pod labels that Flagger uses to create pod selectors.")
 	flag.StringVar(&ingressAnnotationsPrefix, "ingress-annotations-prefix", "nginx.ingress.kubernetes.io", "Annotations prefix for ingresses.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false, "Enable leader election.")
 	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "kube-system", "Namespace used to create the leader election config map.")
 	flag.BoolVar(&enableConfigTracking, "enable-config-tracking", true, "Enable secrets and configmaps tracking.")
+	flag.BoolVar(&enableMonitorCloning, "enable-monitor-cloning", false, "Clone the Prometheus Operator ServiceMonitors and PodMonitors for the primary and canary workloads.")
+	flag.StringVar(&aesGCMKeyFile, "secrets-aes-gcm-key-file", "", "Path to the AES-256 key used to decrypt the ENC[AES256_GCM,...] values of the provider credentials, SOPS encrypted values are not supported.")
+	flag.StringVar(&decryptionCommand, "secrets-decryption-command", "", "Command used to decrypt the ENC[...] values of the provider credentials, the value is passed on stdin and the plaintext is read from stdout.")
+	flag.StringVar(&vaultAddress, "vault-address", os.Getenv("VAULT_ADDR"), "Vault server address used to read the metric templates provider credentials.")
+	flag.StringVar(&vaultAuthMount, "vault-auth-mount", secrets.VaultDefaultAuthMount, "Mount path of the Vault Kubernetes auth method.")
+	flag.StringVar(&vaultRolePrefix, "vault-role-prefix", secrets.VaultDefaultRolePrefix, "Prefix of the Vault roles, the metric templates provider credentials are read with the role named after the template namespace.")
+	flag.StringVar(&stateStore, "state-store", "status", "Store of the failed checks, iterations and promotion ETA updated at every analysis run, can be status, memory or configmap, the canary status is updated on phase transitions when a store other than status is used.")
+	flag.DurationVar(&stateCheckpointInterval, "state-checkpoint-interval", 5*time.Minute, "Min interval between the checkpoints of the memory state store to the canary status, used to resume the analysis after a restart, disabled if zero.")
+	flag.StringVar(&policyMetadataPrefixes, "policy-metadata-prefixes", "pod-security.kubernetes.io/,admission.gatekeeper.sh/", "List of label and annotation prefixes kept in sync from the target to the primary workload for the admission policies.")
+	flag.StringVar(&policyExemptionLabels, "policy-exemption-labels", "", "List of key=value labels set on the workloads and pods generated by Flagger, used to exempt them from the admission policy constraints.")
+	flag.Float64Var(&analysisJitter, "analysis-jitter", 0, "Max random delay of the analysis runs as a fraction of the canary interval, between 0 and 1.")
+	flag.BoolVar(&analysisSpread, "analysis-spread", false, "Spread the analysis runs of the canaries with the same interval by aligning each canary to a slot of the interval derived from its name.")
 	flag.BoolVar(&ver, "version", false, "Print version")
 	flag.StringVar(&kubeconfigServiceMesh, "kubeconfig-service-mesh", "", "Path to a kubeconfig for the service mesh control plane cluster.")
+	flag.StringVar(&conformanceCanary, "conformance-canary", "", "Canary in the <name>.<namespace> format used by the router-conformance command.")
+	flag.StringVar(&bundleCanary, "bundle-canary", "", "Canary in the <name>.<namespace> format used by the export command.")
+	flag.StringVar(&bundleFile, "bundle-file", "", "Path to the manifest bundle written by the export command or read by the import command, export writes to stdout if empty.")
 }
 
 func main() {
 	flag.Parse()
 
+	// the router-conformance, export and import command flags are parsed after the command name
+	command := flag.Arg(0)
+	switch command {
+	case "router-conformance", "export", "import":
+		flag.CommandLine.Parse(flag.Args()[1:])
+	}
+
 	if ver {
 		fmt.Println("Flagger version", version.VERSION, "revision ", version.REVISION)
 		os.Exit(0)
@@ -135,20 +195,48 @@ func main() {
 		logger.Fatalf("Error building mesh clientset: %v", err)
 	}
 
-	verifyCRDs(flaggerClient, logger)
+	switch command {
+	case "router-conformance":
+		routerFactory := router.NewFactory(cfg, kubeClient, flaggerClient, ingressAnnotationsPrefix, logger, meshClient)
+		code := runRouterConformance(flaggerClient, routerFactory, logger)
+		logger.Sync()
+		os.Exit(code)
+	case "export":
+		code := runExport(kubeClient, flaggerClient, logger)
+		logger.Sync()
+		os.Exit(code)
+	case "import":
+		code := runImport(kubeClient, flaggerClient, logger)
+		logger.Sync()
+		os.Exit(code)
+	}
+
+	crds := verifyCRDs(flaggerClient, logger)
 	verifyKubernetesVersion(kubeClient, logger)
-	infos := startInformers(flaggerClient, logger, stopCh)
+	infos := startInformers(flaggerClient, crds, logger, stopCh)
 
 	labels := strings.Split(selectorLabels, ",")
 	if len(labels) < 1 {
 		logger.Fatalf("At least one selector label is required")
 	}
 
+	if analysisJitter < 0 || analysisJitter >= 1 {
+		logger.Fatalf("The analysis jitter must be a fraction of the interval between 0 and 1")
+	}
+
 	if namespace != "" {
 		logger.Infof("Watching namespace %s", namespace)
 	}
 
-	observerFactory, err := observers.NewFactory(metricsServer)
+	providers.ConfigureClient(providers.ClientOptions{
+		Timeout:             providerTimeout,
+		DialTimeout:         providers.DefaultClientOptions.DialTimeout,
+		IdleConnTimeout:     providerIdleConnTimeout,
+		MaxIdleConnsPerHost: providerMaxIdleConns,
+		TokenDir:            metricsTokenDir,
+	})
+
+	observerFactory, err := observers.NewFactory(metricsServer, metricsServerTokenFile)
 	if err != nil {
 		logger.Fatalf("Error building prometheus client: %s", err.Error())
 	}
@@ -160,6 +248,8 @@ func main() {
 		logger.Errorf("Metrics server %s unreachable %v", metricsServer, err)
 	}
 
+	decryptor := initDecryptor(logger)
+
 	// setup Slack or MS Teams notifications
 	notifierClient := initNotifier(logger)
 
@@ -179,7 +269,21 @@ func main() {
 		configTracker = &canary.NopTracker{}
 	}
 
-	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, labels, logger)
+	analysisStore, err := canary.NewStateStore(stateStore, kubeClient, flaggerClient, stateCheckpointInterval)
+	if err != nil {
+		logger.Fatalf("Error building the state store: %v", err)
+	}
+
+	exemptionLabels, err := canary.ParseExemptionLabels(policyExemptionLabels)
+	if err != nil {
+		logger.Fatalf("Error parsing the policy exemption labels: %v", err)
+	}
+	policy := canary.PolicyMetadata{
+		Prefixes:        strings.Split(policyMetadataPrefixes, ","),
+		ExemptionLabels: exemptionLabels,
+	}
+
+	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, analysisStore, labels, policy, logger)
 
 	c := controller.NewController(
 		kubeClient,
@@ -195,8 +299,26 @@ func main() {
 		meshProvider,
 		version.VERSION,
 		fromEnv("EVENT_WEBHOOK_URL", eventWebhook),
+		enableMonitorCloning,
+		decryptor,
+		secrets.NewVaultClient(vaultAddress, vaultAuthMount, vaultRolePrefix, providerTimeout),
+		providerHealthInterval,
+		analysisJitter,
+		analysisSpread,
 	)
 
+	startRouteInformers(c, kubeClient, meshClient, logger, stopCh)
+
+	// serve the canary and primary time series on the HTTP port
+	http.Handle("/timeseries", server.TimeSeriesHandler(infos.CanaryInformer.Lister(), c))
+	http.Handle("/explain", server.ExplainHandler(infos.CanaryInformer.Lister(), c))
+
+	// start gRPC server
+	if grpcPort != "" {
+		canariesServer := server.NewCanariesServer(infos.CanaryInformer.Lister(), c)
+		go server.ListenAndServeGRPC(grpcPort, canariesServer, logger, stopCh)
+	}
+
 	// leader election context
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -229,7 +351,7 @@ func main() {
 	}
 }
 
-func startInformers(flaggerClient clientset.Interface, logger *zap.SugaredLogger, stopCh <-chan struct{}) controller.Informers {
+func startInformers(flaggerClient clientset.Interface, crds optionalCRDs, logger *zap.SugaredLogger, stopCh <-chan struct{}) controller.Informers {
 	flaggerInformerFactory := informers.NewSharedInformerFactoryWithOptions(flaggerClient, time.Second*30, informers.WithNamespace(namespace))
 
 	logger.Info("Waiting for canary informer cache to sync")
@@ -253,13 +375,68 @@ func startInformers(flaggerClient clientset.Interface, logger *zap.SugaredLogger
 		logger.Fatalf("failed to wait for cache to sync")
 	}
 
+	var classInformer flaggerinformers.CanaryClassInformer
+	if crds.canaryClasses {
+		logger.Info("Waiting for canary class informer cache to sync")
+		classInformer = flaggerInformerFactory.Flagger().V1beta1().CanaryClasses()
+		go classInformer.Informer().Run(stopCh)
+		if ok := cache.WaitForNamedCacheSync("flagger", stopCh, classInformer.Informer().HasSynced); !ok {
+			logger.Fatalf("failed to wait for cache to sync")
+		}
+	}
+
+	var routeInformer flaggerinformers.AlertRouteInformer
+	if crds.alertRoutes {
+		logger.Info("Waiting for alert route informer cache to sync")
+		routeInformer = flaggerInformerFactory.Flagger().V1beta1().AlertRoutes()
+		go routeInformer.Informer().Run(stopCh)
+		if ok := cache.WaitForNamedCacheSync("flagger", stopCh, routeInformer.Informer().HasSynced); !ok {
+			logger.Fatalf("failed to wait for cache to sync")
+		}
+	}
+
 	return controller.Informers{
 		CanaryInformer: canaryInformer,
 		MetricInformer: metricInformer,
 		AlertInformer:  alertInformer,
+		ClassInformer:  classInformer,
+		RouteInformer:  routeInformer,
 	}
 }
 
+// startRouteInformers watches the router objects of the mesh provider so that the routes changed outside
+// of Flagger are restored right away, the routes of the other providers are checked every control loop interval
+func startRouteInformers(c *controller.Controller, kubeClient kubernetes.Interface, meshClient clientset.Interface,
+	logger *zap.SugaredLogger, stopCh <-chan struct{}) {
+	meshInformerFactory := informers.NewSharedInformerFactoryWithOptions(meshClient, time.Second*30, informers.WithNamespace(namespace))
+
+	var informer cache.SharedIndexInformer
+	switch {
+	case meshProvider == "istio":
+		informer = meshInformerFactory.Networking().V1alpha3().VirtualServices().Informer()
+	case meshProvider == "osm":
+		informer = meshInformerFactory.Split().V1alpha3().TrafficSplits().Informer()
+	case meshProvider == "linkerd" || strings.HasPrefix(meshProvider, "linkerd:") || strings.HasPrefix(meshProvider, "smi:"):
+		switch {
+		case strings.HasSuffix(meshProvider, ":v1alpha2"):
+			informer = meshInformerFactory.Split().V1alpha2().TrafficSplits().Informer()
+		case strings.HasSuffix(meshProvider, ":v1alpha3"):
+			informer = meshInformerFactory.Split().V1alpha3().TrafficSplits().Informer()
+		default:
+			informer = meshInformerFactory.Split().V1alpha1().TrafficSplits().Informer()
+		}
+	case meshProvider == "nginx" || meshProvider == "haproxy":
+		kubeInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, time.Second*30, kubeinformers.WithNamespace(namespace))
+		informer = kubeInformerFactory.Extensions().V1beta1().Ingresses().Informer()
+	default:
+		return
+	}
+
+	logger.Infof("Watching the %s router objects", meshProvider)
+	c.WatchRoutes(meshProvider, informer)
+	go informer.Run(stopCh)
+}
+
 func startLeaderElection(ctx context.Context, run func(), ns string, kubeClient kubernetes.Interface, logger *zap.SugaredLogger) {
 	configMapName := "flagger-leader-election"
 	id, err := os.Hostname()
@@ -328,6 +505,32 @@ func initNotifier(logger *zap.SugaredLogger) (client notifier.Interface) {
 	return
 }
 
+func initDecryptor(logger *zap.SugaredLogger) secrets.Decryptor {
+	if aesGCMKeyFile != "" && decryptionCommand != "" {
+		logger.Fatalf("The secrets AES-GCM key file and decryption command are mutually exclusive")
+	}
+
+	if aesGCMKeyFile != "" {
+		decryptor, err := secrets.NewAESGCMDecryptor(aesGCMKeyFile)
+		if err != nil {
+			logger.Fatalf("Error loading secrets decryption key: %s", err.Error())
+		}
+		logger.Infof("Secrets decryption enabled with key %s", aesGCMKeyFile)
+		return decryptor
+	}
+
+	if decryptionCommand != "" {
+		decryptor, err := secrets.NewExecDecryptor(decryptionCommand, providerTimeout)
+		if err != nil {
+			logger.Fatalf("Error configuring secrets decryption: %s", err.Error())
+		}
+		logger.Infof("Secrets decryption enabled with command %s", strings.Fields(decryptionCommand)[0])
+		return decryptor
+	}
+
+	return nil
+}
+
 func fromEnv(envVar string, defaultVal string) string {
 	if os.Getenv(envVar) != "" {
 		return os.Getenv(envVar)
@@ -335,7 +538,113 @@ func fromEnv(envVar string, defaultVal string) string {
 	return defaultVal
 }
 
-func verifyCRDs(flaggerClient clientset.Interface, logger *zap.SugaredLogger) {
+// runRouterConformance runs the router conformance checks against the canary
+// and prints the report, the exit code is non-zero if a check failed
+func runRouterConformance(flaggerClient clientset.Interface, routerFactory *router.Factory, logger *zap.SugaredLogger) int {
+	parts := strings.SplitN(conformanceCanary, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		logger.Errorf("The router-conformance command requires -conformance-canary=<name>.<namespace>")
+		return 2
+	}
+
+	cd, err := flaggerClient.FlaggerV1beta1().Canaries(parts[1]).Get(parts[0], metav1.GetOptions{})
+	if err != nil {
+		logger.Errorf("Canary %s query error %v", conformanceCanary, err)
+		return 2
+	}
+
+	provider := meshProvider
+	if cd.Spec.Provider != "" {
+		provider = cd.Spec.Provider
+	}
+
+	fmt.Printf("Router conformance for provider %s canary %s\n", provider, conformanceCanary)
+	code := 0
+	for _, result := range router.RunConformance(routerFactory.MeshRouter(provider), cd) {
+		switch {
+		case result.Passed:
+			fmt.Printf("PASS %s\n", result.Check)
+		case result.Skipped:
+			fmt.Printf("SKIP %s: %s\n", result.Check, result.Message)
+		default:
+			code = 1
+			fmt.Printf("FAIL %s: %s\n", result.Check, result.Message)
+		}
+	}
+	return code
+}
+
+// runExport writes the manifest bundle of the canary and its generated objects
+func runExport(kubeClient kubernetes.Interface, flaggerClient clientset.Interface, logger *zap.SugaredLogger) int {
+	parts := strings.SplitN(bundleCanary, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		logger.Errorf("The export command requires -bundle-canary=<name>.<namespace>")
+		return 2
+	}
+
+	list, err := backup.Export(kubeClient, flaggerClient, parts[0], parts[1])
+	if err != nil {
+		logger.Errorf("Export failed: %v", err)
+		return 1
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		logger.Errorf("Bundle encoding error %v", err)
+		return 1
+	}
+
+	if bundleFile == "" {
+		fmt.Println(string(data))
+		return 0
+	}
+	if err := ioutil.WriteFile(bundleFile, data, 0600); err != nil {
+		logger.Errorf("Bundle write error %v", err)
+		return 1
+	}
+	logger.Infof("Canary %s exported to %s", bundleCanary, bundleFile)
+	return 0
+}
+
+// runImport restores the canary and its generated objects from a manifest bundle
+func runImport(kubeClient kubernetes.Interface, flaggerClient clientset.Interface, logger *zap.SugaredLogger) int {
+	if bundleFile == "" {
+		logger.Errorf("The import command requires -bundle-file=<path>")
+		return 2
+	}
+
+	data, err := ioutil.ReadFile(bundleFile)
+	if err != nil {
+		logger.Errorf("Bundle read error %v", err)
+		return 1
+	}
+
+	list := &corev1.List{}
+	if err := json.Unmarshal(data, list); err != nil {
+		logger.Errorf("Bundle decoding error %v", err)
+		return 1
+	}
+
+	cd, err := backup.Import(kubeClient, flaggerClient, list)
+	if err != nil {
+		logger.Errorf("Import failed: %v", err)
+		return 1
+	}
+	logger.Infof("Canary %s.%s imported with phase %s weight %v",
+		cd.Name, cd.Namespace, cd.Status.Phase, cd.Status.CanaryWeight)
+	return 0
+}
+
+// optionalCRDs holds the availability of the CRDs added after the first releases,
+// the features that depend on them are disabled until the CRDs are applied and Flagger is restarted
+type optionalCRDs struct {
+	canaryClasses bool
+	alertRoutes   bool
+}
+
+func verifyCRDs(flaggerClient clientset.Interface, logger *zap.SugaredLogger) optionalCRDs {
+	crds := optionalCRDs{canaryClasses: true, alertRoutes: true}
+
 	_, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).List(metav1.ListOptions{Limit: 1})
 	if err != nil {
 		logger.Fatalf("Canary CRD is not registered %v", err)
@@ -350,6 +659,19 @@ func verifyCRDs(flaggerClient clientset.Interface, logger *zap.SugaredLogger) {
 	if err != nil {
 		logger.Fatalf("AlertProvider CRD is not registered %v", err)
 	}
+
+	_, err = flaggerClient.FlaggerV1beta1().CanaryClasses().List(metav1.ListOptions{Limit: 1})
+	if err != nil {
+		logger.Warnf("CanaryClass CRD is not registered, the canary classes are disabled %v", err)
+		crds.canaryClasses = false
+	}
+
+	_, err = flaggerClient.FlaggerV1beta1().AlertRoutes().List(metav1.ListOptions{Limit: 1})
+	if err != nil {
+		logger.Warnf("AlertRoute CRD is not registered, the alert routes are disabled %v", err)
+		crds.alertRoutes = false
+	}
+	return crds
 }
 
 func verifyKubernetesVersion(kubeClient kubernetes.Interface, logger *zap.SugaredLogger) {
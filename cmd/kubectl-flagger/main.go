@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/weaveworks/flagger/pkg/cli"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+const usage = `kubectl flagger controls the canary analysis run by Flagger.
+
+Usage:
+  kubectl flagger status [canary] [-n namespace]
+  kubectl flagger pause <canary> [-n namespace]
+  kubectl flagger resume <canary> [-n namespace]
+  kubectl flagger promote <canary> [-n namespace]
+  kubectl flagger rollback <canary> [-n namespace]
+
+Commands:
+  status    print the analysis status of a canary or of all the canaries in the namespace
+  pause     hold the canary at its current weight by setting spec.suspend
+  resume    continue the analysis of a paused canary
+  promote   promote the canary revision under analysis without finishing the analysis
+  rollback  roll back the canary revision under analysis
+
+Flags:
+`
+
+func main() {
+	fs := flag.NewFlagSet("kubectl-flagger", flag.ExitOnError)
+	kubeconfig := fs.String("kubeconfig", "", "Path to a kubeconfig, defaults to the kubectl configuration.")
+	namespace := fs.String("namespace", "", "Namespace of the canary, defaults to the kubeconfig context namespace.")
+	fs.StringVar(namespace, "n", "", "Shorthand for -namespace.")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	// the flags can be set before or after the command and canary name
+	var args []string
+	rest := os.Args[1:]
+	for {
+		fs.Parse(rest)
+		if fs.NArg() == 0 {
+			break
+		}
+		args = append(args, fs.Arg(0))
+		rest = fs.Args()[1:]
+	}
+	if len(args) == 0 || len(args) > 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	command, name := args[0], ""
+	if len(args) == 2 {
+		name = args[1]
+	}
+	if name == "" && command != "status" {
+		fmt.Fprintf(os.Stderr, "the %s command requires a canary name\n", command)
+		os.Exit(2)
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = *kubeconfig
+	overrides := &clientcmd.ConfigOverrides{}
+	overrides.Context.Namespace = *namespace
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	cfg, err := clientConfig.ClientConfig()
+	if err != nil {
+		fatalf("Error building kubeconfig: %v", err)
+	}
+	ns, _, err := clientConfig.Namespace()
+	if err != nil {
+		fatalf("Error reading the namespace: %v", err)
+	}
+	flaggerClient, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		fatalf("Error building flagger clientset: %v", err)
+	}
+
+	switch command {
+	case "status":
+		err = cli.Status(flaggerClient, name, ns, os.Stdout)
+	case "pause":
+		err = cli.Pause(flaggerClient, name, ns)
+	case "resume":
+		err = cli.Resume(flaggerClient, name, ns)
+	case "promote":
+		err = cli.Promote(flaggerClient, name, ns)
+	case "rollback":
+		err = cli.Rollback(flaggerClient, name, ns)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	if command != "status" {
+		fmt.Printf("canary %s.%s %s requested\n", name, ns, command)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
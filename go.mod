@@ -6,6 +6,7 @@ require (
 	github.com/Masterminds/semver/v3 v3.0.3
 	github.com/davecgh/go-spew v1.1.1
 	github.com/golang/groupcache v0.0.0-20190129154638-5b532d6fd5ef // indirect
+	github.com/golang/protobuf v1.3.2
 	github.com/google/go-cmp v0.3.0
 	github.com/googleapis/gnostic v0.2.0 // indirect
 	github.com/imdario/mergo v0.3.7 // indirect
@@ -14,6 +15,7 @@ require (
 	go.uber.org/atomic v1.3.2 // indirect
 	go.uber.org/multierr v1.1.0 // indirect
 	go.uber.org/zap v1.10.0
+	google.golang.org/grpc v1.27.1
 	gopkg.in/h2non/gock.v1 v1.0.14
 	k8s.io/api v0.17.2
 	k8s.io/apimachinery v0.17.2
@@ -0,0 +1,502 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: flagger.proto
+
+package v1
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type ListRequest struct {
+	// Namespace to list canaries from, all namespaces if empty
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListRequest) Reset()         { *m = ListRequest{} }
+func (m *ListRequest) String() string { return proto.CompactTextString(m) }
+func (*ListRequest) ProtoMessage()    {}
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6aab1b0615090602, []int{0}
+}
+
+func (m *ListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRequest.Unmarshal(m, b)
+}
+func (m *ListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRequest.Marshal(b, m, deterministic)
+}
+func (m *ListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRequest.Merge(m, src)
+}
+func (m *ListRequest) XXX_Size() int {
+	return xxx_messageInfo_ListRequest.Size(m)
+}
+func (m *ListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRequest proto.InternalMessageInfo
+
+func (m *ListRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type ListResponse struct {
+	Canaries             []*Canary `protobuf:"bytes,1,rep,name=canaries,proto3" json:"canaries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ListResponse) Reset()         { *m = ListResponse{} }
+func (m *ListResponse) String() string { return proto.CompactTextString(m) }
+func (*ListResponse) ProtoMessage()    {}
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6aab1b0615090602, []int{1}
+}
+
+func (m *ListResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListResponse.Unmarshal(m, b)
+}
+func (m *ListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListResponse.Marshal(b, m, deterministic)
+}
+func (m *ListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListResponse.Merge(m, src)
+}
+func (m *ListResponse) XXX_Size() int {
+	return xxx_messageInfo_ListResponse.Size(m)
+}
+func (m *ListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListResponse proto.InternalMessageInfo
+
+func (m *ListResponse) GetCanaries() []*Canary {
+	if m != nil {
+		return m.Canaries
+	}
+	return nil
+}
+
+type GetRequest struct {
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetRequest) Reset()         { *m = GetRequest{} }
+func (m *GetRequest) String() string { return proto.CompactTextString(m) }
+func (*GetRequest) ProtoMessage()    {}
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6aab1b0615090602, []int{2}
+}
+
+func (m *GetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetRequest.Unmarshal(m, b)
+}
+func (m *GetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetRequest.Marshal(b, m, deterministic)
+}
+func (m *GetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetRequest.Merge(m, src)
+}
+func (m *GetRequest) XXX_Size() int {
+	return xxx_messageInfo_GetRequest.Size(m)
+}
+func (m *GetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetRequest proto.InternalMessageInfo
+
+func (m *GetRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *GetRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type Canary struct {
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Kind of the target workload e.g. Deployment
+	TargetKind string `protobuf:"bytes,3,opt,name=target_kind,json=targetKind,proto3" json:"target_kind,omitempty"`
+	// Name of the target workload
+	TargetName string `protobuf:"bytes,4,opt,name=target_name,json=targetName,proto3" json:"target_name,omitempty"`
+	// Analysis phase e.g. Progressing
+	Phase string `protobuf:"bytes,5,opt,name=phase,proto3" json:"phase,omitempty"`
+	// Traffic percentage routed to the canary
+	CanaryWeight int32 `protobuf:"varint,6,opt,name=canary_weight,json=canaryWeight,proto3" json:"canary_weight,omitempty"`
+	FailedChecks int32 `protobuf:"varint,7,opt,name=failed_checks,json=failedChecks,proto3" json:"failed_checks,omitempty"`
+	Iterations   int32 `protobuf:"varint,8,opt,name=iterations,proto3" json:"iterations,omitempty"`
+	// Unix timestamp in seconds of the last phase transition
+	LastTransitionTime int64 `protobuf:"varint,9,opt,name=last_transition_time,json=lastTransitionTime,proto3" json:"last_transition_time,omitempty"`
+	// Results of the last metric checks run for this canary
+	Metrics              []*MetricResult `protobuf:"bytes,10,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Canary) Reset()         { *m = Canary{} }
+func (m *Canary) String() string { return proto.CompactTextString(m) }
+func (*Canary) ProtoMessage()    {}
+func (*Canary) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6aab1b0615090602, []int{3}
+}
+
+func (m *Canary) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Canary.Unmarshal(m, b)
+}
+func (m *Canary) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Canary.Marshal(b, m, deterministic)
+}
+func (m *Canary) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Canary.Merge(m, src)
+}
+func (m *Canary) XXX_Size() int {
+	return xxx_messageInfo_Canary.Size(m)
+}
+func (m *Canary) XXX_DiscardUnknown() {
+	xxx_messageInfo_Canary.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Canary proto.InternalMessageInfo
+
+func (m *Canary) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Canary) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *Canary) GetTargetKind() string {
+	if m != nil {
+		return m.TargetKind
+	}
+	return ""
+}
+
+func (m *Canary) GetTargetName() string {
+	if m != nil {
+		return m.TargetName
+	}
+	return ""
+}
+
+func (m *Canary) GetPhase() string {
+	if m != nil {
+		return m.Phase
+	}
+	return ""
+}
+
+func (m *Canary) GetCanaryWeight() int32 {
+	if m != nil {
+		return m.CanaryWeight
+	}
+	return 0
+}
+
+func (m *Canary) GetFailedChecks() int32 {
+	if m != nil {
+		return m.FailedChecks
+	}
+	return 0
+}
+
+func (m *Canary) GetIterations() int32 {
+	if m != nil {
+		return m.Iterations
+	}
+	return 0
+}
+
+func (m *Canary) GetLastTransitionTime() int64 {
+	if m != nil {
+		return m.LastTransitionTime
+	}
+	return 0
+}
+
+func (m *Canary) GetMetrics() []*MetricResult {
+	if m != nil {
+		return m.Metrics
+	}
+	return nil
+}
+
+type MetricResult struct {
+	Name   string  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value  float64 `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	Passed bool    `protobuf:"varint,3,opt,name=passed,proto3" json:"passed,omitempty"`
+	// Error returned by the metrics provider if the query failed
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// Unix timestamp in seconds of the check
+	Timestamp            int64    `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MetricResult) Reset()         { *m = MetricResult{} }
+func (m *MetricResult) String() string { return proto.CompactTextString(m) }
+func (*MetricResult) ProtoMessage()    {}
+func (*MetricResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6aab1b0615090602, []int{4}
+}
+
+func (m *MetricResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MetricResult.Unmarshal(m, b)
+}
+func (m *MetricResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MetricResult.Marshal(b, m, deterministic)
+}
+func (m *MetricResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetricResult.Merge(m, src)
+}
+func (m *MetricResult) XXX_Size() int {
+	return xxx_messageInfo_MetricResult.Size(m)
+}
+func (m *MetricResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetricResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetricResult proto.InternalMessageInfo
+
+func (m *MetricResult) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *MetricResult) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *MetricResult) GetPassed() bool {
+	if m != nil {
+		return m.Passed
+	}
+	return false
+}
+
+func (m *MetricResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *MetricResult) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*ListRequest)(nil), "flagger.v1.ListRequest")
+	proto.RegisterType((*ListResponse)(nil), "flagger.v1.ListResponse")
+	proto.RegisterType((*GetRequest)(nil), "flagger.v1.GetRequest")
+	proto.RegisterType((*Canary)(nil), "flagger.v1.Canary")
+	proto.RegisterType((*MetricResult)(nil), "flagger.v1.MetricResult")
+}
+
+func init() { proto.RegisterFile("flagger.proto", fileDescriptor_6aab1b0615090602) }
+
+var fileDescriptor_6aab1b0615090602 = []byte{
+	// 432 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x93, 0x4f, 0x6b, 0xdb, 0x40,
+	0x10, 0xc5, 0x23, 0xcb, 0x76, 0xec, 0xb1, 0x73, 0x19, 0x4c, 0xba, 0x84, 0xd2, 0x1a, 0xf5, 0x62,
+	0x28, 0x98, 0xc6, 0x39, 0x16, 0x72, 0xa8, 0x0f, 0x39, 0xf4, 0xcf, 0x61, 0x09, 0x14, 0x7a, 0x11,
+	0x5b, 0x79, 0x22, 0x2f, 0xd1, 0xbf, 0xee, 0xac, 0x5d, 0x02, 0x3d, 0xf6, 0x93, 0xf6, 0x93, 0x94,
+	0xdd, 0x75, 0x2c, 0xb5, 0xcd, 0xa1, 0x37, 0xcd, 0xfb, 0x3d, 0x9e, 0x56, 0xb3, 0x4f, 0x70, 0x76,
+	0x57, 0xa8, 0x3c, 0x27, 0xb3, 0x6c, 0x4c, 0x6d, 0x6b, 0x84, 0xc7, 0x71, 0x7f, 0x99, 0xbc, 0x86,
+	0xc9, 0x07, 0xcd, 0x56, 0xd2, 0xb7, 0x1d, 0xb1, 0xc5, 0xe7, 0x30, 0xae, 0x54, 0x49, 0xdc, 0xa8,
+	0x8c, 0x44, 0x34, 0x8f, 0x16, 0x63, 0xd9, 0x0a, 0xc9, 0x35, 0x4c, 0x83, 0x99, 0x9b, 0xba, 0x62,
+	0xc2, 0x25, 0x8c, 0x32, 0x55, 0x29, 0xa3, 0x89, 0x45, 0x34, 0x8f, 0x17, 0x93, 0x15, 0x2e, 0xdb,
+	0xec, 0xe5, 0xda, 0xb1, 0x07, 0x79, 0xf4, 0x24, 0xd7, 0x00, 0x37, 0xf4, 0x7f, 0xef, 0x42, 0x84,
+	0xbe, 0x1b, 0x44, 0xcf, 0x03, 0xff, 0x9c, 0xfc, 0xea, 0xc1, 0x30, 0x84, 0x1e, 0x71, 0xd4, 0xe2,
+	0x3f, 0x03, 0x7b, 0x7f, 0x07, 0xbe, 0x84, 0x89, 0x55, 0x26, 0x27, 0x9b, 0xde, 0xeb, 0x6a, 0x23,
+	0x62, 0xcf, 0x21, 0x48, 0xef, 0x75, 0xb5, 0xe9, 0x18, 0x7c, 0x72, 0xbf, 0x6b, 0xf8, 0xe4, 0xf2,
+	0x67, 0x30, 0x68, 0xb6, 0x8a, 0x49, 0x0c, 0x3c, 0x0a, 0x03, 0xbe, 0x82, 0x33, 0xff, 0x81, 0x0f,
+	0xe9, 0x77, 0xd2, 0xf9, 0xd6, 0x8a, 0xe1, 0x3c, 0x5a, 0x0c, 0xe4, 0x34, 0x88, 0x9f, 0xbd, 0xe6,
+	0x4c, 0x77, 0x4a, 0x17, 0xb4, 0x49, 0xb3, 0x2d, 0x65, 0xf7, 0x2c, 0x4e, 0x83, 0x29, 0x88, 0x6b,
+	0xaf, 0xe1, 0x0b, 0x00, 0x6d, 0xc9, 0x28, 0xab, 0xeb, 0x8a, 0xc5, 0xc8, 0x3b, 0x3a, 0x0a, 0xbe,
+	0x81, 0x59, 0xa1, 0xd8, 0xa6, 0xd6, 0xa8, 0x8a, 0xb5, 0xd3, 0x52, 0xab, 0x4b, 0x12, 0xe3, 0x79,
+	0xb4, 0x88, 0x25, 0x3a, 0x76, 0x7b, 0x44, 0xb7, 0xba, 0x24, 0x5c, 0xc1, 0x69, 0x49, 0xd6, 0xe8,
+	0x8c, 0x05, 0xf8, 0xfb, 0x11, 0xdd, 0xfb, 0xf9, 0xe8, 0x91, 0x24, 0xde, 0x15, 0x56, 0x3e, 0x1a,
+	0x93, 0x9f, 0x11, 0x4c, 0xbb, 0xe4, 0xc9, 0x55, 0xcf, 0x60, 0xb0, 0x57, 0xc5, 0x2e, 0xac, 0x39,
+	0x92, 0x61, 0xc0, 0x73, 0x18, 0x36, 0x8a, 0x99, 0xc2, 0x76, 0x47, 0xf2, 0x30, 0x39, 0x37, 0x19,
+	0x53, 0x9b, 0xc3, 0x4e, 0xc3, 0xe0, 0xae, 0xcb, 0x1d, 0x9f, 0xad, 0x2a, 0x1b, 0xbf, 0xd2, 0x58,
+	0xb6, 0xc2, 0xea, 0x07, 0x8c, 0xd6, 0x87, 0xde, 0xe0, 0x5b, 0xe8, 0xbb, 0xde, 0xe1, 0xb3, 0xee,
+	0xe9, 0x3b, 0xb5, 0xbd, 0x10, 0xff, 0x82, 0x50, 0xd1, 0xe4, 0x04, 0xaf, 0x20, 0xbe, 0x21, 0x8b,
+	0xe7, 0x5d, 0x4b, 0xdb, 0xc2, 0x8b, 0x27, 0x1a, 0x9b, 0x9c, 0xbc, 0xeb, 0x7f, 0xe9, 0xed, 0x2f,
+	0xbf, 0x0e, 0xfd, 0xff, 0x72, 0xf5, 0x7b, 0x00, 0xaa, 0xa3, 0x59, 0xb9, 0x40, 0x03, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// CanariesClient is the client API for Canaries service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CanariesClient interface {
+	// List returns the canaries from the given namespace or from all namespaces
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	// Get returns the canary with the given name and namespace
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Canary, error)
+}
+
+type canariesClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCanariesClient(cc *grpc.ClientConn) CanariesClient {
+	return &canariesClient{cc}
+}
+
+func (c *canariesClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/flagger.v1.Canaries/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *canariesClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Canary, error) {
+	out := new(Canary)
+	err := c.cc.Invoke(ctx, "/flagger.v1.Canaries/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CanariesServer is the server API for Canaries service.
+type CanariesServer interface {
+	// List returns the canaries from the given namespace or from all namespaces
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	// Get returns the canary with the given name and namespace
+	Get(context.Context, *GetRequest) (*Canary, error)
+}
+
+// UnimplementedCanariesServer can be embedded to have forward compatible implementations.
+type UnimplementedCanariesServer struct {
+}
+
+func (*UnimplementedCanariesServer) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (*UnimplementedCanariesServer) Get(ctx context.Context, req *GetRequest) (*Canary, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+
+func RegisterCanariesServer(s *grpc.Server, srv CanariesServer) {
+	s.RegisterService(&_Canaries_serviceDesc, srv)
+}
+
+func _Canaries_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CanariesServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flagger.v1.Canaries/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CanariesServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Canaries_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CanariesServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flagger.v1.Canaries/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CanariesServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Canaries_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "flagger.v1.Canaries",
+	HandlerType: (*CanariesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "List",
+			Handler:    _Canaries_List_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _Canaries_Get_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "flagger.proto",
+}
@@ -0,0 +1,5 @@
+package ambassador
+
+const (
+	GroupName = "getambassador.io"
+)
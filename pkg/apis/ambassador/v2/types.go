@@ -0,0 +1,47 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Mapping is a specification for an Ambassador Mapping resource
+type Mapping struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MappingSpec `json:"spec"`
+}
+
+// MappingSpec associates a host and URL prefix with a Kubernetes service
+type MappingSpec struct {
+	// Prefix is the URL prefix matched by the mapping
+	Prefix string `json:"prefix"`
+	// Host is the value of the Host header matched by the mapping
+	Host string `json:"host,omitempty"`
+	// Service is the upstream address e.g. podinfo-primary.test:80
+	Service string `json:"service"`
+	// Weight is the percentage of the requests routed to this mapping,
+	// the mappings without weight share the remaining traffic
+	Weight *int `json:"weight,omitempty"`
+	// Headers are the exact header values matched by the mapping
+	Headers map[string]string `json:"headers,omitempty"`
+	// RegexHeaders are the header regular expressions matched by the mapping
+	RegexHeaders map[string]string `json:"regex_headers,omitempty"`
+	// Rewrite replaces the prefix before forwarding the request, defaults to /
+	Rewrite *string `json:"rewrite,omitempty"`
+	// TimeoutMs is the upstream request timeout in milliseconds
+	TimeoutMs int64 `json:"timeout_ms,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MappingList is a list of Mapping resources
+type MappingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Mapping `json:"items"`
+}
@@ -0,0 +1,5 @@
+// +k8s:deepcopy-gen=package
+
+// Package v2 is the v2 version of the API.
+// +groupName=apisix.apache.org
+package v2
@@ -0,0 +1,73 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApisixRoute is a specification for an Apache APISIX route resource
+type ApisixRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ApisixRouteSpec `json:"spec"`
+}
+
+// ApisixRouteSpec is the list of HTTP routes
+type ApisixRouteSpec struct {
+	HTTP []ApisixRouteHTTP `json:"http,omitempty"`
+}
+
+// ApisixRouteHTTP routes the matching requests to the weighted backends
+type ApisixRouteHTTP struct {
+	// Name is unique within the ApisixRoute
+	Name string `json:"name"`
+	// Priority of the route, the highest priority is evaluated first
+	Priority int                      `json:"priority,omitempty"`
+	Match    ApisixRouteHTTPMatch     `json:"match"`
+	Backends []ApisixRouteHTTPBackend `json:"backends"`
+}
+
+// ApisixRouteHTTPMatch is the host, path and expression matching of a route
+type ApisixRouteHTTPMatch struct {
+	Paths []string                   `json:"paths"`
+	Hosts []string                   `json:"hosts,omitempty"`
+	Exprs []ApisixRouteHTTPMatchExpr `json:"exprs,omitempty"`
+}
+
+// ApisixRouteHTTPMatchExpr matches a header, cookie, query or path value
+type ApisixRouteHTTPMatchExpr struct {
+	Subject ApisixRouteHTTPMatchExprSubject `json:"subject"`
+	// Op can be Equal, NotEqual, GreaterThan, LessThan, In, NotIn,
+	// RegexMatch, RegexMatchCaseInsensitive or RegexNotMatch
+	Op    string `json:"op"`
+	Value string `json:"value,omitempty"`
+}
+
+// ApisixRouteHTTPMatchExprSubject is the request value of the expression
+type ApisixRouteHTTPMatchExprSubject struct {
+	// Scope can be Header, Query, Cookie or Path
+	Scope string `json:"scope"`
+	Name  string `json:"name,omitempty"`
+}
+
+// ApisixRouteHTTPBackend is a Kubernetes service upstream
+type ApisixRouteHTTPBackend struct {
+	ServiceName string             `json:"serviceName"`
+	ServicePort intstr.IntOrString `json:"servicePort"`
+	// Weight of the backend, APISIX defaults to 100 when omitted
+	Weight int `json:"weight"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApisixRouteList is a list of ApisixRoute resources
+type ApisixRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ApisixRoute `json:"items"`
+}
@@ -411,3 +411,44 @@ type VirtualNodeList struct {
 
 	Items []VirtualNode `json:"items"`
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GatewayRoute is a specification for a GatewayRoute resource
+type GatewayRoute struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec GatewayRouteSpec `json:"spec,omitempty"`
+	// +optional
+	Status GatewayRouteStatus `json:"status,omitempty"`
+}
+
+// GatewayRouteSpec is the spec for a GatewayRoute resource
+type GatewayRouteSpec struct {
+	MeshName string `json:"meshName"`
+	// VirtualGatewayName is the virtual gateway the routes are attached to
+	VirtualGatewayName string `json:"virtualGatewayName"`
+	// +optional
+	Routes []Route `json:"routes,omitempty"`
+}
+
+// GatewayRouteStatus is the status for a GatewayRoute resource
+type GatewayRouteStatus struct {
+	// GatewayRouteArn is the AppMesh GatewayRoute object's Amazon Resource Name
+	// +optional
+	GatewayRouteArn *string `json:"gatewayRouteArn,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GatewayRouteList is a list of GatewayRoute resources
+type GatewayRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []GatewayRoute `json:"items"`
+}
@@ -0,0 +1,56 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DNSEndpoint is a specification for an ExternalDNS endpoint resource,
+// ExternalDNS creates a record in the DNS provider for each endpoint
+type DNSEndpoint struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DNSEndpointSpec   `json:"spec"`
+	Status DNSEndpointStatus `json:"status,omitempty"`
+}
+
+// DNSEndpointSpec is the list of DNS records
+type DNSEndpointSpec struct {
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+}
+
+// Endpoint is a DNS record, the records with the same name and type
+// are told apart by their set identifier
+type Endpoint struct {
+	DNSName       string   `json:"dnsName,omitempty"`
+	Targets       []string `json:"targets,omitempty"`
+	RecordType    string   `json:"recordType,omitempty"`
+	SetIdentifier string   `json:"setIdentifier,omitempty"`
+	RecordTTL     int64    `json:"recordTTL,omitempty"`
+	// ProviderSpecific holds the properties of the DNS provider e.g. the aws/weight routing policy
+	ProviderSpecific []ProviderSpecificProperty `json:"providerSpecific,omitempty"`
+}
+
+// ProviderSpecificProperty is a DNS provider property of a record
+type ProviderSpecificProperty struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// DNSEndpointStatus is the observed generation of the records
+type DNSEndpointStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DNSEndpointList is a list of DNSEndpoint resources
+type DNSEndpointList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []DNSEndpoint `json:"items"`
+}
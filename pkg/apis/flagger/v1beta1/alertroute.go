@@ -0,0 +1,64 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	AlertRouteKind = "AlertRoute"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertRoute is a cluster wide routing table of the canary alerts,
+// the canaries matching a route selector send their alerts to the route providers
+type AlertRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AlertRouteSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertRouteList is a list of alert route resources
+type AlertRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []AlertRoute `json:"items"`
+}
+
+// AlertRouteSpec is the specification of the alert routing table
+type AlertRouteSpec struct {
+	// Routes evaluated against the canary labels
+	Routes []AlertRouteRule `json:"routes"`
+}
+
+// AlertRouteRule selects the canaries by labels and defines their alerts
+type AlertRouteRule struct {
+	// Selector of the canary labels, an empty selector matches all canaries
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Alerts sent for the matching canaries, the provider namespace defaults to the canary namespace
+	Alerts []CanaryAlert `json:"alerts"`
+}
@@ -18,9 +18,15 @@ package v1beta1
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
 	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+	"github.com/weaveworks/flagger/pkg/schedule"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -30,6 +36,43 @@ const (
 	ProgressDeadlineSeconds = 600
 	AnalysisInterval        = 60 * time.Second
 	MetricInterval          = "1m"
+	SessionCookieName       = "flagger-cookie"
+	SessionCookieMaxAge     = 86400
+)
+
+const (
+	// RollbackStrategyInstant routes all traffic to primary when the analysis fails
+	RollbackStrategyInstant = "instant"
+	// RollbackStrategyStepped decreases the canary traffic in steps when the analysis fails
+	RollbackStrategyStepped = "stepped"
+)
+
+const (
+	// RevisionPolicyRestart restarts the analysis when a new revision is detected
+	RevisionPolicyRestart = "restart"
+	// RevisionPolicyQueue holds back a new revision until the running analysis is finished
+	RevisionPolicyQueue = "queue"
+	// RevisionPolicyReject reverts a new revision detected while the analysis is running
+	RevisionPolicyReject = "reject"
+)
+
+const (
+	// ImpactCheckHalt keeps the canary from starting when it can't absorb the first step
+	ImpactCheckHalt = "halt"
+	// ImpactCheckAlert notifies the alert providers and starts the analysis
+	ImpactCheckAlert = "alert"
+)
+
+const (
+	// PromoteAnnotation promotes the canary without finishing the analysis,
+	// the value must be the canary revision under analysis (status.lastAppliedSpec)
+	PromoteAnnotation = "flagger.app/promote"
+	// RollbackAnnotation rolls back the canary without finishing the analysis,
+	// the value must be the canary revision under analysis (status.lastAppliedSpec)
+	RollbackAnnotation = "flagger.app/rollback"
+	// HotfixAnnotation set on the target pod template promotes the revision without analysis once its pods are ready,
+	// the value identifies the hotfix and the next revisions keeping the same value are analysed
+	HotfixAnnotation = "flagger.app/hotfix"
 )
 
 // +genclient
@@ -80,6 +123,10 @@ type CanarySpec struct {
 	// Service defines how ClusterIP services, service mesh or ingress routing objects are generated
 	Service CanaryService `json:"service"`
 
+	// CanaryClassName references the CanaryClass that provides the analysis defaults
+	// +optional
+	CanaryClassName string `json:"canaryClassName,omitempty"`
+
 	// Analysis defines the validation process of a release
 	Analysis *CanaryAnalysis `json:"analysis,omitempty"`
 
@@ -94,6 +141,62 @@ type CanarySpec struct {
 	// SkipAnalysis promotes the canary without analysing it
 	// +optional
 	SkipAnalysis bool `json:"skipAnalysis,omitempty"`
+
+	// Suspend holds the canary at its current weight, the analysis doesn't advance
+	// nor roll back the canary until it's resumed, except when the target rollout is undone
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// RevertOnDeletion routes the traffic back to the target and scales it up before the canary is deleted,
+	// the deletion is held by a finalizer until the target pods are ready
+	// +optional
+	RevertOnDeletion bool `json:"revertOnDeletion,omitempty"`
+
+	// Cluster runs the canary deployment in a second cluster for multi-cluster canary releases,
+	// the primary is the target deployment in the Flagger cluster
+	// +optional
+	Cluster *CanaryCluster `json:"cluster,omitempty"`
+}
+
+// CanaryCluster is the cluster running the canary deployment of a multi-cluster canary release
+type CanaryCluster struct {
+	// Name of the cluster, available as the cluster variable in the metric templates
+	Name string `json:"name"`
+
+	// Secret reference containing the cluster kubeconfig in the kubeconfig key
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+
+	// Prometheus address of the cluster, queried by the builtin metric checks and the in-line queries
+	// +optional
+	MetricsServer string `json:"metricsServer,omitempty"`
+
+	// Weighted DNS records of the primary and canary clusters managed by the externaldns provider
+	// +optional
+	DNS *CanaryDNS `json:"dns,omitempty"`
+}
+
+// CanaryDNS defines the weighted DNS records that split the traffic between the primary and canary endpoints
+type CanaryDNS struct {
+	// Hostname of the records
+	Hostname string `json:"hostname"`
+
+	// Type of the records, defaults to CNAME
+	// +optional
+	RecordType string `json:"recordType,omitempty"`
+
+	// TTL of the records in seconds
+	// +optional
+	TTL int64 `json:"ttl,omitempty"`
+
+	// Addresses of the primary endpoints e.g. the load balancer of the primary cluster
+	PrimaryTargets []string `json:"primaryTargets"`
+
+	// Addresses of the canary endpoints e.g. the load balancer of the canary cluster
+	CanaryTargets []string `json:"canaryTargets"`
+
+	// Provider specific property holding the record weight, defaults to aws/weight
+	// +optional
+	WeightProperty string `json:"weightProperty,omitempty"`
 }
 
 // CanaryService defines how ClusterIP services, service mesh or ingress routing objects are generated
@@ -116,14 +219,42 @@ type CanaryService struct {
 	// +optional
 	TargetPort intstr.IntOrString `json:"targetPort,omitempty"`
 
+	// Protocol of the generated Kubernetes service port, can be TCP or UDP
+	// Defaults to TCP
+	// +optional
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+
 	// PortDiscovery adds all container ports to the generated Kubernetes service
 	PortDiscovery bool `json:"portDiscovery"`
 
+	// Ports are the additional named ports of the generated Kubernetes services,
+	// the mesh routes are generated for each port e.g. grpc next to http
+	// +optional
+	Ports []CanaryServicePort `json:"ports,omitempty"`
+
+	// Headless generates the Kubernetes services without a cluster IP,
+	// for workloads that rely on client-side load balancing
+	// +optional
+	Headless bool `json:"headless,omitempty"`
+
+	// Apex is the metadata to add to the apex service
+	// +optional
+	Apex *CustomMetadata `json:"apex,omitempty"`
+
+	// Primary is the metadata to add to the primary service
+	// +optional
+	Primary *CustomMetadata `json:"primary,omitempty"`
+
+	// Canary is the metadata to add to the canary service
+	// +optional
+	Canary *CustomMetadata `json:"canary,omitempty"`
+
 	// Timeout of the HTTP or gRPC request
 	// +optional
 	Timeout string `json:"timeout,omitempty"`
 
 	// Gateways attached to the generated Istio virtual service
+	// or App Mesh virtual gateways routing the edge traffic with the canary weights
 	// Defaults to the internal mesh gateway
 	// +optional
 	Gateways []string `json:"gateways,omitempty"`
@@ -133,6 +264,16 @@ type CanaryService struct {
 	// +optional
 	Hosts []string `json:"hosts,omitempty"`
 
+	// GatewayHosts groups the gateways with the hosts exposed on them,
+	// the generated Istio virtual service routes match the gateway of each group
+	// +optional
+	GatewayHosts []GatewayHosts `json:"gatewayHosts,omitempty"`
+
+	// Delegation generates the Istio virtual service without hosts and gateways,
+	// to be referenced as a delegate by a virtual service that's not managed by Flagger
+	// +optional
+	Delegation bool `json:"delegation,omitempty"`
+
 	// TrafficPolicy attached to the generated Istio destination rules
 	// +optional
 	TrafficPolicy *istiov1alpha3.TrafficPolicy `json:"trafficPolicy,omitempty"`
@@ -164,13 +305,78 @@ type CanaryService struct {
 	// Backends of the generated App Mesh virtual nodes
 	// +optional
 	Backends []string `json:"backends,omitempty"`
+
+	// DNS defines the weighted records managed by the externaldns provider,
+	// for services exposed through separate primary and canary load balancers or VMs
+	// +optional
+	DNS *CanaryDNS `json:"dns,omitempty"`
+}
+
+// CanaryServicePort is an additional port of the generated Kubernetes services
+type CanaryServicePort struct {
+	// Name of the port, the prefix selects the protocol routed by the mesh e.g. grpc-api or tcp-admin
+	Name string `json:"name"`
+
+	// Port number of the generated Kubernetes services
+	Port int32 `json:"port"`
+
+	// Target port number or name
+	// Defaults to CanaryServicePort.Port
+	// +optional
+	TargetPort intstr.IntOrString `json:"targetPort,omitempty"`
+
+	// Protocol of the port, can be TCP or UDP
+	// Defaults to TCP
+	// +optional
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+}
+
+// GatewayHosts is a set of Istio gateways and the hosts exposed through them
+type GatewayHosts struct {
+	// Gateways of the group, defaults to the internal mesh gateway
+	// +optional
+	Gateways []string `json:"gateways,omitempty"`
+
+	// Hosts exposed on the gateways of the group
+	// +optional
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// SessionAffinity defines the cookie that routes the returning users to the canary
+type SessionAffinity struct {
+	// Name of the cookie set on the canary responses, defaults to flagger-cookie
+	// +optional
+	CookieName string `json:"cookieName,omitempty"`
+
+	// Lifetime of the cookie in seconds, defaults to one day
+	// +optional
+	MaxAge int `json:"maxAge,omitempty"`
+}
+
+// CustomMetadata holds labels and annotations to set on generated objects
+type CustomMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // CanaryAnalysis is used to describe how the analysis should be done
 type CanaryAnalysis struct {
-	// Schedule interval for this canary analysis
+	// Schedule interval for this canary analysis, accepts the d and w units e.g. 1d
 	Interval string `json:"interval"`
 
+	// Cron expression of the analysis runs, overrides the interval e.g. 0 9 * * mon-fri
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// IANA time zone of the cron schedule and windows e.g. Europe/London, defaults to UTC
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
+	// Cron expressions of the minutes in which the analysis can start or advance e.g. * 9-15 * * mon-fri,
+	// outside the windows the canary waits
+	// +optional
+	Windows []string `json:"windows,omitempty"`
+
 	// Number of checks to run for A/B Testing and Blue/Green
 	// +optional
 	Iterations int `json:"iterations,omitempty"`
@@ -179,6 +385,19 @@ type CanaryAnalysis struct {
 	// +optional
 	Mirror bool `json:"mirror,omitempty"`
 
+	// Percentage of the traffic mirrored to canary (default 100)
+	// +optional
+	MirrorWeight int `json:"mirrorWeight,omitempty"`
+
+	// Number of mirror-only checks to run before shifting traffic to canary (default 1),
+	// for Blue/Green the traffic is mirrored during all iterations unless set
+	// +optional
+	MirrorIterations int `json:"mirrorIterations,omitempty"`
+
+	// External endpoint that receives the mirrored traffic instead of canary
+	// +optional
+	MirrorTarget *CanaryMirrorTarget `json:"mirrorTarget,omitempty"`
+
 	// Max traffic percentage routed to canary
 	// +optional
 	MaxWeight int `json:"maxWeight,omitempty"`
@@ -187,6 +406,79 @@ type CanaryAnalysis struct {
 	// +optional
 	StepWeight int `json:"stepWeight,omitempty"`
 
+	// Traffic percentages of the canary steps e.g. [1, 5, 20, 50, 80], takes precedence over the linear step weight,
+	// the last step is the max weight
+	// +optional
+	StepWeights []int `json:"stepWeights,omitempty"`
+
+	// Number of consecutive passing checks required at the first step weight before increasing the canary traffic,
+	// a failed check restarts the count
+	// +optional
+	WarmupIterations int `json:"warmupIterations,omitempty"`
+
+	// Warmup duration after the canary pods are ready during which the failed checks are not counted
+	// e.g. 2m, the traffic is held at the current weight when a check fails
+	// +optional
+	WarmupDuration string `json:"warmupDuration,omitempty"`
+
+	// Deadline of the analysis measured from its start e.g. 1h, the canary is rolled back
+	// when it isn't promoted in time regardless of the failed checks
+	// +optional
+	Deadline string `json:"deadline,omitempty"`
+
+	// Adaptive steps compute the next weight increase from the headroom of the metrics to their thresholds,
+	// replacing the fixed step weight after the first step
+	// +optional
+	AdaptiveSteps *CanaryAdaptiveSteps `json:"adaptiveSteps,omitempty"`
+
+	// Traffic percentage shifted from the canary to the promoted primary every interval,
+	// all traffic is routed to the primary at once if not set
+	// +optional
+	StepWeightPromotion int `json:"stepWeightPromotion,omitempty"`
+
+	// Traffic percentage kept on the canary after a successful analysis instead of promoting it,
+	// the split is held until a new revision is detected or the field is removed
+	// +optional
+	HoldWeight int `json:"holdWeight,omitempty"`
+
+	// Proportional replicas scales the canary deployment to the share of the primary replicas
+	// matching the canary weight at every traffic step
+	// +optional
+	ProportionalReplicas bool `json:"proportionalReplicas,omitempty"`
+
+	// Baseline workload runs a copy of the primary sized like the canary during the analysis,
+	// the comparison metrics are evaluated against the baseline instead of the primary
+	// +optional
+	BaselineWorkload bool `json:"baselineWorkload,omitempty"`
+
+	// Session affinity pins the users routed to the canary with a cookie for the duration of the analysis
+	// +optional
+	SessionAffinity *SessionAffinity `json:"sessionAffinity,omitempty"`
+
+	// Rollback strategy can be instant (default) or stepped,
+	// a stepped rollback decreases the canary traffic every interval instead of routing all traffic to primary at once
+	// +optional
+	RollbackStrategy string `json:"rollbackStrategy,omitempty"`
+
+	// Traffic percentage removed from the canary at every stepped rollback interval, defaults to the step weight
+	// +optional
+	RollbackStepWeight int `json:"rollbackStepWeight,omitempty"`
+
+	// Max time from the failure detection to all traffic routed back to primary e.g. 5m,
+	// the rollbacks that take longer are reported as alerts
+	// +optional
+	MaxRollbackTime string `json:"maxRollbackTime,omitempty"`
+
+	// Time after a rollback during which the analysis of the rolled back revision is refused e.g. 1h,
+	// the revision is analysed again if it's applied after the cooldown
+	// +optional
+	RollbackCooldown string `json:"rollbackCooldown,omitempty"`
+
+	// Policy for the target revisions applied while the analysis is running,
+	// can be restart (default), queue or reject
+	// +optional
+	RevisionPolicy string `json:"revisionPolicy,omitempty"`
+
 	// Max number of failed checks before the canary is terminated
 	Threshold int `json:"threshold"`
 
@@ -197,13 +489,114 @@ type CanaryAnalysis struct {
 	// +optional
 	Metrics []CanaryMetric `json:"metrics,omitempty"`
 
+	// Metric groups change how the failed checks of their metrics halt the analysis,
+	// the metrics that are not part of a group halt the analysis when any of them fails
+	// +optional
+	MetricGroups []CanaryMetricGroup `json:"metricGroups,omitempty"`
+
 	// Webhook list for this canary  analysis
 	// +optional
 	Webhooks []CanaryWebhook `json:"webhooks,omitempty"`
 
+	// Fire drill simulates a failing metric check without routing traffic to the canary
+	// to verify that the alerts, webhooks and rollback work as expected
+	// +optional
+	FireDrill bool `json:"fireDrill,omitempty"`
+
+	// Dry run runs the metric checks and webhooks against the canary without shifting traffic,
+	// the canary is never promoted and the verdict is reported in the status and events
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Number of executions of the canary job template for CronJob targets, defaults to one
+	// +optional
+	JobExecutions int `json:"jobExecutions,omitempty"`
+
+	// Release tracking list for this canary analysis
+	// +optional
+	ReleaseTrackers []CanaryReleaseTracker `json:"releaseTrackers,omitempty"`
+
+	// Impact check of the first traffic step against the canary capacity, run before the analysis starts
+	// +optional
+	ImpactCheck *CanaryImpactCheck `json:"impactCheck,omitempty"`
+
 	// A/B testing HTTP header match conditions
 	// +optional
 	Match []istiov1alpha3.HTTPMatchRequest `json:"match,omitempty"`
+
+	// A/B testing gRPC match conditions, appended to the HTTP match conditions
+	// +optional
+	GRPCMatch []CanaryGRPCMatch `json:"grpcMatch,omitempty"`
+}
+
+// CanaryGRPCMatch selects the gRPC calls routed to the canary by service, method and request metadata
+type CanaryGRPCMatch struct {
+	// Fully qualified name of the gRPC service e.g. grpc.health.v1.Health, all services match if empty
+	// +optional
+	Service string `json:"service,omitempty"`
+
+	// Method of the service, all methods match if empty
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Request metadata conditions, the keys are case-insensitive
+	// +optional
+	Metadata map[string]istiov1alpha1.StringMatch `json:"metadata,omitempty"`
+}
+
+// CanaryMirrorTarget is an endpoint running outside the cluster, e.g. a rewrite of the service on VMs,
+// that receives the mirrored traffic through a Kubernetes service generated by Flagger
+type CanaryMirrorTarget struct {
+	// DNS name or IP address of the external endpoint
+	Host string `json:"host"`
+
+	// Port of the external endpoint, defaults to the canary service port
+	// +optional
+	Port int32 `json:"port,omitempty"`
+}
+
+// CanaryAdaptiveSteps scales the weight increase with the smallest headroom of the metrics,
+// the headroom being the distance of a metric value to its threshold relative to the threshold
+type CanaryAdaptiveSteps struct {
+	// Weight increase applied when all the metrics have a full headroom, defaults to twice the step weight
+	// +optional
+	MaxStepWeight int `json:"maxStepWeight,omitempty"`
+
+	// Headroom percentage under which the canary weight is held, defaults to 10
+	// +optional
+	MinHeadroom int `json:"minHeadroom,omitempty"`
+}
+
+// GetMaxStepWeight returns the weight increase applied at a full headroom
+func (a *CanaryAdaptiveSteps) GetMaxStepWeight(stepWeight int) int {
+	if a.MaxStepWeight > 0 {
+		return a.MaxStepWeight
+	}
+	return 2 * stepWeight
+}
+
+// GetMinHeadroom returns the headroom fraction under which the canary weight is held
+func (a *CanaryAdaptiveSteps) GetMinHeadroom() float64 {
+	if a.MinHeadroom > 0 {
+		return float64(a.MinHeadroom) / 100
+	}
+	return 0.1
+}
+
+// CanaryImpactCheck estimates the load routed to the canary by the first step weight,
+// from the request rate and the replica capacity if set or else from the primary HPA utilization
+type CanaryImpactCheck struct {
+	// Action taken when the canary can't absorb the first step: halt (default) or alert
+	// +optional
+	Action string `json:"action,omitempty"`
+
+	// Metric template reference returning the requests per second served by the primary
+	// +optional
+	RequestRate *CrossNamespaceObjectReference `json:"requestRate,omitempty"`
+
+	// Max requests per second a canary replica can serve
+	// +optional
+	MaxRequestsPerReplica *float64 `json:"maxRequestsPerReplica,omitempty"`
 }
 
 // CanaryMetric holds the reference to metrics used for canary analysis
@@ -228,6 +621,158 @@ type CanaryMetric struct {
 	// TemplateRef references a metric template object
 	// +optional
 	TemplateRef *CrossNamespaceObjectReference `json:"templateRef,omitempty"`
+
+	// Expression computes the metric value from the results of the metric templates
+	// e.g. errors / requests * 100
+	// +optional
+	Expression string `json:"expression,omitempty"`
+
+	// Templates are the metric templates referenced by name in the expression
+	// +optional
+	Templates []CanaryMetricTemplate `json:"templates,omitempty"`
+
+	// Ratio divides the results of two metric templates evaluated at the same point in time
+	// +optional
+	Ratio *CanaryMetricRatio `json:"ratio,omitempty"`
+
+	// Range evaluates an aggregation of all the datapoints in the interval instead of the last value
+	// +optional
+	Range *CanaryMetricRange `json:"range,omitempty"`
+
+	// Baseline compares the metric template result to its result at a time offset,
+	// the thresholds apply to the current value as a percentage of the baseline value
+	// +optional
+	Baseline *CanaryMetricBaseline `json:"baseline,omitempty"`
+
+	// Comparison judges the datapoints of the canary against the ones of the baseline workload
+	// with a Mann-Whitney U test, the thresholds are ignored
+	// +optional
+	Comparison *CanaryMetricComparison `json:"comparison,omitempty"`
+
+	// SLO judges the error ratio returned by the metric template against the error budget of an objective
+	// with a fast and a slow burn rate, the thresholds are ignored
+	// +optional
+	SLO *CanaryMetricSLO `json:"slo,omitempty"`
+
+	// Group is the name of the metric group this metric belongs to
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Routes scopes the metric to the HTTP paths matching any of the conditions,
+	// defaults to the URI match conditions of the service and the analysis
+	// +optional
+	Routes []istiov1alpha1.StringMatch `json:"routes,omitempty"`
+
+	// RouteLabel is the label holding the HTTP path in the builtin metrics,
+	// the builtin checks are scoped to the routes only when the label is set
+	// +optional
+	RouteLabel string `json:"routeLabel,omitempty"`
+}
+
+const (
+	// MetricGroupAnd halts the analysis only when all the metrics of the group fail
+	MetricGroupAnd = "and"
+	// MetricGroupOr halts the analysis when any metric of the group fails
+	MetricGroupOr = "or"
+)
+
+// CanaryMetricGroup defines the boolean semantics of the failed checks of a set of metrics
+type CanaryMetricGroup struct {
+	// Name of the group
+	Name string `json:"name"`
+
+	// Operator can be and (all metrics must fail) or or (any metric fails), defaults to or
+	// +optional
+	Operator string `json:"operator,omitempty"`
+}
+
+// CanaryMetricRatio computes the metric value as numerator / denominator,
+// the analysis halts when the denominator is below the minimum
+type CanaryMetricRatio struct {
+	// Numerator metric template reference e.g. orders
+	Numerator CrossNamespaceObjectReference `json:"numerator"`
+
+	// Denominator metric template reference e.g. checkouts
+	Denominator CrossNamespaceObjectReference `json:"denominator"`
+
+	// Minimum denominator value required to evaluate the ratio
+	// +optional
+	MinDenominator float64 `json:"minDenominator,omitempty"`
+}
+
+// CanaryMetricTemplate binds the result of a metric template query to an expression variable
+type CanaryMetricTemplate struct {
+	// Name of the expression variable
+	Name string `json:"name"`
+
+	// TemplateRef references a metric template object
+	TemplateRef CrossNamespaceObjectReference `json:"templateRef"`
+}
+
+// CanaryMetricRange defines how the datapoints of a range query are aggregated
+type CanaryMetricRange struct {
+	// Aggregation can be avg, max, min or slope (rate of change per second)
+	Aggregation string `json:"aggregation"`
+
+	// Step is the resolution of the range query, defaults to a tenth of the interval
+	// +optional
+	Step string `json:"step,omitempty"`
+}
+
+// CanaryMetricBaseline defines the time shifted query used as the metric baseline
+type CanaryMetricBaseline struct {
+	// Offset is the time shift of the baseline e.g. -7d for the same time last week
+	Offset string `json:"offset"`
+
+	// Minimum baseline value required to evaluate the metric
+	// +optional
+	MinValue float64 `json:"minValue,omitempty"`
+}
+
+// CanaryMetricComparison defines the statistical comparison of the canary and baseline datapoints
+type CanaryMetricComparison struct {
+	// BaselineTarget is the workload name rendered as target in the baseline query, defaults to the primary
+	// +optional
+	BaselineTarget string `json:"baselineTarget,omitempty"`
+
+	// Direction of the change that fails the check, can be increase, decrease or either, defaults to increase
+	// +optional
+	Direction string `json:"direction,omitempty"`
+
+	// Confidence level in percentage required to report a change as significant, defaults to 95
+	// +optional
+	Confidence float64 `json:"confidence,omitempty"`
+
+	// Step is the resolution of the range queries, defaults to a tenth of the interval
+	// +optional
+	Step string `json:"step,omitempty"`
+
+	// MinDataPoints is the number of datapoints required for both workloads, defaults to 8
+	// +optional
+	MinDataPoints int `json:"minDataPoints,omitempty"`
+}
+
+// CanaryMetricSLO defines the service level objective of a metric
+// and the burn rates of its error budget that fail the check
+type CanaryMetricSLO struct {
+	// Objective is the percentage of good events e.g. 99.9
+	Objective float64 `json:"objective"`
+
+	// FastWindow is the window of the fast burn rate, defaults to 5m
+	// +optional
+	FastWindow string `json:"fastWindow,omitempty"`
+
+	// FastBurnRate is the maximum burn rate over the fast window, defaults to 14.4
+	// +optional
+	FastBurnRate float64 `json:"fastBurnRate,omitempty"`
+
+	// SlowWindow is the window of the slow burn rate, defaults to 1h
+	// +optional
+	SlowWindow string `json:"slowWindow,omitempty"`
+
+	// SlowBurnRate is the maximum burn rate over the slow window, defaults to 6
+	// +optional
+	SlowBurnRate float64 `json:"slowBurnRate,omitempty"`
 }
 
 // CanaryThresholdRange defines the range used for metrics validation
@@ -262,6 +807,39 @@ type CanaryAlert struct {
 	ProviderRef CrossNamespaceObjectReference `json:"providerRef"`
 }
 
+// CanaryReleaseTracker notifies an APM release tracking API when the analysis starts and
+// when the canary is promoted
+type CanaryReleaseTracker struct {
+	// Name of the release tracker
+	Name string `json:"name"`
+
+	// Type of release tracker: datadog, newrelic or sentry
+	Type string `json:"type"`
+
+	// API address of the release tracker, defaults to the provider's public API
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// Secret reference containing the release tracker credentials
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Service name reported to the release tracker, defaults to the target name
+	// +optional
+	Service string `json:"service,omitempty"`
+
+	// Environment reported to the release tracker, defaults to the canary namespace
+	// +optional
+	Environment string `json:"environment,omitempty"`
+
+	// Project of the release tracker, the New Relic application ID or the Sentry project slug
+	// +optional
+	Project string `json:"project,omitempty"`
+
+	// Organization slug, required by Sentry
+	// +optional
+	Organization string `json:"organization,omitempty"`
+}
+
 // HookType can be pre, post or during rollout
 type HookType string
 
@@ -291,7 +869,8 @@ type CanaryWebhook struct {
 	Name string `json:"name"`
 
 	// URL address of this webhook
-	URL string `json:"url"`
+	// +optional
+	URL string `json:"url,omitempty"`
 
 	// Request timeout for this webhook
 	Timeout string `json:"timeout"`
@@ -299,6 +878,22 @@ type CanaryWebhook struct {
 	// Metadata (key-value pairs) for this webhook
 	// +optional
 	Metadata *map[string]string `json:"metadata,omitempty"`
+
+	// Job runs the pre-rollout or post-rollout hook as a Kubernetes Job instead of calling the URL
+	// +optional
+	Job *CanaryWebhookJob `json:"job,omitempty"`
+}
+
+// CanaryWebhookJob is a hook run as a Job in the canary namespace,
+// the hook passes when the Job completes and fails when the Job fails
+type CanaryWebhookJob struct {
+	// Spec of the Job created for each canary revision
+	Spec batchv1.JobSpec `json:"spec"`
+
+	// ResultPath is the file the containers write their result to e.g. a JUnit report,
+	// the file is captured through the container termination message limited to 4KB
+	// +optional
+	ResultPath string `json:"resultPath,omitempty"`
 }
 
 // CanaryWebhookPayload holds the deployment info and metadata sent to webhooks
@@ -370,7 +965,7 @@ func (c *Canary) GetAnalysisInterval() time.Duration {
 		return AnalysisInterval
 	}
 
-	interval, err := time.ParseDuration(c.GetAnalysis().Interval)
+	interval, err := schedule.ParseDuration(c.GetAnalysis().Interval)
 	if err != nil {
 		return AnalysisInterval
 	}
@@ -382,6 +977,41 @@ func (c *Canary) GetAnalysisInterval() time.Duration {
 	return interval
 }
 
+// GetAnalysisSchedule returns the cron schedule of the analysis runs or nil if the analysis runs every interval
+func (c *Canary) GetAnalysisSchedule() (*schedule.Cron, error) {
+	if c.GetAnalysis().Schedule == "" {
+		return nil, nil
+	}
+	return schedule.ParseCron(c.GetAnalysis().Schedule, c.GetAnalysis().Timezone)
+}
+
+// IsWithinAnalysisWindows returns true if the analysis can run at the given time,
+// either because no windows are set or because one of the windows matches it
+func (c *Canary) IsWithinAnalysisWindows(t time.Time) (bool, error) {
+	windows := c.GetAnalysis().Windows
+	if len(windows) == 0 {
+		return true, nil
+	}
+	for _, w := range windows {
+		cron, err := schedule.ParseCron(w, c.GetAnalysis().Timezone)
+		if err != nil {
+			return false, err
+		}
+		if cron.Matches(t) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetRevisionPolicy returns the policy for the revisions detected during the analysis (default restart)
+func (c *Canary) GetRevisionPolicy() string {
+	if c.GetAnalysis().RevisionPolicy == "" {
+		return RevisionPolicyRestart
+	}
+	return c.GetAnalysis().RevisionPolicy
+}
+
 // GetAnalysisThreshold returns the canary threshold (default 1)
 func (c *Canary) GetAnalysisThreshold() int {
 	if c.GetAnalysis().Threshold > 0 {
@@ -390,16 +1020,193 @@ func (c *Canary) GetAnalysisThreshold() int {
 	return 1
 }
 
+// GetMaxWeight returns the max traffic percentage routed to canary,
+// the last of the step weights if set or else the max weight (default 100)
+func (c *Canary) GetMaxWeight() int {
+	if weights := c.GetAnalysis().StepWeights; len(weights) > 0 {
+		return weights[len(weights)-1]
+	}
+	if c.GetAnalysis().MaxWeight > 0 {
+		return c.GetAnalysis().MaxWeight
+	}
+	return 100
+}
+
+// HasStepWeights returns true if the analysis shifts the traffic to canary in steps
+func (c *Canary) HasStepWeights() bool {
+	return c.GetAnalysis().StepWeight > 0 || len(c.GetAnalysis().StepWeights) > 0
+}
+
+// GetNextStepWeight returns the canary weight of the step following the given weight,
+// the first step weight above it if the step weights are set or else the weight incremented by the step weight
+func (c *Canary) GetNextStepWeight(canaryWeight int) int {
+	if weights := c.GetAnalysis().StepWeights; len(weights) > 0 {
+		for _, w := range weights {
+			if w > canaryWeight {
+				return w
+			}
+		}
+		return weights[len(weights)-1]
+	}
+	next := canaryWeight + c.GetAnalysis().StepWeight
+	if next > 100 {
+		return 100
+	}
+	return next
+}
+
+// GetRollbackStepWeight returns the traffic percentage removed from the canary at every rollback step,
+// zero means the traffic is routed to primary at once
+func (c *Canary) GetRollbackStepWeight() int {
+	if c.GetAnalysis().RollbackStrategy != RollbackStrategyStepped {
+		return 0
+	}
+	if c.GetAnalysis().RollbackStepWeight > 0 {
+		return c.GetAnalysis().RollbackStepWeight
+	}
+	return c.GetAnalysis().StepWeight
+}
+
+// GetMaxRollbackTime returns the rollback time objective, zero means the rollback time isn't enforced
+func (c *Canary) GetMaxRollbackTime() time.Duration {
+	if c.GetAnalysis().MaxRollbackTime == "" {
+		return 0
+	}
+	d, err := schedule.ParseDuration(c.GetAnalysis().MaxRollbackTime)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// GetWarmupDuration returns the time during which the failed checks are not counted, zero means no warmup
+func (c *Canary) GetWarmupDuration() time.Duration {
+	if c.GetAnalysis().WarmupDuration == "" {
+		return 0
+	}
+	d, err := schedule.ParseDuration(c.GetAnalysis().WarmupDuration)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// GetAnalysisDeadline returns the time after which a progressing analysis is rolled back, zero means no deadline
+func (c *Canary) GetAnalysisDeadline() time.Duration {
+	if c.GetAnalysis().Deadline == "" {
+		return 0
+	}
+	d, err := schedule.ParseDuration(c.GetAnalysis().Deadline)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// GetRollbackCooldown returns the time during which a rolled back revision is not analysed, zero means no cooldown
+func (c *Canary) GetRollbackCooldown() time.Duration {
+	if c.GetAnalysis().RollbackCooldown == "" {
+		return 0
+	}
+	d, err := schedule.ParseDuration(c.GetAnalysis().RollbackCooldown)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// GetMirrorWeight returns the percentage of the traffic mirrored to canary (default 100)
+func (c *Canary) GetMirrorWeight() int {
+	if w := c.GetAnalysis().MirrorWeight; w > 0 && w < 100 {
+		return w
+	}
+	return 100
+}
+
+// GetMirrorTargetPort returns the port of the external mirror target (default service port)
+func (c *Canary) GetMirrorTargetPort() int32 {
+	if t := c.GetAnalysis().MirrorTarget; t != nil && t.Port > 0 {
+		return t.Port
+	}
+	return c.Spec.Service.Port
+}
+
+// GetMirrorIterations returns the number of mirror-only checks run before the traffic shifting (default 1)
+func (c *Canary) GetMirrorIterations() int {
+	if c.GetAnalysis().MirrorIterations > 0 {
+		return c.GetAnalysis().MirrorIterations
+	}
+	return 1
+}
+
+// GetJobExecutions returns the number of canary job executions, defaults to one
+func (c *Canary) GetJobExecutions() int {
+	if c.GetAnalysis().JobExecutions > 0 {
+		return c.GetAnalysis().JobExecutions
+	}
+	return 1
+}
+
 // GetMetricInterval returns the metric interval default value (1m)
 func (c *Canary) GetMetricInterval() string {
 	return MetricInterval
 }
 
+// GetSessionCookie returns the name and max age of the canary session affinity cookie,
+// the name is empty when the session affinity is disabled
+func (c *Canary) GetSessionCookie() (string, int) {
+	sa := c.GetAnalysis().SessionAffinity
+	if sa == nil {
+		return "", 0
+	}
+	name := SessionCookieName
+	if sa.CookieName != "" {
+		name = sa.CookieName
+	}
+	maxAge := SessionCookieMaxAge
+	if sa.MaxAge > 0 {
+		maxAge = sa.MaxAge
+	}
+	return name, maxAge
+}
+
+// GetGRPCMatch returns the gRPC match conditions as HTTP/2 match conditions,
+// the gRPC calls are requests on the /<service>/<method> path and the metadata are sent as headers
+func (c *Canary) GetGRPCMatch() []istiov1alpha3.HTTPMatchRequest {
+	var matches []istiov1alpha3.HTTPMatchRequest
+	for _, m := range c.GetAnalysis().GRPCMatch {
+		match := istiov1alpha3.HTTPMatchRequest{}
+		switch {
+		case m.Service != "" && m.Method != "":
+			match.Uri = &istiov1alpha1.StringMatch{Exact: fmt.Sprintf("/%s/%s", m.Service, m.Method)}
+		case m.Service != "":
+			match.Uri = &istiov1alpha1.StringMatch{Prefix: fmt.Sprintf("/%s/", m.Service)}
+		case m.Method != "":
+			match.Uri = &istiov1alpha1.StringMatch{Regex: fmt.Sprintf("^/[^/]+/%s$", regexp.QuoteMeta(m.Method))}
+		}
+		if len(m.Metadata) > 0 {
+			match.Headers = make(map[string]istiov1alpha1.StringMatch, len(m.Metadata))
+			for k, v := range m.Metadata {
+				match.Headers[strings.ToLower(k)] = v
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// IsCommandRequested returns true if the command annotation targets the canary revision under analysis,
+// the annotations left on the canary after the command was carried out are ignored for the next revisions
+func (c *Canary) IsCommandRequested(annotation string) bool {
+	revision, ok := c.Annotations[annotation]
+	return ok && revision != "" && revision == c.Status.LastAppliedSpec
+}
+
 // SkipAnalysis returns true if the analysis is nil
-// or if spec.SkipAnalysis is true
+// or if spec.SkipAnalysis is true, a dry run is never skipped
 func (c *Canary) SkipAnalysis() bool {
 	if c.Spec.Analysis == nil && c.Spec.CanaryAnalysis == nil {
 		return true
 	}
-	return c.Spec.SkipAnalysis
+	return c.Spec.SkipAnalysis && !c.GetAnalysis().DryRun
 }
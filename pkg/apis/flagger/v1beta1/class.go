@@ -0,0 +1,253 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	CanaryClassKind = "CanaryClass"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryClass is a cluster wide template of the canary analysis,
+// the canaries referencing a class inherit its analysis and override the fields they set
+type CanaryClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CanaryClassSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryClassList is a list of canary class resources
+type CanaryClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []CanaryClass `json:"items"`
+}
+
+// CanaryClassSpec is the specification of the defaults inherited by the canaries
+type CanaryClassSpec struct {
+	// Analysis defaults including the metrics, webhooks and alerts
+	// +optional
+	Analysis *CanaryAnalysis `json:"analysis,omitempty"`
+}
+
+// ApplyClass merges the class analysis into the canary analysis,
+// the scalar fields set in the canary take precedence and the metrics, metric groups,
+// webhooks, alerts and release trackers are merged by name
+func (c *Canary) ApplyClass(class *CanaryClass) {
+	if class.Spec.Analysis == nil {
+		return
+	}
+
+	analysis := c.GetAnalysis()
+	if analysis == nil {
+		c.Spec.Analysis = class.Spec.Analysis.DeepCopy()
+		return
+	}
+
+	defaults := class.Spec.Analysis.DeepCopy()
+	if analysis.Interval == "" {
+		analysis.Interval = defaults.Interval
+	}
+	if analysis.Schedule == "" {
+		analysis.Schedule = defaults.Schedule
+	}
+	if analysis.Timezone == "" {
+		analysis.Timezone = defaults.Timezone
+	}
+	if analysis.Iterations == 0 {
+		analysis.Iterations = defaults.Iterations
+	}
+	if !analysis.Mirror {
+		analysis.Mirror = defaults.Mirror
+	}
+	if analysis.MaxWeight == 0 {
+		analysis.MaxWeight = defaults.MaxWeight
+	}
+	// the step weights of the class don't replace the linear step of the canary
+	if len(analysis.StepWeights) == 0 && analysis.StepWeight == 0 {
+		analysis.StepWeights = defaults.StepWeights
+	}
+	if analysis.StepWeight == 0 {
+		analysis.StepWeight = defaults.StepWeight
+	}
+	if analysis.WarmupIterations == 0 {
+		analysis.WarmupIterations = defaults.WarmupIterations
+	}
+	if analysis.WarmupDuration == "" {
+		analysis.WarmupDuration = defaults.WarmupDuration
+	}
+	if analysis.Deadline == "" {
+		analysis.Deadline = defaults.Deadline
+	}
+	if analysis.AdaptiveSteps == nil {
+		analysis.AdaptiveSteps = defaults.AdaptiveSteps
+	}
+	if analysis.StepWeightPromotion == 0 {
+		analysis.StepWeightPromotion = defaults.StepWeightPromotion
+	}
+	if analysis.HoldWeight == 0 {
+		analysis.HoldWeight = defaults.HoldWeight
+	}
+	if !analysis.ProportionalReplicas {
+		analysis.ProportionalReplicas = defaults.ProportionalReplicas
+	}
+	if !analysis.BaselineWorkload {
+		analysis.BaselineWorkload = defaults.BaselineWorkload
+	}
+	if analysis.RollbackStrategy == "" {
+		analysis.RollbackStrategy = defaults.RollbackStrategy
+	}
+	if analysis.RollbackStepWeight == 0 {
+		analysis.RollbackStepWeight = defaults.RollbackStepWeight
+	}
+	if analysis.RollbackCooldown == "" {
+		analysis.RollbackCooldown = defaults.RollbackCooldown
+	}
+	if analysis.RevisionPolicy == "" {
+		analysis.RevisionPolicy = defaults.RevisionPolicy
+	}
+	if analysis.Threshold == 0 {
+		analysis.Threshold = defaults.Threshold
+	}
+	if !analysis.FireDrill {
+		analysis.FireDrill = defaults.FireDrill
+	}
+	if !analysis.DryRun {
+		analysis.DryRun = defaults.DryRun
+	}
+	if analysis.JobExecutions == 0 {
+		analysis.JobExecutions = defaults.JobExecutions
+	}
+	if len(analysis.Match) == 0 {
+		analysis.Match = defaults.Match
+	}
+
+	analysis.Alerts = mergeAlerts(defaults.Alerts, analysis.Alerts)
+	analysis.Metrics = mergeMetrics(defaults.Metrics, analysis.Metrics)
+	analysis.MetricGroups = mergeMetricGroups(defaults.MetricGroups, analysis.MetricGroups)
+	analysis.Webhooks = mergeWebhooks(defaults.Webhooks, analysis.Webhooks)
+	analysis.ReleaseTrackers = mergeReleaseTrackers(defaults.ReleaseTrackers, analysis.ReleaseTrackers)
+}
+
+// mergeByName returns the positions of the overrides in the merged list,
+// the defaults keep their order and the new names are appended
+func mergeByName(defaults []string, overrides []string) (index []int, size int) {
+	positions := make(map[string]int, len(defaults))
+	for i, name := range defaults {
+		positions[name] = i
+	}
+	size = len(defaults)
+	for _, name := range overrides {
+		if i, ok := positions[name]; ok {
+			index = append(index, i)
+			continue
+		}
+		index = append(index, size)
+		size++
+	}
+	return
+}
+
+func mergeAlerts(defaults []CanaryAlert, overrides []CanaryAlert) []CanaryAlert {
+	var dn, on []string
+	for _, v := range defaults {
+		dn = append(dn, v.Name)
+	}
+	for _, v := range overrides {
+		on = append(on, v.Name)
+	}
+	index, size := mergeByName(dn, on)
+	result := append(make([]CanaryAlert, 0, size), defaults...)[:size]
+	for i, v := range overrides {
+		result[index[i]] = v
+	}
+	return result
+}
+
+func mergeMetrics(defaults []CanaryMetric, overrides []CanaryMetric) []CanaryMetric {
+	var dn, on []string
+	for _, v := range defaults {
+		dn = append(dn, v.Name)
+	}
+	for _, v := range overrides {
+		on = append(on, v.Name)
+	}
+	index, size := mergeByName(dn, on)
+	result := append(make([]CanaryMetric, 0, size), defaults...)[:size]
+	for i, v := range overrides {
+		result[index[i]] = v
+	}
+	return result
+}
+
+func mergeMetricGroups(defaults []CanaryMetricGroup, overrides []CanaryMetricGroup) []CanaryMetricGroup {
+	var dn, on []string
+	for _, v := range defaults {
+		dn = append(dn, v.Name)
+	}
+	for _, v := range overrides {
+		on = append(on, v.Name)
+	}
+	index, size := mergeByName(dn, on)
+	result := append(make([]CanaryMetricGroup, 0, size), defaults...)[:size]
+	for i, v := range overrides {
+		result[index[i]] = v
+	}
+	return result
+}
+
+func mergeWebhooks(defaults []CanaryWebhook, overrides []CanaryWebhook) []CanaryWebhook {
+	var dn, on []string
+	for _, v := range defaults {
+		dn = append(dn, v.Name)
+	}
+	for _, v := range overrides {
+		on = append(on, v.Name)
+	}
+	index, size := mergeByName(dn, on)
+	result := append(make([]CanaryWebhook, 0, size), defaults...)[:size]
+	for i, v := range overrides {
+		result[index[i]] = v
+	}
+	return result
+}
+
+func mergeReleaseTrackers(defaults []CanaryReleaseTracker, overrides []CanaryReleaseTracker) []CanaryReleaseTracker {
+	var dn, on []string
+	for _, v := range defaults {
+		dn = append(dn, v.Name)
+	}
+	for _, v := range overrides {
+		on = append(on, v.Name)
+	}
+	index, size := mergeByName(dn, on)
+	result := append(make([]CanaryReleaseTracker, 0, size), defaults...)[:size]
+	for i, v := range overrides {
+		result[index[i]] = v
+	}
+	return result
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+	"strconv"
 	"text/template"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -56,6 +59,41 @@ type MetricTemplateSpec struct {
 
 	// Query template for this metric
 	Query string `json:"query,omitempty"`
+
+	// SLI generates the query from a recipe, the query is ignored when set
+	// +optional
+	SLI *MetricTemplateSLI `json:"sli,omitempty"`
+}
+
+const (
+	// SLIErrorRatio is the percentage of failed requests
+	SLIErrorRatio = "error-ratio"
+	// SLIP99Latency is the 99th percentile of the requests duration
+	SLIP99Latency = "p99-latency"
+	// SLISaturation is the resource usage, as a percentage of the limit if set
+	SLISaturation = "saturation"
+)
+
+// MetricTemplateSLI is a service level indicator recipe translated into the provider query
+type MetricTemplateSLI struct {
+	// Recipe of the indicator, can be error-ratio, p99-latency or saturation
+	Recipe string `json:"recipe"`
+
+	// Metric is the requests counter for error-ratio, the duration histogram or distribution
+	// for p99-latency and the usage gauge or counter for saturation
+	Metric string `json:"metric"`
+
+	// Selector holds the labels or tags of the measured series, the values can contain template variables
+	// +optional
+	Selector map[string]string `json:"selector,omitempty"`
+
+	// ErrorSelector holds the labels or tags of the failed requests for error-ratio
+	// +optional
+	ErrorSelector map[string]string `json:"errorSelector,omitempty"`
+
+	// LimitMetric is the resource limit the saturation is computed against
+	// +optional
+	LimitMetric string `json:"limitMetric,omitempty"`
 }
 
 // MetricProvider is the spec for a MetricProvider resource
@@ -69,6 +107,76 @@ type MetricTemplateProvider struct {
 	// Secret reference containing the provider credentials
 	// +optional
 	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Bearer token file in the controller token directory, the file is read on every request
+	// so that the rotated tokens are used e.g. a projected service account token
+	// +optional
+	TokenFile string `json:"tokenFile,omitempty"`
+
+	// Vault secret containing the provider credentials, the values are merged over the secret ones
+	// +optional
+	VaultRef *MetricTemplateVaultRef `json:"vaultRef,omitempty"`
+
+	// Config map reference containing the CA bundle (ca.crt) used to verify the provider address
+	// +optional
+	CAConfigMapRef *corev1.LocalObjectReference `json:"caConfigMapRef,omitempty"`
+
+	// Timeout of the provider queries, defaults to 5s
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	// Retry policy for the queries that fail with a transient error
+	// +optional
+	Retry *MetricTemplateRetry `json:"retry,omitempty"`
+
+	// Budget limits the queries run against this provider during a canary analysis
+	// +optional
+	Budget *MetricTemplateBudget `json:"budget,omitempty"`
+
+	// Duration the query results are cached for and shared between canaries
+	// +optional
+	CacheTTL string `json:"cacheTTL,omitempty"`
+
+	// Headers added to the provider requests to select the tenant or the account
+	// e.g. X-Scope-OrgID for Cortex and Thanos, the credentials headers are reserved
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// MetricTemplateVaultRef references a Vault secret read with the Kubernetes auth method,
+// the secret is read from the controller Vault server with the role of the metric template namespace
+type MetricTemplateVaultRef struct {
+	// Path of the secret e.g. secret/data/flagger/datadog
+	Path string `json:"path"`
+}
+
+// MetricTemplateRetry is the retry policy for failed provider queries
+type MetricTemplateRetry struct {
+	// Number of retries after the first failed query, at most 10
+	Attempts int `json:"attempts"`
+
+	// Wait duration before the first retry, doubled after every attempt,
+	// the retries stop before their total wait exceeds half of the analysis interval
+	// Defaults to 1s, at most 1m
+	// +optional
+	Backoff string `json:"backoff,omitempty"`
+}
+
+// MetricTemplateBudget holds the query limits of a provider per canary analysis,
+// the templates with the same provider type, address and credentials share the budget
+// and the strictest limits apply
+type MetricTemplateBudget struct {
+	// Maximum number of queries per canary analysis
+	// +optional
+	MaxQueries int `json:"maxQueries,omitempty"`
+
+	// Estimated cost of a single query in the provider billing currency
+	// +optional
+	CostPerQuery *float64 `json:"costPerQuery,omitempty"`
+
+	// Maximum estimated cost per canary analysis
+	// +optional
+	MaxCost *float64 `json:"maxCost,omitempty"`
 }
 
 // MetricTemplateModel is the query template model
@@ -79,6 +187,16 @@ type MetricTemplateModel struct {
 	Service   string `json:"service"`
 	Ingress   string `json:"ingress"`
 	Interval  string `json:"interval"`
+	// Route is the regex matching the HTTP paths the metric is scoped to, empty if not scoped
+	Route string `json:"route"`
+	// RouteLabel is the label holding the HTTP path in the builtin queries
+	RouteLabel string `json:"routeLabel"`
+	// Cluster is the name of the canary cluster, empty if the canary runs in the Flagger cluster
+	Cluster string `json:"cluster"`
+	// CanaryWorkload is the name of the canary workload, the target is the baseline in the comparison queries
+	CanaryWorkload string `json:"canaryWorkload"`
+	// PrimaryWorkload is the name of the primary workload
+	PrimaryWorkload string `json:"primaryWorkload"`
 }
 
 // TemplateFunctions returns a map of functions, one for each model field
@@ -90,7 +208,36 @@ func (mtm *MetricTemplateModel) TemplateFunctions() template.FuncMap {
 		"service":   func() string { return mtm.Service },
 		"ingress":   func() string { return mtm.Ingress },
 		"interval":  func() string { return mtm.Interval },
+		"route": func() string {
+			if mtm.Route == "" {
+				return ".*"
+			}
+			return mtm.Route
+		},
+		"routeSelector":   mtm.routeSelector,
+		"cluster":         func() string { return mtm.Cluster },
+		"canaryWorkload":  func() string { return mtm.CanaryWorkload },
+		"primaryWorkload": func() string { return mtm.PrimaryWorkload },
+		"intervalSeconds": mtm.intervalSeconds,
+	}
+}
+
+// intervalSeconds returns the metric interval in seconds for the providers that don't accept durations
+func (mtm *MetricTemplateModel) intervalSeconds() (int64, error) {
+	d, err := time.ParseDuration(mtm.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing interval: %v", err)
+	}
+	return int64(d.Seconds()), nil
+}
+
+// routeSelector returns the label matcher appended to the builtin queries,
+// empty when the metric isn't scoped or the route label is not set
+func (mtm *MetricTemplateModel) routeSelector() string {
+	if mtm.Route == "" || mtm.RouteLabel == "" {
+		return ""
 	}
+	return fmt.Sprintf(",%s=~%s", mtm.RouteLabel, strconv.Quote(mtm.Route))
 }
 
 type MetricTemplateStatus struct {
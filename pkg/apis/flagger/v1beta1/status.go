@@ -11,6 +11,10 @@ type CanaryConditionType string
 const (
 	// PromotedType refers to the result of the last canary analysis
 	PromotedType CanaryConditionType = "Promoted"
+
+	// ValidType is false when the canary spec can't be analysed e.g. the builtin metrics
+	// are not supported by the provider, it's only set once a spec was rejected
+	ValidType CanaryConditionType = "Valid"
 )
 
 // CanaryCondition is a status condition for a Canary
@@ -54,9 +58,18 @@ const (
 	// CanaryPhaseSucceeded means the canary analysis has been successful
 	// and the canary deployment has been promoted
 	CanaryPhaseSucceeded CanaryPhase = "Succeeded"
+	// CanaryPhaseHolding means the canary analysis has been successful
+	// and the canary receives the hold weight without being promoted
+	CanaryPhaseHolding CanaryPhase = "Holding"
+	// CanaryPhaseRollingBack means the canary analysis failed
+	// and the traffic is being shifted back to primary in steps
+	CanaryPhaseRollingBack CanaryPhase = "RollingBack"
 	// CanaryPhaseFailed means the canary analysis failed
 	// and the canary deployment has been scaled to zero
 	CanaryPhaseFailed CanaryPhase = "Failed"
+	// CanaryPhaseDryRunPassed means the dry run analysis has been successful,
+	// the canary deployment has been scaled to zero without being promoted
+	CanaryPhaseDryRunPassed CanaryPhase = "DryRunPassed"
 )
 
 // CanaryStatus is used for state persistence (read-only)
@@ -73,6 +86,27 @@ type CanaryStatus struct {
 	LastPromotedSpec string `json:"lastPromotedSpec,omitempty"`
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Time the analysis of the current revision started, set until the analysis ends
+	// +optional
+	AnalysisStartTime *metav1.Time `json:"analysisStartTime,omitempty"`
+	// Time the analysis was suspended, the suspended time doesn't count towards the analysis deadline
+	// +optional
+	SuspendTime *metav1.Time `json:"suspendTime,omitempty"`
+	// Estimated time of the promotion, set while the analysis is progressing
+	// +optional
+	PromotionETA *metav1.Time `json:"promotionETA,omitempty"`
+	// Time the failure was detected, set while the traffic is routed back to primary
+	// +optional
+	RollbackStartTime *metav1.Time `json:"rollbackStartTime,omitempty"`
+	// Time from the failure detection to all traffic routed to primary of the last rollback
+	// +optional
+	LastRollbackDuration *metav1.Duration `json:"lastRollbackDuration,omitempty"`
+	// Revision of the last failed canary, its analysis is refused during the rollback cooldown
+	// +optional
+	LastFailedSpec string `json:"lastFailedSpec,omitempty"`
+	// Time the last failed canary was rolled back
+	// +optional
+	LastFailedTime *metav1.Time `json:"lastFailedTime,omitempty"`
 	// +optional
 	Conditions []CanaryCondition `json:"conditions,omitempty"`
 }
@@ -21,8 +21,10 @@ limitations under the License.
 package v1beta1
 
 import (
+	v1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
 	v1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -149,6 +151,115 @@ func (in *AlertProviderStatus) DeepCopy() *AlertProviderStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertRoute) DeepCopyInto(out *AlertRoute) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertRoute.
+func (in *AlertRoute) DeepCopy() *AlertRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AlertRoute) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertRouteList) DeepCopyInto(out *AlertRouteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AlertRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertRouteList.
+func (in *AlertRouteList) DeepCopy() *AlertRouteList {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertRouteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AlertRouteList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertRouteRule) DeepCopyInto(out *AlertRouteRule) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = make([]CanaryAlert, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertRouteRule.
+func (in *AlertRouteRule) DeepCopy() *AlertRouteRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertRouteRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertRouteSpec) DeepCopyInto(out *AlertRouteSpec) {
+	*out = *in
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]AlertRouteRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertRouteSpec.
+func (in *AlertRouteSpec) DeepCopy() *AlertRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Canary) DeepCopyInto(out *Canary) {
 	*out = *in
@@ -177,6 +288,22 @@ func (in *Canary) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAdaptiveSteps) DeepCopyInto(out *CanaryAdaptiveSteps) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAdaptiveSteps.
+func (in *CanaryAdaptiveSteps) DeepCopy() *CanaryAdaptiveSteps {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAdaptiveSteps)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryAlert) DeepCopyInto(out *CanaryAlert) {
 	*out = *in
@@ -197,6 +324,31 @@ func (in *CanaryAlert) DeepCopy() *CanaryAlert {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MirrorTarget != nil {
+		in, out := &in.MirrorTarget, &out.MirrorTarget
+		*out = new(CanaryMirrorTarget)
+		**out = **in
+	}
+	if in.StepWeights != nil {
+		in, out := &in.StepWeights, &out.StepWeights
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdaptiveSteps != nil {
+		in, out := &in.AdaptiveSteps, &out.AdaptiveSteps
+		*out = new(CanaryAdaptiveSteps)
+		**out = **in
+	}
+	if in.SessionAffinity != nil {
+		in, out := &in.SessionAffinity, &out.SessionAffinity
+		*out = new(SessionAffinity)
+		**out = **in
+	}
 	if in.Alerts != nil {
 		in, out := &in.Alerts, &out.Alerts
 		*out = make([]CanaryAlert, len(*in))
@@ -209,6 +361,11 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MetricGroups != nil {
+		in, out := &in.MetricGroups, &out.MetricGroups
+		*out = make([]CanaryMetricGroup, len(*in))
+		copy(*out, *in)
+	}
 	if in.Webhooks != nil {
 		in, out := &in.Webhooks, &out.Webhooks
 		*out = make([]CanaryWebhook, len(*in))
@@ -216,6 +373,18 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ReleaseTrackers != nil {
+		in, out := &in.ReleaseTrackers, &out.ReleaseTrackers
+		*out = make([]CanaryReleaseTracker, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImpactCheck != nil {
+		in, out := &in.ImpactCheck, &out.ImpactCheck
+		*out = new(CanaryImpactCheck)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Match != nil {
 		in, out := &in.Match, &out.Match
 		*out = make([]v1alpha3.HTTPMatchRequest, len(*in))
@@ -223,92 +392,464 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.GRPCMatch != nil {
+		in, out := &in.GRPCMatch, &out.GRPCMatch
+		*out = make([]CanaryGRPCMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysis.
+func (in *CanaryAnalysis) DeepCopy() *CanaryAnalysis {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryClass) DeepCopyInto(out *CanaryClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryClass.
+func (in *CanaryClass) DeepCopy() *CanaryClass {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryClass) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryClassList) DeepCopyInto(out *CanaryClassList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CanaryClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryClassList.
+func (in *CanaryClassList) DeepCopy() *CanaryClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryClassList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryClassSpec) DeepCopyInto(out *CanaryClassSpec) {
+	*out = *in
+	if in.Analysis != nil {
+		in, out := &in.Analysis, &out.Analysis
+		*out = new(CanaryAnalysis)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryClassSpec.
+func (in *CanaryClassSpec) DeepCopy() *CanaryClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryCluster) DeepCopyInto(out *CanaryCluster) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(CanaryDNS)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCluster.
+func (in *CanaryCluster) DeepCopy() *CanaryCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryCondition) DeepCopyInto(out *CanaryCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCondition.
+func (in *CanaryCondition) DeepCopy() *CanaryCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryDNS) DeepCopyInto(out *CanaryDNS) {
+	*out = *in
+	if in.PrimaryTargets != nil {
+		in, out := &in.PrimaryTargets, &out.PrimaryTargets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CanaryTargets != nil {
+		in, out := &in.CanaryTargets, &out.CanaryTargets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryDNS.
+func (in *CanaryDNS) DeepCopy() *CanaryDNS {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryDNS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGRPCMatch) DeepCopyInto(out *CanaryGRPCMatch) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]v1alpha1.StringMatch, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGRPCMatch.
+func (in *CanaryGRPCMatch) DeepCopy() *CanaryGRPCMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGRPCMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryImpactCheck) DeepCopyInto(out *CanaryImpactCheck) {
+	*out = *in
+	if in.RequestRate != nil {
+		in, out := &in.RequestRate, &out.RequestRate
+		*out = new(CrossNamespaceObjectReference)
+		**out = **in
+	}
+	if in.MaxRequestsPerReplica != nil {
+		in, out := &in.MaxRequestsPerReplica, &out.MaxRequestsPerReplica
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryImpactCheck.
+func (in *CanaryImpactCheck) DeepCopy() *CanaryImpactCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryImpactCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryList) DeepCopyInto(out *CanaryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Canary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryList.
+func (in *CanaryList) DeepCopy() *CanaryList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
+	*out = *in
+	if in.ThresholdRange != nil {
+		in, out := &in.ThresholdRange, &out.ThresholdRange
+		*out = new(CanaryThresholdRange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(CrossNamespaceObjectReference)
+		**out = **in
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]CanaryMetricTemplate, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ratio != nil {
+		in, out := &in.Ratio, &out.Ratio
+		*out = new(CanaryMetricRatio)
+		**out = **in
+	}
+	if in.Range != nil {
+		in, out := &in.Range, &out.Range
+		*out = new(CanaryMetricRange)
+		**out = **in
+	}
+	if in.Baseline != nil {
+		in, out := &in.Baseline, &out.Baseline
+		*out = new(CanaryMetricBaseline)
+		**out = **in
+	}
+	if in.Comparison != nil {
+		in, out := &in.Comparison, &out.Comparison
+		*out = new(CanaryMetricComparison)
+		**out = **in
+	}
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(CanaryMetricSLO)
+		**out = **in
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]v1alpha1.StringMatch, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetric.
+func (in *CanaryMetric) DeepCopy() *CanaryMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricBaseline) DeepCopyInto(out *CanaryMetricBaseline) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricBaseline.
+func (in *CanaryMetricBaseline) DeepCopy() *CanaryMetricBaseline {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricBaseline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricComparison) DeepCopyInto(out *CanaryMetricComparison) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricComparison.
+func (in *CanaryMetricComparison) DeepCopy() *CanaryMetricComparison {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricComparison)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricGroup) DeepCopyInto(out *CanaryMetricGroup) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricGroup.
+func (in *CanaryMetricGroup) DeepCopy() *CanaryMetricGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricRange) DeepCopyInto(out *CanaryMetricRange) {
+	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysis.
-func (in *CanaryAnalysis) DeepCopy() *CanaryAnalysis {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricRange.
+func (in *CanaryMetricRange) DeepCopy() *CanaryMetricRange {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryAnalysis)
+	out := new(CanaryMetricRange)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryCondition) DeepCopyInto(out *CanaryCondition) {
+func (in *CanaryMetricRatio) DeepCopyInto(out *CanaryMetricRatio) {
 	*out = *in
-	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	out.Numerator = in.Numerator
+	out.Denominator = in.Denominator
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCondition.
-func (in *CanaryCondition) DeepCopy() *CanaryCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricRatio.
+func (in *CanaryMetricRatio) DeepCopy() *CanaryMetricRatio {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryCondition)
+	out := new(CanaryMetricRatio)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryList) DeepCopyInto(out *CanaryList) {
+func (in *CanaryMetricSLO) DeepCopyInto(out *CanaryMetricSLO) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Canary, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryList.
-func (in *CanaryList) DeepCopy() *CanaryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricSLO.
+func (in *CanaryMetricSLO) DeepCopy() *CanaryMetricSLO {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryList)
+	out := new(CanaryMetricSLO)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CanaryList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricTemplate) DeepCopyInto(out *CanaryMetricTemplate) {
+	*out = *in
+	out.TemplateRef = in.TemplateRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricTemplate.
+func (in *CanaryMetricTemplate) DeepCopy() *CanaryMetricTemplate {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(CanaryMetricTemplate)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
+func (in *CanaryMirrorTarget) DeepCopyInto(out *CanaryMirrorTarget) {
 	*out = *in
-	if in.ThresholdRange != nil {
-		in, out := &in.ThresholdRange, &out.ThresholdRange
-		*out = new(CanaryThresholdRange)
-		(*in).DeepCopyInto(*out)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMirrorTarget.
+func (in *CanaryMirrorTarget) DeepCopy() *CanaryMirrorTarget {
+	if in == nil {
+		return nil
 	}
-	if in.TemplateRef != nil {
-		in, out := &in.TemplateRef, &out.TemplateRef
-		*out = new(CrossNamespaceObjectReference)
+	out := new(CanaryMirrorTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryReleaseTracker) DeepCopyInto(out *CanaryReleaseTracker) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetric.
-func (in *CanaryMetric) DeepCopy() *CanaryMetric {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryReleaseTracker.
+func (in *CanaryReleaseTracker) DeepCopy() *CanaryReleaseTracker {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryMetric)
+	out := new(CanaryReleaseTracker)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -317,6 +858,26 @@ func (in *CanaryMetric) DeepCopy() *CanaryMetric {
 func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 	*out = *in
 	out.TargetPort = in.TargetPort
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]CanaryServicePort, len(*in))
+		copy(*out, *in)
+	}
+	if in.Apex != nil {
+		in, out := &in.Apex, &out.Apex
+		*out = new(CustomMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Primary != nil {
+		in, out := &in.Primary, &out.Primary
+		*out = new(CustomMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CustomMetadata)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Gateways != nil {
 		in, out := &in.Gateways, &out.Gateways
 		*out = make([]string, len(*in))
@@ -327,6 +888,13 @@ func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.GatewayHosts != nil {
+		in, out := &in.GatewayHosts, &out.GatewayHosts
+		*out = make([]GatewayHosts, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.TrafficPolicy != nil {
 		in, out := &in.TrafficPolicy, &out.TrafficPolicy
 		*out = new(v1alpha3.TrafficPolicy)
@@ -347,7 +915,7 @@ func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 	if in.Retries != nil {
 		in, out := &in.Retries, &out.Retries
 		*out = new(v1alpha3.HTTPRetry)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Headers != nil {
 		in, out := &in.Headers, &out.Headers
@@ -364,6 +932,11 @@ func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(CanaryDNS)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -377,6 +950,23 @@ func (in *CanaryService) DeepCopy() *CanaryService {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryServicePort) DeepCopyInto(out *CanaryServicePort) {
+	*out = *in
+	out.TargetPort = in.TargetPort
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryServicePort.
+func (in *CanaryServicePort) DeepCopy() *CanaryServicePort {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryServicePort)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 	*out = *in
@@ -407,6 +997,11 @@ func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(CanaryCluster)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -435,6 +1030,31 @@ func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
 		}
 	}
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.AnalysisStartTime != nil {
+		in, out := &in.AnalysisStartTime, &out.AnalysisStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SuspendTime != nil {
+		in, out := &in.SuspendTime, &out.SuspendTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PromotionETA != nil {
+		in, out := &in.PromotionETA, &out.PromotionETA
+		*out = (*in).DeepCopy()
+	}
+	if in.RollbackStartTime != nil {
+		in, out := &in.RollbackStartTime, &out.RollbackStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRollbackDuration != nil {
+		in, out := &in.LastRollbackDuration, &out.LastRollbackDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.LastFailedTime != nil {
+		in, out := &in.LastFailedTime, &out.LastFailedTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]CanaryCondition, len(*in))
@@ -495,6 +1115,11 @@ func (in *CanaryWebhook) DeepCopyInto(out *CanaryWebhook) {
 			}
 		}
 	}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(CanaryWebhookJob)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -508,6 +1133,23 @@ func (in *CanaryWebhook) DeepCopy() *CanaryWebhook {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWebhookJob) DeepCopyInto(out *CanaryWebhookJob) {
+	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWebhookJob.
+func (in *CanaryWebhookJob) DeepCopy() *CanaryWebhookJob {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWebhookJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryWebhookPayload) DeepCopyInto(out *CanaryWebhookPayload) {
 	*out = *in
@@ -547,6 +1189,62 @@ func (in *CrossNamespaceObjectReference) DeepCopy() *CrossNamespaceObjectReferen
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomMetadata) DeepCopyInto(out *CustomMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomMetadata.
+func (in *CustomMetadata) DeepCopy() *CustomMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayHosts) DeepCopyInto(out *GatewayHosts) {
+	*out = *in
+	if in.Gateways != nil {
+		in, out := &in.Gateways, &out.Gateways
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayHosts.
+func (in *GatewayHosts) DeepCopy() *GatewayHosts {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayHosts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricTemplate) DeepCopyInto(out *MetricTemplate) {
 	*out = *in
@@ -575,6 +1273,32 @@ func (in *MetricTemplate) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTemplateBudget) DeepCopyInto(out *MetricTemplateBudget) {
+	*out = *in
+	if in.CostPerQuery != nil {
+		in, out := &in.CostPerQuery, &out.CostPerQuery
+		*out = new(float64)
+		**out = **in
+	}
+	if in.MaxCost != nil {
+		in, out := &in.MaxCost, &out.MaxCost
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTemplateBudget.
+func (in *MetricTemplateBudget) DeepCopy() *MetricTemplateBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTemplateBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricTemplateCondition) DeepCopyInto(out *MetricTemplateCondition) {
 	*out = *in
@@ -650,6 +1374,33 @@ func (in *MetricTemplateProvider) DeepCopyInto(out *MetricTemplateProvider) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.VaultRef != nil {
+		in, out := &in.VaultRef, &out.VaultRef
+		*out = new(MetricTemplateVaultRef)
+		**out = **in
+	}
+	if in.CAConfigMapRef != nil {
+		in, out := &in.CAConfigMapRef, &out.CAConfigMapRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(MetricTemplateRetry)
+		**out = **in
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		*out = new(MetricTemplateBudget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -663,10 +1414,61 @@ func (in *MetricTemplateProvider) DeepCopy() *MetricTemplateProvider {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTemplateRetry) DeepCopyInto(out *MetricTemplateRetry) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTemplateRetry.
+func (in *MetricTemplateRetry) DeepCopy() *MetricTemplateRetry {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTemplateRetry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTemplateSLI) DeepCopyInto(out *MetricTemplateSLI) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ErrorSelector != nil {
+		in, out := &in.ErrorSelector, &out.ErrorSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTemplateSLI.
+func (in *MetricTemplateSLI) DeepCopy() *MetricTemplateSLI {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTemplateSLI)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricTemplateSpec) DeepCopyInto(out *MetricTemplateSpec) {
 	*out = *in
 	in.Provider.DeepCopyInto(&out.Provider)
+	if in.SLI != nil {
+		in, out := &in.SLI, &out.SLI
+		*out = new(MetricTemplateSLI)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -702,3 +1504,35 @@ func (in *MetricTemplateStatus) DeepCopy() *MetricTemplateStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTemplateVaultRef) DeepCopyInto(out *MetricTemplateVaultRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTemplateVaultRef.
+func (in *MetricTemplateVaultRef) DeepCopy() *MetricTemplateVaultRef {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTemplateVaultRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionAffinity) DeepCopyInto(out *SessionAffinity) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionAffinity.
+func (in *SessionAffinity) DeepCopy() *SessionAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,5 @@
+package gloogateway
+
+const (
+	GroupName = "gateway.solo.io"
+)
@@ -0,0 +1,62 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	gloov1 "github.com/weaveworks/flagger/pkg/apis/gloo/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RouteTable is a specification for a Gloo RouteTable resource
+type RouteTable struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RouteTableSpec `json:"spec"`
+}
+
+type RouteTableSpec struct {
+	Routes []Route `json:"routes,omitempty"`
+}
+
+// Route is a collection of matchers and an action for routing the matching requests
+type Route struct {
+	Matchers []Matcher   `json:"matchers,omitempty"`
+	Action   RouteAction `json:"routeAction,omitempty"`
+	// Options hold the route plugins configuration e.g. timeouts, retries and transformations,
+	// the raw value is kept so that the options unknown to Flagger are not dropped on updates
+	Options *runtime.RawExtension `json:"options,omitempty"`
+}
+
+// Matcher selects the requests by path and headers
+type Matcher struct {
+	Prefix  string          `json:"prefix,omitempty"`
+	Headers []HeaderMatcher `json:"headers,omitempty"`
+}
+
+// HeaderMatcher matches a request header by exact value or regular expression
+type HeaderMatcher struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	Regex       bool   `json:"regex,omitempty"`
+	InvertMatch bool   `json:"invertMatch,omitempty"`
+}
+
+// RouteAction routes the requests to a single upstream or to an upstream group
+type RouteAction struct {
+	Single        *gloov1.Destination `json:"single,omitempty"`
+	UpstreamGroup *gloov1.ResourceRef `json:"upstreamGroup,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RouteTableList is a list of RouteTable resources
+type RouteTableList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []RouteTable `json:"items"`
+}
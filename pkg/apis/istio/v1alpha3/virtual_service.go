@@ -283,6 +283,18 @@ type Destination struct {
 	Port *PortSelector `json:"port,omitempty"`
 }
 
+// Describes the delegate VirtualService.
+// The delegate VirtualService must not set the hosts and gateways fields,
+// its routes are merged with the ones of the VirtualService that references it.
+type Delegate struct {
+	// Name specifies the name of the delegate VirtualService.
+	Name string `json:"name,omitempty"`
+
+	// Namespace specifies the namespace where the delegate VirtualService resides.
+	// By default, it is same to the root's.
+	Namespace string `json:"namespace,omitempty"`
+}
+
 // Describes match conditions and actions for routing HTTP/1.1, HTTP2, and
 // gRPC traffic. See VirtualService for usage examples.
 type HTTPRoute struct {
@@ -298,6 +310,12 @@ type HTTPRoute struct {
 	// service version determine the proportion of traffic it receives.
 	Route []DestinationWeight `json:"route,omitempty"`
 
+	// Delegate is used to specify the particular VirtualService which
+	// can be used to define delegate HTTPRoute. It can be set only when
+	// `Route` and `Redirect` are empty, and the route rules of the
+	// delegate VirtualService will be merged with that in the current one.
+	Delegate *Delegate `json:"delegate,omitempty"`
+
 	// A http rule can either redirect or forward (default) traffic. If
 	// traffic passthrough option is specified in the rule,
 	// route/redirect will be ignored. The redirect primitive can be used to
@@ -325,6 +343,11 @@ type HTTPRoute struct {
 	// destination.
 	Mirror *Destination `json:"mirror,omitempty"`
 
+	// Percentage of the traffic to be mirrored by the mirror field.
+	// If this field is absent, all the traffic (100%) will be mirrored.
+	// Max value is 100.
+	MirrorPercentage *Percent `json:"mirrorPercentage,omitempty"`
+
 	// Cross-Origin Resource Sharing policy (CORS). Refer to
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/CORS
 	// for further details about cross origin resource sharing.
@@ -442,6 +465,20 @@ type HTTPMatchRequest struct {
 	// **Note:** The keys `uri`, `scheme`, `method`, and `authority` will be ignored.
 	Headers map[string]v1alpha1.StringMatch `json:"headers,omitempty"`
 
+	// Query parameters for matching.
+	//
+	// Ex:
+	// - For a query parameter like "?key=true", the map key would be "key" and
+	//   the string match could be defined as `exact: "true"`.
+	// - For a query parameter like "?key", the map key would be "key" and the
+	//   string match could be defined as `exact: ""`.
+	// - For a query parameter like "?key=123", the map key would be "key" and the
+	//   string match could be defined as `regex: "\d+$"`. Note that this
+	//   configuration will only match values like "123" but not "a123" or "123a".
+	//
+	// **Note:** `prefix` matching is currently not supported.
+	QueryParams map[string]v1alpha1.StringMatch `json:"queryParams,omitempty"`
+
 	// Specifies the ports on the host that is being addressed. Many services
 	// only expose a single port or label ports with the protocols they support,
 	// in these cases it is not required to explicitly select the port.
@@ -469,6 +506,9 @@ type DestinationWeight struct {
 	// If there is only destination in a rule, the weight value is assumed to
 	// be 100.
 	Weight int `json:"weight"`
+
+	// Header manipulation rules applied to the requests and responses of this destination
+	Headers *Headers `json:"headers,omitempty"`
 }
 
 // PortSelector specifies the number of a port to be used for
@@ -514,10 +554,8 @@ type TCPRoute struct {
 	Match []L4MatchAttributes `json:"match,omitempty"`
 
 	// The destination to which the connection should be forwarded to.
-	// Currently, only one destination is allowed for TCP services. When TCP
-	// weighted routing support is introduced in Envoy, multiple destinations
-	// with weights can be specified.
-	Route DestinationWeight `json:"route"`
+	// The connections are distributed across the destinations by weight.
+	Route []DestinationWeight `json:"route"`
 }
 
 // L4 connection match attributes. Note that L4 connection matching support
@@ -655,6 +693,9 @@ type HTTPRetry struct {
 	// <https://www.envoyproxy.io/docs/envoy/latest/configuration/http_filters/router_filter#x-envoy-retry-on>
 	// and <https://www.envoyproxy.io/docs/envoy/latest/configuration/http_filters/router_filter#x-envoy-retry-grpc-on>
 	RetryOn string `json:"retryOn,omitempty"`
+
+	// Flag to specify whether the retries should retry to other localities.
+	RetryRemoteLocalities *bool `json:"retryRemoteLocalities,omitempty"`
 }
 
 // Describes the Cross-Origin Resource Sharing (CORS) policy, for a given
@@ -695,6 +736,12 @@ type CorsPolicy struct {
 	// header. Wildcard * will allow all origins.
 	AllowOrigin []string `json:"allowOrigin,omitempty"`
 
+	// String patterns that match allowed origins. An origin is allowed if
+	// any of the string matchers match. If a match is found, then the
+	// outgoing Access-Control-Allow-Origin would be set to the origin as
+	// provided by the client.
+	AllowOrigins []v1alpha1.StringMatch `json:"allowOrigins,omitempty"`
+
 	// List of HTTP methods allowed to access the resource. The content will
 	// be serialized into the Access-Control-Allow-Methods header.
 	AllowMethods []string `json:"allowMethods,omitempty"`
@@ -811,6 +858,11 @@ type InjectAbort struct {
 	HttpStatus int `json:"httpStatus"`
 }
 
+// Percent specifies a percentage in the range of [0.0, 100.0].
+type Percent struct {
+	Value float64 `json:"value,omitempty"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // VirtualServiceList is a list of VirtualService resources
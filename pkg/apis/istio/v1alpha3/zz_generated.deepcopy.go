@@ -80,6 +80,11 @@ func (in *CorsPolicy) DeepCopyInto(out *CorsPolicy) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.AllowOrigins != nil {
+		in, out := &in.AllowOrigins, &out.AllowOrigins
+		*out = make([]v1alpha1.StringMatch, len(*in))
+		copy(*out, *in)
+	}
 	if in.AllowMethods != nil {
 		in, out := &in.AllowMethods, &out.AllowMethods
 		*out = make([]string, len(*in))
@@ -108,6 +113,22 @@ func (in *CorsPolicy) DeepCopy() *CorsPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Delegate) DeepCopyInto(out *Delegate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Delegate.
+func (in *Delegate) DeepCopy() *Delegate {
+	if in == nil {
+		return nil
+	}
+	out := new(Delegate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Destination) DeepCopyInto(out *Destination) {
 	*out = *in
@@ -221,6 +242,11 @@ func (in *DestinationRuleSpec) DeepCopy() *DestinationRuleSpec {
 func (in *DestinationWeight) DeepCopyInto(out *DestinationWeight) {
 	*out = *in
 	in.Destination.DeepCopyInto(&out.Destination)
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = new(Headers)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -306,6 +332,13 @@ func (in *HTTPMatchRequest) DeepCopyInto(out *HTTPMatchRequest) {
 			(*out)[key] = val
 		}
 	}
+	if in.QueryParams != nil {
+		in, out := &in.QueryParams, &out.QueryParams
+		*out = make(map[string]v1alpha1.StringMatch, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.SourceLabels != nil {
 		in, out := &in.SourceLabels, &out.SourceLabels
 		*out = make(map[string]string, len(*in))
@@ -350,6 +383,11 @@ func (in *HTTPRedirect) DeepCopy() *HTTPRedirect {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPRetry) DeepCopyInto(out *HTTPRetry) {
 	*out = *in
+	if in.RetryRemoteLocalities != nil {
+		in, out := &in.RetryRemoteLocalities, &out.RetryRemoteLocalities
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -396,6 +434,11 @@ func (in *HTTPRoute) DeepCopyInto(out *HTTPRoute) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Delegate != nil {
+		in, out := &in.Delegate, &out.Delegate
+		*out = new(Delegate)
+		**out = **in
+	}
 	if in.Redirect != nil {
 		in, out := &in.Redirect, &out.Redirect
 		*out = new(HTTPRedirect)
@@ -409,7 +452,7 @@ func (in *HTTPRoute) DeepCopyInto(out *HTTPRoute) {
 	if in.Retries != nil {
 		in, out := &in.Retries, &out.Retries
 		*out = new(HTTPRetry)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Fault != nil {
 		in, out := &in.Fault, &out.Fault
@@ -421,6 +464,11 @@ func (in *HTTPRoute) DeepCopyInto(out *HTTPRoute) {
 		*out = new(Destination)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MirrorPercentage != nil {
+		in, out := &in.MirrorPercentage, &out.MirrorPercentage
+		*out = new(Percent)
+		**out = **in
+	}
 	if in.CorsPolicy != nil {
 		in, out := &in.CorsPolicy, &out.CorsPolicy
 		*out = new(CorsPolicy)
@@ -628,6 +676,22 @@ func (in *OutlierDetection) DeepCopy() *OutlierDetection {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Percent) DeepCopyInto(out *Percent) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Percent.
+func (in *Percent) DeepCopy() *Percent {
+	if in == nil {
+		return nil
+	}
+	out := new(Percent)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PortSelector) DeepCopyInto(out *PortSelector) {
 	*out = *in
@@ -719,7 +783,13 @@ func (in *TCPRoute) DeepCopyInto(out *TCPRoute) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	in.Route.DeepCopyInto(&out.Route)
+	if in.Route != nil {
+		in, out := &in.Route, &out.Route
+		*out = make([]DestinationWeight, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -0,0 +1,89 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PausedReplicasAnnotation scales the target of a ScaledObject to the given replicas and stops the autoscaling
+const PausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScaledObject is a specification for a KEDA ScaledObject resource,
+// KEDA manages an HPA for the scale target from the triggers
+type ScaledObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScaledObjectSpec   `json:"spec"`
+	Status ScaledObjectStatus `json:"status,omitempty"`
+}
+
+// ScaledObjectSpec is the scaling configuration of a workload
+type ScaledObjectSpec struct {
+	ScaleTargetRef   *ScaleTarget    `json:"scaleTargetRef"`
+	PollingInterval  *int32          `json:"pollingInterval,omitempty"`
+	CooldownPeriod   *int32          `json:"cooldownPeriod,omitempty"`
+	IdleReplicaCount *int32          `json:"idleReplicaCount,omitempty"`
+	MinReplicaCount  *int32          `json:"minReplicaCount,omitempty"`
+	MaxReplicaCount  *int32          `json:"maxReplicaCount,omitempty"`
+	Advanced         *AdvancedConfig `json:"advanced,omitempty"`
+	Triggers         []ScaleTriggers `json:"triggers"`
+	Fallback         *Fallback       `json:"fallback,omitempty"`
+}
+
+// ScaleTarget references the scaled workload
+type ScaleTarget struct {
+	Name                   string `json:"name"`
+	APIVersion             string `json:"apiVersion,omitempty"`
+	Kind                   string `json:"kind,omitempty"`
+	EnvSourceContainerName string `json:"envSourceContainerName,omitempty"`
+}
+
+// AdvancedConfig holds the settings of the HPA managed by KEDA
+type AdvancedConfig struct {
+	HorizontalPodAutoscalerConfig *HorizontalPodAutoscalerConfig `json:"horizontalPodAutoscalerConfig,omitempty"`
+	RestoreToOriginalReplicaCount bool                           `json:"restoreToOriginalReplicaCount,omitempty"`
+}
+
+// HorizontalPodAutoscalerConfig sets the name of the HPA managed by KEDA
+type HorizontalPodAutoscalerConfig struct {
+	Name string `json:"name,omitempty"`
+}
+
+// ScaleTriggers is a scaler and its metadata
+type ScaleTriggers struct {
+	Type              string               `json:"type"`
+	Name              string               `json:"name,omitempty"`
+	Metadata          map[string]string    `json:"metadata"`
+	AuthenticationRef *ScaledObjectAuthRef `json:"authenticationRef,omitempty"`
+	MetricType        string               `json:"metricType,omitempty"`
+}
+
+// ScaledObjectAuthRef references the TriggerAuthentication of a trigger
+type ScaledObjectAuthRef struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// Fallback is the replicas count used when a scaler fails
+type Fallback struct {
+	FailureThreshold int32 `json:"failureThreshold"`
+	Replicas         int32 `json:"replicas"`
+}
+
+// ScaledObjectStatus is the status reported by KEDA
+type ScaledObjectStatus struct {
+	HpaName string `json:"hpaName,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScaledObjectList is a list of ScaledObject resources
+type ScaledObjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ScaledObject `json:"items"`
+}
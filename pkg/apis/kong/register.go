@@ -0,0 +1,5 @@
+package kong
+
+const (
+	GroupName = "configuration.konghq.com"
+)
@@ -0,0 +1,43 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KongPlugin is a specification for a Kong plugin resource
+type KongPlugin struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// PluginName is the name of the Kong plugin e.g. canary
+	PluginName string `json:"plugin"`
+	// Disabled turns off the plugin without removing it from the routes
+	Disabled bool `json:"disabled,omitempty"`
+	// Config holds the canary plugin configuration
+	Config CanaryConfig `json:"config,omitempty"`
+}
+
+// CanaryConfig is the configuration of the Kong canary plugin
+type CanaryConfig struct {
+	// Percentage of the requests routed to the canary upstream
+	Percentage int `json:"percentage"`
+	// UpstreamHost is the host name of the canary upstream
+	UpstreamHost string `json:"upstream_host,omitempty"`
+	// UpstreamPort is the port of the canary upstream
+	UpstreamPort int32 `json:"upstream_port,omitempty"`
+	// Hash is the entity used to pin the requests to the canary e.g. none, consumer or ip
+	Hash string `json:"hash,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KongPluginList is a list of KongPlugin resources
+type KongPluginList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []KongPlugin `json:"items"`
+}
@@ -0,0 +1,76 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TrafficRoute is a specification for a Kuma traffic route resource,
+// the Kuma policies are cluster scoped and belong to a mesh
+type TrafficRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Mesh the route belongs to, Kuma defaults to the default mesh when omitted
+	Mesh string `json:"mesh,omitempty"`
+
+	Spec TrafficRouteSpec `json:"spec"`
+}
+
+// TrafficRouteSpec routes the traffic from the source data planes to the destination services
+type TrafficRouteSpec struct {
+	Sources      []Selector       `json:"sources"`
+	Destinations []Selector       `json:"destinations"`
+	Conf         TrafficRouteConf `json:"conf"`
+}
+
+// Selector matches the data planes by their tags
+type Selector struct {
+	Match map[string]string `json:"match"`
+}
+
+// TrafficRouteConf is the weighted split of the matching traffic,
+// the HTTP routes are evaluated in order before the default split
+type TrafficRouteConf struct {
+	Split []TrafficRouteSplit `json:"split,omitempty"`
+	HTTP  []TrafficRouteHTTP  `json:"http,omitempty"`
+}
+
+// TrafficRouteHTTP splits the HTTP requests matching the method, path and headers
+type TrafficRouteHTTP struct {
+	Match TrafficRouteHTTPMatch `json:"match"`
+	Split []TrafficRouteSplit   `json:"split"`
+}
+
+// TrafficRouteHTTPMatch matches the requests on all the set fields
+type TrafficRouteHTTPMatch struct {
+	Method  *StringMatch           `json:"method,omitempty"`
+	Path    *StringMatch           `json:"path,omitempty"`
+	Headers map[string]StringMatch `json:"headers,omitempty"`
+}
+
+// StringMatch matches a value by prefix, exact value or RE2 regular expression
+type StringMatch struct {
+	Prefix string `json:"prefix,omitempty"`
+	Exact  string `json:"exact,omitempty"`
+	Regex  string `json:"regex,omitempty"`
+}
+
+// TrafficRouteSplit routes a share of the traffic to the data planes matching the destination tags
+type TrafficRouteSplit struct {
+	Weight      uint32            `json:"weight"`
+	Destination map[string]string `json:"destination"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TrafficRouteList is a list of TrafficRoute resources
+type TrafficRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TrafficRoute `json:"items"`
+}
@@ -0,0 +1,5 @@
+package monitoring
+
+const (
+	GroupName = "monitoring.coreos.com"
+)
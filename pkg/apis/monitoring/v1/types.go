@@ -0,0 +1,238 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceMonitor is a specification for a Prometheus Operator ServiceMonitor resource
+type ServiceMonitor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServiceMonitorSpec `json:"spec"`
+}
+
+// ServiceMonitorSpec contains the specification of the services selected for target discovery
+type ServiceMonitorSpec struct {
+	// The label to use to retrieve the job name from
+	JobLabel string `json:"jobLabel,omitempty"`
+
+	// TargetLabels transfers labels on the Kubernetes Service onto the target
+	TargetLabels []string `json:"targetLabels,omitempty"`
+
+	// PodTargetLabels transfers labels on the Kubernetes Pod onto the target
+	PodTargetLabels []string `json:"podTargetLabels,omitempty"`
+
+	// A list of endpoints allowed as part of this ServiceMonitor
+	Endpoints []Endpoint `json:"endpoints"`
+
+	// Selector to select Endpoints objects
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Selector to select which namespaces the Endpoints objects are discovered from
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector,omitempty"`
+
+	// SampleLimit defines per-scrape limit on number of scraped samples that will be accepted
+	SampleLimit uint64 `json:"sampleLimit,omitempty"`
+}
+
+// Endpoint defines a scrapeable endpoint serving Prometheus metrics
+type Endpoint struct {
+	// Name of the service port this endpoint refers to
+	Port string `json:"port,omitempty"`
+
+	// Name or number of the pod port this endpoint refers to
+	TargetPort *intstr.IntOrString `json:"targetPort,omitempty"`
+
+	// HTTP path to scrape for metrics
+	Path string `json:"path,omitempty"`
+
+	// HTTP scheme to use for scraping
+	Scheme string `json:"scheme,omitempty"`
+
+	// Optional HTTP URL parameters
+	Params map[string][]string `json:"params,omitempty"`
+
+	// Interval at which metrics should be scraped
+	Interval string `json:"interval,omitempty"`
+
+	// Timeout after which the scrape is ended
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+
+	// TLS configuration to use when scraping the endpoint
+	TLSConfig *TLSConfig `json:"tlsConfig,omitempty"`
+
+	// File to read bearer token for scraping targets
+	BearerTokenFile string `json:"bearerTokenFile,omitempty"`
+
+	// Secret to mount to read bearer token for scraping targets
+	BearerTokenSecret corev1.SecretKeySelector `json:"bearerTokenSecret,omitempty"`
+
+	// HonorLabels chooses the metric's labels on collisions with target labels
+	HonorLabels bool `json:"honorLabels,omitempty"`
+
+	// BasicAuth allow an endpoint to authenticate over basic authentication
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+
+	// MetricRelabelConfigs to apply to samples before ingestion
+	MetricRelabelConfigs []*RelabelConfig `json:"metricRelabelings,omitempty"`
+
+	// RelabelConfigs to apply to samples before scraping
+	RelabelConfigs []*RelabelConfig `json:"relabelings,omitempty"`
+
+	// ProxyURL eg http://proxyserver:2195 Directs scrapes to proxy through this endpoint
+	ProxyURL *string `json:"proxyUrl,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceMonitorList is a list of ServiceMonitor resources
+type ServiceMonitorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ServiceMonitor `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodMonitor is a specification for a Prometheus Operator PodMonitor resource
+type PodMonitor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PodMonitorSpec `json:"spec"`
+}
+
+// PodMonitorSpec contains the specification of the pods selected for target discovery
+type PodMonitorSpec struct {
+	// The label to use to retrieve the job name from
+	JobLabel string `json:"jobLabel,omitempty"`
+
+	// PodTargetLabels transfers labels on the Kubernetes Pod onto the target
+	PodTargetLabels []string `json:"podTargetLabels,omitempty"`
+
+	// A list of endpoints allowed as part of this PodMonitor
+	PodMetricsEndpoints []PodMetricsEndpoint `json:"podMetricsEndpoints"`
+
+	// Selector to select Pod objects
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Selector to select which namespaces the Pod objects are discovered from
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector,omitempty"`
+
+	// SampleLimit defines per-scrape limit on number of scraped samples that will be accepted
+	SampleLimit uint64 `json:"sampleLimit,omitempty"`
+}
+
+// PodMetricsEndpoint defines a scrapeable endpoint of a Kubernetes Pod serving Prometheus metrics
+type PodMetricsEndpoint struct {
+	// Name of the pod port this endpoint refers to
+	Port string `json:"port,omitempty"`
+
+	// Name or number of the pod port this endpoint refers to
+	TargetPort *intstr.IntOrString `json:"targetPort,omitempty"`
+
+	// HTTP path to scrape for metrics
+	Path string `json:"path,omitempty"`
+
+	// HTTP scheme to use for scraping
+	Scheme string `json:"scheme,omitempty"`
+
+	// Optional HTTP URL parameters
+	Params map[string][]string `json:"params,omitempty"`
+
+	// Interval at which metrics should be scraped
+	Interval string `json:"interval,omitempty"`
+
+	// Timeout after which the scrape is ended
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+
+	// HonorLabels chooses the metric's labels on collisions with target labels
+	HonorLabels bool `json:"honorLabels,omitempty"`
+
+	// MetricRelabelConfigs to apply to samples before ingestion
+	MetricRelabelConfigs []*RelabelConfig `json:"metricRelabelings,omitempty"`
+
+	// RelabelConfigs to apply to samples before scraping
+	RelabelConfigs []*RelabelConfig `json:"relabelings,omitempty"`
+
+	// ProxyURL eg http://proxyserver:2195 Directs scrapes to proxy through this endpoint
+	ProxyURL *string `json:"proxyUrl,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodMonitorList is a list of PodMonitor resources
+type PodMonitorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PodMonitor `json:"items"`
+}
+
+// NamespaceSelector is a selector for selecting either all namespaces or a list of namespaces
+type NamespaceSelector struct {
+	// Boolean describing whether all namespaces are selected in contrast to a list restricting them
+	Any bool `json:"any,omitempty"`
+
+	// List of namespace names
+	MatchNames []string `json:"matchNames,omitempty"`
+}
+
+// TLSConfig specifies TLS configuration parameters
+type TLSConfig struct {
+	// Path to the CA cert in the Prometheus container to use for the targets
+	CAFile string `json:"caFile,omitempty"`
+
+	// Path to the client cert file in the Prometheus container for the targets
+	CertFile string `json:"certFile,omitempty"`
+
+	// Path to the client key file in the Prometheus container for the targets
+	KeyFile string `json:"keyFile,omitempty"`
+
+	// Used to verify the hostname for the targets
+	ServerName string `json:"serverName,omitempty"`
+
+	// Disable target certificate validation
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// BasicAuth allow an endpoint to authenticate over basic authentication
+type BasicAuth struct {
+	// The secret that contains the username for authenticate
+	Username corev1.SecretKeySelector `json:"username,omitempty"`
+
+	// The secret that contains the password for authenticate
+	Password corev1.SecretKeySelector `json:"password,omitempty"`
+}
+
+// RelabelConfig allows dynamic rewriting of the label set
+type RelabelConfig struct {
+	// The source labels select values from existing labels
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+
+	// Separator placed between concatenated source label values
+	Separator string `json:"separator,omitempty"`
+
+	// Label to which the resulting value is written in a replace action
+	TargetLabel string `json:"targetLabel,omitempty"`
+
+	// Regular expression against which the extracted value is matched
+	Regex string `json:"regex,omitempty"`
+
+	// Modulus to take of the hash of the source label values
+	Modulus uint64 `json:"modulus,omitempty"`
+
+	// Replacement value against which a regex replace is performed if the regular expression matches
+	Replacement string `json:"replacement,omitempty"`
+
+	// Action to perform based on regex matching
+	Action string `json:"action,omitempty"`
+}
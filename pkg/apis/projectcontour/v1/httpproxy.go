@@ -47,7 +47,7 @@ type Include struct {
 }
 
 // Condition are policies that are applied on top of HTTPProxies.
-// One of Prefix or Header must be provided.
+// One of Prefix, Header or QueryParameter must be provided.
 type Condition struct {
 	// Prefix defines a prefix match for a request.
 	// +optional
@@ -56,6 +56,50 @@ type Condition struct {
 	// Header specifies the header condition to match.
 	// +optional
 	Header *HeaderCondition `json:"header,omitempty"`
+
+	// QueryParameter specifies the query parameter condition to match.
+	// +optional
+	QueryParameter *QueryParameterCondition `json:"queryParameter,omitempty"`
+}
+
+// QueryParameterCondition specifies how to conditionally match against HTTP
+// query parameters. The Name field is required, only one of Exact, Prefix,
+// Suffix, Regex, Contains and Present may be set.
+type QueryParameterCondition struct {
+	// Name is the name of the query parameter to match against. Name is required.
+	// Query parameter names are case insensitive.
+	Name string `json:"name"`
+
+	// Exact specifies a string that the query parameter value must be equal to.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Prefix defines a prefix match for the query parameter value.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Suffix defines a suffix match for the query parameter value.
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+
+	// Regex specifies a regular expression pattern that must match the query
+	// parameter value.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// Contains specifies a substring that must be present in
+	// the query parameter value.
+	// +optional
+	Contains string `json:"contains,omitempty"`
+
+	// IgnoreCase specifies that string matching should be case insensitive.
+	// +optional
+	IgnoreCase bool `json:"ignoreCase,omitempty"`
+
+	// Present specifies that condition is true when the named query parameter
+	// is present in the HTTP request.
+	// +optional
+	Present bool `json:"present,omitempty"`
 }
 
 // HeaderCondition specifies the header condition to match.
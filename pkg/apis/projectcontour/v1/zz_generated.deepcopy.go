@@ -32,6 +32,11 @@ func (in *Condition) DeepCopyInto(out *Condition) {
 		*out = new(HeaderCondition)
 		**out = **in
 	}
+	if in.QueryParameter != nil {
+		in, out := &in.QueryParameter, &out.QueryParameter
+		*out = new(QueryParameterCondition)
+		**out = **in
+	}
 	return
 }
 
@@ -280,6 +285,22 @@ func (in *PathRewritePolicy) DeepCopy() *PathRewritePolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryParameterCondition) DeepCopyInto(out *QueryParameterCondition) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryParameterCondition.
+func (in *QueryParameterCondition) DeepCopy() *QueryParameterCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryParameterCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplacePrefix) DeepCopyInto(out *ReplacePrefix) {
 	*out = *in
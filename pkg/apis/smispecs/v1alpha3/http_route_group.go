@@ -0,0 +1,58 @@
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HTTPRouteGroup is used to describe HTTP/1 and HTTP/2 traffic,
+// it enumerates the routes that can be served by an application
+type HTTPRouteGroup struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Spec is the specification of the routes
+	// +optional
+	Spec HTTPRouteGroupSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// HTTPRouteGroupSpec is the specification for a HTTPRouteGroup
+type HTTPRouteGroupSpec struct {
+	// Matches is a list of HTTPMatch to match traffic
+	Matches []HTTPMatch `json:"matches,omitempty"`
+}
+
+// HTTPMatch defines an individual route for HTTP traffic
+type HTTPMatch struct {
+	// Name is the name of the match for referencing in a TrafficTarget
+	Name string `json:"name,omitempty"`
+
+	// Methods for this match
+	// +optional
+	Methods []string `json:"methods,omitempty"`
+
+	// PathRegex is a regular expression defining the route
+	// +optional
+	PathRegex string `json:"pathRegex,omitempty"`
+
+	// Headers is a list of headers used to match HTTP traffic,
+	// the header values are regular expressions
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HTTPRouteGroupList is a list of HTTPRouteGroup resources
+type HTTPRouteGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []HTTPRouteGroup `json:"items"`
+}
@@ -0,0 +1,335 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	hpav1 "k8s.io/api/autoscaling/v2beta1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+var (
+	deploymentKind  = appsv1.SchemeGroupVersion.WithKind("Deployment")
+	daemonSetKind   = appsv1.SchemeGroupVersion.WithKind("DaemonSet")
+	cronJobKind     = batchv1beta1.SchemeGroupVersion.WithKind("CronJob")
+	autoscalerKind  = hpav1.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler")
+	serviceKind     = corev1.SchemeGroupVersion.WithKind("Service")
+	configMapKind   = corev1.SchemeGroupVersion.WithKind("ConfigMap")
+	secretKind      = corev1.SchemeGroupVersion.WithKind("Secret")
+	canaryKind      = flaggerv1.SchemeGroupVersion.WithKind(flaggerv1.CanaryKind)
+	controlledKinds = []schema.GroupVersionKind{
+		deploymentKind, daemonSetKind, cronJobKind, autoscalerKind, serviceKind, configMapKind, secretKind,
+	}
+)
+
+// Export returns a List manifest with the canary, including its analysis status,
+// and the primary workloads, autoscalers, services, config maps and secrets generated for it.
+// The service mesh and ingress objects are not exported, Flagger regenerates them
+// from the canary spec and restores the traffic weight from the status.
+func Export(kubeClient kubernetes.Interface, flaggerClient clientset.Interface, name string, namespace string) (*corev1.List, error) {
+	cd, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("canary %s.%s query error %v", name, namespace, err)
+	}
+
+	list := &corev1.List{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "List"},
+	}
+
+	objects := []runtime.Object{cd}
+	for _, kind := range controlledKinds {
+		controlled, err := listControlled(kubeClient, cd, kind)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, controlled...)
+	}
+
+	cd.SetGroupVersionKind(canaryKind)
+	clearServerFields(&cd.ObjectMeta)
+
+	for _, obj := range objects {
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("%s encoding error %v", obj.GetObjectKind().GroupVersionKind().Kind, err)
+		}
+		list.Items = append(list.Items, runtime.RawExtension{Raw: raw})
+	}
+
+	return list, nil
+}
+
+// listControlled returns the objects of a kind controlled by the canary,
+// the server generated fields are removed so the objects can be created in another cluster
+func listControlled(kubeClient kubernetes.Interface, cd *flaggerv1.Canary, kind schema.GroupVersionKind) ([]runtime.Object, error) {
+	var objects []runtime.Object
+	var err error
+	switch kind {
+	case deploymentKind:
+		var list *appsv1.DeploymentList
+		if list, err = kubeClient.AppsV1().Deployments(cd.Namespace).List(metav1.ListOptions{}); err == nil {
+			for i := range list.Items {
+				list.Items[i].Status = appsv1.DeploymentStatus{}
+				objects = append(objects, &list.Items[i])
+			}
+		}
+	case daemonSetKind:
+		var list *appsv1.DaemonSetList
+		if list, err = kubeClient.AppsV1().DaemonSets(cd.Namespace).List(metav1.ListOptions{}); err == nil {
+			for i := range list.Items {
+				list.Items[i].Status = appsv1.DaemonSetStatus{}
+				objects = append(objects, &list.Items[i])
+			}
+		}
+	case cronJobKind:
+		var list *batchv1beta1.CronJobList
+		if list, err = kubeClient.BatchV1beta1().CronJobs(cd.Namespace).List(metav1.ListOptions{}); err == nil {
+			for i := range list.Items {
+				list.Items[i].Status = batchv1beta1.CronJobStatus{}
+				objects = append(objects, &list.Items[i])
+			}
+		}
+	case autoscalerKind:
+		var list *hpav1.HorizontalPodAutoscalerList
+		if list, err = kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers(cd.Namespace).List(metav1.ListOptions{}); err == nil {
+			for i := range list.Items {
+				list.Items[i].Status = hpav1.HorizontalPodAutoscalerStatus{}
+				objects = append(objects, &list.Items[i])
+			}
+		}
+	case serviceKind:
+		var list *corev1.ServiceList
+		if list, err = kubeClient.CoreV1().Services(cd.Namespace).List(metav1.ListOptions{}); err == nil {
+			for i := range list.Items {
+				// the cluster IP is allocated by the new cluster
+				list.Items[i].Spec.ClusterIP = ""
+				list.Items[i].Status = corev1.ServiceStatus{}
+				objects = append(objects, &list.Items[i])
+			}
+		}
+	case configMapKind:
+		var list *corev1.ConfigMapList
+		if list, err = kubeClient.CoreV1().ConfigMaps(cd.Namespace).List(metav1.ListOptions{}); err == nil {
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+		}
+	case secretKind:
+		var list *corev1.SecretList
+		if list, err = kubeClient.CoreV1().Secrets(cd.Namespace).List(metav1.ListOptions{}); err == nil {
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s list error %v", kind.Kind, err)
+	}
+
+	var controlled []runtime.Object
+	for _, obj := range objects {
+		meta, err := metaAccessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		if !metav1.IsControlledBy(meta, cd) {
+			continue
+		}
+		clearServerFields(meta)
+		obj.GetObjectKind().SetGroupVersionKind(kind)
+		controlled = append(controlled, obj)
+	}
+	return controlled, nil
+}
+
+// Import creates the canary of an exported List manifest if it doesn't exist and restores its analysis status,
+// then creates or updates the exported objects with the owner references set to the imported canary.
+// The existing services are kept as they are, Flagger reconciles them from the canary spec.
+func Import(kubeClient kubernetes.Interface, flaggerClient clientset.Interface, list *corev1.List) (*flaggerv1.Canary, error) {
+	var exported *flaggerv1.Canary
+	var objects []runtime.Object
+	for _, item := range list.Items {
+		obj, err := decode(item.Raw)
+		if err != nil {
+			return nil, err
+		}
+		if cd, ok := obj.(*flaggerv1.Canary); ok {
+			if exported != nil {
+				return nil, fmt.Errorf("manifest contains more than one canary")
+			}
+			exported = cd
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	if exported == nil {
+		return nil, fmt.Errorf("manifest contains no canary")
+	}
+
+	cd, err := importCanary(flaggerClient, exported)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range objects {
+		meta, err := metaAccessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		clearServerFields(meta)
+		meta.SetNamespace(cd.Namespace)
+		meta.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(cd, canaryKind)})
+
+		if err := importObject(kubeClient, obj); err != nil {
+			return nil, fmt.Errorf("%s %s.%s import error %v",
+				obj.GetObjectKind().GroupVersionKind().Kind, meta.GetName(), meta.GetNamespace(), err)
+		}
+	}
+
+	return cd, nil
+}
+
+// importCanary creates the canary if it doesn't exist, the spec of an existing canary is left unchanged
+// so the manifests applied to the rebuilt cluster take precedence
+func importCanary(flaggerClient clientset.Interface, exported *flaggerv1.Canary) (*flaggerv1.Canary, error) {
+	name, namespace := exported.Name, exported.Namespace
+	cd, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		newCanary := exported.DeepCopy()
+		clearServerFields(&newCanary.ObjectMeta)
+		cd, err = flaggerClient.FlaggerV1beta1().Canaries(namespace).Create(newCanary)
+		if err != nil {
+			return nil, fmt.Errorf("canary %s.%s create error %v", name, namespace, err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("canary %s.%s query error %v", name, namespace, err)
+	}
+
+	cdCopy := cd.DeepCopy()
+	cdCopy.Status = exported.Status
+	cd, err = flaggerClient.FlaggerV1beta1().Canaries(namespace).UpdateStatus(cdCopy)
+	if err != nil {
+		return nil, fmt.Errorf("canary %s.%s status update error %v", name, namespace, err)
+	}
+	return cd, nil
+}
+
+func importObject(kubeClient kubernetes.Interface, obj runtime.Object) error {
+	var err error
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		client := kubeClient.AppsV1().Deployments(o.Namespace)
+		if _, err = client.Create(o); errors.IsAlreadyExists(err) {
+			var current *appsv1.Deployment
+			if current, err = client.Get(o.Name, metav1.GetOptions{}); err == nil {
+				o.ResourceVersion = current.ResourceVersion
+				_, err = client.Update(o)
+			}
+		}
+	case *appsv1.DaemonSet:
+		client := kubeClient.AppsV1().DaemonSets(o.Namespace)
+		if _, err = client.Create(o); errors.IsAlreadyExists(err) {
+			var current *appsv1.DaemonSet
+			if current, err = client.Get(o.Name, metav1.GetOptions{}); err == nil {
+				o.ResourceVersion = current.ResourceVersion
+				_, err = client.Update(o)
+			}
+		}
+	case *batchv1beta1.CronJob:
+		client := kubeClient.BatchV1beta1().CronJobs(o.Namespace)
+		if _, err = client.Create(o); errors.IsAlreadyExists(err) {
+			var current *batchv1beta1.CronJob
+			if current, err = client.Get(o.Name, metav1.GetOptions{}); err == nil {
+				o.ResourceVersion = current.ResourceVersion
+				_, err = client.Update(o)
+			}
+		}
+	case *hpav1.HorizontalPodAutoscaler:
+		client := kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers(o.Namespace)
+		if _, err = client.Create(o); errors.IsAlreadyExists(err) {
+			var current *hpav1.HorizontalPodAutoscaler
+			if current, err = client.Get(o.Name, metav1.GetOptions{}); err == nil {
+				o.ResourceVersion = current.ResourceVersion
+				_, err = client.Update(o)
+			}
+		}
+	case *corev1.Service:
+		if _, err = kubeClient.CoreV1().Services(o.Namespace).Create(o); errors.IsAlreadyExists(err) {
+			err = nil
+		}
+	case *corev1.ConfigMap:
+		client := kubeClient.CoreV1().ConfigMaps(o.Namespace)
+		if _, err = client.Create(o); errors.IsAlreadyExists(err) {
+			_, err = client.Update(o)
+		}
+	case *corev1.Secret:
+		client := kubeClient.CoreV1().Secrets(o.Namespace)
+		if _, err = client.Create(o); errors.IsAlreadyExists(err) {
+			_, err = client.Update(o)
+		}
+	}
+	return err
+}
+
+// decode returns the typed object of a manifest item based on its kind
+func decode(raw []byte) (runtime.Object, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("manifest item decoding error %v", err)
+	}
+
+	var obj runtime.Object
+	switch typeMeta.GroupVersionKind() {
+	case canaryKind:
+		obj = &flaggerv1.Canary{}
+	case deploymentKind:
+		obj = &appsv1.Deployment{}
+	case daemonSetKind:
+		obj = &appsv1.DaemonSet{}
+	case cronJobKind:
+		obj = &batchv1beta1.CronJob{}
+	case autoscalerKind:
+		obj = &hpav1.HorizontalPodAutoscaler{}
+	case serviceKind:
+		obj = &corev1.Service{}
+	case configMapKind:
+		obj = &corev1.ConfigMap{}
+	case secretKind:
+		obj = &corev1.Secret{}
+	default:
+		return nil, fmt.Errorf("manifest item kind %s %s is not supported", typeMeta.APIVersion, typeMeta.Kind)
+	}
+
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, fmt.Errorf("%s decoding error %v", typeMeta.Kind, err)
+	}
+	return obj, nil
+}
+
+func metaAccessor(obj runtime.Object) (metav1.Object, error) {
+	meta, ok := obj.(metav1.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T has no object metadata", obj)
+	}
+	return meta, nil
+}
+
+// clearServerFields removes the metadata set by the API server of the source cluster
+func clearServerFields(meta metav1.Object) {
+	meta.SetUID("")
+	meta.SetResourceVersion("")
+	meta.SetSelfLink("")
+	meta.SetGeneration(0)
+	meta.SetCreationTimestamp(metav1.Time{})
+	meta.SetManagedFields(nil)
+}
@@ -0,0 +1,208 @@
+package backup
+
+import (
+	"encoding/json"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+)
+
+func TestExportImport(t *testing.T) {
+	cd := newBackupTestCanary()
+	source := fake.NewSimpleClientset(
+		newBackupTestDeployment("podinfo", nil),
+		newBackupTestDeployment("podinfo-primary", cd),
+		newBackupTestService("podinfo-primary", cd),
+		newBackupTestSecret("podinfo-secret-primary", cd),
+	)
+
+	list, err := Export(source, fakeFlagger.NewSimpleClientset(cd), "podinfo", "default")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// canary, primary deployment, service and secret
+	if len(list.Items) != 4 {
+		t.Fatalf("Got %v manifest items wanted %v", len(list.Items), 4)
+	}
+
+	// the manifest is read back from a file
+	raw, err := json.Marshal(list)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	list = &corev1.List{}
+	if err := json.Unmarshal(raw, list); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// import in a rebuilt cluster where the canary is applied with a new uid
+	applied := newBackupTestCanary()
+	applied.UID = "rebuilt"
+	applied.Status = flaggerv1.CanaryStatus{}
+	kubeClient := fake.NewSimpleClientset()
+	flaggerClient := fakeFlagger.NewSimpleClientset(applied)
+
+	imported, err := Import(kubeClient, flaggerClient, list)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if imported.Status.Phase != flaggerv1.CanaryPhaseProgressing || imported.Status.CanaryWeight != 20 {
+		t.Errorf("Got canary phase %v weight %v wanted %v %v",
+			imported.Status.Phase, imported.Status.CanaryWeight, flaggerv1.CanaryPhaseProgressing, 20)
+	}
+
+	primary, err := kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !metav1.IsControlledBy(primary, applied) {
+		t.Errorf("Got primary owner references %v wanted canary uid %v", primary.OwnerReferences, applied.UID)
+	}
+
+	svc, err := kubeClient.CoreV1().Services("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if svc.Spec.ClusterIP != "" {
+		t.Errorf("Got service cluster IP %v wanted none", svc.Spec.ClusterIP)
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets("default").Get("podinfo-secret-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(secret.Data["apiKey"]) != "secret" {
+		t.Errorf("Got secret data %v wanted %v", string(secret.Data["apiKey"]), "secret")
+	}
+
+	// the target deployment isn't generated by Flagger
+	if _, err := kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{}); err == nil {
+		t.Error("Target deployment was imported")
+	}
+
+	// importing again updates the existing objects
+	if _, err := Import(kubeClient, flaggerClient, list); err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestImport_UnsupportedKind(t *testing.T) {
+	cd := newBackupTestCanary()
+	cd.TypeMeta = metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String(), Kind: flaggerv1.CanaryKind}
+	canaryRaw, _ := json.Marshal(cd)
+	list := &corev1.List{
+		Items: []runtime.RawExtension{
+			{Raw: canaryRaw},
+			{Raw: []byte(`{"apiVersion":"networking.istio.io/v1alpha3","kind":"VirtualService"}`)},
+		},
+	}
+
+	_, err := Import(fake.NewSimpleClientset(), fakeFlagger.NewSimpleClientset(), list)
+	if err == nil {
+		t.Error("Expected error for unsupported kind")
+	}
+}
+
+func newBackupTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "podinfo",
+			UID:             types.UID("source"),
+			ResourceVersion: "100",
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "podinfo",
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+			},
+			Service: flaggerv1.CanaryService{
+				Port: 9898,
+			},
+			CanaryAnalysis: &flaggerv1.CanaryAnalysis{
+				Threshold:  10,
+				StepWeight: 10,
+				MaxWeight:  50,
+			},
+		},
+		Status: flaggerv1.CanaryStatus{
+			Phase:           flaggerv1.CanaryPhaseProgressing,
+			CanaryWeight:    20,
+			LastAppliedSpec: "abc",
+		},
+	}
+}
+
+func newBackupTestDeployment(name string, owner *flaggerv1.Canary) *appsv1.Deployment {
+	dep := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            name,
+			ResourceVersion: "10",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": name},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": name},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "podinfo", Image: "quay.io/stefanprodan/podinfo:1.2.0"},
+					},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			AvailableReplicas: 1,
+		},
+	}
+	if owner != nil {
+		dep.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, canaryKind)}
+	}
+	return dep
+}
+
+func newBackupTestService(name string, owner *flaggerv1.Canary) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            name,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(owner, canaryKind)},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "10.0.0.10",
+			Selector:  map[string]string{"app": name},
+			Ports:     []corev1.ServicePort{{Name: "http", Port: 9898}},
+		},
+	}
+}
+
+func newBackupTestSecret(name string, owner *flaggerv1.Canary) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            name,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(owner, canaryKind)},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{"apiKey": []byte("secret")},
+	}
+}
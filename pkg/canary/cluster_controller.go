@@ -0,0 +1,251 @@
+package canary
+
+import (
+	"fmt"
+
+	ex "github.com/pkg/errors"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// ClusterController is managing the operations of a multi-cluster canary,
+// the primary is the target deployment in the Flagger cluster and the canary is
+// the deployment with the same name and namespace in the canary cluster
+type ClusterController struct {
+	kubeClient    kubernetes.Interface
+	clusterClient kubernetes.Interface
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+	deployments   *DeploymentController
+}
+
+// IsPrimaryReady checks the target deployment status in the primary cluster
+func (c *ClusterController) IsPrimaryReady(cd *flaggerv1.Canary) (bool, error) {
+	primary, err := c.getPrimary(cd)
+	if err != nil {
+		return true, err
+	}
+
+	retriable, err := c.deployments.isDeploymentReady(primary, cd.GetProgressDeadlineSeconds())
+	if err != nil {
+		return retriable, fmt.Errorf("Halt advancement %s.%s %s", primary.Name, cd.Namespace, err.Error())
+	}
+
+	if primary.Spec.Replicas != nil && *primary.Spec.Replicas == 0 {
+		return true, fmt.Errorf("Halt %s.%s advancement primary deployment is scaled to zero",
+			cd.Name, cd.Namespace)
+	}
+	return true, nil
+}
+
+// IsCanaryReady checks the target deployment status in the canary cluster
+func (c *ClusterController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	canary, err := c.getCanary(cd)
+	if err != nil {
+		return true, err
+	}
+
+	retriable, err := c.deployments.isDeploymentReady(canary, cd.GetProgressDeadlineSeconds())
+	if err != nil {
+		if retriable {
+			return retriable, fmt.Errorf("Halt advancement %s.%s in cluster %s %s",
+				canary.Name, cd.Namespace, cd.Spec.Cluster.Name, err.Error())
+		}
+		return retriable, fmt.Errorf("deployment in cluster %s does not have minimum availability for more than %vs",
+			cd.Spec.Cluster.Name, cd.GetProgressDeadlineSeconds())
+	}
+
+	return true, nil
+}
+
+// GetMetadata returns the pod label selector and the ports of the canary cluster deployment,
+// used to create the services of a multi-cluster canary routed by a shared gateway
+func (c *ClusterController) GetMetadata(cd *flaggerv1.Canary) (string, map[string]int32, error) {
+	canary, err := c.getCanary(cd)
+	if err != nil {
+		return "", nil, err
+	}
+
+	label, err := c.deployments.getSelectorLabel(canary)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid label selector! Deployment %s.%s in cluster %s spec.selector.matchLabels must contain selector 'app: %s'",
+			canary.Name, cd.Namespace, cd.Spec.Cluster.Name, canary.Name)
+	}
+
+	var ports map[string]int32
+	if cd.Spec.Service.PortDiscovery {
+		p, err := getPorts(cd, canary.Spec.Template.Spec.Containers)
+		if err != nil {
+			return "", nil, fmt.Errorf("port discovery failed with error: %v", err)
+		}
+		ports = p
+	}
+
+	return label, ports, nil
+}
+
+// Initialize checks that the target deployment exists in both clusters,
+// unlike the deployment kind nothing is created or scaled down
+func (c *ClusterController) Initialize(cd *flaggerv1.Canary, skipLivenessChecks bool) error {
+	if _, err := c.getCanary(cd); err != nil {
+		return err
+	}
+
+	if !skipLivenessChecks && !cd.SkipAnalysis() {
+		if _, err := c.IsPrimaryReady(cd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Promote copies the pod spec of the canary cluster deployment to the primary cluster deployment,
+// the config maps and secrets referenced by the pod spec are not copied between clusters
+func (c *ClusterController) Promote(cd *flaggerv1.Canary) error {
+	canary, err := c.getCanary(cd)
+	if err != nil {
+		return err
+	}
+
+	primary, err := c.getPrimary(cd)
+	if err != nil {
+		return err
+	}
+
+	annotations, err := makeAnnotations(canary.Spec.Template.Annotations)
+	if err != nil {
+		return err
+	}
+
+	primaryCopy := primary.DeepCopy()
+	primaryCopy.Spec.MinReadySeconds = canary.Spec.MinReadySeconds
+	primaryCopy.Spec.Strategy = canary.Spec.Strategy
+	primaryCopy.Spec.Template.Spec = canary.Spec.Template.Spec
+	primaryCopy.Spec.Template.Annotations = annotations
+
+	_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(primaryCopy)
+	if err != nil {
+		return fmt.Errorf("updating deployment %s.%s template spec failed: %v",
+			primaryCopy.GetName(), primaryCopy.Namespace, err)
+	}
+	return nil
+}
+
+// HasTargetChanged returns true if the pod spec of the canary cluster deployment has changed
+func (c *ClusterController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	canary, err := c.getCanary(cd)
+	if err != nil {
+		return false, err
+	}
+
+	return hasSpecChanged(cd, canary.Spec.Template)
+}
+
+// HaveDependenciesChanged returns false, the config maps and secrets are not tracked across clusters
+func (c *ClusterController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bool, error) {
+	return false, nil
+}
+
+// Scale sets the canary cluster deployment replicas
+func (c *ClusterController) Scale(cd *flaggerv1.Canary, replicas int32) error {
+	canary, err := c.getCanary(cd)
+	if err != nil {
+		return err
+	}
+
+	canaryCopy := canary.DeepCopy()
+	canaryCopy.Spec.Replicas = int32p(replicas)
+
+	_, err = c.clusterClient.AppsV1().Deployments(cd.Namespace).Update(canaryCopy)
+	if err != nil {
+		return fmt.Errorf("scaling %s.%s in cluster %s to %v failed: %v",
+			canaryCopy.GetName(), canaryCopy.Namespace, cd.Spec.Cluster.Name, replicas, err)
+	}
+	return nil
+}
+
+// ScaleFromZero scales the canary cluster deployment to the primary replicas
+// so that the canary cluster can take over all the traffic
+func (c *ClusterController) ScaleFromZero(cd *flaggerv1.Canary) error {
+	canary, err := c.getCanary(cd)
+	if err != nil {
+		return err
+	}
+	if canary.Spec.Replicas != nil && *canary.Spec.Replicas > 0 {
+		return nil
+	}
+
+	replicas := int32(1)
+	if primary, err := c.getPrimary(cd); err == nil && primary.Spec.Replicas != nil && *primary.Spec.Replicas > 0 {
+		replicas = *primary.Spec.Replicas
+	}
+	return c.Scale(cd, replicas)
+}
+
+// SyncStatus encodes the canary cluster pod spec and updates the canary status
+func (c *ClusterController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	canary, err := c.getCanary(cd)
+	if err != nil {
+		return ex.Wrap(err, "SyncStatus")
+	}
+
+	return syncCanaryStatus(c.flaggerClient, cd, status, canary.Spec.Template, func(cdCopy *flaggerv1.Canary) {})
+}
+
+// SetStatusFailedChecks updates the canary failed checks counter
+func (c *ClusterController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusFailedChecks(c.flaggerClient, cd, val)
+}
+
+// SetStatusWeight updates the canary status weight value
+func (c *ClusterController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
+	return setStatusWeight(c.flaggerClient, cd, val)
+}
+
+// SetStatusIterations updates the canary status iterations value
+func (c *ClusterController) SetStatusIterations(cd *flaggerv1.Canary, val int) error {
+	return setStatusIterations(c.flaggerClient, cd, val)
+}
+
+// SetStatusPhase updates the canary status phase
+func (c *ClusterController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
+	return setStatusPhase(c.flaggerClient, cd, phase)
+}
+
+// SetStatusPromotionETA updates the canary status estimated promotion time
+func (c *ClusterController) SetStatusPromotionETA(cd *flaggerv1.Canary, eta *metav1.Time) error {
+	return setStatusPromotionETA(c.flaggerClient, cd, eta)
+}
+
+// SetStatusSuspended records the suspension of the analysis
+func (c *ClusterController) SetStatusSuspended(cd *flaggerv1.Canary, suspended bool) error {
+	return setStatusSuspended(c.flaggerClient, cd, suspended)
+}
+
+// getPrimary returns the target deployment of the primary cluster
+func (c *ClusterController) getPrimary(cd *flaggerv1.Canary) (*appsv1.Deployment, error) {
+	return c.getDeployment(c.kubeClient, "primary", cd)
+}
+
+// getCanary returns the target deployment of the canary cluster
+func (c *ClusterController) getCanary(cd *flaggerv1.Canary) (*appsv1.Deployment, error) {
+	return c.getDeployment(c.clusterClient, cd.Spec.Cluster.Name, cd)
+}
+
+func (c *ClusterController) getDeployment(client kubernetes.Interface, cluster string, cd *flaggerv1.Canary) (*appsv1.Deployment, error) {
+	targetName := cd.Spec.TargetRef.Name
+	dep, err := client.AppsV1().Deployments(cd.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("deployment %s.%s not found in cluster %s", targetName, cd.Namespace, cluster)
+		}
+		return nil, fmt.Errorf("deployment %s.%s query error in cluster %s %v", targetName, cd.Namespace, cluster, err)
+	}
+	return dep, nil
+}
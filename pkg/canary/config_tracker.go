@@ -94,8 +94,7 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 	res := make(map[string]ConfigRef)
 	targetName := cd.Spec.TargetRef.Name
 
-	var vs []corev1.Volume
-	var cs []corev1.Container
+	var spec corev1.PodSpec
 	switch cd.Spec.TargetRef.Kind {
 	case "Deployment":
 		targetDep, err := ct.KubeClient.AppsV1().Deployments(cd.Namespace).Get(targetName, metav1.GetOptions{})
@@ -105,8 +104,7 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 			}
 			return res, fmt.Errorf("deployment %s.%s query error %v", targetName, cd.Namespace, err)
 		}
-		vs = targetDep.Spec.Template.Spec.Volumes
-		cs = targetDep.Spec.Template.Spec.Containers
+		spec = targetDep.Spec.Template.Spec
 	case "DaemonSet":
 		targetDae, err := ct.KubeClient.AppsV1().DaemonSets(cd.Namespace).Get(targetName, metav1.GetOptions{})
 		if err != nil {
@@ -115,14 +113,22 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 			}
 			return res, fmt.Errorf("daemonset %s.%s query error %v", targetName, cd.Namespace, err)
 		}
-		vs = targetDae.Spec.Template.Spec.Volumes
-		cs = targetDae.Spec.Template.Spec.Containers
+		spec = targetDae.Spec.Template.Spec
+	case "CronJob":
+		targetCj, err := ct.KubeClient.BatchV1beta1().CronJobs(cd.Namespace).Get(targetName, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return res, fmt.Errorf("cronjob %s.%s not found", targetName, cd.Namespace)
+			}
+			return res, fmt.Errorf("cronjob %s.%s query error %v", targetName, cd.Namespace, err)
+		}
+		spec = targetCj.Spec.JobTemplate.Spec.Template.Spec
 	default:
 		return nil, fmt.Errorf("TargetRef.Kind invalid: %s", cd.Spec.TargetRef.Kind)
 	}
 
 	// scan volumes
-	for _, volume := range vs {
+	for _, volume := range spec.Volumes {
 		if cmv := volume.ConfigMap; cmv != nil {
 			config, err := ct.getRefFromConfigMap(cmv.Name, cd.Namespace)
 			if err != nil {
@@ -170,9 +176,21 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 				}
 			}
 		}
+
+		if csi := volume.CSI; csi != nil && csi.NodePublishSecretRef != nil {
+			name := csi.NodePublishSecretRef.Name
+			secret, err := ct.getRefFromSecret(name, cd.Namespace)
+			if err != nil {
+				ct.Logger.Errorf("secret %s.%s query error %v", name, cd.Namespace, err)
+				continue
+			}
+			if secret != nil {
+				res[secret.GetName()] = *secret
+			}
+		}
 	}
-	// scan containers
-	for _, container := range cs {
+	// scan init, app and ephemeral containers
+	for _, container := range podContainers(spec) {
 		// scan env
 		for _, env := range container.Env {
 			if env.ValueFrom != nil {
@@ -399,10 +417,17 @@ func (ct *ConfigTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, refs map[strin
 				}
 			}
 		}
+
+		if csi := volume.CSI; csi != nil && csi.NodePublishSecretRef != nil {
+			name := fmt.Sprintf("%s/%s", ConfigRefSecret, csi.NodePublishSecretRef.Name)
+			if _, exists := refs[name]; exists {
+				spec.Volumes[i].CSI.NodePublishSecretRef.Name += "-primary"
+			}
+		}
 	}
 
-	// update containers
-	for _, container := range spec.Containers {
+	// update init, app and ephemeral containers
+	for _, container := range podContainers(spec) {
 		// update env
 		for i, env := range container.Env {
 			if env.ValueFrom != nil {
@@ -439,3 +464,19 @@ func (ct *ConfigTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, refs map[strin
 
 	return spec
 }
+
+// podContainers returns the init, app and ephemeral containers of the pod spec,
+// the env and envFrom slices of the returned containers are shared with the pod spec
+func podContainers(spec corev1.PodSpec) []corev1.Container {
+	containers := make([]corev1.Container, 0, len(spec.InitContainers)+len(spec.Containers)+len(spec.EphemeralContainers))
+	containers = append(containers, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, ec := range spec.EphemeralContainers {
+		containers = append(containers, corev1.Container{
+			Name:    ec.Name,
+			Env:     ec.Env,
+			EnvFrom: ec.EnvFrom,
+		})
+	}
+	return containers
+}
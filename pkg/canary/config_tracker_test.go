@@ -3,6 +3,7 @@ package canary
 import (
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -257,3 +258,99 @@ func TestConfigTracker_Secrets(t *testing.T) {
 		}
 	})
 }
+
+func TestConfigTracker_InitEphemeralContainersAndCSI(t *testing.T) {
+	mocks := newDeploymentFixture()
+	_, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Create(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "podinfo-config-init"},
+		Data:       map[string]string{"color": "red"},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, name := range []string{"podinfo-secret-debug", "podinfo-secret-csi"} {
+		_, err := mocks.kubeClient.CoreV1().Secrets("default").Create(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"apiKey": []byte("test")},
+		})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	dep.Spec.Template.Spec.InitContainers = []corev1.Container{{
+		Name: "init",
+		EnvFrom: []corev1.EnvFromSource{{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "podinfo-config-init"},
+			},
+		}},
+	}}
+	dep.Spec.Template.Spec.EphemeralContainers = []corev1.EphemeralContainer{{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name: "debug",
+			EnvFrom: []corev1.EnvFromSource{{
+				SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "podinfo-secret-debug"},
+				},
+			}},
+		},
+	}}
+	dep.Spec.Template.Spec.Volumes = append(dep.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: "csi",
+		VolumeSource: corev1.VolumeSource{
+			CSI: &corev1.CSIVolumeSource{
+				Driver:               "secrets-store.csi.k8s.io",
+				NodePublishSecretRef: &corev1.LocalObjectReference{Name: "podinfo-secret-csi"},
+			},
+		},
+	})
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(dep)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = mocks.controller.Initialize(mocks.canary, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	spec := depPrimary.Spec.Template.Spec
+
+	if name := spec.InitContainers[0].EnvFrom[0].ConfigMapRef.Name; name != "podinfo-config-init-primary" {
+		t.Errorf("Got init container config name %v wanted %v", name, "podinfo-config-init-primary")
+	}
+	if name := spec.EphemeralContainers[0].EnvFrom[0].SecretRef.Name; name != "podinfo-secret-debug-primary" {
+		t.Errorf("Got ephemeral container secret name %v wanted %v", name, "podinfo-secret-debug-primary")
+	}
+	if name := spec.Volumes[len(spec.Volumes)-1].CSI.NodePublishSecretRef.Name; name != "podinfo-secret-csi-primary" {
+		t.Errorf("Got CSI secret name %v wanted %v", name, "podinfo-secret-csi-primary")
+	}
+
+	for _, name := range []string{"podinfo-secret-debug-primary", "podinfo-secret-csi-primary"} {
+		if _, err := mocks.kubeClient.CoreV1().Secrets("default").Get(name, metav1.GetOptions{}); err != nil {
+			t.Errorf("Got secret %s error %v", name, err)
+		}
+	}
+	if _, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get("podinfo-config-init-primary", metav1.GetOptions{}); err != nil {
+		t.Errorf("Got config map %s error %v", "podinfo-config-init-primary", err)
+	}
+
+	// the canary template keeps the original names
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if name := dep.Spec.Template.Spec.InitContainers[0].EnvFrom[0].ConfigMapRef.Name; name != "podinfo-config-init" {
+		t.Errorf("Got canary init container config name %v wanted %v", name, "podinfo-config-init")
+	}
+}
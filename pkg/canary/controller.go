@@ -1,6 +1,8 @@
 package canary
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 )
 
@@ -13,6 +15,8 @@ type Controller interface {
 	SetStatusWeight(canary *flaggerv1.Canary, val int) error
 	SetStatusIterations(canary *flaggerv1.Canary, val int) error
 	SetStatusPhase(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error
+	SetStatusPromotionETA(canary *flaggerv1.Canary, eta *metav1.Time) error
+	SetStatusSuspended(canary *flaggerv1.Canary, suspended bool) error
 	Initialize(canary *flaggerv1.Canary, skipLivenessChecks bool) error
 	Promote(canary *flaggerv1.Canary) error
 	HasTargetChanged(canary *flaggerv1.Canary) (bool, error)
@@ -0,0 +1,356 @@
+package canary
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+const (
+	canaryJobLabel         = "flagger.app/canary"
+	canaryJobRevisionLabel = "flagger.app/canary-revision"
+
+	// the job name is used as a pod label value
+	maxJobNameLength = 63
+)
+
+// CronJobController is managing the operations for Kubernetes CronJob kind,
+// the target CronJob is suspended and its job template is executed on demand during the analysis
+type CronJobController struct {
+	kubeClient    kubernetes.Interface
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+	configTracker Tracker
+	labels        []string
+	policy        PolicyMetadata
+}
+
+// Scale suspends the target CronJob and removes the canary jobs when scaling to zero,
+// there's no concept of `replicas` for CronJob
+func (c *CronJobController) Scale(cd *flaggerv1.Canary, v int32) error {
+	if v > 0 {
+		return nil
+	}
+
+	targetName := cd.Spec.TargetRef.Name
+	cj, err := c.getCronJob(targetName, cd.Namespace)
+	if err != nil {
+		return err
+	}
+
+	if cj.Spec.Suspend == nil || !*cj.Spec.Suspend {
+		cjCopy := cj.DeepCopy()
+		suspend := true
+		cjCopy.Spec.Suspend = &suspend
+		_, err = c.kubeClient.BatchV1beta1().CronJobs(cd.Namespace).Update(cjCopy)
+		if err != nil {
+			return fmt.Errorf("suspending cronjob %s.%s failed: %v", targetName, cd.Namespace, err)
+		}
+	}
+
+	jobs, err := c.listCanaryJobs(cd, "")
+	if err != nil {
+		return err
+	}
+	propagation := metav1.DeletePropagationBackground
+	for _, job := range jobs {
+		err := c.kubeClient.BatchV1().Jobs(cd.Namespace).Delete(job.Name, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("deleting job %s.%s failed: %v", job.Name, cd.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// ScaleFromZero starts the canary executions of the target job template
+func (c *CronJobController) ScaleFromZero(cd *flaggerv1.Canary) error {
+	cj, err := c.getCronJob(cd.Spec.TargetRef.Name, cd.Namespace)
+	if err != nil {
+		return err
+	}
+	return c.ensureCanaryJobs(cd, cj)
+}
+
+// Initialize creates the primary CronJob and suspends the target CronJob
+func (c *CronJobController) Initialize(cd *flaggerv1.Canary, skipLivenessChecks bool) (err error) {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	err = c.createPrimaryCronJob(cd)
+	if err != nil {
+		return fmt.Errorf("creating cronjob %s.%s failed: %v", primaryName, cd.Namespace, err)
+	}
+
+	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("Suspending %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+		if err := c.Scale(cd, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Promote copies the schedule, job template, secrets and config maps from canary to primary
+func (c *CronJobController) Promote(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+
+	canary, err := c.getCronJob(targetName, cd.Namespace)
+	if err != nil {
+		return err
+	}
+
+	label, err := c.getSelectorLabel(canary)
+	if err != nil {
+		return fmt.Errorf("invalid label selector! CronJob %s.%s spec.jobTemplate.spec.template.metadata.labels must contain selector 'app: %s'",
+			targetName, cd.Namespace, targetName)
+	}
+
+	primary, err := c.getCronJob(primaryName, cd.Namespace)
+	if err != nil {
+		return err
+	}
+
+	// promote secrets and config maps
+	configRefs, err := c.configTracker.GetTargetConfigs(cd)
+	if err != nil {
+		return err
+	}
+	if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs); err != nil {
+		return err
+	}
+
+	primaryCopy := primary.DeepCopy()
+	primaryCopy.Spec.Schedule = canary.Spec.Schedule
+	primaryCopy.Spec.StartingDeadlineSeconds = canary.Spec.StartingDeadlineSeconds
+	primaryCopy.Spec.ConcurrencyPolicy = canary.Spec.ConcurrencyPolicy
+	primaryCopy.Spec.SuccessfulJobsHistoryLimit = canary.Spec.SuccessfulJobsHistoryLimit
+	primaryCopy.Spec.FailedJobsHistoryLimit = canary.Spec.FailedJobsHistoryLimit
+	primaryCopy.Spec.JobTemplate = c.makePrimaryJobTemplate(canary.Spec.JobTemplate, primaryName, label, configRefs)
+
+	// keep the mesh security and admission policies in sync with the target
+	primaryCopy.ObjectMeta.Annotations = syncMeshAnnotations(canary.ObjectMeta.Annotations, primaryCopy.ObjectMeta.Annotations)
+	primaryCopy.ObjectMeta.Annotations = c.policy.syncMetadata(canary.ObjectMeta.Annotations, primaryCopy.ObjectMeta.Annotations)
+	primaryCopy.ObjectMeta.Labels = c.policy.syncLabels(canary.ObjectMeta.Labels, primaryCopy.ObjectMeta.Labels)
+
+	// apply update
+	_, err = c.kubeClient.BatchV1beta1().CronJobs(cd.Namespace).Update(primaryCopy)
+	if err != nil {
+		return fmt.Errorf("updating cronjob %s.%s job template failed: %v",
+			primaryCopy.GetName(), primaryCopy.Namespace, err)
+	}
+	return nil
+}
+
+// HasTargetChanged returns true if the canary CronJob job template has changed
+func (c *CronJobController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	canary, err := c.getCronJob(cd.Spec.TargetRef.Name, cd.Namespace)
+	if err != nil {
+		return false, err
+	}
+
+	return hasSpecChanged(cd, canary.Spec.JobTemplate)
+}
+
+// GetMetadata returns the pod label selector, CronJobs have no ports
+func (c *CronJobController) GetMetadata(cd *flaggerv1.Canary) (string, map[string]int32, error) {
+	targetName := cd.Spec.TargetRef.Name
+
+	canary, err := c.kubeClient.BatchV1beta1().CronJobs(cd.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil, fmt.Errorf("cronjob %s.%s not found, retrying", targetName, cd.Namespace)
+		}
+		return "", nil, err
+	}
+
+	label, err := c.getSelectorLabel(canary)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid label selector! CronJob %s.%s spec.jobTemplate.spec.template.metadata.labels must contain selector 'app: %s'",
+			targetName, cd.Namespace, targetName)
+	}
+
+	return label, nil, nil
+}
+
+func (c *CronJobController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bool, error) {
+	return c.configTracker.HasConfigChanged(cd)
+}
+
+func (c *CronJobController) createPrimaryCronJob(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+
+	canary, err := c.kubeClient.BatchV1beta1().CronJobs(cd.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("cronjob %s.%s not found, retrying", targetName, cd.Namespace)
+		}
+		return err
+	}
+
+	label, err := c.getSelectorLabel(canary)
+	if err != nil {
+		return fmt.Errorf("invalid label selector! CronJob %s.%s spec.jobTemplate.spec.template.metadata.labels must contain selector 'app: %s'",
+			targetName, cd.Namespace, targetName)
+	}
+
+	_, err = c.kubeClient.BatchV1beta1().CronJobs(cd.Namespace).Get(primaryName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		// create primary secrets and config maps
+		configRefs, err := c.configTracker.GetTargetConfigs(cd)
+		if err != nil {
+			return err
+		}
+		if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs); err != nil {
+			return err
+		}
+
+		// create primary cronjob with the schedule of the target
+		primary := &batchv1beta1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      primaryName,
+				Namespace: cd.Namespace,
+				Labels: c.policy.syncLabels(canary.ObjectMeta.Labels, map[string]string{
+					label: primaryName,
+				}),
+				Annotations: c.policy.syncMetadata(canary.ObjectMeta.Annotations, syncMeshAnnotations(canary.ObjectMeta.Annotations, nil)),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: batchv1beta1.CronJobSpec{
+				Schedule:                   canary.Spec.Schedule,
+				StartingDeadlineSeconds:    canary.Spec.StartingDeadlineSeconds,
+				ConcurrencyPolicy:          canary.Spec.ConcurrencyPolicy,
+				SuccessfulJobsHistoryLimit: canary.Spec.SuccessfulJobsHistoryLimit,
+				FailedJobsHistoryLimit:     canary.Spec.FailedJobsHistoryLimit,
+				JobTemplate:                c.makePrimaryJobTemplate(canary.Spec.JobTemplate, primaryName, label, configRefs),
+			},
+		}
+
+		_, err = c.kubeClient.BatchV1beta1().CronJobs(cd.Namespace).Create(primary)
+		if err != nil {
+			return err
+		}
+
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("CronJob %s.%s created", primary.GetName(), cd.Namespace)
+	}
+	return nil
+}
+
+// makePrimaryJobTemplate copies the job template and sets the primary labels, secrets and config maps
+func (c *CronJobController) makePrimaryJobTemplate(template batchv1beta1.JobTemplateSpec, primaryName string, label string, configRefs map[string]ConfigRef) batchv1beta1.JobTemplateSpec {
+	res := *template.DeepCopy()
+	res.Spec.Template.Labels = c.policy.exemptLabels(makePrimaryLabels(template.Spec.Template.Labels, primaryName, label))
+	res.Spec.Template.Spec = c.configTracker.ApplyPrimaryConfigs(template.Spec.Template.Spec, configRefs)
+	// let the job controller generate the selector of the primary jobs
+	res.Spec.Selector = nil
+	res.Spec.ManualSelector = nil
+	return res
+}
+
+// ensureCanaryJobs creates the missing canary executions for the current job template revision
+func (c *CronJobController) ensureCanaryJobs(cd *flaggerv1.Canary, cj *batchv1beta1.CronJob) error {
+	revision := computeHash(cj.Spec.JobTemplate)
+	jobs, err := c.listCanaryJobs(cd, revision)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		existing[job.Name] = true
+	}
+
+	for i := 0; i < cd.GetJobExecutions(); i++ {
+		suffix := fmt.Sprintf("-canary-%s-%d", revision, i)
+		prefix := cj.Name
+		if max := maxJobNameLength - len(suffix); len(prefix) > max {
+			prefix = prefix[:max]
+		}
+		name := prefix + suffix
+		if existing[name] {
+			continue
+		}
+
+		template := cj.Spec.JobTemplate.DeepCopy()
+		labels := c.policy.exemptLabels(make(map[string]string, len(template.Labels)+2))
+		for k, v := range template.Labels {
+			labels[k] = v
+		}
+		labels[canaryJobLabel] = cd.Name
+		labels[canaryJobRevisionLabel] = revision
+
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   cd.Namespace,
+				Labels:      labels,
+				Annotations: template.Annotations,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: template.Spec,
+		}
+
+		_, err := c.kubeClient.BatchV1().Jobs(cd.Namespace).Create(job)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("creating job %s.%s failed: %v", name, cd.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("Job %s.%s created", name, cd.Namespace)
+	}
+	return nil
+}
+
+// listCanaryJobs returns the canary executions of a job template revision or of all revisions if empty
+func (c *CronJobController) listCanaryJobs(cd *flaggerv1.Canary, revision string) ([]batchv1.Job, error) {
+	selector := fmt.Sprintf("%s=%s", canaryJobLabel, cd.Name)
+	if revision != "" {
+		selector = fmt.Sprintf("%s,%s=%s", selector, canaryJobRevisionLabel, revision)
+	}
+
+	list, err := c.kubeClient.BatchV1().Jobs(cd.Namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("jobs %s.%s query error %v", selector, cd.Namespace, err)
+	}
+	return list.Items, nil
+}
+
+func (c *CronJobController) getCronJob(name string, namespace string) (*batchv1beta1.CronJob, error) {
+	cj, err := c.kubeClient.BatchV1beta1().CronJobs(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("cronjob %s.%s not found", name, namespace)
+		}
+		return nil, fmt.Errorf("cronjob %s.%s query error %v", name, namespace, err)
+	}
+	return cj, nil
+}
+
+// getSelectorLabel returns the selector label of the job pod template
+func (c *CronJobController) getSelectorLabel(cronJob *batchv1beta1.CronJob) (string, error) {
+	for _, l := range c.labels {
+		if _, ok := cronJob.Spec.JobTemplate.Spec.Template.Labels[l]; ok {
+			return l, nil
+		}
+	}
+
+	return "", fmt.Errorf("selector not found")
+}
@@ -0,0 +1,187 @@
+package canary
+
+import (
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestCronJobController_Sync(t *testing.T) {
+	mocks := newCronJobFixture()
+	err := mocks.controller.Initialize(mocks.canary, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	primary, err := mocks.kubeClient.BatchV1beta1().CronJobs("default").Get("report-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if primary.Spec.Schedule != "*/5 * * * *" {
+		t.Errorf("Got schedule %s wanted %s", primary.Spec.Schedule, "*/5 * * * *")
+	}
+
+	if label := primary.Spec.JobTemplate.Spec.Template.Labels["app"]; label != "report-primary" {
+		t.Errorf("Got label %s wanted %s", label, "report-primary")
+	}
+
+	target, err := mocks.kubeClient.BatchV1beta1().CronJobs("default").Get("report", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if target.Spec.Suspend == nil || !*target.Spec.Suspend {
+		t.Errorf("Expected the target cronjob to be suspended")
+	}
+}
+
+func TestCronJobController_CanaryExecutions(t *testing.T) {
+	mocks := newCronJobFixture()
+	if err := mocks.controller.Initialize(mocks.canary, true); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := mocks.controller.ScaleFromZero(mocks.canary); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	jobs, err := mocks.controller.listCanaryJobs(mocks.canary, "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(jobs) != 2 {
+		t.Fatalf("Got %v canary jobs wanted %v", len(jobs), 2)
+	}
+
+	mocks.canary.Status.LastTransitionTime = metav1.Now()
+	retriable, err := mocks.controller.IsCanaryReady(mocks.canary)
+	if err == nil || !retriable {
+		t.Errorf("Expected a retriable error while the canary jobs are running")
+	}
+
+	// finish the canary executions, one of them fails
+	for i, job := range jobs {
+		start := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+		completion := metav1.NewTime(start.Add(time.Minute))
+		condition := batchv1.JobComplete
+		if i == 1 {
+			condition = batchv1.JobFailed
+		}
+		job.Status = batchv1.JobStatus{
+			StartTime:      &start,
+			CompletionTime: &completion,
+			Conditions:     []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}},
+		}
+		if _, err := mocks.kubeClient.BatchV1().Jobs("default").UpdateStatus(&job); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	if _, err := mocks.controller.IsCanaryReady(mocks.canary); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, job := range []*batchv1.Job{
+		newCronJobControllerTestJob("report-primary-1", "report-primary", false, 30),
+		newCronJobControllerTestJob("report-primary-2", "report-primary", false, 30),
+		newCronJobControllerTestJob("report-other-1", "report-other", true, 30),
+	} {
+		if _, err := mocks.kubeClient.BatchV1().Jobs("default").Create(job); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	primary, canary, err := mocks.controller.GetJobStats(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if primary.Finished != 2 || primary.FailureRate() != 0 || primary.Duration != 30*time.Second {
+		t.Errorf("Got primary stats %+v wanted two succeeded executions of 30s", primary)
+	}
+
+	if canary.Finished != 2 || canary.FailureRate() != 50 || canary.Duration != time.Minute {
+		t.Errorf("Got canary stats %+v wanted one succeeded execution of 1m and one failure", canary)
+	}
+
+	if err := mocks.controller.Scale(mocks.canary, 0); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	jobs, err = mocks.controller.listCanaryJobs(mocks.canary, "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(jobs) != 0 {
+		t.Errorf("Got %v canary jobs wanted none after scaling down", len(jobs))
+	}
+}
+
+func TestCronJobController_Promote(t *testing.T) {
+	mocks := newCronJobFixture()
+	if err := mocks.controller.Initialize(mocks.canary, true); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := mocks.controller.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized}); err != nil {
+		t.Fatal(err.Error())
+	}
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("report", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	changed, err := mocks.controller.HasTargetChanged(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if changed {
+		t.Errorf("Expected no change before updating the job template")
+	}
+
+	target, err := mocks.kubeClient.BatchV1beta1().CronJobs("default").Get("report", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	target.Spec.Schedule = "0 * * * *"
+	target.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Image = "report:2.0.0"
+	if _, err := mocks.kubeClient.BatchV1beta1().CronJobs("default").Update(target); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	changed, err = mocks.controller.HasTargetChanged(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !changed {
+		t.Errorf("Expected the job template change to be detected")
+	}
+
+	if err := mocks.controller.Promote(cd); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	primary, err := mocks.kubeClient.BatchV1beta1().CronJobs("default").Get("report-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if image := primary.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Image; image != "report:2.0.0" {
+		t.Errorf("Got image %s wanted %s", image, "report:2.0.0")
+	}
+
+	if primary.Spec.Schedule != "0 * * * *" {
+		t.Errorf("Got schedule %s wanted %s", primary.Spec.Schedule, "0 * * * *")
+	}
+
+	if primary.Spec.Suspend != nil && *primary.Spec.Suspend {
+		t.Errorf("Expected the primary cronjob to keep running")
+	}
+}
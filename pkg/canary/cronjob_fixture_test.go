@@ -0,0 +1,132 @@
+package canary
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+type cronJobControllerFixture struct {
+	canary        *flaggerv1.Canary
+	kubeClient    kubernetes.Interface
+	flaggerClient clientset.Interface
+	controller    CronJobController
+	logger        *zap.SugaredLogger
+}
+
+func newCronJobFixture() cronJobControllerFixture {
+	canary := newCronJobControllerTestCanary()
+	flaggerClient := fakeFlagger.NewSimpleClientset(canary)
+	kubeClient := fake.NewSimpleClientset(newCronJobControllerTestReport())
+
+	logger, _ := logger.NewLogger("debug")
+
+	ctrl := CronJobController{
+		flaggerClient: flaggerClient,
+		kubeClient:    kubeClient,
+		logger:        logger,
+		labels:        []string{"app", "name"},
+		configTracker: &ConfigTracker{
+			Logger:        logger,
+			KubeClient:    kubeClient,
+			FlaggerClient: flaggerClient,
+		},
+	}
+
+	return cronJobControllerFixture{
+		canary:        canary,
+		controller:    ctrl,
+		logger:        logger,
+		flaggerClient: flaggerClient,
+		kubeClient:    kubeClient,
+	}
+}
+
+func newCronJobControllerTestCanary() *flaggerv1.Canary {
+	cd := &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "report",
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "report",
+				APIVersion: "batch/v1beta1",
+				Kind:       "CronJob",
+			},
+			CanaryAnalysis: &flaggerv1.CanaryAnalysis{
+				JobExecutions: 2,
+			},
+		},
+	}
+	return cd
+}
+
+func newCronJobControllerTestReport() *batchv1beta1.CronJob {
+	return &batchv1beta1.CronJob{
+		TypeMeta: metav1.TypeMeta{APIVersion: batchv1beta1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "report",
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule: "*/5 * * * *",
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{"app": "report"},
+						},
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{
+									Name:  "report",
+									Image: "report:1.0.0",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newCronJobControllerTestJob(name string, owner string, failed bool, seconds int) *batchv1.Job {
+	start := metav1.Now()
+	completion := metav1.NewTime(start.Add(time.Duration(seconds) * time.Second))
+	controller := true
+	condition := batchv1.JobComplete
+	if failed {
+		condition = batchv1.JobFailed
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "batch/v1beta1", Kind: "CronJob", Name: owner, Controller: &controller},
+			},
+		},
+		Status: batchv1.JobStatus{
+			StartTime:      &start,
+			CompletionTime: &completion,
+			Conditions: []batchv1.JobCondition{
+				{Type: condition, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
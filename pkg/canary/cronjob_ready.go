@@ -0,0 +1,55 @@
+package canary
+
+import (
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// IsPrimaryReady checks if the primary cronjob exists, cronjobs have no rollout status
+func (c *CronJobController) IsPrimaryReady(cd *flaggerv1.Canary) (bool, error) {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	if _, err := c.getCronJob(primaryName, cd.Namespace); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// IsCanaryReady starts the missing canary executions and returns an error until all of them are finished,
+// the error is not retriable if the executions exceeded the progress deadline
+func (c *CronJobController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	targetName := cd.Spec.TargetRef.Name
+	canary, err := c.getCronJob(targetName, cd.Namespace)
+	if err != nil {
+		return true, err
+	}
+
+	if err := c.ensureCanaryJobs(cd, canary); err != nil {
+		return true, err
+	}
+
+	jobs, err := c.listCanaryJobs(cd, computeHash(canary.Spec.JobTemplate))
+	if err != nil {
+		return true, err
+	}
+
+	finished := 0
+	for _, job := range jobs {
+		if _, done := jobResult(job); done {
+			finished++
+		}
+	}
+
+	if finished < cd.GetJobExecutions() {
+		from := cd.Status.LastTransitionTime
+		delta := time.Duration(cd.GetProgressDeadlineSeconds()) * time.Second
+		dl := from.Add(delta)
+		if dl.Before(time.Now()) {
+			return false, fmt.Errorf("cronjob %s exceeded its progress deadline", cd.GetName())
+		}
+		return true, fmt.Errorf("halt advancement %s.%s waiting for canary executions to finish: %v/%v",
+			targetName, cd.Namespace, finished, cd.GetJobExecutions())
+	}
+	return true, nil
+}
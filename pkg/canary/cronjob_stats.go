@@ -0,0 +1,102 @@
+package canary
+
+import (
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// JobController is implemented by the controllers of batch workloads
+type JobController interface {
+	// GetJobStats returns the stats of the finished primary and canary executions
+	GetJobStats(cd *flaggerv1.Canary) (primary JobStats, canary JobStats, err error)
+}
+
+// JobStats summarizes the finished executions of a job template
+type JobStats struct {
+	Finished int
+	Failed   int
+	// Duration is the average duration of the succeeded executions
+	Duration time.Duration
+}
+
+// FailureRate returns the percentage of failed executions
+func (s JobStats) FailureRate() float64 {
+	if s.Finished == 0 {
+		return 0
+	}
+	return float64(s.Failed) / float64(s.Finished) * 100
+}
+
+// GetJobStats compares the canary executions of the current job template
+// with the executions of the primary cronjob retained by its history limits
+func (c *CronJobController) GetJobStats(cd *flaggerv1.Canary) (JobStats, JobStats, error) {
+	canary, err := c.getCronJob(cd.Spec.TargetRef.Name, cd.Namespace)
+	if err != nil {
+		return JobStats{}, JobStats{}, err
+	}
+
+	canaryJobs, err := c.listCanaryJobs(cd, computeHash(canary.Spec.JobTemplate))
+	if err != nil {
+		return JobStats{}, JobStats{}, err
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	list, err := c.kubeClient.BatchV1().Jobs(cd.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return JobStats{}, JobStats{}, fmt.Errorf("jobs %s query error %v", cd.Namespace, err)
+	}
+	var primaryJobs []batchv1.Job
+	for _, job := range list.Items {
+		if ref := metav1.GetControllerOf(&job); ref != nil && ref.Kind == "CronJob" && ref.Name == primaryName {
+			primaryJobs = append(primaryJobs, job)
+		}
+	}
+
+	return makeJobStats(primaryJobs), makeJobStats(canaryJobs), nil
+}
+
+func makeJobStats(jobs []batchv1.Job) JobStats {
+	var stats JobStats
+	var total time.Duration
+	for _, job := range jobs {
+		failed, done := jobResult(job)
+		if !done {
+			continue
+		}
+		stats.Finished++
+		if failed {
+			stats.Failed++
+			continue
+		}
+		if job.Status.StartTime != nil && job.Status.CompletionTime != nil {
+			total += job.Status.CompletionTime.Sub(job.Status.StartTime.Time)
+		}
+	}
+
+	if succeeded := stats.Finished - stats.Failed; succeeded > 0 {
+		stats.Duration = total / time.Duration(succeeded)
+	}
+	return stats
+}
+
+// jobResult returns true if the job is finished and whether it failed
+func jobResult(job batchv1.Job) (failed bool, done bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return false, true
+		case batchv1.JobFailed:
+			return true, true
+		}
+	}
+	return false, false
+}
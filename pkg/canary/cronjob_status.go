@@ -0,0 +1,61 @@
+package canary
+
+import (
+	"fmt"
+
+	ex "github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// SyncStatus encodes the canary job template and updates the canary status
+func (c *CronJobController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	cj, err := c.kubeClient.BatchV1beta1().CronJobs(cd.Namespace).Get(cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("cronjob %s.%s not found", cd.Spec.TargetRef.Name, cd.Namespace)
+		}
+		return ex.Wrap(err, "SyncStatus cronjob query error")
+	}
+
+	configs, err := c.configTracker.GetConfigRefs(cd)
+	if err != nil {
+		return ex.Wrap(err, "SyncStatus configs query error")
+	}
+
+	return syncCanaryStatus(c.flaggerClient, cd, status, cj.Spec.JobTemplate, func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.TrackedConfigs = configs
+	})
+}
+
+// SetStatusFailedChecks updates the canary failed checks counter
+func (c *CronJobController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusFailedChecks(c.flaggerClient, cd, val)
+}
+
+// SetStatusWeight updates the canary status weight value
+func (c *CronJobController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
+	return setStatusWeight(c.flaggerClient, cd, val)
+}
+
+// SetStatusIterations updates the canary status iterations value
+func (c *CronJobController) SetStatusIterations(cd *flaggerv1.Canary, val int) error {
+	return setStatusIterations(c.flaggerClient, cd, val)
+}
+
+// SetStatusPhase updates the canary status phase
+func (c *CronJobController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
+	return setStatusPhase(c.flaggerClient, cd, phase)
+}
+
+// SetStatusPromotionETA updates the canary status estimated promotion time
+func (c *CronJobController) SetStatusPromotionETA(cd *flaggerv1.Canary, eta *metav1.Time) error {
+	return setStatusPromotionETA(c.flaggerClient, cd, eta)
+}
+
+// SetStatusSuspended records the suspension of the analysis
+func (c *CronJobController) SetStatusSuspended(cd *flaggerv1.Canary, suspended bool) error {
+	return setStatusSuspended(c.flaggerClient, cd, suspended)
+}
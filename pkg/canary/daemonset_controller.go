@@ -26,6 +26,7 @@ type DaemonSetController struct {
 	logger        *zap.SugaredLogger
 	configTracker Tracker
 	labels        []string
+	policy        PolicyMetadata
 }
 
 func (c *DaemonSetController) Scale(cd *flaggerv1.Canary, v int32) error {
@@ -162,7 +163,12 @@ func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
 	}
 	primaryCopy.Spec.Template.Annotations = annotations
 
-	primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryName, label)
+	primaryCopy.Spec.Template.Labels = c.policy.exemptLabels(makePrimaryLabels(canary.Spec.Template.Labels, primaryName, label))
+
+	// keep the mesh security and admission policies in sync with the target
+	primaryCopy.ObjectMeta.Annotations = syncMeshAnnotations(canary.ObjectMeta.Annotations, primaryCopy.ObjectMeta.Annotations)
+	primaryCopy.ObjectMeta.Annotations = c.policy.syncMetadata(canary.ObjectMeta.Annotations, primaryCopy.ObjectMeta.Annotations)
+	primaryCopy.ObjectMeta.Labels = c.policy.syncLabels(canary.ObjectMeta.Labels, primaryCopy.ObjectMeta.Labels)
 
 	// apply update
 	_, err = c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Update(primaryCopy)
@@ -271,9 +277,10 @@ func (c *DaemonSetController) createPrimaryDaemonSet(cd *flaggerv1.Canary) error
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      primaryName,
 				Namespace: cd.Namespace,
-				Labels: map[string]string{
+				Labels: c.policy.syncLabels(canaryDae.ObjectMeta.Labels, map[string]string{
 					label: primaryName,
-				},
+				}),
+				Annotations: c.policy.syncMetadata(canaryDae.ObjectMeta.Annotations, syncMeshAnnotations(canaryDae.ObjectMeta.Annotations, nil)),
 				OwnerReferences: []metav1.OwnerReference{
 					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
 						Group:   flaggerv1.SchemeGroupVersion.Group,
@@ -293,7 +300,7 @@ func (c *DaemonSetController) createPrimaryDaemonSet(cd *flaggerv1.Canary) error
 				},
 				Template: corev1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
-						Labels:      makePrimaryLabels(canaryDae.Spec.Template.Labels, primaryName, label),
+						Labels:      c.policy.exemptLabels(makePrimaryLabels(canaryDae.Spec.Template.Labels, primaryName, label)),
 						Annotations: annotations,
 					},
 					// update spec with the primary secrets and config maps
@@ -49,8 +49,13 @@ func (c *DaemonSetController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error)
 	return true, nil
 }
 
-// isDaemonSetReady determines if a daemonset is ready by checking the number of old version daemons
+// isDaemonSetReady determines if a daemonset is ready by checking the number of old version daemons,
+// the node counts are only compared once the daemonset controller has observed the latest spec
 func (c *DaemonSetController) isDaemonSetReady(cd *flaggerv1.Canary, daemonSet *appsv1.DaemonSet) (bool, error) {
+	if daemonSet.Generation > daemonSet.Status.ObservedGeneration {
+		return true, fmt.Errorf("waiting for rollout to start: generation=%d, observedGeneration=%d",
+			daemonSet.Generation, daemonSet.Status.ObservedGeneration)
+	}
 	if diff := daemonSet.Status.DesiredNumberScheduled - daemonSet.Status.UpdatedNumberScheduled; diff > 0 || daemonSet.Status.NumberUnavailable > 0 {
 		from := cd.Status.LastTransitionTime
 		delta := time.Duration(cd.GetProgressDeadlineSeconds()) * time.Second
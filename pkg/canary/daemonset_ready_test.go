@@ -66,6 +66,21 @@ func TestDaemonSetController_isDaemonSetReady(t *testing.T) {
 		t.Fatal("expected retriable")
 	}
 
+	ds.Status.DesiredNumberScheduled--
+
+	// the latest spec is not observed yet
+	ds.Generation = 2
+	ds.Status.ObservedGeneration = 1
+	retrieable, err = mocks.controller.isDaemonSetReady(cd, ds)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !retrieable {
+		t.Fatal("expected retriable")
+	}
+	ds.Status.ObservedGeneration = 2
+	ds.Status.DesiredNumberScheduled++
+
 	// not ready and not retriable
 	cd.Status.LastTransitionTime = metav1.Now()
 	cd.Spec.ProgressDeadlineSeconds = int32p(-1e5)
@@ -59,3 +59,13 @@ func (c *DaemonSetController) SetStatusIterations(cd *flaggerv1.Canary, val int)
 func (c *DaemonSetController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
 }
+
+// SetStatusPromotionETA updates the canary status estimated promotion time
+func (c *DaemonSetController) SetStatusPromotionETA(cd *flaggerv1.Canary, eta *metav1.Time) error {
+	return setStatusPromotionETA(c.flaggerClient, cd, eta)
+}
+
+// SetStatusSuspended records the suspension of the analysis
+func (c *DaemonSetController) SetStatusSuspended(cd *flaggerv1.Canary, suspended bool) error {
+	return setStatusSuspended(c.flaggerClient, cd, suspended)
+}
@@ -0,0 +1,156 @@
+package canary
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	// baselineLabel tells the baseline pods apart from the primary ones, the baseline pods
+	// carry the primary selector label to receive their share of the primary traffic
+	baselineLabel = "flagger.app/baseline"
+	// baselineSpecAnnotation holds the hash of the baseline template to detect the changes
+	baselineSpecAnnotation = "flagger.app/baseline-spec"
+)
+
+// BaselineController is implemented by the controllers of the targets that can run a baseline workload
+type BaselineController interface {
+	// ReconcileBaseline creates or updates the copy of the primary with the canary replicas and resources
+	ReconcileBaseline(cd *flaggerv1.Canary) error
+	// IsBaselineReady returns an error if the baseline pods are not ready
+	IsBaselineReady(cd *flaggerv1.Canary) error
+}
+
+// ReconcileBaseline runs the primary template on fresh pods next to the canary, so that the canary
+// metrics can be compared to pods of the same age, count and resources running the promoted revision
+func (c *DeploymentController) ReconcileBaseline(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+	baselineName := fmt.Sprintf("%s-baseline", targetName)
+
+	canaryDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s query error %v", targetName, cd.Namespace, err)
+	}
+	primaryDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s query error %v", primaryName, cd.Namespace, err)
+	}
+
+	label, err := c.getSelectorLabel(primaryDep)
+	if err != nil {
+		return fmt.Errorf("invalid label selector! Deployment %s.%s spec.selector.matchLabels must contain selector 'app: %s'",
+			primaryName, cd.Namespace, primaryName)
+	}
+
+	template := primaryDep.Spec.Template.DeepCopy()
+	template.Labels[baselineLabel] = targetName
+	for i, container := range template.Spec.Containers {
+		for _, cc := range canaryDep.Spec.Template.Spec.Containers {
+			if cc.Name == container.Name {
+				template.Spec.Containers[i].Resources = cc.Resources
+			}
+		}
+	}
+	specHash := computeHash(*template)
+
+	replicas := int32(1)
+	if canaryDep.Spec.Replicas != nil && *canaryDep.Spec.Replicas > 0 {
+		replicas = *canaryDep.Spec.Replicas
+	}
+
+	baselineDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(baselineName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		baselineDep = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      baselineName,
+				Namespace: cd.Namespace,
+				Labels: map[string]string{
+					label:         baselineName,
+					baselineLabel: targetName,
+				},
+				Annotations: map[string]string{
+					baselineSpecAnnotation: specHash,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: appsv1.DeploymentSpec{
+				MinReadySeconds: primaryDep.Spec.MinReadySeconds,
+				Replicas:        int32p(replicas),
+				Strategy:        primaryDep.Spec.Strategy,
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						label:         primaryName,
+						baselineLabel: targetName,
+					},
+				},
+				Template: *template,
+			},
+		}
+
+		_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Create(baselineDep)
+		if err != nil {
+			return fmt.Errorf("creating deployment %s.%s failed: %v", baselineName, cd.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("Deployment %s.%s created", baselineName, cd.Namespace)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s query error %v", baselineName, cd.Namespace, err)
+	}
+
+	if baselineDep.Annotations[baselineSpecAnnotation] == specHash && int32Default(baselineDep.Spec.Replicas) == replicas {
+		return nil
+	}
+
+	depCopy := baselineDep.DeepCopy()
+	if depCopy.Annotations == nil {
+		depCopy.Annotations = make(map[string]string)
+	}
+	depCopy.Annotations[baselineSpecAnnotation] = specHash
+	depCopy.Spec.Replicas = int32p(replicas)
+	depCopy.Spec.Template = *template
+
+	_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(depCopy)
+	if err != nil {
+		return fmt.Errorf("updating deployment %s.%s failed: %v", baselineName, cd.Namespace, err)
+	}
+	return nil
+}
+
+// IsBaselineReady checks the baseline deployment status
+func (c *DeploymentController) IsBaselineReady(cd *flaggerv1.Canary) error {
+	baselineName := fmt.Sprintf("%s-baseline", cd.Spec.TargetRef.Name)
+	baselineDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(baselineName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s query error %v", baselineName, cd.Namespace, err)
+	}
+
+	if _, err := c.isDeploymentReady(baselineDep, cd.GetProgressDeadlineSeconds()); err != nil {
+		return fmt.Errorf("Halt advancement %s.%s %s", baselineName, cd.Namespace, err.Error())
+	}
+	return nil
+}
+
+// deleteBaseline removes the baseline deployment once the canary is scaled to zero
+func (c *DeploymentController) deleteBaseline(cd *flaggerv1.Canary) error {
+	baselineName := fmt.Sprintf("%s-baseline", cd.Spec.TargetRef.Name)
+	err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Delete(baselineName, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("deleting deployment %s.%s failed: %v", baselineName, cd.Namespace, err)
+	}
+	return nil
+}
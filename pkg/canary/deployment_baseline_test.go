@@ -0,0 +1,88 @@
+package canary
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentController_Baseline(t *testing.T) {
+	mocks := newDeploymentFixture()
+	err := mocks.controller.Initialize(mocks.canary, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the canary runs a new revision with three replicas and more cpu
+	dep2 := newDeploymentControllerTestV2()
+	dep2.Spec.Replicas = int32p(3)
+	dep2.Spec.Template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("2"),
+	}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = mocks.controller.ReconcileBaseline(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	baseline, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-baseline", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if *baseline.Spec.Replicas != 3 {
+		t.Errorf("Got baseline replicas %v wanted %v", *baseline.Spec.Replicas, 3)
+	}
+	container := baseline.Spec.Template.Spec.Containers[0]
+	if container.Image != "quay.io/stefanprodan/podinfo:1.2.0" {
+		t.Errorf("Got baseline image %s wanted the primary image %s", container.Image, "quay.io/stefanprodan/podinfo:1.2.0")
+	}
+	if cpu := container.Resources.Requests[corev1.ResourceCPU]; cpu.String() != "2" {
+		t.Errorf("Got baseline cpu request %s wanted the canary request %s", cpu.String(), "2")
+	}
+
+	// the baseline pods receive their share of the primary traffic
+	labels := baseline.Spec.Template.Labels
+	if labels["name"] != "podinfo-primary" || labels[baselineLabel] != "podinfo" {
+		t.Errorf("Got baseline pod labels %v wanted the primary selector and the baseline label", labels)
+	}
+
+	// the baseline follows the canary replicas
+	dep2.Spec.Replicas = int32p(5)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = mocks.controller.ReconcileBaseline(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	baseline, err = mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-baseline", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if *baseline.Spec.Replicas != 5 {
+		t.Errorf("Got baseline replicas %v wanted %v", *baseline.Spec.Replicas, 5)
+	}
+
+	// the pods of the new deployment are not ready
+	if err := mocks.controller.IsBaselineReady(mocks.canary); err == nil {
+		t.Errorf("Expected the baseline to be not ready")
+	}
+
+	// the baseline is removed with the canary pods
+	err = mocks.controller.Scale(mocks.canary, 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-baseline", metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Expected the baseline deployment to be deleted, got %v", err)
+	}
+}
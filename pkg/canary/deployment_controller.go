@@ -17,6 +17,10 @@ import (
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 )
 
+// hpaBehaviorAnnotation holds the autoscaling/v2 behavior of an HPA read through the v2beta1 API,
+// the API server converts it back to the behavior field when the HPA is written
+const hpaBehaviorAnnotation = "autoscaling.alpha.kubernetes.io/behavior"
+
 // DeploymentController is managing the operations for Kubernetes Deployment kind
 type DeploymentController struct {
 	kubeClient    kubernetes.Interface
@@ -24,9 +28,10 @@ type DeploymentController struct {
 	logger        *zap.SugaredLogger
 	configTracker Tracker
 	labels        []string
+	policy        PolicyMetadata
 }
 
-// Initialize creates the primary deployment, hpa,
+// Initialize creates the primary deployment, hpa or ScaledObject,
 // scales to zero the canary deployment and returns the pod selector label and container ports
 func (c *DeploymentController) Initialize(cd *flaggerv1.Canary, skipLivenessChecks bool) (err error) {
 	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
@@ -55,6 +60,12 @@ func (c *DeploymentController) Initialize(cd *flaggerv1.Canary, skipLivenessChec
 			return fmt.Errorf("creating HorizontalPodAutoscaler %s.%s failed: %v", primaryName, cd.Namespace, err)
 		}
 	}
+
+	if hasScaledObject(cd) {
+		if err := c.reconcilePrimaryScaledObject(cd, true); err != nil {
+			return fmt.Errorf("creating ScaledObject %s.%s failed: %v", primaryName, cd.Namespace, err)
+		}
+	}
 	return nil
 }
 
@@ -71,6 +82,15 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 		return fmt.Errorf("deployment %s.%s query error %v", targetName, cd.Namespace, err)
 	}
 
+	// promote the revision running on the paused canary pods, the queued template is analysed next
+	if canary.Spec.Paused {
+		template, err := c.getRolledOutTemplate(canary)
+		if err != nil {
+			return err
+		}
+		canary.Spec.Template = *template
+	}
+
 	label, err := c.getSelectorLabel(canary)
 	if err != nil {
 		return fmt.Errorf("invalid label selector! Deployment %s.%s spec.selector.matchLabels must contain selector 'app: %s'",
@@ -110,7 +130,12 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 	}
 	primaryCopy.Spec.Template.Annotations = annotations
 
-	primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryName, label)
+	primaryCopy.Spec.Template.Labels = c.policy.exemptLabels(makePrimaryLabels(canary.Spec.Template.Labels, primaryName, label))
+
+	// keep the mesh security and admission policies in sync with the target
+	primaryCopy.ObjectMeta.Annotations = syncMeshAnnotations(canary.ObjectMeta.Annotations, primaryCopy.ObjectMeta.Annotations)
+	primaryCopy.ObjectMeta.Annotations = c.policy.syncMetadata(canary.ObjectMeta.Annotations, primaryCopy.ObjectMeta.Annotations)
+	primaryCopy.ObjectMeta.Labels = c.policy.syncLabels(canary.ObjectMeta.Labels, primaryCopy.ObjectMeta.Labels)
 
 	// apply update
 	_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(primaryCopy)
@@ -126,6 +151,13 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 		}
 	}
 
+	// update ScaledObject
+	if hasScaledObject(cd) {
+		if err := c.reconcilePrimaryScaledObject(cd, false); err != nil {
+			return fmt.Errorf("updating ScaledObject %s.%s failed: %v", primaryName, cd.Namespace, err)
+		}
+	}
+
 	return nil
 }
 
@@ -154,6 +186,20 @@ func (c *DeploymentController) Scale(cd *flaggerv1.Canary, replicas int32) error
 		return fmt.Errorf("deployment %s.%s query error %v", targetName, cd.Namespace, err)
 	}
 
+	// stop KEDA from scaling up the canary deployment
+	if hasScaledObject(cd) && replicas == 0 {
+		if err := c.pauseScaledObject(cd, true); err != nil {
+			return err
+		}
+	}
+
+	// the baseline runs only next to the canary pods
+	if replicas == 0 {
+		if err := c.deleteBaseline(cd); err != nil {
+			return err
+		}
+	}
+
 	depCopy := dep.DeepCopy()
 	depCopy.Spec.Replicas = int32p(replicas)
 
@@ -185,6 +231,11 @@ func (c *DeploymentController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	if err != nil {
 		return fmt.Errorf("scaling %s.%s to %v failed: %v", depCopy.GetName(), depCopy.Namespace, replicas, err)
 	}
+
+	// resume the autoscaling of the canary deployment
+	if hasScaledObject(cd) {
+		return c.pauseScaledObject(cd, false)
+	}
 	return nil
 }
 
@@ -260,9 +311,10 @@ func (c *DeploymentController) createPrimaryDeployment(cd *flaggerv1.Canary) err
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      primaryName,
 				Namespace: cd.Namespace,
-				Labels: map[string]string{
+				Labels: c.policy.syncLabels(canaryDep.ObjectMeta.Labels, map[string]string{
 					label: primaryName,
-				},
+				}),
+				Annotations: c.policy.syncMetadata(canaryDep.ObjectMeta.Annotations, syncMeshAnnotations(canaryDep.ObjectMeta.Annotations, nil)),
 				OwnerReferences: []metav1.OwnerReference{
 					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
 						Group:   flaggerv1.SchemeGroupVersion.Group,
@@ -284,7 +336,7 @@ func (c *DeploymentController) createPrimaryDeployment(cd *flaggerv1.Canary) err
 				},
 				Template: corev1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
-						Labels:      makePrimaryLabels(canaryDep.Spec.Template.Labels, primaryName, label),
+						Labels:      c.policy.exemptLabels(makePrimaryLabels(canaryDep.Spec.Template.Labels, primaryName, label)),
 						Annotations: annotations,
 					},
 					// update spec with the primary secrets and config maps
@@ -315,6 +367,14 @@ func (c *DeploymentController) reconcilePrimaryHpa(cd *flaggerv1.Canary, init bo
 		return err
 	}
 
+	// the metric sources added after the v2beta1 client version are decoded without a source
+	for _, m := range hpa.Spec.Metrics {
+		if m.Object == nil && m.Pods == nil && m.Resource == nil && m.External == nil {
+			return fmt.Errorf("HorizontalPodAutoscaler %s.%s metric type %s can't be copied to the primary HPA",
+				hpa.Name, cd.Namespace, m.Type)
+		}
+	}
+
 	hpaSpec := hpav1.HorizontalPodAutoscalerSpec{
 		ScaleTargetRef: hpav1.CrossVersionObjectReference{
 			Name:       primaryName,
@@ -326,16 +386,24 @@ func (c *DeploymentController) reconcilePrimaryHpa(cd *flaggerv1.Canary, init bo
 		Metrics:     hpa.Spec.Metrics,
 	}
 
+	// the scaling behavior is served to the v2beta1 clients in an annotation
+	behavior, hasBehavior := hpa.Annotations[hpaBehaviorAnnotation]
+
 	primaryHpaName := fmt.Sprintf("%s-primary", cd.Spec.AutoscalerRef.Name)
 	primaryHpa, err := c.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers(cd.Namespace).Get(primaryHpaName, metav1.GetOptions{})
 
 	// create HPA
 	if errors.IsNotFound(err) {
+		var annotations map[string]string
+		if hasBehavior {
+			annotations = map[string]string{hpaBehaviorAnnotation: behavior}
+		}
 		primaryHpa = &hpav1.HorizontalPodAutoscaler{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      primaryHpaName,
-				Namespace: cd.Namespace,
-				Labels:    hpa.Labels,
+				Name:        primaryHpaName,
+				Namespace:   cd.Namespace,
+				Labels:      hpa.Labels,
+				Annotations: annotations,
 				OwnerReferences: []metav1.OwnerReference{
 					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
 						Group:   flaggerv1.SchemeGroupVersion.Group,
@@ -362,12 +430,21 @@ func (c *DeploymentController) reconcilePrimaryHpa(cd *flaggerv1.Canary, init bo
 	// update HPA
 	if !init && primaryHpa != nil {
 		diff := cmp.Diff(hpaSpec.Metrics, primaryHpa.Spec.Metrics)
-		if diff != "" || int32Default(hpaSpec.MinReplicas) != int32Default(primaryHpa.Spec.MinReplicas) || hpaSpec.MaxReplicas != primaryHpa.Spec.MaxReplicas {
-			fmt.Println(diff, hpaSpec.MinReplicas, primaryHpa.Spec.MinReplicas, hpaSpec.MaxReplicas, primaryHpa.Spec.MaxReplicas)
+		primaryBehavior, primaryHasBehavior := primaryHpa.Annotations[hpaBehaviorAnnotation]
+		behaviorChanged := hasBehavior != primaryHasBehavior || behavior != primaryBehavior
+		if diff != "" || behaviorChanged || int32Default(hpaSpec.MinReplicas) != int32Default(primaryHpa.Spec.MinReplicas) || hpaSpec.MaxReplicas != primaryHpa.Spec.MaxReplicas {
 			hpaClone := primaryHpa.DeepCopy()
 			hpaClone.Spec.MaxReplicas = hpaSpec.MaxReplicas
 			hpaClone.Spec.MinReplicas = hpaSpec.MinReplicas
 			hpaClone.Spec.Metrics = hpaSpec.Metrics
+			if hasBehavior {
+				if hpaClone.Annotations == nil {
+					hpaClone.Annotations = make(map[string]string)
+				}
+				hpaClone.Annotations[hpaBehaviorAnnotation] = behavior
+			} else {
+				delete(hpaClone.Annotations, hpaBehaviorAnnotation)
+			}
 
 			_, upErr := c.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers(cd.Namespace).Update(hpaClone)
 			if upErr != nil {
@@ -2,7 +2,9 @@ package canary
 
 import (
 	"testing"
+	"time"
 
+	hpav2 "k8s.io/api/autoscaling/v2beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -107,6 +109,87 @@ func TestDeploymentController_Promote(t *testing.T) {
 	}
 }
 
+func TestDeploymentController_HpaBehavior(t *testing.T) {
+	mocks := newDeploymentFixture()
+	hpa, err := mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	hpaClone := hpa.DeepCopy()
+	hpaClone.Annotations = map[string]string{
+		hpaBehaviorAnnotation: `{"ScaleDown":{"StabilizationWindowSeconds":600}}`,
+	}
+	hpaClone.Spec.Metrics = append(hpaClone.Spec.Metrics, hpav2.MetricSpec{
+		Type: hpav2.ExternalMetricSourceType,
+		External: &hpav2.ExternalMetricSource{
+			MetricName:         "queue_messages_ready",
+			TargetAverageValue: resource.NewQuantity(30, resource.DecimalSI),
+		},
+	})
+	_, err = mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Update(hpaClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = mocks.controller.Initialize(mocks.canary, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	hpaPrimary, err := mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if hpaPrimary.Annotations[hpaBehaviorAnnotation] != hpaClone.Annotations[hpaBehaviorAnnotation] {
+		t.Errorf("Got primary HPA behavior %q wanted %q", hpaPrimary.Annotations[hpaBehaviorAnnotation], hpaClone.Annotations[hpaBehaviorAnnotation])
+	}
+	if len(hpaPrimary.Spec.Metrics) != 2 || hpaPrimary.Spec.Metrics[1].External == nil {
+		t.Errorf("Got primary HPA metrics %+v wanted the external metric", hpaPrimary.Spec.Metrics)
+	}
+
+	// the behavior removed from the canary HPA is removed from the primary on promotion
+	hpaClone.Annotations = nil
+	_, err = mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Update(hpaClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = mocks.controller.Promote(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	hpaPrimary, err = mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, ok := hpaPrimary.Annotations[hpaBehaviorAnnotation]; ok {
+		t.Errorf("Got primary HPA behavior %q wanted none", hpaPrimary.Annotations[hpaBehaviorAnnotation])
+	}
+}
+
+func TestDeploymentController_HpaUnsupportedMetric(t *testing.T) {
+	mocks := newDeploymentFixture()
+	hpa, err := mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	hpaClone := hpa.DeepCopy()
+	hpaClone.Spec.Metrics = append(hpaClone.Spec.Metrics, hpav2.MetricSpec{Type: "ContainerResource"})
+	_, err = mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Update(hpaClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = mocks.controller.Initialize(mocks.canary, true)
+	if err == nil {
+		t.Errorf("Expected an error for the ContainerResource metric")
+	}
+	_, err = mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Get("podinfo-primary", metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Got primary HPA error %v wanted not found", err)
+	}
+}
+
 func TestDeploymentController_IsReady(t *testing.T) {
 	mocks := newDeploymentFixture()
 	err := mocks.controller.Initialize(mocks.canary, true)
@@ -376,3 +459,221 @@ func TestDeploymentController_HasTargetChanged(t *testing.T) {
 		t.Errorf("Got %v wanted %v", isNew, true)
 	}
 }
+
+func TestDeploymentController_RollbackCooldown(t *testing.T) {
+	mocks := newDeploymentFixture()
+	err := mocks.controller.Initialize(mocks.canary, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// save last applied and promoted hash
+	canary, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = mocks.controller.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// analyse and roll back a revision
+	updateCooldownTestDeployment(t, mocks, 100)
+	syncCooldownTestCanary(t, mocks, flaggerv1.CanaryPhaseProgressing)
+	syncCooldownTestCanary(t, mocks, flaggerv1.CanaryPhaseFailed)
+
+	// analyse and promote the next revision
+	updateCooldownTestDeployment(t, mocks, 600)
+	syncCooldownTestCanary(t, mocks, flaggerv1.CanaryPhaseProgressing)
+	canary, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = mocks.controller.SetStatusPhase(canary, flaggerv1.CanaryPhaseSucceeded)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// re-apply the rolled back revision
+	updateCooldownTestDeployment(t, mocks, 100)
+	canary, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if canary.Status.LastFailedSpec == "" || canary.Status.LastFailedTime == nil {
+		t.Fatalf("Got failed revision %q time %v wanted them set", canary.Status.LastFailedSpec, canary.Status.LastFailedTime)
+	}
+
+	// the revision is analysed again without cooldown
+	isNew, err := mocks.controller.HasTargetChanged(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !isNew {
+		t.Errorf("Got %v wanted %v", isNew, true)
+	}
+
+	// the revision is refused during the cooldown
+	canary.GetAnalysis().RollbackCooldown = "1h"
+	isNew, err = mocks.controller.HasTargetChanged(canary)
+	if err == nil || isNew {
+		t.Errorf("Got %v %v wanted the revision to be refused", isNew, err)
+	}
+
+	// the revision is analysed again after the cooldown
+	canary.Status.LastFailedTime = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+	isNew, err = mocks.controller.HasTargetChanged(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !isNew {
+		t.Errorf("Got %v wanted %v", isNew, true)
+	}
+}
+
+func updateCooldownTestDeployment(t *testing.T, mocks deploymentControllerFixture, cpu int64) {
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	depClone := dep.DeepCopy()
+	depClone.Spec.Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU: *resource.NewQuantity(cpu, resource.DecimalExponent),
+		},
+	}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(depClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func syncCooldownTestCanary(t *testing.T, mocks deploymentControllerFixture, phase flaggerv1.CanaryPhase) {
+	canary, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = mocks.controller.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: phase})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestDeploymentController_PromoteMeshAnnotations(t *testing.T) {
+	mocks := newDeploymentFixture()
+	err := mocks.controller.Initialize(mocks.canary, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	depClone := dep.DeepCopy()
+	depClone.Annotations = map[string]string{
+		"config.linkerd.io/default-inbound-policy": "all-authenticated",
+		"team": "payments",
+	}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(depClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = mocks.controller.Promote(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if v := depPrimary.Annotations["config.linkerd.io/default-inbound-policy"]; v != "all-authenticated" {
+		t.Errorf("Got mesh annotation %s wanted %s", v, "all-authenticated")
+	}
+
+	if _, ok := depPrimary.Annotations["team"]; ok {
+		t.Errorf("Non mesh annotation should not be copied to primary")
+	}
+}
+
+func TestDeploymentController_PromotePolicyMetadata(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.controller.policy = PolicyMetadata{
+		Prefixes:        []string{"admission.gatekeeper.sh/", "pod-security.kubernetes.io/"},
+		ExemptionLabels: map[string]string{"flagger.app/generated": "true"},
+	}
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	depClone := dep.DeepCopy()
+	depClone.Labels = map[string]string{
+		"pod-security.kubernetes.io/enforce": "baseline",
+		"team":                               "payments",
+	}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(depClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = mocks.controller.Initialize(mocks.canary, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if v := depPrimary.Labels["pod-security.kubernetes.io/enforce"]; v != "baseline" {
+		t.Errorf("Got policy label %s wanted %s", v, "baseline")
+	}
+	if _, ok := depPrimary.Labels["team"]; ok {
+		t.Errorf("Non policy label should not be copied to primary")
+	}
+	if v := depPrimary.Labels["name"]; v != "podinfo-primary" {
+		t.Errorf("Got selector label %s wanted %s", v, "podinfo-primary")
+	}
+	if v := depPrimary.Spec.Template.Labels["flagger.app/generated"]; v != "true" {
+		t.Errorf("Got pod exemption label %s wanted %s", v, "true")
+	}
+
+	// the policy metadata removed from the target is removed from primary on promotion
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	depClone = dep.DeepCopy()
+	depClone.Labels = nil
+	depClone.Annotations = map[string]string{
+		"admission.gatekeeper.sh/exempt-reason": "legacy",
+	}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(depClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = mocks.controller.Promote(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	depPrimary, err = mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, ok := depPrimary.Labels["pod-security.kubernetes.io/enforce"]; ok {
+		t.Errorf("Policy label removed from target should be removed from primary")
+	}
+	if v := depPrimary.Annotations["admission.gatekeeper.sh/exempt-reason"]; v != "legacy" {
+		t.Errorf("Got policy annotation %s wanted %s", v, "legacy")
+	}
+	if v := depPrimary.Labels["flagger.app/generated"]; v != "true" {
+		t.Errorf("Got exemption label %s wanted %s", v, "true")
+	}
+}
@@ -49,6 +49,15 @@ func (c *DeploymentController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error)
 		return true, fmt.Errorf("deployment %s.%s query error %v", targetName, cd.Namespace, err)
 	}
 
+	// the template changes of a paused canary are not rolled out, only the availability of its pods is checked
+	if canary.Spec.Paused {
+		if canary.Spec.Replicas != nil && canary.Status.AvailableReplicas < *canary.Spec.Replicas {
+			return true, fmt.Errorf("Halt advancement %s.%s waiting for paused rollout: %d of %d replicas are available",
+				targetName, cd.Namespace, canary.Status.AvailableReplicas, *canary.Spec.Replicas)
+		}
+		return true, nil
+	}
+
 	retriable, err := c.isDeploymentReady(canary, cd.GetProgressDeadlineSeconds())
 	if err != nil {
 		if retriable {
@@ -0,0 +1,117 @@
+package canary
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// deploymentRevisionAnnotation is set by the Kubernetes deployment controller on the deployments and replica sets
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// RevisionController is implemented by the controllers that can hold back
+// the revisions applied to the target while the analysis is running
+type RevisionController interface {
+	// PauseRollout stops or resumes the rollout of the target revisions to the canary pods
+	PauseRollout(cd *flaggerv1.Canary, paused bool) error
+	// RevertRevision restores the target template of the revision running on the paused canary pods
+	RevertRevision(cd *flaggerv1.Canary) error
+}
+
+// PauseRollout pauses or resumes the canary deployment,
+// the template changes of a paused deployment are recorded without replacing its pods.
+// The deployments paused with kubectl rollout pause are not resumed.
+func (c *DeploymentController) PauseRollout(cd *flaggerv1.Canary, paused bool) error {
+	targetName := cd.Spec.TargetRef.Name
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("deployment %s.%s not found", targetName, cd.Namespace)
+		}
+		return fmt.Errorf("deployment %s.%s query error %v", targetName, cd.Namespace, err)
+	}
+
+	_, pausedByFlagger := dep.Annotations[rolloutPausedAnnotation]
+	if dep.Spec.Paused == paused && (paused || !pausedByFlagger) {
+		return nil
+	}
+	if !paused && !pausedByFlagger {
+		return nil
+	}
+
+	depCopy := dep.DeepCopy()
+	depCopy.Spec.Paused = paused
+	if paused {
+		if depCopy.Annotations == nil {
+			depCopy.Annotations = make(map[string]string)
+		}
+		depCopy.Annotations[rolloutPausedAnnotation] = "true"
+	} else {
+		delete(depCopy.Annotations, rolloutPausedAnnotation)
+	}
+
+	_, err = c.kubeClient.AppsV1().Deployments(dep.Namespace).Update(depCopy)
+	if err != nil {
+		return fmt.Errorf("pausing %s.%s rollout failed: %v", depCopy.GetName(), depCopy.Namespace, err)
+	}
+	return nil
+}
+
+// RevertRevision sets the canary deployment template to the one of its rolled out replica set
+func (c *DeploymentController) RevertRevision(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("deployment %s.%s not found", targetName, cd.Namespace)
+		}
+		return fmt.Errorf("deployment %s.%s query error %v", targetName, cd.Namespace, err)
+	}
+
+	if !dep.Spec.Paused {
+		return fmt.Errorf("deployment %s.%s is not paused, the rolled out revision can't be determined", targetName, cd.Namespace)
+	}
+
+	template, err := c.getRolledOutTemplate(dep)
+	if err != nil {
+		return err
+	}
+
+	depCopy := dep.DeepCopy()
+	depCopy.Spec.Template = *template
+
+	_, err = c.kubeClient.AppsV1().Deployments(dep.Namespace).Update(depCopy)
+	if err != nil {
+		return fmt.Errorf("reverting %s.%s template spec failed: %v", depCopy.GetName(), depCopy.Namespace, err)
+	}
+	return nil
+}
+
+// getRolledOutTemplate returns the pod template of the replica set matching the deployment revision,
+// the revision isn't incremented while the deployment is paused
+func (c *DeploymentController) getRolledOutTemplate(dep *appsv1.Deployment) (*corev1.PodTemplateSpec, error) {
+	revision := dep.Annotations[deploymentRevisionAnnotation]
+
+	list, err := c.kubeClient.AppsV1().ReplicaSets(dep.Namespace).List(metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(dep.Spec.Selector),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("replica sets of deployment %s.%s query error %v", dep.Name, dep.Namespace, err)
+	}
+
+	for i := range list.Items {
+		rs := list.Items[i]
+		if metav1.IsControlledBy(&rs, dep) && rs.Annotations[deploymentRevisionAnnotation] == revision {
+			template := rs.Spec.Template.DeepCopy()
+			delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+			return template, nil
+		}
+	}
+
+	return nil, fmt.Errorf("replica set of deployment %s.%s revision %s not found", dep.Name, dep.Namespace, revision)
+}
@@ -0,0 +1,51 @@
+package canary
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// rolloutPausedAnnotation marks the deployments paused by Flagger
+// to tell them apart from the ones paused with kubectl rollout pause
+const rolloutPausedAnnotation = "flagger.app/rollout-paused"
+
+// RolloutState is the state set on the target with the kubectl rollout commands
+type RolloutState struct {
+	// Paused is set when the target was paused with kubectl rollout pause
+	Paused bool
+	// Resumed is set when a rollout paused by Flagger was resumed with kubectl rollout resume
+	Resumed bool
+	// Undone is set when the target was reverted to the promoted revision with kubectl rollout undo
+	Undone bool
+}
+
+// RolloutController is implemented by the controllers of the targets managed with the kubectl rollout commands
+type RolloutController interface {
+	// GetRolloutState returns the kubectl rollout pause, resume and undo state of the target
+	GetRolloutState(cd *flaggerv1.Canary) (RolloutState, error)
+}
+
+// GetRolloutState compares the canary deployment with the Flagger pause mark and the promoted spec
+func (c *DeploymentController) GetRolloutState(cd *flaggerv1.Canary) (RolloutState, error) {
+	targetName := cd.Spec.TargetRef.Name
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return RolloutState{}, fmt.Errorf("deployment %s.%s not found", targetName, cd.Namespace)
+		}
+		return RolloutState{}, fmt.Errorf("deployment %s.%s query error %v", targetName, cd.Namespace, err)
+	}
+
+	_, pausedByFlagger := dep.Annotations[rolloutPausedAnnotation]
+	hash := computeHash(dep.Spec.Template)
+	return RolloutState{
+		Paused:  dep.Spec.Paused && !pausedByFlagger,
+		Resumed: !dep.Spec.Paused && pausedByFlagger,
+		Undone: cd.Status.LastPromotedSpec != "" &&
+			cd.Status.LastPromotedSpec == hash && cd.Status.LastAppliedSpec != hash,
+	}, nil
+}
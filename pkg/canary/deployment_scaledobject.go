@@ -0,0 +1,126 @@
+package canary
+
+import (
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	kedav1alpha1 "github.com/weaveworks/flagger/pkg/apis/keda/v1alpha1"
+)
+
+// hasScaledObject returns true if the canary autoscaler is a KEDA ScaledObject
+func hasScaledObject(cd *flaggerv1.Canary) bool {
+	return cd.Spec.AutoscalerRef != nil && cd.Spec.AutoscalerRef.Kind == "ScaledObject"
+}
+
+// reconcilePrimaryScaledObject creates or updates the primary ScaledObject from the canary one,
+// the primary copy targets the primary deployment and the HPA managed by KEDA gets a primary name
+func (c *DeploymentController) reconcilePrimaryScaledObject(cd *flaggerv1.Canary, init bool) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	so, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Get(cd.Spec.AutoscalerRef.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("ScaledObject %s.%s not found, retrying",
+				cd.Spec.AutoscalerRef.Name, cd.Namespace)
+		}
+		return err
+	}
+
+	soSpec := so.Spec.DeepCopy()
+	soSpec.ScaleTargetRef = &kedav1alpha1.ScaleTarget{Name: primaryName}
+	if so.Spec.ScaleTargetRef != nil {
+		soSpec.ScaleTargetRef.Kind = so.Spec.ScaleTargetRef.Kind
+		soSpec.ScaleTargetRef.APIVersion = so.Spec.ScaleTargetRef.APIVersion
+		soSpec.ScaleTargetRef.EnvSourceContainerName = so.Spec.ScaleTargetRef.EnvSourceContainerName
+	}
+	if hpa := soSpec.Advanced; hpa != nil && hpa.HorizontalPodAutoscalerConfig != nil && hpa.HorizontalPodAutoscalerConfig.Name != "" {
+		hpa.HorizontalPodAutoscalerConfig.Name = fmt.Sprintf("%s-primary", hpa.HorizontalPodAutoscalerConfig.Name)
+	}
+
+	primarySoName := fmt.Sprintf("%s-primary", cd.Spec.AutoscalerRef.Name)
+	primarySo, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Get(primarySoName, metav1.GetOptions{})
+
+	// create ScaledObject
+	if errors.IsNotFound(err) {
+		primarySo = &kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      primarySoName,
+				Namespace: cd.Namespace,
+				Labels:    so.Labels,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: *soSpec,
+		}
+
+		_, err = c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Create(primarySo)
+		if err != nil {
+			return err
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("ScaledObject %s.%s created", primarySo.GetName(), cd.Namespace)
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// update ScaledObject
+	if !init && primarySo != nil {
+		if diff := cmp.Diff(*soSpec, primarySo.Spec); diff != "" {
+			soClone := primarySo.DeepCopy()
+			soClone.Spec = *soSpec
+
+			_, upErr := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Update(soClone)
+			if upErr != nil {
+				return upErr
+			}
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("ScaledObject %s.%s updated", primarySo.GetName(), cd.Namespace)
+		}
+	}
+
+	return nil
+}
+
+// pauseScaledObject pauses the canary ScaledObject at zero replicas, otherwise KEDA would scale up
+// the canary deployment to its min replicas after Flagger scales it to zero
+func (c *DeploymentController) pauseScaledObject(cd *flaggerv1.Canary, paused bool) error {
+	name := cd.Spec.AutoscalerRef.Name
+	so, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("ScaledObject %s.%s not found, retrying", name, cd.Namespace)
+		}
+		return fmt.Errorf("ScaledObject %s.%s query error %v", name, cd.Namespace, err)
+	}
+
+	_, isPaused := so.Annotations[kedav1alpha1.PausedReplicasAnnotation]
+	if isPaused == paused {
+		return nil
+	}
+
+	soCopy := so.DeepCopy()
+	if paused {
+		if soCopy.Annotations == nil {
+			soCopy.Annotations = make(map[string]string)
+		}
+		soCopy.Annotations[kedav1alpha1.PausedReplicasAnnotation] = "0"
+	} else {
+		delete(soCopy.Annotations, kedav1alpha1.PausedReplicasAnnotation)
+	}
+
+	_, err = c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Update(soCopy)
+	if err != nil {
+		return fmt.Errorf("updating ScaledObject %s.%s failed: %v", name, cd.Namespace, err)
+	}
+	return nil
+}
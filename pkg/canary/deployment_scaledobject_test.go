@@ -0,0 +1,129 @@
+package canary
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	kedav1alpha1 "github.com/weaveworks/flagger/pkg/apis/keda/v1alpha1"
+)
+
+func TestDeploymentController_ScaledObject(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.canary.Spec.AutoscalerRef = &flaggerv1.CrossNamespaceObjectReference{
+		Name:       "podinfo",
+		APIVersion: "keda.sh/v1alpha1",
+		Kind:       "ScaledObject",
+	}
+	_, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Create(newDeploymentControllerTestScaledObject())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = mocks.controller.Initialize(mocks.canary, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the primary ScaledObject targets the primary deployment
+	soPrimary, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if soPrimary.Spec.ScaleTargetRef.Name != "podinfo-primary" {
+		t.Errorf("Got ScaledObject target %s wanted %s", soPrimary.Spec.ScaleTargetRef.Name, "podinfo-primary")
+	}
+	if hpaName := soPrimary.Spec.Advanced.HorizontalPodAutoscalerConfig.Name; hpaName != "keda-podinfo-primary" {
+		t.Errorf("Got HPA name %s wanted %s", hpaName, "keda-podinfo-primary")
+	}
+
+	// the canary autoscaling is paused while the canary is scaled to zero
+	assertScaledObjectPaused(t, mocks, true)
+	err = mocks.controller.ScaleFromZero(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	assertScaledObjectPaused(t, mocks, false)
+
+	// the promotion copies the canary triggers
+	so, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	soCopy := so.DeepCopy()
+	soCopy.Spec.MaxReplicaCount = int32p(4)
+	soCopy.Spec.Triggers[0].Metadata["threshold"] = "200"
+	_, err = mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Update(soCopy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = mocks.controller.Promote(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	soPrimary, err = mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if *soPrimary.Spec.MaxReplicaCount != 4 || soPrimary.Spec.Triggers[0].Metadata["threshold"] != "200" {
+		t.Errorf("Got primary ScaledObject max replicas %v threshold %v wanted 4 200",
+			*soPrimary.Spec.MaxReplicaCount, soPrimary.Spec.Triggers[0].Metadata["threshold"])
+	}
+	if soPrimary.Spec.ScaleTargetRef.Name != "podinfo-primary" {
+		t.Errorf("Got ScaledObject target %s wanted %s", soPrimary.Spec.ScaleTargetRef.Name, "podinfo-primary")
+	}
+
+	err = mocks.controller.Scale(mocks.canary, 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	assertScaledObjectPaused(t, mocks, true)
+}
+
+func assertScaledObjectPaused(t *testing.T, mocks deploymentControllerFixture, paused bool) {
+	t.Helper()
+	so, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, ok := so.Annotations[kedav1alpha1.PausedReplicasAnnotation]; ok != paused {
+		t.Errorf("Got ScaledObject annotations %v wanted paused %v", so.Annotations, paused)
+	}
+}
+
+func newDeploymentControllerTestScaledObject() *kedav1alpha1.ScaledObject {
+	return &kedav1alpha1.ScaledObject{
+		TypeMeta: metav1.TypeMeta{APIVersion: kedav1alpha1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{
+				Name:       "podinfo",
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+			},
+			MinReplicaCount: int32p(1),
+			MaxReplicaCount: int32p(2),
+			Advanced: &kedav1alpha1.AdvancedConfig{
+				HorizontalPodAutoscalerConfig: &kedav1alpha1.HorizontalPodAutoscalerConfig{
+					Name: "keda-podinfo",
+				},
+			},
+			Triggers: []kedav1alpha1.ScaleTriggers{
+				{
+					Type: "prometheus",
+					Metadata: map[string]string{
+						"serverAddress": "http://prometheus.monitoring:9090",
+						"query":         `sum(rate(http_requests_total{app="podinfo"}[1m]))`,
+						"threshold":     "100",
+					},
+				},
+			},
+		},
+	}
+}
@@ -25,7 +25,17 @@ func (c *DeploymentController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1
 		return ex.Wrap(err, "SyncStatus configs query error")
 	}
 
-	return syncCanaryStatus(c.flaggerClient, cd, status, dep.Spec.Template, func(cdCopy *flaggerv1.Canary) {
+	// the pods of a paused canary run the revision under analysis, not the queued template
+	template := dep.Spec.Template
+	if dep.Spec.Paused {
+		rolledOut, err := c.getRolledOutTemplate(dep)
+		if err != nil {
+			return ex.Wrap(err, "SyncStatus")
+		}
+		template = *rolledOut
+	}
+
+	return syncCanaryStatus(c.flaggerClient, cd, status, template, func(cdCopy *flaggerv1.Canary) {
 		cdCopy.Status.TrackedConfigs = configs
 	})
 }
@@ -49,3 +59,13 @@ func (c *DeploymentController) SetStatusIterations(cd *flaggerv1.Canary, val int
 func (c *DeploymentController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
 }
+
+// SetStatusPromotionETA updates the canary status estimated promotion time
+func (c *DeploymentController) SetStatusPromotionETA(cd *flaggerv1.Canary, eta *metav1.Time) error {
+	return setStatusPromotionETA(c.flaggerClient, cd, eta)
+}
+
+// SetStatusSuspended records the suspension of the analysis
+func (c *DeploymentController) SetStatusSuspended(cd *flaggerv1.Canary, suspended bool) error {
+	return setStatusSuspended(c.flaggerClient, cd, suspended)
+}
@@ -4,6 +4,7 @@ import (
 	"go.uber.org/zap"
 	"k8s.io/client-go/kubernetes"
 
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 )
 
@@ -12,20 +13,26 @@ type Factory struct {
 	flaggerClient clientset.Interface
 	logger        *zap.SugaredLogger
 	configTracker Tracker
+	stateStore    StateStore
 	labels        []string
+	policy        PolicyMetadata
 }
 
 func NewFactory(kubeClient kubernetes.Interface,
 	flaggerClient clientset.Interface,
 	configTracker Tracker,
+	stateStore StateStore,
 	labels []string,
+	policy PolicyMetadata,
 	logger *zap.SugaredLogger) *Factory {
 	return &Factory{
 		kubeClient:    kubeClient,
 		flaggerClient: flaggerClient,
 		logger:        logger,
 		configTracker: configTracker,
+		stateStore:    stateStore,
 		labels:        labels,
+		policy:        policy,
 	}
 }
 
@@ -35,6 +42,7 @@ func (factory *Factory) Controller(kind string) Controller {
 		kubeClient:    factory.kubeClient,
 		flaggerClient: factory.flaggerClient,
 		labels:        factory.labels,
+		policy:        factory.policy,
 		configTracker: factory.configTracker,
 	}
 	daemonSetCtrl := &DaemonSetController{
@@ -42,8 +50,23 @@ func (factory *Factory) Controller(kind string) Controller {
 		kubeClient:    factory.kubeClient,
 		flaggerClient: factory.flaggerClient,
 		labels:        factory.labels,
+		policy:        factory.policy,
 		configTracker: factory.configTracker,
 	}
+	cronJobCtrl := &CronJobController{
+		logger:        factory.logger,
+		kubeClient:    factory.kubeClient,
+		flaggerClient: factory.flaggerClient,
+		labels:        factory.labels,
+		policy:        factory.policy,
+		configTracker: factory.configTracker,
+	}
+	statefulSetCtrl := &StatefulSetController{
+		logger:        factory.logger,
+		kubeClient:    factory.kubeClient,
+		flaggerClient: factory.flaggerClient,
+		labels:        factory.labels,
+	}
 	serviceCtrl := &ServiceController{
 		logger:        factory.logger,
 		kubeClient:    factory.kubeClient,
@@ -52,12 +75,56 @@ func (factory *Factory) Controller(kind string) Controller {
 
 	switch {
 	case kind == "DaemonSet":
-		return daemonSetCtrl
+		return factory.withState(daemonSetCtrl)
 	case kind == "Deployment":
-		return deploymentCtrl
+		return factory.withState(deploymentCtrl)
+	case kind == "CronJob":
+		return factory.withState(cronJobCtrl)
 	case kind == "Service":
-		return serviceCtrl
+		return factory.withState(serviceCtrl)
+	case kind == "StatefulSet":
+		return factory.withState(statefulSetCtrl)
 	default:
-		return deploymentCtrl
+		return factory.withState(deploymentCtrl)
+	}
+}
+
+// ClusterController returns the controller of a multi-cluster canary,
+// the cluster client manages the canary deployment in the second cluster
+func (factory *Factory) ClusterController(clusterClient kubernetes.Interface) Controller {
+	return factory.withState(&ClusterController{
+		logger:        factory.logger,
+		kubeClient:    factory.kubeClient,
+		clusterClient: clusterClient,
+		flaggerClient: factory.flaggerClient,
+		deployments:   &DeploymentController{labels: factory.labels},
+	})
+}
+
+// LoadState overrides the canary status with the analysis state kept in the state store
+func (factory *Factory) LoadState(cd *flaggerv1.Canary) error {
+	if factory.stateStore == nil {
+		return nil
+	}
+	return applyState(factory.stateStore, cd)
+}
+
+// DeleteState removes the analysis state of a deleted canary from the state store
+func (factory *Factory) DeleteState(cd *flaggerv1.Canary) error {
+	if factory.stateStore == nil {
+		return nil
+	}
+	return factory.stateStore.Delete(cd)
+}
+
+// withState keeps the analysis bookkeeping of the controller in the state store if one is set
+func (factory *Factory) withState(ctrl Controller) Controller {
+	if factory.stateStore == nil {
+		return ctrl
+	}
+	sc := &StateController{Controller: ctrl, store: factory.stateStore}
+	if jc, ok := ctrl.(JobController); ok {
+		return &stateJobController{StateController: sc, JobController: jc}
 	}
+	return sc
 }
@@ -0,0 +1,56 @@
+package canary
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// HotfixController is implemented by the controllers that can detect the target revisions marked as hotfix
+type HotfixController interface {
+	// GetHotfix returns the hotfix annotated on the target revision, empty if the revision isn't a hotfix
+	GetHotfix(cd *flaggerv1.Canary) (string, error)
+}
+
+// hotfixValue returns the hotfix annotation of the target template if it differs from the primary one,
+// the annotation is copied to the primary on promotion so that a hotfix marks a single revision
+func hotfixValue(target, primary *corev1.PodTemplateSpec) string {
+	hotfix := target.Annotations[flaggerv1.HotfixAnnotation]
+	if hotfix == "" || hotfix == primary.Annotations[flaggerv1.HotfixAnnotation] {
+		return ""
+	}
+	return hotfix
+}
+
+// GetHotfix returns the hotfix annotated on the canary deployment template
+func (c *DeploymentController) GetHotfix(cd *flaggerv1.Canary) (string, error) {
+	targetName := cd.Spec.TargetRef.Name
+	canary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("deployment %s.%s query error %v", targetName, cd.Namespace, err)
+	}
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+	primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(primaryName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("deployment %s.%s query error %v", primaryName, cd.Namespace, err)
+	}
+	return hotfixValue(&canary.Spec.Template, &primary.Spec.Template), nil
+}
+
+// GetHotfix returns the hotfix annotated on the canary daemonset template
+func (c *DaemonSetController) GetHotfix(cd *flaggerv1.Canary) (string, error) {
+	targetName := cd.Spec.TargetRef.Name
+	canary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("daemonset %s.%s query error %v", targetName, cd.Namespace, err)
+	}
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+	primary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(primaryName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("daemonset %s.%s query error %v", primaryName, cd.Namespace, err)
+	}
+	return hotfixValue(&canary.Spec.Template, &primary.Spec.Template), nil
+}
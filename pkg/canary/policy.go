@@ -0,0 +1,80 @@
+package canary
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PolicyMetadata holds the metadata required by the admission policies on the workloads generated by Flagger,
+// without it a strict policy can reject the primary workload in the middle of a rollout
+type PolicyMetadata struct {
+	// Prefixes of the target labels and annotations kept in sync on the primary workload,
+	// e.g. the Gatekeeper constraint annotations and the Pod Security Standards labels
+	Prefixes []string
+
+	// Labels set on the generated workloads and pods so that the policy constraints can exempt them
+	ExemptionLabels map[string]string
+}
+
+// ParseExemptionLabels parses a comma separated list of key=value labels
+func ParseExemptionLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid exemption label %s, must be in the key=value format", kv)
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels, nil
+}
+
+func (p PolicyMetadata) isPolicyKey(key string) bool {
+	for _, prefix := range p.Prefixes {
+		if prefix != "" && strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncMetadata copies the policy labels or annotations from the target to the primary,
+// removes the ones the target no longer has and keeps all other primary labels or annotations
+func (p PolicyMetadata) syncMetadata(target map[string]string, primary map[string]string) map[string]string {
+	res := make(map[string]string)
+	for k, v := range primary {
+		if !p.isPolicyKey(k) {
+			res[k] = v
+		}
+	}
+	for k, v := range target {
+		if p.isPolicyKey(k) {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// syncLabels copies the policy labels from the target to the primary and adds the exemption labels
+func (p PolicyMetadata) syncLabels(target map[string]string, primary map[string]string) map[string]string {
+	return p.exemptLabels(p.syncMetadata(target, primary))
+}
+
+// exemptLabels adds the exemption labels without overriding the existing ones
+func (p PolicyMetadata) exemptLabels(labels map[string]string) map[string]string {
+	if len(p.ExemptionLabels) == 0 {
+		return labels
+	}
+	res := make(map[string]string, len(labels)+len(p.ExemptionLabels))
+	for k, v := range p.ExemptionLabels {
+		res[k] = v
+	}
+	for k, v := range labels {
+		res[k] = v
+	}
+	return res
+}
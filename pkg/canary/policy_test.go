@@ -0,0 +1,25 @@
+package canary
+
+import (
+	"testing"
+)
+
+func TestParseExemptionLabels(t *testing.T) {
+	labels, err := ParseExemptionLabels("flagger.app/generated=true, team=platform")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(labels) != 2 || labels["flagger.app/generated"] != "true" || labels["team"] != "platform" {
+		t.Errorf("Got labels %v", labels)
+	}
+
+	labels, err = ParseExemptionLabels("")
+	if err != nil || len(labels) != 0 {
+		t.Errorf("Got labels %v error %v wanted none", labels, err)
+	}
+
+	_, err = ParseExemptionLabels("flagger.app/generated")
+	if err == nil {
+		t.Errorf("Expected error for a label without value")
+	}
+}
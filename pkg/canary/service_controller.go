@@ -42,6 +42,16 @@ func (c *ServiceController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1
 	return setStatusPhase(c.flaggerClient, cd, phase)
 }
 
+// SetStatusPromotionETA updates the canary status estimated promotion time
+func (c *ServiceController) SetStatusPromotionETA(cd *flaggerv1.Canary, eta *metav1.Time) error {
+	return setStatusPromotionETA(c.flaggerClient, cd, eta)
+}
+
+// SetStatusSuspended records the suspension of the analysis
+func (c *ServiceController) SetStatusSuspended(cd *flaggerv1.Canary, suspended bool) error {
+	return setStatusSuspended(c.flaggerClient, cd, suspended)
+}
+
 // GetMetadata returns the pod label selector and svc ports
 func (c *ServiceController) GetMetadata(cd *flaggerv1.Canary) (string, map[string]int32, error) {
 	return "", nil, nil
@@ -58,6 +68,11 @@ func (c *ServiceController) Initialize(cd *flaggerv1.Canary, skipLivenessChecks
 		return err
 	}
 
+	// ExternalName services have no endpoints that could be split between primary and canary
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		return fmt.Errorf("service %s.%s of type ExternalName is not supported as target", targetName, cd.Namespace)
+	}
+
 	// canary svc
 	err = c.reconcileCanaryService(cd, canaryName, svc)
 	if err != nil {
@@ -121,8 +136,9 @@ func (c *ServiceController) reconcilePrimaryService(canary *flaggerv1.Canary, na
 func (c *ServiceController) createService(canary *flaggerv1.Canary, name string, src *corev1.Service) error {
 	svc := buildService(canary, name, src)
 
-	if svc.Spec.Type == "ClusterIP" {
-		// Reset and let K8s assign the IP. Otherwise we get an error due to the IP is already assigned
+	if svc.Spec.Type == "ClusterIP" && svc.Spec.ClusterIP != corev1.ClusterIPNone {
+		// Reset and let K8s assign the IP. Otherwise we get an error due to the IP is already assigned.
+		// Headless services keep the None value so that DNS returns the pod endpoints
 		svc.Spec.ClusterIP = ""
 	}
 
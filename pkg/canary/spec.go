@@ -3,6 +3,7 @@ package canary
 import (
 	"fmt"
 	"hash/fnv"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -26,6 +27,14 @@ func hasSpecChanged(cd *flaggerv1.Canary, spec interface{}) (bool, error) {
 	}
 
 	if cd.Status.LastAppliedSpec != newHash {
+		// refuse to analyse a rolled back revision until the cooldown ends
+		if cd.Status.LastFailedSpec == newHash && cd.Status.LastFailedTime != nil {
+			elapsed := time.Since(cd.Status.LastFailedTime.Time)
+			if left := cd.GetRollbackCooldown() - elapsed; left > 0 {
+				return false, fmt.Errorf("revision %s of %s.%s was rolled back %v ago, the analysis is refused for another %v",
+					newHash, cd.Name, cd.Namespace, elapsed.Round(time.Second), left.Round(time.Second))
+			}
+		}
 		return true, nil
 	}
 
@@ -0,0 +1,124 @@
+package canary
+
+import (
+	ex "github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// StateController keeps the failed checks, iterations and estimated promotion in the state store,
+// the canary status is updated with the stored values only when the phase, weight or whole status is written
+type StateController struct {
+	Controller
+	store StateStore
+}
+
+// stateJobController keeps the job stats of the wrapped job controller available
+type stateJobController struct {
+	*StateController
+	JobController
+}
+
+// SetStatusFailedChecks stores the canary failed checks counter
+func (c *StateController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
+	_, state, err := c.load(cd)
+	if err != nil {
+		return ex.Wrap(err, "SetStatusFailedChecks")
+	}
+	state.FailedChecks = val
+	if err := c.store.Save(cd, state); err != nil {
+		return ex.Wrap(err, "SetStatusFailedChecks")
+	}
+	return nil
+}
+
+// SetStatusIterations stores the canary iterations value
+func (c *StateController) SetStatusIterations(cd *flaggerv1.Canary, val int) error {
+	_, state, err := c.load(cd)
+	if err != nil {
+		return ex.Wrap(err, "SetStatusIterations")
+	}
+	state.Iterations = val
+	if err := c.store.Save(cd, state); err != nil {
+		return ex.Wrap(err, "SetStatusIterations")
+	}
+	return nil
+}
+
+// SetStatusPromotionETA stores the canary estimated promotion time
+func (c *StateController) SetStatusPromotionETA(cd *flaggerv1.Canary, eta *metav1.Time) error {
+	_, state, err := c.load(cd)
+	if err != nil {
+		return ex.Wrap(err, "SetStatusPromotionETA")
+	}
+	state.PromotionETA = eta
+	if err := c.store.Save(cd, state); err != nil {
+		return ex.Wrap(err, "SetStatusPromotionETA")
+	}
+	return nil
+}
+
+// SetStatusPhase writes the stored state to the canary status along with the phase,
+// the state is reset the same way as the status when the analysis ends
+func (c *StateController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
+	cdCopy, state, err := c.load(cd)
+	if err != nil {
+		return ex.Wrap(err, "SetStatusPhase")
+	}
+	if err := c.Controller.SetStatusPhase(cdCopy, phase); err != nil {
+		return err
+	}
+
+	if phase != flaggerv1.CanaryPhaseProgressing && phase != flaggerv1.CanaryPhaseWaiting &&
+		phase != flaggerv1.CanaryPhaseRollingBack && phase != flaggerv1.CanaryPhaseHolding {
+		return ex.Wrap(c.store.Delete(cd), "SetStatusPhase")
+	}
+	if phase != flaggerv1.CanaryPhaseProgressing && state.PromotionETA != nil {
+		state.PromotionETA = nil
+		return ex.Wrap(c.store.Save(cd, state), "SetStatusPhase")
+	}
+	return nil
+}
+
+// SyncStatus updates the canary status and stores its analysis state
+func (c *StateController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	if err := c.Controller.SyncStatus(cd, status); err != nil {
+		return err
+	}
+
+	state := AnalysisState{
+		FailedChecks: status.FailedChecks,
+		Iterations:   status.Iterations,
+		PromotionETA: status.PromotionETA,
+	}
+	return ex.Wrap(c.store.Save(cd, state), "SyncStatus")
+}
+
+// load returns a copy of the canary with the stored state applied to its status
+func (c *StateController) load(cd *flaggerv1.Canary) (*flaggerv1.Canary, AnalysisState, error) {
+	cdCopy := cd.DeepCopy()
+	if err := applyState(c.store, cdCopy); err != nil {
+		return nil, AnalysisState{}, err
+	}
+	state := AnalysisState{
+		FailedChecks: cdCopy.Status.FailedChecks,
+		Iterations:   cdCopy.Status.Iterations,
+		PromotionETA: cdCopy.Status.PromotionETA,
+	}
+	return cdCopy, state, nil
+}
+
+// applyState overrides the canary status with the stored state
+func applyState(store StateStore, cd *flaggerv1.Canary) error {
+	state, err := store.Load(cd)
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		cd.Status.FailedChecks = state.FailedChecks
+		cd.Status.Iterations = state.Iterations
+		cd.Status.PromotionETA = state.PromotionETA
+	}
+	return nil
+}
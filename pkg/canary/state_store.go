@@ -0,0 +1,230 @@
+package canary
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// AnalysisState is the analysis bookkeeping updated at every run
+type AnalysisState struct {
+	FailedChecks int
+	Iterations   int
+	PromotionETA *metav1.Time
+}
+
+// StateStore persists the analysis bookkeeping outside of the canary status,
+// reducing the status updates of the canaries to the phase transitions
+type StateStore interface {
+	// Load returns the stored state of the canary or nil if none
+	Load(cd *flaggerv1.Canary) (*AnalysisState, error)
+	// Save stores the state of the canary
+	Save(cd *flaggerv1.Canary, state AnalysisState) error
+	// Delete removes the state of the canary
+	Delete(cd *flaggerv1.Canary) error
+}
+
+// NewStateStore returns the state store of the given kind, no store is returned for the status kind,
+// the memory store checkpoints the state to the canary status at most once per checkpoint interval
+func NewStateStore(kind string, kubeClient kubernetes.Interface, flaggerClient clientset.Interface,
+	checkpointInterval time.Duration) (StateStore, error) {
+	switch kind {
+	case "", "status":
+		return nil, nil
+	case "memory":
+		return &MemoryStateStore{flaggerClient: flaggerClient, checkpointInterval: checkpointInterval}, nil
+	case "configmap":
+		return &ConfigMapStateStore{kubeClient: kubeClient}, nil
+	default:
+		return nil, fmt.Errorf("state store %s not supported, can be status, memory or configmap", kind)
+	}
+}
+
+// MemoryStateStore keeps the state in the Flagger process, on restart or leader change
+// the analysis resumes from the values written to the canary status on the last checkpoint or phase transition
+type MemoryStateStore struct {
+	flaggerClient clientset.Interface
+	// checkpointInterval is the min time between two writes of the state to the canary status, zero disables the checkpoints
+	checkpointInterval time.Duration
+	states             sync.Map
+	checkpoints        sync.Map
+}
+
+// Load returns a copy of the state of the canary
+func (s *MemoryStateStore) Load(cd *flaggerv1.Canary) (*AnalysisState, error) {
+	v, ok := s.states.Load(stateKey(cd))
+	if !ok {
+		return nil, nil
+	}
+	state := v.(AnalysisState)
+	return &state, nil
+}
+
+// Save stores the state of the canary and checkpoints it to the canary status if the last checkpoint is too old
+func (s *MemoryStateStore) Save(cd *flaggerv1.Canary, state AnalysisState) error {
+	s.states.Store(stateKey(cd), state)
+	return s.checkpoint(cd, state)
+}
+
+// Delete removes the state of the canary
+func (s *MemoryStateStore) Delete(cd *flaggerv1.Canary) error {
+	s.states.Delete(stateKey(cd))
+	s.checkpoints.Delete(stateKey(cd))
+	return nil
+}
+
+// checkpoint writes the state to the canary status once per checkpoint interval,
+// the first save after a restart is always written
+func (s *MemoryStateStore) checkpoint(cd *flaggerv1.Canary, state AnalysisState) error {
+	if s.flaggerClient == nil || s.checkpointInterval <= 0 {
+		return nil
+	}
+	if v, ok := s.checkpoints.Load(stateKey(cd)); ok && time.Since(v.(time.Time)) < s.checkpointInterval {
+		return nil
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		c, err := s.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(cd.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if c.Status.FailedChecks == state.FailedChecks && c.Status.Iterations == state.Iterations &&
+			c.Status.PromotionETA.Equal(state.PromotionETA) {
+			return nil
+		}
+		cdCopy := c.DeepCopy()
+		cdCopy.Status.FailedChecks = state.FailedChecks
+		cdCopy.Status.Iterations = state.Iterations
+		cdCopy.Status.PromotionETA = state.PromotionETA
+		return updateStatusWithUpgrade(s.flaggerClient, cdCopy)
+	})
+	if err != nil {
+		return fmt.Errorf("canary %s.%s state checkpoint error %v", cd.Name, cd.Namespace, err)
+	}
+	s.checkpoints.Store(stateKey(cd), time.Now())
+	return nil
+}
+
+func stateKey(cd *flaggerv1.Canary) string {
+	return fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+}
+
+// ConfigMapStateStore keeps the state in a config map owned by the canary,
+// the small config map is written only when the state changes
+type ConfigMapStateStore struct {
+	kubeClient kubernetes.Interface
+}
+
+const (
+	stateFailedChecksKey = "failedChecks"
+	stateIterationsKey   = "iterations"
+	statePromotionETAKey = "promotionETA"
+)
+
+// Load reads the state of the canary from its config map
+func (s *ConfigMapStateStore) Load(cd *flaggerv1.Canary) (*AnalysisState, error) {
+	name := stateConfigMapName(cd)
+	cm, err := s.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("configmap %s.%s query error %v", name, cd.Namespace, err)
+	}
+
+	state := &AnalysisState{}
+	if v, ok := cm.Data[stateFailedChecksKey]; ok {
+		if state.FailedChecks, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("configmap %s.%s invalid %s %v", name, cd.Namespace, stateFailedChecksKey, err)
+		}
+	}
+	if v, ok := cm.Data[stateIterationsKey]; ok {
+		if state.Iterations, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("configmap %s.%s invalid %s %v", name, cd.Namespace, stateIterationsKey, err)
+		}
+	}
+	if v, ok := cm.Data[statePromotionETAKey]; ok && v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("configmap %s.%s invalid %s %v", name, cd.Namespace, statePromotionETAKey, err)
+		}
+		eta := metav1.NewTime(t)
+		state.PromotionETA = &eta
+	}
+	return state, nil
+}
+
+// Save writes the state of the canary to its config map
+func (s *ConfigMapStateStore) Save(cd *flaggerv1.Canary, state AnalysisState) error {
+	name := stateConfigMapName(cd)
+	data := map[string]string{
+		stateFailedChecksKey: strconv.Itoa(state.FailedChecks),
+		stateIterationsKey:   strconv.Itoa(state.Iterations),
+	}
+	if state.PromotionETA != nil {
+		data[statePromotionETAKey] = state.PromotionETA.UTC().Format(time.RFC3339)
+	}
+
+	cm, err := s.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cd.Namespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "flagger"},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Data: data,
+		}
+		if _, err := s.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Create(cm); err != nil {
+			return fmt.Errorf("configmap %s.%s create error %v", name, cd.Namespace, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("configmap %s.%s query error %v", name, cd.Namespace, err)
+	}
+
+	if cmp.Equal(cm.Data, data) {
+		return nil
+	}
+
+	cmCopy := cm.DeepCopy()
+	cmCopy.Data = data
+	if _, err := s.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Update(cmCopy); err != nil {
+		return fmt.Errorf("configmap %s.%s update error %v", name, cd.Namespace, err)
+	}
+	return nil
+}
+
+// Delete removes the config map of the canary
+func (s *ConfigMapStateStore) Delete(cd *flaggerv1.Canary) error {
+	name := stateConfigMapName(cd)
+	err := s.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("configmap %s.%s delete error %v", name, cd.Namespace, err)
+	}
+	return nil
+}
+
+func stateConfigMapName(cd *flaggerv1.Canary) string {
+	return fmt.Sprintf("%s-analysis-state", cd.Name)
+}
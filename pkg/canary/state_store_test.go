@@ -0,0 +1,147 @@
+package canary
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestConfigMapStateStore(t *testing.T) {
+	mocks := newDeploymentFixture()
+	store := &ConfigMapStateStore{kubeClient: mocks.kubeClient}
+
+	state, err := store.Load(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if state != nil {
+		t.Errorf("Got state %v wanted none", state)
+	}
+
+	eta := metav1.NewTime(time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC))
+	err = store.Save(mocks.canary, AnalysisState{FailedChecks: 2, Iterations: 3, PromotionETA: &eta})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	cm, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get("podinfo-analysis-state", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].Name != "podinfo" {
+		t.Errorf("Got owner references %v wanted the canary", cm.OwnerReferences)
+	}
+
+	state, err = store.Load(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if state == nil || state.FailedChecks != 2 || state.Iterations != 3 || !state.PromotionETA.Equal(&eta) {
+		t.Fatalf("Got state %+v wanted failed checks 2 iterations 3 promotion %v", state, eta)
+	}
+
+	err = store.Delete(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	state, err = store.Load(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if state != nil {
+		t.Errorf("Got state %v wanted none", state)
+	}
+}
+
+func TestStateController(t *testing.T) {
+	mocks := newDeploymentFixture()
+	store := &MemoryStateStore{}
+	ctrl := &StateController{Controller: &mocks.controller, store: store}
+
+	err := ctrl.SetStatusFailedChecks(mocks.canary, 2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the failed checks are not written to the canary status
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if cd.Status.FailedChecks != 0 {
+		t.Errorf("Got status failed checks %v wanted 0", cd.Status.FailedChecks)
+	}
+
+	err = ctrl.SetStatusIterations(mocks.canary, 4)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	state, _ := store.Load(mocks.canary)
+	if state == nil || state.FailedChecks != 2 || state.Iterations != 4 {
+		t.Fatalf("Got state %+v wanted failed checks 2 iterations 4", state)
+	}
+
+	// the phase transition writes the stored state to the canary status
+	err = ctrl.SetStatusPhase(mocks.canary, flaggerv1.CanaryPhaseProgressing)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if cd.Status.FailedChecks != 2 || cd.Status.Iterations != 4 {
+		t.Errorf("Got status failed checks %v iterations %v wanted 2 4", cd.Status.FailedChecks, cd.Status.Iterations)
+	}
+
+	// the state is reset when the analysis ends
+	err = ctrl.SetStatusPhase(cd, flaggerv1.CanaryPhaseSucceeded)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	state, _ = store.Load(mocks.canary)
+	if state != nil {
+		t.Errorf("Got state %+v wanted none", state)
+	}
+}
+
+func TestMemoryStateStore_Checkpoint(t *testing.T) {
+	mocks := newDeploymentFixture()
+	store := &MemoryStateStore{flaggerClient: mocks.flaggerClient, checkpointInterval: time.Hour}
+
+	// the first save is written to the canary status
+	err := store.Save(mocks.canary, AnalysisState{FailedChecks: 1, Iterations: 3})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the next saves are kept in memory until the checkpoint interval has passed
+	err = store.Save(mocks.canary, AnalysisState{FailedChecks: 2, Iterations: 4})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if cd.Status.FailedChecks != 1 || cd.Status.Iterations != 3 {
+		t.Errorf("Got status failed checks %v iterations %v wanted 1 3", cd.Status.FailedChecks, cd.Status.Iterations)
+	}
+
+	// after a restart the analysis resumes from the checkpoint
+	restarted := &MemoryStateStore{flaggerClient: mocks.flaggerClient, checkpointInterval: time.Hour}
+	err = applyState(restarted, cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if cd.Status.FailedChecks != 1 || cd.Status.Iterations != 3 {
+		t.Errorf("Got resumed failed checks %v iterations %v wanted 1 3", cd.Status.FailedChecks, cd.Status.Iterations)
+	}
+}
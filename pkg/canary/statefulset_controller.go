@@ -0,0 +1,187 @@
+package canary
+
+import (
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// holdPartition stops the rollout of the update revision to any pod including the ones added by a scale up
+const holdPartition = math.MaxInt32
+
+// StatefulSetController is managing the operations for Kubernetes StatefulSet kind,
+// there is no primary workload, the pods above the rolling update partition are the canary
+type StatefulSetController struct {
+	kubeClient    kubernetes.Interface
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+	labels        []string
+}
+
+// Scale holds the pods at the current revision by setting the rolling update partition above any ordinal,
+// the StatefulSet replicas are managed by the user
+func (c *StatefulSetController) Scale(cd *flaggerv1.Canary, v int32) error {
+	if v != 0 {
+		return nil
+	}
+
+	sts, err := c.getStatefulSet(cd)
+	if err != nil {
+		return err
+	}
+	return c.setPartition(sts, holdPartition)
+}
+
+// ScaleFromZero does nothing, the canary pods are updated by the router when the partition is lowered
+func (c *StatefulSetController) ScaleFromZero(cd *flaggerv1.Canary) error {
+	return nil
+}
+
+// Initialize validates the StatefulSet update strategy and
+// sets the rolling update partition to hold the next revision
+func (c *StatefulSetController) Initialize(cd *flaggerv1.Canary, skipLivenessChecks bool) (err error) {
+	sts, err := c.getStatefulSet(cd)
+	if err != nil {
+		return err
+	}
+
+	if sts.Spec.UpdateStrategy.Type != "" &&
+		sts.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return fmt.Errorf("statefulset %s.%s must have RollingUpdate strategy but have %s",
+			sts.Name, cd.Namespace, sts.Spec.UpdateStrategy.Type)
+	}
+
+	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
+		if !skipLivenessChecks && !cd.SkipAnalysis() {
+			_, readyErr := c.IsPrimaryReady(cd)
+			if readyErr != nil {
+				return readyErr
+			}
+		}
+
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("Holding the rollout of %s.%s", sts.Name, cd.Namespace)
+		if err := c.setPartition(sts, holdPartition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Promote rolls out the new revision to all pods by removing the rolling update partition
+func (c *StatefulSetController) Promote(cd *flaggerv1.Canary) error {
+	sts, err := c.getStatefulSet(cd)
+	if err != nil {
+		return err
+	}
+	return c.setPartition(sts, 0)
+}
+
+// HasTargetChanged returns true if the StatefulSet pod spec has changed
+func (c *StatefulSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	sts, err := c.getStatefulSet(cd)
+	if err != nil {
+		return false, err
+	}
+	return hasSpecChanged(cd, sts.Spec.Template)
+}
+
+// GetMetadata returns the pod label selector and svc ports
+func (c *StatefulSetController) GetMetadata(cd *flaggerv1.Canary) (string, map[string]int32, error) {
+	targetName := cd.Spec.TargetRef.Name
+
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil, fmt.Errorf("statefulset %s.%s not found, retrying", targetName, cd.Namespace)
+		}
+		return "", nil, err
+	}
+
+	label, err := c.getSelectorLabel(sts)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid label selector! StatefulSet %s.%s spec.selector.matchLabels must contain selector 'app: %s'",
+			targetName, cd.Namespace, targetName)
+	}
+
+	var ports map[string]int32
+	if cd.Spec.Service.PortDiscovery {
+		p, err := getPorts(cd, sts.Spec.Template.Spec.Containers)
+		if err != nil {
+			return "", nil, fmt.Errorf("port discovery failed with error: %v", err)
+		}
+		ports = p
+	}
+
+	return label, ports, nil
+}
+
+// HaveDependenciesChanged returns false, the config maps and secrets of a StatefulSet are not tracked
+// since there is no primary workload to copy them to
+func (c *StatefulSetController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bool, error) {
+	return false, nil
+}
+
+func (c *StatefulSetController) getStatefulSet(cd *flaggerv1.Canary) (*appsv1.StatefulSet, error) {
+	targetName := cd.Spec.TargetRef.Name
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("statefulset %s.%s not found", targetName, cd.Namespace)
+		}
+		return nil, fmt.Errorf("statefulset %s.%s query error %v", targetName, cd.Namespace, err)
+	}
+	return sts, nil
+}
+
+func (c *StatefulSetController) setPartition(sts *appsv1.StatefulSet, partition int32) error {
+	if current := sts.Spec.UpdateStrategy.RollingUpdate; current != nil && current.Partition != nil && *current.Partition == partition {
+		return nil
+	}
+
+	stsCopy := sts.DeepCopy()
+	stsCopy.Spec.UpdateStrategy.Type = appsv1.RollingUpdateStatefulSetStrategyType
+	stsCopy.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{
+		Partition: &partition,
+	}
+
+	_, err := c.kubeClient.AppsV1().StatefulSets(sts.Namespace).Update(stsCopy)
+	if err != nil {
+		return fmt.Errorf("updating statefulset %s.%s partition to %v failed: %v", sts.Name, sts.Namespace, partition, err)
+	}
+	return nil
+}
+
+// getSelectorLabel returns the selector match label
+func (c *StatefulSetController) getSelectorLabel(sts *appsv1.StatefulSet) (string, error) {
+	for _, l := range c.labels {
+		if _, ok := sts.Spec.Selector.MatchLabels[l]; ok {
+			return l, nil
+		}
+	}
+
+	return "", fmt.Errorf("selector not found")
+}
+
+// statefulSetReplicas returns the desired replicas, one if not set
+func statefulSetReplicas(sts *appsv1.StatefulSet) int32 {
+	if sts.Spec.Replicas == nil {
+		return 1
+	}
+	return *sts.Spec.Replicas
+}
+
+// statefulSetPartition returns the rolling update partition, zero if not set
+func statefulSetPartition(sts *appsv1.StatefulSet) int32 {
+	if sts.Spec.UpdateStrategy.RollingUpdate == nil || sts.Spec.UpdateStrategy.RollingUpdate.Partition == nil {
+		return 0
+	}
+	return *sts.Spec.UpdateStrategy.RollingUpdate.Partition
+}
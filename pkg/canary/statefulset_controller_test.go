@@ -0,0 +1,230 @@
+package canary
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+func TestStatefulSetController_Initialize(t *testing.T) {
+	ctrl, cd := newStatefulSetTestController(newStatefulSetControllerTestPodInfo())
+	err := ctrl.Initialize(cd, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the next revision is held until the analysis starts
+	assertStatefulSetPartition(t, ctrl, holdPartition)
+
+	label, _, err := ctrl.GetMetadata(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if label != "app" {
+		t.Errorf("Got label %s wanted %s", label, "app")
+	}
+}
+
+func TestStatefulSetController_InitializeOnDelete(t *testing.T) {
+	sts := newStatefulSetControllerTestPodInfo()
+	sts.Spec.UpdateStrategy.Type = appsv1.OnDeleteStatefulSetStrategyType
+	ctrl, cd := newStatefulSetTestController(sts)
+
+	err := ctrl.Initialize(cd, true)
+	if err == nil {
+		t.Errorf("Expected an error for the OnDelete update strategy")
+	}
+}
+
+func TestStatefulSetController_Promote(t *testing.T) {
+	ctrl, cd := newStatefulSetTestController(newStatefulSetControllerTestPodInfo())
+	err := ctrl.Initialize(cd, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = ctrl.Promote(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	assertStatefulSetPartition(t, ctrl, 0)
+
+	// scaling down the canary holds the next revision
+	err = ctrl.Scale(cd, 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	assertStatefulSetPartition(t, ctrl, holdPartition)
+}
+
+func TestStatefulSetController_HasTargetChanged(t *testing.T) {
+	ctrl, cd := newStatefulSetTestController(newStatefulSetControllerTestPodInfo())
+	err := ctrl.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cd, err = ctrl.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	isNew, err := ctrl.HasTargetChanged(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if isNew {
+		t.Errorf("Got target changed wanted unchanged")
+	}
+
+	sts := newStatefulSetControllerTestPodInfo()
+	sts.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.1"
+	_, err = ctrl.kubeClient.AppsV1().StatefulSets("default").Update(sts)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	isNew, err = ctrl.HasTargetChanged(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !isNew {
+		t.Errorf("Got target unchanged wanted changed")
+	}
+}
+
+func TestStatefulSetController_IsCanaryReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		partition int32
+		status    appsv1.StatefulSetStatus
+		ready     bool
+	}{
+		{
+			name:      "canary pods updated and ready",
+			partition: 2,
+			status:    appsv1.StatefulSetStatus{ObservedGeneration: 1, UpdatedReplicas: 1, ReadyReplicas: 3},
+			ready:     true,
+		},
+		{
+			name:      "canary pods not updated",
+			partition: 1,
+			status:    appsv1.StatefulSetStatus{ObservedGeneration: 1, UpdatedReplicas: 1, ReadyReplicas: 3},
+		},
+		{
+			name:      "pod not ready",
+			partition: 2,
+			status:    appsv1.StatefulSetStatus{ObservedGeneration: 1, UpdatedReplicas: 1, ReadyReplicas: 2},
+		},
+		{
+			name:      "rollout not observed",
+			partition: 2,
+			status:    appsv1.StatefulSetStatus{ObservedGeneration: 0, UpdatedReplicas: 3, ReadyReplicas: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sts := newStatefulSetControllerTestPodInfo()
+			sts.Generation = 1
+			sts.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: &tt.partition}
+			sts.Status = tt.status
+			ctrl, cd := newStatefulSetTestController(sts)
+			cd.Status.LastTransitionTime = metav1.Now()
+
+			retriable, err := ctrl.IsCanaryReady(cd)
+			if tt.ready && err != nil {
+				t.Errorf("Got error %v wanted ready", err)
+			}
+			if !tt.ready && err == nil {
+				t.Errorf("Got ready wanted an error")
+			}
+			if !retriable {
+				t.Errorf("Got not retriable wanted retriable")
+			}
+		})
+	}
+}
+
+func newStatefulSetTestController(sts *appsv1.StatefulSet) (*StatefulSetController, *flaggerv1.Canary) {
+	cd := newStatefulSetControllerTestCanary()
+	flaggerClient := fakeFlagger.NewSimpleClientset(cd)
+	kubeClient := fake.NewSimpleClientset(sts)
+	logger, _ := logger.NewLogger("debug")
+
+	return &StatefulSetController{
+		flaggerClient: flaggerClient,
+		kubeClient:    kubeClient,
+		logger:        logger,
+		labels:        []string{"app", "name"},
+	}, cd
+}
+
+func assertStatefulSetPartition(t *testing.T, ctrl *StatefulSetController, expected int32) {
+	sts, err := ctrl.kubeClient.AppsV1().StatefulSets("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if partition := statefulSetPartition(sts); partition != expected {
+		t.Errorf("Got partition %v wanted %v", partition, expected)
+	}
+}
+
+func newStatefulSetControllerTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "podinfo",
+				APIVersion: "apps/v1",
+				Kind:       "StatefulSet",
+			},
+		},
+	}
+}
+
+func newStatefulSetControllerTestPodInfo() *appsv1.StatefulSet {
+	replicas := int32(3)
+	return &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": "podinfo",
+				},
+			},
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.RollingUpdateStatefulSetStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": "podinfo",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "podinfo",
+							Image: "quay.io/stefanprodan/podinfo:1.2.0",
+						},
+					},
+				},
+			},
+		},
+	}
+}
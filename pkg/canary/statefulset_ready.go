@@ -0,0 +1,55 @@
+package canary
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// IsPrimaryReady checks the StatefulSet status and returns an error if
+// the pods are in the middle of a rolling update
+func (c *StatefulSetController) IsPrimaryReady(cd *flaggerv1.Canary) (bool, error) {
+	return c.IsCanaryReady(cd)
+}
+
+// IsCanaryReady checks the StatefulSet status and returns an error if
+// the pods above the partition are not updated or if any pod is not ready
+func (c *StatefulSetController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	sts, err := c.getStatefulSet(cd)
+	if err != nil {
+		return true, err
+	}
+
+	retriable, err := c.isStatefulSetReady(cd, sts)
+	if err != nil {
+		return retriable, fmt.Errorf("halt advancement %s.%s %s", sts.Name, cd.Namespace, err.Error())
+	}
+	return true, nil
+}
+
+// isStatefulSetReady determines if a StatefulSet is ready by checking that the pods above the partition
+// run the update revision and that all pods are ready, the counts are only compared once the
+// StatefulSet controller has observed the latest spec
+func (c *StatefulSetController) isStatefulSetReady(cd *flaggerv1.Canary, sts *appsv1.StatefulSet) (bool, error) {
+	if sts.Generation > sts.Status.ObservedGeneration {
+		return true, fmt.Errorf("waiting for rollout to start: generation=%d, observedGeneration=%d",
+			sts.Generation, sts.Status.ObservedGeneration)
+	}
+
+	replicas := statefulSetReplicas(sts)
+	partition := statefulSetPartition(sts)
+	if sts.Status.UpdatedReplicas < replicas-partition || sts.Status.ReadyReplicas < replicas {
+		from := cd.Status.LastTransitionTime
+		delta := time.Duration(cd.GetProgressDeadlineSeconds()) * time.Second
+		dl := from.Add(delta)
+		if dl.Before(time.Now()) {
+			return false, fmt.Errorf("statefulset %s exceeded its progress deadline", cd.GetName())
+		}
+		return true, fmt.Errorf("waiting for rollout to finish: replicas=%d, partition=%d, updatedReplicas=%d, readyReplicas=%d",
+			replicas, partition, sts.Status.UpdatedReplicas, sts.Status.ReadyReplicas)
+	}
+	return true, nil
+}
@@ -3,6 +3,7 @@ package canary
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	ex "github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -33,8 +34,37 @@ func syncCanaryStatus(flaggerClient clientset.Interface, cd *flaggerv1.Canary, s
 		cdCopy.Status.Iterations = status.Iterations
 		cdCopy.Status.LastAppliedSpec = hash
 		cdCopy.Status.LastTransitionTime = metav1.Now()
+		cdCopy.Status.PromotionETA = status.PromotionETA
+		if status.LastRollbackDuration != nil {
+			cdCopy.Status.LastRollbackDuration = status.LastRollbackDuration
+		}
+		// the rollback start is kept until the traffic is routed back to primary
+		if status.Phase != flaggerv1.CanaryPhaseRollingBack {
+			cdCopy.Status.RollbackStartTime = nil
+		}
+		// the analysis starts or restarts when the status is synced as progressing
+		if status.Phase == flaggerv1.CanaryPhaseProgressing {
+			now := metav1.Now()
+			cdCopy.Status.AnalysisStartTime = &now
+		} else {
+			cdCopy.Status.AnalysisStartTime = nil
+		}
+		cdCopy.Status.SuspendTime = nil
 		setAll(cdCopy)
 
+		// keep the rolled back revision for the rollback cooldown,
+		// the target reverted to the promoted revision is not a failed revision
+		if status.Phase == flaggerv1.CanaryPhaseFailed && hash != cd.Status.LastPromotedSpec {
+			now := metav1.Now()
+			cdCopy.Status.LastFailedSpec = hash
+			cdCopy.Status.LastFailedTime = &now
+		}
+
+		// the primary is created from the target spec on initialization
+		if status.Phase == flaggerv1.CanaryPhaseInitialized {
+			cdCopy.Status.LastPromotedSpec = hash
+		}
+
 		if ok, conditions := MakeStatusConditions(cd, status.Phase); ok {
 			cdCopy.Status.Conditions = conditions
 		}
@@ -123,6 +153,66 @@ func setStatusIterations(flaggerClient clientset.Interface, cd *flaggerv1.Canary
 	return nil
 }
 
+// setStatusPromotionETA is called after the other status updates of an analysis run,
+// the latest canary is fetched every time to not revert them
+func setStatusPromotionETA(flaggerClient clientset.Interface, cd *flaggerv1.Canary, eta *metav1.Time) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest, err := flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(cd.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		cdCopy := latest.DeepCopy()
+		cdCopy.Status.PromotionETA = eta
+
+		return updateStatusWithUpgrade(flaggerClient, cdCopy)
+	})
+	if err != nil {
+		return ex.Wrap(err, "SetStatusPromotionETA")
+	}
+	return nil
+}
+
+// setStatusSuspended records the time the analysis was suspended, on resume the analysis start is moved
+// forward by the suspended time so that it doesn't count towards the analysis deadline,
+// the times are copied to the given canary
+func setStatusSuspended(flaggerClient clientset.Interface, cd *flaggerv1.Canary, suspended bool) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest, err := flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(cd.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		cdCopy := latest.DeepCopy()
+		switch {
+		case suspended && cdCopy.Status.SuspendTime == nil:
+			now := metav1.Now()
+			cdCopy.Status.SuspendTime = &now
+		case !suspended && cdCopy.Status.SuspendTime != nil:
+			if start := cdCopy.Status.AnalysisStartTime; start != nil {
+				shifted := metav1.NewTime(start.Add(time.Since(cdCopy.Status.SuspendTime.Time)))
+				cdCopy.Status.AnalysisStartTime = &shifted
+			}
+			cdCopy.Status.SuspendTime = nil
+		default:
+			cd.Status.SuspendTime = latest.Status.SuspendTime
+			cd.Status.AnalysisStartTime = latest.Status.AnalysisStartTime
+			return nil
+		}
+
+		if err := updateStatusWithUpgrade(flaggerClient, cdCopy); err != nil {
+			return err
+		}
+		cd.Status.SuspendTime = cdCopy.Status.SuspendTime
+		cd.Status.AnalysisStartTime = cdCopy.Status.AnalysisStartTime
+		return nil
+	})
+	if err != nil {
+		return ex.Wrap(err, "SetStatusSuspended")
+	}
+	return nil
+}
+
 func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	firstTry := true
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
@@ -138,9 +228,21 @@ func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, pha
 		cdCopy.Status.Phase = phase
 		cdCopy.Status.LastTransitionTime = metav1.Now()
 
-		if phase != flaggerv1.CanaryPhaseProgressing && phase != flaggerv1.CanaryPhaseWaiting {
+		if phase != flaggerv1.CanaryPhaseProgressing && phase != flaggerv1.CanaryPhaseWaiting &&
+			phase != flaggerv1.CanaryPhaseRollingBack && phase != flaggerv1.CanaryPhaseHolding {
 			cdCopy.Status.CanaryWeight = 0
 			cdCopy.Status.Iterations = 0
+			cdCopy.Status.AnalysisStartTime = nil
+			cdCopy.Status.SuspendTime = nil
+		}
+
+		// the promotion is estimated only while the analysis is progressing
+		if phase != flaggerv1.CanaryPhaseProgressing {
+			cdCopy.Status.PromotionETA = nil
+		}
+
+		if phase != flaggerv1.CanaryPhaseRollingBack {
+			cdCopy.Status.RollbackStartTime = nil
 		}
 
 		// on promotion set primary spec hash
@@ -202,9 +304,18 @@ func MakeStatusConditions(cd *flaggerv1.Canary,
 	case flaggerv1.CanaryPhaseSucceeded:
 		status = corev1.ConditionTrue
 		message = "Canary analysis completed successfully, promotion finished."
+	case flaggerv1.CanaryPhaseHolding:
+		status = corev1.ConditionUnknown
+		message = fmt.Sprintf("Canary analysis completed, holding %v%% of the traffic on canary.", cd.GetAnalysis().HoldWeight)
+	case flaggerv1.CanaryPhaseRollingBack:
+		status = corev1.ConditionFalse
+		message = "Canary analysis failed, routing traffic back to primary."
 	case flaggerv1.CanaryPhaseFailed:
 		status = corev1.ConditionFalse
 		message = fmt.Sprintf("Canary analysis failed, %s scaled to zero.", cd.Spec.TargetRef.Kind)
+	case flaggerv1.CanaryPhaseDryRunPassed:
+		status = corev1.ConditionFalse
+		message = fmt.Sprintf("Canary dry run completed successfully, %s scaled to zero without promotion.", cd.Spec.TargetRef.Kind)
 	}
 
 	newCondition := &flaggerv1.CanaryCondition{
@@ -226,7 +337,52 @@ func MakeStatusConditions(cd *flaggerv1.Canary,
 		newCondition.LastTransitionTime = currentCondition.LastTransitionTime
 	}
 
-	return true, []flaggerv1.CanaryCondition{*newCondition}
+	return true, setStatusCondition(cd.Status.Conditions, *newCondition)
+}
+
+// MakeValidCondition updates the canary status conditions with the result of the spec validation,
+// the condition is only added when the spec is invalid and set back to true once it's fixed
+func MakeValidCondition(cd *flaggerv1.Canary, validationErr error) (bool, []flaggerv1.CanaryCondition) {
+	currentCondition := getStatusCondition(cd.Status, flaggerv1.ValidType)
+
+	newCondition := flaggerv1.CanaryCondition{
+		Type:               flaggerv1.ValidType,
+		Status:             corev1.ConditionTrue,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             "Valid",
+		Message:            "Canary spec is valid.",
+	}
+	if validationErr != nil {
+		newCondition.Status = corev1.ConditionFalse
+		newCondition.Reason = "Invalid"
+		newCondition.Message = validationErr.Error()
+	}
+
+	if currentCondition == nil && validationErr == nil {
+		return false, nil
+	}
+	if currentCondition != nil &&
+		currentCondition.Status == newCondition.Status &&
+		currentCondition.Message == newCondition.Message {
+		return false, nil
+	}
+	if currentCondition != nil && currentCondition.Status == newCondition.Status {
+		newCondition.LastTransitionTime = currentCondition.LastTransitionTime
+	}
+
+	return true, setStatusCondition(cd.Status.Conditions, newCondition)
+}
+
+// setStatusCondition returns a copy of the conditions with the condition of the same type replaced
+func setStatusCondition(conditions []flaggerv1.CanaryCondition, condition flaggerv1.CanaryCondition) []flaggerv1.CanaryCondition {
+	result := []flaggerv1.CanaryCondition{}
+	for _, c := range conditions {
+		if c.Type != condition.Type {
+			result = append(result, c)
+		}
+	}
+	return append(result, condition)
 }
 
 // updateStatusWithUpgrade tries to update the status sub-resource
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"fmt"
 	"io"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -25,22 +26,20 @@ func getPorts(cd *flaggerv1.Canary, cs []corev1.Container) (map[string]int32, er
 		}
 		for i, p := range container.Ports {
 			// exclude canary.service.port or canary.service.targetPort
-			if cd.Spec.Service.TargetPort.String() == "0" {
-				if p.ContainerPort == cd.Spec.Service.Port {
-					continue
-				}
-			} else {
-				if cd.Spec.Service.TargetPort.Type == intstr.Int {
-					if p.ContainerPort == cd.Spec.Service.TargetPort.IntVal {
-						continue
-					}
-				}
-				if cd.Spec.Service.TargetPort.Type == intstr.String {
-					if p.Name == cd.Spec.Service.TargetPort.StrVal {
-						continue
-					}
+			if isServicePort(p, cd.Spec.Service.Port, cd.Spec.Service.TargetPort) {
+				continue
+			}
+			// exclude the additional ports declared in canary.service.ports
+			declared := false
+			for _, sp := range cd.Spec.Service.Ports {
+				if isServicePort(p, sp.Port, sp.TargetPort) {
+					declared = true
+					break
 				}
 			}
+			if declared {
+				continue
+			}
 			name := fmt.Sprintf("tcp-%s-%v", container.Name, i)
 			if p.Name != "" {
 				name = p.Name
@@ -52,6 +51,17 @@ func getPorts(cd *flaggerv1.Canary, cs []corev1.Container) (map[string]int32, er
 	return ports, nil
 }
 
+// isServicePort returns true if the container port is the target of the service port
+func isServicePort(p corev1.ContainerPort, port int32, targetPort intstr.IntOrString) bool {
+	if targetPort.String() == "0" {
+		return p.ContainerPort == port
+	}
+	if targetPort.Type == intstr.Int {
+		return p.ContainerPort == targetPort.IntVal
+	}
+	return p.Name == targetPort.StrVal
+}
+
 // makeAnnotations appends an unique ID to annotations map
 func makeAnnotations(annotations map[string]string) (map[string]string, error) {
 	idKey := "flagger-id"
@@ -75,6 +85,45 @@ func makeAnnotations(annotations map[string]string) (map[string]string, error) {
 	return res, nil
 }
 
+// meshAnnotationPrefixes are the service mesh security and policy annotations
+// that are kept in sync between the target workload and the primary workload
+var meshAnnotationPrefixes = []string{
+	"sidecar.istio.io/",
+	"traffic.sidecar.istio.io/",
+	"security.istio.io/",
+	"linkerd.io/",
+	"config.linkerd.io/",
+	"config.alpha.linkerd.io/",
+	"appmesh.k8s.aws/",
+}
+
+func isMeshAnnotation(key string) bool {
+	for _, prefix := range meshAnnotationPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncMeshAnnotations copies the mesh annotations from the target to the primary annotations,
+// removes the mesh annotations the target no longer has and keeps all other primary annotations
+func syncMeshAnnotations(target map[string]string, primary map[string]string) map[string]string {
+	res := make(map[string]string)
+	for k, v := range primary {
+		if !isMeshAnnotation(k) {
+			res[k] = v
+		}
+	}
+	for k, v := range target {
+		if isMeshAnnotation(k) {
+			res[k] = v
+		}
+	}
+
+	return res
+}
+
 func makePrimaryLabels(labels map[string]string, primaryName string, label string) map[string]string {
 	res := make(map[string]string)
 	for k, v := range labels {
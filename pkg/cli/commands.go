@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// Status writes the analysis status of a canary, or of all the canaries in the namespace if the name is empty
+func Status(flaggerClient clientset.Interface, name string, namespace string, w io.Writer) error {
+	var canaries []flaggerv1.Canary
+	if name == "" {
+		list, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("canaries query error %v", err)
+		}
+		canaries = list.Items
+	} else {
+		cd, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("canary %s.%s query error %v", name, namespace, err)
+		}
+		canaries = append(canaries, *cd)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPHASE\tWEIGHT\tITERATIONS\tFAILED CHECKS\tSUSPENDED\tLAST TRANSITION\tMESSAGE")
+	for _, cd := range canaries {
+		message := ""
+		for _, condition := range cd.Status.Conditions {
+			if condition.Type == flaggerv1.PromotedType {
+				message = condition.Message
+			}
+		}
+		lastTransition := "-"
+		if !cd.Status.LastTransitionTime.IsZero() {
+			lastTransition = cd.Status.LastTransitionTime.UTC().Format("2006-01-02T15:04:05Z")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%v\t%v\t%v/%v\t%v\t%s\t%s\n",
+			cd.Name, cd.Status.Phase, cd.Status.CanaryWeight, cd.Status.Iterations,
+			cd.Status.FailedChecks, cd.GetAnalysisThreshold(), cd.Spec.Suspend, lastTransition, message)
+	}
+	return tw.Flush()
+}
+
+// Pause suspends the canary, Flagger holds the current weight until the canary is resumed
+func Pause(flaggerClient clientset.Interface, name string, namespace string) error {
+	return patchCanary(flaggerClient, name, namespace, map[string]interface{}{
+		"spec": map[string]interface{}{"suspend": true},
+	})
+}
+
+// Resume continues the analysis of a suspended canary from the step it was suspended on
+func Resume(flaggerClient clientset.Interface, name string, namespace string) error {
+	return patchCanary(flaggerClient, name, namespace, map[string]interface{}{
+		"spec": map[string]interface{}{"suspend": false},
+	})
+}
+
+// Promote requests the promotion of the canary revision under analysis
+func Promote(flaggerClient clientset.Interface, name string, namespace string) error {
+	return requestCommand(flaggerClient, name, namespace, flaggerv1.PromoteAnnotation)
+}
+
+// Rollback requests the rollback of the canary revision under analysis
+func Rollback(flaggerClient clientset.Interface, name string, namespace string) error {
+	return requestCommand(flaggerClient, name, namespace, flaggerv1.RollbackAnnotation)
+}
+
+// requestCommand annotates the canary with the revision under analysis,
+// the controller carries out the command at the next analysis run
+func requestCommand(flaggerClient clientset.Interface, name string, namespace string, annotation string) error {
+	cd, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("canary %s.%s query error %v", name, namespace, err)
+	}
+	if cd.Status.Phase != flaggerv1.CanaryPhaseProgressing && cd.Status.Phase != flaggerv1.CanaryPhaseWaiting {
+		return fmt.Errorf("canary %s.%s is not under analysis, phase %s", name, namespace, cd.Status.Phase)
+	}
+
+	return patchCanary(flaggerClient, name, namespace, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{annotation: cd.Status.LastAppliedSpec},
+		},
+	})
+}
+
+func patchCanary(flaggerClient clientset.Interface, name string, namespace string, patch map[string]interface{}) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = flaggerClient.FlaggerV1beta1().Canaries(namespace).Patch(name, types.MergePatchType, data)
+	if err != nil {
+		return fmt.Errorf("canary %s.%s patch error %v", name, namespace, err)
+	}
+	return nil
+}
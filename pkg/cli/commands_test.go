@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+)
+
+func TestPauseResume(t *testing.T) {
+	flaggerClient := fakeFlagger.NewSimpleClientset(newCLITestCanary(flaggerv1.CanaryPhaseProgressing))
+
+	if err := Pause(flaggerClient, "podinfo", "default"); err != nil {
+		t.Fatal(err.Error())
+	}
+	cd, err := flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !cd.Spec.Suspend {
+		t.Errorf("Got suspend %v wanted %v", cd.Spec.Suspend, true)
+	}
+
+	if err := Resume(flaggerClient, "podinfo", "default"); err != nil {
+		t.Fatal(err.Error())
+	}
+	cd, err = flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if cd.Spec.Suspend {
+		t.Errorf("Got suspend %v wanted %v", cd.Spec.Suspend, false)
+	}
+}
+
+func TestPromoteRollback(t *testing.T) {
+	flaggerClient := fakeFlagger.NewSimpleClientset(newCLITestCanary(flaggerv1.CanaryPhaseProgressing))
+
+	if err := Promote(flaggerClient, "podinfo", "default"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := Rollback(flaggerClient, "podinfo", "default"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	cd, err := flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !cd.IsCommandRequested(flaggerv1.PromoteAnnotation) {
+		t.Errorf("Promote annotation %v doesn't target revision %s", cd.Annotations, cd.Status.LastAppliedSpec)
+	}
+	if !cd.IsCommandRequested(flaggerv1.RollbackAnnotation) {
+		t.Errorf("Rollback annotation %v doesn't target revision %s", cd.Annotations, cd.Status.LastAppliedSpec)
+	}
+	if cd.Annotations["app.kubernetes.io/managed-by"] != "flux" {
+		t.Errorf("Got annotations %v wanted the existing annotations to be kept", cd.Annotations)
+	}
+}
+
+func TestPromote_NotUnderAnalysis(t *testing.T) {
+	flaggerClient := fakeFlagger.NewSimpleClientset(newCLITestCanary(flaggerv1.CanaryPhaseSucceeded))
+
+	if err := Promote(flaggerClient, "podinfo", "default"); err == nil {
+		t.Errorf("Expected an error for a canary that is not under analysis")
+	}
+}
+
+func TestStatus(t *testing.T) {
+	flaggerClient := fakeFlagger.NewSimpleClientset(newCLITestCanary(flaggerv1.CanaryPhaseProgressing))
+
+	var out bytes.Buffer
+	if err := Status(flaggerClient, "", "default", &out); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Got %v lines wanted 2\n%s", len(lines), out.String())
+	}
+	for _, field := range []string{"podinfo", "Progressing", "30", "1/5", "New revision detected"} {
+		if !strings.Contains(lines[1], field) {
+			t.Errorf("Status %q doesn't contain %q", lines[1], field)
+		}
+	}
+
+	if err := Status(flaggerClient, "missing", "default", &out); err == nil {
+		t.Errorf("Expected an error for a missing canary")
+	}
+}
+
+func newCLITestCanary(phase flaggerv1.CanaryPhase) *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "podinfo",
+			Annotations: map[string]string{"app.kubernetes.io/managed-by": "flux"},
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "podinfo",
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+			},
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Threshold:  5,
+				StepWeight: 10,
+				MaxWeight:  50,
+			},
+		},
+		Status: flaggerv1.CanaryStatus{
+			Phase:           phase,
+			CanaryWeight:    30,
+			FailedChecks:    1,
+			LastAppliedSpec: "5b8f8f6d4c",
+			Conditions: []flaggerv1.CanaryCondition{{
+				Type:    flaggerv1.PromotedType,
+				Message: "New revision detected, progressing canary analysis.",
+			}},
+		},
+	}
+}
@@ -21,13 +21,23 @@ package versioned
 import (
 	"fmt"
 
+	getambassadorv2 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/ambassador/v2"
+	apisixv2 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/apisix/v2"
 	appmeshv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1"
+	externaldnsv1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/externaldns/v1alpha1"
 	flaggerv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1"
 	gloov1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/gloo/v1"
+	gatewayv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/gloogateway/v1"
 	networkingv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3"
+	kedav1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1"
+	configurationv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/kong/v1"
+	kumav1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/kuma/v1alpha1"
+	monitoringv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/monitoring/v1"
 	projectcontourv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1"
 	splitv1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1"
 	splitv1alpha2 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha2"
+	splitv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha3"
+	specsv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smispecs/v1alpha3"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -35,26 +45,56 @@ import (
 
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
+	GetambassadorV2() getambassadorv2.GetambassadorV2Interface
+	ApisixV2() apisixv2.ApisixV2Interface
 	AppmeshV1beta1() appmeshv1beta1.AppmeshV1beta1Interface
+	ExternaldnsV1alpha1() externaldnsv1alpha1.ExternaldnsV1alpha1Interface
 	FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface
 	GlooV1() gloov1.GlooV1Interface
+	GatewayV1() gatewayv1.GatewayV1Interface
 	NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3Interface
+	KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface
+	ConfigurationV1() configurationv1.ConfigurationV1Interface
+	KumaV1alpha1() kumav1alpha1.KumaV1alpha1Interface
+	MonitoringV1() monitoringv1.MonitoringV1Interface
 	ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface
 	SplitV1alpha1() splitv1alpha1.SplitV1alpha1Interface
 	SplitV1alpha2() splitv1alpha2.SplitV1alpha2Interface
+	SplitV1alpha3() splitv1alpha3.SplitV1alpha3Interface
+	SpecsV1alpha3() specsv1alpha3.SpecsV1alpha3Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
 // version included in a Clientset.
 type Clientset struct {
 	*discovery.DiscoveryClient
-	appmeshV1beta1     *appmeshv1beta1.AppmeshV1beta1Client
-	flaggerV1beta1     *flaggerv1beta1.FlaggerV1beta1Client
-	glooV1             *gloov1.GlooV1Client
-	networkingV1alpha3 *networkingv1alpha3.NetworkingV1alpha3Client
-	projectcontourV1   *projectcontourv1.ProjectcontourV1Client
-	splitV1alpha1      *splitv1alpha1.SplitV1alpha1Client
-	splitV1alpha2      *splitv1alpha2.SplitV1alpha2Client
+	getambassadorV2     *getambassadorv2.GetambassadorV2Client
+	apisixV2            *apisixv2.ApisixV2Client
+	appmeshV1beta1      *appmeshv1beta1.AppmeshV1beta1Client
+	externaldnsV1alpha1 *externaldnsv1alpha1.ExternaldnsV1alpha1Client
+	flaggerV1beta1      *flaggerv1beta1.FlaggerV1beta1Client
+	glooV1              *gloov1.GlooV1Client
+	gatewayV1           *gatewayv1.GatewayV1Client
+	networkingV1alpha3  *networkingv1alpha3.NetworkingV1alpha3Client
+	kedaV1alpha1        *kedav1alpha1.KedaV1alpha1Client
+	configurationV1     *configurationv1.ConfigurationV1Client
+	kumaV1alpha1        *kumav1alpha1.KumaV1alpha1Client
+	monitoringV1        *monitoringv1.MonitoringV1Client
+	projectcontourV1    *projectcontourv1.ProjectcontourV1Client
+	splitV1alpha1       *splitv1alpha1.SplitV1alpha1Client
+	splitV1alpha2       *splitv1alpha2.SplitV1alpha2Client
+	splitV1alpha3       *splitv1alpha3.SplitV1alpha3Client
+	specsV1alpha3       *specsv1alpha3.SpecsV1alpha3Client
+}
+
+// GetambassadorV2 retrieves the GetambassadorV2Client
+func (c *Clientset) GetambassadorV2() getambassadorv2.GetambassadorV2Interface {
+	return c.getambassadorV2
+}
+
+// ApisixV2 retrieves the ApisixV2Client
+func (c *Clientset) ApisixV2() apisixv2.ApisixV2Interface {
+	return c.apisixV2
 }
 
 // AppmeshV1beta1 retrieves the AppmeshV1beta1Client
@@ -62,6 +102,11 @@ func (c *Clientset) AppmeshV1beta1() appmeshv1beta1.AppmeshV1beta1Interface {
 	return c.appmeshV1beta1
 }
 
+// ExternaldnsV1alpha1 retrieves the ExternaldnsV1alpha1Client
+func (c *Clientset) ExternaldnsV1alpha1() externaldnsv1alpha1.ExternaldnsV1alpha1Interface {
+	return c.externaldnsV1alpha1
+}
+
 // FlaggerV1beta1 retrieves the FlaggerV1beta1Client
 func (c *Clientset) FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface {
 	return c.flaggerV1beta1
@@ -72,11 +117,36 @@ func (c *Clientset) GlooV1() gloov1.GlooV1Interface {
 	return c.glooV1
 }
 
+// GatewayV1 retrieves the GatewayV1Client
+func (c *Clientset) GatewayV1() gatewayv1.GatewayV1Interface {
+	return c.gatewayV1
+}
+
 // NetworkingV1alpha3 retrieves the NetworkingV1alpha3Client
 func (c *Clientset) NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3Interface {
 	return c.networkingV1alpha3
 }
 
+// KedaV1alpha1 retrieves the KedaV1alpha1Client
+func (c *Clientset) KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface {
+	return c.kedaV1alpha1
+}
+
+// ConfigurationV1 retrieves the ConfigurationV1Client
+func (c *Clientset) ConfigurationV1() configurationv1.ConfigurationV1Interface {
+	return c.configurationV1
+}
+
+// KumaV1alpha1 retrieves the KumaV1alpha1Client
+func (c *Clientset) KumaV1alpha1() kumav1alpha1.KumaV1alpha1Interface {
+	return c.kumaV1alpha1
+}
+
+// MonitoringV1 retrieves the MonitoringV1Client
+func (c *Clientset) MonitoringV1() monitoringv1.MonitoringV1Interface {
+	return c.monitoringV1
+}
+
 // ProjectcontourV1 retrieves the ProjectcontourV1Client
 func (c *Clientset) ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface {
 	return c.projectcontourV1
@@ -92,6 +162,16 @@ func (c *Clientset) SplitV1alpha2() splitv1alpha2.SplitV1alpha2Interface {
 	return c.splitV1alpha2
 }
 
+// SplitV1alpha3 retrieves the SplitV1alpha3Client
+func (c *Clientset) SplitV1alpha3() splitv1alpha3.SplitV1alpha3Interface {
+	return c.splitV1alpha3
+}
+
+// SpecsV1alpha3 retrieves the SpecsV1alpha3Client
+func (c *Clientset) SpecsV1alpha3() specsv1alpha3.SpecsV1alpha3Interface {
+	return c.specsV1alpha3
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -113,10 +193,22 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	}
 	var cs Clientset
 	var err error
+	cs.getambassadorV2, err = getambassadorv2.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.apisixV2, err = apisixv2.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.appmeshV1beta1, err = appmeshv1beta1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
 	}
+	cs.externaldnsV1alpha1, err = externaldnsv1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.flaggerV1beta1, err = flaggerv1beta1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
@@ -125,10 +217,30 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.gatewayV1, err = gatewayv1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.networkingV1alpha3, err = networkingv1alpha3.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
 	}
+	cs.kedaV1alpha1, err = kedav1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.configurationV1, err = configurationv1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.kumaV1alpha1, err = kumav1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.monitoringV1, err = monitoringv1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.projectcontourV1, err = projectcontourv1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
@@ -141,6 +253,14 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.splitV1alpha3, err = splitv1alpha3.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.specsV1alpha3, err = specsv1alpha3.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -153,13 +273,23 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 // panics if there is an error in the config.
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
+	cs.getambassadorV2 = getambassadorv2.NewForConfigOrDie(c)
+	cs.apisixV2 = apisixv2.NewForConfigOrDie(c)
 	cs.appmeshV1beta1 = appmeshv1beta1.NewForConfigOrDie(c)
+	cs.externaldnsV1alpha1 = externaldnsv1alpha1.NewForConfigOrDie(c)
 	cs.flaggerV1beta1 = flaggerv1beta1.NewForConfigOrDie(c)
 	cs.glooV1 = gloov1.NewForConfigOrDie(c)
+	cs.gatewayV1 = gatewayv1.NewForConfigOrDie(c)
 	cs.networkingV1alpha3 = networkingv1alpha3.NewForConfigOrDie(c)
+	cs.kedaV1alpha1 = kedav1alpha1.NewForConfigOrDie(c)
+	cs.configurationV1 = configurationv1.NewForConfigOrDie(c)
+	cs.kumaV1alpha1 = kumav1alpha1.NewForConfigOrDie(c)
+	cs.monitoringV1 = monitoringv1.NewForConfigOrDie(c)
 	cs.projectcontourV1 = projectcontourv1.NewForConfigOrDie(c)
 	cs.splitV1alpha1 = splitv1alpha1.NewForConfigOrDie(c)
 	cs.splitV1alpha2 = splitv1alpha2.NewForConfigOrDie(c)
+	cs.splitV1alpha3 = splitv1alpha3.NewForConfigOrDie(c)
+	cs.specsV1alpha3 = specsv1alpha3.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -168,13 +298,23 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 // New creates a new Clientset for the given RESTClient.
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
+	cs.getambassadorV2 = getambassadorv2.New(c)
+	cs.apisixV2 = apisixv2.New(c)
 	cs.appmeshV1beta1 = appmeshv1beta1.New(c)
+	cs.externaldnsV1alpha1 = externaldnsv1alpha1.New(c)
 	cs.flaggerV1beta1 = flaggerv1beta1.New(c)
 	cs.glooV1 = gloov1.New(c)
+	cs.gatewayV1 = gatewayv1.New(c)
 	cs.networkingV1alpha3 = networkingv1alpha3.New(c)
+	cs.kedaV1alpha1 = kedav1alpha1.New(c)
+	cs.configurationV1 = configurationv1.New(c)
+	cs.kumaV1alpha1 = kumav1alpha1.New(c)
+	cs.monitoringV1 = monitoringv1.New(c)
 	cs.projectcontourV1 = projectcontourv1.New(c)
 	cs.splitV1alpha1 = splitv1alpha1.New(c)
 	cs.splitV1alpha2 = splitv1alpha2.New(c)
+	cs.splitV1alpha3 = splitv1alpha3.New(c)
+	cs.specsV1alpha3 = specsv1alpha3.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -20,20 +20,40 @@ package fake
 
 import (
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+	getambassadorv2 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/ambassador/v2"
+	fakegetambassadorv2 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/ambassador/v2/fake"
+	apisixv2 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/apisix/v2"
+	fakeapisixv2 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/apisix/v2/fake"
 	appmeshv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1"
 	fakeappmeshv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1/fake"
+	externaldnsv1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/externaldns/v1alpha1"
+	fakeexternaldnsv1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/externaldns/v1alpha1/fake"
 	flaggerv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1"
 	fakeflaggerv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1/fake"
 	gloov1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/gloo/v1"
 	fakegloov1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/gloo/v1/fake"
+	gatewayv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/gloogateway/v1"
+	fakegatewayv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/gloogateway/v1/fake"
 	networkingv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3"
 	fakenetworkingv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3/fake"
+	kedav1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1"
+	fakekedav1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1/fake"
+	configurationv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/kong/v1"
+	fakeconfigurationv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/kong/v1/fake"
+	kumav1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/kuma/v1alpha1"
+	fakekumav1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/kuma/v1alpha1/fake"
+	monitoringv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/monitoring/v1"
+	fakemonitoringv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/monitoring/v1/fake"
 	projectcontourv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1"
 	fakeprojectcontourv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1/fake"
 	splitv1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1"
 	fakesplitv1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1/fake"
 	splitv1alpha2 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha2"
 	fakesplitv1alpha2 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha2/fake"
+	splitv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha3"
+	fakesplitv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha3/fake"
+	specsv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smispecs/v1alpha3"
+	fakespecsv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smispecs/v1alpha3/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -88,11 +108,26 @@ func (c *Clientset) Tracker() testing.ObjectTracker {
 
 var _ clientset.Interface = &Clientset{}
 
+// GetambassadorV2 retrieves the GetambassadorV2Client
+func (c *Clientset) GetambassadorV2() getambassadorv2.GetambassadorV2Interface {
+	return &fakegetambassadorv2.FakeGetambassadorV2{Fake: &c.Fake}
+}
+
+// ApisixV2 retrieves the ApisixV2Client
+func (c *Clientset) ApisixV2() apisixv2.ApisixV2Interface {
+	return &fakeapisixv2.FakeApisixV2{Fake: &c.Fake}
+}
+
 // AppmeshV1beta1 retrieves the AppmeshV1beta1Client
 func (c *Clientset) AppmeshV1beta1() appmeshv1beta1.AppmeshV1beta1Interface {
 	return &fakeappmeshv1beta1.FakeAppmeshV1beta1{Fake: &c.Fake}
 }
 
+// ExternaldnsV1alpha1 retrieves the ExternaldnsV1alpha1Client
+func (c *Clientset) ExternaldnsV1alpha1() externaldnsv1alpha1.ExternaldnsV1alpha1Interface {
+	return &fakeexternaldnsv1alpha1.FakeExternaldnsV1alpha1{Fake: &c.Fake}
+}
+
 // FlaggerV1beta1 retrieves the FlaggerV1beta1Client
 func (c *Clientset) FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface {
 	return &fakeflaggerv1beta1.FakeFlaggerV1beta1{Fake: &c.Fake}
@@ -103,11 +138,36 @@ func (c *Clientset) GlooV1() gloov1.GlooV1Interface {
 	return &fakegloov1.FakeGlooV1{Fake: &c.Fake}
 }
 
+// GatewayV1 retrieves the GatewayV1Client
+func (c *Clientset) GatewayV1() gatewayv1.GatewayV1Interface {
+	return &fakegatewayv1.FakeGatewayV1{Fake: &c.Fake}
+}
+
 // NetworkingV1alpha3 retrieves the NetworkingV1alpha3Client
 func (c *Clientset) NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3Interface {
 	return &fakenetworkingv1alpha3.FakeNetworkingV1alpha3{Fake: &c.Fake}
 }
 
+// KedaV1alpha1 retrieves the KedaV1alpha1Client
+func (c *Clientset) KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface {
+	return &fakekedav1alpha1.FakeKedaV1alpha1{Fake: &c.Fake}
+}
+
+// ConfigurationV1 retrieves the ConfigurationV1Client
+func (c *Clientset) ConfigurationV1() configurationv1.ConfigurationV1Interface {
+	return &fakeconfigurationv1.FakeConfigurationV1{Fake: &c.Fake}
+}
+
+// KumaV1alpha1 retrieves the KumaV1alpha1Client
+func (c *Clientset) KumaV1alpha1() kumav1alpha1.KumaV1alpha1Interface {
+	return &fakekumav1alpha1.FakeKumaV1alpha1{Fake: &c.Fake}
+}
+
+// MonitoringV1 retrieves the MonitoringV1Client
+func (c *Clientset) MonitoringV1() monitoringv1.MonitoringV1Interface {
+	return &fakemonitoringv1.FakeMonitoringV1{Fake: &c.Fake}
+}
+
 // ProjectcontourV1 retrieves the ProjectcontourV1Client
 func (c *Clientset) ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface {
 	return &fakeprojectcontourv1.FakeProjectcontourV1{Fake: &c.Fake}
@@ -122,3 +182,13 @@ func (c *Clientset) SplitV1alpha1() splitv1alpha1.SplitV1alpha1Interface {
 func (c *Clientset) SplitV1alpha2() splitv1alpha2.SplitV1alpha2Interface {
 	return &fakesplitv1alpha2.FakeSplitV1alpha2{Fake: &c.Fake}
 }
+
+// SplitV1alpha3 retrieves the SplitV1alpha3Client
+func (c *Clientset) SplitV1alpha3() splitv1alpha3.SplitV1alpha3Interface {
+	return &fakesplitv1alpha3.FakeSplitV1alpha3{Fake: &c.Fake}
+}
+
+// SpecsV1alpha3 retrieves the SpecsV1alpha3Client
+func (c *Clientset) SpecsV1alpha3() specsv1alpha3.SpecsV1alpha3Interface {
+	return &fakespecsv1alpha3.FakeSpecsV1alpha3{Fake: &c.Fake}
+}
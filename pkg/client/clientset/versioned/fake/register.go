@@ -19,13 +19,23 @@ limitations under the License.
 package fake
 
 import (
+	getambassadorv2 "github.com/weaveworks/flagger/pkg/apis/ambassador/v2"
+	apisixv2 "github.com/weaveworks/flagger/pkg/apis/apisix/v2"
 	appmeshv1beta1 "github.com/weaveworks/flagger/pkg/apis/appmesh/v1beta1"
+	externaldnsv1alpha1 "github.com/weaveworks/flagger/pkg/apis/externaldns/v1alpha1"
 	flaggerv1beta1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	gloov1 "github.com/weaveworks/flagger/pkg/apis/gloo/v1"
+	gatewayv1 "github.com/weaveworks/flagger/pkg/apis/gloogateway/v1"
 	networkingv1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+	kedav1alpha1 "github.com/weaveworks/flagger/pkg/apis/keda/v1alpha1"
+	configurationv1 "github.com/weaveworks/flagger/pkg/apis/kong/v1"
+	kumav1alpha1 "github.com/weaveworks/flagger/pkg/apis/kuma/v1alpha1"
+	monitoringv1 "github.com/weaveworks/flagger/pkg/apis/monitoring/v1"
 	projectcontourv1 "github.com/weaveworks/flagger/pkg/apis/projectcontour/v1"
 	splitv1alpha1 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha1"
 	splitv1alpha2 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha2"
+	splitv1alpha3 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha3"
+	specsv1alpha3 "github.com/weaveworks/flagger/pkg/apis/smispecs/v1alpha3"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -37,26 +47,36 @@ var scheme = runtime.NewScheme()
 var codecs = serializer.NewCodecFactory(scheme)
 var parameterCodec = runtime.NewParameterCodec(scheme)
 var localSchemeBuilder = runtime.SchemeBuilder{
+	getambassadorv2.AddToScheme,
+	apisixv2.AddToScheme,
 	appmeshv1beta1.AddToScheme,
+	externaldnsv1alpha1.AddToScheme,
 	flaggerv1beta1.AddToScheme,
 	gloov1.AddToScheme,
+	gatewayv1.AddToScheme,
 	networkingv1alpha3.AddToScheme,
+	kedav1alpha1.AddToScheme,
+	configurationv1.AddToScheme,
+	kumav1alpha1.AddToScheme,
+	monitoringv1.AddToScheme,
 	projectcontourv1.AddToScheme,
 	splitv1alpha1.AddToScheme,
 	splitv1alpha2.AddToScheme,
+	splitv1alpha3.AddToScheme,
+	specsv1alpha3.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
 // of clientsets, like in:
 //
-//   import (
-//     "k8s.io/client-go/kubernetes"
-//     clientsetscheme "k8s.io/client-go/kubernetes/scheme"
-//     aggregatorclientsetscheme "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset/scheme"
-//   )
+//	import (
+//	  "k8s.io/client-go/kubernetes"
+//	  clientsetscheme "k8s.io/client-go/kubernetes/scheme"
+//	  aggregatorclientsetscheme "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset/scheme"
+//	)
 //
-//   kclientset, _ := kubernetes.NewForConfig(c)
-//   _ = aggregatorclientsetscheme.AddToScheme(clientsetscheme.Scheme)
+//	kclientset, _ := kubernetes.NewForConfig(c)
+//	_ = aggregatorclientsetscheme.AddToScheme(clientsetscheme.Scheme)
 //
 // After this, RawExtensions in Kubernetes types will serialize kube-aggregator types
 // correctly.
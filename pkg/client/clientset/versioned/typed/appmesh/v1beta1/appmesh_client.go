@@ -26,6 +26,7 @@ import (
 
 type AppmeshV1beta1Interface interface {
 	RESTClient() rest.Interface
+	GatewayRoutesGetter
 	MeshesGetter
 	VirtualNodesGetter
 	VirtualServicesGetter
@@ -36,6 +37,10 @@ type AppmeshV1beta1Client struct {
 	restClient rest.Interface
 }
 
+func (c *AppmeshV1beta1Client) GatewayRoutes(namespace string) GatewayRouteInterface {
+	return newGatewayRoutes(c, namespace)
+}
+
 func (c *AppmeshV1beta1Client) Meshes() MeshInterface {
 	return newMeshes(c)
 }
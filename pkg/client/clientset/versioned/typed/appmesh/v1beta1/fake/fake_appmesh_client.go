@@ -28,6 +28,10 @@ type FakeAppmeshV1beta1 struct {
 	*testing.Fake
 }
 
+func (c *FakeAppmeshV1beta1) GatewayRoutes(namespace string) v1beta1.GatewayRouteInterface {
+	return &FakeGatewayRoutes{c, namespace}
+}
+
 func (c *FakeAppmeshV1beta1) Meshes() v1beta1.MeshInterface {
 	return &FakeMeshes{c}
 }
@@ -0,0 +1,140 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1beta1 "github.com/weaveworks/flagger/pkg/apis/appmesh/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeGatewayRoutes implements GatewayRouteInterface
+type FakeGatewayRoutes struct {
+	Fake *FakeAppmeshV1beta1
+	ns   string
+}
+
+var gatewayroutesResource = schema.GroupVersionResource{Group: "appmesh.k8s.aws", Version: "v1beta1", Resource: "gatewayroutes"}
+
+var gatewayroutesKind = schema.GroupVersionKind{Group: "appmesh.k8s.aws", Version: "v1beta1", Kind: "GatewayRoute"}
+
+// Get takes name of the gatewayRoute, and returns the corresponding gatewayRoute object, and an error if there is any.
+func (c *FakeGatewayRoutes) Get(name string, options v1.GetOptions) (result *v1beta1.GatewayRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(gatewayroutesResource, c.ns, name), &v1beta1.GatewayRoute{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.GatewayRoute), err
+}
+
+// List takes label and field selectors, and returns the list of GatewayRoutes that match those selectors.
+func (c *FakeGatewayRoutes) List(opts v1.ListOptions) (result *v1beta1.GatewayRouteList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(gatewayroutesResource, gatewayroutesKind, c.ns, opts), &v1beta1.GatewayRouteList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.GatewayRouteList{ListMeta: obj.(*v1beta1.GatewayRouteList).ListMeta}
+	for _, item := range obj.(*v1beta1.GatewayRouteList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested gatewayRoutes.
+func (c *FakeGatewayRoutes) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(gatewayroutesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a gatewayRoute and creates it.  Returns the server's representation of the gatewayRoute, and an error, if there is any.
+func (c *FakeGatewayRoutes) Create(gatewayRoute *v1beta1.GatewayRoute) (result *v1beta1.GatewayRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(gatewayroutesResource, c.ns, gatewayRoute), &v1beta1.GatewayRoute{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.GatewayRoute), err
+}
+
+// Update takes the representation of a gatewayRoute and updates it. Returns the server's representation of the gatewayRoute, and an error, if there is any.
+func (c *FakeGatewayRoutes) Update(gatewayRoute *v1beta1.GatewayRoute) (result *v1beta1.GatewayRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(gatewayroutesResource, c.ns, gatewayRoute), &v1beta1.GatewayRoute{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.GatewayRoute), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeGatewayRoutes) UpdateStatus(gatewayRoute *v1beta1.GatewayRoute) (*v1beta1.GatewayRoute, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(gatewayroutesResource, "status", c.ns, gatewayRoute), &v1beta1.GatewayRoute{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.GatewayRoute), err
+}
+
+// Delete takes name of the gatewayRoute and deletes it. Returns an error if one occurs.
+func (c *FakeGatewayRoutes) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(gatewayroutesResource, c.ns, name), &v1beta1.GatewayRoute{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeGatewayRoutes) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(gatewayroutesResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.GatewayRouteList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched gatewayRoute.
+func (c *FakeGatewayRoutes) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.GatewayRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(gatewayroutesResource, c.ns, name, pt, data, subresources...), &v1beta1.GatewayRoute{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.GatewayRoute), err
+}
@@ -0,0 +1,191 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"time"
+
+	v1beta1 "github.com/weaveworks/flagger/pkg/apis/appmesh/v1beta1"
+	scheme "github.com/weaveworks/flagger/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// GatewayRoutesGetter has a method to return a GatewayRouteInterface.
+// A group's client should implement this interface.
+type GatewayRoutesGetter interface {
+	GatewayRoutes(namespace string) GatewayRouteInterface
+}
+
+// GatewayRouteInterface has methods to work with GatewayRoute resources.
+type GatewayRouteInterface interface {
+	Create(*v1beta1.GatewayRoute) (*v1beta1.GatewayRoute, error)
+	Update(*v1beta1.GatewayRoute) (*v1beta1.GatewayRoute, error)
+	UpdateStatus(*v1beta1.GatewayRoute) (*v1beta1.GatewayRoute, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1beta1.GatewayRoute, error)
+	List(opts v1.ListOptions) (*v1beta1.GatewayRouteList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.GatewayRoute, err error)
+	GatewayRouteExpansion
+}
+
+// gatewayRoutes implements GatewayRouteInterface
+type gatewayRoutes struct {
+	client rest.Interface
+	ns     string
+}
+
+// newGatewayRoutes returns a GatewayRoutes
+func newGatewayRoutes(c *AppmeshV1beta1Client, namespace string) *gatewayRoutes {
+	return &gatewayRoutes{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the gatewayRoute, and returns the corresponding gatewayRoute object, and an error if there is any.
+func (c *gatewayRoutes) Get(name string, options v1.GetOptions) (result *v1beta1.GatewayRoute, err error) {
+	result = &v1beta1.GatewayRoute{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("gatewayroutes").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of GatewayRoutes that match those selectors.
+func (c *gatewayRoutes) List(opts v1.ListOptions) (result *v1beta1.GatewayRouteList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.GatewayRouteList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("gatewayroutes").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested gatewayRoutes.
+func (c *gatewayRoutes) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("gatewayroutes").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a gatewayRoute and creates it.  Returns the server's representation of the gatewayRoute, and an error, if there is any.
+func (c *gatewayRoutes) Create(gatewayRoute *v1beta1.GatewayRoute) (result *v1beta1.GatewayRoute, err error) {
+	result = &v1beta1.GatewayRoute{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("gatewayroutes").
+		Body(gatewayRoute).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a gatewayRoute and updates it. Returns the server's representation of the gatewayRoute, and an error, if there is any.
+func (c *gatewayRoutes) Update(gatewayRoute *v1beta1.GatewayRoute) (result *v1beta1.GatewayRoute, err error) {
+	result = &v1beta1.GatewayRoute{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("gatewayroutes").
+		Name(gatewayRoute.Name).
+		Body(gatewayRoute).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *gatewayRoutes) UpdateStatus(gatewayRoute *v1beta1.GatewayRoute) (result *v1beta1.GatewayRoute, err error) {
+	result = &v1beta1.GatewayRoute{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("gatewayroutes").
+		Name(gatewayRoute.Name).
+		SubResource("status").
+		Body(gatewayRoute).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the gatewayRoute and deletes it. Returns an error if one occurs.
+func (c *gatewayRoutes) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("gatewayroutes").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *gatewayRoutes) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("gatewayroutes").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched gatewayRoute.
+func (c *gatewayRoutes) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.GatewayRoute, err error) {
+	result = &v1beta1.GatewayRoute{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("gatewayroutes").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -18,6 +18,8 @@ limitations under the License.
 
 package v1beta1
 
+type GatewayRouteExpansion interface{}
+
 type MeshExpansion interface{}
 
 type VirtualNodeExpansion interface{}
@@ -0,0 +1,164 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"time"
+
+	v1beta1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	scheme "github.com/weaveworks/flagger/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// AlertRoutesGetter has a method to return a AlertRouteInterface.
+// A group's client should implement this interface.
+type AlertRoutesGetter interface {
+	AlertRoutes() AlertRouteInterface
+}
+
+// AlertRouteInterface has methods to work with AlertRoute resources.
+type AlertRouteInterface interface {
+	Create(*v1beta1.AlertRoute) (*v1beta1.AlertRoute, error)
+	Update(*v1beta1.AlertRoute) (*v1beta1.AlertRoute, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1beta1.AlertRoute, error)
+	List(opts v1.ListOptions) (*v1beta1.AlertRouteList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.AlertRoute, err error)
+	AlertRouteExpansion
+}
+
+// alertRoutes implements AlertRouteInterface
+type alertRoutes struct {
+	client rest.Interface
+}
+
+// newAlertRoutes returns a AlertRoutes
+func newAlertRoutes(c *FlaggerV1beta1Client) *alertRoutes {
+	return &alertRoutes{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the alertRoute, and returns the corresponding alertRoute object, and an error if there is any.
+func (c *alertRoutes) Get(name string, options v1.GetOptions) (result *v1beta1.AlertRoute, err error) {
+	result = &v1beta1.AlertRoute{}
+	err = c.client.Get().
+		Resource("alertroutes").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of AlertRoutes that match those selectors.
+func (c *alertRoutes) List(opts v1.ListOptions) (result *v1beta1.AlertRouteList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.AlertRouteList{}
+	err = c.client.Get().
+		Resource("alertroutes").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested alertRoutes.
+func (c *alertRoutes) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("alertroutes").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a alertRoute and creates it.  Returns the server's representation of the alertRoute, and an error, if there is any.
+func (c *alertRoutes) Create(alertRoute *v1beta1.AlertRoute) (result *v1beta1.AlertRoute, err error) {
+	result = &v1beta1.AlertRoute{}
+	err = c.client.Post().
+		Resource("alertroutes").
+		Body(alertRoute).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a alertRoute and updates it. Returns the server's representation of the alertRoute, and an error, if there is any.
+func (c *alertRoutes) Update(alertRoute *v1beta1.AlertRoute) (result *v1beta1.AlertRoute, err error) {
+	result = &v1beta1.AlertRoute{}
+	err = c.client.Put().
+		Resource("alertroutes").
+		Name(alertRoute.Name).
+		Body(alertRoute).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the alertRoute and deletes it. Returns an error if one occurs.
+func (c *alertRoutes) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("alertroutes").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *alertRoutes) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("alertroutes").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched alertRoute.
+func (c *alertRoutes) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.AlertRoute, err error) {
+	result = &v1beta1.AlertRoute{}
+	err = c.client.Patch(pt).
+		Resource("alertroutes").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,164 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"time"
+
+	v1beta1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	scheme "github.com/weaveworks/flagger/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// CanaryClassesGetter has a method to return a CanaryClassInterface.
+// A group's client should implement this interface.
+type CanaryClassesGetter interface {
+	CanaryClasses() CanaryClassInterface
+}
+
+// CanaryClassInterface has methods to work with CanaryClass resources.
+type CanaryClassInterface interface {
+	Create(*v1beta1.CanaryClass) (*v1beta1.CanaryClass, error)
+	Update(*v1beta1.CanaryClass) (*v1beta1.CanaryClass, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1beta1.CanaryClass, error)
+	List(opts v1.ListOptions) (*v1beta1.CanaryClassList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.CanaryClass, err error)
+	CanaryClassExpansion
+}
+
+// canaryClasses implements CanaryClassInterface
+type canaryClasses struct {
+	client rest.Interface
+}
+
+// newCanaryClasses returns a CanaryClasses
+func newCanaryClasses(c *FlaggerV1beta1Client) *canaryClasses {
+	return &canaryClasses{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the canaryClass, and returns the corresponding canaryClass object, and an error if there is any.
+func (c *canaryClasses) Get(name string, options v1.GetOptions) (result *v1beta1.CanaryClass, err error) {
+	result = &v1beta1.CanaryClass{}
+	err = c.client.Get().
+		Resource("canaryclasses").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of CanaryClasses that match those selectors.
+func (c *canaryClasses) List(opts v1.ListOptions) (result *v1beta1.CanaryClassList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.CanaryClassList{}
+	err = c.client.Get().
+		Resource("canaryclasses").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested canaryClasses.
+func (c *canaryClasses) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("canaryclasses").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a canaryClass and creates it.  Returns the server's representation of the canaryClass, and an error, if there is any.
+func (c *canaryClasses) Create(canaryClass *v1beta1.CanaryClass) (result *v1beta1.CanaryClass, err error) {
+	result = &v1beta1.CanaryClass{}
+	err = c.client.Post().
+		Resource("canaryclasses").
+		Body(canaryClass).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a canaryClass and updates it. Returns the server's representation of the canaryClass, and an error, if there is any.
+func (c *canaryClasses) Update(canaryClass *v1beta1.CanaryClass) (result *v1beta1.CanaryClass, err error) {
+	result = &v1beta1.CanaryClass{}
+	err = c.client.Put().
+		Resource("canaryclasses").
+		Name(canaryClass.Name).
+		Body(canaryClass).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the canaryClass and deletes it. Returns an error if one occurs.
+func (c *canaryClasses) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("canaryclasses").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *canaryClasses) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("canaryclasses").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched canaryClass.
+func (c *canaryClasses) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.CanaryClass, err error) {
+	result = &v1beta1.CanaryClass{}
+	err = c.client.Patch(pt).
+		Resource("canaryclasses").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
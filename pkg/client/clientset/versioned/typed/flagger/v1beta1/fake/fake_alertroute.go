@@ -0,0 +1,120 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1beta1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeAlertRoutes implements AlertRouteInterface
+type FakeAlertRoutes struct {
+	Fake *FakeFlaggerV1beta1
+}
+
+var alertroutesResource = schema.GroupVersionResource{Group: "flagger.app", Version: "v1beta1", Resource: "alertroutes"}
+
+var alertroutesKind = schema.GroupVersionKind{Group: "flagger.app", Version: "v1beta1", Kind: "AlertRoute"}
+
+// Get takes name of the alertRoute, and returns the corresponding alertRoute object, and an error if there is any.
+func (c *FakeAlertRoutes) Get(name string, options v1.GetOptions) (result *v1beta1.AlertRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(alertroutesResource, name), &v1beta1.AlertRoute{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AlertRoute), err
+}
+
+// List takes label and field selectors, and returns the list of AlertRoutes that match those selectors.
+func (c *FakeAlertRoutes) List(opts v1.ListOptions) (result *v1beta1.AlertRouteList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(alertroutesResource, alertroutesKind, opts), &v1beta1.AlertRouteList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.AlertRouteList{ListMeta: obj.(*v1beta1.AlertRouteList).ListMeta}
+	for _, item := range obj.(*v1beta1.AlertRouteList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested alertRoutes.
+func (c *FakeAlertRoutes) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(alertroutesResource, opts))
+}
+
+// Create takes the representation of a alertRoute and creates it.  Returns the server's representation of the alertRoute, and an error, if there is any.
+func (c *FakeAlertRoutes) Create(alertRoute *v1beta1.AlertRoute) (result *v1beta1.AlertRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(alertroutesResource, alertRoute), &v1beta1.AlertRoute{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AlertRoute), err
+}
+
+// Update takes the representation of a alertRoute and updates it. Returns the server's representation of the alertRoute, and an error, if there is any.
+func (c *FakeAlertRoutes) Update(alertRoute *v1beta1.AlertRoute) (result *v1beta1.AlertRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(alertroutesResource, alertRoute), &v1beta1.AlertRoute{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AlertRoute), err
+}
+
+// Delete takes name of the alertRoute and deletes it. Returns an error if one occurs.
+func (c *FakeAlertRoutes) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(alertroutesResource, name), &v1beta1.AlertRoute{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeAlertRoutes) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(alertroutesResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.AlertRouteList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched alertRoute.
+func (c *FakeAlertRoutes) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.AlertRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(alertroutesResource, name, pt, data, subresources...), &v1beta1.AlertRoute{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AlertRoute), err
+}
@@ -0,0 +1,120 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1beta1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeCanaryClasses implements CanaryClassInterface
+type FakeCanaryClasses struct {
+	Fake *FakeFlaggerV1beta1
+}
+
+var canaryclassesResource = schema.GroupVersionResource{Group: "flagger.app", Version: "v1beta1", Resource: "canaryclasses"}
+
+var canaryclassesKind = schema.GroupVersionKind{Group: "flagger.app", Version: "v1beta1", Kind: "CanaryClass"}
+
+// Get takes name of the canaryClass, and returns the corresponding canaryClass object, and an error if there is any.
+func (c *FakeCanaryClasses) Get(name string, options v1.GetOptions) (result *v1beta1.CanaryClass, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(canaryclassesResource, name), &v1beta1.CanaryClass{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryClass), err
+}
+
+// List takes label and field selectors, and returns the list of CanaryClasses that match those selectors.
+func (c *FakeCanaryClasses) List(opts v1.ListOptions) (result *v1beta1.CanaryClassList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(canaryclassesResource, canaryclassesKind, opts), &v1beta1.CanaryClassList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.CanaryClassList{ListMeta: obj.(*v1beta1.CanaryClassList).ListMeta}
+	for _, item := range obj.(*v1beta1.CanaryClassList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested canaryClasses.
+func (c *FakeCanaryClasses) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(canaryclassesResource, opts))
+}
+
+// Create takes the representation of a canaryClass and creates it.  Returns the server's representation of the canaryClass, and an error, if there is any.
+func (c *FakeCanaryClasses) Create(canaryClass *v1beta1.CanaryClass) (result *v1beta1.CanaryClass, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(canaryclassesResource, canaryClass), &v1beta1.CanaryClass{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryClass), err
+}
+
+// Update takes the representation of a canaryClass and updates it. Returns the server's representation of the canaryClass, and an error, if there is any.
+func (c *FakeCanaryClasses) Update(canaryClass *v1beta1.CanaryClass) (result *v1beta1.CanaryClass, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(canaryclassesResource, canaryClass), &v1beta1.CanaryClass{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryClass), err
+}
+
+// Delete takes name of the canaryClass and deletes it. Returns an error if one occurs.
+func (c *FakeCanaryClasses) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(canaryclassesResource, name), &v1beta1.CanaryClass{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeCanaryClasses) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(canaryclassesResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.CanaryClassList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched canaryClass.
+func (c *FakeCanaryClasses) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.CanaryClass, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(canaryclassesResource, name, pt, data, subresources...), &v1beta1.CanaryClass{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryClass), err
+}
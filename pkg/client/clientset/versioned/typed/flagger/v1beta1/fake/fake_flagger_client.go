@@ -32,10 +32,18 @@ func (c *FakeFlaggerV1beta1) AlertProviders(namespace string) v1beta1.AlertProvi
 	return &FakeAlertProviders{c, namespace}
 }
 
+func (c *FakeFlaggerV1beta1) AlertRoutes() v1beta1.AlertRouteInterface {
+	return &FakeAlertRoutes{c}
+}
+
 func (c *FakeFlaggerV1beta1) Canaries(namespace string) v1beta1.CanaryInterface {
 	return &FakeCanaries{c, namespace}
 }
 
+func (c *FakeFlaggerV1beta1) CanaryClasses() v1beta1.CanaryClassInterface {
+	return &FakeCanaryClasses{c}
+}
+
 func (c *FakeFlaggerV1beta1) MetricTemplates(namespace string) v1beta1.MetricTemplateInterface {
 	return &FakeMetricTemplates{c, namespace}
 }
@@ -27,7 +27,9 @@ import (
 type FlaggerV1beta1Interface interface {
 	RESTClient() rest.Interface
 	AlertProvidersGetter
+	AlertRoutesGetter
 	CanariesGetter
+	CanaryClassesGetter
 	MetricTemplatesGetter
 }
 
@@ -40,10 +42,18 @@ func (c *FlaggerV1beta1Client) AlertProviders(namespace string) AlertProviderInt
 	return newAlertProviders(c, namespace)
 }
 
+func (c *FlaggerV1beta1Client) AlertRoutes() AlertRouteInterface {
+	return newAlertRoutes(c)
+}
+
 func (c *FlaggerV1beta1Client) Canaries(namespace string) CanaryInterface {
 	return newCanaries(c, namespace)
 }
 
+func (c *FlaggerV1beta1Client) CanaryClasses() CanaryClassInterface {
+	return newCanaryClasses(c)
+}
+
 func (c *FlaggerV1beta1Client) MetricTemplates(namespace string) MetricTemplateInterface {
 	return newMetricTemplates(c, namespace)
 }
@@ -20,6 +20,10 @@ package v1beta1
 
 type AlertProviderExpansion interface{}
 
+type AlertRouteExpansion interface{}
+
 type CanaryExpansion interface{}
 
+type CanaryClassExpansion interface{}
+
 type MetricTemplateExpansion interface{}
@@ -0,0 +1,128 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	kongv1 "github.com/weaveworks/flagger/pkg/apis/kong/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeKongPlugins implements KongPluginInterface
+type FakeKongPlugins struct {
+	Fake *FakeConfigurationV1
+	ns   string
+}
+
+var kongpluginsResource = schema.GroupVersionResource{Group: "configuration.konghq.com", Version: "v1", Resource: "kongplugins"}
+
+var kongpluginsKind = schema.GroupVersionKind{Group: "configuration.konghq.com", Version: "v1", Kind: "KongPlugin"}
+
+// Get takes name of the kongPlugin, and returns the corresponding kongPlugin object, and an error if there is any.
+func (c *FakeKongPlugins) Get(name string, options v1.GetOptions) (result *kongv1.KongPlugin, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(kongpluginsResource, c.ns, name), &kongv1.KongPlugin{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*kongv1.KongPlugin), err
+}
+
+// List takes label and field selectors, and returns the list of KongPlugins that match those selectors.
+func (c *FakeKongPlugins) List(opts v1.ListOptions) (result *kongv1.KongPluginList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(kongpluginsResource, kongpluginsKind, c.ns, opts), &kongv1.KongPluginList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &kongv1.KongPluginList{ListMeta: obj.(*kongv1.KongPluginList).ListMeta}
+	for _, item := range obj.(*kongv1.KongPluginList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested kongPlugins.
+func (c *FakeKongPlugins) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(kongpluginsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a kongPlugin and creates it.  Returns the server's representation of the kongPlugin, and an error, if there is any.
+func (c *FakeKongPlugins) Create(kongPlugin *kongv1.KongPlugin) (result *kongv1.KongPlugin, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(kongpluginsResource, c.ns, kongPlugin), &kongv1.KongPlugin{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*kongv1.KongPlugin), err
+}
+
+// Update takes the representation of a kongPlugin and updates it. Returns the server's representation of the kongPlugin, and an error, if there is any.
+func (c *FakeKongPlugins) Update(kongPlugin *kongv1.KongPlugin) (result *kongv1.KongPlugin, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(kongpluginsResource, c.ns, kongPlugin), &kongv1.KongPlugin{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*kongv1.KongPlugin), err
+}
+
+// Delete takes name of the kongPlugin and deletes it. Returns an error if one occurs.
+func (c *FakeKongPlugins) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(kongpluginsResource, c.ns, name), &kongv1.KongPlugin{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeKongPlugins) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(kongpluginsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &kongv1.KongPluginList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched kongPlugin.
+func (c *FakeKongPlugins) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *kongv1.KongPlugin, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(kongpluginsResource, c.ns, name, pt, data, subresources...), &kongv1.KongPlugin{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*kongv1.KongPlugin), err
+}
@@ -0,0 +1,164 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1alpha1 "github.com/weaveworks/flagger/pkg/apis/kuma/v1alpha1"
+	scheme "github.com/weaveworks/flagger/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// TrafficRoutesGetter has a method to return a TrafficRouteInterface.
+// A group's client should implement this interface.
+type TrafficRoutesGetter interface {
+	TrafficRoutes() TrafficRouteInterface
+}
+
+// TrafficRouteInterface has methods to work with TrafficRoute resources.
+type TrafficRouteInterface interface {
+	Create(*v1alpha1.TrafficRoute) (*v1alpha1.TrafficRoute, error)
+	Update(*v1alpha1.TrafficRoute) (*v1alpha1.TrafficRoute, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.TrafficRoute, error)
+	List(opts v1.ListOptions) (*v1alpha1.TrafficRouteList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.TrafficRoute, err error)
+	TrafficRouteExpansion
+}
+
+// trafficRoutes implements TrafficRouteInterface
+type trafficRoutes struct {
+	client rest.Interface
+}
+
+// newTrafficRoutes returns a TrafficRoutes
+func newTrafficRoutes(c *KumaV1alpha1Client) *trafficRoutes {
+	return &trafficRoutes{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the trafficRoute, and returns the corresponding trafficRoute object, and an error if there is any.
+func (c *trafficRoutes) Get(name string, options v1.GetOptions) (result *v1alpha1.TrafficRoute, err error) {
+	result = &v1alpha1.TrafficRoute{}
+	err = c.client.Get().
+		Resource("trafficroutes").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of TrafficRoutes that match those selectors.
+func (c *trafficRoutes) List(opts v1.ListOptions) (result *v1alpha1.TrafficRouteList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.TrafficRouteList{}
+	err = c.client.Get().
+		Resource("trafficroutes").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested trafficRoutes.
+func (c *trafficRoutes) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("trafficroutes").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a trafficRoute and creates it.  Returns the server's representation of the trafficRoute, and an error, if there is any.
+func (c *trafficRoutes) Create(trafficRoute *v1alpha1.TrafficRoute) (result *v1alpha1.TrafficRoute, err error) {
+	result = &v1alpha1.TrafficRoute{}
+	err = c.client.Post().
+		Resource("trafficroutes").
+		Body(trafficRoute).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a trafficRoute and updates it. Returns the server's representation of the trafficRoute, and an error, if there is any.
+func (c *trafficRoutes) Update(trafficRoute *v1alpha1.TrafficRoute) (result *v1alpha1.TrafficRoute, err error) {
+	result = &v1alpha1.TrafficRoute{}
+	err = c.client.Put().
+		Resource("trafficroutes").
+		Name(trafficRoute.Name).
+		Body(trafficRoute).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the trafficRoute and deletes it. Returns an error if one occurs.
+func (c *trafficRoutes) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("trafficroutes").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *trafficRoutes) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("trafficroutes").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched trafficRoute.
+func (c *trafficRoutes) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.TrafficRoute, err error) {
+	result = &v1alpha1.TrafficRoute{}
+	err = c.client.Patch(pt).
+		Resource("trafficroutes").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,174 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha3
+
+import (
+	"time"
+
+	v1alpha3 "github.com/weaveworks/flagger/pkg/apis/smispecs/v1alpha3"
+	scheme "github.com/weaveworks/flagger/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// HTTPRouteGroupsGetter has a method to return a HTTPRouteGroupInterface.
+// A group's client should implement this interface.
+type HTTPRouteGroupsGetter interface {
+	HTTPRouteGroups(namespace string) HTTPRouteGroupInterface
+}
+
+// HTTPRouteGroupInterface has methods to work with HTTPRouteGroup resources.
+type HTTPRouteGroupInterface interface {
+	Create(*v1alpha3.HTTPRouteGroup) (*v1alpha3.HTTPRouteGroup, error)
+	Update(*v1alpha3.HTTPRouteGroup) (*v1alpha3.HTTPRouteGroup, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha3.HTTPRouteGroup, error)
+	List(opts v1.ListOptions) (*v1alpha3.HTTPRouteGroupList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha3.HTTPRouteGroup, err error)
+	HTTPRouteGroupExpansion
+}
+
+// hTTPRouteGroups implements HTTPRouteGroupInterface
+type hTTPRouteGroups struct {
+	client rest.Interface
+	ns     string
+}
+
+// newHTTPRouteGroups returns a HTTPRouteGroups
+func newHTTPRouteGroups(c *SpecsV1alpha3Client, namespace string) *hTTPRouteGroups {
+	return &hTTPRouteGroups{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the hTTPRouteGroup, and returns the corresponding hTTPRouteGroup object, and an error if there is any.
+func (c *hTTPRouteGroups) Get(name string, options v1.GetOptions) (result *v1alpha3.HTTPRouteGroup, err error) {
+	result = &v1alpha3.HTTPRouteGroup{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("httproutegroups").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of HTTPRouteGroups that match those selectors.
+func (c *hTTPRouteGroups) List(opts v1.ListOptions) (result *v1alpha3.HTTPRouteGroupList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha3.HTTPRouteGroupList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("httproutegroups").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested hTTPRouteGroups.
+func (c *hTTPRouteGroups) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("httproutegroups").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a hTTPRouteGroup and creates it.  Returns the server's representation of the hTTPRouteGroup, and an error, if there is any.
+func (c *hTTPRouteGroups) Create(hTTPRouteGroup *v1alpha3.HTTPRouteGroup) (result *v1alpha3.HTTPRouteGroup, err error) {
+	result = &v1alpha3.HTTPRouteGroup{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("httproutegroups").
+		Body(hTTPRouteGroup).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a hTTPRouteGroup and updates it. Returns the server's representation of the hTTPRouteGroup, and an error, if there is any.
+func (c *hTTPRouteGroups) Update(hTTPRouteGroup *v1alpha3.HTTPRouteGroup) (result *v1alpha3.HTTPRouteGroup, err error) {
+	result = &v1alpha3.HTTPRouteGroup{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("httproutegroups").
+		Name(hTTPRouteGroup.Name).
+		Body(hTTPRouteGroup).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the hTTPRouteGroup and deletes it. Returns an error if one occurs.
+func (c *hTTPRouteGroups) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("httproutegroups").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *hTTPRouteGroups) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("httproutegroups").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched hTTPRouteGroup.
+func (c *hTTPRouteGroups) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha3.HTTPRouteGroup, err error) {
+	result = &v1alpha3.HTTPRouteGroup{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("httproutegroups").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
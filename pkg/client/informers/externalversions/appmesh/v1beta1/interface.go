@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// GatewayRoutes returns a GatewayRouteInformer.
+	GatewayRoutes() GatewayRouteInformer
 	// Meshes returns a MeshInformer.
 	Meshes() MeshInformer
 	// VirtualNodes returns a VirtualNodeInformer.
@@ -43,6 +45,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// GatewayRoutes returns a GatewayRouteInformer.
+func (v *version) GatewayRoutes() GatewayRouteInformer {
+	return &gatewayRouteInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Meshes returns a MeshInformer.
 func (v *version) Meshes() MeshInformer {
 	return &meshInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -24,13 +24,22 @@ import (
 	time "time"
 
 	versioned "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+	ambassador "github.com/weaveworks/flagger/pkg/client/informers/externalversions/ambassador"
+	apisix "github.com/weaveworks/flagger/pkg/client/informers/externalversions/apisix"
 	appmesh "github.com/weaveworks/flagger/pkg/client/informers/externalversions/appmesh"
+	externaldns "github.com/weaveworks/flagger/pkg/client/informers/externalversions/externaldns"
 	flagger "github.com/weaveworks/flagger/pkg/client/informers/externalversions/flagger"
 	gloo "github.com/weaveworks/flagger/pkg/client/informers/externalversions/gloo"
+	gloogateway "github.com/weaveworks/flagger/pkg/client/informers/externalversions/gloogateway"
 	internalinterfaces "github.com/weaveworks/flagger/pkg/client/informers/externalversions/internalinterfaces"
 	istio "github.com/weaveworks/flagger/pkg/client/informers/externalversions/istio"
+	keda "github.com/weaveworks/flagger/pkg/client/informers/externalversions/keda"
+	kong "github.com/weaveworks/flagger/pkg/client/informers/externalversions/kong"
+	kuma "github.com/weaveworks/flagger/pkg/client/informers/externalversions/kuma"
+	monitoring "github.com/weaveworks/flagger/pkg/client/informers/externalversions/monitoring"
 	projectcontour "github.com/weaveworks/flagger/pkg/client/informers/externalversions/projectcontour"
 	smi "github.com/weaveworks/flagger/pkg/client/informers/externalversions/smi"
+	smispecs "github.com/weaveworks/flagger/pkg/client/informers/externalversions/smispecs"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -177,18 +186,39 @@ type SharedInformerFactory interface {
 	ForResource(resource schema.GroupVersionResource) (GenericInformer, error)
 	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
 
+	Getambassador() ambassador.Interface
+	Apisix() apisix.Interface
 	Appmesh() appmesh.Interface
+	Externaldns() externaldns.Interface
 	Flagger() flagger.Interface
 	Gloo() gloo.Interface
+	Gateway() gloogateway.Interface
 	Networking() istio.Interface
+	Keda() keda.Interface
+	Configuration() kong.Interface
+	Kuma() kuma.Interface
+	Monitoring() monitoring.Interface
 	Projectcontour() projectcontour.Interface
 	Split() smi.Interface
+	Specs() smispecs.Interface
+}
+
+func (f *sharedInformerFactory) Getambassador() ambassador.Interface {
+	return ambassador.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Apisix() apisix.Interface {
+	return apisix.New(f, f.namespace, f.tweakListOptions)
 }
 
 func (f *sharedInformerFactory) Appmesh() appmesh.Interface {
 	return appmesh.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Externaldns() externaldns.Interface {
+	return externaldns.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Flagger() flagger.Interface {
 	return flagger.New(f, f.namespace, f.tweakListOptions)
 }
@@ -197,10 +227,30 @@ func (f *sharedInformerFactory) Gloo() gloo.Interface {
 	return gloo.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Gateway() gloogateway.Interface {
+	return gloogateway.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Networking() istio.Interface {
 	return istio.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Keda() keda.Interface {
+	return keda.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Configuration() kong.Interface {
+	return kong.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Kuma() kuma.Interface {
+	return kuma.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Monitoring() monitoring.Interface {
+	return monitoring.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Projectcontour() projectcontour.Interface {
 	return projectcontour.New(f, f.namespace, f.tweakListOptions)
 }
@@ -208,3 +258,7 @@ func (f *sharedInformerFactory) Projectcontour() projectcontour.Interface {
 func (f *sharedInformerFactory) Split() smi.Interface {
 	return smi.New(f, f.namespace, f.tweakListOptions)
 }
+
+func (f *sharedInformerFactory) Specs() smispecs.Interface {
+	return smispecs.New(f, f.namespace, f.tweakListOptions)
+}
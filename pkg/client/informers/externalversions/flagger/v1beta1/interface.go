@@ -26,8 +26,12 @@ import (
 type Interface interface {
 	// AlertProviders returns a AlertProviderInformer.
 	AlertProviders() AlertProviderInformer
+	// AlertRoutes returns a AlertRouteInformer.
+	AlertRoutes() AlertRouteInformer
 	// Canaries returns a CanaryInformer.
 	Canaries() CanaryInformer
+	// CanaryClasses returns a CanaryClassInformer.
+	CanaryClasses() CanaryClassInformer
 	// MetricTemplates returns a MetricTemplateInformer.
 	MetricTemplates() MetricTemplateInformer
 }
@@ -48,11 +52,21 @@ func (v *version) AlertProviders() AlertProviderInformer {
 	return &alertProviderInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// AlertRoutes returns a AlertRouteInformer.
+func (v *version) AlertRoutes() AlertRouteInformer {
+	return &alertRouteInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // Canaries returns a CanaryInformer.
 func (v *version) Canaries() CanaryInformer {
 	return &canaryInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CanaryClasses returns a CanaryClassInformer.
+func (v *version) CanaryClasses() CanaryClassInformer {
+	return &canaryClassInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // MetricTemplates returns a MetricTemplateInformer.
 func (v *version) MetricTemplates() MetricTemplateInformer {
 	return &metricTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
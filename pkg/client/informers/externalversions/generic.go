@@ -21,13 +21,23 @@ package externalversions
 import (
 	"fmt"
 
+	ambassadorv2 "github.com/weaveworks/flagger/pkg/apis/ambassador/v2"
+	v2 "github.com/weaveworks/flagger/pkg/apis/apisix/v2"
 	v1beta1 "github.com/weaveworks/flagger/pkg/apis/appmesh/v1beta1"
+	v1alpha1 "github.com/weaveworks/flagger/pkg/apis/externaldns/v1alpha1"
 	flaggerv1beta1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
-	v1 "github.com/weaveworks/flagger/pkg/apis/gloo/v1"
+	gloov1 "github.com/weaveworks/flagger/pkg/apis/gloo/v1"
+	gloogatewayv1 "github.com/weaveworks/flagger/pkg/apis/gloogateway/v1"
 	v1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+	kedav1alpha1 "github.com/weaveworks/flagger/pkg/apis/keda/v1alpha1"
+	v1 "github.com/weaveworks/flagger/pkg/apis/kong/v1"
+	kumav1alpha1 "github.com/weaveworks/flagger/pkg/apis/kuma/v1alpha1"
+	monitoringv1 "github.com/weaveworks/flagger/pkg/apis/monitoring/v1"
 	projectcontourv1 "github.com/weaveworks/flagger/pkg/apis/projectcontour/v1"
-	v1alpha1 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha1"
+	smiv1alpha1 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha1"
 	v1alpha2 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha2"
+	smiv1alpha3 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha3"
+	smispecsv1alpha3 "github.com/weaveworks/flagger/pkg/apis/smispecs/v1alpha3"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -58,7 +68,13 @@ func (f *genericInformer) Lister() cache.GenericLister {
 // TODO extend this to unknown resources with a client pool
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
-	// Group=appmesh.k8s.aws, Version=v1beta1
+	// Group=apisix.apache.org, Version=v2
+	case v2.SchemeGroupVersion.WithResource("apisixroutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Apisix().V2().ApisixRoutes().Informer()}, nil
+
+		// Group=appmesh.k8s.aws, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("gatewayroutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta1().GatewayRoutes().Informer()}, nil
 	case v1beta1.SchemeGroupVersion.WithResource("meshes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta1().Meshes().Informer()}, nil
 	case v1beta1.SchemeGroupVersion.WithResource("virtualnodes"):
@@ -66,18 +82,52 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1beta1.SchemeGroupVersion.WithResource("virtualservices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta1().VirtualServices().Informer()}, nil
 
+		// Group=configuration.konghq.com, Version=v1
+	case v1.SchemeGroupVersion.WithResource("kongplugins"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Configuration().V1().KongPlugins().Informer()}, nil
+
+		// Group=externaldns.k8s.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("dnsendpoints"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Externaldns().V1alpha1().DNSEndpoints().Informer()}, nil
+
 		// Group=flagger.app, Version=v1beta1
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("alertproviders"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().AlertProviders().Informer()}, nil
+	case flaggerv1beta1.SchemeGroupVersion.WithResource("alertroutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().AlertRoutes().Informer()}, nil
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("canaries"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().Canaries().Informer()}, nil
+	case flaggerv1beta1.SchemeGroupVersion.WithResource("canaryclasses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().CanaryClasses().Informer()}, nil
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("metrictemplates"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().MetricTemplates().Informer()}, nil
 
+		// Group=gateway.solo.io, Version=v1
+	case gloogatewayv1.SchemeGroupVersion.WithResource("routetables"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Gateway().V1().RouteTables().Informer()}, nil
+
+		// Group=getambassador.io, Version=v2
+	case ambassadorv2.SchemeGroupVersion.WithResource("mappings"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Getambassador().V2().Mappings().Informer()}, nil
+
 		// Group=gloo.solo.io, Version=v1
-	case v1.SchemeGroupVersion.WithResource("upstreamgroups"):
+	case gloov1.SchemeGroupVersion.WithResource("upstreamgroups"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Gloo().V1().UpstreamGroups().Informer()}, nil
 
+		// Group=keda.sh, Version=v1alpha1
+	case kedav1alpha1.SchemeGroupVersion.WithResource("scaledobjects"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Keda().V1alpha1().ScaledObjects().Informer()}, nil
+
+		// Group=kuma.io, Version=v1alpha1
+	case kumav1alpha1.SchemeGroupVersion.WithResource("trafficroutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kuma().V1alpha1().TrafficRoutes().Informer()}, nil
+
+		// Group=monitoring.coreos.com, Version=v1
+	case monitoringv1.SchemeGroupVersion.WithResource("podmonitors"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Monitoring().V1().PodMonitors().Informer()}, nil
+	case monitoringv1.SchemeGroupVersion.WithResource("servicemonitors"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Monitoring().V1().ServiceMonitors().Informer()}, nil
+
 		// Group=networking.istio.io, Version=v1alpha3
 	case v1alpha3.SchemeGroupVersion.WithResource("destinationrules"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha3().DestinationRules().Informer()}, nil
@@ -88,14 +138,22 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case projectcontourv1.SchemeGroupVersion.WithResource("httpproxies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Projectcontour().V1().HTTPProxies().Informer()}, nil
 
+		// Group=specs.smi-spec.io, Version=v1alpha3
+	case smispecsv1alpha3.SchemeGroupVersion.WithResource("httproutegroups"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Specs().V1alpha3().HTTPRouteGroups().Informer()}, nil
+
 		// Group=split.smi-spec.io, Version=v1alpha1
-	case v1alpha1.SchemeGroupVersion.WithResource("trafficsplits"):
+	case smiv1alpha1.SchemeGroupVersion.WithResource("trafficsplits"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Split().V1alpha1().TrafficSplits().Informer()}, nil
 
 		// Group=split.smi-spec.io, Version=v1alpha2
 	case v1alpha2.SchemeGroupVersion.WithResource("trafficsplits"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Split().V1alpha2().TrafficSplits().Informer()}, nil
 
+		// Group=split.smi-spec.io, Version=v1alpha3
+	case smiv1alpha3.SchemeGroupVersion.WithResource("trafficsplits"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Split().V1alpha3().TrafficSplits().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1beta1
 
+// GatewayRouteListerExpansion allows custom methods to be added to
+// GatewayRouteLister.
+type GatewayRouteListerExpansion interface{}
+
+// GatewayRouteNamespaceListerExpansion allows custom methods to be added to
+// GatewayRouteNamespaceLister.
+type GatewayRouteNamespaceListerExpansion interface{}
+
 // MeshListerExpansion allows custom methods to be added to
 // MeshLister.
 type MeshListerExpansion interface{}
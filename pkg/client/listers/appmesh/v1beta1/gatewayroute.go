@@ -0,0 +1,94 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/weaveworks/flagger/pkg/apis/appmesh/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// GatewayRouteLister helps list GatewayRoutes.
+type GatewayRouteLister interface {
+	// List lists all GatewayRoutes in the indexer.
+	List(selector labels.Selector) (ret []*v1beta1.GatewayRoute, err error)
+	// GatewayRoutes returns an object that can list and get GatewayRoutes.
+	GatewayRoutes(namespace string) GatewayRouteNamespaceLister
+	GatewayRouteListerExpansion
+}
+
+// gatewayRouteLister implements the GatewayRouteLister interface.
+type gatewayRouteLister struct {
+	indexer cache.Indexer
+}
+
+// NewGatewayRouteLister returns a new GatewayRouteLister.
+func NewGatewayRouteLister(indexer cache.Indexer) GatewayRouteLister {
+	return &gatewayRouteLister{indexer: indexer}
+}
+
+// List lists all GatewayRoutes in the indexer.
+func (s *gatewayRouteLister) List(selector labels.Selector) (ret []*v1beta1.GatewayRoute, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.GatewayRoute))
+	})
+	return ret, err
+}
+
+// GatewayRoutes returns an object that can list and get GatewayRoutes.
+func (s *gatewayRouteLister) GatewayRoutes(namespace string) GatewayRouteNamespaceLister {
+	return gatewayRouteNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// GatewayRouteNamespaceLister helps list and get GatewayRoutes.
+type GatewayRouteNamespaceLister interface {
+	// List lists all GatewayRoutes in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1beta1.GatewayRoute, err error)
+	// Get retrieves the GatewayRoute from the indexer for a given namespace and name.
+	Get(name string) (*v1beta1.GatewayRoute, error)
+	GatewayRouteNamespaceListerExpansion
+}
+
+// gatewayRouteNamespaceLister implements the GatewayRouteNamespaceLister
+// interface.
+type gatewayRouteNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all GatewayRoutes in the indexer for a given namespace.
+func (s gatewayRouteNamespaceLister) List(selector labels.Selector) (ret []*v1beta1.GatewayRoute, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.GatewayRoute))
+	})
+	return ret, err
+}
+
+// Get retrieves the GatewayRoute from the indexer for a given namespace and name.
+func (s gatewayRouteNamespaceLister) Get(name string) (*v1beta1.GatewayRoute, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("gatewayroute"), name)
+	}
+	return obj.(*v1beta1.GatewayRoute), nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// AlertRouteLister helps list AlertRoutes.
+type AlertRouteLister interface {
+	// List lists all AlertRoutes in the indexer.
+	List(selector labels.Selector) (ret []*v1beta1.AlertRoute, err error)
+	// Get retrieves the AlertRoute from the index for a given name.
+	Get(name string) (*v1beta1.AlertRoute, error)
+	AlertRouteListerExpansion
+}
+
+// alertRouteLister implements the AlertRouteLister interface.
+type alertRouteLister struct {
+	indexer cache.Indexer
+}
+
+// NewAlertRouteLister returns a new AlertRouteLister.
+func NewAlertRouteLister(indexer cache.Indexer) AlertRouteLister {
+	return &alertRouteLister{indexer: indexer}
+}
+
+// List lists all AlertRoutes in the indexer.
+func (s *alertRouteLister) List(selector labels.Selector) (ret []*v1beta1.AlertRoute, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.AlertRoute))
+	})
+	return ret, err
+}
+
+// Get retrieves the AlertRoute from the index for a given name.
+func (s *alertRouteLister) Get(name string) (*v1beta1.AlertRoute, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("alertroute"), name)
+	}
+	return obj.(*v1beta1.AlertRoute), nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CanaryClassLister helps list CanaryClasses.
+type CanaryClassLister interface {
+	// List lists all CanaryClasses in the indexer.
+	List(selector labels.Selector) (ret []*v1beta1.CanaryClass, err error)
+	// Get retrieves the CanaryClass from the index for a given name.
+	Get(name string) (*v1beta1.CanaryClass, error)
+	CanaryClassListerExpansion
+}
+
+// canaryClassLister implements the CanaryClassLister interface.
+type canaryClassLister struct {
+	indexer cache.Indexer
+}
+
+// NewCanaryClassLister returns a new CanaryClassLister.
+func NewCanaryClassLister(indexer cache.Indexer) CanaryClassLister {
+	return &canaryClassLister{indexer: indexer}
+}
+
+// List lists all CanaryClasses in the indexer.
+func (s *canaryClassLister) List(selector labels.Selector) (ret []*v1beta1.CanaryClass, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.CanaryClass))
+	})
+	return ret, err
+}
+
+// Get retrieves the CanaryClass from the index for a given name.
+func (s *canaryClassLister) Get(name string) (*v1beta1.CanaryClass, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("canaryclass"), name)
+	}
+	return obj.(*v1beta1.CanaryClass), nil
+}
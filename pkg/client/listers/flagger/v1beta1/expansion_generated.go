@@ -26,6 +26,10 @@ type AlertProviderListerExpansion interface{}
 // AlertProviderNamespaceLister.
 type AlertProviderNamespaceListerExpansion interface{}
 
+// AlertRouteListerExpansion allows custom methods to be added to
+// AlertRouteLister.
+type AlertRouteListerExpansion interface{}
+
 // CanaryListerExpansion allows custom methods to be added to
 // CanaryLister.
 type CanaryListerExpansion interface{}
@@ -34,6 +38,10 @@ type CanaryListerExpansion interface{}
 // CanaryNamespaceLister.
 type CanaryNamespaceListerExpansion interface{}
 
+// CanaryClassListerExpansion allows custom methods to be added to
+// CanaryClassLister.
+type CanaryClassListerExpansion interface{}
+
 // MetricTemplateListerExpansion allows custom methods to be added to
 // MetricTemplateLister.
 type MetricTemplateListerExpansion interface{}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// nextStepWeight returns the canary weight of the next step, with adaptive steps the increase is
+// proportional to the smallest metric headroom and the weight is held if the headroom is too small
+func (c *Controller) nextStepWeight(cd *flaggerv1.Canary, canaryWeight int) (next int, held bool) {
+	adaptive := cd.GetAnalysis().AdaptiveSteps
+	if adaptive == nil || canaryWeight == 0 || len(cd.GetAnalysis().StepWeights) > 0 || cd.GetAnalysis().StepWeight < 1 {
+		return cd.GetNextStepWeight(canaryWeight), false
+	}
+
+	headroom, ok := c.metricsHeadroom(cd)
+	if !ok {
+		return cd.GetNextStepWeight(canaryWeight), false
+	}
+
+	if minHeadroom := adaptive.GetMinHeadroom(); headroom < minHeadroom {
+		c.recordEventInfof(cd, "Hold %s.%s canary weight %v metrics headroom %.0f%% < %.0f%%",
+			cd.Name, cd.Namespace, canaryWeight, headroom*100, minHeadroom*100)
+		c.recordHalt(cd, haltReasonHeadroom, "metrics headroom %.0f%% below %.0f%%", headroom*100, minHeadroom*100)
+		return canaryWeight, true
+	}
+
+	step := int(math.Round(float64(adaptive.GetMaxStepWeight(cd.GetAnalysis().StepWeight)) * headroom))
+	if step < 1 {
+		step = 1
+	}
+	next = canaryWeight + step
+	if maxWeight := cd.GetMaxWeight(); next > maxWeight {
+		next = maxWeight
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Debugf("Metrics headroom %.2f, canary weight step %v", headroom, next-canaryWeight)
+	return next, false
+}
+
+// metricsHeadroom returns the smallest headroom of the last metric results,
+// it returns false if a metric has no value to compare to its threshold
+func (c *Controller) metricsHeadroom(cd *flaggerv1.Canary) (float64, bool) {
+	results := make(map[string]MetricResult)
+	for _, r := range c.metricResults.get(cd.Name, cd.Namespace) {
+		results[r.Name] = r
+	}
+
+	headroom := 1.0
+	found := false
+	for _, metric := range cd.GetAnalysis().Metrics {
+		// the comparison and SLO metrics have no threshold to measure the headroom from
+		if metric.Comparison != nil || metric.SLO != nil {
+			continue
+		}
+		r, ok := results[metric.Name]
+		if !ok || r.Error != "" {
+			return 0, false
+		}
+		found = true
+		if h := metricHeadroom(metric, r.Value); h < headroom {
+			headroom = h
+		}
+	}
+	return headroom, found
+}
+
+// metricHeadroom returns the distance of the value to the threshold as a fraction between 0 and 1,
+// relative to the threshold or to the half range when both bounds are set.
+// The success rate headroom is relative to the error budget left by its min threshold.
+func metricHeadroom(metric flaggerv1.CanaryMetric, val float64) float64 {
+	minThreshold := metric.Name == "request-success-rate"
+	if !isWithinThreshold(metric, val, minThreshold) {
+		return 0
+	}
+
+	var h float64
+	switch {
+	case metric.ThresholdRange != nil && metric.ThresholdRange.Min != nil && metric.ThresholdRange.Max != nil:
+		tr := *metric.ThresholdRange
+		h = headroomRatio(math.Min(val-*tr.Min, *tr.Max-val), (*tr.Max-*tr.Min)/2)
+	case metric.ThresholdRange != nil && metric.ThresholdRange.Max != nil:
+		h = headroomRatio(*metric.ThresholdRange.Max-val, *metric.ThresholdRange.Max)
+	case metric.ThresholdRange != nil && metric.ThresholdRange.Min != nil:
+		h = aboveThresholdHeadroom(minThreshold, *metric.ThresholdRange.Min, val)
+	case metric.ThresholdRange != nil:
+		return 1
+	case minThreshold:
+		h = aboveThresholdHeadroom(minThreshold, metric.Threshold, val)
+	default:
+		h = headroomRatio(metric.Threshold-val, metric.Threshold)
+	}
+
+	return math.Max(0, math.Min(1, h))
+}
+
+// aboveThresholdHeadroom returns the headroom of a value above a min threshold,
+// the success rate can't exceed 100% so its headroom is relative to the error budget
+func aboveThresholdHeadroom(percentage bool, threshold float64, val float64) float64 {
+	if percentage {
+		return headroomRatio(val-threshold, 100-threshold)
+	}
+	return headroomRatio(val-threshold, threshold)
+}
+
+// headroomRatio divides the distance by the absolute reference, a zero reference gives a full headroom
+func headroomRatio(distance float64, reference float64) float64 {
+	if reference == 0 {
+		return 1
+	}
+	return distance / math.Abs(reference)
+}
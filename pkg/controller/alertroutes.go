@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// canaryAlerts returns the alerts of the canary analysis followed by the alerts of the
+// AlertRoute rules that select the canary labels, a provider is notified once per alert
+// and the canary alerts take precedence over the routed ones
+func (c *Controller) canaryAlerts(cd *flaggerv1.Canary) []flaggerv1.CanaryAlert {
+	alerts := cd.GetAnalysis().Alerts
+
+	// the label based routing is skipped without the AlertRoute CRD
+	if c.flaggerInformers.RouteInformer == nil {
+		return alerts
+	}
+
+	routes, err := c.flaggerInformers.RouteInformer.Lister().List(labels.Everything())
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Errorf("AlertRoutes list error %v", err)
+		return alerts
+	}
+	if len(routes) == 0 {
+		return alerts
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Name < routes[j].Name
+	})
+
+	seen := make(map[string]bool)
+	providerKey := func(alert flaggerv1.CanaryAlert) string {
+		namespace := cd.Namespace
+		if alert.ProviderRef.Namespace != "" {
+			namespace = alert.ProviderRef.Namespace
+		}
+		return fmt.Sprintf("%s.%s", alert.ProviderRef.Name, namespace)
+	}
+
+	result := make([]flaggerv1.CanaryAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		seen[providerKey(alert)] = true
+		result = append(result, alert)
+	}
+
+	canaryLabels := labels.Set(cd.GetLabels())
+	for _, route := range routes {
+		for i, rule := range route.Spec.Routes {
+			selector := labels.Everything()
+			if rule.Selector != nil {
+				selector, err = metav1.LabelSelectorAsSelector(rule.Selector)
+				if err != nil {
+					c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+						Errorf("AlertRoute %s route %d selector error %v", route.Name, i, err)
+					continue
+				}
+			}
+			if !selector.Matches(canaryLabels) {
+				continue
+			}
+			for _, alert := range rule.Alerts {
+				if key := providerKey(alert); !seen[key] {
+					seen[key] = true
+					result = append(result, alert)
+				}
+			}
+		}
+	}
+	return result
+}
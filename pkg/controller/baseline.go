@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+// baselineTarget returns the workload the comparison metrics are evaluated against
+func baselineTarget(cd *flaggerv1.Canary) string {
+	if cd.GetAnalysis().BaselineWorkload {
+		return fmt.Sprintf("%s-baseline", cd.Spec.TargetRef.Name)
+	}
+	return fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+}
+
+// reconcileBaseline runs the baseline workload next to the canary for the targets that support it,
+// it returns false until the baseline pods are ready
+func (c *Controller) reconcileBaseline(cd *flaggerv1.Canary, canaryController canary.Controller, skipLivenessChecks bool) bool {
+	if !cd.GetAnalysis().BaselineWorkload || cd.Spec.Cluster != nil {
+		return true
+	}
+
+	baselineController, ok := canaryController.(canary.BaselineController)
+	if !ok {
+		c.recordEventWarningf(cd, "Baseline workload is not supported for %s targets", cd.Spec.TargetRef.Kind)
+		return true
+	}
+
+	if err := baselineController.ReconcileBaseline(cd); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+
+	if !skipLivenessChecks {
+		if err := baselineController.IsBaselineReady(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			c.recordHalt(cd, haltReasonNotReady, "%v", err)
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// queryBudgets holds the provider budgets of each canary analysis in memory,
+// the budgets are reset when a new analysis starts or when the controller restarts
+type queryBudgets struct {
+	mux   sync.Mutex
+	items map[string]map[string]*providers.Budget
+}
+
+// get returns the budget of the metric template provider for the current analysis of a canary,
+// the templates that query the same provider share its budget
+func (b *queryBudgets) get(canary *flaggerv1.Canary, template *flaggerv1.MetricTemplate) *providers.Budget {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if b.items == nil {
+		b.items = make(map[string]map[string]*providers.Budget)
+	}
+
+	key := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+	budgets, ok := b.items[key]
+	if !ok {
+		budgets = make(map[string]*providers.Budget)
+		b.items[key] = budgets
+	}
+
+	providerKey := providerBudgetKey(template)
+	budget, ok := budgets[providerKey]
+	if !ok {
+		budget = providers.NewBudget(*template.Spec.Provider.Budget)
+		budgets[providerKey] = budget
+	} else {
+		budget.Restrict(*template.Spec.Provider.Budget)
+	}
+	return budget
+}
+
+func (b *queryBudgets) delete(name string, namespace string) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	delete(b.items, fmt.Sprintf("%s.%s", name, namespace))
+}
+
+// providerBudgetKey identifies the provider of a metric template by its type, address and credentials,
+// the credentials are referenced by the secret or the Vault path so that the key doesn't hold secret values
+func providerBudgetKey(template *flaggerv1.MetricTemplate) string {
+	spec := template.Spec.Provider
+	key := fmt.Sprintf("%s/%s", spec.Type, spec.Address)
+	if spec.SecretRef != nil {
+		key += fmt.Sprintf(" secret=%s/%s", template.Namespace, spec.SecretRef.Name)
+	}
+	if spec.TokenFile != "" {
+		key += fmt.Sprintf(" token=%s", spec.TokenFile)
+	}
+	if spec.VaultRef != nil {
+		key += fmt.Sprintf(" vault=%s/%s", template.Namespace, spec.VaultRef.Path)
+	}
+	return key
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// applyCanaryClass returns a copy of the canary with the analysis of its CanaryClass merged in,
+// the canary is returned as is when it doesn't reference a class
+func (c *Controller) applyCanaryClass(cd *flaggerv1.Canary) (*flaggerv1.Canary, error) {
+	if cd.Spec.CanaryClassName != "" {
+		if c.flaggerInformers.ClassInformer == nil {
+			return nil, fmt.Errorf("CanaryClass %s can't be resolved, the CanaryClass CRD is not registered", cd.Spec.CanaryClassName)
+		}
+		class, err := c.flaggerInformers.ClassInformer.Lister().Get(cd.Spec.CanaryClassName)
+		if err != nil {
+			return nil, fmt.Errorf("CanaryClass %s query error %v", cd.Spec.CanaryClassName, err)
+		}
+		cd = cd.DeepCopy()
+		cd.ApplyClass(class)
+	}
+
+	if cd.GetAnalysis() == nil {
+		return nil, fmt.Errorf("canary %s.%s has no analysis, set the analysis or the canaryClassName", cd.Name, cd.Namespace)
+	}
+
+	return cd, nil
+}
+
+// enqueueClassCanaries queues the canaries that reference the class
+// so the class changes are picked up by the scheduler
+func (c *Controller) enqueueClassCanaries(obj interface{}) {
+	class, ok := obj.(*flaggerv1.CanaryClass)
+	if !ok {
+		return
+	}
+
+	canaries, err := c.flaggerInformers.CanaryInformer.Lister().List(labels.Everything())
+	if err != nil {
+		c.logger.Errorf("Canaries list error %v", err)
+		return
+	}
+
+	for _, cd := range canaries {
+		if cd.Spec.CanaryClassName == class.Name {
+			c.enqueue(cd)
+		}
+	}
+}
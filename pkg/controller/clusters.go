@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/router"
+)
+
+// clusterKubeconfigKey is the secret key holding the kubeconfig of the canary cluster
+const clusterKubeconfigKey = "kubeconfig"
+
+// getCanaryController returns the controller of the target kind,
+// or the multi-cluster controller if the canary runs in a second cluster
+func (c *Controller) getCanaryController(cd *flaggerv1.Canary) (canary.Controller, error) {
+	if cd.Spec.Cluster == nil {
+		return c.canaryFactory.Controller(cd.Spec.TargetRef.Kind), nil
+	}
+
+	if cd.Spec.TargetRef.Kind != "Deployment" {
+		return nil, fmt.Errorf("canary %s.%s target kind %s is not supported in a second cluster, only Deployment is",
+			cd.Name, cd.Namespace, cd.Spec.TargetRef.Kind)
+	}
+
+	client, err := c.getClusterClient(cd)
+	if err != nil {
+		return nil, err
+	}
+	return c.canaryFactory.ClusterController(client), nil
+}
+
+// getKubernetesRouter returns the router of the canary services,
+// the services of the clusters of a multi-cluster canary routed by DNS are not managed
+func (c *Controller) getKubernetesRouter(cd *flaggerv1.Canary, provider string,
+	labelSelector string, ports map[string]int32) (router.KubernetesRouter, error) {
+	switch {
+	case cd.Spec.Cluster == nil:
+		return c.routerFactory.KubernetesRouter(cd.Spec.TargetRef.Kind, labelSelector, map[string]string{}, ports), nil
+	case provider != "externaldns":
+		clusterClient, err := c.getClusterClient(cd)
+		if err != nil {
+			return nil, err
+		}
+		return c.routerFactory.ClusterKubernetesRouter(clusterClient, labelSelector, ports), nil
+	}
+	return &router.KubernetesNoopRouter{}, nil
+}
+
+// getClusterClient builds a Kubernetes client from the kubeconfig secret of the canary cluster
+func (c *Controller) getClusterClient(cd *flaggerv1.Canary) (kubernetes.Interface, error) {
+	if c.clusterClient != nil {
+		return c.clusterClient(cd)
+	}
+
+	cluster := cd.Spec.Cluster
+	data, err := c.getSecretData(cd.Namespace, cluster.SecretRef.Name)
+	if err != nil {
+		return nil, fmt.Errorf("cluster %s secret %s error: %v", cluster.Name, cluster.SecretRef.Name, err)
+	}
+	kubeconfig, ok := data[clusterKubeconfigKey]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s secret %s does not contain %s", cluster.Name, cluster.SecretRef.Name, clusterKubeconfigKey)
+	}
+
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("cluster %s kubeconfig error: %v", cluster.Name, err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cluster %s client error: %v", cluster.Name, err)
+	}
+	return client, nil
+}
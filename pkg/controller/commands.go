@@ -0,0 +1,36 @@
+package controller
+
+import (
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/router"
+)
+
+// runCanaryCommands carries out the promote and rollback commands annotated on a canary under analysis,
+// the commands are applied even if the canary is suspended.
+// It returns false if the analysis should not advance.
+func (c *Controller) runCanaryCommands(cd *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface, skipLivenessChecks bool) bool {
+	if cd.Status.Phase != flaggerv1.CanaryPhaseProgressing && cd.Status.Phase != flaggerv1.CanaryPhaseWaiting {
+		return true
+	}
+
+	if cd.IsCommandRequested(flaggerv1.RollbackAnnotation) {
+		c.recordEventWarningf(cd, "Rolling back %s.%s manual rollback requested", cd.Name, cd.Namespace)
+		c.alert(cd, "Rolling back manual rollback requested", false, flaggerv1.SeverityWarn)
+		c.rollback(cd, canaryController, meshRouter)
+		return false
+	}
+
+	if cd.IsCommandRequested(flaggerv1.PromoteAnnotation) {
+		if !skipLivenessChecks {
+			if _, err := canaryController.IsCanaryReady(cd); err != nil {
+				c.recordEventWarningf(cd, "Halt %s.%s manual promotion %v", cd.Name, cd.Namespace, err)
+				return false
+			}
+		}
+		c.promoteWithoutAnalysis(cd, canaryController, meshRouter, "Canary analysis was ended by a manual promotion")
+		return false
+	}
+
+	return true
+}
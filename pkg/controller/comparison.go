@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/judge"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+const (
+	defaultComparisonConfidence    = 95
+	defaultComparisonMinDataPoints = 8
+)
+
+// lowDataPointsError is returned when a comparison metric has too few datapoints
+// for the significance test to be meaningful
+type lowDataPointsError struct {
+	canary   int
+	baseline int
+	min      int
+}
+
+func (e *lowDataPointsError) Error() string {
+	return fmt.Sprintf("datapoints canary %v baseline %v are below the minimum %v", e.canary, e.baseline, e.min)
+}
+
+// runComparisonMetric fetches the datapoints of the interval for the canary and the baseline workload
+// by rendering the metric template with both targets, then tests if the canary values differ significantly
+func (c *Controller) runComparisonMetric(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric) (*judge.Result, error) {
+	if metric.TemplateRef == nil || metric.Range != nil || metric.Baseline != nil {
+		return nil, newMetricTemplateError("Metric %s comparison requires a templateRef without range or baseline", metric.Name)
+	}
+	comparison := metric.Comparison
+
+	interval, err := time.ParseDuration(metric.Interval)
+	if err != nil {
+		return nil, newMetricTemplateError("Metric %s comparison error parsing interval: %v", metric.Name, err)
+	}
+	step, err := providers.RangeStep(interval, comparison.Step)
+	if err != nil {
+		return nil, newMetricTemplateError("Metric %s comparison %v", metric.Name, err)
+	}
+
+	model := toMetricModel(canary, metric)
+	canaryValues, err := c.runMetricTemplateRangeQuery(canary, metric, model, interval, step)
+	if err != nil {
+		return nil, err
+	}
+
+	model.Target = baselineTarget(canary)
+	if comparison.BaselineTarget != "" {
+		model.Target = comparison.BaselineTarget
+	}
+	baselineValues, err := c.runMetricTemplateRangeQuery(canary, metric, model, interval, step)
+	if err != nil {
+		if _, ok := err.(*metricTemplateError); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("baseline: %v", err)
+	}
+
+	minDataPoints := comparison.MinDataPoints
+	if minDataPoints < 1 {
+		minDataPoints = defaultComparisonMinDataPoints
+	}
+	if len(canaryValues) < minDataPoints || len(baselineValues) < minDataPoints {
+		return nil, &lowDataPointsError{canary: len(canaryValues), baseline: len(baselineValues), min: minDataPoints}
+	}
+
+	direction := comparison.Direction
+	if direction == "" {
+		direction = judge.DirectionIncrease
+	}
+	confidence := comparison.Confidence
+	if confidence == 0 {
+		confidence = defaultComparisonConfidence
+	}
+
+	result, err := judge.Compare(canaryValues, baselineValues, direction, confidence)
+	if err != nil {
+		return nil, newMetricTemplateError("Metric %s comparison %v", metric.Name, err)
+	}
+	return &result, nil
+}
+
+// runMetricTemplateRangeQuery renders the metric template with the model and returns the values of the interval
+func (c *Controller) runMetricTemplateRangeQuery(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	model flaggerv1.MetricTemplateModel, interval time.Duration, step time.Duration) ([]float64, error) {
+	q, err := c.newMetricTemplateQuery(canary, metric, *metric.TemplateRef, model)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := providers.RunRangeQuery(q.provider, q.query, interval, step)
+	c.recordQueryBudget(canary, q)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]float64, 0, len(points))
+	for _, p := range points {
+		values = append(values, p.Value)
+	}
+	return values, nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/weaveworks/flagger/pkg/metrics/observers"
 	"github.com/weaveworks/flagger/pkg/notifier"
 	"github.com/weaveworks/flagger/pkg/router"
+	"github.com/weaveworks/flagger/pkg/secrets"
 )
 
 const controllerAgentName = "flagger"
@@ -52,12 +53,31 @@ type Controller struct {
 	observerFactory  *observers.Factory
 	meshProvider     string
 	eventWebhook     string
+	cloneMonitors    bool
+	decryptor        secrets.Decryptor
+	vault            *secrets.VaultClient
+	healthInterval   time.Duration
+	analysisJitter   float64
+	analysisSpread   bool
+	metricResults    metricResults
+	queryBudgets     queryBudgets
+	timeSeries       timeSeries
+	halts            halts
+	warmupStarts     warmupStarts
+	routesWatch      string
+	routesEvents     sync.Map
+	podLogs          func(namespace string, pod string, container string) (string, error)
+	clusterClient    func(cd *flaggerv1.Canary) (kubernetes.Interface, error)
 }
 
+// Informers holds the informers of the Flagger objects, the class and route informers
+// are nil when the CanaryClass and AlertRoute CRDs are not registered
 type Informers struct {
 	CanaryInformer flaggerinformers.CanaryInformer
 	MetricInformer flaggerinformers.MetricTemplateInformer
 	AlertInformer  flaggerinformers.AlertProviderInformer
+	ClassInformer  flaggerinformers.CanaryClassInformer
+	RouteInformer  flaggerinformers.AlertRouteInformer
 }
 
 func NewController(
@@ -74,6 +94,12 @@ func NewController(
 	meshProvider string,
 	version string,
 	eventWebhook string,
+	cloneMonitors bool,
+	decryptor secrets.Decryptor,
+	vault *secrets.VaultClient,
+	healthInterval time.Duration,
+	analysisJitter float64,
+	analysisSpread bool,
 ) *Controller {
 	logger.Debug("Creating event broadcaster")
 	flaggerscheme.AddToScheme(scheme.Scheme)
@@ -106,6 +132,12 @@ func NewController(
 		routerFactory:    routerFactory,
 		meshProvider:     meshProvider,
 		eventWebhook:     eventWebhook,
+		cloneMonitors:    cloneMonitors,
+		decryptor:        decryptor,
+		vault:            vault,
+		healthInterval:   healthInterval,
+		analysisJitter:   analysisJitter,
+		analysisSpread:   analysisSpread,
 	}
 
 	flaggerInformers.CanaryInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -138,10 +170,47 @@ func NewController(
 			if ok {
 				ctrl.logger.Infof("Deleting %s.%s from cache", r.Name, r.Namespace)
 				ctrl.canaries.Delete(fmt.Sprintf("%s.%s", r.Name, r.Namespace))
+				ctrl.metricResults.delete(r.Name, r.Namespace)
+				ctrl.queryBudgets.delete(r.Name, r.Namespace)
+				ctrl.timeSeries.delete(r.Name, r.Namespace)
+				ctrl.halts.delete(r.Name, r.Namespace)
+				ctrl.warmupStarts.delete(r.Name, r.Namespace)
+				if err := ctrl.canaryFactory.DeleteState(&r); err != nil {
+					ctrl.logger.Errorf("Deleting %s.%s state failed %v", r.Name, r.Namespace, err)
+				}
 			}
 		},
 	})
 
+	// report the invalid provider settings when the templates are created or changed
+	flaggerInformers.MetricInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: ctrl.validateMetricTemplate,
+		UpdateFunc: func(old, new interface{}) {
+			ctrl.validateMetricTemplate(new)
+		},
+	})
+
+	if flaggerInformers.ClassInformer != nil {
+		flaggerInformers.ClassInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: ctrl.enqueueClassCanaries,
+			UpdateFunc: func(old, new interface{}) {
+				oldClass, ok := old.(*flaggerv1.CanaryClass)
+				if !ok {
+					return
+				}
+				newClass, ok := new.(*flaggerv1.CanaryClass)
+				if !ok {
+					return
+				}
+
+				if diff := cmp.Diff(newClass.Spec, oldClass.Spec); diff != "" {
+					ctrl.logger.Debugf("Diff detected CanaryClass %s %s", newClass.Name, diff)
+					ctrl.enqueueClassCanaries(new)
+				}
+			},
+		})
+	}
+
 	return ctrl
 }
 
@@ -161,11 +230,19 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 
 	c.logger.Info("Started operator workers")
 
+	// check the metric providers health, disabled if the interval is zero
+	var healthChan <-chan time.Time
+	if c.healthInterval > 0 {
+		healthChan = time.NewTicker(c.healthInterval).C
+	}
+
 	tickChan := time.NewTicker(c.flaggerWindow).C
 	for {
 		select {
 		case <-tickChan:
 			c.scheduleCanaries()
+		case <-healthChan:
+			c.checkProviders()
 		case <-stopCh:
 			c.logger.Info("Shutting down operator workers")
 			return nil
@@ -235,7 +312,14 @@ func (c *Controller) syncHandler(key string) error {
 		}
 	}
 
-	c.canaries.Store(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace), cd)
+	// merge the analysis defaults of the canary class
+	cdWithClass, err := c.applyCanaryClass(cd)
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return err
+	}
+
+	c.canaries.Store(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace), cdWithClass)
 	c.logger.Infof("Synced %s", key)
 
 	return nil
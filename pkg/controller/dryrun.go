@@ -0,0 +1,49 @@
+package controller
+
+import (
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+// dryRunRouter keeps all the traffic on primary during a dry run,
+// the canary weight only advances in the status
+type dryRunRouter struct{}
+
+// Reconcile does nothing, the routes are reconciled before the dry run starts
+func (dr *dryRunRouter) Reconcile(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// SetRoutes does nothing, the primary keeps serving all the traffic
+func (dr *dryRunRouter) SetRoutes(_ *flaggerv1.Canary, _ int, _ int, _ bool) error {
+	return nil
+}
+
+// GetRoutes returns the canary weight recorded in the status
+func (dr *dryRunRouter) GetRoutes(cd *flaggerv1.Canary) (primaryWeight int, canaryWeight int, mirrored bool, err error) {
+	return 100 - cd.Status.CanaryWeight, cd.Status.CanaryWeight, false, nil
+}
+
+// finishDryRun scales the canary to zero and reports the passed analysis without promoting the canary
+func (c *Controller) finishDryRun(cd *flaggerv1.Canary, canaryController canary.Controller) {
+	// resume the rollout of the queued revision
+	if ok := c.pauseRollout(cd, canaryController, false); !ok {
+		return
+	}
+
+	if err := canaryController.Scale(cd, 0); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+
+	if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseDryRunPassed); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	c.recorder.SetStatus(cd, flaggerv1.CanaryPhaseDryRunPassed)
+	c.runPostRolloutHooks(cd, flaggerv1.CanaryPhaseDryRunPassed)
+	c.recordEventInfof(cd, "Dry run passed! Scaling down %s.%s, the primary was not updated",
+		cd.Spec.TargetRef.Name, cd.Namespace)
+	c.alert(cd, "Canary dry run completed successfully, the revision was not promoted.",
+		false, flaggerv1.SeverityInfo)
+}
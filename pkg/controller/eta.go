@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+// promotionETATolerance is the drift of the estimate that doesn't trigger a status update,
+// it's lower than the minimum analysis interval so a halted advancement is always reported
+const promotionETATolerance = 5 * time.Second
+
+// remainingRuns returns the number of analysis runs left until the primary is promoted,
+// based on the canary weight, iterations and mirroring set by the current run.
+// It returns false when the canary is held at a weight instead of being promoted.
+func remainingRuns(cd *flaggerv1.Canary, provider string, canaryWeight int, iterations int, mirrored bool) (int, bool) {
+	analysis := cd.GetAnalysis()
+
+	// apply the same defaults as the scheduler
+	maxIterations := analysis.Iterations
+	if cd.Spec.TargetRef.Kind == "CronJob" && maxIterations < 1 {
+		maxIterations = 1
+	}
+	if provider == "kubernetes" && maxIterations < 1 {
+		maxIterations = 10
+	}
+
+	switch {
+	// A/B testing: one run per iteration and one for the promotion
+	case len(analysis.Match) > 0 && provider != "kubernetes" && maxIterations > 0:
+		runs := 1
+		if maxIterations > iterations {
+			runs += maxIterations - iterations
+		}
+		return runs, true
+	// Blue/Green: one run per iteration, one for routing all traffic to canary and one for the promotion
+	case maxIterations > 0:
+		if iterations > maxIterations {
+			return 1, true
+		}
+		return maxIterations - iterations + 2, true
+	// Canary: one run per step weight and one for the promotion
+	case cd.HasStepWeights():
+		if analysis.HoldWeight > 0 {
+			return 0, false
+		}
+		maxWeight := cd.GetMaxWeight()
+		runs := 1
+		if mirrorIterations := cd.GetMirrorIterations(); analysis.Mirror && canaryWeight == 0 && iterations < mirrorIterations {
+			runs += mirrorIterations - iterations
+		}
+		for w := canaryWeight; w < maxWeight; w = cd.GetNextStepWeight(w) {
+			runs++
+		}
+		runs += warmupHolds(cd, canaryWeight, iterations)
+		return runs, true
+	}
+
+	return 0, false
+}
+
+// promotionTime returns the time of the analysis run that promotes the canary,
+// the runs follow the analysis schedule if one is set or else the analysis interval
+func promotionTime(cd *flaggerv1.Canary, runs int, now time.Time) (time.Time, bool) {
+	cron, err := cd.GetAnalysisSchedule()
+	if err != nil || cron == nil {
+		return now.Add(time.Duration(runs) * cd.GetAnalysisInterval()), true
+	}
+
+	t := now
+	for i := 0; i < runs; i++ {
+		t = cron.Next(t)
+		if t.IsZero() {
+			return t, false
+		}
+	}
+	return t, true
+}
+
+// updatePromotionETA records the estimated promotion time in the canary status and
+// the time left in the promotion gauge, the status is only updated when the estimate changes
+func (c *Controller) updatePromotionETA(cd *flaggerv1.Canary, canaryController canary.Controller,
+	provider string, canaryWeight int, iterations int, mirrored bool) {
+	var eta *metav1.Time
+	if runs, ok := remainingRuns(cd, provider, canaryWeight, iterations, mirrored); ok {
+		if t, ok := promotionTime(cd, runs, time.Now()); ok {
+			eta = &metav1.Time{Time: t.Truncate(time.Second)}
+		}
+	}
+	c.setPromotionETA(cd, canaryController, eta)
+}
+
+// clearPromotionETA removes the estimate when the advancement is halted by a gate
+func (c *Controller) clearPromotionETA(cd *flaggerv1.Canary, canaryController canary.Controller) {
+	c.setPromotionETA(cd, canaryController, nil)
+}
+
+func (c *Controller) setPromotionETA(cd *flaggerv1.Canary, canaryController canary.Controller, eta *metav1.Time) {
+	if eta != nil {
+		c.recorder.SetPromotionRemaining(cd, time.Until(eta.Time))
+	} else {
+		c.recorder.DeletePromotionRemaining(cd)
+	}
+
+	current := cd.Status.PromotionETA
+	if current == nil && eta == nil {
+		return
+	}
+	if current != nil && eta != nil {
+		drift := current.Sub(eta.Time)
+		if drift > -promotionETATolerance && drift < promotionETATolerance {
+			return
+		}
+	}
+
+	if err := canaryController.SetStatusPromotionETA(cd, eta); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+	}
+}
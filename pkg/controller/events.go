@@ -3,8 +3,6 @@ package controller
 import (
 	"fmt"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
 	corev1 "k8s.io/api/core/v1"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
@@ -37,6 +35,7 @@ func (c *Controller) sendEventToWebhook(r *flaggerv1.Canary, eventType, template
 				webhookOverride = true
 				err := CallEventWebhook(r, canaryWebhook.URL, fmt.Sprintf(template, args...), eventType)
 				if err != nil {
+					c.recorder.IncWebhookFailures(r, flaggerv1.EventHook, canaryWebhook.Name)
 					c.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Errorf("error sending event to webhook: %s", err)
 				}
 			}
@@ -46,13 +45,15 @@ func (c *Controller) sendEventToWebhook(r *flaggerv1.Canary, eventType, template
 	if c.eventWebhook != "" && !webhookOverride {
 		err := CallEventWebhook(r, c.eventWebhook, fmt.Sprintf(template, args...), eventType)
 		if err != nil {
+			c.recorder.IncWebhookFailures(r, flaggerv1.EventHook, "global")
 			c.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Errorf("error sending event to webhook: %s", err)
 		}
 	}
 }
 
 func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bool, severity flaggerv1.AlertSeverity) {
-	if c.notifier == nil && len(canary.GetAnalysis().Alerts) == 0 {
+	alerts := c.canaryAlerts(canary)
+	if c.notifier == nil && len(alerts) == 0 {
 		return
 	}
 
@@ -62,7 +63,7 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 	}
 
 	// send alert with the global notifier
-	if len(canary.GetAnalysis().Alerts) == 0 {
+	if len(alerts) == 0 {
 		err := c.notifier.Post(canary.Name, canary.Namespace, message, fields, string(severity))
 		if err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
@@ -72,8 +73,8 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 		return
 	}
 
-	// send canary alerts
-	for _, alert := range canary.GetAnalysis().Alerts {
+	// send canary and routed alerts
+	for _, alert := range alerts {
 		// determine if alert should be sent based on severity level
 		shouldAlert := false
 		if alert.Severity == flaggerv1.SeverityInfo {
@@ -109,13 +110,13 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 
 		// extract address from secret
 		if provider.Spec.SecretRef != nil {
-			secret, err := c.kubeClient.CoreV1().Secrets(providerNamespace).Get(provider.Spec.SecretRef.Name, metav1.GetOptions{})
+			data, err := c.getSecretData(providerNamespace, provider.Spec.SecretRef.Name)
 			if err != nil {
 				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 					Errorf("alert provider %s.%s secretRef error: %v", alert.ProviderRef.Name, providerNamespace, err)
 				continue
 			}
-			if address, ok := secret.Data["address"]; ok {
+			if address, ok := data["address"]; ok {
 				url = string(address)
 			} else {
 				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
@@ -170,7 +171,12 @@ func alertMetadata(canary *flaggerv1.Canary) []notifier.Field {
 		},
 	)
 
-	if canary.GetAnalysis().StepWeight > 0 {
+	if weights := canary.GetAnalysis().StepWeights; len(weights) > 0 {
+		fields = append(fields, notifier.Field{
+			Name:  "Traffic routing",
+			Value: fmt.Sprintf("Weight steps: %v", weights),
+		})
+	} else if canary.GetAnalysis().StepWeight > 0 {
 		fields = append(fields, notifier.Field{
 			Name: "Traffic routing",
 			Value: fmt.Sprintf("Weight step: %v max: %v",
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// Halt is the gate or check that stopped the last analysis run of a canary
+type Halt struct {
+	Reason    string
+	Message   string
+	Timestamp time.Time
+}
+
+// Explanation describes the decision inputs of the current canary phase
+type Explanation struct {
+	Name         string
+	Namespace    string
+	Phase        flaggerv1.CanaryPhase
+	CanaryWeight int
+	Iterations   int
+	FailedChecks int
+	Threshold    int
+	// Checks are the last metric check results of the analysis
+	Checks []MetricResult
+	// Gate is set when the last analysis run was halted, nil when the canary is advancing
+	Gate *Halt
+	// NextAction is what Flagger does at the next analysis run
+	NextAction string
+	// NextRun is the latest time of the next analysis run, nil when the analysis is not running
+	NextRun *time.Time
+	// PromotionETA is the estimated promotion time, nil when the promotion can't be estimated
+	PromotionETA *time.Time
+}
+
+// halts holds the last halt of each canary in memory, the halt is cleared when the analysis checks pass
+type halts struct {
+	items sync.Map
+}
+
+func (h *halts) record(canary *flaggerv1.Canary, halt Halt) {
+	h.items.Store(fmt.Sprintf("%s.%s", canary.Name, canary.Namespace), halt)
+}
+
+func (h *halts) get(name string, namespace string) *Halt {
+	current, ok := h.items.Load(fmt.Sprintf("%s.%s", name, namespace))
+	if !ok {
+		return nil
+	}
+	halt := current.(Halt)
+	return &halt
+}
+
+func (h *halts) delete(name string, namespace string) {
+	h.items.Delete(fmt.Sprintf("%s.%s", name, namespace))
+}
+
+// recordHalt reports the halted analysis run and keeps its reason for the canary explanation
+func (c *Controller) recordHalt(canary *flaggerv1.Canary, reason string, format string, args ...interface{}) {
+	c.recorder.IncHalts(canary, reason)
+	c.halts.record(canary, Halt{
+		Reason:    reason,
+		Message:   fmt.Sprintf(format, args...),
+		Timestamp: time.Now(),
+	})
+}
+
+// Explain returns the checks, the blocking gate and the next action of a canary,
+// the analysis state kept in the state store takes precedence over the canary status
+func (c *Controller) Explain(cd *flaggerv1.Canary) (Explanation, error) {
+	cd = cd.DeepCopy()
+	if err := c.canaryFactory.LoadState(cd); err != nil {
+		return Explanation{}, fmt.Errorf("loading state of %s.%s failed %v", cd.Name, cd.Namespace, err)
+	}
+
+	e := Explanation{
+		Name:         cd.Name,
+		Namespace:    cd.Namespace,
+		Phase:        cd.Status.Phase,
+		CanaryWeight: cd.Status.CanaryWeight,
+		Iterations:   cd.Status.Iterations,
+		FailedChecks: cd.Status.FailedChecks,
+	}
+	if cd.Status.PromotionETA != nil {
+		eta := cd.Status.PromotionETA.Time
+		e.PromotionETA = &eta
+	}
+	if cd.Spec.Suspend {
+		e.Gate = c.halts.get(cd.Name, cd.Namespace)
+		e.NextAction = fmt.Sprintf("hold the canary weight at %v until the canary is resumed", cd.Status.CanaryWeight)
+		return e, nil
+	}
+	if cd.SkipAnalysis() {
+		e.NextAction = fmt.Sprintf("promote the next revision of %s/%s without analysis", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name)
+		return e, nil
+	}
+
+	e.Threshold = cd.GetAnalysisThreshold()
+	e.Checks = c.GetMetricResults(cd.Name, cd.Namespace)
+	sort.Slice(e.Checks, func(i, j int) bool {
+		return e.Checks[i].Name < e.Checks[j].Name
+	})
+
+	switch cd.Status.Phase {
+	case "", flaggerv1.CanaryPhaseInitializing:
+		e.NextAction = "create the primary workload and the routes"
+	case flaggerv1.CanaryPhaseInitialized, flaggerv1.CanaryPhaseSucceeded:
+		e.NextAction = fmt.Sprintf("wait for a new revision of %s/%s", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name)
+	case flaggerv1.CanaryPhaseDryRunPassed:
+		e.NextAction = fmt.Sprintf("keep the primary and wait for a new revision of %s/%s to dry run", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name)
+	case flaggerv1.CanaryPhaseFailed:
+		e.NextAction = fmt.Sprintf("keep the primary and wait for a new revision of %s/%s", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name)
+	case flaggerv1.CanaryPhaseHolding:
+		e.NextAction = fmt.Sprintf("hold the canary weight at %v until a new revision is detected", cd.Status.CanaryWeight)
+	case flaggerv1.CanaryPhasePromoting:
+		e.NextAction = "route all traffic to the promoted primary"
+		if step := cd.GetAnalysis().StepWeightPromotion; step > 0 && cd.Status.CanaryWeight > step {
+			e.NextAction = fmt.Sprintf("advance the promoted primary weight to %v", 100-cd.Status.CanaryWeight+step)
+		}
+	case flaggerv1.CanaryPhaseFinalising:
+		e.NextAction = "scale down the canary"
+	case flaggerv1.CanaryPhaseRollingBack:
+		e.NextAction = "route the traffic back to the primary"
+	case flaggerv1.CanaryPhaseWaiting, flaggerv1.CanaryPhaseProgressing:
+		e.Gate = c.halts.get(cd.Name, cd.Namespace)
+		e.NextAction = nextAction(cd, e.Gate)
+	}
+
+	// the analysis keeps running while a rollout is in progress
+	switch cd.Status.Phase {
+	case flaggerv1.CanaryPhaseWaiting, flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhasePromoting,
+		flaggerv1.CanaryPhaseFinalising, flaggerv1.CanaryPhaseRollingBack:
+		if next, ok := promotionTime(cd, 1, time.Now()); ok {
+			e.NextRun = &next
+		}
+	}
+
+	return e, nil
+}
+
+// nextAction describes the next analysis run of a waiting or progressing canary
+func nextAction(cd *flaggerv1.Canary, gate *Halt) string {
+	if gate != nil {
+		switch gate.Reason {
+		case haltReasonConfirmRollout:
+			return "start the analysis when the confirm-rollout gate is approved"
+		case haltReasonConfirmPromotion:
+			return "promote the canary when the confirm-promotion gate is approved"
+		case haltReasonWindow:
+			return "resume the analysis when an analysis window opens"
+		case haltReasonNotReady:
+			return "retry the analysis when the canary workload is ready"
+		default:
+			if left := cd.GetAnalysisThreshold() - cd.Status.FailedChecks; left > 0 {
+				return fmt.Sprintf("retry the analysis, the canary is rolled back after %v more failed checks", left)
+			}
+			return "roll back the canary"
+		}
+	}
+
+	if cd.Status.Phase == flaggerv1.CanaryPhaseWaiting {
+		return "start the analysis when the confirm-rollout gate is approved"
+	}
+
+	promote := "promote the canary"
+	if cd.GetAnalysis().DryRun {
+		promote = "end the dry run without promoting the canary"
+	}
+
+	analysis := cd.GetAnalysis()
+	if analysis.Iterations > 0 || cd.Spec.TargetRef.Kind == "CronJob" {
+		iterations := analysis.Iterations
+		if iterations < 1 {
+			iterations = 1
+		}
+		if cd.Status.Iterations < iterations {
+			return fmt.Sprintf("run the analysis iteration %v/%v", cd.Status.Iterations+1, iterations)
+		}
+		return promote
+	}
+
+	if cd.Status.CanaryWeight > 0 && cd.HasStepWeights() && warmupHolds(cd, cd.Status.CanaryWeight, cd.Status.Iterations) > 0 {
+		return fmt.Sprintf("run the warmup check %v/%v at the canary weight %v",
+			cd.Status.Iterations-warmupBase(cd)+1, analysis.WarmupIterations, cd.Status.CanaryWeight)
+	}
+	if cd.Status.CanaryWeight < cd.GetMaxWeight() && cd.HasStepWeights() {
+		return fmt.Sprintf("advance the canary weight to %v", cd.GetNextStepWeight(cd.Status.CanaryWeight))
+	}
+	if analysis.HoldWeight > 0 && !analysis.DryRun {
+		return fmt.Sprintf("hold the canary weight at %v", analysis.HoldWeight)
+	}
+	return promote
+}
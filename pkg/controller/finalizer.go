@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/router"
+)
+
+// finalizer holds the deletion of the canaries that revert the routing on deletion
+const finalizer = "finalizer.flagger.app"
+
+// hasFinalizer returns true if the canary deletion is held by the Flagger finalizer
+func hasFinalizer(cd *flaggerv1.Canary) bool {
+	for _, f := range cd.Finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// syncFinalizer adds or removes the finalizer according to the revert on deletion setting,
+// it returns false if the canary couldn't be updated
+func (c *Controller) syncFinalizer(cd *flaggerv1.Canary) bool {
+	if cd.Spec.RevertOnDeletion == hasFinalizer(cd) {
+		return true
+	}
+	if err := c.setFinalizer(cd, cd.Spec.RevertOnDeletion); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+	return true
+}
+
+// setFinalizer adds or removes the finalizer on the stored canary and copies the finalizers to the given canary,
+// the canary of the scheduler has the class defaults merged and can't be written back
+func (c *Controller) setFinalizer(cd *flaggerv1.Canary, add bool) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		stored, err := c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(cd.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if hasFinalizer(stored) == add {
+			cd.Finalizers = stored.Finalizers
+			return nil
+		}
+
+		cdCopy := stored.DeepCopy()
+		if add {
+			cdCopy.Finalizers = append(cdCopy.Finalizers, finalizer)
+		} else {
+			cdCopy.Finalizers = nil
+			for _, f := range stored.Finalizers {
+				if f != finalizer {
+					cdCopy.Finalizers = append(cdCopy.Finalizers, f)
+				}
+			}
+		}
+		updated, err := c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Update(cdCopy)
+		if err != nil {
+			return err
+		}
+		cd.Finalizers = updated.Finalizers
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("canary %s.%s finalizer update failed: %v", cd.Name, cd.Namespace, err)
+	}
+	return nil
+}
+
+// finalize routes the traffic back to the target and scales it up before releasing the canary deletion,
+// the finalizer is kept until the target pods are ready. The deletion is released without reverting
+// when the target is gone or when the revert keeps failing for longer than finalizeTimeout.
+func (c *Controller) finalize(cd *flaggerv1.Canary, skipLivenessChecks bool) {
+	if !hasFinalizer(cd) {
+		return
+	}
+
+	if err := c.revertOnDeletion(cd, skipLivenessChecks); err != nil {
+		if _, ok := err.(revertError); !ok && time.Since(cd.DeletionTimestamp.Time) < finalizeTimeout {
+			c.recordEventWarningf(cd, "Halt %s.%s deletion %v", cd.Name, cd.Namespace, err)
+			return
+		}
+		c.recordEventWarningf(cd, "Canary %s.%s deleted without reverting the routing %v", cd.Name, cd.Namespace, err)
+		if err := c.setFinalizer(cd, false); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+		return
+	}
+
+	if err := c.setFinalizer(cd, false); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	c.recordEventInfof(cd, "Canary deleted! Traffic routed back to %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+}
+
+// finalizeTimeout is the time after which a failing revert no longer holds the canary deletion
+const finalizeTimeout = 10 * time.Minute
+
+// revertError reports a revert that can't succeed by retrying, e.g. the target was deleted
+type revertError struct {
+	err error
+}
+
+func (e revertError) Error() string {
+	return e.err.Error()
+}
+
+// revertOnDeletion resumes the target rollout, scales the target up and routes all traffic to it
+func (c *Controller) revertOnDeletion(cd *flaggerv1.Canary, skipLivenessChecks bool) error {
+	provider := c.getProvider(cd)
+
+	canaryController, err := c.getCanaryController(cd)
+	if err != nil {
+		return revertError{err}
+	}
+	// without the target there is nothing to route the traffic to
+	labelSelector, ports, err := canaryController.GetMetadata(cd)
+	if err != nil {
+		return revertError{err}
+	}
+	kubeRouter, err := c.getKubernetesRouter(cd, provider, labelSelector, ports)
+	if err != nil {
+		return err
+	}
+
+	// resume the rollout of the target revision held during the analysis
+	if revisionController, ok := canaryController.(canary.RevisionController); ok {
+		if err := revisionController.PauseRollout(cd, false); err != nil {
+			return err
+		}
+	}
+
+	if err := c.scaleTargetOnDeletion(cd, canaryController); err != nil {
+		return err
+	}
+
+	if !skipLivenessChecks {
+		if _, err := canaryController.IsCanaryReady(cd); err != nil {
+			return err
+		}
+	}
+
+	// route all traffic to the target
+	if err := c.routerFactory.MeshRouter(provider).SetRoutes(cd, 0, 100, false); err != nil {
+		return err
+	}
+	if kubeFinalizer, ok := kubeRouter.(router.KubernetesFinalizer); ok {
+		if err := kubeFinalizer.Finalize(cd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scaleTargetOnDeletion scales the target up, a deployment without autoscaler
+// gets the primary replicas so that it can take all the traffic
+func (c *Controller) scaleTargetOnDeletion(cd *flaggerv1.Canary, canaryController canary.Controller) error {
+	if err := canaryController.ScaleFromZero(cd); err != nil {
+		return err
+	}
+	if cd.Spec.TargetRef.Kind != "Deployment" || cd.Spec.AutoscalerRef != nil || cd.Spec.Cluster != nil {
+		return nil
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s query error %v", primaryName, cd.Namespace, err)
+	}
+	if primary.Spec.Replicas == nil || *primary.Spec.Replicas < 2 {
+		return nil
+	}
+	return canaryController.Scale(cd, *primary.Spec.Replicas)
+}
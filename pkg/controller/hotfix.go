@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/router"
+)
+
+// promoteHotfix promotes the target revisions annotated as hotfix without running the analysis,
+// it returns true if the canary was promoted
+func (c *Controller) promoteHotfix(cd *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) bool {
+	if cd.Status.Phase != flaggerv1.CanaryPhaseProgressing || cd.GetAnalysis().DryRun {
+		return false
+	}
+
+	hotfixController, ok := canaryController.(canary.HotfixController)
+	if !ok {
+		return false
+	}
+
+	hotfix, err := hotfixController.GetHotfix(cd)
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+	if hotfix == "" {
+		return false
+	}
+
+	return c.promoteWithoutAnalysis(cd, canaryController, meshRouter,
+		fmt.Sprintf("Canary analysis was skipped for hotfix %s", hotfix))
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// canaryImpact is the estimated load of the first traffic step and the canary capacity
+type canaryImpact struct {
+	// requestRate is the requests per second served by the primary, zero if unknown
+	requestRate float64
+	// requiredReplicas is the number of canary replicas needed to serve the first step
+	requiredReplicas int32
+	// canaryReplicas is the number of ready canary replicas
+	canaryReplicas int32
+}
+
+func (i canaryImpact) String() string {
+	if i.requestRate > 0 {
+		return fmt.Sprintf("%.2f req/s, the first step requires %v canary replicas, found %v",
+			i.requestRate, i.requiredReplicas, i.canaryReplicas)
+	}
+	return fmt.Sprintf("the first step requires %v canary replicas, found %v", i.requiredReplicas, i.canaryReplicas)
+}
+
+// runImpactCheck estimates the load routed to the canary by the first step weight and returns false
+// if the canary replicas can't absorb it and the check action is halt, the alert action only notifies
+func (c *Controller) runImpactCheck(cd *flaggerv1.Canary) bool {
+	check := cd.GetAnalysis().ImpactCheck
+	if check == nil || !cd.HasStepWeights() || cd.GetAnalysis().Iterations > 0 {
+		return true
+	}
+
+	impact, ok, err := c.estimateImpact(cd, check)
+	if err != nil {
+		c.recordEventWarningf(cd, "Impact check for %s.%s failed %v", cd.Name, cd.Namespace, err)
+		return check.Action == flaggerv1.ImpactCheckAlert
+	}
+	if !ok {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Debugf("Impact check skipped, the capacity can't be estimated without maxRequestsPerReplica or a primary HPA utilization")
+		return true
+	}
+	if impact.requiredReplicas <= impact.canaryReplicas {
+		return true
+	}
+
+	if check.Action == flaggerv1.ImpactCheckAlert {
+		c.recordEventWarningf(cd, "Canary %s.%s may be overloaded by the first step, %s", cd.Name, cd.Namespace, impact)
+		c.alert(cd, fmt.Sprintf("Canary may be overloaded by the first step, %s", impact),
+			false, flaggerv1.SeverityWarn)
+		return true
+	}
+
+	c.recordEventWarningf(cd, "Halt %s.%s advancement the canary can't absorb the first step, %s",
+		cd.Name, cd.Namespace, impact)
+	return false
+}
+
+// estimateImpact computes the canary replicas required by the first step weight,
+// from the request rate and the replica capacity if set or else from the primary HPA utilization.
+// It returns false when the capacity can't be estimated.
+func (c *Controller) estimateImpact(cd *flaggerv1.Canary, check *flaggerv1.CanaryImpactCheck) (canaryImpact, bool, error) {
+	var impact canaryImpact
+	if cd.Spec.TargetRef.Kind != "Deployment" {
+		return impact, false, nil
+	}
+	weight := float64(cd.GetNextStepWeight(0)) / 100
+
+	canaryDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return impact, false, fmt.Errorf("deployment %s.%s query error %v", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+	impact.canaryReplicas = canaryDep.Status.ReadyReplicas
+	if impact.canaryReplicas == 0 && canaryDep.Spec.Replicas != nil {
+		impact.canaryReplicas = *canaryDep.Spec.Replicas
+	}
+
+	if check.RequestRate != nil {
+		metric := flaggerv1.CanaryMetric{Name: "request-rate", Interval: "1m"}
+		rps, err := c.runMetricTemplateQuery(cd, metric, *check.RequestRate, time.Time{})
+		if err != nil {
+			return impact, false, fmt.Errorf("request rate query error %v", err)
+		}
+		impact.requestRate = rps
+	}
+
+	// the replica capacity is known
+	if check.MaxRequestsPerReplica != nil && *check.MaxRequestsPerReplica > 0 && check.RequestRate != nil {
+		impact.requiredReplicas = int32(math.Ceil(impact.requestRate * weight / *check.MaxRequestsPerReplica))
+		return impact, true, nil
+	}
+
+	// the primary replicas run at the HPA utilization, the canary replicas must serve
+	// the step share of the primary load without exceeding the HPA target
+	if cd.Spec.AutoscalerRef == nil || cd.Spec.AutoscalerRef.Kind != "HorizontalPodAutoscaler" {
+		return impact, false, nil
+	}
+	hpaName := fmt.Sprintf("%s-primary", cd.Spec.AutoscalerRef.Name)
+	hpa, err := c.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers(cd.Namespace).Get(hpaName, metav1.GetOptions{})
+	if err != nil {
+		return impact, false, fmt.Errorf("HorizontalPodAutoscaler %s.%s query error %v", hpaName, cd.Namespace, err)
+	}
+
+	var target, current int32
+	for _, m := range hpa.Spec.Metrics {
+		if m.Resource != nil && m.Resource.Name == "cpu" && m.Resource.TargetAverageUtilization != nil {
+			target = *m.Resource.TargetAverageUtilization
+		}
+	}
+	for _, m := range hpa.Status.CurrentMetrics {
+		if m.Resource != nil && m.Resource.Name == "cpu" && m.Resource.CurrentAverageUtilization != nil {
+			current = *m.Resource.CurrentAverageUtilization
+		}
+	}
+	if target <= 0 || current <= 0 || hpa.Status.CurrentReplicas <= 0 {
+		return impact, false, nil
+	}
+
+	load := float64(hpa.Status.CurrentReplicas) * float64(current) / float64(target)
+	impact.requiredReplicas = int32(math.Ceil(load * weight))
+	return impact, true, nil
+}
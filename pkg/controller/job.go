@@ -1,6 +1,11 @@
 package controller
 
-import "time"
+import (
+	"math/rand"
+	"time"
+
+	"github.com/weaveworks/flagger/pkg/schedule"
+)
 
 // CanaryJob holds the reference to a canary deployment schedule
 type CanaryJob struct {
@@ -8,9 +13,16 @@ type CanaryJob struct {
 	Namespace        string
 	SkipTests        bool
 	function         func(name string, namespace string, skipTests bool)
+	routesFunction   func(name string, namespace string)
 	done             chan bool
 	ticker           *time.Ticker
+	routesTicker     *time.Ticker
+	routesEvents     chan struct{}
 	analysisInterval time.Duration
+	cron             *schedule.Cron
+	schedule         string
+	offset           time.Duration
+	jitter           time.Duration
 }
 
 // Start runs the canary analysis on a schedule
@@ -18,23 +30,120 @@ func (j CanaryJob) Start() {
 	go func() {
 		// run the infra bootstrap on job creation
 		j.function(j.Name, j.Namespace, j.SkipTests)
+
+		// the analysis runs at the cron times instead of every interval if a schedule is set
+		var timer *time.Timer
+		if j.cron != nil {
+			timer = j.nextRun()
+		}
+
+		// the analysis run is delayed when an offset or jitter is set
+		var delayed *time.Timer
+
 		for {
 			select {
-			case <-j.ticker.C:
+			case <-j.tickerC():
+				delayed = j.delayRun(delayed)
+			case <-timerC(timer):
+				delayed = j.delayRun(delayed)
+				timer = j.nextRun()
+			case <-timerC(delayed):
+				delayed = nil
 				j.function(j.Name, j.Namespace, j.SkipTests)
+			case <-j.routesC():
+				j.routesFunction(j.Name, j.Namespace)
+			case <-j.routesEventsC():
+				j.routesFunction(j.Name, j.Namespace)
 			case <-j.done:
+				if timer != nil {
+					timer.Stop()
+				}
+				if delayed != nil {
+					delayed.Stop()
+				}
 				return
 			}
 		}
 	}()
 }
 
+// delayRun runs the analysis or returns a timer that fires after the offset and a random jitter,
+// a run still pending when the next one is due is executed right away
+func (j CanaryJob) delayRun(pending *time.Timer) *time.Timer {
+	if pending != nil && pending.Stop() {
+		j.function(j.Name, j.Namespace, j.SkipTests)
+	}
+
+	delay := j.offset
+	if j.jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(j.jitter)))
+	}
+	if delay <= 0 {
+		j.function(j.Name, j.Namespace, j.SkipTests)
+		return nil
+	}
+	return time.NewTimer(delay)
+}
+
+// nextRun returns a timer that fires at the next cron time or nil if the schedule has no next run
+func (j CanaryJob) nextRun() *time.Timer {
+	now := time.Now()
+	next := j.cron.Next(now)
+	if next.IsZero() {
+		return nil
+	}
+	return time.NewTimer(next.Sub(now))
+}
+
+// tickerC returns the analysis ticker channel or nil if the analysis runs on a cron schedule
+func (j CanaryJob) tickerC() <-chan time.Time {
+	if j.ticker == nil {
+		return nil
+	}
+	return j.ticker.C
+}
+
+func timerC(t *time.Timer) <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+	return t.C
+}
+
 // Stop closes the job channel and stops the ticker
 func (j CanaryJob) Stop() {
 	close(j.done)
-	j.ticker.Stop()
+	if j.ticker != nil {
+		j.ticker.Stop()
+	}
+	if j.routesTicker != nil {
+		j.routesTicker.Stop()
+	}
+}
+
+// routesC returns the routes ticker channel or nil if the routes check is disabled,
+// receiving from a nil channel blocks forever
+func (j CanaryJob) routesC() <-chan time.Time {
+	if j.routesTicker == nil || j.routesFunction == nil {
+		return nil
+	}
+	return j.routesTicker.C
+}
+
+// routesEventsC returns the channel notified on the changes of the watched router objects
+// or nil if the router objects of the canary are not watched
+func (j CanaryJob) routesEventsC() <-chan struct{} {
+	if j.routesFunction == nil {
+		return nil
+	}
+	return j.routesEvents
 }
 
 func (j CanaryJob) GetCanaryAnalysisInterval() time.Duration {
 	return j.analysisInterval
 }
+
+// GetCanaryAnalysisSchedule returns the cron expression and time zone the job was created with
+func (j CanaryJob) GetCanaryAnalysisSchedule() string {
+	return j.schedule
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+const (
+	// jobFailureRateMetric is the increase of the canary failure percentage over the primary one
+	jobFailureRateMetric = "job-failure-rate"
+	// jobDurationMetric is the average canary duration as a percentage of the primary average duration
+	jobDurationMetric = "job-duration"
+)
+
+// runJobMetricChecks compares the finished canary executions with the primary ones
+// for the targets that are run as jobs
+func (c *Controller) runJobMetricChecks(cd *flaggerv1.Canary, groups *metricGroups) bool {
+	jobController, ok := c.canaryFactory.Controller(cd.Spec.TargetRef.Kind).(canary.JobController)
+	if !ok {
+		return true
+	}
+
+	var primary, canary canary.JobStats
+	loaded := false
+	for _, metric := range cd.GetAnalysis().Metrics {
+		if metric.Name != jobFailureRateMetric && metric.Name != jobDurationMetric {
+			continue
+		}
+
+		if !loaded {
+			var err error
+			primary, canary, err = jobController.GetJobStats(cd)
+			if err != nil {
+				c.recordEventErrorf(cd, "Job stats query failed: %v", err)
+				return false
+			}
+			loaded = true
+		}
+
+		if metric.Name == jobFailureRateMetric {
+			val := canary.FailureRate() - primary.FailureRate()
+			ok := isWithinThreshold(metric, val, false)
+			c.recordMetricValue(cd, metric.Name, val, ok)
+			if !ok && groups.halt(metric) {
+				c.recordEventWarningf(cd, "Halt %s.%s advancement job failure rate %.2f%% primary %.2f%% increase %.2f%% is not within threshold",
+					cd.Name, cd.Namespace, canary.FailureRate(), primary.FailureRate(), val)
+				return false
+			}
+		}
+
+		if metric.Name == jobDurationMetric {
+			if primary.Duration == 0 || canary.Duration == 0 {
+				c.recordEventInfof(cd, "Skipping %s check for %s.%s no succeeded primary and canary executions to compare",
+					metric.Name, cd.Name, cd.Namespace)
+				continue
+			}
+			val := float64(canary.Duration) / float64(primary.Duration) * 100
+			ok := isWithinThreshold(metric, val, false)
+			c.recordMetricValue(cd, metric.Name, val, ok)
+			if !ok && groups.halt(metric) {
+				c.recordEventWarningf(cd, "Halt %s.%s advancement job duration %v primary %v ratio %.2f%% is not within threshold",
+					cd.Name, cd.Namespace, canary.Duration.Round(time.Second), primary.Duration.Round(time.Second), val)
+				return false
+			}
+		}
+	}
+
+	return true
+}
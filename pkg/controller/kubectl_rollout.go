@@ -0,0 +1,97 @@
+package controller
+
+import (
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/router"
+)
+
+// runRolloutCommands maps the kubectl rollout commands applied to the target on the canary analysis,
+// pause halts the advancement, resume continues it and undo to the promoted revision rolls back the canary.
+// It returns false if the analysis should not advance.
+func (c *Controller) runRolloutCommands(cd *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) bool {
+	rolloutController, ok := canaryController.(canary.RolloutController)
+	if !ok {
+		return true
+	}
+
+	// the targets are paused and reverted by Flagger while initializing
+	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
+		return true
+	}
+
+	state, err := rolloutController.GetRolloutState(cd)
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+
+	analysing := cd.Status.Phase == flaggerv1.CanaryPhaseProgressing || cd.Status.Phase == flaggerv1.CanaryPhaseWaiting
+	switch {
+	case state.Undone && analysing:
+		c.recordEventWarningf(cd, "Rolling back %s.%s %s %s.%s was reverted to the promoted revision",
+			cd.Name, cd.Namespace, cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace)
+		c.alert(cd, "Rolling back, the target was reverted to the promoted revision", false, flaggerv1.SeverityWarn)
+		c.rollback(cd, canaryController, meshRouter)
+		return false
+	case state.Paused && isIdle(cd):
+		// the new revision is analysed once it's rolled out to the canary pods
+		c.recordEventWarningf(cd, "Halt %s.%s advancement %s %s.%s rollout is paused",
+			cd.Name, cd.Namespace, cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace)
+		return false
+	case state.Paused:
+		if cd.Status.Phase != flaggerv1.CanaryPhaseWaiting {
+			if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseWaiting); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+				return false
+			}
+			c.recordEventWarningf(cd, "Halt %s.%s advancement %s %s.%s rollout is paused",
+				cd.Name, cd.Namespace, cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace)
+			c.alert(cd, "Canary is waiting for the target rollout to be resumed.", false, flaggerv1.SeverityWarn)
+		}
+		return false
+	case cd.Status.Phase == flaggerv1.CanaryPhaseWaiting && !hasConfirmRolloutHooks(cd) && len(cd.GetAnalysis().Windows) == 0:
+		// the confirm-rollout gates and the analysis windows resume the analysis when they are open
+		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseProgressing); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return false
+		}
+		c.recordEventInfof(cd, "%s %s.%s rollout resumed", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace)
+		return false
+	}
+	return true
+}
+
+// isRolloutResumed returns true if the target rollout paused by Flagger was resumed with kubectl
+func (c *Controller) isRolloutResumed(cd *flaggerv1.Canary, canaryController canary.Controller) bool {
+	rolloutController, ok := canaryController.(canary.RolloutController)
+	if !ok {
+		return false
+	}
+
+	state, err := rolloutController.GetRolloutState(cd)
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+	return state.Resumed
+}
+
+// isIdle returns true if the canary is not analysing, promoting or rolling back a revision
+func isIdle(cd *flaggerv1.Canary) bool {
+	switch cd.Status.Phase {
+	case flaggerv1.CanaryPhaseInitialized, flaggerv1.CanaryPhaseSucceeded,
+		flaggerv1.CanaryPhaseFailed, flaggerv1.CanaryPhaseHolding, flaggerv1.CanaryPhaseDryRunPassed:
+		return true
+	}
+	return false
+}
+
+func hasConfirmRolloutHooks(cd *flaggerv1.Canary) bool {
+	for _, webhook := range cd.GetAnalysis().Webhooks {
+		if webhook.Type == flaggerv1.ConfirmRolloutHook {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// metricGroups tracks the failed checks of the grouped metrics during an analysis run,
+// the failures of the and groups are deferred until all the metrics are checked
+type metricGroups struct {
+	names     []string
+	operators map[string]string
+	members   map[string][]string
+	failed    map[string][]string
+}
+
+// newMetricGroups validates the metric groups of the canary analysis
+func newMetricGroups(canary *flaggerv1.Canary) (*metricGroups, error) {
+	g := &metricGroups{
+		operators: make(map[string]string),
+		members:   make(map[string][]string),
+		failed:    make(map[string][]string),
+	}
+
+	for _, group := range canary.GetAnalysis().MetricGroups {
+		operator := group.Operator
+		if operator == "" {
+			operator = flaggerv1.MetricGroupOr
+		}
+		if operator != flaggerv1.MetricGroupAnd && operator != flaggerv1.MetricGroupOr {
+			return nil, fmt.Errorf("metric group %s operator %s is not supported", group.Name, group.Operator)
+		}
+		g.names = append(g.names, group.Name)
+		g.operators[group.Name] = operator
+	}
+
+	for _, metric := range canary.GetAnalysis().Metrics {
+		if metric.Group == "" {
+			continue
+		}
+		if _, ok := g.operators[metric.Group]; !ok {
+			return nil, fmt.Errorf("metric %s group %s is not defined", metric.Name, metric.Group)
+		}
+		g.members[metric.Group] = append(g.members[metric.Group], metric.Name)
+	}
+
+	return g, nil
+}
+
+// halt records the failed check and returns true if the analysis must halt right away,
+// a nil metricGroups halts on every failed check
+func (g *metricGroups) halt(metric flaggerv1.CanaryMetric) bool {
+	if g == nil || g.operators[metric.Group] != flaggerv1.MetricGroupAnd {
+		return true
+	}
+	for _, name := range g.failed[metric.Group] {
+		if name == metric.Name {
+			return false
+		}
+	}
+	g.failed[metric.Group] = append(g.failed[metric.Group], metric.Name)
+	return false
+}
+
+// check returns an error for the first and group with all its metrics failed
+func (g *metricGroups) check() error {
+	for _, name := range g.names {
+		members := g.members[name]
+		if g.operators[name] != flaggerv1.MetricGroupAnd || len(members) == 0 {
+			continue
+		}
+		if len(g.failed[name]) == len(members) {
+			return fmt.Errorf("all metrics of group %s are not within threshold: %s", name, strings.Join(members, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// getProviderCredentials returns the decrypted secret data of the metric template provider
+// merged with the Vault secret and the CA bundle from the config map if they are referenced
+func (c *Controller) getProviderCredentials(template *flaggerv1.MetricTemplate) (map[string][]byte, error) {
+	var credentials map[string][]byte
+	if template.Spec.Provider.SecretRef != nil {
+		data, err := c.getSecretData(template.Namespace, template.Spec.Provider.SecretRef.Name)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s error: %v", template.Spec.Provider.SecretRef.Name, err)
+		}
+		credentials = data
+	}
+
+	if ref := template.Spec.Provider.VaultRef; ref != nil {
+		if c.vault == nil {
+			return nil, fmt.Errorf("vault %s error: vault client is not configured", ref.Path)
+		}
+		// the role is bound to the template namespace, a template can't read the secrets of other namespaces
+		data, err := c.vault.Read(template.Namespace, ref.Path)
+		if err != nil {
+			return nil, fmt.Errorf("vault %s error: %v", ref.Path, err)
+		}
+		// the Vault values take precedence over the secret ones
+		for k, v := range credentials {
+			if _, ok := data[k]; !ok {
+				data[k] = v
+			}
+		}
+		credentials = data
+	}
+
+	if template.Spec.Provider.CAConfigMapRef != nil {
+		cm, err := c.kubeClient.CoreV1().ConfigMaps(template.Namespace).Get(template.Spec.Provider.CAConfigMapRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("config map %s error: %v", template.Spec.Provider.CAConfigMapRef.Name, err)
+		}
+		ca, ok := cm.Data["ca.crt"]
+		if !ok {
+			return nil, fmt.Errorf("config map %s does not contain ca.crt", template.Spec.Provider.CAConfigMapRef.Name)
+		}
+		// copy the secret data to avoid mutating the informer cache
+		merged := make(map[string][]byte, len(credentials)+1)
+		for k, v := range credentials {
+			merged[k] = v
+		}
+		merged["ca.crt"] = []byte(ca)
+		credentials = merged
+	}
+
+	return credentials, nil
+}
+
+// checkProviders calls the health endpoint of the metrics server and of every
+// metric template provider to keep the provider online gauges up to date
+func (c *Controller) checkProviders() {
+	if ok, err := c.observerFactory.Client.IsOnline(); !ok || err != nil {
+		c.logger.Debugf("Metrics server health check failed: %v", err)
+	}
+
+	templates, err := c.flaggerInformers.MetricInformer.Lister().List(labels.Everything())
+	if err != nil {
+		c.logger.Errorf("Metric templates query error: %v", err)
+		return
+	}
+
+	for _, template := range templates {
+		credentials, err := c.getProviderCredentials(template)
+		if err != nil {
+			c.logger.Debugf("Metric template %s.%s %v", template.Name, template.Namespace, err)
+			continue
+		}
+
+		// the health checks are not retried, cached or accounted against the query budgets
+		spec := template.Spec.Provider
+		spec.Retry = nil
+		spec.CacheTTL = ""
+		// the metric interval is not used by the health checks
+		provider, err := providers.Factory{}.Provider("1m", spec, credentials)
+		if err != nil {
+			c.logger.Debugf("Metric template %s.%s provider %s error: %v", template.Name, template.Namespace, spec.Type, err)
+			continue
+		}
+
+		if ok, err := provider.IsOnline(); !ok || err != nil {
+			c.logger.Debugf("Metric template %s.%s provider %s health check failed: %v", template.Name, template.Namespace, spec.Type, err)
+		}
+	}
+}
+
+// validateMetricTemplate checks the provider identity headers of a metric template
+// and records a warning event on the template when they are not valid
+func (c *Controller) validateMetricTemplate(obj interface{}) {
+	template, ok := obj.(*flaggerv1.MetricTemplate)
+	if !ok {
+		return
+	}
+
+	if err := providers.ValidateHeaders(template.Spec.Provider); err != nil {
+		c.logger.Errorf("Metric template %s.%s provider %v", template.Name, template.Namespace, err)
+		c.eventRecorder.Event(template, corev1.EventTypeWarning, "Invalid", fmt.Sprintf("Provider %v", err))
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/releases"
+)
+
+const versionLabel = "app.kubernetes.io/version"
+
+// trackRelease notifies the release trackers of the canary, the errors are logged
+// since the release tracking must not block the analysis
+func (c *Controller) trackRelease(canary *flaggerv1.Canary, phase releases.Phase) {
+	if len(canary.GetAnalysis().ReleaseTrackers) == 0 {
+		return
+	}
+
+	release := releases.Release{
+		Name:      canary.Name,
+		Namespace: canary.Namespace,
+		Version:   c.releaseVersion(canary),
+		Phase:     phase,
+		Timestamp: time.Now(),
+	}
+
+	for _, tracker := range canary.GetAnalysis().ReleaseTrackers {
+		var credentials map[string][]byte
+		if tracker.SecretRef != nil {
+			data, err := c.getSecretData(canary.Namespace, tracker.SecretRef.Name)
+			if err != nil {
+				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+					Errorf("release tracker %s secretRef error: %v", tracker.Name, err)
+				continue
+			}
+			credentials = data
+		}
+
+		factory := releases.Factory{}
+		t, err := factory.Tracker(tracker, credentials)
+		if err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Errorf("release tracker %s error: %v", tracker.Name, err)
+			continue
+		}
+
+		release.Service = canary.Spec.TargetRef.Name
+		if tracker.Service != "" {
+			release.Service = tracker.Service
+		}
+		release.Environment = canary.Namespace
+		if tracker.Environment != "" {
+			release.Environment = tracker.Environment
+		}
+
+		if err := t.Track(release); err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Errorf("release tracker %s send error: %v", tracker.Name, err)
+		}
+	}
+}
+
+// releaseVersion returns the app.kubernetes.io/version label of the target pod template,
+// the image tag of the first container or, if the target can't be found, the last applied spec hash
+func (c *Controller) releaseVersion(canary *flaggerv1.Canary) string {
+	var template *corev1.PodTemplateSpec
+	switch canary.Spec.TargetRef.Kind {
+	case "Deployment":
+		dep, err := c.kubeClient.AppsV1().Deployments(canary.Namespace).Get(canary.Spec.TargetRef.Name, metav1.GetOptions{})
+		if err == nil {
+			template = &dep.Spec.Template
+		}
+	case "DaemonSet":
+		ds, err := c.kubeClient.AppsV1().DaemonSets(canary.Namespace).Get(canary.Spec.TargetRef.Name, metav1.GetOptions{})
+		if err == nil {
+			template = &ds.Spec.Template
+		}
+	}
+
+	if template == nil {
+		return canary.Status.LastAppliedSpec
+	}
+
+	if version, ok := template.Labels[versionLabel]; ok && version != "" {
+		return version
+	}
+
+	if len(template.Spec.Containers) > 0 {
+		image := template.Spec.Containers[0].Image
+		if i := strings.LastIndex(image, "@"); i > -1 {
+			return image[i+1:]
+		}
+		if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+			return image[i+1:]
+		}
+		return "latest"
+	}
+
+	return canary.Status.LastAppliedSpec
+}
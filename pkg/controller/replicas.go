@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+// proportionalReplicas returns the share of the primary replicas matching the canary weight,
+// rounded up so that the canary runs at least one replica
+func proportionalReplicas(primaryReplicas int32, canaryWeight int) int32 {
+	replicas := (int(primaryReplicas)*canaryWeight + 99) / 100
+	if replicas < 1 {
+		return 1
+	}
+	return int32(replicas)
+}
+
+// scaleCanaryToWeight scales the canary deployment to the share of the primary replicas matching the canary weight
+// before the traffic is shifted, it returns false if the canary can't be scaled
+func (c *Controller) scaleCanaryToWeight(cd *flaggerv1.Canary, canaryController canary.Controller, canaryWeight int) bool {
+	if !cd.GetAnalysis().ProportionalReplicas || cd.Spec.TargetRef.Kind != "Deployment" || cd.Spec.Cluster != nil {
+		return true
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(primaryName, metav1.GetOptions{})
+	if err != nil {
+		c.recordEventWarningf(cd, "deployment %s.%s query error %v", primaryName, cd.Namespace, err)
+		return false
+	}
+	canaryDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		c.recordEventWarningf(cd, "deployment %s.%s query error %v", cd.Spec.TargetRef.Name, cd.Namespace, err)
+		return false
+	}
+
+	primaryReplicas := int32(1)
+	if primary.Spec.Replicas != nil {
+		primaryReplicas = *primary.Spec.Replicas
+	}
+	replicas := proportionalReplicas(primaryReplicas, canaryWeight)
+	if canaryDep.Spec.Replicas != nil && *canaryDep.Spec.Replicas == replicas {
+		return true
+	}
+
+	if err := canaryController.Scale(cd, replicas); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+	c.recordEventInfof(cd, "Scaling %s.%s to %v replicas for canary weight %v",
+		cd.Spec.TargetRef.Name, cd.Namespace, replicas, canaryWeight)
+	return true
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// MetricResult is the outcome of a metric check
+type MetricResult struct {
+	Name      string
+	Value     float64
+	Passed    bool
+	Error     string
+	Timestamp time.Time
+}
+
+// metricResults holds the last metric results of each canary in memory,
+// the results are lost when the controller restarts
+type metricResults struct {
+	items sync.Map
+}
+
+func (r *metricResults) record(canary *flaggerv1.Canary, result MetricResult) {
+	key := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+
+	// copy on write, the analysis of a canary runs in a single goroutine
+	results := make(map[string]MetricResult)
+	if current, ok := r.items.Load(key); ok {
+		for k, v := range current.(map[string]MetricResult) {
+			results[k] = v
+		}
+	}
+	results[result.Name] = result
+	r.items.Store(key, results)
+}
+
+func (r *metricResults) get(name string, namespace string) []MetricResult {
+	current, ok := r.items.Load(fmt.Sprintf("%s.%s", name, namespace))
+	if !ok {
+		return nil
+	}
+
+	var results []MetricResult
+	for _, v := range current.(map[string]MetricResult) {
+		results = append(results, v)
+	}
+	return results
+}
+
+func (r *metricResults) delete(name string, namespace string) {
+	r.items.Delete(fmt.Sprintf("%s.%s", name, namespace))
+}
+
+// GetMetricResults returns the last metric check results of a canary
+func (c *Controller) GetMetricResults(name string, namespace string) []MetricResult {
+	return c.metricResults.get(name, namespace)
+}
+
+func (c *Controller) recordMetricValue(canary *flaggerv1.Canary, name string, value float64, passed bool) {
+	c.metricResults.record(canary, MetricResult{
+		Name:      name,
+		Value:     value,
+		Passed:    passed,
+		Timestamp: time.Now(),
+	})
+	c.timeSeries.append(canary, name, TimeSeriesSample{
+		Timestamp:    time.Now(),
+		CanaryWeight: canary.Status.CanaryWeight,
+		Canary:       value,
+	})
+}
+
+func (c *Controller) recordMetricError(canary *flaggerv1.Canary, name string, err error) {
+	c.metricResults.record(canary, MetricResult{
+		Name:      name,
+		Error:     err.Error(),
+		Timestamp: time.Now(),
+	})
+}
+
+// isWithinThreshold returns true if the value is inside the threshold range or,
+// if no range is specified, if the value is below the threshold or above it when minThreshold is set
+func isWithinThreshold(metric flaggerv1.CanaryMetric, val float64, minThreshold bool) bool {
+	if metric.ThresholdRange != nil {
+		tr := *metric.ThresholdRange
+		if tr.Min != nil && val < *tr.Min {
+			return false
+		}
+		if tr.Max != nil && val > *tr.Max {
+			return false
+		}
+		return true
+	}
+
+	if minThreshold {
+		return val >= metric.Threshold
+	}
+	return val <= metric.Threshold
+}
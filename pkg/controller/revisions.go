@@ -0,0 +1,69 @@
+package controller
+
+import (
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+// pauseRollout stops or resumes the rollout of new revisions to the canary pods
+// for the targets that support it, it returns false if the target couldn't be updated
+func (c *Controller) pauseRollout(cd *flaggerv1.Canary, canaryController canary.Controller, paused bool) bool {
+	revisionController, ok := canaryController.(canary.RevisionController)
+	if !ok {
+		return true
+	}
+
+	if err := revisionController.PauseRollout(cd, paused); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+	return true
+}
+
+// holdRevision applies the revision policy to a revision detected during the analysis,
+// it returns false if the analysis should be restarted for the new revision
+func (c *Controller) holdRevision(cd *flaggerv1.Canary, canaryController canary.Controller) bool {
+	policy := cd.GetRevisionPolicy()
+	if policy == flaggerv1.RevisionPolicyRestart {
+		return false
+	}
+
+	// there is no analysis evidence to keep before the first step
+	if cd.Status.CanaryWeight == 0 && cd.Status.Iterations == 0 {
+		return false
+	}
+
+	revisionController, ok := canaryController.(canary.RevisionController)
+	if !ok {
+		c.recordEventWarningf(cd, "Revision policy %s is not supported for %s targets, restarting analysis",
+			policy, cd.Spec.TargetRef.Kind)
+		return false
+	}
+
+	// the held revisions are rolled out when the rollout is resumed with kubectl
+	if c.isRolloutResumed(cd, canaryController) {
+		c.recordEventInfof(cd, "%s %s.%s rollout resumed, restarting analysis",
+			cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace)
+		return false
+	}
+
+	// the config changes are picked up by the running pods and can't be held back
+	if diff, _ := canaryController.HaveDependenciesChanged(cd); diff {
+		return false
+	}
+
+	switch policy {
+	case flaggerv1.RevisionPolicyQueue:
+		c.recordEventInfof(cd, "New revision of %s.%s queued until the analysis is finished",
+			cd.Spec.TargetRef.Name, cd.Namespace)
+	case flaggerv1.RevisionPolicyReject:
+		if err := revisionController.RevertRevision(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return true
+		}
+		c.recordEventWarningf(cd, "New revision of %s.%s rejected, the analysis is running",
+			cd.Spec.TargetRef.Name, cd.Namespace)
+		c.alert(cd, "New revision rejected while the canary analysis is running.", false, flaggerv1.SeverityWarn)
+	}
+	return true
+}
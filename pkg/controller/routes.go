@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// WatchRoutes checks the routing weights of the canaries when the router objects of the provider change,
+// the routes changed outside of Flagger are restored right away instead of in between the analysis runs.
+// It must be called before the controller runs.
+func (c *Controller) WatchRoutes(provider string, informer cache.SharedIndexInformer) {
+	c.routesWatch = provider
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			c.notifyRoutesChange(new)
+		},
+	})
+}
+
+// notifyRoutesChange signals the job of the canary that owns the router object,
+// the check runs in the job so that it never interleaves with the analysis
+func (c *Controller) notifyRoutesChange(obj interface{}) {
+	object, ok := obj.(metav1.Object)
+	if !ok {
+		return
+	}
+	owner := metav1.GetControllerOf(object)
+	if owner == nil || owner.Kind != flaggerv1.CanaryKind {
+		return
+	}
+
+	events, ok := c.routesEvents.Load(fmt.Sprintf("%s.%s", owner.Name, object.GetNamespace()))
+	if !ok {
+		return
+	}
+	// a pending check covers this change
+	select {
+	case events.(chan struct{}) <- struct{}{}:
+	default:
+	}
+}
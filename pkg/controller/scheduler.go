@@ -2,22 +2,73 @@ package controller
 
 import (
 	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
 	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/metrics/expression"
+	"github.com/weaveworks/flagger/pkg/metrics/judge"
 	"github.com/weaveworks/flagger/pkg/metrics/observers"
 	"github.com/weaveworks/flagger/pkg/metrics/providers"
+	"github.com/weaveworks/flagger/pkg/releases"
 	"github.com/weaveworks/flagger/pkg/router"
 )
 
 const (
 	MetricsProviderServiceSuffix = ":service"
+
+	// fireDrillMetric is the name of the metric reported as failed during a fire drill
+	fireDrillMetric = "fire-drill"
+)
+
+// reasons of the halted analysis iterations reported by the canary_halts_total metric
+const (
+	haltReasonConfirmRollout   = "confirm-rollout"
+	haltReasonConfirmPromotion = "confirm-promotion"
+	haltReasonPreRollout       = "pre-rollout"
+	haltReasonWebhook          = "webhook"
+	haltReasonMetrics          = "metrics"
+	haltReasonImpactCheck      = "impact-check"
+	haltReasonFireDrill        = "fire-drill"
+	haltReasonNotReady         = "not-ready"
+	haltReasonWindow           = "window"
+	haltReasonSuspended        = "suspended"
+	haltReasonHeadroom         = "headroom"
+	haltReasonWarmup           = "warmup"
 )
 
+// analysisDelay returns the offset and the max jitter of the analysis runs of a job ticking from the given time,
+// or aligned to the wall clock if the time is zero. When spreading is enabled, the offset aligns the runs to a point of the interval derived from the canary name,
+// so the canaries with the same interval are spread evenly and keep their slot across restarts.
+func (c *Controller) analysisDelay(cd *flaggerv1.Canary, start time.Time) (offset time.Duration, jitter time.Duration) {
+	interval := cd.GetAnalysisInterval()
+	if interval <= 0 {
+		return 0, 0
+	}
+	jitter = time.Duration(float64(interval) * c.analysisJitter)
+
+	if c.analysisSpread {
+		h := fnv.New32a()
+		h.Write([]byte(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)))
+		slot := time.Duration(uint64(h.Sum32()) % uint64(interval))
+		var phase time.Duration
+		if !start.IsZero() {
+			phase = time.Duration(start.UnixNano() % int64(interval))
+		}
+		offset = (slot - phase + interval) % interval
+	}
+	return offset, jitter
+}
+
 // scheduleCanaries synchronises the canary map with the jobs map,
 // for new canaries new jobs are created and started
 // for the removed canaries the jobs are stopped and deleted
@@ -33,8 +84,10 @@ func (c *Controller) scheduleCanaries() {
 		current[name] = fmt.Sprintf("%s.%s", canary.Spec.TargetRef.Name, canary.Namespace)
 
 		job, exists := c.jobs[name]
-		// schedule new job for existing job with different analysis interval or non-existing job
-		if (exists && job.GetCanaryAnalysisInterval() != canary.GetAnalysisInterval()) || !exists {
+		analysisSchedule := strings.TrimSpace(canary.GetAnalysis().Schedule + " " + canary.GetAnalysis().Timezone)
+		// schedule new job for existing job with different analysis interval or schedule or non-existing job
+		if (exists && (job.GetCanaryAnalysisInterval() != canary.GetAnalysisInterval() ||
+			job.GetCanaryAnalysisSchedule() != analysisSchedule)) || !exists {
 			if exists {
 				job.Stop()
 			}
@@ -43,9 +96,37 @@ func (c *Controller) scheduleCanaries() {
 				Name:             canary.Name,
 				Namespace:        canary.Namespace,
 				function:         c.advanceCanary,
+				routesFunction:   c.checkRoutes,
 				done:             make(chan bool),
-				ticker:           time.NewTicker(canary.GetAnalysisInterval()),
 				analysisInterval: canary.GetAnalysisInterval(),
+				schedule:         analysisSchedule,
+			}
+
+			cron, err := canary.GetAnalysisSchedule()
+			if err != nil {
+				c.recordEventErrorf(canary, "Analysis schedule error, falling back to the %v interval: %v",
+					canary.GetAnalysisInterval(), err)
+			}
+			if cron != nil {
+				newJob.cron = cron
+			} else {
+				newJob.ticker = time.NewTicker(canary.GetAnalysisInterval())
+			}
+			// the cron runs are aligned to the wall clock while the ticks start with the job
+			start := time.Now()
+			if newJob.cron != nil {
+				start = time.Time{}
+			}
+			newJob.offset, newJob.jitter = c.analysisDelay(canary, start)
+
+			// check the routing weights when the router objects change, or in between the analysis runs
+			// if the router objects of the canary provider are not watched
+			c.routesEvents.Delete(name)
+			if c.routesWatch != "" && c.getProvider(canary) == c.routesWatch {
+				newJob.routesEvents = make(chan struct{}, 1)
+				c.routesEvents.Store(name, newJob.routesEvents)
+			} else if c.flaggerWindow > 0 && (newJob.cron != nil || c.flaggerWindow < canary.GetAnalysisInterval()) {
+				newJob.routesTicker = time.NewTicker(c.flaggerWindow)
 			}
 
 			c.jobs[name] = newJob
@@ -67,6 +148,7 @@ func (c *Controller) scheduleCanaries() {
 		if _, exists := current[job]; !exists {
 			c.jobs[job].Stop()
 			delete(c.jobs, job)
+			c.routesEvents.Delete(job)
 		}
 	}
 
@@ -95,14 +177,90 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 		return
 	}
 
+	// read the analysis bookkeeping from the state store
+	if err := c.canaryFactory.LoadState(cd); err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
+			Errorf("State store query error %v", err)
+		return
+	}
+
+	// revert the routing and the target scaling before the canary is deleted
+	if cd.DeletionTimestamp != nil {
+		c.finalize(cd, skipLivenessChecks)
+		return
+	}
+
+	// merge the analysis defaults of the canary class
+	cd, err = c.applyCanaryClass(cd)
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).Errorf("%v", err)
+		return
+	}
+
+	// reject the specs that can't be analysed before the canary is initialized
+	if !c.syncValidCondition(cd) {
+		return
+	}
+
+	// save the logs and results of the finished hook jobs
+	c.syncHookArtifacts(cd)
+
+	// refresh the time left until the estimated promotion
+	if eta := cd.Status.PromotionETA; eta != nil {
+		c.recorder.SetPromotionRemaining(cd, time.Until(eta.Time))
+	} else {
+		c.recorder.DeletePromotionRemaining(cd)
+	}
+
 	// override the global provider if one is specified in the canary spec
 	provider := c.meshProvider
 	if cd.Spec.Provider != "" {
 		provider = cd.Spec.Provider
 	}
 
+	// jobs don't receive traffic
+	if cd.Spec.TargetRef.Kind == "CronJob" {
+		provider = "kubernetes"
+	}
+
+	// the StatefulSet pods are not routed, the canary weight is the percentage of pods above the rolling update partition
+	if cd.Spec.TargetRef.Kind == "StatefulSet" {
+		provider = "partition"
+	}
+
+	// the mesh and ingress routers can't split UDP traffic, datagram services use blue/green
+	if cd.Spec.Service.Protocol == corev1.ProtocolUDP && !router.SupportsUDP(provider) {
+		c.recordEventWarningf(cd, "UDP routing is not supported by the %s provider, using the kubernetes provider", provider)
+		provider = "kubernetes"
+	}
+
+	// the canary of a second cluster is reachable only through the DNS records or a multi-cluster mesh
+	if cd.Spec.Cluster != nil && !router.SupportsMultiCluster(provider) {
+		c.recordEventWarningf(cd, "multi-cluster canary is not supported by the %s provider, use externaldns or istio", provider)
+		return
+	}
+
+	// the gRPC calls are matched by the routers that support them, the other routers would send all calls to the canary
+	if len(cd.GetAnalysis().GRPCMatch) > 0 {
+		if !router.SupportsGRPCMatch(provider) {
+			c.recordEventWarningf(cd, "gRPC match conditions are not supported by the %s provider, use istio", provider)
+			return
+		}
+		appendGRPCMatch(cd)
+	}
+
+	// the canary weight only increases during the analysis
+	if weights := cd.GetAnalysis().StepWeights; !isIncreasing(weights) {
+		c.recordEventWarningf(cd, "Step weights %v must be increasing values between 1 and 100", weights)
+		return
+	}
+
 	// init controller based on target kind
-	canaryController := c.canaryFactory.Controller(cd.Spec.TargetRef.Kind)
+	canaryController, err := c.getCanaryController(cd)
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
 	labelSelector, ports, err := canaryController.GetMetadata(cd)
 	if err != nil {
 		c.recordEventWarningf(cd, "%v", err)
@@ -110,8 +268,16 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 	}
 
 	// init Kubernetes router
-	router := c.routerFactory.KubernetesRouter(cd.Spec.TargetRef.Kind, labelSelector, map[string]string{}, ports)
-	if err := router.Initialize(cd); err != nil {
+	kubeRouter, err := c.getKubernetesRouter(cd, provider, labelSelector, ports)
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	if ok := c.syncFinalizer(cd); !ok {
+		return
+	}
+
+	if err := kubeRouter.Initialize(cd); err != nil {
 		c.recordEventWarningf(cd, "%v", err)
 		return
 	}
@@ -127,17 +293,29 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 	meshRouter := c.routerFactory.MeshRouter(provider)
 
 	// create or update svc
-	if err := router.Reconcile(cd); err != nil {
+	if err := kubeRouter.Reconcile(cd); err != nil {
 		c.recordEventWarningf(cd, "%v", err)
 		return
 	}
 
+	// clone the Prometheus Operator monitors, scraping errors must not block the analysis
+	if c.cloneMonitors && cd.Spec.Cluster == nil {
+		if err := c.routerFactory.MonitorsRouter(labelSelector).Reconcile(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+	}
+
 	// create or update mesh routes
 	if err := meshRouter.Reconcile(cd); err != nil {
 		c.recordEventWarningf(cd, "%v", err)
 		return
 	}
 
+	// keep all traffic on primary during a dry run
+	if cd.GetAnalysis().DryRun {
+		meshRouter = &dryRunRouter{}
+	}
+
 	// check for changes
 	shouldAdvance, err := c.shouldAdvance(cd, canaryController)
 	if err != nil {
@@ -146,20 +324,67 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 	}
 
 	if !shouldAdvance {
+		// keep the canary at the hold weight until a new revision is detected
+		if cd.Status.Phase == flaggerv1.CanaryPhaseHolding {
+			c.checkHold(cd, canaryController, meshRouter)
+		}
 		c.recorder.SetStatus(cd, cd.Status.Phase)
 		return
 	}
 
+	// route all traffic back to primary when a new revision replaces the held canary
+	if cd.Status.Phase == flaggerv1.CanaryPhaseHolding {
+		if err := meshRouter.SetRoutes(cd, 100, 0, false); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+		if err := canaryController.SetStatusWeight(cd, 0); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+		cd.Status.CanaryWeight = 0
+	}
+
+	// check the kubectl rollout pause, resume and undo commands
+	if ok := c.runRolloutCommands(cd, canaryController, meshRouter); !ok {
+		return
+	}
+
+	// check the promote and rollback commands
+	if ok := c.runCanaryCommands(cd, canaryController, meshRouter, skipLivenessChecks); !ok {
+		return
+	}
+
+	// freeze the canary while it's suspended, the suspended time doesn't count towards the analysis deadline
+	if cd.Spec.Suspend {
+		if cd.Status.AnalysisStartTime != nil && cd.Status.SuspendTime == nil {
+			if err := canaryController.SetStatusSuspended(cd, true); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+			}
+		}
+		c.recordHalt(cd, haltReasonSuspended, "canary is suspended")
+		c.recordEventWarningf(cd, "Halt %s.%s advancement canary is suspended", cd.Name, cd.Namespace)
+		return
+	}
+	if cd.Status.SuspendTime != nil {
+		if err := canaryController.SetStatusSuspended(cd, false); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+	}
+
+	// check the analysis windows
+	if ok := c.runAnalysisWindows(cd, canaryController); !ok {
+		return
+	}
+
 	// check gates
 	if isApproved := c.runConfirmRolloutHooks(cd, canaryController); !isApproved {
 		return
 	}
 
-	// set max weight default value to 100%
-	maxWeight := 100
-	if cd.GetAnalysis().MaxWeight > 0 {
-		maxWeight = cd.GetAnalysis().MaxWeight
-	}
+	// set max weight default value to 100% or to the last step weight
+	maxWeight := cd.GetMaxWeight()
 
 	// check primary status
 	if !skipLivenessChecks && !cd.SkipAnalysis() {
@@ -176,6 +401,12 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 		return
 	}
 
+	// restore the routing weights if they were reset by the mesh or ingress controller
+	if restored := c.restoreRoutes(cd, meshRouter, provider, canaryWeight, mirrored); restored {
+		primaryWeight = 100 - cd.Status.CanaryWeight
+		canaryWeight = cd.Status.CanaryWeight
+	}
+
 	c.recorder.SetWeight(cd, primaryWeight, canaryWeight)
 
 	// check if canary analysis should start (canary revision has changes) or continue
@@ -184,10 +415,15 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 	}
 
 	// check if canary revision changed during analysis
-	if restart := c.hasCanaryRevisionChanged(cd, canaryController); restart {
+	if restart := c.hasCanaryRevisionChanged(cd, canaryController); restart && !c.holdRevision(cd, canaryController) {
 		c.recordEventInfof(cd, "New revision detected! Restarting analysis for %s.%s",
 			cd.Spec.TargetRef.Name, cd.Namespace)
 
+		// roll out the new revision
+		if ok := c.pauseRollout(cd, canaryController, false); !ok {
+			return
+		}
+
 		// route all traffic back to primary
 		primaryWeight = 100
 		canaryWeight = 0
@@ -210,12 +446,27 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 		return
 	}
 
+	// continue the stepped rollback, the new revisions are picked up once the rollback is finished
+	if cd.Status.Phase == flaggerv1.CanaryPhaseRollingBack {
+		c.rollback(cd, canaryController, meshRouter)
+		return
+	}
+
 	// check canary status
 	var retriable = true
 	if !skipLivenessChecks {
 		retriable, err = canaryController.IsCanaryReady(cd)
 		if err != nil && retriable {
 			c.recordEventWarningf(cd, "%v", err)
+			c.recordHalt(cd, haltReasonNotReady, "%v", err)
+			return
+		}
+	}
+
+	// hold back the revisions applied while the analysis is running
+	if err == nil && cd.Status.Phase == flaggerv1.CanaryPhaseProgressing &&
+		cd.GetRevisionPolicy() != flaggerv1.RevisionPolicyRestart {
+		if ok := c.pauseRollout(cd, canaryController, true); !ok {
 			return
 		}
 	}
@@ -225,6 +476,11 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 		return
 	}
 
+	// promote the hotfix revisions once the canary pods are ready
+	if err == nil && c.promoteHotfix(cd, canaryController, meshRouter) {
+		return
+	}
+
 	// check if we should rollback
 	if cd.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
 		cd.Status.Phase == flaggerv1.CanaryPhaseWaiting {
@@ -238,6 +494,22 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 
 	// route all traffic to primary if analysis has succeeded
 	if cd.Status.Phase == flaggerv1.CanaryPhasePromoting {
+		// shift the traffic to the promoted primary in steps to warm it up
+		if step := cd.GetAnalysis().StepWeightPromotion; step > 0 && provider != "kubernetes" && canaryWeight > step {
+			canaryWeight -= step
+			if err := meshRouter.SetRoutes(cd, 100-canaryWeight, canaryWeight, false); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+				return
+			}
+			if err := canaryController.SetStatusWeight(cd, canaryWeight); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+				return
+			}
+			c.recorder.SetWeight(cd, 100-canaryWeight, canaryWeight)
+			c.recordEventInfof(cd, "Advance %s.%s primary weight %v", cd.Name, cd.Namespace, 100-canaryWeight)
+			return
+		}
+
 		if provider != "kubernetes" {
 			c.recordEventInfof(cd, "Routing all traffic to primary")
 			if err := meshRouter.SetRoutes(cd, 100, 0, false); err != nil {
@@ -263,6 +535,11 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 			return
 		}
 
+		// resume the rollout of the queued revision
+		if ok := c.pauseRollout(cd, canaryController, false); !ok {
+			return
+		}
+
 		// set status to succeeded
 		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseSucceeded); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
@@ -273,17 +550,24 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 		c.recordEventInfof(cd, "Promotion completed! Scaling down %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
 		c.alert(cd, "Canary analysis completed successfully, promotion finished.",
 			false, flaggerv1.SeverityInfo)
+		c.trackRelease(cd, releases.PhasePromoted)
 		return
 	}
 
-	// check if the number of failed checks reached the threshold
+	// check if the number of failed checks reached the threshold or the analysis deadline passed
+	deadlineExceeded := analysisDeadlineExceeded(cd)
 	if cd.Status.Phase == flaggerv1.CanaryPhaseProgressing &&
-		(!retriable || cd.Status.FailedChecks >= cd.GetAnalysisThreshold()) {
+		(!retriable || deadlineExceeded || cd.Status.FailedChecks >= cd.GetAnalysisThreshold()) {
 		if !retriable {
 			c.recordEventWarningf(cd, "Rolling back %s.%s progress deadline exceeded %v",
 				cd.Name, cd.Namespace, err)
 			c.alert(cd, fmt.Sprintf("Progress deadline exceeded %v", err),
 				false, flaggerv1.SeverityError)
+		} else if deadlineExceeded && cd.Status.FailedChecks < cd.GetAnalysisThreshold() {
+			c.recordEventWarningf(cd, "Rolling back %s.%s analysis deadline %v exceeded at canary weight %v",
+				cd.Name, cd.Namespace, cd.GetAnalysisDeadline(), cd.Status.CanaryWeight)
+			c.alert(cd, fmt.Sprintf("Analysis deadline %v exceeded at canary weight %v",
+				cd.GetAnalysisDeadline(), cd.Status.CanaryWeight), false, flaggerv1.SeverityError)
 		}
 		c.rollback(cd, canaryController, meshRouter)
 		return
@@ -294,11 +578,30 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 		c.recorder.SetDuration(cd, time.Since(begin))
 	}()
 
+	// run the baseline workload for the comparison metrics
+	if ok := c.reconcileBaseline(cd, canaryController, skipLivenessChecks); !ok {
+		return
+	}
+
 	// check if the canary success rate is above the threshold
 	// skip check if no traffic is routed or mirrored to canary
 	if canaryWeight == 0 && cd.Status.Iterations == 0 &&
 		(cd.GetAnalysis().Mirror == false || mirrored == false) {
 		c.recordEventInfof(cd, "Starting canary analysis for %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+		c.queryBudgets.delete(cd.Name, cd.Namespace)
+		c.timeSeries.delete(cd.Name, cd.Namespace)
+		c.halts.delete(cd.Name, cd.Namespace)
+		// the canary pods are ready, the warmup starts with the last attempt before the first step
+		c.warmupStarts.start(cd)
+		// the analysis start is retried until the pre-rollout hooks pass, notify only the first attempt
+		if cd.Status.FailedChecks == 0 {
+			c.trackRelease(cd, releases.PhaseStarted)
+		}
+
+		// wait for the pre-rollout jobs to finish, running jobs don't count as failed checks
+		if ok := c.waitPreRolloutJobs(cd); !ok {
+			return
+		}
 
 		// run pre-rollout web hooks
 		if ok := c.runPreRolloutHooks(cd); !ok {
@@ -306,16 +609,58 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 				c.recordEventWarningf(cd, "%v", err)
 				return
 			}
+			c.updatePromotionETA(cd, canaryController, provider, canaryWeight, cd.Status.Iterations, mirrored)
+			return
+		}
+
+		// check that the canary replicas can absorb the first traffic step
+		if ok := c.runImpactCheck(cd); !ok {
+			c.recordHalt(cd, haltReasonImpactCheck, "canary replicas can't absorb the first traffic step")
+			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+				return
+			}
+			c.updatePromotionETA(cd, canaryController, provider, canaryWeight, cd.Status.Iterations, mirrored)
 			return
 		}
 	} else {
 		if ok := c.runAnalysis(cd); !ok {
+			// the failed checks are not counted while the canary warms up, the weight is held
+			if remaining := c.warmupStarts.remaining(cd); remaining > 0 {
+				c.recordEventInfof(cd, "Warmup %s.%s failed checks are not counted for %v",
+					cd.Name, cd.Namespace, remaining.Round(time.Second))
+				c.recordHalt(cd, haltReasonWarmup, "failed checks are not counted during the warmup")
+				return
+			}
+			c.resetWarmup(cd, canaryController, provider, canaryWeight)
 			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
 				c.recordEventWarningf(cd, "%v", err)
 				return
 			}
+			c.updatePromotionETA(cd, canaryController, provider, canaryWeight, cd.Status.Iterations, mirrored)
+			return
+		}
+	}
+	c.halts.delete(cd.Name, cd.Namespace)
+
+	// simulate a failing metric check without routing traffic to the canary
+	if cd.GetAnalysis().FireDrill {
+		c.recordEventWarningf(cd, "Fire drill! Halt %s.%s advancement simulated failure of metric %s",
+			cd.Name, cd.Namespace, fireDrillMetric)
+		c.recordMetricError(cd, fireDrillMetric, fmt.Errorf("simulated failure"))
+		c.recordHalt(cd, haltReasonFireDrill, "fire drill simulated failure of metric %s", fireDrillMetric)
+		c.resetWarmup(cd, canaryController, provider, canaryWeight)
+		if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
 			return
 		}
+		c.updatePromotionETA(cd, canaryController, provider, canaryWeight, cd.Status.Iterations, mirrored)
+		return
+	}
+
+	// the finished canary executions are evaluated once for cron jobs
+	if cd.Spec.TargetRef.Kind == "CronJob" && cd.GetAnalysis().Iterations < 1 {
+		cd.GetAnalysis().Iterations = 1
 	}
 
 	// use blue/green strategy for kubernetes provider
@@ -339,46 +684,154 @@ func (c *Controller) advanceCanary(name string, namespace string, skipLivenessCh
 
 	// strategy: Blue/Green
 	if cd.GetAnalysis().Iterations > 0 {
-		c.runBlueGreen(cd, canaryController, meshRouter, provider, mirrored)
+		c.runBlueGreen(cd, canaryController, meshRouter, provider, mirrored, canaryWeight)
 		return
 	}
 
 	// strategy: Canary progressive traffic increase
-	if cd.GetAnalysis().StepWeight > 0 {
+	if cd.HasStepWeights() {
 		c.runCanary(cd, canaryController, meshRouter, provider, mirrored, canaryWeight, primaryWeight, maxWeight)
 	}
 
 }
 
+// checkRoutes runs in between the analysis intervals and re-asserts the canary weight
+// when the routing objects were modified outside of Flagger
+func (c *Controller) checkRoutes(name string, namespace string) {
+	cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return
+	}
+	cd, err = c.applyCanaryClass(cd)
+	if err != nil {
+		return
+	}
+
+	provider := c.getProvider(cd)
+	if len(cd.GetAnalysis().GRPCMatch) > 0 {
+		if !router.SupportsGRPCMatch(provider) {
+			return
+		}
+		appendGRPCMatch(cd)
+	}
+
+	if !hasExpectedWeight(cd, provider) {
+		return
+	}
+
+	meshRouter := c.routerFactory.MeshRouter(provider)
+	_, canaryWeight, mirrored, err := meshRouter.GetRoutes(cd)
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).Errorf("%v", err)
+		return
+	}
+
+	c.restoreRoutes(cd, meshRouter, provider, canaryWeight, mirrored)
+}
+
+// getProvider returns the router provider of the canary, the kind defaults and the UDP fallback
+// are applied silently, the analysis reports them
+func (c *Controller) getProvider(cd *flaggerv1.Canary) string {
+	provider := c.meshProvider
+	if cd.Spec.Provider != "" {
+		provider = cd.Spec.Provider
+	}
+	if cd.Spec.TargetRef.Kind == "CronJob" {
+		provider = "kubernetes"
+	}
+	if cd.Spec.TargetRef.Kind == "StatefulSet" {
+		provider = "partition"
+	}
+	if cd.Spec.Service.Protocol == corev1.ProtocolUDP && !router.SupportsUDP(provider) {
+		provider = "kubernetes"
+	}
+	return provider
+}
+
+// restoreRoutes sets the routes to the weight recorded in the canary status
+// if the current canary weight differs, it returns true if the routes were restored
+func (c *Controller) restoreRoutes(cd *flaggerv1.Canary, meshRouter router.Interface, provider string, canaryWeight int, mirrored bool) bool {
+	if !hasExpectedWeight(cd, provider) || canaryWeight == cd.Status.CanaryWeight {
+		return false
+	}
+
+	c.recordEventWarningf(cd, "Routing weight drift detected for %s.%s canary weight %v expected %v, restoring routes",
+		cd.Name, cd.Namespace, canaryWeight, cd.Status.CanaryWeight)
+	if err := meshRouter.SetRoutes(cd, 100-cd.Status.CanaryWeight, cd.Status.CanaryWeight, mirrored); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+	c.recorder.SetWeight(cd, 100-cd.Status.CanaryWeight, cd.Status.CanaryWeight)
+
+	return true
+}
+
+// hasExpectedWeight returns true if the canary is progressively shifting traffic
+// and the status holds the weight that should be applied to the routes
+func hasExpectedWeight(cd *flaggerv1.Canary, provider string) bool {
+	// the routes of a dry run stay on primary, the canary weight is only recorded in the status
+	if provider == "kubernetes" || cd.SkipAnalysis() || cd.GetAnalysis().DryRun {
+		return false
+	}
+	if cd.Status.Phase != flaggerv1.CanaryPhaseProgressing && cd.Status.Phase != flaggerv1.CanaryPhaseWaiting &&
+		cd.Status.Phase != flaggerv1.CanaryPhaseRollingBack && cd.Status.Phase != flaggerv1.CanaryPhaseHolding {
+		return false
+	}
+	analysis := cd.GetAnalysis()
+	if analysis.Iterations > 0 || !cd.HasStepWeights() {
+		return false
+	}
+
+	// the weight is zero at the start of the analysis and during the mirroring step
+	return cd.Status.CanaryWeight > 0
+}
+
 func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface, provider string, mirrored bool, canaryWeight int, primaryWeight int, maxWeight int) {
 	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
 
 	// increase traffic weight
 	if canaryWeight < maxWeight {
-		// If in "mirror" mode, do one step of mirroring before shifting traffic to canary.
-		// When mirroring, all requests go to primary and canary, but only responses from
-		// primary go back to the user.
+		// keep the first step weight until the warmup checks have passed consecutively
+		if canaryWeight > 0 && warmupHolds(canary, canaryWeight, canary.Status.Iterations) > 0 {
+			iterations := canary.Status.Iterations + 1
+			if err := canaryController.SetStatusIterations(canary, iterations); err != nil {
+				c.recordEventWarningf(canary, "%v", err)
+				return
+			}
+			c.updatePromotionETA(canary, canaryController, provider, canaryWeight, iterations, false)
+			c.recordEventInfof(canary, "Advance %s.%s warmup check %v/%v at canary weight %v",
+				canary.Name, canary.Namespace, iterations-warmupBase(canary), canary.GetAnalysis().WarmupIterations, canaryWeight)
+			return
+		}
+
+		// If in "mirror" mode, run the mirror-only iterations before shifting traffic to canary.
+		// When mirroring, the requests go to primary and canary, but only responses from
+		// primary go back to the user. The mirror iterations are counted in the status iterations.
 		if canary.GetAnalysis().Mirror && canaryWeight == 0 {
-			if mirrored == false {
+			if canary.Status.Iterations < canary.GetMirrorIterations() {
 				mirrored = true
 				primaryWeight = 100
 				canaryWeight = 0
 			} else {
 				mirrored = false
-				primaryWeight = 100 - canary.GetAnalysis().StepWeight
-				canaryWeight = canary.GetAnalysis().StepWeight
+				canaryWeight = canary.GetNextStepWeight(0)
+				primaryWeight = 100 - canaryWeight
 			}
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("Running mirror step %d/%d/%t", primaryWeight, canaryWeight, mirrored)
 		} else {
-
-			primaryWeight -= canary.GetAnalysis().StepWeight
-			if primaryWeight < 0 {
-				primaryWeight = 0
+			next, held := c.nextStepWeight(canary, canaryWeight)
+			if held {
+				return
 			}
-			canaryWeight += canary.GetAnalysis().StepWeight
-			if canaryWeight > 100 {
-				canaryWeight = 100
+			canaryWeight = next
+			primaryWeight = 100 - canaryWeight
+		}
+
+		// size the canary for its share of the traffic before shifting it
+		if !mirrored {
+			if ok := c.scaleCanaryToWeight(canary, canaryController, canaryWeight); !ok {
+				return
 			}
 		}
 
@@ -393,14 +846,41 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 		}
 
 		c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
+
+		if mirrored {
+			iterations := canary.Status.Iterations + 1
+			if err := canaryController.SetStatusIterations(canary, iterations); err != nil {
+				c.recordEventWarningf(canary, "%v", err)
+				return
+			}
+			c.updatePromotionETA(canary, canaryController, provider, canaryWeight, iterations, mirrored)
+			c.recordEventInfof(canary, "Advance %s.%s mirror iteration %v/%v",
+				canary.Name, canary.Namespace, iterations, canary.GetMirrorIterations())
+			return
+		}
+
+		c.updatePromotionETA(canary, canaryController, provider, canaryWeight, canary.Status.Iterations, mirrored)
 		c.recordEventInfof(canary, "Advance %s.%s canary weight %v", canary.Name, canary.Namespace, canaryWeight)
 		return
 	}
 
 	// promote canary - max weight reached
 	if canaryWeight >= maxWeight {
+		// report the verdict of a dry run instead of promoting the canary
+		if canary.GetAnalysis().DryRun {
+			c.finishDryRun(canary, canaryController)
+			return
+		}
+
+		// keep the canary at the hold weight instead of promoting it
+		if holdWeight := canary.GetAnalysis().HoldWeight; holdWeight > 0 {
+			c.hold(canary, canaryController, meshRouter, holdWeight)
+			return
+		}
+
 		// check promotion gate
 		if promote := c.runConfirmPromotionHooks(canary); !promote {
+			c.clearPromotionETA(canary, canaryController)
 			return
 		}
 
@@ -435,13 +915,21 @@ func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Con
 			c.recordEventWarningf(canary, "%v", err)
 			return
 		}
+		c.updatePromotionETA(canary, canaryController, provider, canary.Status.CanaryWeight, canary.Status.Iterations+1, false)
 		c.recordEventInfof(canary, "Advance %s.%s canary iteration %v/%v",
 			canary.Name, canary.Namespace, canary.Status.Iterations+1, canary.GetAnalysis().Iterations)
 		return
 	}
 
+	// report the verdict of a dry run instead of promoting the canary
+	if canary.GetAnalysis().DryRun {
+		c.finishDryRun(canary, canaryController)
+		return
+	}
+
 	// check promotion gate
 	if promote := c.runConfirmPromotionHooks(canary); !promote {
+		c.clearPromotionETA(canary, canaryController)
 		return
 	}
 
@@ -462,14 +950,29 @@ func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Con
 	}
 }
 
-func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface, provider string, mirrored bool) {
+func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface, provider string, mirrored bool, canaryWeight int) {
 	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
 
 	// increment iterations
 	if canary.GetAnalysis().Iterations > canary.Status.Iterations {
-		// If in "mirror" mode, mirror requests during the entire B/G canary test
-		if provider != "kubernetes" &&
-			canary.GetAnalysis().Mirror == true && mirrored == false {
+		mirrorIterations := canary.GetAnalysis().MirrorIterations
+		switch {
+		// switch all traffic to canary at once when the mirror validation stage has passed,
+		// the remaining iterations are checked against the live traffic
+		case provider != "kubernetes" && canary.GetAnalysis().Mirror && mirrorIterations > 0 &&
+			canary.Status.Iterations >= mirrorIterations:
+			if mirrored || canaryWeight < 100 {
+				if err := meshRouter.SetRoutes(canary, 0, 100, false); err != nil {
+					c.recordEventWarningf(canary, "%v", err)
+					return
+				}
+				c.recorder.SetWeight(canary, 0, 100)
+				c.recordEventInfof(canary, "Mirror validation passed, routing all traffic to canary %s.%s",
+					canary.Spec.TargetRef.Name, canary.Namespace)
+			}
+		// If in "mirror" mode, mirror requests during the entire B/G canary test or its mirror validation stage
+		case provider != "kubernetes" &&
+			canary.GetAnalysis().Mirror == true && mirrored == false:
 			if err := meshRouter.SetRoutes(canary, 100, 0, true); err != nil {
 				c.recordEventWarningf(canary, "%v", err)
 			}
@@ -480,13 +983,21 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 			c.recordEventWarningf(canary, "%v", err)
 			return
 		}
+		c.updatePromotionETA(canary, canaryController, provider, canary.Status.CanaryWeight, canary.Status.Iterations+1, false)
 		c.recordEventInfof(canary, "Advance %s.%s canary iteration %v/%v",
 			canary.Name, canary.Namespace, canary.Status.Iterations+1, canary.GetAnalysis().Iterations)
 		return
 	}
 
+	// report the verdict of a dry run instead of promoting the canary
+	if canary.GetAnalysis().DryRun {
+		c.finishDryRun(canary, canaryController)
+		return
+	}
+
 	// check promotion gate
 	if promote := c.runConfirmPromotionHooks(canary); !promote {
+		c.clearPromotionETA(canary, canaryController)
 		return
 	}
 
@@ -510,6 +1021,7 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 			c.recordEventWarningf(canary, "%v", err)
 			return
 		}
+		c.updatePromotionETA(canary, canaryController, provider, canary.Status.CanaryWeight, canary.Status.Iterations+1, false)
 		return
 	}
 
@@ -536,9 +1048,15 @@ func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryControll
 		return false
 	}
 
+	return c.promoteWithoutAnalysis(canary, canaryController, meshRouter, "Canary analysis was skipped")
+}
+
+// promoteWithoutAnalysis promotes the canary revision and routes all traffic to primary,
+// the reason is reported in the promotion event and alert
+func (c *Controller) promoteWithoutAnalysis(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface, reason string) bool {
 	// route all traffic to primary
-	primaryWeight = 100
-	canaryWeight = 0
+	primaryWeight := 100
+	canaryWeight := 0
 	if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, false); err != nil {
 		c.recordEventWarningf(canary, "%v", err)
 		return false
@@ -567,10 +1085,11 @@ func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryControll
 
 	// notify
 	c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseSucceeded)
-	c.recordEventInfof(canary, "Promotion completed! Canary analysis was skipped for %s.%s",
-		canary.Spec.TargetRef.Name, canary.Namespace)
-	c.alert(canary, "Canary analysis was skipped, promotion finished.",
+	c.recordEventInfof(canary, "Promotion completed! %s for %s.%s",
+		reason, canary.Spec.TargetRef.Name, canary.Namespace)
+	c.alert(canary, fmt.Sprintf("%s, promotion finished.", reason),
 		false, flaggerv1.SeverityInfo)
+	c.trackRelease(canary, releases.PhasePromoted)
 
 	return true
 }
@@ -581,7 +1100,8 @@ func (c *Controller) shouldAdvance(canary *flaggerv1.Canary, canaryController ca
 		canary.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
 		canary.Status.Phase == flaggerv1.CanaryPhaseWaiting ||
 		canary.Status.Phase == flaggerv1.CanaryPhasePromoting ||
-		canary.Status.Phase == flaggerv1.CanaryPhaseFinalising {
+		canary.Status.Phase == flaggerv1.CanaryPhaseFinalising ||
+		canary.Status.Phase == flaggerv1.CanaryPhaseRollingBack {
 		return true, nil
 	}
 
@@ -606,7 +1126,8 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 	c.recorder.SetStatus(canary, canary.Status.Phase)
 	if canary.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
 		canary.Status.Phase == flaggerv1.CanaryPhasePromoting ||
-		canary.Status.Phase == flaggerv1.CanaryPhaseFinalising {
+		canary.Status.Phase == flaggerv1.CanaryPhaseFinalising ||
+		canary.Status.Phase == flaggerv1.CanaryPhaseRollingBack {
 		return true
 	}
 
@@ -629,6 +1150,9 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 		c.alert(canaryPhaseProgressing, "New revision detected, starting canary analysis.",
 			true, flaggerv1.SeverityInfo)
 
+		if ok := c.pauseRollout(canary, canaryController, false); !ok {
+			return false
+		}
 		if err := canaryController.ScaleFromZero(canary); err != nil {
 			c.recordEventErrorf(canary, "%v", err)
 			return false
@@ -659,7 +1183,9 @@ func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryCont
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.ConfirmRolloutHook {
 			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			c.recorder.SetGate(canary, webhook.Type, webhook.Name, err == nil)
 			if err != nil {
+				c.recordHalt(canary, haltReasonConfirmRollout, "waiting for rollout approval %s", webhook.Name)
 				if canary.Status.Phase != flaggerv1.CanaryPhaseWaiting {
 					if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseWaiting); err != nil {
 						c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
@@ -684,28 +1210,75 @@ func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryCont
 	return true
 }
 
-func (c *Controller) runConfirmPromotionHooks(canary *flaggerv1.Canary) bool {
-	for _, webhook := range canary.GetAnalysis().Webhooks {
-		if webhook.Type == flaggerv1.ConfirmPromotionHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
-			if err != nil {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for promotion approval %s",
-					canary.Name, canary.Namespace, webhook.Name)
-				c.alert(canary, "Canary promotion is waiting for approval.", false, flaggerv1.SeverityWarn)
-				return false
-			} else {
-				c.recordEventInfof(canary, "Confirm-promotion check %s passed", webhook.Name)
+// runAnalysisWindows returns false if the analysis can't start or advance at this time,
+// outside the windows the canary waits in the same way as for a confirm-rollout gate
+func (c *Controller) runAnalysisWindows(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
+	if canary.Status.Phase != flaggerv1.CanaryPhaseProgressing && canary.Status.Phase != flaggerv1.CanaryPhaseWaiting {
+		return true
+	}
+
+	ok, err := canary.IsWithinAnalysisWindows(time.Now())
+	if err != nil {
+		c.recordEventWarningf(canary, "Invalid analysis window %v", err)
+		return false
+	}
+	if !ok {
+		c.recordHalt(canary, haltReasonWindow, "outside the analysis windows %s", strings.Join(canary.GetAnalysis().Windows, ", "))
+		if canary.Status.Phase != flaggerv1.CanaryPhaseWaiting {
+			if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseWaiting); err != nil {
+				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 			}
+			c.recordEventWarningf(canary, "Halt %s.%s advancement outside the analysis windows", canary.Name, canary.Namespace)
 		}
+		return false
 	}
-	return true
-}
+
+	// the confirm-rollout gates resume the canary they hold
+	if hasConfirmRolloutHooks(canary) {
+		return true
+	}
+	if canary.Status.Phase == flaggerv1.CanaryPhaseWaiting {
+		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseProgressing); err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
+			return false
+		}
+		c.recordEventInfof(canary, "Analysis window open, resuming %s.%s", canary.Name, canary.Namespace)
+		return false
+	}
+	return true
+}
+
+func (c *Controller) runConfirmPromotionHooks(canary *flaggerv1.Canary) bool {
+	for _, webhook := range canary.GetAnalysis().Webhooks {
+		if webhook.Type == flaggerv1.ConfirmPromotionHook {
+			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			c.recorder.SetGate(canary, webhook.Type, webhook.Name, err == nil)
+			if err != nil {
+				c.recordHalt(canary, haltReasonConfirmPromotion, "waiting for promotion approval %s", webhook.Name)
+				c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for promotion approval %s",
+					canary.Name, canary.Namespace, webhook.Name)
+				c.alert(canary, "Canary promotion is waiting for approval.", false, flaggerv1.SeverityWarn)
+				return false
+			} else {
+				c.recordEventInfof(canary, "Confirm-promotion check %s passed", webhook.Name)
+			}
+		}
+	}
+	return true
+}
 
 func (c *Controller) runPreRolloutHooks(canary *flaggerv1.Canary) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.PreRolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			var err error
+			if webhook.Job != nil {
+				_, err = c.runHookJob(canary, webhook)
+			} else {
+				err = CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			}
 			if err != nil {
+				c.recorder.IncWebhookFailures(canary, webhook.Type, webhook.Name)
+				c.recordHalt(canary, haltReasonPreRollout, "pre-rollout check %s failed %v", webhook.Name, err)
 				c.recordEventWarningf(canary, "Halt %s.%s advancement pre-rollout check %s failed %v",
 					canary.Name, canary.Namespace, webhook.Name, err)
 				return false
@@ -720,8 +1293,19 @@ func (c *Controller) runPreRolloutHooks(canary *flaggerv1.Canary) bool {
 func (c *Controller) runPostRolloutHooks(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.PostRolloutHook {
+			// the post-rollout jobs are not awaited, their artifacts are saved when they finish
+			if webhook.Job != nil {
+				if _, err := c.runHookJob(canary, webhook); err != nil {
+					c.recorder.IncWebhookFailures(canary, webhook.Type, webhook.Name)
+					c.recordEventWarningf(canary, "Post-rollout hook %s failed %v", webhook.Name, err)
+					return false
+				}
+				c.recordEventInfof(canary, "Post-rollout job %s started", hookJobName(canary, webhook))
+				continue
+			}
 			err := CallWebhook(canary.Name, canary.Namespace, phase, webhook)
 			if err != nil {
+				c.recorder.IncWebhookFailures(canary, webhook.Type, webhook.Name)
 				c.recordEventWarningf(canary, "Post-rollout hook %s failed %v", webhook.Name, err)
 				return false
 			} else {
@@ -753,6 +1337,8 @@ func (c *Controller) runAnalysis(canary *flaggerv1.Canary) bool {
 		if webhook.Type == "" || webhook.Type == flaggerv1.RolloutHook {
 			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
+				c.recorder.IncWebhookFailures(canary, flaggerv1.RolloutHook, webhook.Name)
+				c.recordHalt(canary, haltReasonWebhook, "external check %s failed %v", webhook.Name, err)
 				c.recordEventWarningf(canary, "Halt %s.%s advancement external check %s failed %v",
 					canary.Name, canary.Namespace, webhook.Name, err)
 				return false
@@ -760,20 +1346,32 @@ func (c *Controller) runAnalysis(canary *flaggerv1.Canary) bool {
 		}
 	}
 
-	ok := c.runBuiltinMetricChecks(canary)
-	if !ok {
-		return ok
+	groups, err := newMetricGroups(canary)
+	if err != nil {
+		c.recordHalt(canary, haltReasonMetrics, "%v", err)
+		c.recordEventErrorf(canary, "Halt %s.%s advancement %v", canary.Name, canary.Namespace, err)
+		return false
 	}
 
-	ok = c.runMetricChecks(canary)
+	ok := c.runBuiltinMetricChecks(canary, groups) &&
+		c.runJobMetricChecks(canary, groups) &&
+		c.runMetricChecks(canary, groups)
 	if !ok {
+		c.recordHalt(canary, haltReasonMetrics, "metric checks failed")
 		return ok
 	}
 
+	if err := groups.check(); err != nil {
+		c.recordHalt(canary, haltReasonMetrics, "%v", err)
+		c.recordEventWarningf(canary, "Halt %s.%s advancement %v", canary.Name, canary.Namespace, err)
+		return false
+	}
+
 	return true
 }
 
-func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
+// builtinMetricsProvider returns the observer used for the builtin metric checks of the canary
+func (c *Controller) builtinMetricsProvider(canary *flaggerv1.Canary) string {
 	// override the global provider if one is specified in the canary spec
 	var metricsProvider string
 	// set the metrics provider to Crossover Prometheus when Crossover is the mesh provider
@@ -797,18 +1395,35 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 		metricsProvider = metricsProvider + MetricsProviderServiceSuffix
 	}
 
+	return metricsProvider
+}
+
+func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary, groups *metricGroups) bool {
+	metricsProvider := c.builtinMetricsProvider(canary)
+
 	// create observer based on the mesh provider
 	observerFactory := c.observerFactory
 
 	// override the global metrics server if one is specified in the canary spec
 	if canary.Spec.MetricsServer != "" {
 		var err error
-		observerFactory, err = observers.NewFactory(canary.Spec.MetricsServer)
+		// the controller token is never sent to a metrics server defined in the canary spec
+		observerFactory, err = observers.NewFactory(canary.Spec.MetricsServer, "")
 		if err != nil {
 			c.recordEventErrorf(canary, "Error building Prometheus client for %s %v", canary.Spec.MetricsServer, err)
 			return false
 		}
 	}
+
+	// query the canary cluster metrics server for a multi-cluster blue/green
+	if canary.Spec.Cluster != nil && canary.Spec.Cluster.MetricsServer != "" {
+		var err error
+		observerFactory, err = observers.NewFactory(canary.Spec.Cluster.MetricsServer, "")
+		if err != nil {
+			c.recordEventErrorf(canary, "Error building Prometheus client for %s %v", canary.Spec.Cluster.MetricsServer, err)
+			return false
+		}
+	}
 	observer := observerFactory.Observer(metricsProvider)
 
 	// run metrics checks
@@ -818,8 +1433,9 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 		}
 
 		if metric.Name == "request-success-rate" {
-			val, err := observer.GetRequestSuccessRate(toMetricModel(canary, metric.Interval))
+			val, err := observer.GetRequestSuccessRate(toMetricModel(canary, metric))
 			if err != nil {
+				c.recordMetricError(canary, metric.Name, err)
 				if strings.Contains(err.Error(), "no values found") {
 					c.recordEventWarningf(canary, "Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic",
 						metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace)
@@ -828,20 +1444,22 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
+			c.recordMetricValue(canary, metric.Name, val, isWithinThreshold(metric, val, true))
+			c.recordPrimaryValues(canary, observer, metric)
 
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < *tr.Min {
+				if tr.Min != nil && val < *tr.Min && groups.halt(metric) {
 					c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% < %v%%",
 						canary.Name, canary.Namespace, val, *tr.Min)
 					return false
 				}
-				if tr.Max != nil && val > *tr.Max {
+				if tr.Max != nil && val > *tr.Max && groups.halt(metric) {
 					c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% > %v%%",
 						canary.Name, canary.Namespace, val, *tr.Max)
 					return false
 				}
-			} else if metric.Threshold > val {
+			} else if metric.Threshold > val && groups.halt(metric) {
 				c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% < %v%%",
 					canary.Name, canary.Namespace, val, metric.Threshold)
 				return false
@@ -849,8 +1467,9 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 		}
 
 		if metric.Name == "request-duration" {
-			val, err := observer.GetRequestDuration(toMetricModel(canary, metric.Interval))
+			val, err := observer.GetRequestDuration(toMetricModel(canary, metric))
 			if err != nil {
+				c.recordMetricError(canary, metric.Name, err)
 				if strings.Contains(err.Error(), "no values found") {
 					c.recordEventWarningf(canary, "Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic",
 						metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace)
@@ -859,29 +1478,67 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
+			ms := float64(val) / float64(time.Millisecond)
+			c.recordMetricValue(canary, metric.Name, ms, isWithinThreshold(metric, ms, false))
+			c.recordPrimaryValues(canary, observer, metric)
+
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < time.Duration(*tr.Min)*time.Millisecond {
+				if tr.Min != nil && val < time.Duration(*tr.Min)*time.Millisecond && groups.halt(metric) {
 					c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v < %v",
 						canary.Name, canary.Namespace, val, time.Duration(*tr.Min)*time.Millisecond)
 					return false
 				}
-				if tr.Max != nil && val > time.Duration(*tr.Max)*time.Millisecond {
+				if tr.Max != nil && val > time.Duration(*tr.Max)*time.Millisecond && groups.halt(metric) {
 					c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v > %v",
 						canary.Name, canary.Namespace, val, time.Duration(*tr.Max)*time.Millisecond)
 					return false
 				}
-			} else if val > time.Duration(metric.Threshold)*time.Millisecond {
+			} else if val > time.Duration(metric.Threshold)*time.Millisecond && groups.halt(metric) {
 				c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v > %v",
 					canary.Name, canary.Namespace, val, time.Duration(metric.Threshold)*time.Millisecond)
 				return false
 			}
 		}
 
+		if metric.Name == "datagram-error-rate" {
+			val, err := observerFactory.DatagramObserver().GetDatagramErrorRate(toMetricModel(canary, metric))
+			if err != nil {
+				c.recordMetricError(canary, metric.Name, err)
+				if strings.Contains(err.Error(), "no values found") {
+					c.recordEventWarningf(canary, "Halt advancement no values found for metric %s probably %s.%s is not receiving traffic",
+						metric.Name, canary.Spec.TargetRef.Name, canary.Namespace)
+				} else {
+					c.recordEventErrorf(canary, "Prometheus query failed: %v", err)
+				}
+				return false
+			}
+			c.recordMetricValue(canary, metric.Name, val, isWithinThreshold(metric, val, false))
+
+			if metric.ThresholdRange != nil {
+				tr := *metric.ThresholdRange
+				if tr.Min != nil && val < *tr.Min && groups.halt(metric) {
+					c.recordEventWarningf(canary, "Halt %s.%s advancement datagram error rate %.2f%% < %v%%",
+						canary.Name, canary.Namespace, val, *tr.Min)
+					return false
+				}
+				if tr.Max != nil && val > *tr.Max && groups.halt(metric) {
+					c.recordEventWarningf(canary, "Halt %s.%s advancement datagram error rate %.2f%% > %v%%",
+						canary.Name, canary.Namespace, val, *tr.Max)
+					return false
+				}
+			} else if val > metric.Threshold && groups.halt(metric) {
+				c.recordEventWarningf(canary, "Halt %s.%s advancement datagram error rate %.2f%% > %v%%",
+					canary.Name, canary.Namespace, val, metric.Threshold)
+				return false
+			}
+		}
+
 		// in-line PromQL
 		if metric.Query != "" {
 			val, err := observerFactory.Client.RunQuery(metric.Query)
 			if err != nil {
+				c.recordMetricError(canary, metric.Name, err)
 				if strings.Contains(err.Error(), "no values found") {
 					c.recordEventWarningf(canary, "Halt advancement no values found for metric: %s",
 						metric.Name)
@@ -890,19 +1547,21 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
+			c.recordMetricValue(canary, metric.Name, val, isWithinThreshold(metric, val, false))
+
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < *tr.Min {
+				if tr.Min != nil && val < *tr.Min && groups.halt(metric) {
 					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
 						canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
 					return false
 				}
-				if tr.Max != nil && val > *tr.Max {
+				if tr.Max != nil && val > *tr.Max && groups.halt(metric) {
 					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
 						canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
 					return false
 				}
-			} else if val > metric.Threshold {
+			} else if val > metric.Threshold && groups.halt(metric) {
 				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
 					canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
 				return false
@@ -913,81 +1572,377 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 	return true
 }
 
-func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
+func (c *Controller) runMetricChecks(canary *flaggerv1.Canary, groups *metricGroups) bool {
 	for _, metric := range canary.GetAnalysis().Metrics {
-		if metric.TemplateRef != nil {
-			namespace := canary.Namespace
-			if metric.TemplateRef.Namespace != "" {
-				namespace = metric.TemplateRef.Namespace
-			}
+		var val float64
+		var err error
+		var comparison *judge.Result
+		var slo *sloResult
+		switch {
+		case metric.Comparison != nil:
+			comparison, err = c.runComparisonMetric(canary, metric)
+		case metric.SLO != nil:
+			slo, err = c.runSLOMetric(canary, metric)
+		case metric.Baseline != nil:
+			val, err = c.runBaselineMetric(canary, metric)
+		case metric.TemplateRef != nil:
+			val, err = c.runMetricTemplateQuery(canary, metric, *metric.TemplateRef, time.Time{})
+		case metric.Expression != "":
+			val, err = c.runCompositeMetric(canary, metric)
+		case metric.Ratio != nil:
+			val, err = c.runRatioMetric(canary, metric)
+		default:
+			continue
+		}
 
-			template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(metric.TemplateRef.Name)
-			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
+		if err != nil {
+			if _, ok := err.(*metricTemplateError); ok {
+				c.recordEventErrorf(canary, "%v", err)
 				return false
 			}
-
-			var credentials map[string][]byte
-			if template.Spec.Provider.SecretRef != nil {
-				secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
-				if err != nil {
-					c.recordEventErrorf(canary, "Metric template %s.%s secret %s error: %v",
-						metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
-					return false
-				}
-				credentials = secret.Data
+			if _, ok := err.(*lowDenominatorError); ok {
+				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %v",
+					canary.Name, canary.Namespace, metric.Name, err)
+				return false
 			}
-
-			factory := providers.Factory{}
-			provider, err := factory.Provider(metric.Interval, template.Spec.Provider, credentials)
-			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s provider %s error: %v",
-					metric.TemplateRef.Name, namespace, template.Spec.Provider.Type, err)
+			if _, ok := err.(*lowBaselineError); ok {
+				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %v",
+					canary.Name, canary.Namespace, metric.Name, err)
+				return false
+			}
+			if _, ok := err.(*lowDataPointsError); ok {
+				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %v",
+					canary.Name, canary.Namespace, metric.Name, err)
 				return false
 			}
+			c.recordMetricError(canary, metric.Name, err)
+			if strings.Contains(err.Error(), "no values found") {
+				c.recordEventWarningf(canary, "Halt advancement no values found for custom metric: %s",
+					metric.Name)
+			} else {
+				c.recordEventErrorf(canary, "Metric query failed for %s: %v", metric.Name, err)
+			}
+			return false
+		}
 
-			query, err := observers.RenderQuery(template.Spec.Query, toMetricModel(canary, metric.Interval))
-			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s query render error: %v",
-					metric.TemplateRef.Name, namespace, err)
+		// the comparison metrics are judged by the significance test instead of the thresholds
+		if comparison != nil {
+			c.recordMetricValue(canary, metric.Name, comparison.CanaryMedian, !comparison.Significant)
+			c.timeSeries.setPrimary(canary, metric.Name, comparison.BaselineMedian)
+			if comparison.Significant && groups.halt(metric) {
+				c.recordEventWarningf(canary, "Halt %s.%s advancement %s median %.2f baseline %.2f significant change p-value %.4f",
+					canary.Name, canary.Namespace, metric.Name, comparison.CanaryMedian, comparison.BaselineMedian, comparison.PValue)
 				return false
 			}
+			continue
+		}
 
-			val, err := provider.RunQuery(query)
-			if err != nil {
-				if strings.Contains(err.Error(), "no values found") {
-					c.recordEventWarningf(canary, "Halt advancement no values found for custom metric: %s",
-						metric.Name)
-				} else {
-					c.recordEventErrorf(canary, "Metric query failed for %s: %v", metric.Name, err)
-				}
+		// the SLO metrics are judged by the burn rates of the error budget instead of the thresholds
+		if slo != nil {
+			maxFast, maxSlow := maxSLOBurnRates(metric.SLO)
+			c.recordMetricValue(canary, metric.Name, slo.fastBurnRate,
+				slo.fastBurnRate <= maxFast && slo.slowBurnRate <= maxSlow)
+			if slo.fastBurnRate > maxFast && groups.halt(metric) {
+				c.recordEventWarningf(canary, "Halt %s.%s advancement %s fast burn rate %.2f > %v",
+					canary.Name, canary.Namespace, metric.Name, slo.fastBurnRate, maxFast)
 				return false
 			}
+			if slo.slowBurnRate > maxSlow && groups.halt(metric) {
+				c.recordEventWarningf(canary, "Halt %s.%s advancement %s slow burn rate %.2f > %v",
+					canary.Name, canary.Namespace, metric.Name, slo.slowBurnRate, maxSlow)
+				return false
+			}
+			continue
+		}
 
-			if metric.ThresholdRange != nil {
-				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
-					return false
-				}
-				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
-					return false
-				}
-			} else if val > metric.Threshold {
+		c.recordMetricValue(canary, metric.Name, val, isWithinThreshold(metric, val, false))
+
+		if metric.ThresholdRange != nil {
+			tr := *metric.ThresholdRange
+			if tr.Min != nil && val < *tr.Min && groups.halt(metric) {
+				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
+					canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
+				return false
+			}
+			if tr.Max != nil && val > *tr.Max && groups.halt(metric) {
 				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-					canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
+					canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
 				return false
 			}
+		} else if val > metric.Threshold && groups.halt(metric) {
+			c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
+				canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
+			return false
 		}
 	}
 
 	return true
 }
 
-func toMetricModel(r *flaggerv1.Canary, interval string) flaggerv1.MetricTemplateModel {
+// metricTemplateError is returned when a metric template can't be queried
+// due to its configuration, as opposed to a failed query
+type metricTemplateError struct {
+	msg string
+}
+
+func (e *metricTemplateError) Error() string {
+	return e.msg
+}
+
+func newMetricTemplateError(format string, a ...interface{}) error {
+	return &metricTemplateError{msg: fmt.Sprintf(format, a...)}
+}
+
+// lowDenominatorError is returned when the denominator of a ratio metric
+// is too low for the ratio to be meaningful
+type lowDenominatorError struct {
+	value float64
+	min   float64
+}
+
+func (e *lowDenominatorError) Error() string {
+	if e.value <= 0 {
+		return fmt.Sprintf("denominator is %v", e.value)
+	}
+	return fmt.Sprintf("denominator %.2f is below the minimum %v", e.value, e.min)
+}
+
+// lowBaselineError is returned when the baseline of a time shifted metric
+// is too low for the comparison to be meaningful
+type lowBaselineError struct {
+	value  float64
+	min    float64
+	offset string
+}
+
+func (e *lowBaselineError) Error() string {
+	if e.value <= 0 {
+		return fmt.Sprintf("baseline at %s is %v", e.offset, e.value)
+	}
+	return fmt.Sprintf("baseline %.2f at %s is below the minimum %v", e.value, e.offset, e.min)
+}
+
+// runMetricTemplateQuery renders the query of the referenced metric template and runs it against its provider,
+// the query is evaluated at the given time unless the time is zero
+func (c *Controller) runMetricTemplateQuery(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	ref flaggerv1.CrossNamespaceObjectReference, at time.Time) (float64, error) {
+	q, err := c.newMetricTemplateQuery(canary, metric, ref, toMetricModel(canary, metric))
+	if err != nil {
+		return 0, err
+	}
+
+	var val float64
+	switch {
+	case metric.Range != nil:
+		val, err = runRangeQuery(q.provider, q.query, metric)
+	case !at.IsZero():
+		val, err = providers.RunQueryAt(q.provider, q.query, at)
+	default:
+		val, err = q.provider.RunQuery(q.query)
+	}
+	c.recordQueryBudget(canary, q)
+	return val, err
+}
+
+// metricTemplateQuery is a rendered metric template query bound to the template provider
+type metricTemplateQuery struct {
+	query     string
+	provider  providers.Interface
+	budget    *providers.Budget
+	budgetKey string
+}
+
+// newMetricTemplateQuery renders the query of the referenced metric template with the model
+// and creates the provider of the template
+func (c *Controller) newMetricTemplateQuery(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	ref flaggerv1.CrossNamespaceObjectReference, model flaggerv1.MetricTemplateModel) (*metricTemplateQuery, error) {
+	namespace := canary.Namespace
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+
+	template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(ref.Name)
+	if err != nil {
+		return nil, newMetricTemplateError("Metric template %s.%s error: %v", ref.Name, namespace, err)
+	}
+
+	credentials, err := c.getProviderCredentials(template)
+	if err != nil {
+		return nil, newMetricTemplateError("Metric template %s.%s %v", ref.Name, namespace, err)
+	}
+
+	// the retries of a query take at most half of the analysis interval
+	factory := providers.Factory{MaxRetryWait: canary.GetAnalysisInterval() / 2}
+	if template.Spec.Provider.Budget != nil {
+		factory.Budget = c.queryBudgets.get(canary, template)
+	}
+	provider, err := factory.Provider(metric.Interval, template.Spec.Provider, credentials)
+	if err != nil {
+		return nil, newMetricTemplateError("Metric template %s.%s provider %s error: %v",
+			ref.Name, namespace, template.Spec.Provider.Type, err)
+	}
+
+	queryTemplate := template.Spec.Query
+	if template.Spec.SLI != nil {
+		queryTemplate, err = observers.SLIQuery(template.Spec.Provider.Type, *template.Spec.SLI)
+		if err != nil {
+			return nil, newMetricTemplateError("Metric template %s.%s %v", ref.Name, namespace, err)
+		}
+	}
+
+	query, err := observers.RenderQuery(queryTemplate, model)
+	if err != nil {
+		return nil, newMetricTemplateError("Metric template %s.%s query render error: %v",
+			ref.Name, namespace, err)
+	}
+
+	return &metricTemplateQuery{
+		query:     query,
+		provider:  provider,
+		budget:    factory.Budget,
+		budgetKey: providerBudgetKey(template),
+	}, nil
+}
+
+// recordQueryBudget reports the queries accounted against the budget of the metric template provider
+func (c *Controller) recordQueryBudget(canary *flaggerv1.Canary, q *metricTemplateQuery) {
+	if q.budget != nil {
+		c.recorder.SetProviderBudget(canary, q.budgetKey, q.budget.Queries(), q.budget.Cost())
+	}
+}
+
+// runCompositeMetric queries every metric template of the metric and evaluates the expression over the results
+func (c *Controller) runCompositeMetric(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric) (float64, error) {
+	expr, err := expression.Parse(metric.Expression)
+	if err != nil {
+		return 0, newMetricTemplateError("Metric %s expression error: %v", metric.Name, err)
+	}
+
+	names := make(map[string]bool, len(metric.Templates))
+	for _, t := range metric.Templates {
+		names[t.Name] = true
+	}
+	for _, v := range expr.Variables() {
+		if !names[v] {
+			return 0, newMetricTemplateError("Metric %s expression error: variable %s has no template", metric.Name, v)
+		}
+	}
+
+	vars := make(map[string]float64, len(metric.Templates))
+	for _, t := range metric.Templates {
+		val, err := c.runMetricTemplateQuery(canary, metric, t.TemplateRef, time.Time{})
+		if err != nil {
+			if _, ok := err.(*metricTemplateError); ok {
+				return 0, err
+			}
+			return 0, fmt.Errorf("%s: %v", t.Name, err)
+		}
+		vars[t.Name] = val
+	}
+
+	return expr.Evaluate(vars)
+}
+
+// runRatioMetric queries the numerator and denominator templates at the same point in time
+// and divides the results, a denominator below the minimum halts the analysis
+func (c *Controller) runRatioMetric(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric) (float64, error) {
+	at := time.Now()
+
+	denominator, err := c.runMetricTemplateQuery(canary, metric, metric.Ratio.Denominator, at)
+	if err != nil {
+		if _, ok := err.(*metricTemplateError); ok {
+			return 0, err
+		}
+		return 0, fmt.Errorf("denominator: %v", err)
+	}
+	if denominator <= 0 || denominator < metric.Ratio.MinDenominator {
+		return 0, &lowDenominatorError{value: denominator, min: metric.Ratio.MinDenominator}
+	}
+
+	numerator, err := c.runMetricTemplateQuery(canary, metric, metric.Ratio.Numerator, at)
+	if err != nil {
+		if _, ok := err.(*metricTemplateError); ok {
+			return 0, err
+		}
+		return 0, fmt.Errorf("numerator: %v", err)
+	}
+
+	return numerator / denominator, nil
+}
+
+// runBaselineMetric queries the metric template now and at the baseline offset,
+// the metric value is the current result as a percentage of the baseline result
+func (c *Controller) runBaselineMetric(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric) (float64, error) {
+	if metric.TemplateRef == nil || metric.Range != nil {
+		return 0, newMetricTemplateError("Metric %s baseline requires a templateRef without range", metric.Name)
+	}
+	offset, err := parseBaselineOffset(metric.Baseline.Offset)
+	if err != nil {
+		return 0, newMetricTemplateError("Metric %s baseline %v", metric.Name, err)
+	}
+
+	now := time.Now()
+	baseline, err := c.runMetricTemplateQuery(canary, metric, *metric.TemplateRef, now.Add(offset))
+	if err != nil {
+		if _, ok := err.(*metricTemplateError); ok {
+			return 0, err
+		}
+		return 0, fmt.Errorf("baseline: %v", err)
+	}
+	if baseline <= 0 || baseline < metric.Baseline.MinValue {
+		return 0, &lowBaselineError{value: baseline, min: metric.Baseline.MinValue, offset: metric.Baseline.Offset}
+	}
+
+	val, err := c.runMetricTemplateQuery(canary, metric, *metric.TemplateRef, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return val / baseline * 100, nil
+}
+
+// parseBaselineOffset parses a negative duration with support for days e.g. -7d or -168h,
+// a positive offset is shifted in the past
+func parseBaselineOffset(offset string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if strings.HasSuffix(offset, "d") {
+		var days int
+		days, err = strconv.Atoi(strings.TrimSuffix(offset, "d"))
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(offset)
+	}
+	if err != nil || d == 0 {
+		return 0, fmt.Errorf("offset %q is invalid", offset)
+	}
+	if d > 0 {
+		d = -d
+	}
+	return d, nil
+}
+
+// runRangeQuery fetches the datapoints of the metric interval and aggregates them
+func runRangeQuery(provider providers.Interface, query string, metric flaggerv1.CanaryMetric) (float64, error) {
+	interval, err := time.ParseDuration(metric.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing interval: %s", err.Error())
+	}
+
+	step, err := providers.RangeStep(interval, metric.Range.Step)
+	if err != nil {
+		return 0, err
+	}
+
+	points, err := providers.RunRangeQuery(provider, query, interval, step)
+	if err != nil {
+		return 0, err
+	}
+
+	return providers.Aggregate(points, metric.Range.Aggregation)
+}
+
+func toMetricModel(r *flaggerv1.Canary, metric flaggerv1.CanaryMetric) flaggerv1.MetricTemplateModel {
 	service := r.Spec.TargetRef.Name
 	if r.Spec.Service.Name != "" {
 		service = r.Spec.Service.Name
@@ -996,22 +1951,119 @@ func toMetricModel(r *flaggerv1.Canary, interval string) flaggerv1.MetricTemplat
 	if r.Spec.IngressRef != nil {
 		ingress = r.Spec.IngressRef.Name
 	}
+	cluster := ""
+	if r.Spec.Cluster != nil {
+		cluster = r.Spec.Cluster.Name
+	}
 	return flaggerv1.MetricTemplateModel{
-		Name:      r.Name,
-		Namespace: r.Namespace,
-		Target:    r.Spec.TargetRef.Name,
-		Service:   service,
-		Ingress:   ingress,
-		Interval:  interval,
+		Name:            r.Name,
+		Namespace:       r.Namespace,
+		Target:          r.Spec.TargetRef.Name,
+		Service:         service,
+		Ingress:         ingress,
+		Interval:        metric.Interval,
+		Route:           metricRoute(r, metric),
+		RouteLabel:      metric.RouteLabel,
+		Cluster:         cluster,
+		CanaryWorkload:  r.Spec.TargetRef.Name,
+		PrimaryWorkload: fmt.Sprintf("%s-primary", r.Spec.TargetRef.Name),
+	}
+}
+
+// isIncreasing returns true if the step weights are strictly increasing values between 1 and 100
+func isIncreasing(weights []int) bool {
+	prev := 0
+	for _, w := range weights {
+		if w <= prev || w > 100 {
+			return false
+		}
+		prev = w
 	}
+	return true
+}
+
+// appendGRPCMatch adds the gRPC match conditions to the A/B testing conditions,
+// the conditions already present are skipped since the canary can be written back with the merged spec
+func appendGRPCMatch(cd *flaggerv1.Canary) {
+	for _, m := range cd.GetGRPCMatch() {
+		found := false
+		for _, existing := range cd.GetAnalysis().Match {
+			if cmp.Equal(existing, m) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			cd.GetAnalysis().Match = append(cd.GetAnalysis().Match, m)
+		}
+	}
+}
+
+// metricRoute returns the regex matching the HTTP paths of the metric routes
+// or of the URI match conditions of the canary, empty if the canary matches all paths
+func metricRoute(r *flaggerv1.Canary, metric flaggerv1.CanaryMetric) string {
+	routes := metric.Routes
+	if len(routes) == 0 {
+		var matches []istiov1alpha3.HTTPMatchRequest
+		matches = append(matches, r.Spec.Service.Match...)
+		matches = append(matches, r.GetAnalysis().Match...)
+		for _, m := range matches {
+			if m.Uri != nil {
+				routes = append(routes, *m.Uri)
+			}
+		}
+	}
+
+	var exprs []string
+	for _, route := range routes {
+		switch {
+		case route.Exact != "":
+			exprs = append(exprs, regexp.QuoteMeta(route.Exact))
+		case route.Prefix != "":
+			exprs = append(exprs, regexp.QuoteMeta(route.Prefix)+".*")
+		case route.Suffix != "":
+			exprs = append(exprs, ".*"+regexp.QuoteMeta(route.Suffix))
+		case route.Regex != "":
+			exprs = append(exprs, route.Regex)
+		}
+	}
+	return strings.Join(exprs, "|")
+}
+
+// analysisDeadlineExceeded returns true if the analysis deadline passed since the start of a progressing canary
+func analysisDeadlineExceeded(cd *flaggerv1.Canary) bool {
+	deadline := cd.GetAnalysisDeadline()
+	if deadline == 0 || cd.Status.Phase != flaggerv1.CanaryPhaseProgressing || cd.Status.AnalysisStartTime == nil {
+		return false
+	}
+	return time.Since(cd.Status.AnalysisStartTime.Time) > deadline
 }
 
 func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) {
-	if canary.Status.FailedChecks >= canary.GetAnalysisThreshold() {
+	rollingBack := canary.Status.Phase == flaggerv1.CanaryPhaseRollingBack
+	if !rollingBack && canary.Status.FailedChecks >= canary.GetAnalysisThreshold() {
 		c.recordEventWarningf(canary, "Rolling back %s.%s failed checks threshold reached %v",
 			canary.Name, canary.Namespace, canary.Status.FailedChecks)
-		c.alert(canary, fmt.Sprintf("Failed checks threshold reached %v", canary.Status.FailedChecks),
-			false, flaggerv1.SeverityError)
+		message := fmt.Sprintf("Failed checks threshold reached %v", canary.Status.FailedChecks)
+		if canary.GetAnalysis().FireDrill {
+			message = fmt.Sprintf("Fire drill! %s", message)
+		}
+		if canary.GetAnalysis().DryRun {
+			message = fmt.Sprintf("Dry run! %s", message)
+		}
+		c.alert(canary, message, false, flaggerv1.SeverityError)
+	}
+
+	// the rollback time is measured from the failure detection, kept in the status during a stepped rollback
+	rollbackStart := time.Now()
+	if rollingBack && canary.Status.RollbackStartTime != nil {
+		rollbackStart = canary.Status.RollbackStartTime.Time
+	}
+
+	// step the canary traffic down in the reverse order of the analysis steps
+	if step := canary.GetRollbackStepWeight(); step > 0 && canary.Status.CanaryWeight > step {
+		c.rollbackStep(canary, canaryController, meshRouter, canary.Status.CanaryWeight-step, rollbackStart)
+		return
 	}
 
 	// route all traffic back to primary
@@ -1021,6 +2073,8 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 		c.recordEventWarningf(canary, "%v", err)
 		return
 	}
+	rollbackDuration := time.Since(rollbackStart)
+	c.recordRollbackDuration(canary, rollbackDuration)
 
 	canaryPhaseFailed := canary.DeepCopy()
 	canaryPhaseFailed.Status.Phase = flaggerv1.CanaryPhaseFailed
@@ -1036,11 +2090,131 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 	}
 
 	// mark canary as failed
-	if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseFailed, CanaryWeight: 0}); err != nil {
+	status := flaggerv1.CanaryStatus{
+		Phase:                flaggerv1.CanaryPhaseFailed,
+		CanaryWeight:         0,
+		LastRollbackDuration: &metav1.Duration{Duration: rollbackDuration},
+	}
+	if err := canaryController.SyncStatus(canary, status); err != nil {
 		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 		return
 	}
 
+	// resume the rollout of the queued revision
+	c.pauseRollout(canary, canaryController, false)
+
 	c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseFailed)
 	c.runPostRolloutHooks(canary, flaggerv1.CanaryPhaseFailed)
 }
+
+// rollbackStep decreases the canary traffic weight and keeps the canary in the rolling back phase
+func (c *Controller) rollbackStep(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface,
+	canaryWeight int, rollbackStart time.Time) {
+	primaryWeight := 100 - canaryWeight
+	if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, false); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+	c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
+
+	if canary.Status.Phase != flaggerv1.CanaryPhaseRollingBack {
+		// the rolling back phase keeps the weight of the status
+		cdCopy := canary.DeepCopy()
+		cdCopy.Status.CanaryWeight = canaryWeight
+		cdCopy.Status.RollbackStartTime = &metav1.Time{Time: rollbackStart}
+		if err := canaryController.SetStatusPhase(cdCopy, flaggerv1.CanaryPhaseRollingBack); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+			return
+		}
+		c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseRollingBack)
+	} else if err := canaryController.SetStatusWeight(canary, canaryWeight); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+
+	c.recordEventWarningf(canary, "Rolling back %s.%s canary weight %v", canary.Name, canary.Namespace, canaryWeight)
+}
+
+// recordRollbackDuration reports the time it took to route all traffic back to primary,
+// the rollbacks slower than the max rollback time trip an alert
+func (c *Controller) recordRollbackDuration(canary *flaggerv1.Canary, duration time.Duration) {
+	c.recorder.SetRollbackDuration(canary, duration)
+
+	maxRollbackTime := canary.GetMaxRollbackTime()
+	if maxRollbackTime == 0 || duration <= maxRollbackTime {
+		return
+	}
+
+	c.recorder.IncRollbackTimeExceeded(canary)
+	message := fmt.Sprintf("Rollback took %v, exceeding the max rollback time %v",
+		duration.Round(time.Second), maxRollbackTime)
+	c.recordEventWarningf(canary, "%s", message)
+	c.alert(canary, message, false, flaggerv1.SeverityError)
+}
+
+// hold routes the hold weight to the canary and ends the analysis without promoting the canary
+func (c *Controller) hold(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface, canaryWeight int) {
+	// size the canary for the held share of the traffic
+	if ok := c.scaleCanaryToWeight(canary, canaryController, canaryWeight); !ok {
+		return
+	}
+
+	primaryWeight := 100 - canaryWeight
+	if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, false); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+	c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
+
+	// the holding phase keeps the weight of the status
+	cdCopy := canary.DeepCopy()
+	cdCopy.Status.CanaryWeight = canaryWeight
+	if err := canaryController.SetStatusPhase(cdCopy, flaggerv1.CanaryPhaseHolding); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+
+	c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseHolding)
+	c.recordEventInfof(canary, "Canary analysis completed! Holding %s.%s canary weight %v",
+		canary.Name, canary.Namespace, canaryWeight)
+	c.alert(canary, fmt.Sprintf("Canary analysis completed successfully, holding %v%% of the traffic on canary.", canaryWeight),
+		false, flaggerv1.SeverityInfo)
+}
+
+// checkHold promotes the held canary once the hold weight is removed from the analysis,
+// otherwise it keeps the routes and the status in sync with the hold weight
+func (c *Controller) checkHold(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) {
+	holdWeight := canary.GetAnalysis().HoldWeight
+	if holdWeight < 1 {
+		c.recordEventInfof(canary, "Hold weight removed! Copying %s.%s template spec to %s-primary.%s",
+			canary.Spec.TargetRef.Name, canary.Namespace, canary.Spec.TargetRef.Name, canary.Namespace)
+		if err := canaryController.Promote(canary); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+			return
+		}
+		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhasePromoting); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+		}
+		return
+	}
+
+	_, canaryWeight, _, err := meshRouter.GetRoutes(canary)
+	if err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+	if canaryWeight == holdWeight && canary.Status.CanaryWeight == holdWeight {
+		return
+	}
+
+	if err := meshRouter.SetRoutes(canary, 100-holdWeight, holdWeight, false); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+	if err := canaryController.SetStatusWeight(canary, holdWeight); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+	c.recorder.SetWeight(canary, 100-holdWeight, holdWeight)
+	c.recordEventInfof(canary, "Holding %s.%s canary weight %v", canary.Name, canary.Namespace, holdWeight)
+}
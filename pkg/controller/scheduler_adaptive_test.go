@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"math"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_AdaptiveSteps(t *testing.T) {
+	// the fake metrics server returns 100, the custom metric has a 75% headroom
+	mocks := newAdaptiveStepsFixture(t, 400)
+
+	for _, expected := range []int{25, 40, 50} {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+		assertAdaptiveStepsTestWeight(t, mocks, expected)
+	}
+}
+
+func TestScheduler_AdaptiveStepsHold(t *testing.T) {
+	// the custom metric has a 5% headroom
+	mocks := newAdaptiveStepsFixture(t, 105)
+
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertAdaptiveStepsTestWeight(t, mocks, 10)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertAdaptiveStepsTestWeight(t, mocks, 10)
+}
+
+func TestMetricHeadroom(t *testing.T) {
+	tests := []struct {
+		name     string
+		metric   flaggerv1.CanaryMetric
+		value    float64
+		headroom float64
+	}{
+		{
+			name:     "success rate error budget",
+			metric:   flaggerv1.CanaryMetric{Name: "request-success-rate", Threshold: 99},
+			value:    99.5,
+			headroom: 0.5,
+		},
+		{
+			name:     "max threshold",
+			metric:   flaggerv1.CanaryMetric{Name: "latency", Threshold: 500},
+			value:    125,
+			headroom: 0.75,
+		},
+		{
+			name:     "range",
+			metric:   flaggerv1.CanaryMetric{Name: "cpu", ThresholdRange: &flaggerv1.CanaryThresholdRange{Min: toFloatPtr(0), Max: toFloatPtr(100)}},
+			value:    25,
+			headroom: 0.5,
+		},
+		{
+			name:     "min range",
+			metric:   flaggerv1.CanaryMetric{Name: "throughput", ThresholdRange: &flaggerv1.CanaryThresholdRange{Min: toFloatPtr(200)}},
+			value:    300,
+			headroom: 0.5,
+		},
+		{
+			name:     "threshold exceeded",
+			metric:   flaggerv1.CanaryMetric{Name: "latency", Threshold: 500},
+			value:    600,
+			headroom: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if h := metricHeadroom(tt.metric, tt.value); math.Abs(h-tt.headroom) > 0.001 {
+				t.Errorf("Got headroom %v wanted %v", h, tt.headroom)
+			}
+		})
+	}
+}
+
+// newAdaptiveStepsFixture starts the analysis of a canary with adaptive steps up to 20%
+// and advances it to the first step weight
+func newAdaptiveStepsFixture(t *testing.T, threshold float64) fixture {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.AdaptiveSteps = &flaggerv1.CanaryAdaptiveSteps{MaxStepWeight: 20}
+	cd.Spec.CanaryAnalysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name:      "request-success-rate",
+			Threshold: 99,
+			Interval:  "1m",
+		},
+		{
+			Name:      "custom",
+			Threshold: threshold,
+			Interval:  "1m",
+			TemplateRef: &flaggerv1.CrossNamespaceObjectReference{
+				Name:      "envoy",
+				Namespace: "default",
+			},
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes and advance to the first step
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertAdaptiveStepsTestWeight(t, mocks, 10)
+	return mocks
+}
+
+func assertAdaptiveStepsTestWeight(t *testing.T, mocks fixture, weight int) {
+	t.Helper()
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing || c.Status.CanaryWeight != weight {
+		t.Fatalf("Got canary phase %v weight %v wanted %v %v", c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseProgressing, weight)
+	}
+}
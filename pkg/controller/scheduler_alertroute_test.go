@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newAlertRouteTest() *flaggerv1.AlertRoute {
+	return &flaggerv1.AlertRoute{
+		TypeMeta:   metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "teams"},
+		Spec: flaggerv1.AlertRouteSpec{
+			Routes: []flaggerv1.AlertRouteRule{
+				{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"team": "payments"},
+					},
+					Alerts: []flaggerv1.CanaryAlert{
+						{
+							Name:        "payments-slack",
+							Severity:    flaggerv1.SeverityInfo,
+							ProviderRef: flaggerv1.CrossNamespaceObjectReference{Name: "payments-slack", Namespace: "flagger"},
+						},
+						{
+							Name:        "payments-pager",
+							Severity:    flaggerv1.SeverityError,
+							ProviderRef: flaggerv1.CrossNamespaceObjectReference{Name: "payments-pager", Namespace: "flagger"},
+						},
+					},
+				},
+				{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"team": "search"},
+					},
+					Alerts: []flaggerv1.CanaryAlert{
+						{
+							Name:        "search-slack",
+							ProviderRef: flaggerv1.CrossNamespaceObjectReference{Name: "search-slack", Namespace: "flagger"},
+						},
+					},
+				},
+				{
+					// an empty selector matches all canaries
+					Alerts: []flaggerv1.CanaryAlert{
+						{
+							Name:        "audit",
+							Severity:    flaggerv1.SeverityInfo,
+							ProviderRef: flaggerv1.CrossNamespaceObjectReference{Name: "audit", Namespace: "flagger"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestScheduler_AlertRoutes(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Labels = map[string]string{"team": "payments"}
+	cd.Spec.CanaryAnalysis.Alerts = nil
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.flaggerInformers.RouteInformer.Informer().GetIndexer().Add(newAlertRouteTest())
+
+	alerts := mocks.ctrl.canaryAlerts(mocks.canary)
+	names := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		names = append(names, alert.Name)
+	}
+	if len(names) != 3 || names[0] != "payments-slack" || names[1] != "payments-pager" || names[2] != "audit" {
+		t.Errorf("Got alerts %v wanted [payments-slack payments-pager audit]", names)
+	}
+}
+
+func TestScheduler_AlertRoutesCanaryPrecedence(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Labels = map[string]string{"team": "search"}
+	cd.Spec.CanaryAnalysis.Alerts = []flaggerv1.CanaryAlert{
+		{
+			Name:        "search-errors",
+			Severity:    flaggerv1.SeverityError,
+			ProviderRef: flaggerv1.CrossNamespaceObjectReference{Name: "search-slack", Namespace: "flagger"},
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.flaggerInformers.RouteInformer.Informer().GetIndexer().Add(newAlertRouteTest())
+
+	alerts := mocks.ctrl.canaryAlerts(mocks.canary)
+	if len(alerts) != 2 || alerts[0].Name != "search-errors" || alerts[1].Name != "audit" {
+		t.Errorf("Got alerts %v wanted search-errors and audit", alerts)
+	}
+}
+
+func TestScheduler_AlertRoutesNoMatch(t *testing.T) {
+	route := newAlertRouteTest()
+	route.Spec.Routes = route.Spec.Routes[:2]
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.flaggerInformers.RouteInformer.Informer().GetIndexer().Add(route)
+
+	alerts := mocks.ctrl.canaryAlerts(mocks.canary)
+	if len(alerts) != len(mocks.canary.GetAnalysis().Alerts) {
+		t.Errorf("Got alerts %v wanted the canary alerts", alerts)
+	}
+}
+
+func TestScheduler_AlertRoutesCRDMissing(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.flaggerInformers.RouteInformer = nil
+
+	alerts := mocks.ctrl.canaryAlerts(mocks.canary)
+	if len(alerts) != len(mocks.canary.GetAnalysis().Alerts) {
+		t.Errorf("Got alerts %v wanted the canary alerts", alerts)
+	}
+}
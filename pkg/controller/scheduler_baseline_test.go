@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newBaselineTestCanary(offset string, minValue float64, tr flaggerv1.CanaryThresholdRange) *flaggerv1.Canary {
+	cd := newDeploymentTestCanary()
+	// the envoy template provider is fake and returns 100 for every query
+	cd.Spec.CanaryAnalysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name:        "checkout-rate",
+			TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "envoy"},
+			Baseline: &flaggerv1.CanaryMetricBaseline{
+				Offset:   offset,
+				MinValue: minValue,
+			},
+			ThresholdRange: &tr,
+			Interval:       "1m",
+		},
+	}
+	return cd
+}
+
+func TestScheduler_BaselineMetric(t *testing.T) {
+	min := 90.0
+	mocks := newDeploymentFixture(newBaselineTestCanary("-7d", 10, flaggerv1.CanaryThresholdRange{Min: &min}))
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); !ok {
+		t.Errorf("Expected the baseline metric check to pass")
+	}
+
+	results := mocks.ctrl.GetMetricResults(mocks.canary.Name, mocks.canary.Namespace)
+	if len(results) != 1 || results[0].Value != 100 {
+		t.Errorf("Got results %v wanted checkout-rate 100", results)
+	}
+
+	max := 50.0
+	mocks = newDeploymentFixture(newBaselineTestCanary("-7d", 10, flaggerv1.CanaryThresholdRange{Max: &max}))
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the baseline metric check to fail")
+	}
+}
+
+func TestScheduler_BaselineMetricMinValue(t *testing.T) {
+	min := 90.0
+	mocks := newDeploymentFixture(newBaselineTestCanary("-7d", 1000, flaggerv1.CanaryThresholdRange{Min: &min}))
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the baseline metric check to halt below the minimum value")
+	}
+
+	if results := mocks.ctrl.GetMetricResults(mocks.canary.Name, mocks.canary.Namespace); len(results) != 0 {
+		t.Errorf("Got results %v wanted none", results)
+	}
+}
+
+func TestScheduler_BaselineMetricInvalidOffset(t *testing.T) {
+	min := 90.0
+	mocks := newDeploymentFixture(newBaselineTestCanary("last-week", 0, flaggerv1.CanaryThresholdRange{Min: &min}))
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the baseline metric check to fail for an invalid offset")
+	}
+}
+
+func TestScheduler_ParseBaselineOffset(t *testing.T) {
+	tests := map[string]time.Duration{
+		"-7d":  -7 * 24 * time.Hour,
+		"7d":   -7 * 24 * time.Hour,
+		"-24h": -24 * time.Hour,
+		"1h":   -time.Hour,
+	}
+	for offset, want := range tests {
+		got, err := parseBaselineOffset(offset)
+		if err != nil {
+			t.Fatalf("parseBaselineOffset(%s) failed %v", offset, err)
+		}
+		if got != want {
+			t.Errorf("parseBaselineOffset(%s) got %v wanted %v", offset, got, want)
+		}
+	}
+
+	for _, offset := range []string{"", "0d", "week", "-d"} {
+		if _, err := parseBaselineOffset(offset); err == nil {
+			t.Errorf("parseBaselineOffset(%s) expected error", offset)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_BlueGreenMirrorValidation(t *testing.T) {
+	cd := newDeploymentTestCanaryMirror()
+	cd.Spec.Provider = "istio"
+	cd.Spec.CanaryAnalysis.Iterations = 4
+	cd.Spec.CanaryAnalysis.MirrorIterations = 2
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	tests := []struct {
+		primaryWeight int
+		canaryWeight  int
+		mirrored      bool
+	}{
+		// mirror validation stage
+		{100, 0, true},
+		{100, 0, true},
+		// atomic switch after the mirror iterations
+		{0, 100, false},
+		{0, 100, false},
+		// max iterations reached
+		{0, 100, false},
+	}
+	for i, tt := range tests {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+		primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if primaryWeight != tt.primaryWeight || canaryWeight != tt.canaryWeight || mirrored != tt.mirrored {
+			t.Fatalf("Iteration %v got routes %v/%v mirrored %v wanted %v/%v mirrored %v",
+				i+1, primaryWeight, canaryWeight, mirrored, tt.primaryWeight, tt.canaryWeight, tt.mirrored)
+		}
+	}
+
+	// promote
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhasePromoting {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhasePromoting)
+	}
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+func TestQueryBudgets_SharedProvider(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	newTemplate := func(name string, maxQueries int, secret string) *flaggerv1.MetricTemplate {
+		template := &flaggerv1.MetricTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: flaggerv1.MetricTemplateSpec{
+				Provider: flaggerv1.MetricTemplateProvider{
+					Type:    "datadog",
+					Address: "https://api.datadoghq.com",
+					Budget:  &flaggerv1.MetricTemplateBudget{MaxQueries: maxQueries},
+				},
+			},
+		}
+		if secret != "" {
+			template.Spec.Provider.SecretRef = &corev1.LocalObjectReference{Name: secret}
+		}
+		return template
+	}
+
+	var budgets queryBudgets
+	errorRate := budgets.get(cd, newTemplate("error-rate", 10, "datadog"))
+	latency := budgets.get(cd, newTemplate("latency", 5, "datadog"))
+	if errorRate != latency {
+		t.Fatal("Expected the templates of the same provider to share the budget")
+	}
+	if other := budgets.get(cd, newTemplate("latency", 5, "datadog-eu")); other == latency {
+		t.Error("Expected the provider credentials to be part of the budget key")
+	}
+
+	// the shared budget enforces the strictest limit
+	prom, err := providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: "fake"}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	provider := providers.NewBudgetProvider(prom, errorRate)
+	for i := 0; i < 5; i++ {
+		if _, err := provider.RunQuery("sum(trace.http.request.errors{*})"); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	if _, err := provider.RunQuery("sum(trace.http.request.errors{*})"); err == nil {
+		t.Errorf("Expected the query budget of %v queries to be enforced", 5)
+	}
+	if key := providerBudgetKey(newTemplate("error-rate", 10, "datadog")); key != "datadog/https://api.datadoghq.com secret=default/datadog" {
+		t.Errorf("Got budget key %s", key)
+	}
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newClassTestCanary() (*flaggerv1.Canary, *flaggerv1.CanaryClass) {
+	cd := newDeploymentTestCanary()
+	class := &flaggerv1.CanaryClass{
+		TypeMeta:   metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: flaggerv1.CanaryClassSpec{
+			Analysis: cd.Spec.CanaryAnalysis.DeepCopy(),
+		},
+	}
+	class.Spec.Analysis.StepWeight = 20
+
+	cd.Spec.CanaryClassName = class.Name
+	cd.Spec.CanaryAnalysis = &flaggerv1.CanaryAnalysis{
+		Metrics: []flaggerv1.CanaryMetric{
+			{
+				Name:      "request-success-rate",
+				Threshold: 95,
+				Interval:  "1m",
+			},
+			{
+				Name: "latency",
+				ThresholdRange: &flaggerv1.CanaryThresholdRange{
+					Max: toFloatPtr(500),
+				},
+				Interval: "1m",
+				TemplateRef: &flaggerv1.CrossNamespaceObjectReference{
+					Name:      "envoy",
+					Namespace: "default",
+				},
+			},
+		},
+	}
+	return cd, class
+}
+
+func TestScheduler_CanaryClassMerge(t *testing.T) {
+	cd, class := newClassTestCanary()
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.flaggerInformers.ClassInformer.Informer().GetIndexer().Add(class)
+
+	merged, err := mocks.ctrl.applyCanaryClass(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	analysis := merged.GetAnalysis()
+	if analysis.StepWeight != 20 || analysis.MaxWeight != 50 || analysis.Threshold != 10 {
+		t.Errorf("Got step weight %v max weight %v threshold %v wanted %v %v %v",
+			analysis.StepWeight, analysis.MaxWeight, analysis.Threshold, 20, 50, 10)
+	}
+
+	names := []string{"request-success-rate", "request-duration", "custom", "latency"}
+	if len(analysis.Metrics) != len(names) {
+		t.Fatalf("Got %v metrics wanted %v", len(analysis.Metrics), len(names))
+	}
+	for i, name := range names {
+		if analysis.Metrics[i].Name != name {
+			t.Errorf("Got metric %s at %v wanted %s", analysis.Metrics[i].Name, i, name)
+		}
+	}
+
+	if analysis.Metrics[0].Threshold != 95 {
+		t.Errorf("Got request-success-rate threshold %v wanted %v", analysis.Metrics[0].Threshold, 95)
+	}
+
+	// the informer cache must not be mutated
+	if len(mocks.canary.GetAnalysis().Metrics) != 2 || mocks.canary.GetAnalysis().StepWeight != 0 {
+		t.Errorf("The canary spec was modified by the class")
+	}
+}
+
+func TestScheduler_CanaryClassAnalysis(t *testing.T) {
+	cd, class := newClassTestCanary()
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.flaggerInformers.ClassInformer.Informer().GetIndexer().Add(class)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// advance with the class step weight
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if canaryWeight != 20 {
+		t.Errorf("Got canary weight %v wanted %v", canaryWeight, 20)
+	}
+}
+
+func TestScheduler_CanaryClassNotFound(t *testing.T) {
+	cd, _ := newClassTestCanary()
+	mocks := newDeploymentFixture(cd)
+
+	if _, err := mocks.ctrl.applyCanaryClass(mocks.canary); err == nil {
+		t.Errorf("Expected error for missing canary class")
+	}
+
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err == nil {
+		t.Errorf("The primary should not be created without the canary class")
+	}
+}
+
+func TestScheduler_CanaryClassCRDMissing(t *testing.T) {
+	cd, _ := newClassTestCanary()
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.flaggerInformers.ClassInformer = nil
+
+	if _, err := mocks.ctrl.applyCanaryClass(mocks.canary); err == nil {
+		t.Errorf("Expected error for a class without the CanaryClass CRD")
+	}
+
+	// the canaries without a class are not affected
+	mocks.canary.Spec.CanaryClassName = ""
+	mocks.canary.Spec.CanaryAnalysis = newDeploymentTestCanary().Spec.CanaryAnalysis
+	if _, err := mocks.ctrl.applyCanaryClass(mocks.canary); err != nil {
+		t.Errorf("Got error %v wanted none", err)
+	}
+}
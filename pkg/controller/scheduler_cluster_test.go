@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newClusterTestCanary() *flaggerv1.Canary {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Provider = "externaldns"
+	cd.Spec.AutoscalerRef = nil
+	cd.Spec.Cluster = &flaggerv1.CanaryCluster{
+		Name: "green",
+		DNS: &flaggerv1.CanaryDNS{
+			Hostname:       "podinfo.example.com",
+			PrimaryTargets: []string{"blue.example.com"},
+			CanaryTargets:  []string{"green.example.com"},
+		},
+	}
+	return cd
+}
+
+func newClusterFixture(cd *flaggerv1.Canary) (fixture, kubernetes.Interface) {
+	mocks := newDeploymentFixture(cd)
+	clusterClient := fake.NewSimpleClientset(newDeploymentTestDeployment())
+	mocks.ctrl.clusterClient = func(cd *flaggerv1.Canary) (kubernetes.Interface, error) {
+		return clusterClient, nil
+	}
+	return mocks, clusterClient
+}
+
+func TestScheduler_ClusterInit(t *testing.T) {
+	mocks, _ := newClusterFixture(newClusterTestCanary())
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// the primary is the target deployment of the Flagger cluster
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err == nil {
+		t.Errorf("Primary deployment should not be created in cluster mode")
+	}
+
+	endpoint, err := mocks.flaggerClient.ExternaldnsV1alpha1().DNSEndpoints("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(endpoint.Spec.Endpoints) != 2 {
+		t.Errorf("Got %v DNS records wanted 2", len(endpoint.Spec.Endpoints))
+	}
+}
+
+func TestScheduler_ClusterNewRevision(t *testing.T) {
+	mocks, clusterClient := newClusterFixture(newClusterTestCanary())
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update the canary cluster deployment
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := clusterClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseProgressing)
+	}
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	meshRouter := mocks.ctrl.routerFactory.MeshRouter("externaldns")
+	primaryWeight, canaryWeight, _, err := meshRouter.GetRoutes(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if primaryWeight != 90 || canaryWeight != 10 {
+		t.Errorf("Got primary weight %v canary weight %v wanted 90 10", primaryWeight, canaryWeight)
+	}
+
+	// the canary cluster deployment is never copied to a primary deployment during the analysis
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if dep.Spec.Template.Spec.Containers[0].Image == dep2.Spec.Template.Spec.Containers[0].Image {
+		t.Errorf("Primary cluster deployment should not be updated before promotion")
+	}
+}
+
+func TestScheduler_ClusterIstio(t *testing.T) {
+	cd := newClusterTestCanary()
+	cd.Spec.Provider = "istio"
+	cd.Spec.Cluster.DNS = nil
+	mocks, clusterClient := newClusterFixture(cd)
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// the canary service is created in the canary cluster only
+	if _, err := clusterClient.CoreV1().Services("default").Get("podinfo-canary", metav1.GetOptions{}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := mocks.kubeClient.CoreV1().Services("default").Get("podinfo-canary", metav1.GetOptions{}); err == nil {
+		t.Errorf("Canary service should not be created in the Flagger cluster")
+	}
+	if _, err := mocks.kubeClient.CoreV1().Services("default").Get("podinfo-primary", metav1.GetOptions{}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// update the canary cluster deployment
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := clusterClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// the shared gateway shifts the traffic to the canary cluster
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if primaryWeight != 90 || canaryWeight != 10 {
+		t.Errorf("Got primary weight %v canary weight %v wanted 90 10", primaryWeight, canaryWeight)
+	}
+}
+
+func TestScheduler_ClusterUnsupportedProvider(t *testing.T) {
+	cd := newClusterTestCanary()
+	cd.Spec.Provider = "nginx"
+	mocks, clusterClient := newClusterFixture(cd)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != "" {
+		t.Errorf("Got canary state %v wanted none", c.Status.Phase)
+	}
+	if _, err := clusterClient.CoreV1().Services("default").Get("podinfo-canary", metav1.GetOptions{}); err == nil {
+		t.Errorf("Canary service should not be created for an unsupported provider")
+	}
+}
+
+func TestScheduler_ClusterUnsupportedKind(t *testing.T) {
+	cd := newClusterTestCanary()
+	cd.Spec.TargetRef.Kind = "DaemonSet"
+	mocks, _ := newClusterFixture(cd)
+
+	_, err := mocks.ctrl.getCanaryController(cd)
+	if err == nil {
+		t.Errorf("Expected an error for a DaemonSet target in cluster mode")
+	}
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func setCommandTestCanary(t *testing.T, mocks fixture, annotation string, revision string) {
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cdCopy := cd.DeepCopy()
+	if revision == "" {
+		revision = cd.Status.LastAppliedSpec
+	}
+	cdCopy.Annotations = map[string]string{annotation: revision}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(cdCopy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestScheduler_CommandPromote(t *testing.T) {
+	mocks := startRolloutTest(t)
+	setSuspendTestCanary(t, mocks, true)
+	setCommandTestCanary(t, mocks, flaggerv1.PromoteAnnotation, "")
+
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseSucceeded {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseSucceeded)
+	}
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if image := primary.Spec.Template.Spec.Containers[0].Image; image != "quay.io/stefanprodan/podinfo:1.2.1" {
+		t.Errorf("Got primary image %v wanted %v", image, "quay.io/stefanprodan/podinfo:1.2.1")
+	}
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if primaryWeight != 100 || canaryWeight != 0 {
+		t.Errorf("Got weights %v/%v wanted 100/0", primaryWeight, canaryWeight)
+	}
+}
+
+func TestScheduler_CommandRollback(t *testing.T) {
+	mocks := startRolloutTest(t)
+	setCommandTestCanary(t, mocks, flaggerv1.RollbackAnnotation, "")
+
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseFailed || c.Status.CanaryWeight != 0 {
+		t.Errorf("Got canary phase %v weight %v wanted %v 0", c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseFailed)
+	}
+}
+
+func TestScheduler_CommandStaleRevision(t *testing.T) {
+	mocks := startRolloutTest(t)
+	setCommandTestCanary(t, mocks, flaggerv1.RollbackAnnotation, "previous-revision")
+
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing || c.Status.CanaryWeight != 20 {
+		t.Errorf("Got canary phase %v weight %v wanted %v 20", c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseProgressing)
+	}
+}
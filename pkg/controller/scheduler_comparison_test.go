@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// newComparisonTestServer returns a Prometheus API serving the latency datapoints
+// of the baseline or canary pods depending on the target of the query
+func newComparisonTestServer(canary []float64, baseline []float64, baselineTarget string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		values := canary
+		if strings.Contains(r.URL.Query().Get("query"), baselineTarget) {
+			values = baseline
+		}
+		var points []string
+		for i, v := range values {
+			points = append(points, fmt.Sprintf(`[%v,"%v"]`, 1600000000+i*6, v))
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[%s]}]}}`,
+			strings.Join(points, ","))
+	}))
+}
+
+func newComparisonTestFixture(address string, comparison flaggerv1.CanaryMetricComparison) fixture {
+	return newComparisonTestFixtureWithCanary(newDeploymentTestCanary(), address, comparison)
+}
+
+func newComparisonTestFixtureWithCanary(cd *flaggerv1.Canary, address string, comparison flaggerv1.CanaryMetricComparison) fixture {
+	cd.Spec.CanaryAnalysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name:        "latency",
+			TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "latency"},
+			Comparison:  &comparison,
+			Interval:    "1m",
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(&flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "latency",
+		},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{
+				Type:    "prometheus",
+				Address: address,
+			},
+			Query: `histogram_quantile(0.99, rate(http_request_duration_seconds_bucket{pod=~"{{ target }}-.*"}[1m]))`,
+		},
+	})
+	return mocks
+}
+
+var comparisonTestBaseline = []float64{100, 102, 98, 101, 99, 103, 97, 100, 101, 99}
+
+func TestScheduler_ComparisonMetric(t *testing.T) {
+	ts := newComparisonTestServer([]float64{101, 99, 100, 102, 98, 100, 103, 97, 100, 101}, comparisonTestBaseline, "podinfo-primary")
+	defer ts.Close()
+
+	mocks := newComparisonTestFixture(ts.URL, flaggerv1.CanaryMetricComparison{})
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); !ok {
+		t.Errorf("Expected the comparison metric check to pass")
+	}
+
+	results := mocks.ctrl.GetMetricResults(mocks.canary.Name, mocks.canary.Namespace)
+	if len(results) != 1 || results[0].Value != 100 || !results[0].Passed {
+		t.Errorf("Got results %v wanted latency 100 passed", results)
+	}
+}
+
+func TestScheduler_ComparisonMetricSignificant(t *testing.T) {
+	slower := []float64{120, 125, 118, 130, 122, 119, 127, 121, 124, 126}
+	ts := newComparisonTestServer(slower, comparisonTestBaseline, "podinfo-primary")
+	defer ts.Close()
+
+	mocks := newComparisonTestFixture(ts.URL, flaggerv1.CanaryMetricComparison{})
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the comparison metric check to fail")
+	}
+
+	series := mocks.ctrl.GetTimeSeries(mocks.canary.Name, mocks.canary.Namespace)
+	if len(series) != 1 || series[0].Samples[0].Primary == nil || *series[0].Samples[0].Primary != 100 {
+		t.Errorf("Got time series %v wanted the baseline median 100", series)
+	}
+
+	// the canary is slower, a decrease only check passes
+	mocks = newComparisonTestFixture(ts.URL, flaggerv1.CanaryMetricComparison{Direction: "decrease"})
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); !ok {
+		t.Errorf("Expected the comparison metric check on decrease to pass")
+	}
+}
+
+func TestScheduler_ComparisonMetricMinDataPoints(t *testing.T) {
+	ts := newComparisonTestServer([]float64{100, 101, 99}, comparisonTestBaseline, "podinfo-primary")
+	defer ts.Close()
+
+	mocks := newComparisonTestFixture(ts.URL, flaggerv1.CanaryMetricComparison{})
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the comparison metric check to halt below the minimum datapoints")
+	}
+
+	if results := mocks.ctrl.GetMetricResults(mocks.canary.Name, mocks.canary.Namespace); len(results) != 0 {
+		t.Errorf("Got results %v wanted none", results)
+	}
+
+	mocks = newComparisonTestFixture(ts.URL, flaggerv1.CanaryMetricComparison{MinDataPoints: 3})
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); !ok {
+		t.Errorf("Expected the comparison metric check to pass with three datapoints")
+	}
+}
+
+func TestScheduler_ComparisonMetricBaselineWorkload(t *testing.T) {
+	// the primary is faster than the canary, the baseline isn't
+	slower := []float64{120, 125, 118, 130, 122, 119, 127, 121, 124, 126}
+	ts := newComparisonTestServer(slower, comparisonTestBaseline, "podinfo-primary")
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.BaselineWorkload = true
+	mocks := newComparisonTestFixtureWithCanary(cd, ts.URL, flaggerv1.CanaryMetricComparison{})
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	if _, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-baseline", metav1.GetOptions{}); err == nil {
+		t.Errorf("Expected no baseline deployment before the analysis")
+	}
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes and start the analysis
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	baseline, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-baseline", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if image := baseline.Spec.Template.Spec.Containers[0].Image; image != "quay.io/stefanprodan/podinfo:1.2.0" {
+		t.Errorf("Got baseline image %v wanted %v", image, "quay.io/stefanprodan/podinfo:1.2.0")
+	}
+
+	// the canary is compared to the baseline and advances to promotion
+	for i := 0; i < 10; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+	}
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseSucceeded {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseSucceeded)
+	}
+
+	// the baseline is removed with the canary pods
+	if _, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-baseline", metav1.GetOptions{}); err == nil {
+		t.Errorf("Expected the baseline deployment to be deleted")
+	}
+}
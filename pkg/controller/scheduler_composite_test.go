@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newCompositeTestCanary(expression string, threshold float64) *flaggerv1.Canary {
+	cd := newDeploymentTestCanary()
+	// the envoy template provider is fake and returns 100 for every query
+	cd.Spec.CanaryAnalysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name:       "error-ratio",
+			Expression: expression,
+			Templates: []flaggerv1.CanaryMetricTemplate{
+				{Name: "errors", TemplateRef: flaggerv1.CrossNamespaceObjectReference{Name: "envoy"}},
+				{Name: "requests", TemplateRef: flaggerv1.CrossNamespaceObjectReference{Name: "envoy", Namespace: "default"}},
+			},
+			Threshold: threshold,
+			Interval:  "1m",
+		},
+	}
+	return cd
+}
+
+func TestScheduler_CompositeMetric(t *testing.T) {
+	mocks := newDeploymentFixture(newCompositeTestCanary("(errors + 50) / requests * 100", 200))
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); !ok {
+		t.Errorf("Expected the composite metric check to pass")
+	}
+
+	results := mocks.ctrl.GetMetricResults(mocks.canary.Name, mocks.canary.Namespace)
+	if len(results) != 1 || results[0].Value != 150 {
+		t.Errorf("Got results %v wanted error-ratio 150", results)
+	}
+
+	mocks = newDeploymentFixture(newCompositeTestCanary("(errors + 50) / requests * 100", 100))
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the composite metric check to fail")
+	}
+}
+
+func TestScheduler_CompositeMetricErrors(t *testing.T) {
+	for _, expr := range []string{"errors / latency", "errors /", "errors / (requests - 100)"} {
+		mocks := newDeploymentFixture(newCompositeTestCanary(expr, 100))
+		if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok {
+			t.Errorf("Expected the composite metric check to fail for %s", expr)
+		}
+	}
+}
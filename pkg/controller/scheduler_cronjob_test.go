@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_CronJobInit(t *testing.T) {
+	mocks := newDeploymentFixture(newCronJobTestCanary())
+	if _, err := mocks.kubeClient.BatchV1beta1().CronJobs("default").Create(newCronJobTestCronJob()); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mocks.ctrl.advanceCanary("report", "default", true)
+
+	if _, err := mocks.kubeClient.BatchV1beta1().CronJobs("default").Get("report-primary", metav1.GetOptions{}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// no services are created for jobs
+	if _, err := mocks.kubeClient.CoreV1().Services("default").Get("report", metav1.GetOptions{}); err == nil {
+		t.Errorf("Expected no service for the cronjob target")
+	}
+}
+
+func TestScheduler_CronJobMetricChecks(t *testing.T) {
+	mocks := newDeploymentFixture(newCronJobTestCanary())
+	if _, err := mocks.kubeClient.BatchV1beta1().CronJobs("default").Create(newCronJobTestCronJob()); err != nil {
+		t.Fatal(err.Error())
+	}
+	mocks.ctrl.advanceCanary("report", "default", true)
+
+	controller := true
+	newJob := func(name string, labels map[string]string, owner string, failed bool) *batchv1.Job {
+		start := metav1.NewTime(time.Now().Add(-time.Hour))
+		completion := metav1.NewTime(start.Add(time.Minute))
+		condition := batchv1.JobComplete
+		if failed {
+			condition = batchv1.JobFailed
+		}
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels},
+			Status: batchv1.JobStatus{
+				StartTime:      &start,
+				CompletionTime: &completion,
+				Conditions:     []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}},
+			},
+		}
+		if owner != "" {
+			job.OwnerReferences = []metav1.OwnerReference{{Kind: "CronJob", Name: owner, Controller: &controller}}
+		}
+		return job
+	}
+
+	mocks.ctrl.canaryFactory.Controller("CronJob").ScaleFromZero(mocks.canary)
+	list, err := mocks.kubeClient.BatchV1().Jobs("default").List(metav1.ListOptions{LabelSelector: "flagger.app/canary=report"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("Got %v canary jobs wanted 1", len(list.Items))
+	}
+
+	// the canary execution fails while the primary ones succeed
+	canaryJob := newJob(list.Items[0].Name, list.Items[0].Labels, "", true)
+	if _, err := mocks.kubeClient.BatchV1().Jobs("default").Update(canaryJob); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := mocks.kubeClient.BatchV1().Jobs("default").Create(newJob("report-primary-1", nil, "report-primary", false)); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if ok := mocks.ctrl.runJobMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the job failure rate check to fail")
+	}
+
+	canaryJob = newJob(list.Items[0].Name, list.Items[0].Labels, "", false)
+	if _, err := mocks.kubeClient.BatchV1().Jobs("default").Update(canaryJob); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if ok := mocks.ctrl.runJobMetricChecks(mocks.canary, nil); !ok {
+		t.Errorf("Expected the job checks to pass")
+	}
+}
+
+func newCronJobTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "report",
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "report",
+				APIVersion: "batch/v1beta1",
+				Kind:       "CronJob",
+			},
+			CanaryAnalysis: &flaggerv1.CanaryAnalysis{
+				Threshold: 1,
+				Metrics: []flaggerv1.CanaryMetric{
+					{
+						Name:      "job-failure-rate",
+						Threshold: 0,
+					},
+					{
+						Name:      "job-duration",
+						Threshold: 120,
+					},
+				},
+			},
+		},
+	}
+}
+
+func newCronJobTestCronJob() *batchv1beta1.CronJob {
+	return &batchv1beta1.CronJob{
+		TypeMeta: metav1.TypeMeta{APIVersion: batchv1beta1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "report",
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule: "*/5 * * * *",
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{"app": "report"},
+						},
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{
+									Name:  "report",
+									Image: "report:1.0.0",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -72,13 +72,15 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
 		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
 		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		ClassInformer:  flaggerInformerFactory.Flagger().V1beta1().CanaryClasses(),
+		RouteInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertRoutes(),
 	}
 
 	// init router
 	rf := router.NewFactory(nil, kubeClient, flaggerClient, "annotationsPrefix", logger, flaggerClient)
 
 	// init observer
-	observerFactory, _ := observers.NewFactory("fake")
+	observerFactory, _ := observers.NewFactory("fake", "")
 
 	// init canary factory
 	configTracker := &canary.ConfigTracker{
@@ -86,7 +88,7 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		KubeClient:    kubeClient,
 		FlaggerClient: flaggerClient,
 	}
-	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, []string{"app", "name"}, logger)
+	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, nil, []string{"app", "name"}, canary.PolicyMetadata{}, logger)
 
 	ctrl := &Controller{
 		kubeClient:       kubeClient,
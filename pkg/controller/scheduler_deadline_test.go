@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_AnalysisDeadline(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.Deadline = "1h"
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes and start the analysis
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing || c.Status.AnalysisStartTime == nil {
+		t.Fatalf("Got canary phase %v analysis start %v wanted %v with a start time",
+			c.Status.Phase, c.Status.AnalysisStartTime, flaggerv1.CanaryPhaseProgressing)
+	}
+
+	// simulate an analysis that started two hours ago without reaching the failed checks threshold
+	cdCopy := c.DeepCopy()
+	cdCopy.Status.AnalysisStartTime = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+	if _, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(cdCopy); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// rollback
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseFailed {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseFailed)
+	}
+	if c.Status.AnalysisStartTime != nil {
+		t.Errorf("Got analysis start time %v wanted nil", c.Status.AnalysisStartTime)
+	}
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if primaryWeight != 100 || canaryWeight != 0 {
+		t.Errorf("Got primary weight %v canary weight %v wanted %v %v", primaryWeight, canaryWeight, 100, 0)
+	}
+}
+
+func TestScheduler_AnalysisDeadlineSuspended(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.Deadline = "1h"
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes and start the analysis
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// suspend
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	c.Spec.Suspend = true
+	if _, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(c); err != nil {
+		t.Fatal(err.Error())
+	}
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.SuspendTime == nil {
+		t.Fatalf("Got suspend time nil wanted the suspension time")
+	}
+
+	// simulate an analysis that started two hours ago and was suspended for the last 90 minutes
+	c.Status.AnalysisStartTime = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+	c.Status.SuspendTime = &metav1.Time{Time: time.Now().Add(-90 * time.Minute)}
+	c.Spec.Suspend = false
+	if _, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(c); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// resume
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseProgressing)
+	}
+	if c.Status.SuspendTime != nil {
+		t.Errorf("Got suspend time %v wanted nil", c.Status.SuspendTime)
+	}
+	if c.Status.AnalysisStartTime == nil || time.Since(c.Status.AnalysisStartTime.Time) > 31*time.Minute {
+		t.Errorf("Got analysis start time %v wanted 30m ago", c.Status.AnalysisStartTime)
+	}
+}
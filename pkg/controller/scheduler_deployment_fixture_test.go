@@ -74,13 +74,15 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
 		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
 		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		ClassInformer:  flaggerInformerFactory.Flagger().V1beta1().CanaryClasses(),
+		RouteInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertRoutes(),
 	}
 
 	// init router
 	rf := router.NewFactory(nil, kubeClient, flaggerClient, "annotationsPrefix", logger, flaggerClient)
 
 	// init observer
-	observerFactory, _ := observers.NewFactory("fake")
+	observerFactory, _ := observers.NewFactory("fake", "")
 
 	// init canary factory
 	configTracker := &canary.ConfigTracker{
@@ -88,7 +90,7 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 		KubeClient:    kubeClient,
 		FlaggerClient: flaggerClient,
 	}
-	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, []string{"app", "name"}, logger)
+	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, nil, []string{"app", "name"}, canary.PolicyMetadata{}, logger)
 
 	ctrl := &Controller{
 		kubeClient:       kubeClient,
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -525,6 +526,58 @@ func TestScheduler_DeploymentMirroring(t *testing.T) {
 	}
 }
 
+func TestScheduler_DeploymentMirrorIterations(t *testing.T) {
+	cd := newDeploymentTestCanaryMirror()
+	cd.Spec.CanaryAnalysis.MirrorIterations = 2
+	mocks := newDeploymentFixture(cd)
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// run the mirror iterations
+	for i := 1; i <= 2; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+		primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if primaryWeight != 100 || canaryWeight != 0 || !mirrored {
+			t.Errorf("Got primary=%v canary=%v mirrored=%v wanted primary=100 canary=0 mirrored=true",
+				primaryWeight, canaryWeight, mirrored)
+		}
+
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if c.Status.Iterations != i {
+			t.Errorf("Got iterations %v wanted %v", c.Status.Iterations, i)
+		}
+	}
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if primaryWeight != 90 || canaryWeight != 10 || mirrored {
+		t.Errorf("Got primary=%v canary=%v mirrored=%v wanted primary=90 canary=10 mirrored=false",
+			primaryWeight, canaryWeight, mirrored)
+	}
+}
+
 func TestScheduler_DeploymentABTesting(t *testing.T) {
 	mocks := newDeploymentFixture(newDeploymentTestCanaryAB())
 	// init
@@ -796,3 +849,347 @@ func TestScheduler_DeploymentAlerts(t *testing.T) {
 	// init canary and send alerts
 	mocks.ctrl.advanceCanary("podinfo", "default", true)
 }
+
+func TestScheduler_DeploymentRoutesDrift(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if c.Status.CanaryWeight == 0 {
+		t.Fatalf("Got canary weight %v wanted more than %v", c.Status.CanaryWeight, 0)
+	}
+
+	// reset routes outside of Flagger
+	err = mocks.router.SetRoutes(mocks.canary, 100, 0, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// restore routes in between analysis runs
+	mocks.ctrl.checkRoutes("podinfo", "default")
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if canaryWeight != c.Status.CanaryWeight {
+		t.Errorf("Got canary route %v wanted %v", canaryWeight, c.Status.CanaryWeight)
+	}
+
+	if primaryWeight != 100-c.Status.CanaryWeight {
+		t.Errorf("Got primary route %v wanted %v", primaryWeight, 100-c.Status.CanaryWeight)
+	}
+}
+
+func TestScheduler_DeploymentFireDrill(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// enable fire drill
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cd := c.DeepCopy()
+	cd.Spec.CanaryAnalysis.FireDrill = true
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// run simulated checks
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if c.Status.FailedChecks != 2 {
+		t.Errorf("Got failed checks %v wanted %v", c.Status.FailedChecks, 2)
+	}
+
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if canaryWeight != 0 {
+		t.Errorf("Got canary route %v wanted %v", canaryWeight, 0)
+	}
+
+	// reach the failed checks threshold
+	err = mocks.deployer.SyncStatus(c, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, FailedChecks: c.GetAnalysisThreshold()})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// rollback
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if c.Status.Phase != flaggerv1.CanaryPhaseFailed {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseFailed)
+	}
+}
+
+func TestScheduler_DeploymentSteppedRollback(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.RollbackStrategy = flaggerv1.RollbackStrategyStepped
+	mocks := newDeploymentFixture(cd)
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update failed checks to max with 30% of the traffic routed to the canary
+	err := mocks.deployer.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, FailedChecks: 10, CanaryWeight: 30})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := mocks.router.SetRoutes(c, 70, 30, false); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the traffic is stepped down by the step weight
+	for _, want := range []int{20, 10} {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+		c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if c.Status.Phase != flaggerv1.CanaryPhaseRollingBack {
+			t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseRollingBack)
+		}
+
+		_, canaryWeight, _, err := mocks.router.GetRoutes(c)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if canaryWeight != want || c.Status.CanaryWeight != want {
+			t.Errorf("Got canary weight %v status weight %v wanted %v", canaryWeight, c.Status.CanaryWeight, want)
+		}
+	}
+
+	// the last step routes all traffic to primary
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseFailed {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseFailed)
+	}
+	_, canaryWeight, _, err := mocks.router.GetRoutes(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if canaryWeight != 0 {
+		t.Errorf("Got canary weight %v wanted %v", canaryWeight, 0)
+	}
+}
+
+func TestScheduler_DeploymentHold(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.HoldWeight = 20
+	mocks := newDeploymentFixture(cd)
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// route the max weight to the canary
+	if err := mocks.router.SetRoutes(mocks.canary, 50, 50, false); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the analysis succeeds and the canary is held instead of promoted
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseHolding {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseHolding)
+	}
+	_, canaryWeight, _, err := mocks.router.GetRoutes(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if canaryWeight != 20 || c.Status.CanaryWeight != 20 {
+		t.Errorf("Got canary weight %v status weight %v wanted %v", canaryWeight, c.Status.CanaryWeight, 20)
+	}
+
+	// the hold weight is restored if the routes are changed
+	if err := mocks.router.SetRoutes(c, 100, 0, false); err != nil {
+		t.Fatal(err.Error())
+	}
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseHolding {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseHolding)
+	}
+	_, canaryWeight, _, err = mocks.router.GetRoutes(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if canaryWeight != 20 {
+		t.Errorf("Got canary weight %v wanted %v", canaryWeight, 20)
+	}
+
+	// the canary is promoted once the hold weight is removed
+	c.Spec.CanaryAnalysis.HoldWeight = 0
+	if _, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(c); err != nil {
+		t.Fatal(err.Error())
+	}
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhasePromoting {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhasePromoting)
+	}
+
+	// finalise and succeed
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseSucceeded {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseSucceeded)
+	}
+	_, canaryWeight, _, err = mocks.router.GetRoutes(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if canaryWeight != 0 {
+		t.Errorf("Got canary weight %v wanted %v", canaryWeight, 0)
+	}
+}
+
+func TestScheduler_DeploymentHoldNewRevision(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.HoldWeight = 20
+	mocks := newDeploymentFixture(cd)
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// hold the current revision
+	err := mocks.deployer.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseHolding, CanaryWeight: 20})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := mocks.router.SetRoutes(mocks.canary, 80, 20, false); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the new revision routes all traffic to primary and restarts the analysis
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseProgressing)
+	}
+	_, canaryWeight, _, err := mocks.router.GetRoutes(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if canaryWeight != 0 || c.Status.CanaryWeight != 0 {
+		t.Errorf("Got canary weight %v status weight %v wanted %v", canaryWeight, c.Status.CanaryWeight, 0)
+	}
+}
+
+func TestScheduler_DeploymentRoutesWatch(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	events := make(chan struct{}, 1)
+	mocks.ctrl.routesEvents.Store("podinfo.default", events)
+
+	// the router objects not owned by a canary are ignored
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+	}
+	mocks.ctrl.notifyRoutesChange(svc)
+	if len(events) != 0 {
+		t.Fatalf("Got %v routes events wanted none", len(events))
+	}
+
+	// a change of the router object of the canary signals the job once
+	svc.OwnerReferences = []metav1.OwnerReference{
+		*metav1.NewControllerRef(mocks.canary, flaggerv1.SchemeGroupVersion.WithKind(flaggerv1.CanaryKind)),
+	}
+	mocks.ctrl.notifyRoutesChange(svc)
+	mocks.ctrl.notifyRoutesChange(svc)
+	if len(events) != 1 {
+		t.Errorf("Got %v routes events wanted 1", len(events))
+	}
+}
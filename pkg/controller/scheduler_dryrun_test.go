@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_DryRun(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.DryRun = true
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// advance to the max weight, the traffic stays on primary
+	for i := 0; i < 5; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+		mocks.ctrl.checkRoutes("podinfo", "default")
+	}
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing || c.Status.CanaryWeight != 50 {
+		t.Errorf("Got canary phase %v weight %v wanted %v 50", c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseProgressing)
+	}
+	assertDryRunTestRoutes(t, mocks, c)
+
+	// end the dry run without promoting the canary
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseDryRunPassed {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseDryRunPassed)
+	}
+	assertDryRunTestRoutes(t, mocks, c)
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if image := primary.Spec.Template.Spec.Containers[0].Image; image != "quay.io/stefanprodan/podinfo:1.2.0" {
+		t.Errorf("Got primary image %v wanted %v", image, "quay.io/stefanprodan/podinfo:1.2.0")
+	}
+
+	canary, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if canary.Spec.Replicas == nil || *canary.Spec.Replicas != 0 {
+		t.Errorf("Got canary replicas %v wanted %v", canary.Spec.Replicas, 0)
+	}
+
+	// the same revision is not analysed again
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseDryRunPassed {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseDryRunPassed)
+	}
+}
+
+func TestScheduler_DryRunFailed(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.DryRun = true
+	cd.Spec.CanaryAnalysis.FireDrill = true
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes and fail the checks up to the threshold
+	for i := 0; i < cd.GetAnalysisThreshold()+3; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+	}
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseFailed {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseFailed)
+	}
+	assertDryRunTestRoutes(t, mocks, c)
+}
+
+func assertDryRunTestRoutes(t *testing.T, mocks fixture, cd *flaggerv1.Canary) {
+	t.Helper()
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if primaryWeight != 100 || canaryWeight != 0 {
+		t.Errorf("Got primary weight %v canary weight %v wanted %v %v", primaryWeight, canaryWeight, 100, 0)
+	}
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_PromotionETA(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// advance to 10% and 20%
+	for weight := 10; weight <= 20; weight += 10 {
+		begin := time.Now().Truncate(time.Second)
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if c.Status.CanaryWeight != weight {
+			t.Fatalf("Got canary weight %v wanted %v", c.Status.CanaryWeight, weight)
+		}
+		if c.Status.PromotionETA == nil {
+			t.Fatal("Promotion ETA not set")
+		}
+
+		// one run per step weight left until 50% and one for the promotion
+		runs := (50-weight)/10 + 1
+		expected := begin.Add(time.Duration(runs) * time.Minute)
+		if eta := c.Status.PromotionETA.Time; eta.Before(expected) || eta.After(expected.Add(time.Second)) {
+			t.Errorf("Got promotion ETA %v wanted %v", eta, expected)
+		}
+	}
+
+	// advance to 50% and promote
+	for i := 0; i < 4; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+	}
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhasePromoting {
+		t.Fatalf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhasePromoting)
+	}
+	if c.Status.PromotionETA != nil {
+		t.Errorf("Got promotion ETA %v wanted none", c.Status.PromotionETA)
+	}
+}
+
+func TestScheduler_PromotionRemainingRuns(t *testing.T) {
+	tests := []struct {
+		name     string
+		canary   func() *flaggerv1.Canary
+		provider string
+		weight   int
+		iter     int
+		mirrored bool
+		runs     int
+		ok       bool
+	}{
+		{"canary", newDeploymentTestCanary, "istio", 10, 0, false, 5, true},
+		{"canary max weight", newDeploymentTestCanary, "istio", 50, 0, false, 1, true},
+		{"canary mirror", newDeploymentTestCanaryMirror, "istio", 0, 0, false, 7, true},
+		{"canary mirrored", newDeploymentTestCanaryMirror, "istio", 0, 1, true, 6, true},
+		{"canary mirror iterations", func() *flaggerv1.Canary {
+			cd := newDeploymentTestCanaryMirror()
+			cd.Spec.CanaryAnalysis.MirrorIterations = 3
+			return cd
+		}, "istio", 0, 1, true, 8, true},
+		{"canary hold", func() *flaggerv1.Canary {
+			cd := newDeploymentTestCanary()
+			cd.Spec.CanaryAnalysis.HoldWeight = 20
+			return cd
+		}, "istio", 10, 0, false, 0, false},
+		{"canary step weights", func() *flaggerv1.Canary {
+			cd := newDeploymentTestCanary()
+			cd.Spec.CanaryAnalysis.StepWeights = []int{1, 5, 20, 50, 80}
+			return cd
+		}, "istio", 30, 0, false, 3, true},
+		{"canary warmup", func() *flaggerv1.Canary {
+			cd := newDeploymentTestCanary()
+			cd.Spec.CanaryAnalysis.WarmupIterations = 3
+			return cd
+		}, "istio", 0, 0, false, 8, true},
+		{"canary warmup check", func() *flaggerv1.Canary {
+			cd := newDeploymentTestCanary()
+			cd.Spec.CanaryAnalysis.WarmupIterations = 3
+			return cd
+		}, "istio", 10, 1, false, 6, true},
+		{"ab testing", newDeploymentTestCanaryAB, "istio", 100, 3, false, 8, true},
+		{"blue/green", newDeploymentTestCanary, "kubernetes", 0, 4, false, 8, true},
+		{"blue/green promotion", newDeploymentTestCanary, "kubernetes", 0, 11, false, 1, true},
+	}
+
+	for _, tt := range tests {
+		runs, ok := remainingRuns(tt.canary(), tt.provider, tt.weight, tt.iter, tt.mirrored)
+		if runs != tt.runs || ok != tt.ok {
+			t.Errorf("%s: got runs %v %v wanted %v %v", tt.name, runs, ok, tt.runs, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_Explain(t *testing.T) {
+	mocks := startRolloutTest(t)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	e, err := mocks.ctrl.Explain(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if e.Phase != flaggerv1.CanaryPhaseProgressing || e.Gate != nil {
+		t.Fatalf("Got phase %v gate %+v wanted %v without gate", e.Phase, e.Gate, flaggerv1.CanaryPhaseProgressing)
+	}
+	if want := "advance the canary weight to 20"; e.NextAction != want {
+		t.Errorf("Got next action %q wanted %q", e.NextAction, want)
+	}
+	if e.NextRun == nil {
+		t.Error("Got no next run for a progressing canary")
+	}
+
+	// halt the advancement
+	cd := c.DeepCopy()
+	cd.Spec.CanaryAnalysis.FireDrill = true
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	e, err = mocks.ctrl.Explain(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if e.Gate == nil || e.Gate.Reason != haltReasonFireDrill {
+		t.Fatalf("Got gate %+v wanted %v", e.Gate, haltReasonFireDrill)
+	}
+	if e.FailedChecks != 1 || !strings.HasPrefix(e.NextAction, "retry the analysis") {
+		t.Errorf("Got failed checks %v next action %q", e.FailedChecks, e.NextAction)
+	}
+
+	// the gate is cleared when the checks pass
+	cd = c.DeepCopy()
+	cd.Spec.CanaryAnalysis.FireDrill = false
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	e, err = mocks.ctrl.Explain(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if e.Gate != nil {
+		t.Errorf("Got gate %+v wanted none", e.Gate)
+	}
+	if len(e.Checks) == 0 {
+		t.Error("Got no checks wanted the metric results")
+	}
+}
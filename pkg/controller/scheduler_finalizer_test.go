@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestScheduler_RevertOnDeletion(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.RevertOnDeletion = true
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !hasFinalizer(c) {
+		t.Fatalf("Got finalizers %v wanted %v", c.Finalizers, finalizer)
+	}
+
+	// the target is scaled to zero after the initialization
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if dep.Spec.Replicas == nil || *dep.Spec.Replicas != 0 {
+		t.Fatalf("Got target replicas %v wanted 0", dep.Spec.Replicas)
+	}
+
+	// delete
+	now := metav1.Now()
+	c.DeletionTimestamp = &now
+	if _, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(c); err != nil {
+		t.Fatal(err.Error())
+	}
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if hasFinalizer(c) {
+		t.Errorf("Got finalizers %v wanted none", c.Finalizers)
+	}
+
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if dep.Spec.Replicas == nil || *dep.Spec.Replicas < 1 {
+		t.Errorf("Got target replicas %v wanted at least 1", dep.Spec.Replicas)
+	}
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if primaryWeight != 0 || canaryWeight != 100 {
+		t.Errorf("Got primary weight %v canary weight %v wanted %v %v", primaryWeight, canaryWeight, 0, 100)
+	}
+
+	svc, err := mocks.kubeClient.CoreV1().Services("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if selector := svc.Spec.Selector["app"]; selector != "podinfo" {
+		t.Errorf("Got service selector %v wanted %v", selector, "podinfo")
+	}
+}
+
+func TestScheduler_RevertOnDeletionDisabled(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(c.Finalizers) != 0 {
+		t.Errorf("Got finalizers %v wanted none", c.Finalizers)
+	}
+}
+
+func TestScheduler_RevertOnDeletionTargetDeleted(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.RevertOnDeletion = true
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// delete the target then the canary
+	if err := mocks.kubeClient.AppsV1().Deployments("default").Delete("podinfo", &metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err.Error())
+	}
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	now := metav1.Now()
+	c.DeletionTimestamp = &now
+	if _, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(c); err != nil {
+		t.Fatal(err.Error())
+	}
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if hasFinalizer(c) {
+		t.Errorf("Got finalizers %v wanted none", c.Finalizers)
+	}
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+)
+
+func newGRPCTestCanary(provider string) *flaggerv1.Canary {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Provider = provider
+	cd.Spec.CanaryAnalysis.Iterations = 10
+	cd.Spec.CanaryAnalysis.GRPCMatch = []flaggerv1.CanaryGRPCMatch{
+		{
+			Service:  "grpc.health.v1.Health",
+			Method:   "Check",
+			Metadata: map[string]istiov1alpha1.StringMatch{"X-Caller": {Exact: "internal"}},
+		},
+	}
+	return cd
+}
+
+func TestScheduler_GRPCMatch(t *testing.T) {
+	mocks := newDeploymentFixture(newGRPCTestCanary("istio"))
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes and route the matching calls to canary
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(vs.Spec.Http) != 2 || len(vs.Spec.Http[0].Match) != 1 {
+		t.Fatalf("Got http routes %+v wanted a gRPC match route and a default route", vs.Spec.Http)
+	}
+
+	match := vs.Spec.Http[0].Match[0]
+	if match.Uri == nil || match.Uri.Exact != "/grpc.health.v1.Health/Check" {
+		t.Errorf("Got uri %+v wanted exact %s", match.Uri, "/grpc.health.v1.Health/Check")
+	}
+	if h, ok := match.Headers["x-caller"]; !ok || h.Exact != "internal" {
+		t.Errorf("Got headers %+v wanted x-caller metadata", match.Headers)
+	}
+	for _, dst := range vs.Spec.Http[0].Route {
+		if dst.Destination.Host == "podinfo-canary" && dst.Weight != 100 {
+			t.Errorf("Got canary weight %v wanted %v", dst.Weight, 100)
+		}
+	}
+}
+
+func TestScheduler_GRPCMatchNotSupported(t *testing.T) {
+	mocks := newDeploymentFixture(newGRPCTestCanary("nginx"))
+
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// the canary isn't initialized by a router that would send all calls to canary
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Expected the primary deployment not to be created got %v", err)
+	}
+}
+
+func TestCanary_GetGRPCMatch(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.GRPCMatch = []flaggerv1.CanaryGRPCMatch{
+		{Service: "grpc.health.v1.Health"},
+		{Method: "Watch"},
+	}
+
+	matches := cd.GetGRPCMatch()
+	if len(matches) != 2 {
+		t.Fatalf("Got %v matches wanted %v", len(matches), 2)
+	}
+	if matches[0].Uri.Prefix != "/grpc.health.v1.Health/" {
+		t.Errorf("Got service uri %+v", matches[0].Uri)
+	}
+	if matches[1].Uri.Regex != "^/[^/]+/Watch$" {
+		t.Errorf("Got method uri %+v", matches[1].Uri)
+	}
+}
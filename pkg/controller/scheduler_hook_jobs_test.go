@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_PreRolloutJob(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.Webhooks = append(cd.Spec.CanaryAnalysis.Webhooks, flaggerv1.CanaryWebhook{
+		Name: "e2e",
+		Type: flaggerv1.PreRolloutHook,
+		Job: &flaggerv1.CanaryWebhookJob{
+			ResultPath: "/reports/junit.xml",
+			Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "test", Image: "test-runner"}},
+					},
+				},
+			},
+		},
+	})
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.podLogs = func(namespace string, pod string, container string) (string, error) {
+		return "2 tests passed", nil
+	}
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// start the pre-rollout job
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	jobs, err := mocks.kubeClient.BatchV1().Jobs("default").List(metav1.ListOptions{LabelSelector: hookJobCanaryLabel + "=podinfo"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(jobs.Items) != 1 {
+		t.Fatalf("Got %d jobs wanted 1", len(jobs.Items))
+	}
+	job := jobs.Items[0]
+	if job.Labels[hookJobNameLabel] != "e2e" {
+		t.Errorf("Got hook label %s wanted e2e", job.Labels[hookJobNameLabel])
+	}
+	container := job.Spec.Template.Spec.Containers[0]
+	if container.TerminationMessagePath != "/reports/junit.xml" {
+		t.Errorf("Got termination message path %s wanted /reports/junit.xml", container.TerminationMessagePath)
+	}
+	if job.Spec.Template.Spec.RestartPolicy != corev1.RestartPolicyNever {
+		t.Errorf("Got restart policy %s wanted %s", job.Spec.Template.Spec.RestartPolicy, corev1.RestartPolicyNever)
+	}
+
+	// the analysis waits for the job
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.CanaryWeight != 0 || c.Status.FailedChecks != 0 {
+		t.Fatalf("Got canary weight %v failed checks %v wanted 0 0", c.Status.CanaryWeight, c.Status.FailedChecks)
+	}
+
+	// complete the job
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	_, err = mocks.kubeClient.BatchV1().Jobs("default").Update(&job)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name + "-abcde",
+			Namespace: "default",
+			Labels:    map[string]string{"job-name": job.Name},
+		},
+		Spec: job.Spec.Template.Spec,
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "test",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{Message: "<testsuite tests=\"2\"/>"},
+				},
+			}},
+		},
+	}
+	_, err = mocks.kubeClient.CoreV1().Pods("default").Create(pod)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.CanaryWeight != 10 {
+		t.Errorf("Got canary weight %v wanted %v", c.Status.CanaryWeight, 10)
+	}
+
+	cm, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(hookArtifactsName(c), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if cm.Data["e2e.log"] != "2 tests passed" {
+		t.Errorf("Got logs %q wanted %q", cm.Data["e2e.log"], "2 tests passed")
+	}
+	if cm.Data["e2e.result"] != "<testsuite tests=\"2\"/>" {
+		t.Errorf("Got result %q wanted %q", cm.Data["e2e.result"], "<testsuite tests=\"2\"/>")
+	}
+	var artifact HookArtifact
+	if err := json.Unmarshal([]byte(cm.Data["e2e.json"]), &artifact); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !artifact.Succeeded || artifact.Job != job.Name || artifact.Type != string(flaggerv1.PreRolloutHook) {
+		t.Errorf("Got artifact %+v", artifact)
+	}
+}
+
+func TestScheduler_PreRolloutJobInvalidName(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.Webhooks = append(cd.Spec.CanaryAnalysis.Webhooks, flaggerv1.CanaryWebhook{
+		Name: "e2e_tests",
+		Type: flaggerv1.PreRolloutHook,
+		Job: &flaggerv1.CanaryWebhookJob{
+			Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "test", Image: "test-runner"}},
+					},
+				},
+			},
+		},
+	})
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// the pre-rollout check fails without creating the job
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	jobs, err := mocks.kubeClient.BatchV1().Jobs("default").List(metav1.ListOptions{LabelSelector: hookJobCanaryLabel + "=podinfo"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(jobs.Items) != 0 {
+		t.Fatalf("Got %d jobs wanted 0", len(jobs.Items))
+	}
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.CanaryWeight != 0 || c.Status.FailedChecks != 1 {
+		t.Errorf("Got canary weight %v failed checks %v wanted 0 1", c.Status.CanaryWeight, c.Status.FailedChecks)
+	}
+}
+
+func TestFitHookArtifacts(t *testing.T) {
+	artifact := func(completion time.Time) string {
+		out, _ := json.Marshal(HookArtifact{CompletionTime: metav1.NewTime(completion)})
+		return string(out)
+	}
+	now := time.Now()
+	data := map[string]string{
+		"old.json":   artifact(now.Add(-2 * time.Hour)),
+		"old.log":    strings.Repeat("o", maxHookLogBytes),
+		"old.result": "<testsuite/>",
+		"prev.json":  artifact(now.Add(-time.Hour)),
+		"prev.log":   strings.Repeat("p", maxHookLogBytes),
+		"other.json": artifact(now.Add(-30 * time.Minute)),
+		"other.log":  strings.Repeat("x", maxHookLogBytes),
+		"e2e.json":   artifact(now),
+		"e2e.log":    strings.Repeat("e", maxHookLogBytes),
+		"e2e.result": "<testsuite/>",
+		"small.json": artifact(now.Add(-3 * time.Hour)),
+		"small.log":  "ok",
+	}
+
+	fitHookArtifacts(data, "e2e")
+
+	if size := hookArtifactsSize(data); size > maxHookArtifactsBytes {
+		t.Fatalf("Got artifacts size %d wanted at most %d", size, maxHookArtifactsBytes)
+	}
+	for _, key := range []string{"small.json", "small.log", "old.json", "old.log", "old.result"} {
+		if _, ok := data[key]; ok {
+			t.Errorf("Got %s wanted it evicted", key)
+		}
+	}
+	for _, key := range []string{"prev.log", "other.log", "e2e.log", "e2e.result"} {
+		if _, ok := data[key]; !ok {
+			t.Errorf("Got %s evicted wanted it kept", key)
+		}
+	}
+
+	// the logs of the current hook are truncated when they don't fit on their own
+	data = map[string]string{
+		"e2e.json": artifact(now),
+		"e2e.log":  strings.Repeat("e", 2*maxHookArtifactsBytes),
+	}
+	fitHookArtifacts(data, "e2e")
+	if size := hookArtifactsSize(data); size > maxHookArtifactsBytes {
+		t.Fatalf("Got artifacts size %d wanted at most %d", size, maxHookArtifactsBytes)
+	}
+	if !strings.HasPrefix(data["e2e.log"], hookLogTruncatedMarker) {
+		t.Errorf("Got logs without the truncated marker")
+	}
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_DeploymentHotfix(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update with a revision marked as hotfix
+	dep2 := newDeploymentTestDeploymentV2()
+	dep2.Spec.Template.Annotations = map[string]string{flaggerv1.HotfixAnnotation: "INC-1234"}
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	// promote
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseSucceeded {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseSucceeded)
+	}
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if image := primary.Spec.Template.Spec.Containers[0].Image; image != "quay.io/stefanprodan/podinfo:1.2.1" {
+		t.Errorf("Got primary image %v wanted %v", image, "quay.io/stefanprodan/podinfo:1.2.1")
+	}
+
+	// the next revision keeping the hotfix annotation is analysed
+	dep3, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	dep3.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.2"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(dep3)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing || c.Status.CanaryWeight == 0 {
+		t.Errorf("Got canary state %v weight %v wanted %v with traffic", c.Status.Phase, c.Status.CanaryWeight,
+			flaggerv1.CanaryPhaseProgressing)
+	}
+}
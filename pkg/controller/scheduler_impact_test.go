@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"testing"
+
+	hpav2 "k8s.io/api/autoscaling/v2beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func startImpactTestCanary(t *testing.T, mocks fixture) *flaggerv1.Canary {
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// start the analysis
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return c
+}
+
+func TestScheduler_ImpactCheckHalt(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	// the envoy template returns 100 req/s, the first step routes 10 req/s to a single canary replica
+	cd.Spec.CanaryAnalysis.ImpactCheck = &flaggerv1.CanaryImpactCheck{
+		RequestRate:           &flaggerv1.CrossNamespaceObjectReference{Name: "envoy"},
+		MaxRequestsPerReplica: toFloatPtr(5),
+	}
+	mocks := newDeploymentFixture(cd)
+
+	c := startImpactTestCanary(t, mocks)
+	if c.Status.CanaryWeight != 0 || c.Status.FailedChecks != 1 {
+		t.Errorf("Got canary weight %v failed checks %v wanted 0 1", c.Status.CanaryWeight, c.Status.FailedChecks)
+	}
+}
+
+func TestScheduler_ImpactCheckPass(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.ImpactCheck = &flaggerv1.CanaryImpactCheck{
+		RequestRate:           &flaggerv1.CrossNamespaceObjectReference{Name: "envoy"},
+		MaxRequestsPerReplica: toFloatPtr(50),
+	}
+	mocks := newDeploymentFixture(cd)
+
+	c := startImpactTestCanary(t, mocks)
+	if c.Status.CanaryWeight != 10 || c.Status.FailedChecks != 0 {
+		t.Errorf("Got canary weight %v failed checks %v wanted 10 0", c.Status.CanaryWeight, c.Status.FailedChecks)
+	}
+}
+
+func TestScheduler_ImpactCheckAlert(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.ImpactCheck = &flaggerv1.CanaryImpactCheck{
+		Action:                flaggerv1.ImpactCheckAlert,
+		RequestRate:           &flaggerv1.CrossNamespaceObjectReference{Name: "envoy"},
+		MaxRequestsPerReplica: toFloatPtr(5),
+	}
+	mocks := newDeploymentFixture(cd)
+
+	c := startImpactTestCanary(t, mocks)
+	if c.Status.CanaryWeight != 10 || c.Status.FailedChecks != 0 {
+		t.Errorf("Got canary weight %v failed checks %v wanted 10 0", c.Status.CanaryWeight, c.Status.FailedChecks)
+	}
+}
+
+func TestScheduler_ImpactCheckHPA(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.StepWeight = 50
+	cd.Spec.CanaryAnalysis.ImpactCheck = &flaggerv1.CanaryImpactCheck{}
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// the primary runs 4 replicas at the HPA target, half of the load requires 2 canary replicas
+	hpa, err := mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	utilization := int32(99)
+	hpa.Status = hpav2.HorizontalPodAutoscalerStatus{
+		CurrentReplicas: 4,
+		CurrentMetrics: []hpav2.MetricStatus{{
+			Type: hpav2.ResourceMetricSourceType,
+			Resource: &hpav2.ResourceMetricStatus{
+				Name:                      "cpu",
+				CurrentAverageUtilization: &utilization,
+			},
+		}},
+	}
+	_, err = mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Update(hpa)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	c := startImpactTestCanary(t, mocks)
+	if c.Status.CanaryWeight != 0 || c.Status.FailedChecks != 1 {
+		t.Errorf("Got canary weight %v failed checks %v wanted 0 1", c.Status.CanaryWeight, c.Status.FailedChecks)
+	}
+}
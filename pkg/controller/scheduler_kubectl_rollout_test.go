@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// startRolloutTest advances the canary analysis of the v2 deployment to the first step
+func startRolloutTest(t *testing.T) fixture {
+	mocks := newDeploymentFixture(nil)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// advance to the first step
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	return mocks
+}
+
+func setRolloutTestPaused(t *testing.T, mocks fixture, paused bool) {
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	depCopy := dep.DeepCopy()
+	depCopy.Spec.Paused = paused
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(depCopy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestScheduler_KubectlRolloutPauseResume(t *testing.T) {
+	mocks := startRolloutTest(t)
+
+	// kubectl rollout pause
+	setRolloutTestPaused(t, mocks, true)
+	for i := 0; i < 2; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+	}
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseWaiting || c.Status.CanaryWeight != 10 {
+		t.Fatalf("Got canary phase %v weight %v wanted %v %v",
+			c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseWaiting, 10)
+	}
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !dep.Spec.Paused {
+		t.Error("Canary deployment was resumed by Flagger")
+	}
+
+	// kubectl rollout resume
+	setRolloutTestPaused(t, mocks, false)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing {
+		t.Fatalf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseProgressing)
+	}
+
+	// the analysis continues from the paused step
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.CanaryWeight != 20 {
+		t.Errorf("Got canary weight %v wanted %v", c.Status.CanaryWeight, 20)
+	}
+}
+
+func TestScheduler_KubectlRolloutUndo(t *testing.T) {
+	mocks := startRolloutTest(t)
+
+	// kubectl rollout undo
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	depCopy := dep.DeepCopy()
+	depCopy.Spec.Template = newDeploymentTestDeployment().Spec.Template
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(depCopy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseFailed || c.Status.CanaryWeight != 0 {
+		t.Fatalf("Got canary phase %v weight %v wanted %v %v",
+			c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseFailed, 0)
+	}
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if primaryWeight != 100 || canaryWeight != 0 {
+		t.Errorf("Got primary weight %v canary weight %v wanted %v %v", primaryWeight, canaryWeight, 100, 0)
+	}
+
+	// the reverted revision is not analysed
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseFailed {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseFailed)
+	}
+}
+
+func TestScheduler_KubectlRolloutResumeQueued(t *testing.T) {
+	mocks := startRevisionTest(t, flaggerv1.RevisionPolicyQueue)
+
+	// kubectl rollout resume rolls out the queued v3 revision
+	setRolloutTestPaused(t, mocks, false)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.CanaryWeight != 0 {
+		t.Errorf("Got canary weight %v wanted %v", c.Status.CanaryWeight, 0)
+	}
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if dep.Spec.Paused {
+		t.Error("Canary deployment was paused again")
+	}
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newMetricGroupsTestCanary(errorRateThreshold float64, saturationThreshold float64) *flaggerv1.Canary {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.MetricGroups = []flaggerv1.CanaryMetricGroup{
+		{Name: "slo", Operator: flaggerv1.MetricGroupAnd},
+	}
+	// the fake metrics server returns 100 for every query
+	cd.Spec.CanaryAnalysis.Metrics = []flaggerv1.CanaryMetric{
+		{Name: "error-rate", Query: "errors", Threshold: errorRateThreshold, Interval: "1m", Group: "slo"},
+		{Name: "saturation", Query: "saturation", Threshold: saturationThreshold, Interval: "1m", Group: "slo"},
+	}
+	return cd
+}
+
+func TestScheduler_MetricGroupsAnd(t *testing.T) {
+	mocks := newDeploymentFixture(newMetricGroupsTestCanary(50, 200))
+	if ok := mocks.ctrl.runAnalysis(mocks.canary); !ok {
+		t.Errorf("Expected the analysis to pass when one metric of an and group fails")
+	}
+
+	mocks = newDeploymentFixture(newMetricGroupsTestCanary(50, 50))
+	if ok := mocks.ctrl.runAnalysis(mocks.canary); ok {
+		t.Errorf("Expected the analysis to fail when all metrics of an and group fail")
+	}
+}
+
+func TestScheduler_MetricGroupsOr(t *testing.T) {
+	cd := newMetricGroupsTestCanary(50, 200)
+	cd.Spec.CanaryAnalysis.MetricGroups[0].Operator = flaggerv1.MetricGroupOr
+
+	mocks := newDeploymentFixture(cd)
+	if ok := mocks.ctrl.runAnalysis(mocks.canary); ok {
+		t.Errorf("Expected the analysis to fail when any metric of an or group fails")
+	}
+}
+
+func TestScheduler_MetricGroupsUndefined(t *testing.T) {
+	cd := newMetricGroupsTestCanary(200, 200)
+	cd.Spec.CanaryAnalysis.MetricGroups = nil
+
+	mocks := newDeploymentFixture(cd)
+	if ok := mocks.ctrl.runAnalysis(mocks.canary); ok {
+		t.Errorf("Expected the analysis to fail for an undefined metric group")
+	}
+}
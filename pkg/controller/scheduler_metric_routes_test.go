@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+func TestScheduler_MetricRoutes(t *testing.T) {
+	cd := newDeploymentTestCanaryAB()
+	cd.Spec.Service.Match = []istiov1alpha3.HTTPMatchRequest{
+		{Uri: &istiov1alpha1.StringMatch{Prefix: "/api/checkout/"}},
+	}
+	cd.GetAnalysis().Match = append(cd.GetAnalysis().Match, istiov1alpha3.HTTPMatchRequest{
+		Uri: &istiov1alpha1.StringMatch{Exact: "/cart.json"},
+	})
+
+	// the routes default to the URI match conditions
+	model := toMetricModel(cd, flaggerv1.CanaryMetric{Name: "request-success-rate", Interval: "1m"})
+	if model.Route != `/api/checkout/.*|/cart\.json` {
+		t.Errorf("Got route %s wanted %s", model.Route, `/api/checkout/.*|/cart\.json`)
+	}
+
+	// the metric routes take precedence over the match conditions
+	model = toMetricModel(cd, flaggerv1.CanaryMetric{
+		Name:       "request-success-rate",
+		Interval:   "1m",
+		Routes:     []istiov1alpha1.StringMatch{{Regex: "/orders/[0-9]+"}, {Suffix: ".html"}},
+		RouteLabel: "path",
+	})
+	if model.Route != `/orders/[0-9]+|.*\.html` || model.RouteLabel != "path" {
+		t.Errorf("Got route %s label %s", model.Route, model.RouteLabel)
+	}
+
+	// the canary match conditions are not modified
+	if len(cd.Spec.Service.Match) != 1 {
+		t.Errorf("Got service match %v wanted %v", len(cd.Spec.Service.Match), 1)
+	}
+
+	// not scoped without URI conditions
+	model = toMetricModel(newDeploymentTestCanary(), flaggerv1.CanaryMetric{Name: "request-success-rate", Interval: "1m"})
+	if model.Route != "" {
+		t.Errorf("Got route %s wanted empty", model.Route)
+	}
+}
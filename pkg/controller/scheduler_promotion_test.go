@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_StepWeightPromotion(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.StepWeightPromotion = 20
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes, advance to 50% and promote
+	for i := 0; i < 7; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+	}
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhasePromoting {
+		t.Fatalf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhasePromoting)
+	}
+
+	// shift the traffic to the promoted primary in steps
+	for _, weight := range []int{30, 10} {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+		primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if canaryWeight != weight || primaryWeight != 100-weight {
+			t.Fatalf("Got weights %v/%v wanted %v/%v", primaryWeight, canaryWeight, 100-weight, weight)
+		}
+
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if c.Status.Phase != flaggerv1.CanaryPhasePromoting || c.Status.CanaryWeight != weight {
+			t.Fatalf("Got canary phase %v weight %v wanted %v %v",
+				c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhasePromoting, weight)
+		}
+	}
+
+	// route all traffic to primary when the last step is below the promotion step
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if primaryWeight != 100 || canaryWeight != 0 {
+		t.Errorf("Got weights %v/%v wanted %v/%v", primaryWeight, canaryWeight, 100, 0)
+	}
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseFinalising {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseFinalising)
+	}
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newRatioTestCanary(minDenominator float64, tr flaggerv1.CanaryThresholdRange) *flaggerv1.Canary {
+	cd := newDeploymentTestCanary()
+	// the envoy template provider is fake and returns 100 for every query
+	cd.Spec.CanaryAnalysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name: "conversion-rate",
+			Ratio: &flaggerv1.CanaryMetricRatio{
+				Numerator:      flaggerv1.CrossNamespaceObjectReference{Name: "envoy"},
+				Denominator:    flaggerv1.CrossNamespaceObjectReference{Name: "envoy", Namespace: "default"},
+				MinDenominator: minDenominator,
+			},
+			ThresholdRange: &tr,
+			Interval:       "1m",
+		},
+	}
+	return cd
+}
+
+func TestScheduler_RatioMetric(t *testing.T) {
+	min := 0.5
+	mocks := newDeploymentFixture(newRatioTestCanary(10, flaggerv1.CanaryThresholdRange{Min: &min}))
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); !ok {
+		t.Errorf("Expected the ratio metric check to pass")
+	}
+
+	results := mocks.ctrl.GetMetricResults(mocks.canary.Name, mocks.canary.Namespace)
+	if len(results) != 1 || results[0].Value != 1 {
+		t.Errorf("Got results %v wanted conversion-rate 1", results)
+	}
+
+	max := 0.5
+	mocks = newDeploymentFixture(newRatioTestCanary(10, flaggerv1.CanaryThresholdRange{Max: &max}))
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the ratio metric check to fail")
+	}
+}
+
+func TestScheduler_RatioMetricMinDenominator(t *testing.T) {
+	min := 0.5
+	mocks := newDeploymentFixture(newRatioTestCanary(1000, flaggerv1.CanaryThresholdRange{Min: &min}))
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the ratio metric check to halt below the minimum denominator")
+	}
+
+	if results := mocks.ctrl.GetMetricResults(mocks.canary.Name, mocks.canary.Namespace); len(results) != 0 {
+		t.Errorf("Got results %v wanted none", results)
+	}
+}
+
+func TestScheduler_RatioMetricTemplateNotFound(t *testing.T) {
+	min := 0.5
+	cd := newRatioTestCanary(0, flaggerv1.CanaryThresholdRange{Min: &min})
+	cd.Spec.CanaryAnalysis.Metrics[0].Ratio.Numerator.Name = "missing"
+	mocks := newDeploymentFixture(cd)
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the ratio metric check to fail for a missing template")
+	}
+}
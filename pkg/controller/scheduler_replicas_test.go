@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestScheduler_ProportionalReplicas(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.ProportionalReplicas = true
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// run the primary with ten replicas
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	primaryCopy := primary.DeepCopy()
+	primaryCopy.Spec.Replicas = int32p(10)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(primaryCopy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// the canary replicas follow the weight steps
+	for _, expected := range []int32{1, 2, 3, 4, 5} {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+		assertCanaryTestReplicas(t, mocks, expected)
+	}
+}
+
+func TestProportionalReplicas(t *testing.T) {
+	tests := []struct {
+		primary  int32
+		weight   int
+		replicas int32
+	}{
+		{primary: 10, weight: 0, replicas: 1},
+		{primary: 10, weight: 10, replicas: 1},
+		{primary: 10, weight: 25, replicas: 3},
+		{primary: 3, weight: 50, replicas: 2},
+		{primary: 4, weight: 100, replicas: 4},
+	}
+	for _, tt := range tests {
+		if replicas := proportionalReplicas(tt.primary, tt.weight); replicas != tt.replicas {
+			t.Errorf("Got %v replicas for %v primary replicas at weight %v wanted %v", replicas, tt.primary, tt.weight, tt.replicas)
+		}
+	}
+}
+
+func assertCanaryTestReplicas(t *testing.T, mocks fixture, expected int32) {
+	t.Helper()
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if dep.Spec.Replicas == nil || *dep.Spec.Replicas != expected {
+		t.Errorf("Got canary replicas %v wanted %v", dep.Spec.Replicas, expected)
+	}
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	revisionTestImage                = "quay.io/stefanprodan/podinfo:1.2.2"
+	deploymentRevisionTestAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// startRevisionTest advances the canary analysis of the v2 deployment to the first step
+// and creates the replica set of the v2 revision that runs on the paused canary pods
+func startRevisionTest(t *testing.T, policy string) fixture {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.RevisionPolicy = policy
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	dep2.Annotations = map[string]string{deploymentRevisionTestAnnotation: "2"}
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo-v2",
+			Labels:    dep2.Spec.Template.Labels,
+			Annotations: map[string]string{
+				deploymentRevisionTestAnnotation: "2",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(dep2, appsv1.SchemeGroupVersion.WithKind("Deployment")),
+			},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Selector: dep2.Spec.Selector,
+			Template: *dep2.Spec.Template.DeepCopy(),
+		},
+	}
+	rs.Spec.Template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = "v2"
+	_, err = mocks.kubeClient.AppsV1().ReplicaSets("default").Create(rs)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// advance to the first step
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if paused := policy != flaggerv1.RevisionPolicyRestart; dep.Spec.Paused != paused {
+		t.Fatalf("Got canary deployment paused %v wanted %v", dep.Spec.Paused, paused)
+	}
+
+	// apply v3
+	depCopy := dep.DeepCopy()
+	depCopy.Spec.Template.Spec.Containers[0].Image = revisionTestImage
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(depCopy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return mocks
+}
+
+func TestScheduler_RevisionPolicyQueue(t *testing.T) {
+	mocks := startRevisionTest(t, flaggerv1.RevisionPolicyQueue)
+
+	// the analysis continues with the v3 revision queued
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing || c.Status.CanaryWeight != 20 {
+		t.Fatalf("Got canary phase %v weight %v wanted %v %v",
+			c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseProgressing, 20)
+	}
+
+	// advance to 50% and promote
+	for i := 0; i < 4; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+	}
+
+	// the analysed revision is promoted
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if image := primary.Spec.Template.Spec.Containers[0].Image; image != "quay.io/stefanprodan/podinfo:1.2.1" {
+		t.Errorf("Got primary image %v wanted %v", image, "quay.io/stefanprodan/podinfo:1.2.1")
+	}
+
+	// route traffic to primary and finalise
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseSucceeded {
+		t.Fatalf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseSucceeded)
+	}
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if dep.Spec.Paused {
+		t.Error("Canary deployment is still paused")
+	}
+
+	// the queued revision is analysed next
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseProgressing)
+	}
+}
+
+func TestScheduler_RevisionPolicyReject(t *testing.T) {
+	mocks := startRevisionTest(t, flaggerv1.RevisionPolicyReject)
+
+	// the v3 revision is reverted and the analysis continues
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if image := dep.Spec.Template.Spec.Containers[0].Image; image != "quay.io/stefanprodan/podinfo:1.2.1" {
+		t.Errorf("Got canary image %v wanted %v", image, "quay.io/stefanprodan/podinfo:1.2.1")
+	}
+	if _, ok := dep.Spec.Template.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok {
+		t.Errorf("Got replica set label %v in the canary template", appsv1.DefaultDeploymentUniqueLabelKey)
+	}
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing || c.Status.CanaryWeight != 20 {
+		t.Errorf("Got canary phase %v weight %v wanted %v %v",
+			c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseProgressing, 20)
+	}
+}
+
+func TestScheduler_RevisionPolicyRestart(t *testing.T) {
+	mocks := startRevisionTest(t, flaggerv1.RevisionPolicyRestart)
+
+	// the analysis is restarted for the v3 revision
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.CanaryWeight != 0 {
+		t.Errorf("Got canary weight %v wanted %v", c.Status.CanaryWeight, 0)
+	}
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_RollbackDuration(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update failed checks to max
+	err := mocks.deployer.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, FailedChecks: 10})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// rollback
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseFailed {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseFailed)
+	}
+	if c.Status.LastRollbackDuration == nil || c.Status.LastRollbackDuration.Duration > time.Minute {
+		t.Errorf("Got last rollback duration %v wanted less than a minute", c.Status.LastRollbackDuration)
+	}
+	if c.Status.RollbackStartTime != nil {
+		t.Errorf("Got rollback start time %v wanted nil", c.Status.RollbackStartTime)
+	}
+}
+
+func TestScheduler_SteppedRollbackDuration(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.RollbackStrategy = flaggerv1.RollbackStrategyStepped
+	cd.Spec.CanaryAnalysis.MaxRollbackTime = "5m"
+	mocks := newDeploymentFixture(cd)
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update failed checks to max with 20% of the traffic routed to the canary
+	err := mocks.deployer.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, FailedChecks: 10, CanaryWeight: 20})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := mocks.router.SetRoutes(c, 80, 20, false); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the failure detection time is kept while rolling back
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseRollingBack || c.Status.RollbackStartTime == nil {
+		t.Fatalf("Got canary state %v rollback start %v wanted %v with a start time",
+			c.Status.Phase, c.Status.RollbackStartTime, flaggerv1.CanaryPhaseRollingBack)
+	}
+
+	// simulate a rollback that started ten minutes ago
+	cdCopy := c.DeepCopy()
+	cdCopy.Status.RollbackStartTime = &metav1.Time{Time: time.Now().Add(-10 * time.Minute)}
+	if _, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(cdCopy); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the last step routes all traffic to primary
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseFailed {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseFailed)
+	}
+	if c.Status.LastRollbackDuration == nil || c.Status.LastRollbackDuration.Duration < 10*time.Minute {
+		t.Errorf("Got last rollback duration %v wanted at least 10m", c.Status.LastRollbackDuration)
+	}
+	if c.Status.RollbackStartTime != nil {
+		t.Errorf("Got rollback start time %v wanted nil", c.Status.RollbackStartTime)
+	}
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduler_AnalysisSchedule(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.Interval = "1d"
+	cd.Spec.CanaryAnalysis.Schedule = "0 9 * * mon-fri"
+	cd.Spec.CanaryAnalysis.Timezone = "UTC"
+
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.jobs = map[string]CanaryJob{}
+	mocks.ctrl.canaries.Store("podinfo.default", cd)
+	defer func() {
+		for _, job := range mocks.ctrl.jobs {
+			job.Stop()
+		}
+	}()
+
+	mocks.ctrl.scheduleCanaries()
+
+	job, ok := mocks.ctrl.jobs["podinfo.default"]
+	if !ok {
+		t.Fatal("Expected the canary job to be scheduled")
+	}
+	if job.cron == nil || job.ticker != nil {
+		t.Errorf("Expected the job to run on the cron schedule")
+	}
+	if job.GetCanaryAnalysisInterval() != 24*time.Hour {
+		t.Errorf("Got interval %v wanted %v", job.GetCanaryAnalysisInterval(), 24*time.Hour)
+	}
+
+	// an invalid schedule falls back to the interval
+	invalid := cd.DeepCopy()
+	invalid.Spec.CanaryAnalysis.Schedule = "0 25 * * *"
+	mocks.ctrl.canaries.Store("podinfo.default", invalid)
+	mocks.ctrl.scheduleCanaries()
+
+	job = mocks.ctrl.jobs["podinfo.default"]
+	if job.cron != nil || job.ticker == nil {
+		t.Errorf("Expected the job to be rescheduled on the interval")
+	}
+}
+
+func TestScheduler_AnalysisDelay(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.Interval = "1m"
+
+	mocks := newDeploymentFixture(cd)
+
+	// no delay by default
+	offset, jitter := mocks.ctrl.analysisDelay(cd, time.Now())
+	if offset != 0 || jitter != 0 {
+		t.Errorf("Got offset %v jitter %v wanted none", offset, jitter)
+	}
+
+	mocks.ctrl.analysisJitter = 0.1
+	mocks.ctrl.analysisSpread = true
+
+	// the runs land on the same slot of the interval whenever the job starts
+	start := time.Date(2020, 1, 1, 10, 0, 12, 0, time.UTC)
+	offset, jitter = mocks.ctrl.analysisDelay(cd, start)
+	if jitter != 6*time.Second {
+		t.Errorf("Got jitter %v wanted %v", jitter, 6*time.Second)
+	}
+	if offset < 0 || offset >= time.Minute {
+		t.Fatalf("Got offset %v wanted less than the interval", offset)
+	}
+	slot := start.Add(offset).UnixNano() % int64(time.Minute)
+
+	restart := start.Add(7 * time.Second)
+	offset, _ = mocks.ctrl.analysisDelay(cd, restart)
+	if s := restart.Add(offset).UnixNano() % int64(time.Minute); s != slot {
+		t.Errorf("Got slot %v after restart wanted %v", time.Duration(s), time.Duration(slot))
+	}
+
+	// the cron runs are already aligned
+	offset, _ = mocks.ctrl.analysisDelay(cd, time.Time{})
+	if int64(offset) != slot {
+		t.Errorf("Got cron offset %v wanted %v", offset, time.Duration(slot))
+	}
+}
+
+func TestCanaryJob_DelayRun(t *testing.T) {
+	runs := make(chan bool, 2)
+	job := CanaryJob{
+		function: func(name string, namespace string, skipTests bool) {
+			runs <- true
+		},
+		offset: time.Hour,
+	}
+
+	// the run is delayed by the offset
+	pending := job.delayRun(nil)
+	if pending == nil {
+		t.Fatal("Expected the run to be delayed")
+	}
+	if len(runs) != 0 {
+		t.Fatalf("Got %v runs wanted none", len(runs))
+	}
+
+	// a pending run is executed when the next one is due
+	next := job.delayRun(pending)
+	defer next.Stop()
+	if len(runs) != 1 {
+		t.Errorf("Got %v runs wanted 1", len(runs))
+	}
+
+	// no delay without offset and jitter
+	job.offset = 0
+	if job.delayRun(nil) != nil || len(runs) != 2 {
+		t.Errorf("Expected the run to be executed right away")
+	}
+}
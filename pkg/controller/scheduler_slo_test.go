@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// newSLOTestServer returns a Prometheus API serving the error ratios of the fast and slow windows
+func newSLOTestServer(fast float64, slow float64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		val := slow
+		if strings.Contains(r.URL.Query().Get("query"), "[5m]") {
+			val = fast
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"%v"]}]}}`, val)
+	}))
+}
+
+func newSLOTestFixture(address string, slo flaggerv1.CanaryMetricSLO) fixture {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name:        "error-budget",
+			TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "error-ratio"},
+			SLO:         &slo,
+			Interval:    "1m",
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(&flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "error-ratio",
+		},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{
+				Type:    "prometheus",
+				Address: address,
+			},
+			Query: `sum(rate(http_requests_total{pod=~"{{ target }}-.*",status=~"5.."}[{{ interval }}])) / sum(rate(http_requests_total{pod=~"{{ target }}-.*"}[{{ interval }}]))`,
+		},
+	})
+	return mocks
+}
+
+func TestScheduler_SLOMetric(t *testing.T) {
+	tests := []struct {
+		name   string
+		fast   float64
+		slow   float64
+		passed bool
+	}{
+		{name: "within budget", fast: 0.001, slow: 0.001, passed: true},
+		{name: "fast burn", fast: 0.02, slow: 0.001, passed: false},
+		{name: "slow burn", fast: 0.005, slow: 0.007, passed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newSLOTestServer(tt.fast, tt.slow)
+			defer ts.Close()
+
+			// a 99.9% objective leaves a 0.1% error budget
+			mocks := newSLOTestFixture(ts.URL, flaggerv1.CanaryMetricSLO{Objective: 99.9})
+			if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok != tt.passed {
+				t.Errorf("Got metric check %v wanted %v", ok, tt.passed)
+			}
+
+			results := mocks.ctrl.GetMetricResults(mocks.canary.Name, mocks.canary.Namespace)
+			if len(results) != 1 || results[0].Passed != tt.passed {
+				t.Fatalf("Got results %v wanted passed %v", results, tt.passed)
+			}
+			if burnRate := tt.fast / 0.001; results[0].Value < burnRate-0.001 || results[0].Value > burnRate+0.001 {
+				t.Errorf("Got fast burn rate %v wanted %v", results[0].Value, burnRate)
+			}
+		})
+	}
+}
+
+func TestScheduler_SLOMetricBurnRates(t *testing.T) {
+	ts := newSLOTestServer(0.02, 0.007)
+	defer ts.Close()
+
+	mocks := newSLOTestFixture(ts.URL, flaggerv1.CanaryMetricSLO{Objective: 99.9, FastBurnRate: 30, SlowBurnRate: 10})
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); !ok {
+		t.Errorf("Expected the SLO metric check to pass below the max burn rates")
+	}
+
+	mocks = newSLOTestFixture(ts.URL, flaggerv1.CanaryMetricSLO{Objective: 100})
+	if ok := mocks.ctrl.runMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the SLO metric check to fail without error budget")
+	}
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+func TestScheduler_StateStore(t *testing.T) {
+	cd := newDeploymentTestCanaryMirror()
+	cd.Spec.CanaryAnalysis.MirrorIterations = 2
+	mocks := newDeploymentFixture(cd)
+	store := &canary.MemoryStateStore{}
+	configTracker := &canary.ConfigTracker{
+		Logger:        mocks.logger,
+		KubeClient:    mocks.kubeClient,
+		FlaggerClient: mocks.flaggerClient,
+	}
+	mocks.ctrl.canaryFactory = canary.NewFactory(mocks.kubeClient, mocks.flaggerClient, configTracker, store, []string{"app", "name"}, canary.PolicyMetadata{}, mocks.logger)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// run the mirror iterations, counted in the state store
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	state, err := store.Load(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if state == nil || state.Iterations != 2 {
+		t.Fatalf("Got state %+v wanted iterations 2", state)
+	}
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if primaryWeight != 90 || canaryWeight != 10 || mirrored {
+		t.Errorf("Got primary=%v canary=%v mirrored=%v wanted primary=90 canary=10 mirrored=false",
+			primaryWeight, canaryWeight, mirrored)
+	}
+}
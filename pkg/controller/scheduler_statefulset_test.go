@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"math"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_StatefulSetPartition(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.TargetRef.Kind = "StatefulSet"
+	mocks := newDeploymentFixture(cd)
+	_, err := mocks.kubeClient.AppsV1().StatefulSets("default").Create(newStatefulSetTestStatefulSet())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// initializing holds the next revision
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertStatefulSetTestPartition(t, mocks, math.MaxInt32)
+
+	// update the pod spec
+	sts, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	stsCopy := sts.DeepCopy()
+	stsCopy.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.1"
+	_, err = mocks.kubeClient.AppsV1().StatefulSets("default").Update(stsCopy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertStatefulSetTestPartition(t, mocks, math.MaxInt32)
+
+	// 10% of 3 pods is rounded up to one canary pod
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertStatefulSetTestPartition(t, mocks, 2)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing || c.Status.CanaryWeight != 10 {
+		t.Errorf("Got canary phase %v weight %v wanted %v 10", c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseProgressing)
+	}
+
+	// advance to the max weight, 50% of 3 pods is rounded up to two canary pods
+	for i := 0; i < 4; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+	}
+	assertStatefulSetTestPartition(t, mocks, 1)
+
+	// promote rolls out the revision to all pods
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertStatefulSetTestPartition(t, mocks, 0)
+
+	// route traffic to primary and finalise
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertStatefulSetTestPartition(t, mocks, 0)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertStatefulSetTestPartition(t, mocks, math.MaxInt32)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseSucceeded {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseSucceeded)
+	}
+}
+
+func assertStatefulSetTestPartition(t *testing.T, mocks fixture, expected int32) {
+	sts, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	ru := sts.Spec.UpdateStrategy.RollingUpdate
+	if ru == nil || ru.Partition == nil || *ru.Partition != expected {
+		t.Errorf("Got rolling update %+v wanted partition %v", ru, expected)
+	}
+}
+
+func newStatefulSetTestStatefulSet() *appsv1.StatefulSet {
+	replicas := int32(3)
+	return &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": "podinfo",
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": "podinfo",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "podinfo",
+							Image: "quay.io/stefanprodan/podinfo:1.2.0",
+						},
+					},
+				},
+			},
+		},
+		// the pods are updated and ready, the fake client doesn't run the StatefulSet controller
+		Status: appsv1.StatefulSetStatus{
+			Replicas:        3,
+			UpdatedReplicas: 3,
+			ReadyReplicas:   3,
+		},
+	}
+}
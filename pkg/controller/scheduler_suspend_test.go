@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func setSuspendTestCanary(t *testing.T, mocks fixture, suspend bool) {
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cdCopy := cd.DeepCopy()
+	cdCopy.Spec.Suspend = suspend
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(cdCopy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestScheduler_Suspend(t *testing.T) {
+	mocks := startRolloutTest(t)
+	setSuspendTestCanary(t, mocks, true)
+
+	// hold the current weight
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if primaryWeight != 90 || canaryWeight != 10 {
+		t.Errorf("Got weights %v/%v wanted 90/10", primaryWeight, canaryWeight)
+	}
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing || c.Status.CanaryWeight != 10 {
+		t.Errorf("Got canary phase %v weight %v wanted %v 10", c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseProgressing)
+	}
+	if e, _ := mocks.ctrl.Explain(c); e.Gate == nil || e.Gate.Reason != haltReasonSuspended {
+		t.Errorf("Got gate %+v wanted %v", e.Gate, haltReasonSuspended)
+	}
+
+	// resume the analysis
+	setSuspendTestCanary(t, mocks, false)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.CanaryWeight != 20 {
+		t.Errorf("Got canary weight %v wanted 20", c.Status.CanaryWeight)
+	}
+}
+
+func TestScheduler_SuspendRolloutUndo(t *testing.T) {
+	mocks := startRolloutTest(t)
+	setSuspendTestCanary(t, mocks, true)
+
+	// kubectl rollout undo
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	depCopy := dep.DeepCopy()
+	depCopy.Spec.Template = newDeploymentTestDeployment().Spec.Template
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(depCopy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseFailed {
+		t.Errorf("Got canary phase %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseFailed)
+	}
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestScheduler_TimeSeries(t *testing.T) {
+	mocks := startRolloutTest(t)
+
+	// run the checks of the first two steps
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	var series *TimeSeries
+	timeSeries := mocks.ctrl.GetTimeSeries("podinfo", "default")
+	for i := range timeSeries {
+		if timeSeries[i].Metric == "request-success-rate" {
+			series = &timeSeries[i]
+		}
+	}
+	if series == nil {
+		t.Fatal("request-success-rate time series not found")
+	}
+	if len(series.Samples) != 2 {
+		t.Fatalf("Got %v samples wanted %v", len(series.Samples), 2)
+	}
+	for i, s := range series.Samples {
+		if s.Primary == nil {
+			t.Errorf("Sample %v primary value not recorded", i)
+		}
+	}
+	if series.Samples[0].CanaryWeight >= series.Samples[1].CanaryWeight {
+		t.Errorf("Got canary weights %v %v wanted increasing values",
+			series.Samples[0].CanaryWeight, series.Samples[1].CanaryWeight)
+	}
+
+	// the samples are reset when a new revision is analysed
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	depCopy := dep.DeepCopy()
+	depCopy.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.2"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(depCopy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	for _, ts := range mocks.ctrl.GetTimeSeries("podinfo", "default") {
+		if len(ts.Samples) > 1 {
+			t.Errorf("Got %v %s samples after restart wanted at most %v", len(ts.Samples), ts.Metric, 1)
+		}
+	}
+}
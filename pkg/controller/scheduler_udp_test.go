@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_UDPService(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Service.Protocol = corev1.ProtocolUDP
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseInitialized {
+		t.Errorf("Got canary state %v wanted %v", c.Status.Phase, flaggerv1.CanaryPhaseInitialized)
+	}
+
+	svc, err := mocks.kubeClient.CoreV1().Services("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if svc.Spec.Ports[0].Protocol != corev1.ProtocolUDP {
+		t.Errorf("Got svc protocol %s wanted %s", svc.Spec.Ports[0].Protocol, corev1.ProtocolUDP)
+	}
+
+	// the Istio routing is skipped for UDP services
+	_, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Expected the virtual service not to be created got %v", err)
+	}
+}
+
+func TestScheduler_DatagramErrorRate(t *testing.T) {
+	// the fake metrics server returns 100 for every query
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name:      "datagram-error-rate",
+			Threshold: 1,
+			Interval:  "1m",
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+	if ok := mocks.ctrl.runBuiltinMetricChecks(mocks.canary, nil); ok {
+		t.Errorf("Expected the datagram error rate check to fail")
+	}
+
+	results := mocks.ctrl.GetMetricResults(mocks.canary.Name, mocks.canary.Namespace)
+	if len(results) != 1 || results[0].Value != 100 {
+		t.Errorf("Got results %v wanted datagram-error-rate 100", results)
+	}
+
+	max := 100.0
+	cd = newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name:           "datagram-error-rate",
+			ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: &max},
+			Interval:       "1m",
+		},
+	}
+	mocks = newDeploymentFixture(cd)
+	if ok := mocks.ctrl.runBuiltinMetricChecks(mocks.canary, nil); !ok {
+		t.Errorf("Expected the datagram error rate check to pass")
+	}
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func getCanaryCondition(cd *flaggerv1.Canary, conditionType flaggerv1.CanaryConditionType) *flaggerv1.CanaryCondition {
+	for i := range cd.Status.Conditions {
+		if cd.Status.Conditions[i].Type == conditionType {
+			return &cd.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func TestScheduler_KongBuiltinMetricsInvalid(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Provider = "kong"
+	mocks := newDeploymentFixture(cd)
+
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	condition := getCanaryCondition(c, flaggerv1.ValidType)
+	if condition == nil || condition.Status != corev1.ConditionFalse || condition.Reason != "Invalid" {
+		t.Fatalf("Got condition %+v wanted Valid false", condition)
+	}
+	if c.Status.Phase == flaggerv1.CanaryPhaseFailed {
+		t.Errorf("Got canary phase %v wanted the analysis not to run", c.Status.Phase)
+	}
+
+	// the invalid canary is not initialized
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get("podinfo-primary", metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Got %v wanted the primary deployment not to be created", err)
+	}
+
+	// replace the builtin metrics with a metric template
+	c.Spec.CanaryAnalysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name:      "success-rate",
+			Threshold: 99,
+			Interval:  "1m",
+			TemplateRef: &flaggerv1.CrossNamespaceObjectReference{
+				Name:      "envoy",
+				Namespace: "default",
+			},
+		},
+	}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	condition = getCanaryCondition(c, flaggerv1.ValidType)
+	if condition == nil || condition.Status != corev1.ConditionTrue {
+		t.Errorf("Got condition %+v wanted Valid true", condition)
+	}
+}
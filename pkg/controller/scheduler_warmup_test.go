@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_WarmupIterations(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.WarmupIterations = 3
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes and advance to the first step
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// pass the first warmup check
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertWarmupTestCanary(t, mocks, 10, 1)
+
+	// a failed check restarts the warmup
+	setFireDrillTestCanary(t, mocks, true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertWarmupTestCanary(t, mocks, 10, 0)
+
+	// pass the warmup checks
+	setFireDrillTestCanary(t, mocks, false)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertWarmupTestCanary(t, mocks, 10, 1)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertWarmupTestCanary(t, mocks, 10, 2)
+
+	// increase the traffic after three consecutive passing checks
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertWarmupTestCanary(t, mocks, 20, 2)
+
+	// the next steps are not held
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	assertWarmupTestCanary(t, mocks, 30, 2)
+}
+
+func TestScheduler_WarmupDuration(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.CanaryAnalysis.WarmupDuration = "10m"
+	// the fake metrics server returns 100, the custom metric check fails
+	max := 50.0
+	cd.Spec.CanaryAnalysis.Metrics[2].ThresholdRange.Max = &max
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes and advance to the first step
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// the failed checks are not counted during the warmup and the weight is held
+	for i := 0; i < 3; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default", true)
+	}
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.CanaryWeight != 10 || c.Status.FailedChecks != 0 {
+		t.Errorf("Got canary weight %v failed checks %v wanted %v %v", c.Status.CanaryWeight, c.Status.FailedChecks, 10, 0)
+	}
+
+	// the failed checks are counted after the warmup
+	mocks.ctrl.warmupStarts.items.Store("podinfo.default", time.Now().Add(-11*time.Minute))
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.CanaryWeight != 10 || c.Status.FailedChecks != 1 {
+		t.Errorf("Got canary weight %v failed checks %v wanted %v %v", c.Status.CanaryWeight, c.Status.FailedChecks, 10, 1)
+	}
+}
+
+func setFireDrillTestCanary(t *testing.T, mocks fixture, enabled bool) {
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cdCopy := cd.DeepCopy()
+	cdCopy.Spec.CanaryAnalysis.FireDrill = enabled
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(cdCopy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func assertWarmupTestCanary(t *testing.T, mocks fixture, weight int, iterations int) {
+	t.Helper()
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing || c.Status.CanaryWeight != weight || c.Status.Iterations != iterations {
+		t.Fatalf("Got canary phase %v weight %v iterations %v wanted %v %v %v",
+			c.Status.Phase, c.Status.CanaryWeight, c.Status.Iterations, flaggerv1.CanaryPhaseProgressing, weight, iterations)
+	}
+}
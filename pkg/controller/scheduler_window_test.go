@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_AnalysisWindows(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	// the 30th of February never comes
+	cd.Spec.CanaryAnalysis.Windows = []string{"* * 30 2 *"}
+	mocks := newDeploymentFixture(cd)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(dep2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// detect changes and wait outside the windows
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseWaiting || c.Status.CanaryWeight != 0 {
+		t.Fatalf("Got canary phase %v weight %v wanted %v %v", c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseWaiting, 0)
+	}
+	if e, _ := mocks.ctrl.Explain(c); e.Gate == nil || e.Gate.Reason != haltReasonWindow {
+		t.Errorf("Got gate %+v wanted %v", e.Gate, haltReasonWindow)
+	}
+
+	// open the window
+	cd = c.DeepCopy()
+	cd.Spec.CanaryAnalysis.Windows = []string{"* * 30 2 *", "* * * * *"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// resume and advance
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+	mocks.ctrl.advanceCanary("podinfo", "default", true)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Status.Phase != flaggerv1.CanaryPhaseProgressing || c.Status.CanaryWeight != 10 {
+		t.Errorf("Got canary phase %v weight %v wanted %v %v", c.Status.Phase, c.Status.CanaryWeight, flaggerv1.CanaryPhaseProgressing, 10)
+	}
+}
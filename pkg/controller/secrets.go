@@ -0,0 +1,17 @@
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/weaveworks/flagger/pkg/secrets"
+)
+
+// getSecretData fetches the secret and returns a copy of its data with the
+// encrypted values decrypted in memory, the secret itself is never updated
+func (c *Controller) getSecretData(namespace string, name string) (map[string][]byte, error) {
+	secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return secrets.DecryptData(c.decryptor, secret.Data)
+}
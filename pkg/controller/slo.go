@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	defaultSLOFastWindow   = "5m"
+	defaultSLOFastBurnRate = 14.4
+	defaultSLOSlowWindow   = "1h"
+	defaultSLOSlowBurnRate = 6
+)
+
+// sloResult holds the error budget burn rates of a SLO metric
+type sloResult struct {
+	fastBurnRate float64
+	slowBurnRate float64
+}
+
+// runSLOMetric renders the metric template with the fast and slow windows as interval
+// and divides the error ratios by the error budget of the objective
+func (c *Controller) runSLOMetric(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric) (*sloResult, error) {
+	if metric.TemplateRef == nil || metric.Range != nil || metric.Baseline != nil {
+		return nil, newMetricTemplateError("Metric %s slo requires a templateRef without range or baseline", metric.Name)
+	}
+	slo := metric.SLO
+	if slo.Objective <= 0 || slo.Objective >= 100 {
+		return nil, newMetricTemplateError("Metric %s slo objective %v must be between 0 and 100", metric.Name, slo.Objective)
+	}
+	budget := 1 - slo.Objective/100
+
+	fast, err := c.runSLOErrorRatio(canary, metric, sloWindow(slo.FastWindow, defaultSLOFastWindow))
+	if err != nil {
+		return nil, err
+	}
+	slow, err := c.runSLOErrorRatio(canary, metric, sloWindow(slo.SlowWindow, defaultSLOSlowWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	return &sloResult{
+		fastBurnRate: fast / budget,
+		slowBurnRate: slow / budget,
+	}, nil
+}
+
+// runSLOErrorRatio runs the metric template query with the window as interval
+func (c *Controller) runSLOErrorRatio(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric, window string) (float64, error) {
+	metric.Interval = window
+	q, err := c.newMetricTemplateQuery(canary, metric, *metric.TemplateRef, toMetricModel(canary, metric))
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := q.provider.RunQuery(q.query)
+	c.recordQueryBudget(canary, q)
+	if err != nil {
+		return 0, fmt.Errorf("%s window: %v", window, err)
+	}
+	return val, nil
+}
+
+// sloWindow returns the window or its default when not set
+func sloWindow(window string, defaultWindow string) string {
+	if window == "" {
+		return defaultWindow
+	}
+	return window
+}
+
+// maxSLOBurnRates returns the fast and slow burn rates above which the SLO metric check fails
+func maxSLOBurnRates(slo *flaggerv1.CanaryMetricSLO) (fast float64, slow float64) {
+	fast, slow = slo.FastBurnRate, slo.SlowBurnRate
+	if fast <= 0 {
+		fast = defaultSLOFastBurnRate
+	}
+	if slow <= 0 {
+		slow = defaultSLOSlowBurnRate
+	}
+	return fast, slow
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/observers"
+)
+
+// maxTimeSeriesSamples limits the samples kept for each metric of an analysis
+const maxTimeSeriesSamples = 1000
+
+// TimeSeriesSample is a metric value measured at an analysis run
+type TimeSeriesSample struct {
+	Timestamp    time.Time
+	CanaryWeight int
+	Canary       float64
+	// Primary is nil if the metric can't be measured for the primary workload
+	Primary *float64
+}
+
+// TimeSeries holds the samples of a metric collected during the current analysis
+type TimeSeries struct {
+	Metric  string
+	Samples []TimeSeriesSample
+}
+
+// timeSeries holds the metric samples of each canary analysis in memory,
+// the samples are reset when a new analysis starts or when the controller restarts
+type timeSeries struct {
+	mux   sync.Mutex
+	items map[string]map[string][]TimeSeriesSample
+}
+
+func (ts *timeSeries) append(canary *flaggerv1.Canary, metric string, sample TimeSeriesSample) {
+	ts.mux.Lock()
+	defer ts.mux.Unlock()
+
+	if ts.items == nil {
+		ts.items = make(map[string]map[string][]TimeSeriesSample)
+	}
+
+	key := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+	series, ok := ts.items[key]
+	if !ok {
+		series = make(map[string][]TimeSeriesSample)
+		ts.items[key] = series
+	}
+
+	samples := append(series[metric], sample)
+	if len(samples) > maxTimeSeriesSamples {
+		samples = samples[len(samples)-maxTimeSeriesSamples:]
+	}
+	series[metric] = samples
+}
+
+// setPrimary sets the primary value of the last sample of a metric
+func (ts *timeSeries) setPrimary(canary *flaggerv1.Canary, metric string, value float64) {
+	ts.mux.Lock()
+	defer ts.mux.Unlock()
+
+	samples := ts.items[fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)][metric]
+	if len(samples) > 0 {
+		samples[len(samples)-1].Primary = &value
+	}
+}
+
+func (ts *timeSeries) get(name string, namespace string) []TimeSeries {
+	ts.mux.Lock()
+	defer ts.mux.Unlock()
+
+	var result []TimeSeries
+	for metric, samples := range ts.items[fmt.Sprintf("%s.%s", name, namespace)] {
+		result = append(result, TimeSeries{
+			Metric:  metric,
+			Samples: append([]TimeSeriesSample(nil), samples...),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Metric < result[j].Metric
+	})
+	return result
+}
+
+func (ts *timeSeries) delete(name string, namespace string) {
+	ts.mux.Lock()
+	defer ts.mux.Unlock()
+	delete(ts.items, fmt.Sprintf("%s.%s", name, namespace))
+}
+
+// GetTimeSeries returns the canary and primary metric samples collected during the current analysis
+func (c *Controller) GetTimeSeries(name string, namespace string) []TimeSeries {
+	return c.timeSeries.get(name, namespace)
+}
+
+// recordPrimaryValues measures the builtin metrics of the primary workload,
+// the failed queries are logged without affecting the analysis
+func (c *Controller) recordPrimaryValues(canary *flaggerv1.Canary, observer observers.Interface, metric flaggerv1.CanaryMetric) {
+	// the primary of a multi-cluster blue/green runs in another cluster than the canary metrics server
+	if canary.Spec.Cluster != nil {
+		return
+	}
+
+	primaryObserver, ok := observer.(observers.PrimaryInterface)
+	if !ok {
+		return
+	}
+
+	model := toMetricModel(canary, metric)
+	var val float64
+	var err error
+	switch metric.Name {
+	case "request-success-rate":
+		val, err = primaryObserver.GetPrimaryRequestSuccessRate(model)
+	case "request-duration":
+		var d time.Duration
+		d, err = primaryObserver.GetPrimaryRequestDuration(model)
+		val = float64(d) / float64(time.Millisecond)
+	default:
+		return
+	}
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Debugf("Primary metric %s query failed: %v", metric.Name, err)
+		return
+	}
+	c.timeSeries.setPrimary(canary, metric.Name, val)
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+// validateCanary returns an error for the specs that can't be analysed by the metrics provider
+func (c *Controller) validateCanary(cd *flaggerv1.Canary) error {
+	// the Kong Prometheus plugin reports the requests of a route for the Kong service of the ingress,
+	// the requests routed by the canary plugin can't be told apart from the primary requests
+	if c.builtinMetricsProvider(cd) != "kong" {
+		return nil
+	}
+	for _, metric := range cd.GetAnalysis().Metrics {
+		if metric.Name == "request-success-rate" || metric.Name == "request-duration" {
+			return fmt.Errorf("the kong provider doesn't support the builtin %s metric, use a metric template that selects the canary pods", metric.Name)
+		}
+	}
+	return nil
+}
+
+// syncValidCondition validates the canary spec and sets the Valid status condition,
+// it returns false if the canary spec is invalid
+func (c *Controller) syncValidCondition(cd *flaggerv1.Canary) bool {
+	validationErr := c.validateCanary(cd)
+
+	if ok, conditions := canary.MakeValidCondition(cd, validationErr); ok {
+		firstTry := true
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+			current := cd
+			if !firstTry {
+				current, err = c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(cd.Name, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				_, conditions = canary.MakeValidCondition(current, validationErr)
+			}
+			cdCopy := current.DeepCopy()
+			cdCopy.Status.Conditions = conditions
+			_, err = c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).UpdateStatus(cdCopy)
+			firstTry = false
+			return
+		})
+		if err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Errorf("status condition update error: %v", err)
+		} else {
+			cd.Status.Conditions = conditions
+		}
+	}
+
+	if validationErr != nil {
+		c.recordEventWarningf(cd, "Canary %s.%s is invalid: %v", cd.Name, cd.Namespace, validationErr)
+		return false
+	}
+	return true
+}
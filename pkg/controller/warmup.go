@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+// warmupBase returns the status iterations counted before the warmup checks, i.e. the mirror-only iterations
+func warmupBase(cd *flaggerv1.Canary) int {
+	if cd.GetAnalysis().Mirror {
+		return cd.GetMirrorIterations()
+	}
+	return 0
+}
+
+// warmupHolds returns the number of analysis runs left at the first step weight before the canary traffic is increased,
+// the consecutive passing checks at the first step weight are counted in the status iterations
+func warmupHolds(cd *flaggerv1.Canary, canaryWeight int, iterations int) int {
+	warmup := cd.GetAnalysis().WarmupIterations
+	firstStep := cd.GetNextStepWeight(0)
+	if warmup < 2 || canaryWeight > firstStep || firstStep >= cd.GetMaxWeight() {
+		return 0
+	}
+	if canaryWeight < firstStep {
+		return warmup - 1
+	}
+	if passed := iterations - warmupBase(cd); passed < warmup-1 {
+		return warmup - 1 - passed
+	}
+	return 0
+}
+
+// resetWarmup restarts the count of the consecutive passing checks after a failed check at the first step weight
+func (c *Controller) resetWarmup(cd *flaggerv1.Canary, canaryController canary.Controller, provider string, canaryWeight int) {
+	if cd.GetAnalysis().WarmupIterations < 2 || cd.GetAnalysis().Iterations > 0 ||
+		provider == "kubernetes" || cd.Spec.TargetRef.Kind == "CronJob" {
+		return
+	}
+	if canaryWeight != cd.GetNextStepWeight(0) || cd.Status.Iterations <= warmupBase(cd) {
+		return
+	}
+	if err := canaryController.SetStatusIterations(cd, warmupBase(cd)); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	// keep the reset when the failed checks are updated
+	cd.Status.Iterations = warmupBase(cd)
+}
+
+// warmupStarts holds the start time of the current analysis of each canary, the failed checks are not counted
+// during the warmup duration. The start times are lost when the controller restarts and the warmup is skipped.
+type warmupStarts struct {
+	items sync.Map
+}
+
+func (w *warmupStarts) start(canary *flaggerv1.Canary) {
+	w.items.Store(fmt.Sprintf("%s.%s", canary.Name, canary.Namespace), time.Now())
+}
+
+// remaining returns the warmup time left for the canary, zero if the warmup is over or wasn't started
+func (w *warmupStarts) remaining(canary *flaggerv1.Canary) time.Duration {
+	duration := canary.GetWarmupDuration()
+	if duration <= 0 {
+		return 0
+	}
+	start, ok := w.items.Load(fmt.Sprintf("%s.%s", canary.Name, canary.Namespace))
+	if !ok {
+		return 0
+	}
+	if left := duration - time.Since(start.(time.Time)); left > 0 {
+		return left
+	}
+	return 0
+}
+
+func (w *warmupStarts) delete(name string, namespace string) {
+	w.items.Delete(fmt.Sprintf("%s.%s", name, namespace))
+}
@@ -0,0 +1,389 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	hookJobCanaryLabel   = "flagger.app/hook-canary"
+	hookJobNameLabel     = "flagger.app/hook"
+	hookJobRevisionLabel = "flagger.app/canary-revision"
+
+	// hookJobCapturedAnnotation marks the jobs whose artifacts were saved
+	hookJobCapturedAnnotation = "flagger.app/artifacts-captured"
+
+	// the job name is used as a pod label value
+	maxHookJobNameLength = 63
+
+	// maxHookLogBytes limits the logs saved for each hook
+	maxHookLogBytes = 256 * 1024
+
+	// maxHookArtifactsBytes limits the artifacts config map data, the objects stored in etcd are limited to 1MB
+	maxHookArtifactsBytes = 900 * 1024
+
+	hookLogTruncatedMarker = "[truncated]\n"
+)
+
+// HookArtifact is the result of a hook job saved in the artifacts config map
+type HookArtifact struct {
+	Job            string      `json:"job"`
+	Type           string      `json:"type"`
+	Revision       string      `json:"revision"`
+	Succeeded      bool        `json:"succeeded"`
+	CompletionTime metav1.Time `json:"completionTime"`
+}
+
+// hookArtifactsName returns the name of the config map holding the hook jobs logs and results
+func hookArtifactsName(cd *flaggerv1.Canary) string {
+	return fmt.Sprintf("%s-hook-artifacts", cd.Name)
+}
+
+// hookJobName returns the name of the hook job of the current canary revision
+func hookJobName(cd *flaggerv1.Canary, webhook flaggerv1.CanaryWebhook) string {
+	suffix := "-" + cd.Status.LastAppliedSpec
+	prefix := fmt.Sprintf("%s-%s", cd.Name, webhook.Name)
+	if max := maxHookJobNameLength - len(suffix); len(prefix) > max {
+		prefix = prefix[:max]
+	}
+	return strings.ToLower(strings.TrimSuffix(prefix, "-") + suffix)
+}
+
+// runHookJob starts the hook job of the current revision and returns true when the job has finished,
+// the returned error is set when the job failed
+func (c *Controller) runHookJob(cd *flaggerv1.Canary, webhook flaggerv1.CanaryWebhook) (bool, error) {
+	// the webhook name is used as a label value and as a prefix of the config map keys
+	if errs := validation.IsDNS1123Label(webhook.Name); len(errs) > 0 {
+		return true, fmt.Errorf("webhook name %s can't be used for a hook job: %s", webhook.Name, strings.Join(errs, ", "))
+	}
+
+	name := hookJobName(cd, webhook)
+	job, err := c.kubeClient.BatchV1().Jobs(cd.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, c.createHookJob(cd, webhook, name)
+	}
+	if err != nil {
+		return false, fmt.Errorf("job %s.%s query error %v", name, cd.Namespace, err)
+	}
+
+	switch {
+	case isJobFinished(job, batchv1.JobComplete):
+		return true, nil
+	case isJobFinished(job, batchv1.JobFailed):
+		return true, fmt.Errorf("job %s.%s failed", name, cd.Namespace)
+	}
+	return false, nil
+}
+
+func (c *Controller) createHookJob(cd *flaggerv1.Canary, webhook flaggerv1.CanaryWebhook, name string) error {
+	// remove the jobs of the previous revisions
+	selector := fmt.Sprintf("%s=%s,%s=%s", hookJobCanaryLabel, cd.Name, hookJobNameLabel, webhook.Name)
+	list, err := c.kubeClient.BatchV1().Jobs(cd.Namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("jobs %s.%s query error %v", selector, cd.Namespace, err)
+	}
+	propagation := metav1.DeletePropagationBackground
+	for _, job := range list.Items {
+		err := c.kubeClient.BatchV1().Jobs(cd.Namespace).Delete(job.Name, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("deleting job %s.%s failed: %v", job.Name, cd.Namespace, err)
+		}
+	}
+
+	spec := webhook.Job.Spec.DeepCopy()
+	if spec.Template.Spec.RestartPolicy == "" {
+		spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	// the result file is read by the kubelet into the container status when the container terminates
+	if webhook.Job.ResultPath != "" {
+		for i := range spec.Template.Spec.Containers {
+			spec.Template.Spec.Containers[i].TerminationMessagePath = webhook.Job.ResultPath
+			spec.Template.Spec.Containers[i].TerminationMessagePolicy = corev1.TerminationMessageReadFile
+		}
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cd.Namespace,
+			Labels: map[string]string{
+				hookJobCanaryLabel:   cd.Name,
+				hookJobNameLabel:     webhook.Name,
+				hookJobRevisionLabel: cd.Status.LastAppliedSpec,
+			},
+			Annotations: map[string]string{
+				hookJobNameLabel: string(webhook.Type),
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			},
+		},
+		Spec: *spec,
+	}
+
+	_, err = c.kubeClient.BatchV1().Jobs(cd.Namespace).Create(job)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("creating job %s.%s failed: %v", name, cd.Namespace, err)
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("Job %s.%s created", name, cd.Namespace)
+	return nil
+}
+
+// waitPreRolloutJobs starts the pre-rollout jobs and returns false while any of them is running,
+// the jobs result is checked with the pre-rollout webhooks
+func (c *Controller) waitPreRolloutJobs(cd *flaggerv1.Canary) bool {
+	for _, webhook := range cd.GetAnalysis().Webhooks {
+		if webhook.Type != flaggerv1.PreRolloutHook || webhook.Job == nil {
+			continue
+		}
+		done, err := c.runHookJob(cd, webhook)
+		if !done {
+			if err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+				return false
+			}
+			c.recordEventInfof(cd, "Halt %s.%s advancement waiting for pre-rollout job %s",
+				cd.Name, cd.Namespace, hookJobName(cd, webhook))
+			return false
+		}
+	}
+	return true
+}
+
+// syncHookArtifacts saves the logs and the result of the finished hook jobs in the artifacts config map
+func (c *Controller) syncHookArtifacts(cd *flaggerv1.Canary) {
+	hasJobs := false
+	for _, webhook := range cd.GetAnalysis().Webhooks {
+		if webhook.Job != nil {
+			hasJobs = true
+		}
+	}
+	if !hasJobs {
+		return
+	}
+
+	selector := fmt.Sprintf("%s=%s", hookJobCanaryLabel, cd.Name)
+	list, err := c.kubeClient.BatchV1().Jobs(cd.Namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		c.recordEventWarningf(cd, "jobs %s.%s query error %v", selector, cd.Namespace, err)
+		return
+	}
+
+	for i := range list.Items {
+		job := &list.Items[i]
+		succeeded := isJobFinished(job, batchv1.JobComplete)
+		if _, ok := job.Annotations[hookJobCapturedAnnotation]; ok ||
+			(!succeeded && !isJobFinished(job, batchv1.JobFailed)) {
+			continue
+		}
+		if err := c.saveHookArtifacts(cd, job, succeeded); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			continue
+		}
+
+		jobCopy := job.DeepCopy()
+		if jobCopy.Annotations == nil {
+			jobCopy.Annotations = make(map[string]string)
+		}
+		jobCopy.Annotations[hookJobCapturedAnnotation] = "true"
+		if _, err := c.kubeClient.BatchV1().Jobs(cd.Namespace).Update(jobCopy); err != nil {
+			c.recordEventWarningf(cd, "job %s.%s update error %v", job.Name, cd.Namespace, err)
+			continue
+		}
+		c.recordEventInfof(cd, "Hook job %s artifacts saved in config map %s", job.Name, hookArtifactsName(cd))
+	}
+}
+
+func (c *Controller) saveHookArtifacts(cd *flaggerv1.Canary, job *batchv1.Job, succeeded bool) error {
+	hook := job.Labels[hookJobNameLabel]
+	logs, result, err := c.getHookJobOutput(job)
+	if err != nil {
+		return err
+	}
+
+	artifact := HookArtifact{
+		Job:       job.Name,
+		Type:      job.Annotations[hookJobNameLabel],
+		Revision:  job.Labels[hookJobRevisionLabel],
+		Succeeded: succeeded,
+	}
+	if job.Status.CompletionTime != nil {
+		artifact.CompletionTime = *job.Status.CompletionTime
+	} else {
+		artifact.CompletionTime = metav1.NewTime(time.Now())
+	}
+	status, err := json.Marshal(artifact)
+	if err != nil {
+		return err
+	}
+
+	name := hookArtifactsName(cd)
+	cm, err := c.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cd.Namespace,
+				Labels:    map[string]string{hookJobCanaryLabel: cd.Name},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+		}
+		cm, err = c.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Create(cm)
+	}
+	if err != nil {
+		return fmt.Errorf("config map %s.%s query error %v", name, cd.Namespace, err)
+	}
+
+	cmCopy := cm.DeepCopy()
+	if cmCopy.Data == nil {
+		cmCopy.Data = make(map[string]string)
+	}
+	cmCopy.Data[hook+".json"] = string(status)
+	cmCopy.Data[hook+".log"] = logs
+	if result != "" {
+		cmCopy.Data[hook+".result"] = result
+	} else {
+		delete(cmCopy.Data, hook+".result")
+	}
+	fitHookArtifacts(cmCopy.Data, hook)
+	if _, err := c.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Update(cmCopy); err != nil {
+		return fmt.Errorf("config map %s.%s update error %v", name, cd.Namespace, err)
+	}
+	return nil
+}
+
+// fitHookArtifacts keeps the config map data under maxHookArtifactsBytes by removing the artifacts
+// of the other hooks, oldest first, and by truncating the logs of the given hook
+func fitHookArtifacts(data map[string]string, hook string) {
+	size := hookArtifactsSize(data)
+	if size <= maxHookArtifactsBytes {
+		return
+	}
+
+	type entry struct {
+		hook           string
+		completionTime time.Time
+	}
+	var others []entry
+	for key, value := range data {
+		if !strings.HasSuffix(key, ".json") || key == hook+".json" {
+			continue
+		}
+		var artifact HookArtifact
+		_ = json.Unmarshal([]byte(value), &artifact)
+		others = append(others, entry{hook: strings.TrimSuffix(key, ".json"), completionTime: artifact.CompletionTime.Time})
+	}
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].completionTime.Before(others[j].completionTime)
+	})
+
+	for _, other := range others {
+		if size <= maxHookArtifactsBytes {
+			return
+		}
+		for _, key := range []string{other.hook + ".json", other.hook + ".log", other.hook + ".result"} {
+			if value, ok := data[key]; ok {
+				size -= len(key) + len(value)
+				delete(data, key)
+			}
+		}
+	}
+
+	if over := size - maxHookArtifactsBytes; over > 0 {
+		data[hook+".log"] = truncateHookLog(data[hook+".log"], len(data[hook+".log"])-over)
+	}
+}
+
+func hookArtifactsSize(data map[string]string) int {
+	size := 0
+	for key, value := range data {
+		size += len(key) + len(value)
+	}
+	return size
+}
+
+// truncateHookLog keeps the end of the logs, the test summary is usually printed last
+func truncateHookLog(logs string, max int) string {
+	if len(logs) <= max {
+		return logs
+	}
+	if max <= len(hookLogTruncatedMarker) {
+		return ""
+	}
+	return hookLogTruncatedMarker + logs[len(logs)-max+len(hookLogTruncatedMarker):]
+}
+
+// getHookJobOutput returns the logs and the termination messages of the containers of the last job pod
+func (c *Controller) getHookJobOutput(job *batchv1.Job) (string, string, error) {
+	selector := fmt.Sprintf("job-name=%s", job.Name)
+	pods, err := c.kubeClient.CoreV1().Pods(job.Namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return "", "", fmt.Errorf("pods %s.%s query error %v", selector, job.Namespace, err)
+	}
+	if len(pods.Items) == 0 {
+		return "", "", nil
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].CreationTimestamp.Before(&pods.Items[j].CreationTimestamp)
+	})
+	pod := pods.Items[len(pods.Items)-1]
+
+	var logs, results []string
+	for _, container := range pod.Spec.Containers {
+		out, err := c.getPodLogs(pod.Namespace, pod.Name, container.Name)
+		if err != nil {
+			return "", "", fmt.Errorf("pod %s.%s logs query error %v", pod.Name, pod.Namespace, err)
+		}
+		if len(pod.Spec.Containers) > 1 {
+			out = fmt.Sprintf("==> %s <==\n%s", container.Name, out)
+		}
+		logs = append(logs, out)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.Message != "" {
+			results = append(results, status.State.Terminated.Message)
+		}
+	}
+	return truncateHookLog(strings.Join(logs, "\n"), maxHookLogBytes), strings.Join(results, "\n"), nil
+}
+
+func (c *Controller) getPodLogs(namespace string, pod string, container string) (string, error) {
+	if c.podLogs != nil {
+		return c.podLogs(namespace, pod, container)
+	}
+	limit := int64(maxHookLogBytes)
+	out, err := c.kubeClient.CoreV1().Pods(namespace).
+		GetLogs(pod, &corev1.PodLogOptions{Container: container, LimitBytes: &limit}).DoRaw()
+	return string(out), err
+}
+
+func isJobFinished(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
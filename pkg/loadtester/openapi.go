@@ -0,0 +1,317 @@
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const TaskTypeOpenAPI = "openapi"
+
+// OpenAPITask runs contract tests against the canary endpoint,
+// every GET operation of the OpenAPI spec is called and the responses are checked
+// against the documented status codes and JSON schemas
+type OpenAPITask struct {
+	TaskBase
+	specURL string
+	baseURL string
+	client  *http.Client
+}
+
+func (task *OpenAPITask) Hash() string {
+	return hash(task.canary + task.specURL + task.baseURL)
+}
+
+func (task *OpenAPITask) Run(ctx context.Context) (bool, error) {
+	task.logger.With("canary", task.canary).Infof("running contract tests %s against %s", task.specURL, task.baseURL)
+
+	spec, err := task.fetchSpec(ctx)
+	if err != nil {
+		task.logger.With("canary", task.canary).Errorf("contract tests failed %v", err)
+		return false, err
+	}
+
+	var violations []string
+	operations := 0
+	for _, path := range sortedKeys(mapValue(spec, "paths")) {
+		operation := mapValue(mapValue(spec, "paths"), path, "get")
+		if operation == nil {
+			continue
+		}
+
+		target, ok := task.operationURL(spec, path, operation)
+		if !ok {
+			task.logger.With("canary", task.canary).Debugf("skipping GET %s, the path parameters have no example", path)
+			continue
+		}
+
+		operations++
+		for _, v := range task.checkOperation(ctx, spec, target, operation) {
+			violations = append(violations, fmt.Sprintf("GET %s %s", path, v))
+		}
+	}
+
+	if len(violations) > 0 {
+		err := fmt.Errorf("%d contract violations\n%s", len(violations), strings.Join(violations, "\n"))
+		task.logger.With("canary", task.canary).Errorf("contract tests failed %v", err)
+		return false, err
+	}
+
+	task.logger.With("canary", task.canary).Infof("contract tests finished %d operations passed", operations)
+	return true, nil
+}
+
+func (task *OpenAPITask) String() string {
+	return task.specURL
+}
+
+func (task *OpenAPITask) fetchSpec(ctx context.Context) (map[string]interface{}, error) {
+	req, err := http.NewRequest("GET", task.specURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec url %s %v", task.specURL, err)
+	}
+	resp, err := task.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("fetching spec %s failed %v", task.specURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching spec %s failed with status %d", task.specURL, resp.StatusCode)
+	}
+
+	spec := make(map[string]interface{})
+	if err := yaml.NewYAMLOrJSONDecoder(resp.Body, 4096).Decode(&spec); err != nil {
+		return nil, fmt.Errorf("decoding spec %s failed %v", task.specURL, err)
+	}
+	if mapValue(spec, "paths") == nil {
+		return nil, fmt.Errorf("spec %s has no paths", task.specURL)
+	}
+	return spec, nil
+}
+
+// operationURL replaces the path parameters with their examples or defaults,
+// returns false if a path parameter has no value to use
+func (task *OpenAPITask) operationURL(spec map[string]interface{}, path string, operation map[string]interface{}) (string, bool) {
+	params := append(sliceValue(mapValue(spec, "paths", path), "parameters"), sliceValue(operation, "parameters")...)
+	for _, p := range params {
+		param := resolve(spec, asMap(p))
+		if param == nil || param["in"] != "path" {
+			continue
+		}
+		value, ok := exampleValue(param)
+		if !ok {
+			value, ok = exampleValue(resolve(spec, mapValue(param, "schema")))
+		}
+		if !ok {
+			return "", false
+		}
+		path = strings.Replace(path, fmt.Sprintf("{%v}", param["name"]), url.PathEscape(value), -1)
+	}
+	if strings.Contains(path, "{") {
+		return "", false
+	}
+	return strings.TrimSuffix(task.baseURL, "/") + path, true
+}
+
+// checkOperation calls the canary and returns the contract violations of the response
+func (task *OpenAPITask) checkOperation(ctx context.Context, spec map[string]interface{}, target string, operation map[string]interface{}) []string {
+	req, err := http.NewRequest("GET", target, nil)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := task.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return []string{fmt.Sprintf("request failed %v", err)}
+	}
+	defer resp.Body.Close()
+
+	response := findResponse(mapValue(operation, "responses"), resp.StatusCode)
+	if response == nil {
+		return []string{fmt.Sprintf("status %d is not documented", resp.StatusCode)}
+	}
+	response = resolve(spec, response)
+
+	// OpenAPI v3 documents the schema per media type, Swagger v2 per response
+	schema := mapValue(response, "content", "application/json", "schema")
+	if schema == nil {
+		schema = mapValue(response, "schema")
+	}
+	if schema == nil || !strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return []string{fmt.Sprintf("reading the response failed %v", err)}
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []string{fmt.Sprintf("decoding the response failed %v", err)}
+	}
+	return validateSchema(spec, schema, value, "response")
+}
+
+// findResponse returns the response documented for the status code, the range (e.g. 2XX) or the default response
+func findResponse(responses map[string]interface{}, code int) map[string]interface{} {
+	status := strconv.Itoa(code)
+	for _, key := range []string{status, status[:1] + "XX", status[:1] + "xx", "default"} {
+		if r := mapValue(responses, key); r != nil {
+			return r
+		}
+	}
+	return nil
+}
+
+// validateSchema checks a decoded JSON value against the type, required properties,
+// enum values, object properties, array items and allOf of a schema
+func validateSchema(spec map[string]interface{}, schema map[string]interface{}, value interface{}, field string) []string {
+	schema = resolve(spec, schema)
+	if schema == nil {
+		return nil
+	}
+
+	var violations []string
+	for _, s := range sliceValue(schema, "allOf") {
+		violations = append(violations, validateSchema(spec, asMap(s), value, field)...)
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || schema["type"] == nil {
+			return violations
+		}
+		return append(violations, fmt.Sprintf("%s is null", field))
+	}
+
+	if typ, ok := schema["type"].(string); ok && !hasType(value, typ) {
+		return append(violations, fmt.Sprintf("%s is not of type %s", field, typ))
+	}
+
+	if enum := sliceValue(schema, "enum"); len(enum) > 0 {
+		found := false
+		for _, e := range enum {
+			if fmt.Sprint(e) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			violations = append(violations, fmt.Sprintf("%s value %v is not in enum", field, value))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, r := range sliceValue(schema, "required") {
+			if _, ok := v[fmt.Sprint(r)]; !ok {
+				violations = append(violations, fmt.Sprintf("%s.%v is required", field, r))
+			}
+		}
+		properties := mapValue(schema, "properties")
+		for _, name := range sortedKeys(properties) {
+			if pv, ok := v[name]; ok {
+				violations = append(violations, validateSchema(spec, mapValue(properties, name), pv, field+"."+name)...)
+			}
+		}
+	case []interface{}:
+		if items := mapValue(schema, "items"); items != nil {
+			for i, item := range v {
+				violations = append(violations, validateSchema(spec, items, item, fmt.Sprintf("%s[%d]", field, i))...)
+			}
+		}
+	}
+	return violations
+}
+
+func hasType(value interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		switch n := value.(type) {
+		case int64:
+			return true
+		case float64:
+			return n == float64(int64(n))
+		}
+		return false
+	case "number":
+		switch value.(type) {
+		case int64, float64:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// resolve follows the local $ref of a spec object e.g. #/components/schemas/Pet
+func resolve(spec map[string]interface{}, obj map[string]interface{}) map[string]interface{} {
+	for i := 0; obj != nil && i < 10; i++ {
+		ref, ok := obj["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return obj
+		}
+		obj = mapValue(spec, strings.Split(strings.TrimPrefix(ref, "#/"), "/")...)
+	}
+	return obj
+}
+
+func exampleValue(obj map[string]interface{}) (string, bool) {
+	for _, key := range []string{"example", "default", "x-example"} {
+		if v, ok := obj[key]; ok && v != nil {
+			return fmt.Sprint(v), true
+		}
+	}
+	return "", false
+}
+
+func mapValue(obj map[string]interface{}, keys ...string) map[string]interface{} {
+	for _, key := range keys {
+		if obj == nil {
+			return nil
+		}
+		obj = asMap(obj[key])
+	}
+	return obj
+}
+
+func sliceValue(obj map[string]interface{}, key string) []interface{} {
+	if obj == nil {
+		return nil
+	}
+	s, _ := obj[key].([]interface{})
+	return s
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
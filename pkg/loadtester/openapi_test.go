@@ -0,0 +1,139 @@
+package loadtester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+const openAPITestSpec = `
+openapi: 3.0.0
+paths:
+  /version:
+    get:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Version'
+  /items/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+            example: 1
+      responses:
+        '2XX':
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  required: [name]
+                  properties:
+                    name:
+                      type: string
+                    status:
+                      type: string
+                      enum: [active, inactive]
+  /admin/{token}:
+    get:
+      parameters:
+        - name: token
+          in: path
+          required: true
+      responses:
+        '200':
+          description: skipped without an example
+components:
+  schemas:
+    Version:
+      type: object
+      required: [version, commit]
+      properties:
+        version:
+          type: string
+        commit:
+          type: string
+`
+
+func newOpenAPITestServer(items string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(openAPITestSpec))
+	})
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version": "1.0.0", "commit": "abc"}`))
+	})
+	mux.HandleFunc("/items/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(items))
+	})
+	return httptest.NewServer(mux)
+}
+
+func newOpenAPITestTask(ts *httptest.Server) *OpenAPITask {
+	logger, _ := logger.NewLoggerWithEncoding("debug", "console")
+	return &OpenAPITask{
+		TaskBase: TaskBase{canary: "podinfo.default", logger: logger},
+		specURL:  ts.URL + "/openapi.yaml",
+		baseURL:  ts.URL,
+		client:   http.DefaultClient,
+	}
+}
+
+func TestOpenAPITask_Pass(t *testing.T) {
+	ts := newOpenAPITestServer(`[{"name": "a", "status": "active"}]`)
+	defer ts.Close()
+
+	ok, err := newOpenAPITestTask(ts).Run(context.Background())
+	if !ok {
+		t.Errorf("Expected contract tests to pass got %v", err)
+	}
+}
+
+func TestOpenAPITask_Violations(t *testing.T) {
+	ts := newOpenAPITestServer(`[{"status": "unknown"}, {"name": 1}]`)
+	defer ts.Close()
+
+	ok, err := newOpenAPITestTask(ts).Run(context.Background())
+	if ok {
+		t.Fatal("Expected contract tests to fail")
+	}
+
+	for _, v := range []string{
+		"response[0].name is required",
+		"response[0].status value unknown is not in enum",
+		"response[1].name is not of type string",
+	} {
+		if !strings.Contains(err.Error(), v) {
+			t.Errorf("Expected violation %q in %v", v, err)
+		}
+	}
+}
+
+func TestOpenAPITask_UndocumentedStatus(t *testing.T) {
+	ts := newOpenAPITestServer(`[]`)
+	defer ts.Close()
+
+	task := newOpenAPITestTask(ts)
+	task.baseURL = ts.URL + "/v2"
+
+	ok, err := task.Run(context.Background())
+	if ok {
+		t.Fatal("Expected contract tests to fail")
+	}
+	if !strings.Contains(err.Error(), "status 404 is not documented") {
+		t.Errorf("Expected undocumented status violation got %v", err)
+	}
+}
@@ -288,6 +288,38 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 				return
 			}
 
+			// run OpenAPI contract tests (blocking task)
+			if typ == TaskTypeOpenAPI {
+				if payload.Metadata["spec"] == "" || payload.Metadata["url"] == "" {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("spec and url are required metadata"))
+					return
+				}
+
+				contract := OpenAPITask{
+					specURL: payload.Metadata["spec"],
+					baseURL: payload.Metadata["url"],
+					client:  http.DefaultClient,
+					TaskBase: TaskBase{
+						canary: fmt.Sprintf("%s.%s", payload.Name, payload.Namespace),
+						logger: logger,
+					},
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.timeout)
+				defer cancel()
+
+				ok, err := contract.Run(ctx)
+				if !ok {
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
 			taskFactory, ok := GetTaskFactory(typ)
 			if !ok {
 				w.WriteHeader(http.StatusBadRequest)
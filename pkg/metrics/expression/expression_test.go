@@ -0,0 +1,66 @@
+package expression
+
+import (
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	vars := map[string]float64{"errors": 5, "requests": 200, "edge_errors": 3}
+	tests := map[string]float64{
+		"errors / requests * 100":                    2.5,
+		"(errors + edge_errors) / requests * 100":    4,
+		"errors + edge_errors / requests * 100":      6.5,
+		"-errors + 10":                               5,
+		"100 - errors/requests*100":                  97.5,
+		"1.5 * (requests - (errors * 2)) / 0.5 - 10": 560,
+	}
+
+	for expr, want := range tests {
+		e, err := Parse(expr)
+		if err != nil {
+			t.Fatalf("%s parse error: %v", expr, err)
+		}
+		got, err := e.Evaluate(vars)
+		if err != nil {
+			t.Fatalf("%s evaluate error: %v", expr, err)
+		}
+		if got != want {
+			t.Errorf("%s got %v wanted %v", expr, got, want)
+		}
+	}
+}
+
+func TestVariables(t *testing.T) {
+	e, err := Parse("(errors + edge_errors) / requests + errors")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vars := e.Variables()
+	if len(vars) != 3 || vars[0] != "errors" || vars[1] != "edge_errors" || vars[2] != "requests" {
+		t.Errorf("Got variables %v wanted [errors edge_errors requests]", vars)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{"", "errors /", "(errors + 1", "errors % 2", "1.2.3", "errors requests"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Expected a parse error for %q", expr)
+		}
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	e, err := Parse("errors / requests")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := e.Evaluate(map[string]float64{"errors": 1, "requests": 0}); err == nil {
+		t.Errorf("Expected a division by zero error")
+	}
+
+	if _, err := e.Evaluate(map[string]float64{"errors": 1}); err == nil {
+		t.Errorf("Expected an undefined variable error")
+	}
+}
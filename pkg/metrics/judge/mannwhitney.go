@@ -0,0 +1,131 @@
+package judge
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// DirectionIncrease fails the comparison when the canary values are significantly higher
+	DirectionIncrease = "increase"
+	// DirectionDecrease fails the comparison when the canary values are significantly lower
+	DirectionDecrease = "decrease"
+	// DirectionEither fails the comparison when the canary values are significantly different
+	DirectionEither = "either"
+)
+
+// Result is the outcome of the comparison of the canary and baseline samples
+type Result struct {
+	// U is the Mann-Whitney statistic of the canary sample
+	U float64
+	// PValue is the probability of observing the difference if both samples come from the same distribution
+	PValue float64
+	// CanaryMedian is the median of the canary sample
+	CanaryMedian float64
+	// BaselineMedian is the median of the baseline sample
+	BaselineMedian float64
+	// Significant is true if the p-value is below the significance level of the confidence
+	Significant bool
+}
+
+// Compare applies a one or two sided Mann-Whitney U test to the canary and baseline samples,
+// the confidence is a percentage e.g. 95 reports a change with a p-value below 0.05 as significant
+func Compare(canary []float64, baseline []float64, direction string, confidence float64) (Result, error) {
+	if len(canary) < 1 || len(baseline) < 1 {
+		return Result{}, fmt.Errorf("no values found")
+	}
+	if confidence <= 0 || confidence >= 100 {
+		return Result{}, fmt.Errorf("confidence %v must be between 0 and 100", confidence)
+	}
+
+	u, p, err := MannWhitney(canary, baseline, direction)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		U:              u,
+		PValue:         p,
+		CanaryMedian:   median(canary),
+		BaselineMedian: median(baseline),
+		Significant:    p < 1-confidence/100,
+	}, nil
+}
+
+// MannWhitney returns the U statistic of the first sample and the p-value of the test,
+// the p-value uses the normal approximation with the tie and continuity corrections
+func MannWhitney(x []float64, y []float64, direction string) (float64, float64, error) {
+	n1 := float64(len(x))
+	n2 := float64(len(y))
+	n := n1 + n2
+
+	ranks, ties := rank(append(append([]float64(nil), x...), y...))
+	r1 := 0.0
+	for _, r := range ranks[:len(x)] {
+		r1 += r
+	}
+	u := r1 - n1*(n1+1)/2
+
+	mu := n1 * n2 / 2
+	sigma := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+
+	var p float64
+	switch direction {
+	case DirectionIncrease:
+		p = upperTail(u-mu-0.5, sigma)
+	case DirectionDecrease:
+		p = upperTail(mu-u-0.5, sigma)
+	case DirectionEither:
+		p = math.Min(1, 2*upperTail(math.Abs(u-mu)-0.5, sigma))
+	default:
+		return 0, 0, fmt.Errorf("direction %s not supported, must be one of increase, decrease or either", direction)
+	}
+	return u, p, nil
+}
+
+// upperTail returns the probability of the standard normal distribution above d / sigma,
+// a zero sigma means identical samples and no evidence of a difference
+func upperTail(d float64, sigma float64) float64 {
+	if sigma == 0 {
+		return 1
+	}
+	return 0.5 * math.Erfc(d/sigma/math.Sqrt2)
+}
+
+// rank returns the ranks of the values starting at 1, tied values get the average of their ranks,
+// the ties term is the sum of t^3 - t over the groups of t tied values
+func rank(values []float64) ([]float64, float64) {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return values[idx[a]] < values[idx[b]] })
+
+	ranks := make([]float64, len(values))
+	ties := 0.0
+	for i := 0; i < len(idx); {
+		j := i
+		for j < len(idx) && values[idx[j]] == values[idx[i]] {
+			j++
+		}
+		avg := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			ranks[idx[k]] = avg
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+	return ranks, ties
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	m := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[m-1] + sorted[m]) / 2
+	}
+	return sorted[m]
+}
@@ -0,0 +1,76 @@
+package judge
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMannWhitney(t *testing.T) {
+	low := []float64{1, 2, 3, 4, 5}
+	high := []float64{6, 7, 8, 9, 10}
+
+	tests := []struct {
+		name      string
+		x         []float64
+		y         []float64
+		direction string
+		u         float64
+		p         float64
+	}{
+		{name: "decrease", x: low, y: high, direction: DirectionDecrease, u: 0, p: 0.00609},
+		{name: "increase", x: low, y: high, direction: DirectionIncrease, u: 0, p: 0.99669},
+		{name: "either", x: high, y: low, direction: DirectionEither, u: 25, p: 0.01219},
+		{name: "ties", x: []float64{1, 2, 2, 3}, y: []float64{2, 3, 3, 4}, direction: DirectionEither, u: 3, p: 0.17203},
+		{name: "identical", x: []float64{5, 5, 5}, y: []float64{5, 5, 5}, direction: DirectionEither, u: 4.5, p: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, p, err := MannWhitney(tt.x, tt.y, tt.direction)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if u != tt.u || math.Abs(p-tt.p) > 0.0001 {
+				t.Errorf("Got U %v p-value %v wanted %v %v", u, p, tt.u, tt.p)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := []float64{100, 102, 98, 101, 99, 103, 97, 100}
+
+	r, err := Compare([]float64{120, 125, 118, 130, 122, 119, 127, 121}, baseline, DirectionIncrease, 95)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !r.Significant || r.CanaryMedian != 121.5 || r.BaselineMedian != 100 {
+		t.Errorf("Got result %+v wanted a significant increase", r)
+	}
+
+	r, err = Compare([]float64{101, 99, 100, 102, 98, 100, 103, 97}, baseline, DirectionIncrease, 95)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if r.Significant {
+		t.Errorf("Got result %+v wanted no significant change", r)
+	}
+
+	// a decrease doesn't fail a comparison on increase
+	r, err = Compare([]float64{80, 82, 79, 81, 83, 78, 80, 84}, baseline, DirectionIncrease, 95)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if r.Significant {
+		t.Errorf("Got result %+v wanted no significant increase", r)
+	}
+
+	if _, err := Compare(nil, baseline, DirectionIncrease, 95); err == nil {
+		t.Errorf("Expected an error for an empty sample")
+	}
+	if _, err := Compare(baseline, baseline, "up", 95); err == nil {
+		t.Errorf("Expected an error for an invalid direction")
+	}
+	if _, err := Compare(baseline, baseline, DirectionIncrease, 100); err == nil {
+		t.Errorf("Expected an error for an invalid confidence")
+	}
+}
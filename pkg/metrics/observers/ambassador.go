@@ -0,0 +1,75 @@
+package observers
+
+import (
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// Ambassador names the Envoy clusters after the mapping service address,
+// replacing dashes and dots with underscores e.g. cluster_podinfo_canary_test_9898
+var ambassadorQueries = map[string]string{
+	"request-success-rate": `
+	sum(
+		rate(
+			envoy_cluster_upstream_rq{
+				envoy_cluster_name=~"cluster_{{ service | replace "-" "_" }}_canary_{{ namespace | replace "-" "_" }}_[0-9]+.*",
+				envoy_response_code!~"5.*"{{ routeSelector }}
+			}[{{ interval }}]
+		)
+	)
+	/
+	sum(
+		rate(
+			envoy_cluster_upstream_rq{
+				envoy_cluster_name=~"cluster_{{ service | replace "-" "_" }}_canary_{{ namespace | replace "-" "_" }}_[0-9]+.*"{{ routeSelector }}
+			}[{{ interval }}]
+		)
+	)
+	* 100`,
+	"request-duration": `
+	histogram_quantile(
+		0.99,
+		sum(
+			rate(
+				envoy_cluster_upstream_rq_time_bucket{
+					envoy_cluster_name=~"cluster_{{ service | replace "-" "_" }}_canary_{{ namespace | replace "-" "_" }}_[0-9]+.*"{{ routeSelector }}
+				}[{{ interval }}]
+			)
+		) by (le)
+	)`,
+}
+
+type AmbassadorObserver struct {
+	client providers.Interface
+}
+
+func (ob *AmbassadorObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(ambassadorQueries["request-success-rate"], model)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+func (ob *AmbassadorObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(ambassadorQueries["request-duration"], model)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	ms := time.Duration(int64(value)) * time.Millisecond
+	return ms, nil
+}
@@ -0,0 +1,77 @@
+package observers
+
+import (
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// APISIX ingress names the routes <namespace>_<ApisixRoute>_<http route>, the route names are reported
+// when the Prometheus plugin prefer_name option is enabled. The weighted route serves both primary and canary
+// while the A/B testing routes serve the canary once the analysis starts.
+var apisixQueries = map[string]string{
+	"request-success-rate": `
+	sum(
+		rate(
+			apisix_http_status{
+				route=~"{{ namespace }}_{{ service }}_{{ service }}(-canary-[0-9]+)?",
+				code!~"5.."{{ routeSelector }}
+			}[{{ interval }}]
+		)
+	)
+	/
+	sum(
+		rate(
+			apisix_http_status{
+				route=~"{{ namespace }}_{{ service }}_{{ service }}(-canary-[0-9]+)?"{{ routeSelector }}
+			}[{{ interval }}]
+		)
+	)
+	* 100`,
+	"request-duration": `
+	histogram_quantile(
+		0.99,
+		sum(
+			rate(
+				apisix_http_latency_bucket{
+					type="request",
+					route=~"{{ namespace }}_{{ service }}_{{ service }}(-canary-[0-9]+)?"{{ routeSelector }}
+				}[{{ interval }}]
+			)
+		) by (le)
+	)`,
+}
+
+type ApisixObserver struct {
+	client providers.Interface
+}
+
+func (ob *ApisixObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(apisixQueries["request-success-rate"], model)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+func (ob *ApisixObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(apisixQueries["request-duration"], model)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	ms := time.Duration(int64(value)) * time.Millisecond
+	return ms, nil
+}
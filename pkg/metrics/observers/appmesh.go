@@ -14,7 +14,7 @@ var appMeshQueries = map[string]string{
 			envoy_cluster_upstream_rq{
 				kubernetes_namespace="{{ namespace }}",
 				kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)",
-				envoy_response_code!~"5.*"
+				envoy_response_code!~"5.*"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -23,7 +23,7 @@ var appMeshQueries = map[string]string{
 		rate(
 			envoy_cluster_upstream_rq{
 				kubernetes_namespace="{{ namespace }}",
-				kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"
+				kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -35,7 +35,7 @@ var appMeshQueries = map[string]string{
 			rate(
 				envoy_cluster_upstream_rq_time_bucket{
 					kubernetes_namespace="{{ namespace }}",
-					kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"
+					kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"{{ routeSelector }}
 				}[{{ interval }}]
 			)
 		) by (le)
@@ -74,3 +74,13 @@ func (ob *AppMeshObserver) GetRequestDuration(model flaggerv1.MetricTemplateMode
 	ms := time.Duration(int64(value)) * time.Millisecond
 	return ms, nil
 }
+
+// GetPrimaryRequestSuccessRate runs the success rate query for the primary workload
+func (ob *AppMeshObserver) GetPrimaryRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.GetRequestSuccessRate(primaryModel(model))
+}
+
+// GetPrimaryRequestDuration runs the request duration query for the primary workload
+func (ob *AppMeshObserver) GetPrimaryRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.GetRequestDuration(primaryModel(model))
+}
@@ -14,7 +14,7 @@ var crossoverServiceQueries = map[string]string{
 			envoy_cluster_upstream_rq{
 				kubernetes_namespace="{{ namespace }}",
 				envoy_cluster_name="{{ target }}-canary",
-				envoy_response_code!~"5.*"
+				envoy_response_code!~"5.*"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -23,7 +23,7 @@ var crossoverServiceQueries = map[string]string{
 		rate(
 			envoy_cluster_upstream_rq{
 				kubernetes_namespace="{{ namespace }}",
-				envoy_cluster_name="{{ target }}-canary"
+				envoy_cluster_name="{{ target }}-canary"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -35,7 +35,7 @@ var crossoverServiceQueries = map[string]string{
 			rate(
 				envoy_cluster_upstream_rq_time_bucket{
 					kubernetes_namespace="{{ namespace }}",
-					envoy_cluster_name="{{ target }}-canary"
+					envoy_cluster_name="{{ target }}-canary"{{ routeSelector }}
 				}[{{ interval }}]
 			)
 		) by (le)
@@ -0,0 +1,79 @@
+package observers
+
+import (
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// datagramQueries measure the network errors and drops of the target pods with the cAdvisor metrics,
+// the UDP services can't be measured by the service mesh or ingress proxies
+var datagramQueries = map[string]string{
+	"datagram-error-rate": `
+	sum(
+		rate(
+			container_network_receive_errors_total{
+				namespace="{{ namespace }}",
+				pod=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"
+			}[{{ interval }}]
+		)
+		+
+		rate(
+			container_network_receive_packets_dropped_total{
+				namespace="{{ namespace }}",
+				pod=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"
+			}[{{ interval }}]
+		)
+		+
+		rate(
+			container_network_transmit_errors_total{
+				namespace="{{ namespace }}",
+				pod=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"
+			}[{{ interval }}]
+		)
+		+
+		rate(
+			container_network_transmit_packets_dropped_total{
+				namespace="{{ namespace }}",
+				pod=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"
+			}[{{ interval }}]
+		)
+	)
+	/
+	sum(
+		rate(
+			container_network_receive_packets_total{
+				namespace="{{ namespace }}",
+				pod=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"
+			}[{{ interval }}]
+		)
+		+
+		rate(
+			container_network_transmit_packets_total{
+				namespace="{{ namespace }}",
+				pod=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"
+			}[{{ interval }}]
+		)
+	)
+	* 100`,
+}
+
+// DatagramObserver measures the packet error rate of the workloads serving UDP traffic
+type DatagramObserver struct {
+	client providers.Interface
+}
+
+// GetDatagramErrorRate returns the percentage of the received and transmitted packets
+// that were dropped or failed
+func (ob *DatagramObserver) GetDatagramErrorRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(datagramQueries["datagram-error-rate"], model)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
@@ -0,0 +1,53 @@
+package observers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+func TestDatagramObserver_GetDatagramErrorRate(t *testing.T) {
+	expected := ` sum( rate( container_network_receive_errors_total{ namespace="default", pod=~"podinfo-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)" }[1m] ) + rate( container_network_receive_packets_dropped_total{ namespace="default", pod=~"podinfo-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)" }[1m] ) + rate( container_network_transmit_errors_total{ namespace="default", pod=~"podinfo-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)" }[1m] ) + rate( container_network_transmit_packets_dropped_total{ namespace="default", pod=~"podinfo-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)" }[1m] ) ) / sum( rate( container_network_receive_packets_total{ namespace="default", pod=~"podinfo-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)" }[1m] ) + rate( container_network_transmit_packets_total{ namespace="default", pod=~"podinfo-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)" }[1m] ) ) * 100`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		promql := r.URL.Query()["query"][0]
+		if promql != expected {
+			t.Errorf("\nGot %s \nWanted %s", promql, expected)
+		}
+
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1,"0.5"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	client, err := providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: nil,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	observer := &DatagramObserver{
+		client: client,
+	}
+
+	val, err := observer.GetDatagramErrorRate(flaggerv1.MetricTemplateModel{
+		Name:      "podinfo",
+		Namespace: "default",
+		Target:    "podinfo",
+		Service:   "podinfo",
+		Interval:  "1m",
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if val != 0.5 {
+		t.Errorf("Got %v wanted %v", val, 0.5)
+	}
+}
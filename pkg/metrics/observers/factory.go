@@ -11,21 +11,38 @@ type Factory struct {
 	Client providers.Interface
 }
 
-func NewFactory(metricsServer string) (*Factory, error) {
-	client, err := providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+// NewFactory returns an observer factory for the given Prometheus server,
+// if a bearer token file is specified the token is sent with every query
+func NewFactory(metricsServer string, bearerTokenFile string) (*Factory, error) {
+	spec := flaggerv1.MetricTemplateProvider{
 		Type:      "prometheus",
 		Address:   metricsServer,
 		SecretRef: nil,
-	}, nil)
+	}
+	client, err := providers.NewPrometheusProvider(spec, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	if bearerTokenFile != "" {
+		if err := client.SetBearerTokenFile(bearerTokenFile); err != nil {
+			return nil, err
+		}
+	}
+
 	return &Factory{
-		Client: client,
+		Client: providers.NewInstrumentedProvider(client, spec),
 	}, nil
 }
 
+// DatagramObserver returns the observer of the UDP services, the packet metrics
+// are reported by cAdvisor for every mesh provider
+func (factory Factory) DatagramObserver() *DatagramObserver {
+	return &DatagramObserver{
+		client: factory.Client,
+	}
+}
+
 func (factory Factory) Observer(provider string) Interface {
 	switch {
 	case provider == "none":
@@ -36,6 +53,10 @@ func (factory Factory) Observer(provider string) Interface {
 		return &HttpObserver{
 			client: factory.Client,
 		}
+	case provider == "externaldns" || provider == "alb":
+		return &HttpObserver{
+			client: factory.Client,
+		}
 	case provider == "appmesh":
 		return &AppMeshObserver{
 			client: factory.Client,
@@ -52,7 +73,7 @@ func (factory Factory) Observer(provider string) Interface {
 		return &GlooObserver{
 			client: factory.Client,
 		}
-	case provider == "smi:linkerd":
+	case strings.HasPrefix(provider, "smi:linkerd"):
 		return &LinkerdObserver{
 			client: factory.Client,
 		}
@@ -60,14 +81,38 @@ func (factory Factory) Observer(provider string) Interface {
 		return &CrossoverServiceObserver{
 			client: factory.Client,
 		}
-	case provider == "linkerd":
+	case provider == "linkerd" || strings.HasPrefix(provider, "linkerd:v1alpha"):
 		return &LinkerdObserver{
 			client: factory.Client,
 		}
+	case provider == "osm":
+		return &OsmObserver{
+			client: factory.Client,
+		}
+	case provider == "kuma":
+		return &KumaObserver{
+			client: factory.Client,
+		}
 	case provider == "contour":
 		return &ContourObserver{
 			client: factory.Client,
 		}
+	case provider == "haproxy":
+		return &HAProxyObserver{
+			client: factory.Client,
+		}
+	case provider == "kong":
+		return &KongObserver{
+			client: factory.Client,
+		}
+	case provider == "apisix":
+		return &ApisixObserver{
+			client: factory.Client,
+		}
+	case provider == "ambassador":
+		return &AmbassadorObserver{
+			client: factory.Client,
+		}
 	default:
 		return &IstioObserver{
 			client: factory.Client,
@@ -0,0 +1,114 @@
+package observers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// queryFunctions returns the helper functions available in the query templates,
+// the argument order follows sprig so that the last argument can be piped
+func queryFunctions() template.FuncMap {
+	return template.FuncMap{
+		"default":    defaultValue,
+		"replace":    func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"quote":      func(s string) string { return strconv.Quote(s) },
+		"squote":     func(s string) string { return "'" + s + "'" },
+		"toJson":     toJSON,
+
+		"durationSeconds": func(d string) (int64, error) {
+			v, err := time.ParseDuration(d)
+			return int64(v.Seconds()), err
+		},
+		"durationMinutes": func(d string) (int64, error) {
+			v, err := time.ParseDuration(d)
+			return int64(v.Minutes()), err
+		},
+		"addDuration": func(a, b string) (string, error) {
+			return durationMath(a, b, func(x, y time.Duration) time.Duration { return x + y })
+		},
+		"subDuration": func(a, b string) (string, error) {
+			return durationMath(a, b, func(x, y time.Duration) time.Duration { return x - y })
+		},
+		"mulDuration": func(n int, d string) (string, error) {
+			v, err := time.ParseDuration(d)
+			if err != nil {
+				return "", err
+			}
+			return formatDuration(v * time.Duration(n)), nil
+		},
+	}
+}
+
+// defaultValue returns the given value or the default if the value is empty
+func defaultValue(def interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || isEmpty(value[0]) {
+		return def
+	}
+	return value[0]
+}
+
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() == 0
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("toJson error: %s", err.Error())
+	}
+	return string(b), nil
+}
+
+func durationMath(a, b string, fn func(x, y time.Duration) time.Duration) (string, error) {
+	x, err := time.ParseDuration(a)
+	if err != nil {
+		return "", err
+	}
+	y, err := time.ParseDuration(b)
+	if err != nil {
+		return "", err
+	}
+	return formatDuration(fn(x, y)), nil
+}
+
+// formatDuration prints a duration in the largest unit that divides it, e.g. 2m instead of 2m0s,
+// so that the result can be used in PromQL range selectors
+func formatDuration(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "0s"
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return fmt.Sprintf("%dms", d/time.Millisecond)
+}
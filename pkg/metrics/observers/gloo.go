@@ -15,7 +15,7 @@ var glooQueries = map[string]string{
 		rate(
 			envoy_cluster_upstream_rq{
 				envoy_cluster_name=~"{{ namespace }}-{{ target }}-canary-[0-9a-zA-Z-]+_[0-9a-zA-Z-]+",
-				envoy_response_code!~"5.*"
+				envoy_response_code!~"5.*"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -23,7 +23,7 @@ var glooQueries = map[string]string{
 	sum(
 		rate(
 			envoy_cluster_upstream_rq{
-				envoy_cluster_name=~"{{ namespace }}-{{ target }}-canary-[0-9a-zA-Z-]+_[0-9a-zA-Z-]+",
+				envoy_cluster_name=~"{{ namespace }}-{{ target }}-canary-[0-9a-zA-Z-]+_[0-9a-zA-Z-]+"{{ routeSelector }},
 			}[{{ interval }}]
 		)
 	) 
@@ -34,7 +34,7 @@ var glooQueries = map[string]string{
 		sum(
 			rate(
 				envoy_cluster_upstream_rq_time_bucket{
-					envoy_cluster_name=~"{{ namespace }}-{{ target }}-canary-[0-9a-zA-Z-]+_[0-9a-zA-Z-]+",
+					envoy_cluster_name=~"{{ namespace }}-{{ target }}-canary-[0-9a-zA-Z-]+_[0-9a-zA-Z-]+"{{ routeSelector }},
 				}[{{ interval }}]
 			)
 		) by (le)
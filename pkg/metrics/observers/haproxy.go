@@ -0,0 +1,70 @@
+package observers
+
+import (
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// HAProxy Ingress names the backends <namespace>_<service>_<port>
+var haproxyQueries = map[string]string{
+	"request-success-rate": `
+	sum(
+		rate(
+			haproxy_backend_http_responses_total{
+				proxy=~"{{ namespace }}_{{ service }}-canary_.+",
+				code!="5xx"{{ routeSelector }}
+			}[{{ interval }}]
+		)
+	)
+	/
+	sum(
+		rate(
+			haproxy_backend_http_responses_total{
+				proxy=~"{{ namespace }}_{{ service }}-canary_.+"{{ routeSelector }}
+			}[{{ interval }}]
+		)
+	)
+	* 100`,
+	"request-duration": `
+	avg(
+		haproxy_backend_total_time_average_seconds{
+			proxy=~"{{ namespace }}_{{ service }}-canary_.+"{{ routeSelector }}
+		}
+	)
+	* 1000`,
+}
+
+type HAProxyObserver struct {
+	client providers.Interface
+}
+
+func (ob *HAProxyObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(haproxyQueries["request-success-rate"], model)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+func (ob *HAProxyObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(haproxyQueries["request-duration"], model)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	ms := time.Duration(int64(value)) * time.Millisecond
+	return ms, nil
+}
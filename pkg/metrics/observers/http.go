@@ -14,7 +14,7 @@ var httpQueries = map[string]string{
 			http_request_duration_seconds_count{
 				kubernetes_namespace="{{ namespace }}",
 				kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)",
-				status!~"5.*"
+				status!~"5.*"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -23,7 +23,7 @@ var httpQueries = map[string]string{
 		rate(
 			http_request_duration_seconds_count{
 				kubernetes_namespace="{{ namespace }}",
-				kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"
+				kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -35,7 +35,7 @@ var httpQueries = map[string]string{
 			rate(
 				http_request_duration_seconds_bucket{
 					kubernetes_namespace="{{ namespace }}",
-					kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"
+					kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"{{ routeSelector }}
 				}[{{ interval }}]
 			)
 		) by (le)
@@ -74,3 +74,13 @@ func (ob *HttpObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel)
 	ms := time.Duration(int64(value*1000)) * time.Millisecond
 	return ms, nil
 }
+
+// GetPrimaryRequestSuccessRate runs the success rate query for the primary workload
+func (ob *HttpObserver) GetPrimaryRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.GetRequestSuccessRate(primaryModel(model))
+}
+
+// GetPrimaryRequestDuration runs the request duration query for the primary workload
+func (ob *HttpObserver) GetPrimaryRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.GetRequestDuration(primaryModel(model))
+}
@@ -15,7 +15,7 @@ var istioQueries = map[string]string{
 				reporter="destination",
 				destination_workload_namespace="{{ namespace }}",
 				destination_workload=~"{{ target }}",
-				response_code!~"5.*"
+				response_code!~"5.*"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -25,7 +25,7 @@ var istioQueries = map[string]string{
 			istio_requests_total{
 				reporter="destination",
 				destination_workload_namespace="{{ namespace }}",
-				destination_workload=~"{{ target }}"
+				destination_workload=~"{{ target }}"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -38,7 +38,7 @@ var istioQueries = map[string]string{
 				istio_request_duration_seconds_bucket{
 					reporter="destination",
 					destination_workload_namespace="{{ namespace }}",
-					destination_workload=~"{{ target }}"
+					destination_workload=~"{{ target }}"{{ routeSelector }}
 				}[{{ interval }}]
 			)
 		) by (le)
@@ -77,3 +77,13 @@ func (ob *IstioObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel)
 	ms := time.Duration(int64(value*1000)) * time.Millisecond
 	return ms, nil
 }
+
+// GetPrimaryRequestSuccessRate runs the success rate query for the primary workload
+func (ob *IstioObserver) GetPrimaryRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.GetRequestSuccessRate(primaryModel(model))
+}
+
+// GetPrimaryRequestDuration runs the request duration query for the primary workload
+func (ob *IstioObserver) GetPrimaryRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.GetRequestDuration(primaryModel(model))
+}
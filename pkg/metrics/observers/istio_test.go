@@ -95,3 +95,46 @@ func TestIstioObserver_GetRequestDuration(t *testing.T) {
 		t.Errorf("Got %v wanted %v", val, 100*time.Millisecond)
 	}
 }
+
+func TestIstioObserver_GetPrimaryRequestSuccessRate(t *testing.T) {
+	expected := ` sum( rate( istio_requests_total{ reporter="destination", destination_workload_namespace="default", destination_workload=~"podinfo-primary", response_code!~"5.*" }[1m] ) ) / sum( rate( istio_requests_total{ reporter="destination", destination_workload_namespace="default", destination_workload=~"podinfo-primary" }[1m] ) ) * 100`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		promql := r.URL.Query()["query"][0]
+		if promql != expected {
+			t.Errorf("\nGot %s \nWanted %s", promql, expected)
+		}
+
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1,"99"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	client, err := providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: nil,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	observer := &IstioObserver{
+		client: client,
+	}
+
+	val, err := observer.GetPrimaryRequestSuccessRate(flaggerv1.MetricTemplateModel{
+		Name:      "podinfo",
+		Namespace: "default",
+		Target:    "podinfo",
+		Service:   "podinfo",
+		Interval:  "1m",
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if val != 99 {
+		t.Errorf("Got %v wanted %v", val, 99)
+	}
+}
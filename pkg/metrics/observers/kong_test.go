@@ -0,0 +1,45 @@
+package observers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+func TestKongObserver_NoBuiltinMetrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected query %s", r.URL.Query()["query"][0])
+	}))
+	defer ts.Close()
+
+	client, err := providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: nil,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	observer := &KongObserver{
+		client: client,
+	}
+
+	model := flaggerv1.MetricTemplateModel{
+		Name:      "podinfo",
+		Namespace: "default",
+		Target:    "podinfo",
+		Service:   "podinfo",
+		Interval:  "1m",
+	}
+
+	if _, err := observer.GetRequestSuccessRate(model); err != errKongBuiltinMetrics {
+		t.Errorf("Got %v wanted %v", err, errKongBuiltinMetrics)
+	}
+	if _, err := observer.GetRequestDuration(model); err != errKongBuiltinMetrics {
+		t.Errorf("Got %v wanted %v", err, errKongBuiltinMetrics)
+	}
+}
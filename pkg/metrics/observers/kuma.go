@@ -0,0 +1,104 @@
+package observers
+
+import (
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// the Kuma data planes export the Envoy inbound stats labeled with their service tag,
+// the inbound clusters are named localhost_<port>
+var kumaQueries = map[string]string{
+	"request-success-rate": `
+	sum(
+		rate(
+			envoy_cluster_upstream_rq{
+				kuma_io_service=~"{{ service }}_{{ namespace }}_svc_[0-9]+",
+				envoy_cluster_name=~"localhost_[0-9]+",
+				envoy_response_code!~"5.*"
+			}[{{ interval }}]
+		)
+	)
+	/
+	sum(
+		rate(
+			envoy_cluster_upstream_rq{
+				kuma_io_service=~"{{ service }}_{{ namespace }}_svc_[0-9]+",
+				envoy_cluster_name=~"localhost_[0-9]+"
+			}[{{ interval }}]
+		)
+	)
+	* 100`,
+	"request-duration": `
+	histogram_quantile(
+		0.99,
+		sum(
+			rate(
+				envoy_cluster_upstream_rq_time_bucket{
+					kuma_io_service=~"{{ service }}_{{ namespace }}_svc_[0-9]+",
+					envoy_cluster_name=~"localhost_[0-9]+"
+				}[{{ interval }}]
+			)
+		) by (le)
+	)`,
+}
+
+// KumaObserver queries the Envoy metrics exported by the Kuma data planes
+type KumaObserver struct {
+	client providers.Interface
+}
+
+func (ob *KumaObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.successRate(kumaServiceModel(model, "canary"))
+}
+
+func (ob *KumaObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.duration(kumaServiceModel(model, "canary"))
+}
+
+// GetPrimaryRequestSuccessRate runs the success rate query for the primary workload
+func (ob *KumaObserver) GetPrimaryRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.successRate(kumaServiceModel(model, "primary"))
+}
+
+// GetPrimaryRequestDuration runs the request duration query for the primary workload
+func (ob *KumaObserver) GetPrimaryRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.duration(kumaServiceModel(model, "primary"))
+}
+
+func (ob *KumaObserver) successRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(kumaQueries["request-success-rate"], model)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+func (ob *KumaObserver) duration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(kumaQueries["request-duration"], model)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	ms := time.Duration(int64(value)) * time.Millisecond
+	return ms, nil
+}
+
+// kumaServiceModel selects the data planes of the canary or primary service,
+// the data planes are tagged with the service that selects their pods
+func kumaServiceModel(model flaggerv1.MetricTemplateModel, suffix string) flaggerv1.MetricTemplateModel {
+	model.Service = model.Service + "-" + suffix
+	return model
+}
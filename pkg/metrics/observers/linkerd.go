@@ -15,7 +15,7 @@ var linkerdQueries = map[string]string{
 				namespace="{{ namespace }}",
 				deployment=~"{{ target }}",
 				classification!="failure",
-				direction="inbound"
+				direction="inbound"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -25,7 +25,7 @@ var linkerdQueries = map[string]string{
 			response_total{
 				namespace="{{ namespace }}",
 				deployment=~"{{ target }}",
-				direction="inbound"
+				direction="inbound"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -38,7 +38,7 @@ var linkerdQueries = map[string]string{
 				response_latency_ms_bucket{
 					namespace="{{ namespace }}",
 					deployment=~"{{ target }}",
-					direction="inbound"
+					direction="inbound"{{ routeSelector }}
 				}[{{ interval }}]
 			)
 		) by (le)
@@ -77,3 +77,13 @@ func (ob *LinkerdObserver) GetRequestDuration(model flaggerv1.MetricTemplateMode
 	ms := time.Duration(int64(value)) * time.Millisecond
 	return ms, nil
 }
+
+// GetPrimaryRequestSuccessRate runs the success rate query for the primary workload
+func (ob *LinkerdObserver) GetPrimaryRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.GetRequestSuccessRate(primaryModel(model))
+}
+
+// GetPrimaryRequestDuration runs the request duration query for the primary workload
+func (ob *LinkerdObserver) GetPrimaryRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.GetRequestDuration(primaryModel(model))
+}
@@ -14,7 +14,7 @@ var nginxQueries = map[string]string{
 			nginx_ingress_controller_requests{
 				namespace="{{ namespace }}",
 				ingress="{{ ingress }}",
-				status!~"5.*"
+				status!~"5.*"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -23,7 +23,7 @@ var nginxQueries = map[string]string{
 		rate(
 			nginx_ingress_controller_requests{
 				namespace="{{ namespace }}",
-				ingress="{{ ingress }}"
+				ingress="{{ ingress }}"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -33,7 +33,7 @@ var nginxQueries = map[string]string{
 		rate(
 			nginx_ingress_controller_ingress_upstream_latency_seconds_sum{
 				namespace="{{ namespace }}",
-				ingress="{{ ingress }}"
+				ingress="{{ ingress }}"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
@@ -42,7 +42,7 @@ var nginxQueries = map[string]string{
 		rate(
 			nginx_ingress_controller_ingress_upstream_latency_seconds_count{
 				namespace="{{ namespace }}",
-				ingress="{{ ingress }}"
+				ingress="{{ ingress }}"{{ routeSelector }}
 			}[{{ interval }}]
 		)
 	) 
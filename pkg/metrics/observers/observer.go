@@ -10,3 +10,16 @@ type Interface interface {
 	GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error)
 	GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error)
 }
+
+// PrimaryInterface is implemented by the observers that select the workload by target in their queries,
+// they can measure the primary workload with the same queries as the canary
+type PrimaryInterface interface {
+	GetPrimaryRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error)
+	GetPrimaryRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error)
+}
+
+// primaryModel returns the query model of the primary workload
+func primaryModel(model flaggerv1.MetricTemplateModel) flaggerv1.MetricTemplateModel {
+	model.Target = model.Target + "-primary"
+	return model
+}
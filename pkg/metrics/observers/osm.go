@@ -0,0 +1,90 @@
+package observers
+
+import (
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+var osmQueries = map[string]string{
+	"request-success-rate": `
+	sum(
+		rate(
+			osm_request_total{
+				destination_namespace="{{ namespace }}",
+				destination_kind="Deployment",
+				destination_name=~"{{ target }}",
+				response_code!~"5.*"
+			}[{{ interval }}]
+		)
+	)
+	/
+	sum(
+		rate(
+			osm_request_total{
+				destination_namespace="{{ namespace }}",
+				destination_kind="Deployment",
+				destination_name=~"{{ target }}"
+			}[{{ interval }}]
+		)
+	)
+	* 100`,
+	"request-duration": `
+	histogram_quantile(
+		0.99,
+		sum(
+			rate(
+				osm_request_duration_ms_bucket{
+					destination_namespace="{{ namespace }}",
+					destination_kind="Deployment",
+					destination_name=~"{{ target }}"
+				}[{{ interval }}]
+			)
+		) by (le)
+	)`,
+}
+
+// OsmObserver queries the request metrics exported by the Open Service Mesh sidecars
+type OsmObserver struct {
+	client providers.Interface
+}
+
+func (ob *OsmObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(osmQueries["request-success-rate"], model)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+func (ob *OsmObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(osmQueries["request-duration"], model)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	ms := time.Duration(int64(value)) * time.Millisecond
+	return ms, nil
+}
+
+// GetPrimaryRequestSuccessRate runs the success rate query for the primary workload
+func (ob *OsmObserver) GetPrimaryRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.GetRequestSuccessRate(primaryModel(model))
+}
+
+// GetPrimaryRequestDuration runs the request duration query for the primary workload
+func (ob *OsmObserver) GetPrimaryRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.GetRequestDuration(primaryModel(model))
+}
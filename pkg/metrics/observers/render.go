@@ -9,7 +9,7 @@ import (
 )
 
 func RenderQuery(queryTemplate string, model flaggerv1.MetricTemplateModel) (string, error) {
-	t, err := template.New("tmpl").Funcs(model.TemplateFunctions()).Parse(queryTemplate)
+	t, err := template.New("tmpl").Funcs(queryFunctions()).Funcs(model.TemplateFunctions()).Parse(queryTemplate)
 	if err != nil {
 		return "", err
 	}
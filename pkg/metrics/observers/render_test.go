@@ -0,0 +1,125 @@
+package observers
+
+import (
+	"testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestRenderQuery_Functions(t *testing.T) {
+	model := flaggerv1.MetricTemplateModel{
+		Name:      "podinfo",
+		Namespace: "default",
+		Target:    "podinfo",
+		Service:   "podinfo",
+		Interval:  "1m",
+	}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{`{{ ingress | default "podinfo" }}`, "podinfo"},
+		{`{{ namespace | default "test" }}`, "default"},
+		{`{{ target | replace "info" "-canary" }}`, "pod-canary"},
+		{`{{ printf "%s-primary" target | upper }}`, "PODINFO-PRIMARY"},
+		{`{"Expression": {{ printf "SUM(%s)" target | toJson }}}`, `{"Expression": "SUM(podinfo)"}`},
+		{`{{ interval | durationSeconds }}`, "60"},
+		{`rate(http_requests_total[{{ addDuration interval "30s" }}])`, "rate(http_requests_total[90s])"},
+		{`[{{ interval | mulDuration 5 }}]`, "[5m]"},
+	}
+
+	for _, tt := range tests {
+		query, err := RenderQuery(tt.query, model)
+		if err != nil {
+			t.Fatalf("%s render error: %s", tt.query, err.Error())
+		}
+		if query != tt.expected {
+			t.Errorf("Got %s wanted %s", query, tt.expected)
+		}
+	}
+}
+
+func TestRenderQuery_InvalidDuration(t *testing.T) {
+	_, err := RenderQuery(`{{ "1x" | durationSeconds }}`, flaggerv1.MetricTemplateModel{})
+	if err == nil {
+		t.Errorf("Expected error for invalid duration")
+	}
+}
+
+func TestRenderQuery_Route(t *testing.T) {
+	model := flaggerv1.MetricTemplateModel{
+		Namespace:  "default",
+		Target:     "podinfo",
+		Interval:   "1m",
+		Route:      `/api/checkout/.*|/healthz\.json`,
+		RouteLabel: "request_path",
+	}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{`path=~{{ route | quote }}`, `path=~"/api/checkout/.*|/healthz\\.json"`},
+		{`http_requests_total{app="{{ target }}"{{ routeSelector }}}`,
+			`http_requests_total{app="podinfo",request_path=~"/api/checkout/.*|/healthz\\.json"}`},
+	}
+
+	for _, tt := range tests {
+		query, err := RenderQuery(tt.query, model)
+		if err != nil {
+			t.Fatalf("%s render error: %s", tt.query, err.Error())
+		}
+		if query != tt.expected {
+			t.Errorf("Got %s wanted %s", query, tt.expected)
+		}
+	}
+
+	// not scoped
+	model.Route = ""
+	query, err := RenderQuery(`path=~"{{ route }}"{{ routeSelector }}`, model)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if query != `path=~".*"` {
+		t.Errorf("Got %s wanted %s", query, `path=~".*"`)
+	}
+}
+
+func TestRenderQuery_Workloads(t *testing.T) {
+	model := flaggerv1.MetricTemplateModel{
+		Namespace:       "default",
+		Target:          "podinfo-baseline",
+		Interval:        "2m",
+		CanaryWorkload:  "podinfo",
+		PrimaryWorkload: "podinfo-primary",
+	}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{`{{ canaryWorkload }}`, "podinfo"},
+		{`{{ primaryWorkload }}`, "podinfo-primary"},
+		{`{{ intervalSeconds }}`, "120"},
+		{
+			`errors{pod=~"{{ canaryWorkload }}-.*"}[{{ interval }}] - errors{pod=~"{{ primaryWorkload }}-.*"}[{{ interval }}]`,
+			`errors{pod=~"podinfo-.*"}[2m] - errors{pod=~"podinfo-primary-.*"}[2m]`,
+		},
+	}
+
+	for _, tt := range tests {
+		query, err := RenderQuery(tt.query, model)
+		if err != nil {
+			t.Fatalf("%s render error: %s", tt.query, err.Error())
+		}
+		if query != tt.expected {
+			t.Errorf("Got %s wanted %s", query, tt.expected)
+		}
+	}
+
+	model.Interval = "2x"
+	if _, err := RenderQuery(`{{ intervalSeconds }}`, model); err == nil {
+		t.Errorf("Expected error for invalid interval")
+	}
+}
@@ -0,0 +1,115 @@
+package observers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// SLIQuery generates the query template of an SLI recipe for the given provider type,
+// the selector values can contain template variables e.g. {{ namespace }}
+func SLIQuery(providerType string, sli flaggerv1.MetricTemplateSLI) (string, error) {
+	if sli.Metric == "" {
+		return "", fmt.Errorf("SLI %s metric is required", sli.Recipe)
+	}
+	if sli.Recipe == flaggerv1.SLIErrorRatio && len(sli.ErrorSelector) == 0 {
+		return "", fmt.Errorf("SLI %s error selector is required", sli.Recipe)
+	}
+
+	switch providerType {
+	case "", "prometheus":
+		return prometheusSLIQuery(sli)
+	case "datadog":
+		return datadogSLIQuery(sli)
+	}
+	return "", fmt.Errorf("SLI recipes are not supported by the %s provider", providerType)
+}
+
+func prometheusSLIQuery(sli flaggerv1.MetricTemplateSLI) (string, error) {
+	selector := prometheusSelector(sli.Selector)
+	switch sli.Recipe {
+	case flaggerv1.SLIErrorRatio:
+		errors := prometheusSelector(mergeSelectors(sli.Selector, sli.ErrorSelector))
+		return fmt.Sprintf("sum(rate(%s%s[{{ interval }}])) / sum(rate(%s%s[{{ interval }}])) * 100",
+			sli.Metric, errors, sli.Metric, selector), nil
+	case flaggerv1.SLIP99Latency:
+		return fmt.Sprintf("histogram_quantile(0.99, sum(rate(%s%s[{{ interval }}])) by (le))",
+			sli.Metric, selector), nil
+	case flaggerv1.SLISaturation:
+		usage := prometheusGauge(sli.Metric, selector)
+		if sli.LimitMetric == "" {
+			return fmt.Sprintf("max(%s)", usage), nil
+		}
+		return fmt.Sprintf("sum(%s) / sum(%s) * 100", usage, prometheusGauge(sli.LimitMetric, selector)), nil
+	}
+	return "", fmt.Errorf("SLI recipe %s is not supported", sli.Recipe)
+}
+
+// prometheusGauge returns the per second rate of the counters and the value of the gauges
+func prometheusGauge(metric string, selector string) string {
+	if strings.HasSuffix(metric, "_total") {
+		return fmt.Sprintf("rate(%s%s[{{ interval }}])", metric, selector)
+	}
+	return metric + selector
+}
+
+// prometheusSelector returns the label matchers sorted by name, the values are matched as regex
+func prometheusSelector(labels map[string]string) string {
+	var matchers []string
+	for _, name := range sortedKeys(labels) {
+		matchers = append(matchers, fmt.Sprintf(`%s=~"%s"`, name, labels[name]))
+	}
+	return "{" + strings.Join(matchers, ",") + "}"
+}
+
+func datadogSLIQuery(sli flaggerv1.MetricTemplateSLI) (string, error) {
+	selector := datadogSelector(sli.Selector)
+	switch sli.Recipe {
+	case flaggerv1.SLIErrorRatio:
+		errors := datadogSelector(mergeSelectors(sli.Selector, sli.ErrorSelector))
+		return fmt.Sprintf("100 * sum:%s%s.as_count() / sum:%s%s.as_count()",
+			sli.Metric, errors, sli.Metric, selector), nil
+	case flaggerv1.SLIP99Latency:
+		return fmt.Sprintf("p99:%s%s", sli.Metric, selector), nil
+	case flaggerv1.SLISaturation:
+		if sli.LimitMetric == "" {
+			return fmt.Sprintf("max:%s%s", sli.Metric, selector), nil
+		}
+		return fmt.Sprintf("100 * sum:%s%s / sum:%s%s", sli.Metric, selector, sli.LimitMetric, selector), nil
+	}
+	return "", fmt.Errorf("SLI recipe %s is not supported", sli.Recipe)
+}
+
+// datadogSelector returns the tags sorted by name or * when there are no tags
+func datadogSelector(tags map[string]string) string {
+	if len(tags) == 0 {
+		return "{*}"
+	}
+	var list []string
+	for _, name := range sortedKeys(tags) {
+		list = append(list, fmt.Sprintf("%s:%s", name, tags[name]))
+	}
+	return "{" + strings.Join(list, ",") + "}"
+}
+
+func mergeSelectors(selector map[string]string, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(selector)+len(overrides))
+	for k, v := range selector {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,116 @@
+package observers
+
+import (
+	"testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestSLIQuery(t *testing.T) {
+	selector := map[string]string{
+		"namespace": "{{ namespace }}",
+		"app":       "{{ target }}",
+	}
+
+	tests := []struct {
+		provider string
+		sli      flaggerv1.MetricTemplateSLI
+		expected string
+	}{
+		{
+			provider: "prometheus",
+			sli: flaggerv1.MetricTemplateSLI{
+				Recipe:        flaggerv1.SLIErrorRatio,
+				Metric:        "http_requests_total",
+				Selector:      selector,
+				ErrorSelector: map[string]string{"status": "5.."},
+			},
+			expected: `sum(rate(http_requests_total{app=~"podinfo",namespace=~"default",status=~"5.."}[1m])) / sum(rate(http_requests_total{app=~"podinfo",namespace=~"default"}[1m])) * 100`,
+		},
+		{
+			provider: "prometheus",
+			sli: flaggerv1.MetricTemplateSLI{
+				Recipe:   flaggerv1.SLIP99Latency,
+				Metric:   "http_request_duration_seconds_bucket",
+				Selector: selector,
+			},
+			expected: `histogram_quantile(0.99, sum(rate(http_request_duration_seconds_bucket{app=~"podinfo",namespace=~"default"}[1m])) by (le))`,
+		},
+		{
+			provider: "prometheus",
+			sli: flaggerv1.MetricTemplateSLI{
+				Recipe:      flaggerv1.SLISaturation,
+				Metric:      "container_cpu_usage_seconds_total",
+				LimitMetric: "kube_pod_container_resource_limits_cpu_cores",
+				Selector:    map[string]string{"pod": "{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"},
+			},
+			expected: `sum(rate(container_cpu_usage_seconds_total{pod=~"podinfo-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"}[1m])) / sum(kube_pod_container_resource_limits_cpu_cores{pod=~"podinfo-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"}) * 100`,
+		},
+		{
+			provider: "datadog",
+			sli: flaggerv1.MetricTemplateSLI{
+				Recipe:        flaggerv1.SLIErrorRatio,
+				Metric:        "nginx_ingress.controller.requests",
+				Selector:      map[string]string{"ingress": "{{ ingress }}"},
+				ErrorSelector: map[string]string{"status": "5*"},
+			},
+			expected: `100 * sum:nginx_ingress.controller.requests{ingress:podinfo,status:5*}.as_count() / sum:nginx_ingress.controller.requests{ingress:podinfo}.as_count()`,
+		},
+		{
+			provider: "datadog",
+			sli: flaggerv1.MetricTemplateSLI{
+				Recipe: flaggerv1.SLIP99Latency,
+				Metric: "trace.http.request.duration",
+			},
+			expected: `p99:trace.http.request.duration{*}`,
+		},
+		{
+			provider: "datadog",
+			sli: flaggerv1.MetricTemplateSLI{
+				Recipe:   flaggerv1.SLISaturation,
+				Metric:   "kubernetes.memory.usage",
+				Selector: map[string]string{"kube_deployment": "{{ target }}"},
+			},
+			expected: `max:kubernetes.memory.usage{kube_deployment:podinfo}`,
+		},
+	}
+
+	model := flaggerv1.MetricTemplateModel{
+		Name:      "podinfo",
+		Namespace: "default",
+		Target:    "podinfo",
+		Ingress:   "podinfo",
+		Interval:  "1m",
+	}
+	for _, tt := range tests {
+		queryTemplate, err := SLIQuery(tt.provider, tt.sli)
+		if err != nil {
+			t.Fatalf("%s %s error: %s", tt.provider, tt.sli.Recipe, err.Error())
+		}
+		query, err := RenderQuery(queryTemplate, model)
+		if err != nil {
+			t.Fatalf("%s %s render error: %s", tt.provider, tt.sli.Recipe, err.Error())
+		}
+		if query != tt.expected {
+			t.Errorf("%s %s\nGot %s\nWanted %s", tt.provider, tt.sli.Recipe, query, tt.expected)
+		}
+	}
+}
+
+func TestSLIQuery_Errors(t *testing.T) {
+	tests := []struct {
+		provider string
+		sli      flaggerv1.MetricTemplateSLI
+	}{
+		{"prometheus", flaggerv1.MetricTemplateSLI{Recipe: "apdex", Metric: "http_requests_total"}},
+		{"prometheus", flaggerv1.MetricTemplateSLI{Recipe: flaggerv1.SLIP99Latency}},
+		{"prometheus", flaggerv1.MetricTemplateSLI{Recipe: flaggerv1.SLIErrorRatio, Metric: "http_requests_total"}},
+		{"cloudwatch", flaggerv1.MetricTemplateSLI{Recipe: flaggerv1.SLIP99Latency, Metric: "Latency"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := SLIQuery(tt.provider, tt.sli); err == nil {
+			t.Errorf("%s %+v expected error", tt.provider, tt.sli)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package providers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// Budget tracks the number of queries and the estimated cost spent on a provider
+// during a canary analysis, it is safe for concurrent use
+type Budget struct {
+	spec    flaggerv1.MetricTemplateBudget
+	mux     sync.Mutex
+	queries int
+}
+
+// NewBudget returns an empty budget for the given limits
+func NewBudget(spec flaggerv1.MetricTemplateBudget) *Budget {
+	return &Budget{spec: spec}
+}
+
+// Restrict lowers the limits of the budget to the ones of the given spec,
+// the templates that share a provider are accounted against the strictest limits
+func (b *Budget) Restrict(spec flaggerv1.MetricTemplateBudget) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if spec.MaxQueries > 0 && (b.spec.MaxQueries == 0 || spec.MaxQueries < b.spec.MaxQueries) {
+		b.spec.MaxQueries = spec.MaxQueries
+	}
+	if spec.MaxCost != nil && (b.spec.MaxCost == nil || *spec.MaxCost < *b.spec.MaxCost) {
+		b.spec.MaxCost = spec.MaxCost
+	}
+	if spec.CostPerQuery != nil && (b.spec.CostPerQuery == nil || *spec.CostPerQuery > *b.spec.CostPerQuery) {
+		b.spec.CostPerQuery = spec.CostPerQuery
+	}
+}
+
+// reserve accounts for one query or returns an error if the query would exceed the budget
+func (b *Budget) reserve() error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if b.spec.MaxQueries > 0 && b.queries+1 > b.spec.MaxQueries {
+		return fmt.Errorf("query budget exceeded: %v queries of %v allowed per analysis",
+			b.queries, b.spec.MaxQueries)
+	}
+
+	if b.spec.MaxCost != nil && b.costOf(b.queries+1) > *b.spec.MaxCost {
+		return fmt.Errorf("query budget exceeded: estimated cost %v of %v allowed per analysis",
+			b.costOf(b.queries), *b.spec.MaxCost)
+	}
+
+	b.queries++
+	return nil
+}
+
+// Queries returns the number of queries run against the provider
+func (b *Budget) Queries() int {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.queries
+}
+
+// Cost returns the estimated cost of the queries run against the provider
+func (b *Budget) Cost() float64 {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.costOf(b.queries)
+}
+
+func (b *Budget) costOf(queries int) float64 {
+	if b.spec.CostPerQuery == nil {
+		return 0
+	}
+	return float64(queries) * *b.spec.CostPerQuery
+}
+
+// BudgetProvider wraps a provider and refuses to run queries once the budget is spent
+type BudgetProvider struct {
+	provider Interface
+	budget   *Budget
+}
+
+// NewBudgetProvider returns a provider that accounts every query against the budget
+func NewBudgetProvider(provider Interface, budget *Budget) *BudgetProvider {
+	return &BudgetProvider{
+		provider: provider,
+		budget:   budget,
+	}
+}
+
+// RunQuery executes the query if the budget allows it
+func (p *BudgetProvider) RunQuery(query string) (float64, error) {
+	if err := p.budget.reserve(); err != nil {
+		return 0, err
+	}
+	return p.provider.RunQuery(query)
+}
+
+// RunRangeQuery executes the range query if the budget allows it, a range query counts as one query
+func (p *BudgetProvider) RunRangeQuery(query string, interval time.Duration, step time.Duration) ([]DataPoint, error) {
+	if err := p.budget.reserve(); err != nil {
+		return nil, err
+	}
+	return RunRangeQuery(p.provider, query, interval, step)
+}
+
+// RunQueryAt executes the query at the given time if the budget allows it
+func (p *BudgetProvider) RunQueryAt(query string, t time.Time) (float64, error) {
+	if err := p.budget.reserve(); err != nil {
+		return 0, err
+	}
+	return RunQueryAt(p.provider, query, t)
+}
+
+// IsOnline calls the provider endpoint, the liveness checks are not accounted
+func (p *BudgetProvider) IsOnline() (bool, error) {
+	return p.provider.IsOnline()
+}
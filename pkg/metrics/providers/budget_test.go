@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestBudgetProvider_RunQuery(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	budget := NewBudget(flaggerv1.MetricTemplateBudget{MaxQueries: 2})
+	provider := NewBudgetProvider(prom, budget)
+
+	for i := 0; i < 2; i++ {
+		if _, err := provider.RunQuery("sum(envoy_cluster_upstream_rq)"); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	_, err = provider.RunQuery("sum(envoy_cluster_upstream_rq)")
+	if err == nil || !strings.Contains(err.Error(), "query budget exceeded") {
+		t.Errorf("Got error %v wanted query budget exceeded", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("Got %v calls wanted %v", calls, 2)
+	}
+
+	if budget.Queries() != 2 {
+		t.Errorf("Got %v queries wanted %v", budget.Queries(), 2)
+	}
+}
+
+func TestBudgetProvider_MaxCost(t *testing.T) {
+	costPerQuery := 0.01
+	maxCost := 0.025
+	budget := NewBudget(flaggerv1.MetricTemplateBudget{CostPerQuery: &costPerQuery, MaxCost: &maxCost})
+
+	for i := 0; i < 2; i++ {
+		if err := budget.reserve(); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	if err := budget.reserve(); err == nil {
+		t.Errorf("Expected cost cap %v to be enforced", maxCost)
+	}
+
+	if budget.Cost() != 0.02 {
+		t.Errorf("Got cost %v wanted %v", budget.Cost(), 0.02)
+	}
+}
+
+func TestFactory_ProviderWithBudget(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	budget := NewBudget(flaggerv1.MetricTemplateBudget{MaxQueries: 2})
+	factory := Factory{Budget: budget}
+	provider, err := factory.Provider("1m", flaggerv1.MetricTemplateProvider{
+		Type:    "prometheus",
+		Address: ts.URL,
+		Retry:   &flaggerv1.MetricTemplateRetry{Attempts: 3, Backoff: "1ms"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = provider.RunQuery("sum(envoy_cluster_upstream_rq)")
+	if err == nil || !strings.Contains(err.Error(), "query budget exceeded") {
+		t.Errorf("Got error %v wanted query budget exceeded", err)
+	}
+
+	// retries are accounted against the budget
+	if calls != 2 {
+		t.Errorf("Got %v calls wanted %v", calls, 2)
+	}
+}
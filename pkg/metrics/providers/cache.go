@@ -0,0 +1,177 @@
+package providers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// sharedCache holds the query results of all providers, the canary analysis
+// of different canaries can reuse the results of the templates they share
+var sharedCache = newQueryCache()
+
+type cacheEntry struct {
+	value   float64
+	expires time.Time
+}
+
+// inflightQuery is used to wait for the result of a query that is already running
+type inflightQuery struct {
+	done  chan struct{}
+	value float64
+	err   error
+}
+
+// queryCache stores the successful query results for a short period of time
+// and deduplicates the identical queries that run concurrently
+type queryCache struct {
+	mux       sync.Mutex
+	entries   map[string]cacheEntry
+	inflight  map[string]*inflightQuery
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newQueryCache() *queryCache {
+	return &queryCache{
+		entries:  make(map[string]cacheEntry),
+		inflight: make(map[string]*inflightQuery),
+		now:      time.Now,
+	}
+}
+
+func (c *queryCache) do(key string, ttl time.Duration, fn func() (float64, error)) (float64, error) {
+	c.mux.Lock()
+	now := c.now()
+	if entry, ok := c.entries[key]; ok && now.Before(entry.expires) {
+		c.mux.Unlock()
+		return entry.value, nil
+	}
+	if q, ok := c.inflight[key]; ok {
+		c.mux.Unlock()
+		<-q.done
+		return q.value, q.err
+	}
+	q := &inflightQuery{done: make(chan struct{})}
+	c.inflight[key] = q
+	c.mux.Unlock()
+
+	q.value, q.err = fn()
+
+	c.mux.Lock()
+	delete(c.inflight, key)
+	if q.err == nil {
+		c.entries[key] = cacheEntry{value: q.value, expires: c.now().Add(ttl)}
+	}
+	c.sweep()
+	c.mux.Unlock()
+	close(q.done)
+
+	return q.value, q.err
+}
+
+// sweep removes the expired entries at most once a minute, the caller must hold the lock
+func (c *queryCache) sweep() {
+	now := c.now()
+	if now.Sub(c.lastSweep) < time.Minute {
+		return
+	}
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.lastSweep = now
+}
+
+// CacheProvider wraps a provider and serves the query results from the shared cache
+type CacheProvider struct {
+	provider Interface
+	prefix   string
+	ttl      time.Duration
+	cache    *queryCache
+}
+
+// NewCacheProvider parses the cache TTL and returns a provider that caches the query results,
+// the cache key is derived from the provider address, interval, credentials and the rendered query
+func NewCacheProvider(
+	provider Interface,
+	metricInterval string,
+	spec flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte,
+) (*CacheProvider, error) {
+	ttl, err := time.ParseDuration(spec.CacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing cache TTL: %s", err.Error())
+	}
+
+	return &CacheProvider{
+		provider: provider,
+		prefix:   cachePrefix(metricInterval, spec, credentials),
+		ttl:      ttl,
+		cache:    sharedCache,
+	}, nil
+}
+
+// RunQuery returns the cached result or executes the query
+func (p *CacheProvider) RunQuery(query string) (float64, error) {
+	return p.cache.do(p.prefix+query, p.ttl, func() (float64, error) {
+		return p.provider.RunQuery(query)
+	})
+}
+
+// RunRangeQuery executes the range query, the datapoints are not cached
+func (p *CacheProvider) RunRangeQuery(query string, interval time.Duration, step time.Duration) ([]DataPoint, error) {
+	return RunRangeQuery(p.provider, query, interval, step)
+}
+
+// RunQueryAt executes the query at the given time truncated to the cache TTL,
+// the queries made within the same TTL window share the cached result
+func (p *CacheProvider) RunQueryAt(query string, t time.Time) (float64, error) {
+	t = t.Truncate(p.ttl)
+	key := fmt.Sprintf("%s%s@%d", p.prefix, query, t.UnixNano())
+	return p.cache.do(key, p.ttl, func() (float64, error) {
+		return RunQueryAt(p.provider, query, t)
+	})
+}
+
+// IsOnline calls the provider endpoint, the result is not cached
+func (p *CacheProvider) IsOnline() (bool, error) {
+	return p.provider.IsOnline()
+}
+
+// cachePrefix returns a hash of the provider settings so that providers with
+// different addresses, credentials, token files or identity headers don't share results
+func cachePrefix(metricInterval string, spec flaggerv1.MetricTemplateProvider, credentials map[string][]byte) string {
+	h := sha256.New()
+	h.Write([]byte(spec.Type))
+	h.Write([]byte(spec.Address))
+	h.Write([]byte(spec.TokenFile))
+	h.Write([]byte(metricInterval))
+
+	keys := make([]string, 0, len(credentials))
+	for k := range credentials {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write(credentials[k])
+	}
+
+	names := make([]string, 0, len(spec.Headers))
+	for k := range spec.Headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		h.Write([]byte(k))
+		h.Write([]byte(spec.Headers[k]))
+	}
+
+	return fmt.Sprintf("%x/", h.Sum(nil))
+}
@@ -0,0 +1,135 @@
+package providers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestCacheProvider_RunQuery(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	spec := flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL, CacheTTL: "1m"}
+	factory := Factory{}
+
+	for i := 0; i < 3; i++ {
+		provider, err := factory.Provider("1m", spec, nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		val, err := provider.RunQuery("sum(envoy_cluster_upstream_rq)")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if val != 100 {
+			t.Errorf("Got %v wanted %v", val, 100)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Got %v calls wanted %v", calls, 1)
+	}
+
+	provider, err := factory.Provider("5m", spec, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := provider.RunQuery("sum(envoy_cluster_upstream_rq)"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the interval is part of the cache key
+	if calls != 2 {
+		t.Errorf("Got %v calls wanted %v", calls, 2)
+	}
+}
+
+func TestCacheProvider_RunQueryAt(t *testing.T) {
+	var times []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, r.URL.Query().Get("time"))
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	spec := flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL, CacheTTL: "1m"}
+	provider, err := Factory{}.Provider("1m", spec, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the timestamps within the same TTL window share the cached result
+	now := time.Date(2020, 1, 1, 10, 0, 5, 0, time.UTC)
+	for _, at := range []time.Time{now, now.Add(10 * time.Second), now.Add(50 * time.Second), now.Add(time.Minute)} {
+		if _, err := RunQueryAt(provider, "sum(istio_requests_total)", at); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	if len(times) != 2 {
+		t.Fatalf("Got %v calls wanted %v", len(times), 2)
+	}
+	// the query runs at the start of the window so that the cached result doesn't depend on the first caller
+	if times[0] != "1577872800.000" {
+		t.Errorf("Got query time %s wanted %s", times[0], "1577872800.000")
+	}
+}
+
+func TestQueryCache_Expiry(t *testing.T) {
+	cache := newQueryCache()
+	now := time.Now()
+	cache.now = func() time.Time {
+		return now
+	}
+
+	calls := 0
+	fn := func() (float64, error) {
+		calls++
+		return float64(calls), nil
+	}
+
+	cache.do("query", time.Minute, fn)
+	now = now.Add(30 * time.Second)
+	if val, _ := cache.do("query", time.Minute, fn); val != 1 {
+		t.Errorf("Got %v wanted cached value %v", val, 1)
+	}
+
+	now = now.Add(time.Minute)
+	if val, _ := cache.do("query", time.Minute, fn); val != 2 {
+		t.Errorf("Got %v wanted new value %v", val, 2)
+	}
+}
+
+func TestQueryCache_Errors(t *testing.T) {
+	cache := newQueryCache()
+
+	calls := 0
+	fn := func() (float64, error) {
+		calls++
+		return 0, errors.New("no values found")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.do("query", time.Minute, fn); err == nil {
+			t.Errorf("Expected error")
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("Got %v calls wanted %v, errors must not be cached", calls, 2)
+	}
+}
@@ -0,0 +1,218 @@
+package providers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// ClientOptions holds the connection settings of the HTTP client shared by all providers
+type ClientOptions struct {
+	// Timeout is the maximum duration of a provider request
+	Timeout time.Duration
+
+	// DialTimeout is the maximum duration to establish a TCP connection
+	DialTimeout time.Duration
+
+	// IdleConnTimeout is the duration an idle connection is kept in the pool
+	IdleConnTimeout time.Duration
+
+	// MaxIdleConnsPerHost is the number of idle connections kept in the pool for each provider address
+	MaxIdleConnsPerHost int
+
+	// TokenDir is the directory of the bearer token files referenced by the metric templates,
+	// the token files can't be used when empty
+	TokenDir string
+}
+
+// DefaultClientOptions are used when the providers client is not configured
+var DefaultClientOptions = ClientOptions{
+	Timeout:             5 * time.Second,
+	DialTimeout:         5 * time.Second,
+	IdleConnTimeout:     90 * time.Second,
+	MaxIdleConnsPerHost: 10,
+}
+
+var (
+	clientOptions = DefaultClientOptions
+
+	// transports are shared between the provider instances, one per TLS configuration,
+	// so that the connections are reused across the canary analysis runs
+	transports = newTransportPool()
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "flagger",
+		Name:      "provider_request_duration_seconds",
+		Help:      "Duration of the metric provider HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "host", "code"})
+)
+
+func init() {
+	prometheus.MustRegister(requestDuration)
+}
+
+// ConfigureClient sets the connection settings of the shared HTTP client,
+// it must be called before any provider is created
+func ConfigureClient(opts ClientOptions) {
+	clientOptions = opts
+}
+
+// queryTimeout returns the provider timeout or, if not specified, the shared client timeout
+func queryTimeout(provider flaggerv1.MetricTemplateProvider) (time.Duration, error) {
+	if provider.Timeout == "" {
+		return clientOptions.Timeout, nil
+	}
+
+	timeout, err := time.ParseDuration(provider.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing timeout: %s", err.Error())
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("timeout %s must be greater than zero", provider.Timeout)
+	}
+	return timeout, nil
+}
+
+// newHTTPClient returns a client that uses the shared transport for the provider TLS configuration,
+// the CA bundle is read from ca.crt and the client certificate from tls.crt/tls.key
+func newHTTPClient(providerType string, credentials map[string][]byte) (*http.Client, error) {
+	transport, err := transports.get(transportKey(credentials), credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: &instrumentedTransport{
+			next:     transport,
+			provider: providerType,
+		},
+	}, nil
+}
+
+// transportIdleTTL is the duration after which a transport that wasn't used by any provider is removed,
+// the providers are created for each analysis run so the transports of the deleted canaries
+// or of the rotated certificates are released
+const transportIdleTTL = 10 * time.Minute
+
+type transportEntry struct {
+	transport *http.Transport
+	lastUsed  time.Time
+}
+
+// transportPool holds the shared transports and closes the idle connections of the unused ones
+type transportPool struct {
+	mux       sync.Mutex
+	entries   map[string]*transportEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newTransportPool() *transportPool {
+	return &transportPool{
+		entries: make(map[string]*transportEntry),
+		now:     time.Now,
+	}
+}
+
+func (p *transportPool) get(key string, credentials map[string][]byte) (*http.Transport, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	now := p.now()
+	entry, ok := p.entries[key]
+	if !ok {
+		t, err := newTransport(credentials)
+		if err != nil {
+			return nil, err
+		}
+		entry = &transportEntry{transport: t}
+		p.entries[key] = entry
+	}
+	entry.lastUsed = now
+	p.sweep()
+
+	return entry.transport, nil
+}
+
+// sweep removes the unused transports at most once a minute, the caller must hold the lock,
+// the clients created before the eviction keep working and open new connections if needed
+func (p *transportPool) sweep() {
+	now := p.now()
+	if now.Sub(p.lastSweep) < time.Minute {
+		return
+	}
+	for k, entry := range p.entries {
+		if now.Sub(entry.lastUsed) >= transportIdleTTL {
+			entry.transport.CloseIdleConnections()
+			delete(p.entries, k)
+		}
+	}
+	p.lastSweep = now
+}
+
+func newTransport(credentials map[string][]byte) (*http.Transport, error) {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   clientOptions.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   clientOptions.MaxIdleConnsPerHost,
+		IdleConnTimeout:       clientOptions.IdleConnTimeout,
+		TLSHandshakeTimeout:   clientOptions.DialTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	if hasTLSCredentials(credentials) {
+		tlsConfig, err := newTLSConfig(credentials)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return transport, nil
+}
+
+// transportKey returns a hash of the TLS material, the key is empty when no TLS credentials are set
+func transportKey(credentials map[string][]byte) string {
+	if !hasTLSCredentials(credentials) {
+		return ""
+	}
+
+	h := sha256.New()
+	for _, key := range []string{tlsCertSecretKey, tlsKeySecretKey, caCertSecretKey} {
+		h.Write([]byte(key))
+		h.Write(credentials[key])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// instrumentedTransport records the duration and the status code of the provider requests
+type instrumentedTransport struct {
+	next     http.RoundTripper
+	provider string
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(res.StatusCode)
+	}
+	requestDuration.WithLabelValues(t.provider, req.URL.Host, code).Observe(time.Since(start).Seconds())
+
+	return res, err
+}
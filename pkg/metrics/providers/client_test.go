@@ -0,0 +1,116 @@
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNewHTTPClient_SharedTransport(t *testing.T) {
+	a, err := newHTTPClient("prometheus", nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	b, err := newHTTPClient("datadog", map[string][]byte{"apiKey": []byte("key")})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if a.Transport.(*instrumentedTransport).next != b.Transport.(*instrumentedTransport).next {
+		t.Errorf("Expected clients without TLS credentials to share the transport")
+	}
+
+	cert, _ := newTestClientCert(t)
+	c, err := newHTTPClient("prometheus", map[string][]byte{caCertSecretKey: cert})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if a.Transport.(*instrumentedTransport).next == c.Transport.(*instrumentedTransport).next {
+		t.Errorf("Expected clients with TLS credentials to use a dedicated transport")
+	}
+}
+
+func TestTransportPool_Eviction(t *testing.T) {
+	now := time.Now()
+	pool := newTransportPool()
+	pool.now = func() time.Time { return now }
+
+	a, err := pool.get("", nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cert, _ := newTestClientCert(t)
+	credentials := map[string][]byte{caCertSecretKey: cert}
+	c, err := pool.get(transportKey(credentials), credentials)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the transport without TLS credentials is still in use
+	now = now.Add(transportIdleTTL / 2)
+	if b, _ := pool.get("", nil); a != b {
+		t.Errorf("Expected the transport to be reused")
+	}
+
+	now = now.Add(transportIdleTTL / 2)
+	if _, err := pool.get("", nil); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(pool.entries) != 1 {
+		t.Fatalf("Got %d transports wanted 1", len(pool.entries))
+	}
+	if _, ok := pool.entries[transportKey(credentials)]; ok {
+		t.Errorf("Expected the unused transport to be evicted")
+	}
+
+	// the evicted transports are recreated on demand
+	if d, _ := pool.get(transportKey(credentials), credentials); c == d {
+		t.Errorf("Expected a new transport after the eviction")
+	}
+}
+
+func TestNewHTTPClient_RequestDuration(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer ts.Close()
+
+	client, err := newHTTPClient("prometheus", nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	res, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	res.Body.Close()
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(requestDuration)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	found := false
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["provider"] == "prometheus" && labels["code"] == "418" && m.GetHistogram().GetSampleCount() > 0 {
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		t.Errorf("Expected request duration to be recorded for code %v", http.StatusTeapot)
+	}
+}
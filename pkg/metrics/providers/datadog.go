@@ -37,6 +37,8 @@ type DatadogProvider struct {
 	apiKey         string
 	applicationKey string
 	fromDelta      int64
+	headers        map[string]string
+	client         *http.Client
 }
 
 type datadogResponse struct {
@@ -46,20 +48,41 @@ type datadogResponse struct {
 }
 
 // NewDatadogProvider takes a canary spec, a provider spec and the credentials map, and
-// returns a Datadog client ready to execute queries against the API
+// returns a Datadog client ready to execute queries against the API,
+// the identity headers are added to every request
 func NewDatadogProvider(metricInterval string,
 	provider flaggerv1.MetricTemplateProvider,
 	credentials map[string][]byte) (*DatadogProvider, error) {
 
+	if err := ValidateHeaders(provider); err != nil {
+		return nil, fmt.Errorf("datadog %s", err.Error())
+	}
+
+	if provider.TokenFile != "" {
+		return nil, fmt.Errorf("datadog doesn't support token files, the API keys must be set in the secret")
+	}
+
 	address := provider.Address
 	if address == "" {
 		address = datadogDefaultHost
 	}
 
+	client, err := newHTTPClient(provider.Type, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("datadog credentials %s", err.Error())
+	}
+
+	timeout, err := queryTimeout(provider)
+	if err != nil {
+		return nil, err
+	}
+
 	dd := DatadogProvider{
-		timeout:                  5 * time.Second,
+		timeout:                  timeout,
 		metricsQueryEndpoint:     address + datadogMetricsQueryPath,
 		apiKeyValidationEndpoint: address + datadogAPIKeyValidationPath,
+		headers:                  provider.Headers,
+		client:                   client,
 	}
 
 	if b, ok := credentials[datadogAPIKeySecretKey]; ok {
@@ -86,54 +109,95 @@ func NewDatadogProvider(metricInterval string,
 // RunQuery executes the datadog query against DatadogProvider.metricsQueryEndpoint
 // and returns the the first result as float64
 func (p *DatadogProvider) RunQuery(query string) (float64, error) {
+	return p.RunQueryAt(query, time.Now())
+}
+
+// RunQueryAt executes the datadog query over the metric interval that ends at the given time
+// and returns the last point of the first series as float64
+func (p *DatadogProvider) RunQueryAt(query string, t time.Time) (float64, error) {
+	to := t.Unix()
+	pointlist, err := p.query(query, to-p.fromDelta, to)
+	if err != nil {
+		return 0, err
+	}
+
+	vs := pointlist[len(pointlist)-1]
+	if len(vs) < 2 {
+		return 0, fmt.Errorf("no values found in response")
+	}
 
+	return vs[1], nil
+}
+
+// RunRangeQuery executes the datadog query over the interval and returns the points of the first series,
+// the step is decided by the Datadog API based on the interval
+func (p *DatadogProvider) RunRangeQuery(query string, interval time.Duration, step time.Duration) ([]DataPoint, error) {
+	now := time.Now().Unix()
+	pointlist, err := p.query(query, now-int64(interval.Seconds()), now)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []DataPoint
+	for _, vs := range pointlist {
+		if len(vs) < 2 {
+			continue
+		}
+		points = append(points, DataPoint{
+			Timestamp: time.Unix(0, int64(vs[0])*int64(time.Millisecond)),
+			Value:     vs[1],
+		})
+	}
+	if len(points) < 1 {
+		return nil, fmt.Errorf("no values found in response")
+	}
+
+	return points, nil
+}
+
+// query calls the metrics query endpoint and returns the pointlist of the first series
+func (p *DatadogProvider) query(query string, from int64, to int64) ([][]float64, error) {
 	req, err := http.NewRequest("GET", p.metricsQueryEndpoint, nil)
 	if err != nil {
-		return 0, fmt.Errorf("error http.NewRequest: %s", err.Error())
+		return nil, fmt.Errorf("error http.NewRequest: %s", err.Error())
 	}
 
+	setHeaders(req, p.headers)
 	req.Header.Set(datadogAPIKeyHeaderKey, p.apiKey)
 	req.Header.Set(datadogApplicationKeyHeaderKey, p.applicationKey)
-	now := time.Now().Unix()
 	q := req.URL.Query()
 	q.Add("query", query)
-	q.Add("from", strconv.FormatInt(now-p.fromDelta, 10))
-	q.Add("to", strconv.FormatInt(now, 10))
+	q.Add("from", strconv.FormatInt(from, 10))
+	q.Add("to", strconv.FormatInt(to, 10))
 	req.URL.RawQuery = q.Encode()
 
 	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
 	defer cancel()
-	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	r, err := p.client.Do(req.WithContext(ctx))
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	defer r.Body.Close()
 	b, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		return 0, fmt.Errorf("error reading body: %s", err.Error())
+		return nil, fmt.Errorf("error reading body: %s", err.Error())
 	}
 
 	if r.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("error response: %s", string(b))
+		return nil, &responseError{statusCode: r.StatusCode, body: string(b)}
 	}
 
 	var res datadogResponse
 	if err := json.Unmarshal(b, &res); err != nil {
-		return 0, fmt.Errorf("error unmarshaling result: %s, '%s'", err.Error(), string(b))
-	}
-
-	if len(res.Series) < 1 {
-		return 0, fmt.Errorf("no values found in response: %s", string(b))
+		return nil, fmt.Errorf("error unmarshaling result: %s, '%s'", err.Error(), string(b))
 	}
 
-	s := res.Series[0]
-	vs := s.Pointlist[len(s.Pointlist)-1]
-	if len(vs) < 1 {
-		return 0, fmt.Errorf("no values found in response: %s", string(b))
+	if len(res.Series) < 1 || len(res.Series[0].Pointlist) < 1 {
+		return nil, fmt.Errorf("no values found in response: %s", string(b))
 	}
 
-	return vs[1], nil
+	return res.Series[0].Pointlist, nil
 }
 
 // IsOnline calls the Datadog's validation endpoint with api keys
@@ -144,12 +208,13 @@ func (p *DatadogProvider) IsOnline() (bool, error) {
 		return false, fmt.Errorf("error http.NewRequest: %s", err.Error())
 	}
 
+	setHeaders(req, p.headers)
 	req.Header.Add(datadogAPIKeyHeaderKey, p.apiKey)
 	req.Header.Add(datadogApplicationKeyHeaderKey, p.applicationKey)
 
 	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
 	defer cancel()
-	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	r, err := p.client.Do(req.WithContext(ctx))
 	if err != nil {
 		return false, err
 	}
@@ -161,7 +226,7 @@ func (p *DatadogProvider) IsOnline() (bool, error) {
 	}
 
 	if r.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("error response: %s", string(b))
+		return false, &responseError{statusCode: r.StatusCode, body: string(b)}
 	}
 
 	return true, nil
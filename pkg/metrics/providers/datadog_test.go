@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"encoding/pem"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -154,3 +155,76 @@ func TestDatadogProvider_IsOnline(t *testing.T) {
 		})
 	}
 }
+
+func TestDatadogProvider_IsOnlineWithCABundle(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	cs := map[string][]byte{
+		datadogApplicationKeySecretKey: []byte("app-key"),
+		datadogAPIKeySecretKey:         []byte("api-key"),
+	}
+
+	dp, err := NewDatadogProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL}, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := dp.IsOnline(); err == nil {
+		t.Errorf("Expected certificate verification error without a CA bundle")
+	}
+
+	cs[caCertSecretKey] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	dp, err = NewDatadogProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL}, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := dp.IsOnline()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ok {
+		t.Errorf("Got %v wanted %v", ok, true)
+	}
+}
+
+func TestDatadogProvider_RunRangeQuery(t *testing.T) {
+	now := time.Now().Unix()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, _ := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+		if now-from > 61 {
+			t.Errorf("Got from %v wanted the start of the interval", from)
+		}
+		json := `{"series": [{"pointlist": [[1577232000000,1],[1577232030000,2],[1577232060000,3]]}]}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	dp, err := NewDatadogProvider("1m",
+		flaggerv1.MetricTemplateProvider{Address: ts.URL},
+		map[string][]byte{
+			datadogApplicationKeySecretKey: []byte("app-key"),
+			datadogAPIKeySecretKey:         []byte("api-key"),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := dp.RunRangeQuery("avg:system.cpu.user{*}", time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(points) != 3 {
+		t.Fatalf("Got %v datapoints wanted 3", len(points))
+	}
+
+	if points[1].Value != 2 || points[1].Timestamp.Unix() != 1577232030 {
+		t.Errorf("Got %v wanted 2 at 1577232030", points[1])
+	}
+}
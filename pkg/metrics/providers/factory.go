@@ -1,10 +1,18 @@
 package providers
 
 import (
+	"time"
+
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 )
 
-type Factory struct{}
+type Factory struct {
+	// Budget accounts the queries run by the providers, queries are not limited when nil
+	Budget *Budget
+
+	// MaxRetryWait caps the total wait of the query retries, the wait is not limited when zero
+	MaxRetryWait time.Duration
+}
 
 func (factory Factory) Provider(
 	metricInterval string,
@@ -12,6 +20,40 @@ func (factory Factory) Provider(
 	credentials map[string][]byte,
 ) (Interface, error) {
 
+	c, err := factory.newProvider(metricInterval, provider, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	// every attempt is instrumented, including the retries
+	var client Interface = NewInstrumentedProvider(c, provider)
+
+	// the budget is checked before every attempt so that retries are accounted for
+	if factory.Budget != nil {
+		client = NewBudgetProvider(client, factory.Budget)
+	}
+
+	if provider.Retry != nil {
+		client, err = NewRetryProvider(client, *provider.Retry, factory.MaxRetryWait)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// cached results are not accounted against the budget
+	if provider.CacheTTL != "" {
+		return NewCacheProvider(client, metricInterval, provider, credentials)
+	}
+
+	return client, nil
+}
+
+func (factory Factory) newProvider(
+	metricInterval string,
+	provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte,
+) (Interface, error) {
+
 	switch {
 	case provider.Type == "prometheus":
 		return NewPrometheusProvider(provider, credentials)
@@ -0,0 +1,52 @@
+package providers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// headerNameRegex matches the HTTP header field names (RFC 7230 tokens)
+var headerNameRegex = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// reservedHeaders are set by the providers from the credentials or by the HTTP client
+var reservedHeaders = []string{
+	"Authorization",
+	"Host",
+	"Content-Length",
+	"Content-Type",
+	datadogAPIKeyHeaderKey,
+	datadogApplicationKeyHeaderKey,
+}
+
+// ValidateHeaders checks that the identity headers of a provider are valid HTTP headers
+// and that they don't override the headers holding the credentials
+func ValidateHeaders(provider flaggerv1.MetricTemplateProvider) error {
+	for name, value := range provider.Headers {
+		if !headerNameRegex.MatchString(name) {
+			return fmt.Errorf("header name %q is not valid", name)
+		}
+		for _, reserved := range reservedHeaders {
+			if strings.EqualFold(name, reserved) {
+				return fmt.Errorf("header %s is reserved, set the credentials with secretRef", name)
+			}
+		}
+		if value == "" {
+			return fmt.Errorf("header %s value is empty", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("header %s value contains a line break", name)
+		}
+	}
+	return nil
+}
+
+// setHeaders adds the identity headers to the provider request
+func setHeaders(req *http.Request, headers map[string]string) {
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+}
@@ -0,0 +1,41 @@
+package providers
+
+import (
+	"testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestValidateHeaders(t *testing.T) {
+	tests := []struct {
+		headers map[string]string
+		valid   bool
+	}{
+		{headers: map[string]string{"X-Scope-OrgID": "team-a"}, valid: true},
+		{headers: map[string]string{"X-Scope-OrgID": "team-a|team-b", "X-Account": "42"}, valid: true},
+		{headers: map[string]string{"X Scope": "team-a"}},
+		{headers: map[string]string{"X-Scope-OrgID": ""}},
+		{headers: map[string]string{"X-Scope-OrgID": "team-a\r\nX-Other: b"}},
+		{headers: map[string]string{"authorization": "Bearer token"}},
+		{headers: map[string]string{"DD-API-KEY": "key"}},
+	}
+
+	for _, tt := range tests {
+		err := ValidateHeaders(flaggerv1.MetricTemplateProvider{Headers: tt.headers})
+		if tt.valid && err != nil {
+			t.Errorf("Headers %v unexpected error %v", tt.headers, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("Headers %v expected error", tt.headers)
+		}
+	}
+}
+
+func TestCachePrefix_Headers(t *testing.T) {
+	teamA := flaggerv1.MetricTemplateProvider{Address: "http://cortex", Headers: map[string]string{"X-Scope-OrgID": "team-a"}}
+	teamB := flaggerv1.MetricTemplateProvider{Address: "http://cortex", Headers: map[string]string{"X-Scope-OrgID": "team-b"}}
+
+	if cachePrefix("1m", teamA, nil) == cachePrefix("1m", teamB, nil) {
+		t.Error("Expected different cache prefixes for different tenants")
+	}
+}
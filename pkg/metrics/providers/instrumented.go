@@ -0,0 +1,143 @@
+package providers
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+var (
+	providerOnline = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "flagger",
+		Name:      "provider_online",
+		Help:      "Metric provider health check status, 1 if online and 0 if unreachable.",
+	}, []string{"provider", "host"})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "flagger",
+		Name:      "provider_query_duration_seconds",
+		Help:      "Duration of the metric provider queries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "host"})
+
+	queryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "flagger",
+		Name:      "provider_query_errors_total",
+		Help:      "Total number of failed metric provider queries by reason.",
+	}, []string{"provider", "host", "reason"})
+)
+
+func init() {
+	prometheus.MustRegister(providerOnline, queryDuration, queryErrors)
+}
+
+// InstrumentedProvider wraps a provider and records the health status,
+// the duration and the errors of the queries per provider instance
+type InstrumentedProvider struct {
+	provider     Interface
+	providerType string
+	host         string
+}
+
+// NewInstrumentedProvider returns a provider labeled with the provider type and address host
+func NewInstrumentedProvider(provider Interface, spec flaggerv1.MetricTemplateProvider) *InstrumentedProvider {
+	providerType := spec.Type
+	if providerType == "" {
+		providerType = "prometheus"
+	}
+
+	address := spec.Address
+	if address == "" && providerType == "datadog" {
+		address = datadogDefaultHost
+	}
+
+	host := address
+	if u, err := url.Parse(address); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	return &InstrumentedProvider{
+		provider:     provider,
+		providerType: providerType,
+		host:         host,
+	}
+}
+
+// RunQuery executes the query and records its duration and error reason
+func (p *InstrumentedProvider) RunQuery(query string) (float64, error) {
+	start := time.Now()
+	val, err := p.provider.RunQuery(query)
+	p.observe(start, err)
+	return val, err
+}
+
+// RunRangeQuery executes the range query and records its duration and error reason
+func (p *InstrumentedProvider) RunRangeQuery(query string, interval time.Duration, step time.Duration) ([]DataPoint, error) {
+	start := time.Now()
+	points, err := RunRangeQuery(p.provider, query, interval, step)
+	p.observe(start, err)
+	return points, err
+}
+
+// RunQueryAt executes the query at the given time and records its duration and error reason
+func (p *InstrumentedProvider) RunQueryAt(query string, t time.Time) (float64, error) {
+	start := time.Now()
+	val, err := RunQueryAt(p.provider, query, t)
+	p.observe(start, err)
+	return val, err
+}
+
+// IsOnline calls the provider endpoint and records the health status
+func (p *InstrumentedProvider) IsOnline() (bool, error) {
+	ok, err := p.provider.IsOnline()
+	status := 0.0
+	if ok && err == nil {
+		status = 1
+	}
+	providerOnline.WithLabelValues(p.providerType, p.host).Set(status)
+	return ok, err
+}
+
+func (p *InstrumentedProvider) observe(start time.Time, err error) {
+	queryDuration.WithLabelValues(p.providerType, p.host).Observe(time.Since(start).Seconds())
+	if err != nil {
+		queryErrors.WithLabelValues(p.providerType, p.host, errorReason(err)).Inc()
+	}
+}
+
+// errorReason classifies the provider errors for alerting
+func errorReason(err error) string {
+	if err == context.DeadlineExceeded {
+		return "timeout"
+	}
+
+	switch e := err.(type) {
+	case *responseError:
+		switch {
+		case e.statusCode == 401 || e.statusCode == 403:
+			return "unauthorized"
+		case e.statusCode == 429:
+			return "rate_limited"
+		case e.statusCode >= 500:
+			return "server_error"
+		default:
+			return "client_error"
+		}
+	case net.Error:
+		if e.Timeout() {
+			return "timeout"
+		}
+		return "connection"
+	}
+
+	if strings.Contains(err.Error(), "no values found") {
+		return "no_values"
+	}
+	return "other"
+}
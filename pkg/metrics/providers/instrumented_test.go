@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestInstrumentedProvider_RunQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	spec := flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}
+	prom, err := NewPrometheusProvider(spec, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	provider := NewInstrumentedProvider(prom, spec)
+	_, err = provider.RunQuery("sum(envoy_cluster_upstream_rq)")
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	errors := testutil.ToFloat64(queryErrors.WithLabelValues("prometheus", provider.host, "rate_limited"))
+	if errors != 1 {
+		t.Errorf("Got %v errors wanted %v", errors, 1)
+	}
+}
+
+func TestInstrumentedProvider_IsOnline(t *testing.T) {
+	online := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !online {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	spec := flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}
+	prom, err := NewPrometheusProvider(spec, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	provider := NewInstrumentedProvider(prom, spec)
+	if ok, err := provider.IsOnline(); !ok || err != nil {
+		t.Fatalf("Got %v %v wanted online", ok, err)
+	}
+	status := testutil.ToFloat64(providerOnline.WithLabelValues("prometheus", provider.host))
+	if status != 1 {
+		t.Errorf("Got status %v wanted %v", status, 1)
+	}
+
+	online = false
+	provider.IsOnline()
+	status = testutil.ToFloat64(providerOnline.WithLabelValues("prometheus", provider.host))
+	if status != 0 {
+		t.Errorf("Got status %v wanted %v", status, 0)
+	}
+}
+
+func TestNewInstrumentedProvider_Host(t *testing.T) {
+	dd := NewInstrumentedProvider(nil, flaggerv1.MetricTemplateProvider{Type: "datadog"})
+	if dd.host != "api.datadoghq.com" {
+		t.Errorf("Got host %s wanted %s", dd.host, "api.datadoghq.com")
+	}
+
+	prom := NewInstrumentedProvider(nil, flaggerv1.MetricTemplateProvider{Address: "http://prometheus.istio-system:9090"})
+	if prom.providerType != "prometheus" || prom.host != "prometheus.istio-system:9090" {
+		t.Errorf("Got %s %s wanted prometheus prometheus.istio-system:9090", prom.providerType, prom.host)
+	}
+}
+
+func TestErrorReason(t *testing.T) {
+	tests := map[string]error{
+		"timeout":      context.DeadlineExceeded,
+		"unauthorized": &responseError{statusCode: http.StatusForbidden},
+		"rate_limited": &responseError{statusCode: http.StatusTooManyRequests},
+		"server_error": &responseError{statusCode: http.StatusBadGateway},
+		"client_error": &responseError{statusCode: http.StatusBadRequest},
+		"no_values":    fmt.Errorf("no values found"),
+		"other":        fmt.Errorf("error unmarshaling result"),
+	}
+
+	for want, err := range tests {
+		if got := errorReason(err); got != want {
+			t.Errorf("Got reason %s for %v wanted %s", got, err, want)
+		}
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
 	"path"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
@@ -17,10 +19,14 @@ import (
 
 // PrometheusProvider executes promQL queries
 type PrometheusProvider struct {
-	timeout  time.Duration
-	url      url.URL
-	username string
-	password string
+	timeout   time.Duration
+	url       url.URL
+	username  string
+	password  string
+	token     string
+	tokenFile *tokenFile
+	headers   map[string]string
+	client    *http.Client
 }
 
 type prometheusResponse struct {
@@ -34,21 +40,75 @@ type prometheusResponse struct {
 	}
 }
 
+type prometheusRangeResponse struct {
+	Data struct {
+		Result []struct {
+			Values [][]interface{} `json:"values"`
+		}
+	}
+}
+
 // NewPrometheusProvider takes a provider spec and the credentials map,
-// validates the address, extracts the username and password values if provided and
-// returns a Prometheus client ready to execute queries against the API
+// validates the address, extracts the username and password or the bearer token values if provided and
+// returns a Prometheus client ready to execute queries against the API.
+// When the credentials contain a client certificate and key (tls.crt, tls.key)
+// the client authenticates with mutual TLS, the server CA can be set with ca.crt
+// in the secret or in the config map referenced by the provider.
+// The identity headers e.g. X-Scope-OrgID are added to every request
 func NewPrometheusProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*PrometheusProvider, error) {
 	promURL, err := url.Parse(provider.Address)
 	if err != nil {
 		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
 	}
 
+	if err := ValidateHeaders(provider); err != nil {
+		return nil, fmt.Errorf("%s %s", provider.Type, err.Error())
+	}
+
+	client, err := newHTTPClient(provider.Type, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("%s credentials %s", provider.Type, err.Error())
+	}
+
+	timeout, err := queryTimeout(provider)
+	if err != nil {
+		return nil, err
+	}
+
 	prom := PrometheusProvider{
-		timeout: 5 * time.Second,
+		timeout: timeout,
 		url:     *promURL,
+		headers: provider.Headers,
+		client:  client,
+	}
+
+	// the token file takes precedence over the secret credentials, the TLS ones are still used
+	if provider.TokenFile != "" {
+		path, err := tokenFilePath(provider.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s", provider.Type, err.Error())
+		}
+		if err := prom.SetBearerTokenFile(path); err != nil {
+			return nil, fmt.Errorf("%s %s", provider.Type, err.Error())
+		}
+		return &prom, nil
 	}
 
 	if provider.SecretRef != nil {
+		if token, ok := credentials["token"]; ok {
+			prom.token = strings.TrimSpace(string(token))
+			return &prom, nil
+		}
+
+		if hasTLSCredentials(credentials) {
+			// basic auth is optional when using TLS
+			_, hasUsername := credentials["username"]
+			_, hasPassword := credentials["password"]
+			if !hasUsername && !hasPassword {
+				return &prom, nil
+			}
+		}
+
 		if username, ok := credentials["username"]; ok {
 			prom.username = string(username)
 		} else {
@@ -65,46 +125,62 @@ func NewPrometheusProvider(provider flaggerv1.MetricTemplateProvider, credential
 	return &prom, nil
 }
 
-// RunQuery executes the promQL query and returns the the first result as float64
-func (p *PrometheusProvider) RunQuery(query string) (float64, error) {
-	if p.url.String() == "fake" {
-		return 100, nil
-	}
-
-	query = url.QueryEscape(p.trimQuery(query))
-	u, err := url.Parse(fmt.Sprintf("./api/v1/query?query=%s", query))
+// SetBearerTokenFile configures the provider to authenticate with the token read from the given path,
+// the file is read on every request so that the rotated tokens are used e.g. a projected service account token
+func (p *PrometheusProvider) SetBearerTokenFile(path string) error {
+	t, err := newTokenFile(path)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	u.Path = path.Join(p.url.Path, u.Path)
+	p.tokenFile = t
+	return nil
+}
 
-	u = p.url.ResolveReference(u)
+// setAuthorization adds the identity headers and the bearer token or the basic auth credentials to the request
+func (p *PrometheusProvider) setAuthorization(req *http.Request) error {
+	setHeaders(req, p.headers)
 
-	req, err := http.NewRequest("GET", u.String(), nil)
-	if err != nil {
-		return 0, err
+	if p.tokenFile != nil {
+		token, err := p.tokenFile.Token()
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+		return nil
 	}
 
 	if p.username != "" && p.password != "" {
 		req.SetBasicAuth(p.username, p.password)
 	}
+	return nil
+}
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
-	defer cancel()
+// RunQuery executes the promQL query and returns the the first result as float64
+func (p *PrometheusProvider) RunQuery(query string) (float64, error) {
+	return p.runQuery(query, url.Values{})
+}
 
-	r, err := http.DefaultClient.Do(req.WithContext(ctx))
-	if err != nil {
-		return 0, err
-	}
-	defer r.Body.Close()
+// RunQueryAt executes the promQL query at the given time and returns the the first result as float64
+func (p *PrometheusProvider) RunQueryAt(query string, t time.Time) (float64, error) {
+	params := url.Values{}
+	params.Set("time", strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', 3, 64))
+	return p.runQuery(query, params)
+}
 
-	b, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		return 0, fmt.Errorf("error reading body: %s", err.Error())
+func (p *PrometheusProvider) runQuery(query string, params url.Values) (float64, error) {
+	if p.url.String() == "fake" {
+		return 100, nil
 	}
 
-	if 400 <= r.StatusCode {
-		return 0, fmt.Errorf("error response: %s", string(b))
+	params.Set("query", p.trimQuery(query))
+	b, err := p.get("./api/v1/query", params)
+	if err != nil {
+		return 0, err
 	}
 
 	var result prometheusResponse
@@ -132,6 +208,105 @@ func (p *PrometheusProvider) RunQuery(query string) (float64, error) {
 	return *value, nil
 }
 
+// RunRangeQuery executes the promQL query over the interval and returns the datapoints of the first series
+func (p *PrometheusProvider) RunRangeQuery(query string, interval time.Duration, step time.Duration) ([]DataPoint, error) {
+	if p.url.String() == "fake" {
+		return []DataPoint{{Timestamp: time.Now(), Value: 100}}, nil
+	}
+
+	end := time.Now()
+	params := url.Values{}
+	params.Set("query", p.trimQuery(query))
+	params.Set("start", strconv.FormatInt(end.Add(-interval).Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	b, err := p.get("./api/v1/query_range", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result prometheusRangeResponse
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("error unmarshaling result: %s, '%s'", err.Error(), string(b))
+	}
+
+	if len(result.Data.Result) < 1 {
+		return nil, fmt.Errorf("no values found")
+	}
+
+	var points []DataPoint
+	for _, v := range result.Data.Result[0].Values {
+		if len(v) < 2 {
+			continue
+		}
+		ts, ok := v[0].(float64)
+		if !ok {
+			continue
+		}
+		s, ok := v[1].(string)
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(f) {
+			continue
+		}
+		points = append(points, DataPoint{
+			Timestamp: time.Unix(0, int64(ts*float64(time.Second))),
+			Value:     f,
+		})
+	}
+	if len(points) < 1 {
+		return nil, fmt.Errorf("no values found")
+	}
+
+	return points, nil
+}
+
+// get calls the API endpoint with the query params and returns the response body
+func (p *PrometheusProvider) get(endpoint string, params url.Values) ([]byte, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = params.Encode()
+	u.Path = path.Join(p.url.Path, u.Path)
+
+	u = p.url.ResolveReference(u)
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.setAuthorization(req); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %s", err.Error())
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, &responseError{statusCode: r.StatusCode, body: string(b)}
+	}
+
+	return b, nil
+}
+
 // IsOnline calls the Prometheus status endpoint and returns an error if the API is unreachable
 func (p *PrometheusProvider) IsOnline() (bool, error) {
 	u, err := url.Parse("./api/v1/status/flags")
@@ -147,14 +322,14 @@ func (p *PrometheusProvider) IsOnline() (bool, error) {
 		return false, err
 	}
 
-	if p.username != "" && p.password != "" {
-		req.SetBasicAuth(p.username, p.password)
+	if err := p.setAuthorization(req); err != nil {
+		return false, err
 	}
 
 	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
 	defer cancel()
 
-	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	r, err := p.client.Do(req.WithContext(ctx))
 	if err != nil {
 		return false, err
 	}
@@ -166,7 +341,7 @@ func (p *PrometheusProvider) IsOnline() (bool, error) {
 	}
 
 	if 400 <= r.StatusCode {
-		return false, fmt.Errorf("error response: %s", string(b))
+		return false, &responseError{statusCode: r.StatusCode, body: string(b)}
 	}
 
 	return true, nil
@@ -1,10 +1,23 @@
 package providers
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -139,6 +152,48 @@ func TestPrometheusProvider_RunQueryWithBasicAuth(t *testing.T) {
 	}
 }
 
+func TestPrometheusProvider_RunQueryWithHeaders(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tenant := r.Header.Get("X-Scope-OrgID"); tenant != "team-a" {
+			t.Errorf("Got X-Scope-OrgID %s wanted %s", tenant, "team-a")
+		}
+		if _, ok := r.Header["Authorization"]; !ok {
+			t.Error("Authorization header not found")
+		}
+
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	provider := flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: &corev1.LocalObjectReference{Name: "prometheus"},
+		Headers:   map[string]string{"X-Scope-OrgID": "team-a"},
+	}
+	credentials := map[string][]byte{"token": []byte("token")}
+
+	prom, err := NewPrometheusProvider(provider, credentials)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	val, err := prom.RunQuery("sum(envoy_cluster_upstream_rq)")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if val != 100 {
+		t.Errorf("Got %v wanted %v", val, 100)
+	}
+
+	// the credentials headers can't be overridden
+	provider.Headers = map[string]string{"Authorization": "Bearer other"}
+	if _, err := NewPrometheusProvider(provider, credentials); err == nil {
+		t.Error("Expected error for reserved header")
+	}
+}
+
 func TestPrometheusProvider_IsOnline(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadGateway)
@@ -168,3 +223,304 @@ func TestPrometheusProvider_IsOnline(t *testing.T) {
 		t.Errorf("Got %v wanted %v", ok, false)
 	}
 }
+
+func TestPrometheusProvider_RunQueryWithMutualTLS(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			t.Error("Client certificate not found")
+		}
+
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	certPEM, keyPEM := newTestClientCert(t)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+
+	provider := flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: &corev1.LocalObjectReference{Name: "prometheus"},
+	}
+
+	prom, err := NewPrometheusProvider(provider, map[string][]byte{
+		"tls.crt": certPEM,
+		"tls.key": keyPEM,
+		"ca.crt":  caPEM,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	val, err := prom.RunQuery("sum(envoy_cluster_upstream_rq)")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if val != 100 {
+		t.Errorf("Got %v wanted %v", val, 100)
+	}
+
+	_, err = NewPrometheusProvider(provider, map[string][]byte{"tls.crt": certPEM})
+	if err == nil {
+		t.Errorf("Expected error when tls.key is missing")
+	}
+}
+
+func newTestClientCert(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "flagger"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestPrometheusProvider_RunQueryWithBearerTokenFile(t *testing.T) {
+	var token string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("Authorization")
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "flagger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	tokenPath := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenPath, []byte("token-v1\n"), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := prom.SetBearerTokenFile(tokenPath); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := prom.RunQuery("sum(envoy_cluster_upstream_rq)"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if token != "Bearer token-v1" {
+		t.Errorf("Got Authorization header %s wanted %s", token, "Bearer token-v1")
+	}
+
+	// rotate the token
+	if err := ioutil.WriteFile(tokenPath, []byte("token-v2"), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(tokenPath, future, future); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := prom.RunQuery("sum(envoy_cluster_upstream_rq)"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if token != "Bearer token-v2" {
+		t.Errorf("Got Authorization header %s wanted %s", token, "Bearer token-v2")
+	}
+
+	if err := prom.SetBearerTokenFile(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("Expected error for missing token file")
+	}
+}
+
+func TestPrometheusProvider_RunQueryWithTemplateTokenFile(t *testing.T) {
+	var token string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("Authorization")
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "flagger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "thanos"), []byte("token-v1"), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	spec := flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL, TokenFile: "thanos"}
+	if _, err := (Factory{}).Provider("1m", spec, nil); err == nil {
+		t.Errorf("Expected an error when the controller token directory is not set")
+	}
+
+	opts := clientOptions
+	defer ConfigureClient(opts)
+	opts.TokenDir = dir
+	ConfigureClient(opts)
+
+	for _, name := range []string{"/etc/passwd", "../token", "a/../../token"} {
+		invalid := spec
+		invalid.TokenFile = name
+		if _, err := (Factory{}).Provider("1m", invalid, nil); err == nil {
+			t.Errorf("Expected an error for the token file %s outside of the token directory", name)
+		}
+	}
+
+	provider, err := Factory{}.Provider("1m", spec, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := provider.RunQuery("sum(envoy_cluster_upstream_rq)"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if token != "Bearer token-v1" {
+		t.Errorf("Got Authorization header %s wanted %s", token, "Bearer token-v1")
+	}
+
+	// the rotated token is read on the next request
+	if err := ioutil.WriteFile(filepath.Join(dir, "thanos"), []byte("token-v2"), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := provider.RunQuery("sum(envoy_cluster_upstream_rq)"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if token != "Bearer token-v2" {
+		t.Errorf("Got Authorization header %s wanted %s", token, "Bearer token-v2")
+	}
+}
+
+func TestPrometheusProvider_RunQueryWithTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	clients := map[string]bool{"10ms": false, "1s": true}
+	for timeout, ok := range clients {
+		prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "prometheus",
+			Address: ts.URL,
+			Timeout: timeout,
+		}, nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		_, err = prom.RunQuery("sum(envoy_cluster_upstream_rq)")
+		if ok && err != nil {
+			t.Errorf("Got error %v wanted none for timeout %s", err, timeout)
+		}
+		if !ok && err == nil {
+			t.Errorf("Expected error for timeout %s", timeout)
+		}
+	}
+
+	_, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "prometheus",
+		Address: ts.URL,
+		Timeout: "1x",
+	}, nil)
+	if err == nil {
+		t.Errorf("Expected error for invalid timeout")
+	}
+}
+
+func TestPrometheusProvider_RunRangeQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			t.Errorf("Got path %s wanted /api/v1/query_range", r.URL.Path)
+		}
+		if step := r.URL.Query().Get("step"); step != "30" {
+			t.Errorf("Got step %s wanted 30", step)
+		}
+		start, _ := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+		end, _ := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+		if end-start != 120 {
+			t.Errorf("Got range %vs wanted 120s", end-start)
+		}
+		json := `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1545905100,"1"],[1545905130,"NaN"],[1545905160,"3"],[1545905190,"5"]]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "prometheus",
+		Address: ts.URL,
+	}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	points, err := prom.RunRangeQuery("sum(envoy_cluster_upstream_rq)", 2*time.Minute, 30*time.Second)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(points) != 3 {
+		t.Fatalf("Got %v datapoints wanted 3", len(points))
+	}
+
+	if points[2].Value != 5 || points[2].Timestamp.Unix() != 1545905190 {
+		t.Errorf("Got %v wanted 5 at 1545905190", points[2])
+	}
+}
+
+func TestPrometheusProvider_RunQueryAt(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			t.Errorf("Got path %s wanted /api/v1/query", r.URL.Path)
+		}
+		if at := r.URL.Query().Get("time"); at != "1545905100.500" {
+			t.Errorf("Got time %s wanted 1545905100.500", at)
+		}
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905100.5,"42"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "prometheus",
+		Address: ts.URL,
+	}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	val, err := prom.RunQueryAt("sum(envoy_cluster_upstream_rq)", time.Unix(1545905100, 500*int64(time.Millisecond)))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if val != 42 {
+		t.Errorf("Got %v wanted %v", val, 42)
+	}
+}
@@ -1,5 +1,7 @@
 package providers
 
+import "time"
+
 type Interface interface {
 	// RunQuery executes the query and converts the first result to float64
 	RunQuery(query string) (float64, error)
@@ -7,3 +9,15 @@ type Interface interface {
 	// IsOnline calls the provider endpoint and returns an error if the API is unreachable
 	IsOnline() (bool, error)
 }
+
+// RangeProvider is implemented by the providers that can return all the datapoints of an interval
+type RangeProvider interface {
+	// RunRangeQuery executes the query over the interval and returns the datapoints of the first series
+	RunRangeQuery(query string, interval time.Duration, step time.Duration) ([]DataPoint, error)
+}
+
+// PointProvider is implemented by the providers that can evaluate a query at a point in time
+type PointProvider interface {
+	// RunQueryAt executes the query at the given time and converts the first result to float64
+	RunQueryAt(query string, t time.Time) (float64, error)
+}
@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	AggregationAvg   = "avg"
+	AggregationMax   = "max"
+	AggregationMin   = "min"
+	AggregationSlope = "slope"
+
+	// minRangeStep is the lowest resolution used for range queries
+	minRangeStep = time.Second
+	// defaultRangeSteps is the number of datapoints requested when the step is not specified
+	defaultRangeSteps = 10
+)
+
+// DataPoint is a metric value at a point in time
+type DataPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// RangeStep returns the step duration or a tenth of the interval if the step is empty
+func RangeStep(interval time.Duration, step string) (time.Duration, error) {
+	if step == "" {
+		d := interval / defaultRangeSteps
+		if d < minRangeStep {
+			d = minRangeStep
+		}
+		return d, nil
+	}
+
+	d, err := time.ParseDuration(step)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing step: %s", err.Error())
+	}
+	if d < minRangeStep {
+		return 0, fmt.Errorf("step %s must be at least %s", step, minRangeStep)
+	}
+	return d, nil
+}
+
+// RunRangeQuery executes the range query if the provider supports it
+func RunRangeQuery(provider Interface, query string, interval time.Duration, step time.Duration) ([]DataPoint, error) {
+	rp, ok := provider.(RangeProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support range queries")
+	}
+	return rp.RunRangeQuery(query, interval, step)
+}
+
+// RunQueryAt executes the query at the given time if the provider supports it,
+// otherwise the query is evaluated at the current time
+func RunQueryAt(provider Interface, query string, t time.Time) (float64, error) {
+	pp, ok := provider.(PointProvider)
+	if !ok {
+		return provider.RunQuery(query)
+	}
+	return pp.RunQueryAt(query, t)
+}
+
+// Aggregate reduces the datapoints to a single value, the slope is the
+// least squares rate of change per second
+func Aggregate(points []DataPoint, aggregation string) (float64, error) {
+	if len(points) < 1 {
+		return 0, fmt.Errorf("no values found")
+	}
+
+	switch aggregation {
+	case AggregationAvg:
+		sum := 0.0
+		for _, p := range points {
+			sum += p.Value
+		}
+		return sum / float64(len(points)), nil
+	case AggregationMax:
+		max := math.Inf(-1)
+		for _, p := range points {
+			max = math.Max(max, p.Value)
+		}
+		return max, nil
+	case AggregationMin:
+		min := math.Inf(1)
+		for _, p := range points {
+			min = math.Min(min, p.Value)
+		}
+		return min, nil
+	case AggregationSlope:
+		if len(points) < 2 {
+			return 0, fmt.Errorf("slope requires at least two values, found %v", len(points))
+		}
+		return slope(points), nil
+	default:
+		return 0, fmt.Errorf("aggregation %s not supported, must be one of avg, max, min or slope", aggregation)
+	}
+}
+
+// slope returns the linear regression coefficient of the values over time
+func slope(points []DataPoint) float64 {
+	start := points[0].Timestamp
+	n := float64(len(points))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		x := p.Timestamp.Sub(start).Seconds()
+		sumX += x
+		sumY += p.Value
+		sumXY += x * p.Value
+		sumXX += x * x
+	}
+
+	d := n*sumXX - sumX*sumX
+	if d == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / d
+}
@@ -0,0 +1,114 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestAggregate(t *testing.T) {
+	start := time.Unix(1545905100, 0)
+	points := []DataPoint{
+		{Timestamp: start, Value: 10},
+		{Timestamp: start.Add(30 * time.Second), Value: 40},
+		{Timestamp: start.Add(60 * time.Second), Value: 70},
+	}
+
+	tests := map[string]float64{
+		AggregationAvg:   40,
+		AggregationMax:   70,
+		AggregationMin:   10,
+		AggregationSlope: 1,
+	}
+
+	for aggregation, expected := range tests {
+		val, err := Aggregate(points, aggregation)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if val != expected {
+			t.Errorf("Got %v wanted %v for %s", val, expected, aggregation)
+		}
+	}
+
+	if _, err := Aggregate(points, "p99"); err == nil {
+		t.Errorf("Expected error for unsupported aggregation")
+	}
+
+	if _, err := Aggregate(points[:1], AggregationSlope); err == nil {
+		t.Errorf("Expected error for slope with a single datapoint")
+	}
+
+	if _, err := Aggregate(nil, AggregationAvg); err == nil {
+		t.Errorf("Expected error for empty datapoints")
+	}
+}
+
+func TestRangeStep(t *testing.T) {
+	step, err := RangeStep(5*time.Minute, "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if step != 30*time.Second {
+		t.Errorf("Got step %v wanted 30s", step)
+	}
+
+	step, err = RangeStep(5*time.Second, "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if step != time.Second {
+		t.Errorf("Got step %v wanted 1s", step)
+	}
+
+	if _, err := RangeStep(time.Minute, "100ms"); err == nil {
+		t.Errorf("Expected error for step lower than 1s")
+	}
+}
+
+func TestFactory_RunRangeQueryWrapped(t *testing.T) {
+	retry := flaggerv1.MetricTemplateRetry{Attempts: 1}
+	provider, err := Factory{Budget: NewBudget(flaggerv1.MetricTemplateBudget{MaxQueries: 1})}.Provider("1m",
+		flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: "fake", Retry: &retry, CacheTTL: "1m"}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	points, err := RunRangeQuery(provider, "sum(envoy_cluster_upstream_rq)", time.Minute, time.Second)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(points) != 1 || points[0].Value != 100 {
+		t.Errorf("Got %v wanted a single datapoint of 100", points)
+	}
+
+	if _, err := RunRangeQuery(provider, "sum(envoy_cluster_upstream_rq)", time.Minute, time.Second); err == nil {
+		t.Errorf("Expected budget error for the second range query")
+	}
+}
+
+func TestFactory_RunQueryAtWrapped(t *testing.T) {
+	retry := flaggerv1.MetricTemplateRetry{Attempts: 1}
+	provider, err := Factory{Budget: NewBudget(flaggerv1.MetricTemplateBudget{MaxQueries: 1})}.Provider("1m",
+		flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: "fake", Retry: &retry}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, ok := provider.(PointProvider); !ok {
+		t.Fatalf("Expected the wrapped provider to support point queries")
+	}
+
+	val, err := RunQueryAt(provider, "sum(envoy_cluster_upstream_rq)", time.Now())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if val != 100 {
+		t.Errorf("Got %v wanted %v", val, 100)
+	}
+
+	if _, err := RunQueryAt(provider, "sum(envoy_cluster_upstream_rq)", time.Now()); err == nil {
+		t.Errorf("Expected budget error for the second query")
+	}
+}
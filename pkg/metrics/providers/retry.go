@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	defaultRetryBackoff = time.Second
+	maxRetryAttempts    = 10
+	maxRetryBackoff     = time.Minute
+)
+
+// responseError is returned when the provider API responds with an error status code
+type responseError struct {
+	statusCode int
+	body       string
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("error response: %s", e.body)
+}
+
+// RetryProvider wraps a provider and retries the queries that failed with a transient error
+type RetryProvider struct {
+	provider Interface
+	attempts int
+	backoff  time.Duration
+	maxWait  time.Duration
+	sleep    func(time.Duration)
+}
+
+// NewRetryProvider validates the retry policy and returns a provider that retries
+// transient errors with an exponential backoff, the retries stop when the total wait
+// would exceed maxWait, a zero maxWait doesn't limit the wait
+func NewRetryProvider(provider Interface, retry flaggerv1.MetricTemplateRetry, maxWait time.Duration) (*RetryProvider, error) {
+	if retry.Attempts < 0 || retry.Attempts > maxRetryAttempts {
+		return nil, fmt.Errorf("retry attempts %v must be between 0 and %v", retry.Attempts, maxRetryAttempts)
+	}
+
+	backoff := defaultRetryBackoff
+	if retry.Backoff != "" {
+		d, err := time.ParseDuration(retry.Backoff)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing retry backoff: %s", err.Error())
+		}
+		backoff = d
+	}
+	if backoff <= 0 || backoff > maxRetryBackoff {
+		return nil, fmt.Errorf("retry backoff %v must be greater than zero and at most %v", backoff, maxRetryBackoff)
+	}
+
+	return &RetryProvider{
+		provider: provider,
+		attempts: retry.Attempts,
+		backoff:  backoff,
+		maxWait:  maxWait,
+		sleep:    time.Sleep,
+	}, nil
+}
+
+// RunQuery executes the query and retries it if the provider returned a transient error
+func (p *RetryProvider) RunQuery(query string) (float64, error) {
+	var val float64
+	err := p.retry(func() error {
+		var err error
+		val, err = p.provider.RunQuery(query)
+		return err
+	})
+	return val, err
+}
+
+// RunRangeQuery executes the range query and retries it if the provider returned a transient error
+func (p *RetryProvider) RunRangeQuery(query string, interval time.Duration, step time.Duration) ([]DataPoint, error) {
+	var points []DataPoint
+	err := p.retry(func() error {
+		var err error
+		points, err = RunRangeQuery(p.provider, query, interval, step)
+		return err
+	})
+	return points, err
+}
+
+// RunQueryAt executes the query at the given time and retries it if the provider returned a transient error
+func (p *RetryProvider) RunQueryAt(query string, t time.Time) (float64, error) {
+	var val float64
+	err := p.retry(func() error {
+		var err error
+		val, err = RunQueryAt(p.provider, query, t)
+		return err
+	})
+	return val, err
+}
+
+// IsOnline calls the provider endpoint and retries it if the provider returned a transient error
+func (p *RetryProvider) IsOnline() (bool, error) {
+	var ok bool
+	err := p.retry(func() error {
+		var err error
+		ok, err = p.provider.IsOnline()
+		return err
+	})
+	return ok, err
+}
+
+func (p *RetryProvider) retry(fn func() error) error {
+	backoff := p.backoff
+	var waited time.Duration
+	var err error
+	retries := 0
+	for i := 0; i <= p.attempts; i++ {
+		if i > 0 {
+			// give up before the retries delay the next analysis run
+			if p.maxWait > 0 && waited+backoff > p.maxWait {
+				return fmt.Errorf("%s (after %v retries, the next retry would exceed the %v retry time)",
+					err.Error(), retries, p.maxWait)
+			}
+			p.sleep(backoff)
+			waited += backoff
+			backoff *= 2
+			retries++
+		}
+
+		err = fn()
+		if err == nil || !isTransient(err) {
+			return err
+		}
+	}
+
+	if retries > 0 {
+		return fmt.Errorf("%s (after %v retries)", err.Error(), retries)
+	}
+	return err
+}
+
+// isTransient returns true for server side errors, timeouts and connection errors
+func isTransient(err error) bool {
+	switch e := err.(type) {
+	case *responseError:
+		return e.statusCode >= 500 || e.statusCode == 429
+	case net.Error:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestRetryProvider_RunQuery(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	provider, err := NewRetryProvider(prom, flaggerv1.MetricTemplateRetry{Attempts: 2, Backoff: "10ms"}, 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var backoffs []time.Duration
+	provider.sleep = func(d time.Duration) {
+		backoffs = append(backoffs, d)
+	}
+
+	val, err := provider.RunQuery("sum(envoy_cluster_upstream_rq)")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if val != 100 {
+		t.Errorf("Got %v wanted %v", val, 100)
+	}
+
+	if len(backoffs) != 2 || backoffs[0] != 10*time.Millisecond || backoffs[1] != 20*time.Millisecond {
+		t.Errorf("Got backoffs %v wanted %v", backoffs, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond})
+	}
+}
+
+func TestRetryProvider_RunQueryNotTransient(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	provider, err := NewRetryProvider(prom, flaggerv1.MetricTemplateRetry{Attempts: 3}, 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	provider.sleep = func(d time.Duration) {}
+
+	_, err = provider.RunQuery("sum(envoy_cluster_upstream_rq)")
+	if err == nil {
+		t.Errorf("Expected error for bad request")
+	}
+
+	if calls != 1 {
+		t.Errorf("Got %v calls wanted %v", calls, 1)
+	}
+}
+
+func TestRetryProvider_Limits(t *testing.T) {
+	for _, retry := range []flaggerv1.MetricTemplateRetry{
+		{Attempts: -1},
+		{Attempts: 11},
+		{Attempts: 3, Backoff: "0s"},
+		{Attempts: 3, Backoff: "2m"},
+	} {
+		if _, err := NewRetryProvider(nil, retry, 0); err == nil {
+			t.Errorf("Expected error for attempts %v backoff %s", retry.Attempts, retry.Backoff)
+		}
+	}
+}
+
+func TestRetryProvider_MaxWait(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// 1s + 2s fit in the 5s limit, the third retry would wait 4s more
+	provider, err := NewRetryProvider(prom, flaggerv1.MetricTemplateRetry{Attempts: 10, Backoff: "1s"}, 5*time.Second)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var backoffs []time.Duration
+	provider.sleep = func(d time.Duration) {
+		backoffs = append(backoffs, d)
+	}
+
+	_, err = provider.RunQuery("sum(envoy_cluster_upstream_rq)")
+	if err == nil {
+		t.Errorf("Expected error for unavailable provider")
+	}
+
+	if calls != 3 {
+		t.Errorf("Got %v calls wanted %v", calls, 3)
+	}
+	if len(backoffs) != 2 {
+		t.Errorf("Got backoffs %v wanted %v", backoffs, []time.Duration{time.Second, 2 * time.Second})
+	}
+}
@@ -0,0 +1,53 @@
+package providers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+const (
+	tlsCertSecretKey = "tls.crt"
+	tlsKeySecretKey  = "tls.key"
+	caCertSecretKey  = "ca.crt"
+)
+
+// hasTLSCredentials returns true if the credentials map contains a client certificate or a CA bundle
+func hasTLSCredentials(credentials map[string][]byte) bool {
+	for _, key := range []string{tlsCertSecretKey, tlsKeySecretKey, caCertSecretKey} {
+		if _, ok := credentials[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func newTLSConfig(credentials map[string][]byte) (*tls.Config, error) {
+	cert, hasCert := credentials[tlsCertSecretKey]
+	key, hasKey := credentials[tlsKeySecretKey]
+	ca, hasCA := credentials[caCertSecretKey]
+
+	if hasCert != hasKey {
+		return nil, fmt.Errorf("must contain both %s and %s", tlsCertSecretKey, tlsKeySecretKey)
+	}
+
+	tlsConfig := &tls.Config{}
+
+	if hasCert {
+		keyPair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate error: %s", err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{keyPair}
+	}
+
+	if hasCA {
+		pool := x509.NewCertPool()
+		if ok := pool.AppendCertsFromPEM(ca); !ok {
+			return nil, fmt.Errorf("%s does not contain a valid PEM certificate", caCertSecretKey)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// tokenFile reads a bearer token from disk on every request,
+// projected service account tokens are rotated by the kubelet before they expire
+type tokenFile struct {
+	path  string
+	mu    sync.Mutex
+	token string
+}
+
+func newTokenFile(path string) (*tokenFile, error) {
+	t := &tokenFile{path: path}
+	if _, err := t.Token(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Token reads the token from disk, the last known token is returned while the file is being rotated
+func (t *tokenFile) Token() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, err := ioutil.ReadFile(t.path)
+	if err != nil {
+		if t.token != "" {
+			return t.token, nil
+		}
+		return "", fmt.Errorf("token file %s read error: %s", t.path, err.Error())
+	}
+
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		if t.token != "" {
+			return t.token, nil
+		}
+		return "", fmt.Errorf("token file %s is empty", t.path)
+	}
+
+	t.token = token
+	return t.token, nil
+}
+
+// tokenFilePath returns the path of a metric template token file in the controller token directory,
+// the templates can't read files outside of it e.g. the controller service account token
+func tokenFilePath(name string) (string, error) {
+	if clientOptions.TokenDir == "" {
+		return "", fmt.Errorf("token file %s can't be used, the controller token directory is not set", name)
+	}
+	if filepath.IsAbs(name) || name != filepath.Clean(name) || strings.HasPrefix(name, "..") {
+		return "", fmt.Errorf("token file %s must be a relative path in the controller token directory", name)
+	}
+	return filepath.Join(clientOptions.TokenDir, name), nil
+}
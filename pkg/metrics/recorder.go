@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,6 +16,24 @@ type Recorder struct {
 	total    *prometheus.GaugeVec
 	status   *prometheus.GaugeVec
 	weight   *prometheus.GaugeVec
+	queries  *prometheus.GaugeVec
+	cost     *prometheus.GaugeVec
+	eta      *prometheus.GaugeVec
+	gate     *prometheus.GaugeVec
+	gateWait *prometheus.GaugeVec
+	gateFlip *prometheus.CounterVec
+	hookFail *prometheus.CounterVec
+	halts    *prometheus.CounterVec
+	rollback *prometheus.HistogramVec
+	breaches *prometheus.CounterVec
+	gates    *gateStates
+}
+
+// gateStates holds the last state of the gates to count their changes and closed time
+type gateStates struct {
+	mu       sync.Mutex
+	closedAt map[string]time.Time
+	open     map[string]bool
 }
 
 // NewRecorder creates a new recorder and registers the Prometheus metrics
@@ -51,12 +70,83 @@ func NewRecorder(controller string, register bool) Recorder {
 		Help:      "The virtual service destination weight current value",
 	}, []string{"workload", "namespace"})
 
+	queries := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: controller,
+		Name:      "canary_provider_queries",
+		Help:      "Number of metric provider queries run during the current canary analysis",
+	}, []string{"name", "namespace", "provider"})
+
+	cost := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: controller,
+		Name:      "canary_provider_query_cost",
+		Help:      "Estimated cost of the metric provider queries run during the current canary analysis",
+	}, []string{"name", "namespace", "provider"})
+
+	eta := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: controller,
+		Name:      "canary_promotion_remaining_seconds",
+		Help:      "Estimated seconds left until the canary is promoted",
+	}, []string{"name", "namespace"})
+
+	gate := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: controller,
+		Name:      "canary_gate_open",
+		Help:      "Last state of the canary gate, 1 when open and 0 when closed",
+	}, []string{"name", "namespace", "type", "webhook"})
+
+	gateWait := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: controller,
+		Name:      "canary_gate_closed_seconds",
+		Help:      "Seconds since the canary gate closed, 0 when open",
+	}, []string{"name", "namespace", "type", "webhook"})
+
+	gateFlip := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: controller,
+		Name:      "canary_gate_changes_total",
+		Help:      "Number of times the canary gate opened or closed",
+	}, []string{"name", "namespace", "type", "webhook", "status"})
+
+	hookFail := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: controller,
+		Name:      "canary_webhook_failures_total",
+		Help:      "Number of failed canary webhook calls",
+	}, []string{"name", "namespace", "type", "webhook"})
+
+	halts := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: controller,
+		Name:      "canary_halts_total",
+		Help:      "Number of halted canary analysis iterations by reason",
+	}, []string{"name", "namespace", "reason"})
+
+	rollback := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: controller,
+		Name:      "canary_rollback_duration_seconds",
+		Help:      "Seconds from the canary failure detection to all traffic routed to primary.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600},
+	}, []string{"name", "namespace"})
+
+	breaches := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: controller,
+		Name:      "canary_rollback_time_exceeded_total",
+		Help:      "Number of rollbacks that took longer than the canary max rollback time",
+	}, []string{"name", "namespace"})
+
 	if register {
 		prometheus.MustRegister(info)
 		prometheus.MustRegister(duration)
 		prometheus.MustRegister(total)
 		prometheus.MustRegister(status)
 		prometheus.MustRegister(weight)
+		prometheus.MustRegister(queries)
+		prometheus.MustRegister(cost)
+		prometheus.MustRegister(eta)
+		prometheus.MustRegister(gate)
+		prometheus.MustRegister(gateWait)
+		prometheus.MustRegister(gateFlip)
+		prometheus.MustRegister(hookFail)
+		prometheus.MustRegister(halts)
+		prometheus.MustRegister(rollback)
+		prometheus.MustRegister(breaches)
 	}
 
 	return Recorder{
@@ -65,6 +155,20 @@ func NewRecorder(controller string, register bool) Recorder {
 		total:    total,
 		status:   status,
 		weight:   weight,
+		queries:  queries,
+		cost:     cost,
+		eta:      eta,
+		gate:     gate,
+		gateWait: gateWait,
+		gateFlip: gateFlip,
+		hookFail: hookFail,
+		halts:    halts,
+		rollback: rollback,
+		breaches: breaches,
+		gates: &gateStates{
+			closedAt: make(map[string]time.Time),
+			open:     make(map[string]bool),
+		},
 	}
 }
 
@@ -89,7 +193,7 @@ func (cr *Recorder) SetStatus(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase)
 	switch phase {
 	case flaggerv1.CanaryPhaseProgressing:
 		status = 0
-	case flaggerv1.CanaryPhaseFailed:
+	case flaggerv1.CanaryPhaseFailed, flaggerv1.CanaryPhaseRollingBack:
 		status = 2
 	default:
 		status = 1
@@ -102,3 +206,73 @@ func (cr *Recorder) SetWeight(cd *flaggerv1.Canary, primary int, canary int) {
 	cr.weight.WithLabelValues(fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name), cd.Namespace).Set(float64(primary))
 	cr.weight.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Set(float64(canary))
 }
+
+// SetProviderBudget sets the number of queries and the estimated cost spent on a metric provider
+func (cr *Recorder) SetProviderBudget(cd *flaggerv1.Canary, provider string, queries int, cost float64) {
+	cr.queries.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace, provider).Set(float64(queries))
+	cr.cost.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace, provider).Set(cost)
+}
+
+// SetPromotionRemaining sets the estimated time left until the canary is promoted
+func (cr *Recorder) SetPromotionRemaining(cd *flaggerv1.Canary, remaining time.Duration) {
+	cr.eta.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Set(remaining.Seconds())
+}
+
+// DeletePromotionRemaining removes the promotion estimate of a canary that isn't progressing
+func (cr *Recorder) DeletePromotionRemaining(cd *flaggerv1.Canary) {
+	cr.eta.DeleteLabelValues(cd.Spec.TargetRef.Name, cd.Namespace)
+}
+
+// SetGate sets the state of a confirm gate, the changes are counted
+// and the closed time is measured from the first check that found the gate closed
+func (cr *Recorder) SetGate(cd *flaggerv1.Canary, hookType flaggerv1.HookType, webhook string, open bool) {
+	labels := []string{cd.Spec.TargetRef.Name, cd.Namespace, string(hookType), webhook}
+	key := fmt.Sprintf("%s/%s/%s/%s", cd.Namespace, cd.Spec.TargetRef.Name, hookType, webhook)
+
+	cr.gates.mu.Lock()
+	defer cr.gates.mu.Unlock()
+
+	if last, ok := cr.gates.open[key]; !ok || last != open {
+		status := "closed"
+		if open {
+			status = "open"
+		}
+		cr.gateFlip.WithLabelValues(append(labels, status)...).Inc()
+	}
+	cr.gates.open[key] = open
+
+	if open {
+		delete(cr.gates.closedAt, key)
+		cr.gate.WithLabelValues(labels...).Set(1)
+		cr.gateWait.WithLabelValues(labels...).Set(0)
+		return
+	}
+
+	closedAt, ok := cr.gates.closedAt[key]
+	if !ok {
+		closedAt = time.Now()
+		cr.gates.closedAt[key] = closedAt
+	}
+	cr.gate.WithLabelValues(labels...).Set(0)
+	cr.gateWait.WithLabelValues(labels...).Set(time.Since(closedAt).Seconds())
+}
+
+// IncWebhookFailures increments the failed calls of a webhook
+func (cr *Recorder) IncWebhookFailures(cd *flaggerv1.Canary, hookType flaggerv1.HookType, webhook string) {
+	cr.hookFail.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace, string(hookType), webhook).Inc()
+}
+
+// IncHalts increments the halted analysis iterations of a canary for the given reason
+func (cr *Recorder) IncHalts(cd *flaggerv1.Canary, reason string) {
+	cr.halts.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace, reason).Inc()
+}
+
+// SetRollbackDuration records the time from the failure detection to the traffic restored to primary
+func (cr *Recorder) SetRollbackDuration(cd *flaggerv1.Canary, duration time.Duration) {
+	cr.rollback.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Observe(duration.Seconds())
+}
+
+// IncRollbackTimeExceeded increments the rollbacks that exceeded the max rollback time of a canary
+func (cr *Recorder) IncRollbackTimeExceeded(cd *flaggerv1.Canary) {
+	cr.breaches.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Inc()
+}
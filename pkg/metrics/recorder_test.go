@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newRecorderTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{Name: "podinfo"},
+		},
+	}
+}
+
+func TestRecorder_SetGate(t *testing.T) {
+	cr := NewRecorder("flagger", false)
+	cd := newRecorderTestCanary()
+
+	cr.SetGate(cd, flaggerv1.ConfirmRolloutHook, "approval", false)
+	cr.SetGate(cd, flaggerv1.ConfirmRolloutHook, "approval", false)
+
+	if v := testutil.ToFloat64(cr.gate.WithLabelValues("podinfo", "default", "confirm-rollout", "approval")); v != 0 {
+		t.Errorf("Got gate open %v wanted 0", v)
+	}
+	if v := testutil.ToFloat64(cr.gateWait.WithLabelValues("podinfo", "default", "confirm-rollout", "approval")); v <= 0 {
+		t.Errorf("Got gate closed seconds %v wanted > 0", v)
+	}
+	closed := cr.gateFlip.WithLabelValues("podinfo", "default", "confirm-rollout", "approval", "closed")
+	if v := testutil.ToFloat64(closed); v != 1 {
+		t.Errorf("Got gate closed changes %v wanted 1", v)
+	}
+
+	cr.SetGate(cd, flaggerv1.ConfirmRolloutHook, "approval", true)
+
+	if v := testutil.ToFloat64(cr.gate.WithLabelValues("podinfo", "default", "confirm-rollout", "approval")); v != 1 {
+		t.Errorf("Got gate open %v wanted 1", v)
+	}
+	if v := testutil.ToFloat64(cr.gateWait.WithLabelValues("podinfo", "default", "confirm-rollout", "approval")); v != 0 {
+		t.Errorf("Got gate closed seconds %v wanted 0", v)
+	}
+	opened := cr.gateFlip.WithLabelValues("podinfo", "default", "confirm-rollout", "approval", "open")
+	if v := testutil.ToFloat64(opened); v != 1 {
+		t.Errorf("Got gate open changes %v wanted 1", v)
+	}
+}
+
+func TestRecorder_IncHalts(t *testing.T) {
+	cr := NewRecorder("flagger", false)
+	cd := newRecorderTestCanary()
+
+	cr.IncHalts(cd, "metrics")
+	cr.IncHalts(cd, "metrics")
+	cr.IncWebhookFailures(cd, flaggerv1.PreRolloutHook, "smoke-test")
+
+	if v := testutil.ToFloat64(cr.halts.WithLabelValues("podinfo", "default", "metrics")); v != 2 {
+		t.Errorf("Got halts %v wanted 2", v)
+	}
+	if v := testutil.ToFloat64(cr.hookFail.WithLabelValues("podinfo", "default", "pre-rollout", "smoke-test")); v != 1 {
+		t.Errorf("Got webhook failures %v wanted 1", v)
+	}
+}
+
+func TestRecorder_SetRollbackDuration(t *testing.T) {
+	cr := NewRecorder("flagger", false)
+	cd := newRecorderTestCanary()
+
+	cr.SetRollbackDuration(cd, 90*time.Second)
+	cr.IncRollbackTimeExceeded(cd)
+
+	expected := `
+		# HELP flagger_canary_rollback_duration_seconds Seconds from the canary failure detection to all traffic routed to primary.
+		# TYPE flagger_canary_rollback_duration_seconds histogram
+		flagger_canary_rollback_duration_seconds_bucket{name="podinfo",namespace="default",le="1"} 0
+		flagger_canary_rollback_duration_seconds_bucket{name="podinfo",namespace="default",le="5"} 0
+		flagger_canary_rollback_duration_seconds_bucket{name="podinfo",namespace="default",le="10"} 0
+		flagger_canary_rollback_duration_seconds_bucket{name="podinfo",namespace="default",le="30"} 0
+		flagger_canary_rollback_duration_seconds_bucket{name="podinfo",namespace="default",le="60"} 0
+		flagger_canary_rollback_duration_seconds_bucket{name="podinfo",namespace="default",le="120"} 1
+		flagger_canary_rollback_duration_seconds_bucket{name="podinfo",namespace="default",le="300"} 1
+		flagger_canary_rollback_duration_seconds_bucket{name="podinfo",namespace="default",le="600"} 1
+		flagger_canary_rollback_duration_seconds_bucket{name="podinfo",namespace="default",le="1800"} 1
+		flagger_canary_rollback_duration_seconds_bucket{name="podinfo",namespace="default",le="3600"} 1
+		flagger_canary_rollback_duration_seconds_bucket{name="podinfo",namespace="default",le="+Inf"} 1
+		flagger_canary_rollback_duration_seconds_sum{name="podinfo",namespace="default"} 90
+		flagger_canary_rollback_duration_seconds_count{name="podinfo",namespace="default"} 1
+	`
+	if err := testutil.CollectAndCompare(cr.rollback, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+	if v := testutil.ToFloat64(cr.breaches.WithLabelValues("podinfo", "default")); v != 1 {
+		t.Errorf("Got rollback time exceeded %v wanted 1", v)
+	}
+}
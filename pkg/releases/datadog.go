@@ -0,0 +1,75 @@
+package releases
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	datadogDefaultAddress  = "https://api.datadoghq.com"
+	datadogAPIKeySecretKey = "datadog_api_key"
+)
+
+// Datadog posts the releases as events to the Datadog events API,
+// the version and service tags are used by APM Deployment Tracking
+type Datadog struct {
+	URL    string
+	APIKey string
+}
+
+// DatadogEvent holds the Datadog event payload
+type DatadogEvent struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	Tags           []string `json:"tags"`
+	AlertType      string   `json:"alert_type"`
+	SourceTypeName string   `json:"source_type_name"`
+	AggregationKey string   `json:"aggregation_key"`
+}
+
+// NewDatadog validates the address and returns a Datadog release tracker
+func NewDatadog(tracker flaggerv1.CanaryReleaseTracker, credentials map[string][]byte) (*Datadog, error) {
+	address := datadogDefaultAddress
+	if tracker.Address != "" {
+		address = tracker.Address
+	}
+
+	if _, err := url.ParseRequestURI(address); err != nil {
+		return nil, fmt.Errorf("invalid Datadog address %s", address)
+	}
+
+	apiKey, ok := credentials[datadogAPIKeySecretKey]
+	if !ok {
+		return nil, fmt.Errorf("datadog credentials does not contain %s", datadogAPIKeySecretKey)
+	}
+
+	return &Datadog{
+		URL:    strings.TrimSuffix(address, "/") + "/api/v1/events",
+		APIKey: string(apiKey),
+	}, nil
+}
+
+// Track posts the release event
+func (d *Datadog) Track(release Release) error {
+	event := DatadogEvent{
+		Title: fmt.Sprintf("Canary %s %s.%s version %s",
+			release.Phase, release.Name, release.Namespace, release.Version),
+		Text: fmt.Sprintf("Flagger canary analysis %s for %s version %s in %s",
+			release.Phase, release.Service, release.Version, release.Environment),
+		Tags: []string{
+			"service:" + release.Service,
+			"env:" + release.Environment,
+			"version:" + release.Version,
+			"deployment_phase:" + string(release.Phase),
+			"source:flagger",
+		},
+		AlertType:      "info",
+		SourceTypeName: "flagger",
+		AggregationKey: fmt.Sprintf("%s-%s", release.Service, release.Version),
+	}
+
+	return postJSON(d.URL, map[string]string{"DD-API-KEY": d.APIKey}, event)
+}
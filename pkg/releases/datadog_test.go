@@ -0,0 +1,70 @@
+package releases
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestDatadog_Track(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/events" {
+			t.Errorf("Got path %s wanted %s", r.URL.Path, "/api/v1/events")
+		}
+		if r.Header.Get("DD-API-KEY") != "key" {
+			t.Errorf("Got api key %s wanted %s", r.Header.Get("DD-API-KEY"), "key")
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var event DatadogEvent
+		if err := json.Unmarshal(b, &event); err != nil {
+			t.Fatal(err)
+		}
+
+		tags := map[string]bool{}
+		for _, tag := range event.Tags {
+			tags[tag] = true
+		}
+		for _, tag := range []string{"service:podinfo", "env:test", "version:1.2.3"} {
+			if !tags[tag] {
+				t.Errorf("Tag %s not found in %v", tag, event.Tags)
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	tracker, err := NewDatadog(flaggerv1.CanaryReleaseTracker{Type: "datadog", Address: ts.URL},
+		map[string][]byte{datadogAPIKeySecretKey: []byte("key")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tracker.Track(Release{
+		Name:        "podinfo",
+		Namespace:   "test",
+		Service:     "podinfo",
+		Environment: "test",
+		Version:     "1.2.3",
+		Phase:       PhaseStarted,
+		Timestamp:   time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDatadog_MissingAPIKey(t *testing.T) {
+	_, err := NewDatadog(flaggerv1.CanaryReleaseTracker{Type: "datadog"}, nil)
+	if err == nil {
+		t.Errorf("Expected error when %s is missing", datadogAPIKeySecretKey)
+	}
+}
@@ -0,0 +1,22 @@
+package releases
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+type Factory struct{}
+
+func (factory Factory) Tracker(tracker flaggerv1.CanaryReleaseTracker, credentials map[string][]byte) (Interface, error) {
+	switch {
+	case tracker.Type == "datadog":
+		return NewDatadog(tracker, credentials)
+	case tracker.Type == "newrelic":
+		return NewNewRelic(tracker, credentials)
+	case tracker.Type == "sentry":
+		return NewSentry(tracker, credentials)
+	}
+
+	return nil, fmt.Errorf("release tracker %s not supported", tracker.Type)
+}
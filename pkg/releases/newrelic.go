@@ -0,0 +1,74 @@
+package releases
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	newRelicDefaultAddress  = "https://api.newrelic.com"
+	newRelicAPIKeySecretKey = "newrelic_api_key"
+)
+
+// NewRelic records the releases with the New Relic change tracking API
+type NewRelic struct {
+	URL    string
+	APIKey string
+}
+
+// NewRelicPayload holds the New Relic deployment marker
+type NewRelicPayload struct {
+	Deployment NewRelicDeployment `json:"deployment"`
+}
+
+type NewRelicDeployment struct {
+	Revision    string `json:"revision"`
+	Description string `json:"description"`
+	User        string `json:"user"`
+	Timestamp   string `json:"timestamp"`
+}
+
+// NewNewRelic validates the address and the application ID and returns a New Relic release tracker
+func NewNewRelic(tracker flaggerv1.CanaryReleaseTracker, credentials map[string][]byte) (*NewRelic, error) {
+	address := newRelicDefaultAddress
+	if tracker.Address != "" {
+		address = tracker.Address
+	}
+
+	if _, err := url.ParseRequestURI(address); err != nil {
+		return nil, fmt.Errorf("invalid New Relic address %s", address)
+	}
+
+	if tracker.Project == "" {
+		return nil, errors.New("empty New Relic application ID, set the release tracker project")
+	}
+
+	apiKey, ok := credentials[newRelicAPIKeySecretKey]
+	if !ok {
+		return nil, fmt.Errorf("newrelic credentials does not contain %s", newRelicAPIKeySecretKey)
+	}
+
+	return &NewRelic{
+		URL:    fmt.Sprintf("%s/v2/applications/%s/deployments.json", strings.TrimSuffix(address, "/"), tracker.Project),
+		APIKey: string(apiKey),
+	}, nil
+}
+
+// Track posts the deployment marker
+func (n *NewRelic) Track(release Release) error {
+	payload := NewRelicPayload{
+		Deployment: NewRelicDeployment{
+			Revision: release.Version,
+			Description: fmt.Sprintf("Flagger canary analysis %s for %s.%s in %s",
+				release.Phase, release.Name, release.Namespace, release.Environment),
+			User:      "flagger",
+			Timestamp: release.Timestamp.UTC().Format("2006-01-02T15:04:05Z"),
+		},
+	}
+
+	return postJSON(n.URL, map[string]string{"X-Api-Key": n.APIKey}, payload)
+}
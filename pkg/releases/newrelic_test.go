@@ -0,0 +1,64 @@
+package releases
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewRelic_Track(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/applications/1234/deployments.json" {
+			t.Errorf("Got path %s wanted %s", r.URL.Path, "/v2/applications/1234/deployments.json")
+		}
+		if r.Header.Get("X-Api-Key") != "key" {
+			t.Errorf("Got api key %s wanted %s", r.Header.Get("X-Api-Key"), "key")
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var payload NewRelicPayload
+		if err := json.Unmarshal(b, &payload); err != nil {
+			t.Fatal(err)
+		}
+
+		if payload.Deployment.Revision != "1.2.3" {
+			t.Errorf("Got revision %s wanted %s", payload.Deployment.Revision, "1.2.3")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	tracker, err := NewNewRelic(flaggerv1.CanaryReleaseTracker{Type: "newrelic", Address: ts.URL, Project: "1234"},
+		map[string][]byte{newRelicAPIKeySecretKey: []byte("key")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tracker.Track(Release{
+		Name:      "podinfo",
+		Namespace: "test",
+		Service:   "podinfo",
+		Version:   "1.2.3",
+		Phase:     PhasePromoted,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewRelic_MissingProject(t *testing.T) {
+	_, err := NewNewRelic(flaggerv1.CanaryReleaseTracker{Type: "newrelic"},
+		map[string][]byte{newRelicAPIKeySecretKey: []byte("key")})
+	if err == nil {
+		t.Errorf("Expected error when the application ID is missing")
+	}
+}
@@ -0,0 +1,31 @@
+package releases
+
+import "time"
+
+// Phase is the canary release stage reported to the release trackers
+type Phase string
+
+const (
+	// PhaseStarted is reported when the canary analysis starts
+	PhaseStarted Phase = "started"
+	// PhasePromoted is reported when the canary is promoted
+	PhasePromoted Phase = "promoted"
+)
+
+// Release holds the canary version metadata
+type Release struct {
+	// Name and Namespace of the canary
+	Name      string
+	Namespace string
+
+	Service     string
+	Environment string
+	Version     string
+	Phase       Phase
+	Timestamp   time.Time
+}
+
+// Interface describes an APM release tracking API
+type Interface interface {
+	Track(release Release) error
+}
@@ -0,0 +1,93 @@
+package releases
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	sentryDefaultAddress = "https://sentry.io"
+	sentryTokenSecretKey = "sentry_token"
+)
+
+// Sentry creates a release when the analysis starts and records a deploy for each phase
+type Sentry struct {
+	URL     string
+	Token   string
+	Project string
+}
+
+// SentryRelease holds the Sentry release payload
+type SentryRelease struct {
+	Version  string   `json:"version"`
+	Projects []string `json:"projects"`
+}
+
+// SentryDeploy holds the Sentry deploy payload
+type SentryDeploy struct {
+	Environment  string `json:"environment"`
+	Name         string `json:"name"`
+	DateFinished string `json:"dateFinished"`
+}
+
+// NewSentry validates the organization and project and returns a Sentry release tracker
+func NewSentry(tracker flaggerv1.CanaryReleaseTracker, credentials map[string][]byte) (*Sentry, error) {
+	address := sentryDefaultAddress
+	if tracker.Address != "" {
+		address = tracker.Address
+	}
+
+	if _, err := url.ParseRequestURI(address); err != nil {
+		return nil, fmt.Errorf("invalid Sentry address %s", address)
+	}
+
+	if tracker.Organization == "" {
+		return nil, errors.New("empty Sentry organization")
+	}
+
+	if tracker.Project == "" {
+		return nil, errors.New("empty Sentry project")
+	}
+
+	token, ok := credentials[sentryTokenSecretKey]
+	if !ok {
+		return nil, fmt.Errorf("sentry credentials does not contain %s", sentryTokenSecretKey)
+	}
+
+	return &Sentry{
+		URL:     fmt.Sprintf("%s/api/0/organizations/%s/releases/", strings.TrimSuffix(address, "/"), tracker.Organization),
+		Token:   string(token),
+		Project: tracker.Project,
+	}, nil
+}
+
+// Track creates the release on analysis start and records the deploy
+func (s *Sentry) Track(release Release) error {
+	headers := map[string]string{"Authorization": "Bearer " + s.Token}
+
+	// Sentry release versions are unique per organization
+	version := fmt.Sprintf("%s@%s", release.Service, release.Version)
+
+	if release.Phase == PhaseStarted {
+		payload := SentryRelease{
+			Version:  version,
+			Projects: []string{s.Project},
+		}
+		if err := postJSON(s.URL, headers, payload); err != nil {
+			return err
+		}
+	}
+
+	deploy := SentryDeploy{
+		Environment:  release.Environment,
+		Name:         fmt.Sprintf("canary %s", release.Phase),
+		DateFinished: release.Timestamp.UTC().Format(time.RFC3339),
+	}
+
+	return postJSON(fmt.Sprintf("%s%s/deploys/", s.URL, url.PathEscape(version)), headers, deploy)
+}
@@ -0,0 +1,64 @@
+package releases
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestSentry_Track(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Got authorization %s wanted %s", r.Header.Get("Authorization"), "Bearer token")
+		}
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	tracker, err := NewSentry(flaggerv1.CanaryReleaseTracker{
+		Type:         "sentry",
+		Address:      ts.URL,
+		Organization: "flagger",
+		Project:      "podinfo",
+	}, map[string][]byte{sentryTokenSecretKey: []byte("token")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := Release{
+		Name:        "podinfo",
+		Namespace:   "test",
+		Service:     "podinfo",
+		Environment: "test",
+		Version:     "1.2.3",
+		Phase:       PhaseStarted,
+		Timestamp:   time.Now(),
+	}
+	if err := tracker.Track(release); err != nil {
+		t.Fatal(err)
+	}
+
+	release.Phase = PhasePromoted
+	if err := tracker.Track(release); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"/api/0/organizations/flagger/releases/",
+		"/api/0/organizations/flagger/releases/podinfo@1.2.3/deploys/",
+		"/api/0/organizations/flagger/releases/podinfo@1.2.3/deploys/",
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("Got paths %v wanted %v", paths, expected)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("Got path %s wanted %s", paths[i], expected[i])
+		}
+	}
+}
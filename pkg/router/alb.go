@@ -0,0 +1,347 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// albAnnotationsPrefix is the prefix of the AWS Load Balancer Controller annotations
+const albAnnotationsPrefix = "alb.ingress.kubernetes.io"
+
+// albUseAnnotation is the service port that tells the AWS Load Balancer Controller
+// to look up the backend action in the ingress annotations
+const albUseAnnotation = "use-annotation"
+
+// ALBRouter is managing the weighted forward actions of an ingress served by the AWS Load Balancer Controller,
+// the paths of the apex service are forwarded to the primary and canary target groups
+type ALBRouter struct {
+	kubeClient kubernetes.Interface
+	logger     *zap.SugaredLogger
+}
+
+type albAction struct {
+	Type          string           `json:"type"`
+	ForwardConfig albForwardConfig `json:"forwardConfig"`
+}
+
+type albForwardConfig struct {
+	TargetGroups []albTargetGroup `json:"targetGroups"`
+}
+
+type albTargetGroup struct {
+	ServiceName string `json:"serviceName"`
+	ServicePort string `json:"servicePort"`
+	Weight      int    `json:"weight"`
+}
+
+type albCondition struct {
+	Field            string              `json:"field"`
+	HttpHeaderConfig albHttpHeaderConfig `json:"httpHeaderConfig"`
+}
+
+type albHttpHeaderConfig struct {
+	HttpHeaderName string   `json:"httpHeaderName"`
+	Values         []string `json:"values"`
+}
+
+// Reconcile points the paths of the apex service to the weighted forward action,
+// for A/B testing a path with the match conditions is added in front of every apex path
+func (ar *ALBRouter) Reconcile(canary *flaggerv1.Canary) error {
+	if canary.Spec.IngressRef == nil || canary.Spec.IngressRef.Name == "" {
+		return fmt.Errorf("ingress selector is empty")
+	}
+
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	ingressName := canary.Spec.IngressRef.Name
+
+	conditions, err := ar.makeConditions(canary)
+	if err != nil {
+		return err
+	}
+
+	ingress, err := ar.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Get(ingressName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s query error %v", ingressName, canary.Namespace, err)
+	}
+
+	ingressClone := ingress.DeepCopy()
+
+	backendExists := false
+	for k, rule := range ingressClone.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		paths := make([]v1beta1.HTTPIngressPath, 0, len(rule.HTTP.Paths))
+		for _, path := range rule.HTTP.Paths {
+			if strings.HasPrefix(path.Backend.ServiceName, albABTestPrefix(apexName)) {
+				continue
+			}
+			if path.Backend.ServiceName == apexName {
+				for i := range conditions {
+					abPath := path
+					abPath.Backend.ServiceName = albABTestName(apexName, i)
+					abPath.Backend.ServicePort = intstr.FromString(albUseAnnotation)
+					paths = append(paths, abPath)
+				}
+				path.Backend.ServicePort = intstr.FromString(albUseAnnotation)
+				backendExists = true
+			}
+			paths = append(paths, path)
+		}
+		ingressClone.Spec.Rules[k].HTTP.Paths = paths
+	}
+
+	if !backendExists {
+		return fmt.Errorf("backend %s not found in ingress %s", apexName, ingressName)
+	}
+
+	annotations := make(map[string]string, len(ingress.Annotations))
+	for k, v := range ingress.Annotations {
+		if strings.HasPrefix(k, albActionAnnotation(albABTestPrefix(apexName))) ||
+			strings.HasPrefix(k, albConditionsAnnotation(albABTestPrefix(apexName))) {
+			continue
+		}
+		annotations[k] = v
+	}
+
+	// keep the weights set by SetRoutes
+	port := fmt.Sprintf("%d", canary.Spec.Service.Port)
+	primaryWeight, canaryWeight, err := albWeights(ingress.Annotations[albActionAnnotation(apexName)], primaryName, canaryName, port)
+	if err != nil {
+		primaryWeight, canaryWeight = 100, 0
+	}
+	action, err := albForwardAction(primaryName, canaryName, port, primaryWeight, canaryWeight)
+	if err != nil {
+		return err
+	}
+	annotations[albActionAnnotation(apexName)] = action
+
+	for i, condition := range conditions {
+		name := albABTestName(apexName, i)
+		primaryWeight, canaryWeight, err := albWeights(ingress.Annotations[albActionAnnotation(name)], primaryName, canaryName, port)
+		if err != nil {
+			primaryWeight, canaryWeight = 100, 0
+		}
+		action, err := albForwardAction(primaryName, canaryName, port, primaryWeight, canaryWeight)
+		if err != nil {
+			return err
+		}
+		annotations[albActionAnnotation(name)] = action
+		annotations[albConditionsAnnotation(name)] = condition
+	}
+
+	if cmp.Equal(ingressClone.Spec, ingress.Spec) && cmp.Equal(annotations, ingress.Annotations) {
+		return nil
+	}
+
+	ingressClone.Annotations = annotations
+	_, err = ar.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Update(ingressClone)
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s update error %v", ingressName, canary.Namespace, err)
+	}
+
+	ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("Ingress %s.%s updated", ingressName, canary.Namespace)
+	return nil
+}
+
+// GetRoutes returns the primary and canary weights of the forward action,
+// for A/B testing the weights of the match conditions action are returned
+func (ar *ALBRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	ingressName := canary.Spec.IngressRef.Name
+	ingress, err := ar.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Get(ingressName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("ingress %s.%s query error %v", ingressName, canary.Namespace, err)
+		return
+	}
+
+	actionName := apexName
+	if len(canary.GetAnalysis().Match) > 0 {
+		actionName = albABTestName(apexName, 0)
+	}
+
+	port := fmt.Sprintf("%d", canary.Spec.Service.Port)
+	primaryWeight, canaryWeight, err = albWeights(ingress.Annotations[albActionAnnotation(actionName)], primaryName, canaryName, port)
+	if err != nil {
+		err = fmt.Errorf("ingress %s.%s action %s error %v", ingressName, canary.Namespace, actionName, err)
+	}
+
+	mirrored = false
+	return
+}
+
+// SetRoutes updates the primary and canary weights of the forward action,
+// for A/B testing the weights apply to the match conditions actions and all other requests go to primary
+func (ar *ALBRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	ingressName := canary.Spec.IngressRef.Name
+	ingress, err := ar.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Get(ingressName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s query error %v", ingressName, canary.Namespace, err)
+	}
+
+	port := fmt.Sprintf("%d", canary.Spec.Service.Port)
+	ingressClone := ingress.DeepCopy()
+	if ingressClone.Annotations == nil {
+		ingressClone.Annotations = make(map[string]string)
+	}
+
+	if len(canary.GetAnalysis().Match) > 0 {
+		for i := range canary.GetAnalysis().Match {
+			action, err := albForwardAction(primaryName, canaryName, port, primaryWeight, canaryWeight)
+			if err != nil {
+				return err
+			}
+			ingressClone.Annotations[albActionAnnotation(albABTestName(apexName, i))] = action
+		}
+		primaryWeight, canaryWeight = 100, 0
+	}
+
+	action, err := albForwardAction(primaryName, canaryName, port, primaryWeight, canaryWeight)
+	if err != nil {
+		return err
+	}
+	ingressClone.Annotations[albActionAnnotation(apexName)] = action
+
+	_, err = ar.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Update(ingressClone)
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s update error %v", ingressName, canary.Namespace, err)
+	}
+	return nil
+}
+
+// makeConditions converts the A/B testing header matches to ALB http-header conditions,
+// ALB header values support the * and ? wildcards so regex matches are rejected
+func (ar *ALBRouter) makeConditions(canary *flaggerv1.Canary) ([]string, error) {
+	var result []string
+	for _, match := range canary.GetAnalysis().Match {
+		var conditions []albCondition
+		for name, header := range match.Headers {
+			var value string
+			switch {
+			case header.Exact != "":
+				value = header.Exact
+			case header.Prefix != "":
+				value = header.Prefix + "*"
+			case header.Suffix != "":
+				value = "*" + header.Suffix
+			default:
+				return nil, fmt.Errorf("header %s match is not supported by ALB, use exact, prefix or suffix", name)
+			}
+			conditions = append(conditions, albCondition{
+				Field: "http-header",
+				HttpHeaderConfig: albHttpHeaderConfig{
+					HttpHeaderName: name,
+					Values:         []string{value},
+				},
+			})
+		}
+		if len(conditions) == 0 {
+			return nil, fmt.Errorf("ALB A/B testing requires header matches")
+		}
+
+		// the headers map is not ordered
+		sort.Slice(conditions, func(i, j int) bool {
+			return conditions[i].HttpHeaderConfig.HttpHeaderName < conditions[j].HttpHeaderConfig.HttpHeaderName
+		})
+		b, err := json.Marshal(conditions)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, string(b))
+	}
+	return result, nil
+}
+
+func albForwardAction(primaryName string, canaryName string, port string, primaryWeight int, canaryWeight int) (string, error) {
+	action := albAction{
+		Type: "forward",
+		ForwardConfig: albForwardConfig{
+			TargetGroups: []albTargetGroup{
+				{
+					ServiceName: primaryName,
+					ServicePort: port,
+					Weight:      primaryWeight,
+				},
+				{
+					ServiceName: canaryName,
+					ServicePort: port,
+					Weight:      canaryWeight,
+				},
+			},
+		},
+	}
+	b, err := json.Marshal(action)
+	if err != nil {
+		return "", fmt.Errorf("marshaling the forward action failed %v", err)
+	}
+	return string(b), nil
+}
+
+// albWeights returns the primary and canary weights of a forward action annotation,
+// the action must target the primary and canary services on the given port
+func albWeights(annotation string, primaryName string, canaryName string, port string) (int, int, error) {
+	if annotation == "" {
+		return 0, 0, fmt.Errorf("forward action not found")
+	}
+
+	var action albAction
+	if err := json.Unmarshal([]byte(annotation), &action); err != nil {
+		return 0, 0, fmt.Errorf("forward action unmarshal failed %v", err)
+	}
+
+	primaryWeight, canaryWeight := -1, -1
+	for _, tg := range action.ForwardConfig.TargetGroups {
+		if tg.ServicePort != port {
+			continue
+		}
+		switch tg.ServiceName {
+		case primaryName:
+			primaryWeight = tg.Weight
+		case canaryName:
+			canaryWeight = tg.Weight
+		}
+	}
+	if primaryWeight < 0 || canaryWeight < 0 {
+		return 0, 0, fmt.Errorf("forward action does not contain target groups for %s and %s", primaryName, canaryName)
+	}
+	return primaryWeight, canaryWeight, nil
+}
+
+func albActionAnnotation(name string) string {
+	return fmt.Sprintf("%s/actions.%s", albAnnotationsPrefix, name)
+}
+
+func albConditionsAnnotation(name string) string {
+	return fmt.Sprintf("%s/conditions.%s", albAnnotationsPrefix, name)
+}
+
+func albABTestPrefix(apexName string) string {
+	return fmt.Sprintf("%s-ab-", apexName)
+}
+
+func albABTestName(apexName string, index int) string {
+	return fmt.Sprintf("%s%d", albABTestPrefix(apexName), index)
+}
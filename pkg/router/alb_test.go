@@ -0,0 +1,192 @@
+package router
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+func newALBTestRouter(mocks fixture) *ALBRouter {
+	return &ALBRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+	}
+}
+
+func TestALBRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newALBTestRouter(mocks)
+
+	err := router.Reconcile(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ing, err := router.kubeClient.ExtensionsV1beta1().Ingresses("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	backend := ing.Spec.Rules[0].HTTP.Paths[0].Backend
+	if backend.ServiceName != "podinfo" || backend.ServicePort.String() != albUseAnnotation {
+		t.Errorf("Got backend %s:%s wanted podinfo:%s", backend.ServiceName, backend.ServicePort.String(), albUseAnnotation)
+	}
+
+	action := ing.Annotations["alb.ingress.kubernetes.io/actions.podinfo"]
+	expected := `{"type":"forward","forwardConfig":{"targetGroups":[` +
+		`{"serviceName":"podinfo-primary","servicePort":"9898","weight":100},` +
+		`{"serviceName":"podinfo-canary","servicePort":"9898","weight":0}]}}`
+	if action != expected {
+		t.Errorf("Got action %s wanted %s", action, expected)
+	}
+
+	if ing.Annotations["kubernetes.io/ingress.class"] != "nginx" {
+		t.Errorf("Expected the ingress annotations to be kept")
+	}
+}
+
+func TestALBRouter_GetSetRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newALBTestRouter(mocks)
+
+	err := router.Reconcile(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(mocks.ingressCanary, 70, 30, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// reconcile doesn't reset the weights
+	err = router.Reconcile(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, m, err := router.GetRoutes(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 70 || c != 30 || m {
+		t.Errorf("Got primary=%v canary=%v mirrored=%v wanted primary=70 canary=30 mirrored=false", p, c, m)
+	}
+}
+
+func TestALBRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newALBTestRouter(mocks)
+
+	abtest := mocks.ingressCanary.DeepCopy()
+	abtest.Spec.CanaryAnalysis.Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-user-type": {
+					Exact: "insider",
+				},
+			},
+		},
+	}
+
+	err := router.Reconcile(abtest)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(abtest, 0, 100, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ing, err := router.kubeClient.ExtensionsV1beta1().Ingresses("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	paths := ing.Spec.Rules[0].HTTP.Paths
+	if len(paths) != 2 || paths[0].Backend.ServiceName != "podinfo-ab-0" || paths[1].Backend.ServiceName != "podinfo" {
+		t.Fatalf("Got paths %v wanted podinfo-ab-0 and podinfo", paths)
+	}
+
+	conditions := ing.Annotations["alb.ingress.kubernetes.io/conditions.podinfo-ab-0"]
+	expected := `[{"field":"http-header","httpHeaderConfig":{"httpHeaderName":"x-user-type","values":["insider"]}}]`
+	if conditions != expected {
+		t.Errorf("Got conditions %s wanted %s", conditions, expected)
+	}
+
+	p, c, _, err := router.GetRoutes(abtest)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 0 || c != 100 {
+		t.Errorf("Got primary=%v canary=%v wanted primary=0 canary=100", p, c)
+	}
+
+	// the requests that don't match the conditions are routed to primary
+	p, c, err = albWeights(ing.Annotations["alb.ingress.kubernetes.io/actions.podinfo"], "podinfo-primary", "podinfo-canary", "9898")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 100 || c != 0 {
+		t.Errorf("Got primary=%v canary=%v wanted primary=100 canary=0", p, c)
+	}
+
+	// removing the match conditions removes the A/B testing path
+	err = router.Reconcile(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ing, err = router.kubeClient.ExtensionsV1beta1().Ingresses("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(ing.Spec.Rules[0].HTTP.Paths) != 1 {
+		t.Errorf("Got paths %v wanted podinfo", ing.Spec.Rules[0].HTTP.Paths)
+	}
+	if _, ok := ing.Annotations["alb.ingress.kubernetes.io/conditions.podinfo-ab-0"]; ok {
+		t.Errorf("Expected the A/B testing conditions to be removed")
+	}
+}
+
+func TestALBRouter_ABTestRegex(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newALBTestRouter(mocks)
+
+	abtest := mocks.ingressCanary.DeepCopy()
+	abtest.Spec.CanaryAnalysis.Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"cookie": {
+					Regex: "^(.*?;)?(canary=always)(;.*)?$",
+				},
+			},
+		},
+	}
+
+	if err := router.Reconcile(abtest); err == nil {
+		t.Errorf("Expected an error for the regex header match")
+	}
+}
+
+func TestRunConformance_ALB(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newALBTestRouter(mocks)
+
+	results := RunConformance(router, mocks.ingressCanary)
+	for _, r := range results {
+		if r.Check == "mirror" {
+			if !r.Skipped {
+				t.Errorf("Expected the mirror check to be skipped")
+			}
+			continue
+		}
+		if !r.Passed {
+			t.Errorf("Check %s failed %s", r.Check, r.Message)
+		}
+	}
+}
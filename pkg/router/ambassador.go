@@ -0,0 +1,245 @@
+package router
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	ambassadorv2 "github.com/weaveworks/flagger/pkg/apis/ambassador/v2"
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// AmbassadorRouter is managing Ambassador Mappings
+type AmbassadorRouter struct {
+	kubeClient       kubernetes.Interface
+	ambassadorClient clientset.Interface
+	logger           *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the primary and canary mappings,
+// for A/B testing the canary mapping matches the request headers
+func (ar *AmbassadorRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	primarySpec, err := ar.makeSpec(canary, primaryName)
+	if err != nil {
+		return err
+	}
+	if err := ar.reconcileMapping(canary, apexName, primarySpec, false); err != nil {
+		return err
+	}
+
+	canarySpec, err := ar.makeSpec(canary, canaryName)
+	if err != nil {
+		return err
+	}
+
+	if len(canary.GetAnalysis().Match) > 0 {
+		// A/B testing, the header mapping routes to primary until the analysis starts
+		canarySpec.Service = ar.makeService(canary, primaryName)
+		canarySpec.Headers, canarySpec.RegexHeaders = ar.makeHeaders(canary)
+	} else {
+		// Canary, the weight must be set otherwise the mapping shares the traffic with primary
+		weight := 0
+		canarySpec.Weight = &weight
+	}
+
+	return ar.reconcileMapping(canary, fmt.Sprintf("%s-canary", apexName), canarySpec, true)
+}
+
+// GetRoutes returns the canary mapping weight or,
+// for A/B testing, if the canary mapping is routing to the canary service
+func (ar *AmbassadorRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, _, canaryName := canary.GetServiceNames()
+	mappingName := fmt.Sprintf("%s-canary", apexName)
+	mapping, err := ar.ambassadorClient.GetambassadorV2().Mappings(canary.Namespace).Get(mappingName, metav1.GetOptions{})
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("Mapping %s.%s query error %v", mappingName, canary.Namespace, err)
+	}
+
+	// A/B testing
+	if len(canary.GetAnalysis().Match) > 0 {
+		if mapping.Spec.Service == ar.makeService(canary, canaryName) {
+			return 0, 100, false, nil
+		}
+		return 100, 0, false, nil
+	}
+
+	// Canary
+	if mapping.Spec.Weight != nil {
+		canaryWeight = *mapping.Spec.Weight
+	}
+	primaryWeight = 100 - canaryWeight
+	mirrored = false
+	return
+}
+
+// SetRoutes updates the canary mapping weight or,
+// for A/B testing, switches the canary mapping service between the primary and canary services
+func (ar *AmbassadorRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	mappingName := fmt.Sprintf("%s-canary", apexName)
+	mapping, err := ar.ambassadorClient.GetambassadorV2().Mappings(canary.Namespace).Get(mappingName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("Mapping %s.%s query error %v", mappingName, canary.Namespace, err)
+	}
+
+	mClone := mapping.DeepCopy()
+	if len(canary.GetAnalysis().Match) > 0 {
+		// A/B testing
+		mClone.Spec.Service = ar.makeService(canary, primaryName)
+		if canaryWeight > 0 {
+			mClone.Spec.Service = ar.makeService(canary, canaryName)
+		}
+	} else {
+		// Canary
+		mClone.Spec.Weight = &canaryWeight
+	}
+
+	_, err = ar.ambassadorClient.GetambassadorV2().Mappings(canary.Namespace).Update(mClone)
+	if err != nil {
+		return fmt.Errorf("Mapping %s.%s update error %v", mappingName, canary.Namespace, err)
+	}
+
+	return nil
+}
+
+// reconcileMapping creates the mapping or updates its spec,
+// for the canary mapping the current routing is kept
+func (ar *AmbassadorRouter) reconcileMapping(canary *flaggerv1.Canary, name string, newSpec ambassadorv2.MappingSpec, isCanary bool) error {
+	mapping, err := ar.ambassadorClient.GetambassadorV2().Mappings(canary.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		mapping = &ambassadorv2.Mapping{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: newSpec,
+		}
+
+		_, err = ar.ambassadorClient.GetambassadorV2().Mappings(canary.Namespace).Create(mapping)
+		if err != nil {
+			return fmt.Errorf("Mapping %s.%s create error %v", name, canary.Namespace, err)
+		}
+
+		ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Mapping %s.%s created", name, canary.Namespace)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("Mapping %s.%s query error %v", name, canary.Namespace, err)
+	}
+
+	// the canary routing is managed by SetRoutes
+	if isCanary {
+		if newSpec.Weight != nil {
+			if mapping.Spec.Weight != nil {
+				newSpec.Weight = mapping.Spec.Weight
+			}
+		} else if mapping.Spec.Weight == nil {
+			newSpec.Service = mapping.Spec.Service
+		}
+	}
+
+	if diff := cmp.Diff(newSpec, mapping.Spec, cmpopts.EquateEmpty()); diff != "" {
+		mClone := mapping.DeepCopy()
+		mClone.Spec = newSpec
+
+		_, err = ar.ambassadorClient.GetambassadorV2().Mappings(canary.Namespace).Update(mClone)
+		if err != nil {
+			return fmt.Errorf("Mapping %s.%s update error %v", name, canary.Namespace, err)
+		}
+
+		ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Mapping %s.%s updated", name, canary.Namespace)
+	}
+
+	return nil
+}
+
+// makeSpec builds the mapping of the service based on the host, prefix, rewrite and timeout of the canary service spec
+func (ar *AmbassadorRouter) makeSpec(canary *flaggerv1.Canary, serviceName string) (ambassadorv2.MappingSpec, error) {
+	spec := ambassadorv2.MappingSpec{
+		Prefix:  "/",
+		Service: ar.makeService(canary, serviceName),
+	}
+
+	if len(canary.Spec.Service.Match) > 0 &&
+		canary.Spec.Service.Match[0].Uri != nil &&
+		canary.Spec.Service.Match[0].Uri.Prefix != "" {
+		spec.Prefix = canary.Spec.Service.Match[0].Uri.Prefix
+	}
+
+	for _, host := range canary.Spec.Service.Hosts {
+		if host != "*" {
+			spec.Host = host
+			break
+		}
+	}
+
+	if canary.Spec.Service.Rewrite != nil && canary.Spec.Service.Rewrite.Uri != "" {
+		rewrite := canary.Spec.Service.Rewrite.Uri
+		spec.Rewrite = &rewrite
+	}
+
+	if canary.Spec.Service.Timeout != "" {
+		timeout, err := time.ParseDuration(canary.Spec.Service.Timeout)
+		if err != nil {
+			return spec, fmt.Errorf("service timeout %s is invalid: %v", canary.Spec.Service.Timeout, err)
+		}
+		spec.TimeoutMs = timeout.Milliseconds()
+	}
+
+	return spec, nil
+}
+
+// makeHeaders converts the A/B matches into exact and regex header matches
+func (ar *AmbassadorRouter) makeHeaders(canary *flaggerv1.Canary) (map[string]string, map[string]string) {
+	headers := make(map[string]string)
+	regexHeaders := make(map[string]string)
+	for _, m := range canary.GetAnalysis().Match {
+		for k, v := range m.Headers {
+			switch {
+			case v.Exact != "":
+				headers[k] = v.Exact
+			case v.Regex != "":
+				regexHeaders[k] = v.Regex
+			case v.Prefix != "":
+				regexHeaders[k] = fmt.Sprintf("^%s.*", v.Prefix)
+			case v.Suffix != "":
+				regexHeaders[k] = fmt.Sprintf(".*%s$", v.Suffix)
+			}
+		}
+	}
+	return headers, regexHeaders
+}
+
+func (ar *AmbassadorRouter) makeService(canary *flaggerv1.Canary, serviceName string) string {
+	return fmt.Sprintf("%s.%s:%d", serviceName, canary.Namespace, canary.Spec.Service.Port)
+}
@@ -0,0 +1,239 @@
+package router
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+func TestAmbassadorRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &AmbassadorRouter{
+		logger:           mocks.logger,
+		kubeClient:       mocks.kubeClient,
+		ambassadorClient: mocks.meshClient,
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.Hosts = []string{"*", "app.example.com"}
+	canary.Spec.Service.Timeout = "30s"
+	canary.Spec.Service.Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Uri: &istiov1alpha1.StringMatch{
+				Prefix: "/api",
+			},
+		},
+	}
+
+	err := router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	primary, err := router.ambassadorClient.GetambassadorV2().Mappings("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if primary.Spec.Service != "podinfo-primary.default:9898" {
+		t.Errorf("Got service %s wanted %s", primary.Spec.Service, "podinfo-primary.default:9898")
+	}
+
+	if primary.Spec.Prefix != "/api" {
+		t.Errorf("Got prefix %s wanted %s", primary.Spec.Prefix, "/api")
+	}
+
+	if primary.Spec.Host != "app.example.com" {
+		t.Errorf("Got host %s wanted %s", primary.Spec.Host, "app.example.com")
+	}
+
+	if primary.Spec.TimeoutMs != 30000 {
+		t.Errorf("Got timeout %v wanted %v", primary.Spec.TimeoutMs, 30000)
+	}
+
+	if primary.Spec.Weight != nil {
+		t.Errorf("Primary mapping should not have a weight")
+	}
+
+	mCanary, err := router.ambassadorClient.GetambassadorV2().Mappings("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if mCanary.Spec.Service != "podinfo-canary.default:9898" {
+		t.Errorf("Got service %s wanted %s", mCanary.Spec.Service, "podinfo-canary.default:9898")
+	}
+
+	if mCanary.Spec.Weight == nil || *mCanary.Spec.Weight != 0 {
+		t.Errorf("Canary mapping should have weight 0")
+	}
+
+	// test update
+	canary.Spec.Service.Match = nil
+	err = router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mCanary, err = router.ambassadorClient.GetambassadorV2().Mappings("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if mCanary.Spec.Prefix != "/" {
+		t.Errorf("Got prefix %s wanted %s", mCanary.Spec.Prefix, "/")
+	}
+
+	// test invalid timeout
+	canary.Spec.Service.Timeout = "30"
+	err = router.Reconcile(canary)
+	if err == nil {
+		t.Errorf("Expected error for invalid timeout")
+	}
+}
+
+func TestAmbassadorRouter_GetSetRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &AmbassadorRouter{
+		logger:           mocks.logger,
+		kubeClient:       mocks.kubeClient,
+		ambassadorClient: mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if p != 60 {
+		t.Errorf("Got primary weight %v wanted %v", p, 60)
+	}
+
+	if c != 40 {
+		t.Errorf("Got canary weight %v wanted %v", c, 40)
+	}
+
+	if m != false {
+		t.Errorf("Got mirrored %v wanted %v", m, false)
+	}
+
+	// test weight is kept on reconcile
+	err = router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, c, _, err = router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if c != 40 {
+		t.Errorf("Got canary weight %v wanted %v", c, 40)
+	}
+}
+
+func TestAmbassadorRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &AmbassadorRouter{
+		logger:           mocks.logger,
+		kubeClient:       mocks.kubeClient,
+		ambassadorClient: mocks.meshClient,
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.CanaryAnalysis.Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-user-type": {
+					Exact: "test",
+				},
+				"cookie": {
+					Regex: "^(.*?;)?(canary=always)(;.*)?$",
+				},
+			},
+		},
+	}
+
+	err := router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mCanary, err := router.ambassadorClient.GetambassadorV2().Mappings("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if mCanary.Spec.Headers["x-user-type"] != "test" {
+		t.Errorf("Got header %s wanted %s", mCanary.Spec.Headers["x-user-type"], "test")
+	}
+
+	if mCanary.Spec.RegexHeaders["cookie"] != "^(.*?;)?(canary=always)(;.*)?$" {
+		t.Errorf("Got regex header %s wanted %s", mCanary.Spec.RegexHeaders["cookie"], "^(.*?;)?(canary=always)(;.*)?$")
+	}
+
+	if mCanary.Spec.Weight != nil {
+		t.Errorf("Header mapping should not have a weight")
+	}
+
+	if mCanary.Spec.Service != "podinfo-primary.default:9898" {
+		t.Errorf("Got service %s wanted %s", mCanary.Spec.Service, "podinfo-primary.default:9898")
+	}
+
+	err = router.SetRoutes(canary, 0, 100, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, _, err := router.GetRoutes(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if p != 0 || c != 100 {
+		t.Errorf("Got weights %v/%v wanted %v/%v", p, c, 0, 100)
+	}
+
+	// test the service is kept on reconcile
+	err = router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, c, _, err = router.GetRoutes(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if c != 100 {
+		t.Errorf("Got canary weight %v wanted %v", c, 100)
+	}
+
+	err = router.SetRoutes(canary, 100, 0, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, _, err = router.GetRoutes(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if p != 100 || c != 0 {
+		t.Errorf("Got weights %v/%v wanted %v/%v", p, c, 100, 0)
+	}
+}
@@ -0,0 +1,270 @@
+package router
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+
+	apisixv2 "github.com/weaveworks/flagger/pkg/apis/apisix/v2"
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// ApisixRouter is managing Apache APISIX routes
+type ApisixRouter struct {
+	kubeClient   kubernetes.Interface
+	apisixClient clientset.Interface
+	logger       *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the ApisixRoute of the apex service,
+// the default route splits the traffic between the primary and canary backends
+// and for A/B testing a route is added for each match condition
+func (ar *ApisixRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+
+	newSpec := apisixv2.ApisixRouteSpec{
+		HTTP: []apisixv2.ApisixRouteHTTP{
+			{
+				Name:     apexName,
+				Match:    ar.makeMatch(canary),
+				Backends: ar.makeBackends(canary, 100, 0),
+			},
+		},
+	}
+
+	// A/B testing
+	for i, match := range canary.GetAnalysis().Match {
+		route := apisixv2.ApisixRouteHTTP{
+			Name:     fmt.Sprintf("%s-canary-%d", apexName, i),
+			Priority: 1,
+			Match:    ar.makeMatch(canary),
+			Backends: ar.makeBackends(canary, 100, 0),
+		}
+		for name, value := range match.Headers {
+			route.Match.Exprs = append(route.Match.Exprs, ar.makeExpr(name, value))
+		}
+		// sort the expressions to avoid detecting changes on every reconciliation
+		sort.Slice(route.Match.Exprs, func(i, j int) bool {
+			return route.Match.Exprs[i].Subject.Name < route.Match.Exprs[j].Subject.Name
+		})
+		newSpec.HTTP = append(newSpec.HTTP, route)
+	}
+
+	apisixRoute, err := ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		apisixRoute = &apisixv2.ApisixRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apexName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: newSpec,
+		}
+
+		_, err = ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Create(apisixRoute)
+		if err != nil {
+			return fmt.Errorf("ApisixRoute %s.%s create error %v", apexName, canary.Namespace, err)
+		}
+
+		ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ApisixRoute %s.%s created", apexName, canary.Namespace)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("ApisixRoute %s.%s query error %v", apexName, canary.Namespace, err)
+	}
+
+	// keep the weights set by SetRoutes
+	routingName := ar.routingName(canary)
+	for _, current := range apisixRoute.Spec.HTTP {
+		if current.Name != routingName || len(current.Backends) != 2 {
+			continue
+		}
+		for i := range newSpec.HTTP {
+			if ar.isRouting(canary, newSpec.HTTP[i].Name) {
+				newSpec.HTTP[i].Backends = ar.makeBackends(canary, current.Backends[0].Weight, current.Backends[1].Weight)
+			}
+		}
+	}
+
+	if diff := cmp.Diff(newSpec, apisixRoute.Spec, cmpopts.EquateEmpty()); diff != "" {
+		clone := apisixRoute.DeepCopy()
+		clone.Spec = newSpec
+
+		_, err = ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Update(clone)
+		if err != nil {
+			return fmt.Errorf("ApisixRoute %s.%s update error %v", apexName, canary.Namespace, err)
+		}
+
+		ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ApisixRoute %s.%s updated", apexName, canary.Namespace)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the backend weights of the default route or, for A/B testing, of the match routes
+func (ar *ApisixRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, _, _ := canary.GetServiceNames()
+	apisixRoute, err := ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("ApisixRoute %s.%s query error %v", apexName, canary.Namespace, err)
+		return
+	}
+
+	routingName := ar.routingName(canary)
+	for _, route := range apisixRoute.Spec.HTTP {
+		if route.Name == routingName && len(route.Backends) == 2 {
+			primaryWeight = route.Backends[0].Weight
+			canaryWeight = route.Backends[1].Weight
+			return
+		}
+	}
+
+	err = fmt.Errorf("ApisixRoute %s.%s route %s not found", apexName, canary.Namespace, routingName)
+	return
+}
+
+// SetRoutes updates the backend weights of the default route or, for A/B testing, of the match routes
+func (ar *ApisixRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	apexName, _, _ := canary.GetServiceNames()
+	apisixRoute, err := ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ApisixRoute %s.%s query error %v", apexName, canary.Namespace, err)
+	}
+
+	clone := apisixRoute.DeepCopy()
+	for i, route := range clone.Spec.HTTP {
+		if ar.isRouting(canary, route.Name) {
+			clone.Spec.HTTP[i].Backends = ar.makeBackends(canary, primaryWeight, canaryWeight)
+		}
+	}
+
+	_, err = ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Update(clone)
+	if err != nil {
+		return fmt.Errorf("ApisixRoute %s.%s update error %v", apexName, canary.Namespace, err)
+	}
+
+	return nil
+}
+
+// routingName returns the name of the route that splits the traffic
+func (ar *ApisixRouter) routingName(canary *flaggerv1.Canary) string {
+	apexName, _, _ := canary.GetServiceNames()
+	if len(canary.GetAnalysis().Match) > 0 {
+		return fmt.Sprintf("%s-canary-0", apexName)
+	}
+	return apexName
+}
+
+// isRouting returns true if the weights of the route are managed by SetRoutes,
+// for A/B testing the default route keeps sending all the traffic to primary
+func (ar *ApisixRouter) isRouting(canary *flaggerv1.Canary, routeName string) bool {
+	apexName, _, _ := canary.GetServiceNames()
+	if len(canary.GetAnalysis().Match) > 0 {
+		return routeName != apexName
+	}
+	return routeName == apexName
+}
+
+// makeMatch builds the hosts and paths of a route from the canary service spec
+func (ar *ApisixRouter) makeMatch(canary *flaggerv1.Canary) apisixv2.ApisixRouteHTTPMatch {
+	match := apisixv2.ApisixRouteHTTPMatch{
+		Paths: []string{"/*"},
+	}
+
+	if len(canary.Spec.Service.Match) > 0 &&
+		canary.Spec.Service.Match[0].Uri != nil &&
+		canary.Spec.Service.Match[0].Uri.Prefix != "" {
+		match.Paths = []string{strings.TrimSuffix(canary.Spec.Service.Match[0].Uri.Prefix, "*") + "*"}
+	}
+
+	for _, host := range canary.Spec.Service.Hosts {
+		if host != "*" {
+			match.Hosts = append(match.Hosts, host)
+		}
+	}
+
+	return match
+}
+
+func (ar *ApisixRouter) makeBackends(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) []apisixv2.ApisixRouteHTTPBackend {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	return []apisixv2.ApisixRouteHTTPBackend{
+		{
+			ServiceName: primaryName,
+			ServicePort: intstr.FromInt(int(canary.Spec.Service.Port)),
+			Weight:      primaryWeight,
+		},
+		{
+			ServiceName: canaryName,
+			ServicePort: intstr.FromInt(int(canary.Spec.Service.Port)),
+			Weight:      canaryWeight,
+		},
+	}
+}
+
+// makeExpr converts a header match into an APISIX expression,
+// an exact cookie match in the name=value format is matched on the cookie value
+func (ar *ApisixRouter) makeExpr(name string, value istiov1alpha1.StringMatch) apisixv2.ApisixRouteHTTPMatchExpr {
+	expr := apisixv2.ApisixRouteHTTPMatchExpr{
+		Subject: apisixv2.ApisixRouteHTTPMatchExprSubject{
+			Scope: "Header",
+			Name:  name,
+		},
+	}
+
+	switch {
+	case value.Exact != "":
+		expr.Op = "Equal"
+		expr.Value = value.Exact
+		if strings.EqualFold(name, "cookie") && strings.Contains(value.Exact, "=") {
+			parts := strings.SplitN(value.Exact, "=", 2)
+			expr.Subject = apisixv2.ApisixRouteHTTPMatchExprSubject{
+				Scope: "Cookie",
+				Name:  parts[0],
+			}
+			expr.Value = parts[1]
+		}
+	case value.Regex != "":
+		expr.Op = "RegexMatch"
+		expr.Value = value.Regex
+	case value.Prefix != "":
+		expr.Op = "RegexMatch"
+		expr.Value = "^" + regexp.QuoteMeta(value.Prefix)
+	case value.Suffix != "":
+		expr.Op = "RegexMatch"
+		expr.Value = regexp.QuoteMeta(value.Suffix) + "$"
+	}
+
+	return expr
+}
@@ -0,0 +1,242 @@
+package router
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+func TestApisixRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ApisixRouter{
+		logger:       mocks.logger,
+		kubeClient:   mocks.kubeClient,
+		apisixClient: mocks.meshClient,
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.Hosts = []string{"*", "app.example.com"}
+	canary.Spec.Service.Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Uri: &istiov1alpha1.StringMatch{
+				Prefix: "/api",
+			},
+		},
+	}
+
+	err := router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ar, err := router.apisixClient.ApisixV2().ApisixRoutes("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(ar.Spec.HTTP) != 1 {
+		t.Fatalf("Got %v routes wanted %v", len(ar.Spec.HTTP), 1)
+	}
+
+	route := ar.Spec.HTTP[0]
+	if route.Match.Paths[0] != "/api*" {
+		t.Errorf("Got path %s wanted %s", route.Match.Paths[0], "/api*")
+	}
+
+	if len(route.Match.Hosts) != 1 || route.Match.Hosts[0] != "app.example.com" {
+		t.Errorf("Got hosts %v wanted %v", route.Match.Hosts, []string{"app.example.com"})
+	}
+
+	if route.Backends[0].ServiceName != "podinfo-primary" || route.Backends[0].Weight != 100 {
+		t.Errorf("Got primary backend %s weight %v wanted %s weight %v",
+			route.Backends[0].ServiceName, route.Backends[0].Weight, "podinfo-primary", 100)
+	}
+
+	if route.Backends[1].ServiceName != "podinfo-canary" || route.Backends[1].Weight != 0 {
+		t.Errorf("Got canary backend %s weight %v wanted %s weight %v",
+			route.Backends[1].ServiceName, route.Backends[1].Weight, "podinfo-canary", 0)
+	}
+
+	if route.Backends[1].ServicePort.IntValue() != 9898 {
+		t.Errorf("Got port %v wanted %v", route.Backends[1].ServicePort.IntValue(), 9898)
+	}
+
+	// test update
+	canary.Spec.Service.Match = nil
+	err = router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ar, err = router.apisixClient.ApisixV2().ApisixRoutes("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if ar.Spec.HTTP[0].Match.Paths[0] != "/*" {
+		t.Errorf("Got path %s wanted %s", ar.Spec.HTTP[0].Match.Paths[0], "/*")
+	}
+}
+
+func TestApisixRouter_GetSetRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ApisixRouter{
+		logger:       mocks.logger,
+		kubeClient:   mocks.kubeClient,
+		apisixClient: mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if p != 60 {
+		t.Errorf("Got primary weight %v wanted %v", p, 60)
+	}
+
+	if c != 40 {
+		t.Errorf("Got canary weight %v wanted %v", c, 40)
+	}
+
+	if m != false {
+		t.Errorf("Got mirrored %v wanted %v", m, false)
+	}
+
+	// test weights are kept on reconcile
+	err = router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, c, _, err = router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if c != 40 {
+		t.Errorf("Got canary weight %v wanted %v", c, 40)
+	}
+}
+
+func TestApisixRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ApisixRouter{
+		logger:       mocks.logger,
+		kubeClient:   mocks.kubeClient,
+		apisixClient: mocks.meshClient,
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.CanaryAnalysis.Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-user-type": {
+					Exact: "test",
+				},
+			},
+		},
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"cookie": {
+					Exact: "canary=always",
+				},
+				"user-agent": {
+					Prefix: "Mozilla/5.0",
+				},
+			},
+		},
+	}
+
+	err := router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ar, err := router.apisixClient.ApisixV2().ApisixRoutes("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(ar.Spec.HTTP) != 3 {
+		t.Fatalf("Got %v routes wanted %v", len(ar.Spec.HTTP), 3)
+	}
+
+	header := ar.Spec.HTTP[1]
+	if header.Name != "podinfo-canary-0" || header.Priority != 1 {
+		t.Errorf("Got route %s priority %v wanted %s priority %v", header.Name, header.Priority, "podinfo-canary-0", 1)
+	}
+
+	expr := header.Match.Exprs[0]
+	if expr.Subject.Scope != "Header" || expr.Subject.Name != "x-user-type" || expr.Op != "Equal" || expr.Value != "test" {
+		t.Errorf("Got expression %+v", expr)
+	}
+
+	exprs := ar.Spec.HTTP[2].Match.Exprs
+	if len(exprs) != 2 {
+		t.Fatalf("Got %v expressions wanted %v", len(exprs), 2)
+	}
+
+	if exprs[0].Subject.Scope != "Cookie" || exprs[0].Subject.Name != "canary" || exprs[0].Value != "always" {
+		t.Errorf("Got cookie expression %+v", exprs[0])
+	}
+
+	if exprs[1].Op != "RegexMatch" || exprs[1].Value != `^Mozilla/5\.0` {
+		t.Errorf("Got user-agent expression %+v", exprs[1])
+	}
+
+	err = router.SetRoutes(canary, 0, 100, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, _, err := router.GetRoutes(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if p != 0 || c != 100 {
+		t.Errorf("Got weights %v/%v wanted %v/%v", p, c, 0, 100)
+	}
+
+	ar, err = router.apisixClient.ApisixV2().ApisixRoutes("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if ar.Spec.HTTP[0].Backends[0].Weight != 100 {
+		t.Errorf("The default route should send all the traffic to primary")
+	}
+
+	if ar.Spec.HTTP[2].Backends[1].Weight != 100 {
+		t.Errorf("All the match routes should send the traffic to canary")
+	}
+
+	// test the weights are kept on reconcile
+	err = router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, c, _, err = router.GetRoutes(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if c != 100 {
+		t.Errorf("Got canary weight %v wanted %v", c, 100)
+	}
+}
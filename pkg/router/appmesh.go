@@ -73,6 +73,14 @@ func (ar *AppMeshRouter) Reconcile(canary *flaggerv1.Canary) error {
 		return err
 	}
 
+	// sync gateway routes e.g. app-gateway
+	for _, gateway := range canary.Spec.Service.Gateways {
+		err = ar.reconcileGatewayRoute(canary, gateway)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -161,93 +169,12 @@ func (ar *AppMeshRouter) reconcileVirtualNode(canary *flaggerv1.Canary, name str
 // reconcileVirtualService creates or updates a virtual service
 func (ar *AppMeshRouter) reconcileVirtualService(canary *flaggerv1.Canary, name string, canaryWeight int64) error {
 	apexName, _, _ := canary.GetServiceNames()
-	canaryVirtualNode := fmt.Sprintf("%s-canary", apexName)
-	primaryVirtualNode := fmt.Sprintf("%s-primary", apexName)
 	protocol := ar.getProtocol(canary)
 
 	routerName := apexName
 	if canaryWeight > 0 {
 		routerName = fmt.Sprintf("%s-canary", apexName)
 	}
-	// App Mesh supports only URI prefix
-	routePrefix := "/"
-	if len(canary.Spec.Service.Match) > 0 &&
-		canary.Spec.Service.Match[0].Uri != nil &&
-		canary.Spec.Service.Match[0].Uri.Prefix != "" {
-		routePrefix = canary.Spec.Service.Match[0].Uri.Prefix
-	}
-
-	// Canary progressive traffic shift
-	routes := []appmeshv1.Route{
-		{
-			Name: routerName,
-			Http: &appmeshv1.HttpRoute{
-				Match: appmeshv1.HttpRouteMatch{
-					Prefix: routePrefix,
-				},
-				RetryPolicy: makeRetryPolicy(canary),
-				Action: appmeshv1.HttpRouteAction{
-					WeightedTargets: []appmeshv1.WeightedTarget{
-						{
-							VirtualNodeName: canaryVirtualNode,
-							Weight:          canaryWeight,
-						},
-						{
-							VirtualNodeName: primaryVirtualNode,
-							Weight:          100 - canaryWeight,
-						},
-					},
-				},
-			},
-		},
-	}
-
-	// A/B testing - header based routing
-	if len(canary.GetAnalysis().Match) > 0 && canaryWeight == 0 {
-		routes = []appmeshv1.Route{
-			{
-				Name:     fmt.Sprintf("%s-a", apexName),
-				Priority: int64p(10),
-				Http: &appmeshv1.HttpRoute{
-					Match: appmeshv1.HttpRouteMatch{
-						Prefix:  routePrefix,
-						Headers: ar.makeHeaders(canary),
-					},
-					RetryPolicy: makeRetryPolicy(canary),
-					Action: appmeshv1.HttpRouteAction{
-						WeightedTargets: []appmeshv1.WeightedTarget{
-							{
-								VirtualNodeName: canaryVirtualNode,
-								Weight:          canaryWeight,
-							},
-							{
-								VirtualNodeName: primaryVirtualNode,
-								Weight:          100 - canaryWeight,
-							},
-						},
-					},
-				},
-			},
-			{
-				Name:     fmt.Sprintf("%s-b", apexName),
-				Priority: int64p(20),
-				Http: &appmeshv1.HttpRoute{
-					Match: appmeshv1.HttpRouteMatch{
-						Prefix: routePrefix,
-					},
-					RetryPolicy: makeRetryPolicy(canary),
-					Action: appmeshv1.HttpRouteAction{
-						WeightedTargets: []appmeshv1.WeightedTarget{
-							{
-								VirtualNodeName: primaryVirtualNode,
-								Weight:          100,
-							},
-						},
-					},
-				},
-			},
-		}
-	}
 
 	vsSpec := appmeshv1.VirtualServiceSpec{
 		MeshName: canary.Spec.Service.MeshName,
@@ -262,7 +189,7 @@ func (ar *AppMeshRouter) reconcileVirtualService(canary *flaggerv1.Canary, name
 				},
 			},
 		},
-		Routes: routes,
+		Routes: ar.makeRoutes(canary, routerName, canaryWeight),
 	}
 
 	virtualService, err := ar.appmeshClient.AppmeshV1beta1().VirtualServices(canary.Namespace).Get(name, metav1.GetOptions{})
@@ -332,6 +259,158 @@ func (ar *AppMeshRouter) reconcileVirtualService(canary *flaggerv1.Canary, name
 	return nil
 }
 
+// reconcileGatewayRoute creates or updates the gateway route of a virtual gateway,
+// the gateway route naming format is name-gateway
+func (ar *AppMeshRouter) reconcileGatewayRoute(canary *flaggerv1.Canary, gateway string) error {
+	apexName, _, _ := canary.GetServiceNames()
+	name := fmt.Sprintf("%s-%s", apexName, gateway)
+
+	grSpec := appmeshv1.GatewayRouteSpec{
+		MeshName:           canary.Spec.Service.MeshName,
+		VirtualGatewayName: gateway,
+		Routes:             ar.makeRoutes(canary, apexName, 0),
+	}
+
+	gatewayRoute, err := ar.appmeshClient.AppmeshV1beta1().GatewayRoutes(canary.Namespace).Get(name, metav1.GetOptions{})
+
+	// create gateway route
+	if errors.IsNotFound(err) {
+		gatewayRoute = &appmeshv1.GatewayRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: grSpec,
+		}
+
+		_, err = ar.appmeshClient.AppmeshV1beta1().GatewayRoutes(canary.Namespace).Create(gatewayRoute)
+		if err != nil {
+			return fmt.Errorf("GatewayRoute %s create error %v", name, err)
+		}
+		ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("GatewayRoute %s created", gatewayRoute.GetName())
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("GatewayRoute %s query error %v", name, err)
+	}
+
+	// update gateway route but keep the original target weights
+	if diff := cmp.Diff(grSpec, gatewayRoute.Spec, cmpopts.IgnoreTypes(appmeshv1.WeightedTarget{})); diff != "" {
+		grClone := gatewayRoute.DeepCopy()
+		grClone.Spec = grSpec
+		if len(gatewayRoute.Spec.Routes) > 0 && gatewayRoute.Spec.Routes[0].Http != nil {
+			grClone.Spec.Routes[0].Http.Action = gatewayRoute.Spec.Routes[0].Http.Action
+		}
+
+		_, err = ar.appmeshClient.AppmeshV1beta1().GatewayRoutes(canary.Namespace).Update(grClone)
+		if err != nil {
+			return fmt.Errorf("GatewayRoute %s update error %v", name, err)
+		}
+		ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("GatewayRoute %s updated", gatewayRoute.GetName())
+	}
+
+	return nil
+}
+
+// makeRoutes returns the weighted routes of the virtual router and of the gateway routes,
+// for A/B testing the first route matches the headers and the second one targets the primary
+func (ar *AppMeshRouter) makeRoutes(canary *flaggerv1.Canary, routeName string, canaryWeight int64) []appmeshv1.Route {
+	apexName, _, _ := canary.GetServiceNames()
+	canaryVirtualNode := fmt.Sprintf("%s-canary", apexName)
+	primaryVirtualNode := fmt.Sprintf("%s-primary", apexName)
+	// App Mesh supports only URI prefix
+	routePrefix := "/"
+	if len(canary.Spec.Service.Match) > 0 &&
+		canary.Spec.Service.Match[0].Uri != nil &&
+		canary.Spec.Service.Match[0].Uri.Prefix != "" {
+		routePrefix = canary.Spec.Service.Match[0].Uri.Prefix
+	}
+
+	// Canary progressive traffic shift
+	routes := []appmeshv1.Route{
+		{
+			Name: routeName,
+			Http: &appmeshv1.HttpRoute{
+				Match: appmeshv1.HttpRouteMatch{
+					Prefix: routePrefix,
+				},
+				RetryPolicy: makeRetryPolicy(canary),
+				Action: appmeshv1.HttpRouteAction{
+					WeightedTargets: []appmeshv1.WeightedTarget{
+						{
+							VirtualNodeName: canaryVirtualNode,
+							Weight:          canaryWeight,
+						},
+						{
+							VirtualNodeName: primaryVirtualNode,
+							Weight:          100 - canaryWeight,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// A/B testing - header based routing
+	if len(canary.GetAnalysis().Match) > 0 && canaryWeight == 0 {
+		routes = []appmeshv1.Route{
+			{
+				Name:     fmt.Sprintf("%s-a", apexName),
+				Priority: int64p(10),
+				Http: &appmeshv1.HttpRoute{
+					Match: appmeshv1.HttpRouteMatch{
+						Prefix:  routePrefix,
+						Headers: ar.makeHeaders(canary),
+					},
+					RetryPolicy: makeRetryPolicy(canary),
+					Action: appmeshv1.HttpRouteAction{
+						WeightedTargets: []appmeshv1.WeightedTarget{
+							{
+								VirtualNodeName: canaryVirtualNode,
+								Weight:          canaryWeight,
+							},
+							{
+								VirtualNodeName: primaryVirtualNode,
+								Weight:          100 - canaryWeight,
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:     fmt.Sprintf("%s-b", apexName),
+				Priority: int64p(20),
+				Http: &appmeshv1.HttpRoute{
+					Match: appmeshv1.HttpRouteMatch{
+						Prefix: routePrefix,
+					},
+					RetryPolicy: makeRetryPolicy(canary),
+					Action: appmeshv1.HttpRouteAction{
+						WeightedTargets: []appmeshv1.WeightedTarget{
+							{
+								VirtualNodeName: primaryVirtualNode,
+								Weight:          100,
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return routes
+}
+
 // GetRoutes returns the destinations weight for primary and canary
 func (ar *AppMeshRouter) GetRoutes(canary *flaggerv1.Canary) (
 	primaryWeight int,
@@ -393,8 +472,7 @@ func (ar *AppMeshRouter) SetRoutes(
 		return fmt.Errorf("VirtualService %s query error %v", vsName, err)
 	}
 
-	vsClone := vs.DeepCopy()
-	vsClone.Spec.Routes[0].Http.Action = appmeshv1.HttpRouteAction{
+	action := appmeshv1.HttpRouteAction{
 		WeightedTargets: []appmeshv1.WeightedTarget{
 			{
 				VirtualNodeName: fmt.Sprintf("%s-canary", apexName),
@@ -407,11 +485,37 @@ func (ar *AppMeshRouter) SetRoutes(
 		},
 	}
 
+	vsClone := vs.DeepCopy()
+	vsClone.Spec.Routes[0].Http.Action = action
+
 	_, err = ar.appmeshClient.AppmeshV1beta1().VirtualServices(canary.Namespace).Update(vsClone)
 	if err != nil {
 		return fmt.Errorf("VirtualService %s update error %v", vsName, err)
 	}
 
+	// apply the same weights to the edge traffic of the virtual gateways
+	for _, gateway := range canary.Spec.Service.Gateways {
+		grName := fmt.Sprintf("%s-%s", apexName, gateway)
+		gr, err := ar.appmeshClient.AppmeshV1beta1().GatewayRoutes(canary.Namespace).Get(grName, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Errorf("GatewayRoute %s not found", grName)
+			}
+			return fmt.Errorf("GatewayRoute %s query error %v", grName, err)
+		}
+		if len(gr.Spec.Routes) < 1 || gr.Spec.Routes[0].Http == nil {
+			return fmt.Errorf("GatewayRoute %s routes not found", grName)
+		}
+
+		grClone := gr.DeepCopy()
+		grClone.Spec.Routes[0].Http.Action = action
+
+		_, err = ar.appmeshClient.AppmeshV1beta1().GatewayRoutes(canary.Namespace).Update(grClone)
+		if err != nil {
+			return fmt.Errorf("GatewayRoute %s update error %v", grName, err)
+		}
+	}
+
 	return nil
 }
 
@@ -270,3 +270,65 @@ func TestAppmeshRouter_Gateway(t *testing.T) {
 		t.Errorf("Got gateway retries annotation %v wanted %v", retries, strconv.Itoa(mocks.appmeshCanary.Spec.Service.Retries.Attempts))
 	}
 }
+
+func TestAppmeshRouter_GatewayRoute(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &AppMeshRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		appmeshClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	canary := mocks.appmeshCanary.DeepCopy()
+	canary.Spec.Service.Gateways = []string{"ingress-gw"}
+
+	err := router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	grName := fmt.Sprintf("%s-%s", canary.Spec.TargetRef.Name, "ingress-gw")
+	gr, err := router.appmeshClient.AppmeshV1beta1().GatewayRoutes("default").Get(grName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if gr.Spec.VirtualGatewayName != "ingress-gw" {
+		t.Errorf("Got virtual gateway %v wanted %v", gr.Spec.VirtualGatewayName, "ingress-gw")
+	}
+
+	err = router.SetRoutes(canary, 60, 40, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	gr, err = router.appmeshClient.AppmeshV1beta1().GatewayRoutes("default").Get(grName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	canaryName := fmt.Sprintf("%s-canary", canary.Spec.TargetRef.Name)
+	for _, target := range gr.Spec.Routes[0].Http.Action.WeightedTargets {
+		if target.VirtualNodeName == canaryName && target.Weight != 40 {
+			t.Errorf("Got gateway route canary weight %v wanted %v", target.Weight, 40)
+		}
+	}
+
+	// reconcile should keep the gateway route weights
+	err = router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	gr, err = router.appmeshClient.AppmeshV1beta1().GatewayRoutes("default").Get(grName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, target := range gr.Spec.Routes[0].Http.Action.WeightedTargets {
+		if target.VirtualNodeName == canaryName && target.Weight != 40 {
+			t.Errorf("Got gateway route canary weight %v wanted %v after reconcile", target.Weight, 40)
+		}
+	}
+}
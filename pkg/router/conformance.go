@@ -0,0 +1,135 @@
+package router
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+// ConformanceHeader is the header matched by the A/B testing conformance check
+// when the canary analysis has no match conditions
+const ConformanceHeader = "x-flagger-conformance"
+
+// ConformanceResult is the outcome of a router conformance check
+type ConformanceResult struct {
+	Check   string
+	Passed  bool
+	Skipped bool
+	Message string
+}
+
+// RunConformance exercises the router against the canary and reports if it
+// reconciles idempotently, sets and reads back weights, mirrors traffic, routes
+// A/B tests and finalizes the routes on primary.
+// The checks are run in order, the routes are left on primary when they pass.
+func RunConformance(router Interface, canary *flaggerv1.Canary) []ConformanceResult {
+	results := make([]ConformanceResult, 0)
+	failed := false
+	run := func(check string, fn func() (bool, error)) {
+		if failed {
+			results = append(results, ConformanceResult{Check: check, Skipped: true, Message: "previous check failed"})
+			return
+		}
+		supported, err := fn()
+		switch {
+		case err != nil:
+			failed = true
+			results = append(results, ConformanceResult{Check: check, Message: err.Error()})
+		case !supported:
+			results = append(results, ConformanceResult{Check: check, Skipped: true, Message: "not supported by the router"})
+		default:
+			results = append(results, ConformanceResult{Check: check, Passed: true})
+		}
+	}
+
+	run("reconcile", func() (bool, error) {
+		if canary.GetAnalysis() == nil {
+			return true, fmt.Errorf("canary %s.%s has no analysis", canary.Name, canary.Namespace)
+		}
+		for i := 0; i < 2; i++ {
+			if err := router.Reconcile(canary); err != nil {
+				return true, fmt.Errorf("reconcile error %v", err)
+			}
+		}
+		return true, expectRoutes(router, canary, 100, 0, false)
+	})
+
+	run("weights", func() (bool, error) {
+		for _, weight := range []int{10, 50, 100, 0} {
+			if err := router.SetRoutes(canary, 100-weight, weight, false); err != nil {
+				return true, fmt.Errorf("set routes error %v", err)
+			}
+			if err := expectRoutes(router, canary, 100-weight, weight, false); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	})
+
+	run("mirror", func() (bool, error) {
+		if err := router.SetRoutes(canary, 100, 0, true); err != nil {
+			return true, fmt.Errorf("set routes error %v", err)
+		}
+		_, _, mirrored, err := router.GetRoutes(canary)
+		if err != nil {
+			return true, fmt.Errorf("get routes error %v", err)
+		}
+		if err := router.SetRoutes(canary, 100, 0, false); err != nil {
+			return true, fmt.Errorf("set routes error %v", err)
+		}
+		return mirrored, nil
+	})
+
+	run("ab-testing", func() (bool, error) {
+		abtest := canary.DeepCopy()
+		if len(abtest.GetAnalysis().Match) == 0 {
+			abtest.GetAnalysis().Match = []istiov1alpha3.HTTPMatchRequest{
+				{
+					Headers: map[string]istiov1alpha1.StringMatch{
+						ConformanceHeader: {
+							Exact: "true",
+						},
+					},
+				},
+			}
+		}
+		if err := router.Reconcile(abtest); err != nil {
+			return true, fmt.Errorf("reconcile error %v", err)
+		}
+		for _, weight := range []int{100, 0} {
+			if err := router.SetRoutes(abtest, 100-weight, weight, false); err != nil {
+				return true, fmt.Errorf("set routes error %v", err)
+			}
+			if err := expectRoutes(router, abtest, 100-weight, weight, false); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	})
+
+	run("finalize", func() (bool, error) {
+		if err := router.Reconcile(canary); err != nil {
+			return true, fmt.Errorf("reconcile error %v", err)
+		}
+		if err := router.SetRoutes(canary, 100, 0, false); err != nil {
+			return true, fmt.Errorf("set routes error %v", err)
+		}
+		return true, expectRoutes(router, canary, 100, 0, false)
+	})
+
+	return results
+}
+
+func expectRoutes(router Interface, canary *flaggerv1.Canary, primaryWeight int, canaryWeight int, mirrored bool) error {
+	p, c, m, err := router.GetRoutes(canary)
+	if err != nil {
+		return fmt.Errorf("get routes error %v", err)
+	}
+	if p != primaryWeight || c != canaryWeight || m != mirrored {
+		return fmt.Errorf("got routes primary=%v canary=%v mirrored=%v wanted primary=%v canary=%v mirrored=%v",
+			p, c, m, primaryWeight, canaryWeight, mirrored)
+	}
+	return nil
+}
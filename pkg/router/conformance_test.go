@@ -0,0 +1,80 @@
+package router
+
+import (
+	"testing"
+)
+
+func TestRunConformance_Istio(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	results := RunConformance(router, mocks.canary)
+
+	if len(results) != 5 {
+		t.Fatalf("Got %v results wanted %v", len(results), 5)
+	}
+
+	for _, result := range results {
+		if !result.Passed {
+			t.Errorf("Check %s failed: %s", result.Check, result.Message)
+		}
+	}
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if p != 100 || c != 0 || m {
+		t.Errorf("Got routes %v/%v/%v wanted %v/%v/%v", p, c, m, 100, 0, false)
+	}
+}
+
+func TestRunConformance_SkipMirror(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &AmbassadorRouter{
+		logger:           mocks.logger,
+		kubeClient:       mocks.kubeClient,
+		ambassadorClient: mocks.meshClient,
+	}
+
+	results := RunConformance(router, mocks.canary)
+
+	for _, result := range results {
+		if result.Check == "mirror" {
+			if !result.Skipped {
+				t.Errorf("Check mirror should be skipped")
+			}
+			continue
+		}
+		if !result.Passed {
+			t.Errorf("Check %s failed: %s", result.Check, result.Message)
+		}
+	}
+}
+
+func TestRunConformance_Failed(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &NopRouter{}
+
+	results := RunConformance(router, mocks.canary)
+
+	if !results[0].Passed {
+		t.Errorf("Check %s failed: %s", results[0].Check, results[0].Message)
+	}
+
+	if results[1].Passed || results[1].Message == "" {
+		t.Errorf("Check %s should fail", results[1].Check)
+	}
+
+	for _, result := range results[2:] {
+		if !result.Skipped {
+			t.Errorf("Check %s should be skipped", result.Check)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -12,6 +13,8 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
 	contourv1 "github.com/weaveworks/flagger/pkg/apis/projectcontour/v1"
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 )
@@ -26,107 +29,10 @@ type ContourRouter struct {
 
 // Reconcile creates or updates the HTTP proxy
 func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames()
 
 	newSpec := contourv1.HTTPProxySpec{
-		Routes: []contourv1.Route{
-			{
-				Conditions: []contourv1.Condition{
-					{
-						Prefix: cr.makePrefix(canary),
-					},
-				},
-				TimeoutPolicy: cr.makeTimeoutPolicy(canary),
-				RetryPolicy:   cr.makeRetryPolicy(canary),
-				Services: []contourv1.Service{
-					{
-						Name:   primaryName,
-						Port:   int(canary.Spec.Service.Port),
-						Weight: uint32(100),
-						RequestHeadersPolicy: &contourv1.HeadersPolicy{
-							Set: []contourv1.HeaderValue{
-								cr.makeLinkerdHeaderValue(canary, primaryName),
-							},
-						},
-					},
-					{
-						Name:   canaryName,
-						Port:   int(canary.Spec.Service.Port),
-						Weight: uint32(0),
-						RequestHeadersPolicy: &contourv1.HeadersPolicy{
-							Set: []contourv1.HeaderValue{
-								cr.makeLinkerdHeaderValue(canary, canaryName),
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-
-	if len(canary.GetAnalysis().Match) > 0 {
-		newSpec = contourv1.HTTPProxySpec{
-			Routes: []contourv1.Route{
-				{
-					Conditions:    cr.makeConditions(canary),
-					TimeoutPolicy: cr.makeTimeoutPolicy(canary),
-					RetryPolicy:   cr.makeRetryPolicy(canary),
-					Services: []contourv1.Service{
-						{
-							Name:   primaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(100),
-							RequestHeadersPolicy: &contourv1.HeadersPolicy{
-								Set: []contourv1.HeaderValue{
-									cr.makeLinkerdHeaderValue(canary, primaryName),
-								},
-							},
-						},
-						{
-							Name:   canaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(0),
-							RequestHeadersPolicy: &contourv1.HeadersPolicy{
-								Set: []contourv1.HeaderValue{
-									cr.makeLinkerdHeaderValue(canary, canaryName),
-								},
-							},
-						},
-					},
-				},
-				{
-					Conditions: []contourv1.Condition{
-						{
-							Prefix: cr.makePrefix(canary),
-						},
-					},
-					TimeoutPolicy: cr.makeTimeoutPolicy(canary),
-					RetryPolicy:   cr.makeRetryPolicy(canary),
-					Services: []contourv1.Service{
-						{
-							Name:   primaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(100),
-							RequestHeadersPolicy: &contourv1.HeadersPolicy{
-								Set: []contourv1.HeaderValue{
-									cr.makeLinkerdHeaderValue(canary, primaryName),
-								},
-							},
-						},
-						{
-							Name:   canaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(0),
-							RequestHeadersPolicy: &contourv1.HeadersPolicy{
-								Set: []contourv1.HeaderValue{
-									cr.makeLinkerdHeaderValue(canary, canaryName),
-								},
-							},
-						},
-					},
-				},
-			},
-		}
+		Routes: cr.makeRoutes(canary, 100, 0),
 	}
 
 	proxy, err := cr.contourClient.ProjectcontourV1().HTTPProxies(canary.Namespace).Get(apexName, metav1.GetOptions{})
@@ -227,7 +133,7 @@ func (cr *ContourRouter) SetRoutes(
 	canaryWeight int,
 	mirrored bool,
 ) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames()
 
 	if primaryWeight == 0 && canaryWeight == 0 {
 		return fmt.Errorf("HTTPProxy %s.%s update failed: no valid weights", apexName, canary.Namespace)
@@ -243,103 +149,7 @@ func (cr *ContourRouter) SetRoutes(
 	}
 
 	proxy.Spec = contourv1.HTTPProxySpec{
-		Routes: []contourv1.Route{
-			{
-				Conditions: []contourv1.Condition{
-					{
-						Prefix: cr.makePrefix(canary),
-					},
-				},
-				TimeoutPolicy: cr.makeTimeoutPolicy(canary),
-				RetryPolicy:   cr.makeRetryPolicy(canary),
-				Services: []contourv1.Service{
-					{
-						Name:   primaryName,
-						Port:   int(canary.Spec.Service.Port),
-						Weight: uint32(primaryWeight),
-						RequestHeadersPolicy: &contourv1.HeadersPolicy{
-							Set: []contourv1.HeaderValue{
-								cr.makeLinkerdHeaderValue(canary, primaryName),
-							},
-						},
-					},
-					{
-						Name:   canaryName,
-						Port:   int(canary.Spec.Service.Port),
-						Weight: uint32(canaryWeight),
-						RequestHeadersPolicy: &contourv1.HeadersPolicy{
-							Set: []contourv1.HeaderValue{
-								cr.makeLinkerdHeaderValue(canary, canaryName),
-							},
-						},
-					},
-				}},
-		},
-	}
-
-	if len(canary.GetAnalysis().Match) > 0 {
-		proxy.Spec = contourv1.HTTPProxySpec{
-			Routes: []contourv1.Route{
-				{
-					Conditions:    cr.makeConditions(canary),
-					TimeoutPolicy: cr.makeTimeoutPolicy(canary),
-					RetryPolicy:   cr.makeRetryPolicy(canary),
-					Services: []contourv1.Service{
-						{
-							Name:   primaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(primaryWeight),
-							RequestHeadersPolicy: &contourv1.HeadersPolicy{
-								Set: []contourv1.HeaderValue{
-									cr.makeLinkerdHeaderValue(canary, primaryName),
-								},
-							},
-						},
-						{
-							Name:   canaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(canaryWeight),
-							RequestHeadersPolicy: &contourv1.HeadersPolicy{
-								Set: []contourv1.HeaderValue{
-									cr.makeLinkerdHeaderValue(canary, canaryName),
-								},
-							},
-						},
-					},
-				},
-				{
-					Conditions: []contourv1.Condition{
-						{
-							Prefix: cr.makePrefix(canary),
-						},
-					},
-					TimeoutPolicy: cr.makeTimeoutPolicy(canary),
-					RetryPolicy:   cr.makeRetryPolicy(canary),
-					Services: []contourv1.Service{
-						{
-							Name:   primaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(100),
-							RequestHeadersPolicy: &contourv1.HeadersPolicy{
-								Set: []contourv1.HeaderValue{
-									cr.makeLinkerdHeaderValue(canary, primaryName),
-								},
-							},
-						},
-						{
-							Name:   canaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(0),
-							RequestHeadersPolicy: &contourv1.HeadersPolicy{
-								Set: []contourv1.HeaderValue{
-									cr.makeLinkerdHeaderValue(canary, canaryName),
-								},
-							},
-						},
-					},
-				},
-			},
-		}
+		Routes: cr.makeRoutes(canary, primaryWeight, canaryWeight),
 	}
 
 	_, err = cr.contourClient.ProjectcontourV1().HTTPProxies(canary.Namespace).Update(proxy)
@@ -361,45 +171,113 @@ func (cr *ContourRouter) makePrefix(canary *flaggerv1.Canary) string {
 	return prefix
 }
 
-func (cr *ContourRouter) makeConditions(canary *flaggerv1.Canary) []contourv1.Condition {
+// makeRoutes returns the weighted route, or for A/B testing one route per match condition
+// with the given weights followed by the default route to primary
+func (cr *ContourRouter) makeRoutes(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) []contourv1.Route {
+	if len(canary.GetAnalysis().Match) == 0 {
+		return []contourv1.Route{
+			cr.makeRoute(canary, []contourv1.Condition{{Prefix: cr.makePrefix(canary)}}, primaryWeight, canaryWeight),
+		}
+	}
+
+	var routes []contourv1.Route
+	for _, match := range canary.GetAnalysis().Match {
+		routes = append(routes, cr.makeRoute(canary, cr.makeConditions(canary, match), primaryWeight, canaryWeight))
+	}
+	return append(routes, cr.makeRoute(canary, []contourv1.Condition{{Prefix: cr.makePrefix(canary)}}, 100, 0))
+}
+
+func (cr *ContourRouter) makeRoute(canary *flaggerv1.Canary, conditions []contourv1.Condition, primaryWeight int, canaryWeight int) contourv1.Route {
+	_, primaryName, canaryName := canary.GetServiceNames()
+
+	return contourv1.Route{
+		Conditions:    conditions,
+		TimeoutPolicy: cr.makeTimeoutPolicy(canary),
+		RetryPolicy:   cr.makeRetryPolicy(canary),
+		Services: []contourv1.Service{
+			{
+				Name:   primaryName,
+				Port:   int(canary.Spec.Service.Port),
+				Weight: uint32(primaryWeight),
+				RequestHeadersPolicy: &contourv1.HeadersPolicy{
+					Set: []contourv1.HeaderValue{
+						cr.makeLinkerdHeaderValue(canary, primaryName),
+					},
+				},
+			},
+			{
+				Name:   canaryName,
+				Port:   int(canary.Spec.Service.Port),
+				Weight: uint32(canaryWeight),
+				RequestHeadersPolicy: &contourv1.HeadersPolicy{
+					Set: []contourv1.HeaderValue{
+						cr.makeLinkerdHeaderValue(canary, canaryName),
+					},
+				},
+			},
+		},
+	}
+}
+
+// makeConditions renders the headers and query parameters of a match as route conditions,
+// Contour allows a single prefix per route so it is set on the first condition only
+func (cr *ContourRouter) makeConditions(canary *flaggerv1.Canary, match istiov1alpha3.HTTPMatchRequest) []contourv1.Condition {
 	list := []contourv1.Condition{}
 
-	if len(canary.GetAnalysis().Match) > 0 {
-		for _, match := range canary.GetAnalysis().Match {
-			for s, stringMatch := range match.Headers {
-				h := &contourv1.HeaderCondition{
-					Name:  s,
-					Exact: stringMatch.Exact,
-				}
-				if stringMatch.Suffix != "" {
-					h = &contourv1.HeaderCondition{
-						Name:     s,
-						Contains: stringMatch.Suffix,
-					}
-				}
-				if stringMatch.Prefix != "" {
-					h = &contourv1.HeaderCondition{
-						Name:     s,
-						Contains: stringMatch.Prefix,
-					}
-				}
-				list = append(list, contourv1.Condition{
-					Prefix: cr.makePrefix(canary),
-					Header: h,
-				})
+	for _, name := range sortedKeys(match.Headers) {
+		stringMatch := match.Headers[name]
+		h := &contourv1.HeaderCondition{
+			Name:  name,
+			Exact: stringMatch.Exact,
+		}
+		if stringMatch.Suffix != "" {
+			h = &contourv1.HeaderCondition{
+				Name:     name,
+				Contains: stringMatch.Suffix,
 			}
 		}
-	} else {
-		list = []contourv1.Condition{
-			{
-				Prefix: cr.makePrefix(canary),
-			},
+		if stringMatch.Prefix != "" {
+			h = &contourv1.HeaderCondition{
+				Name:     name,
+				Contains: stringMatch.Prefix,
+			}
 		}
+		list = append(list, contourv1.Condition{
+			Header: h,
+		})
 	}
 
+	for _, name := range sortedKeys(match.QueryParams) {
+		stringMatch := match.QueryParams[name]
+		list = append(list, contourv1.Condition{
+			QueryParameter: &contourv1.QueryParameterCondition{
+				Name:   name,
+				Exact:  stringMatch.Exact,
+				Prefix: stringMatch.Prefix,
+				Suffix: stringMatch.Suffix,
+				Regex:  stringMatch.Regex,
+			},
+		})
+	}
+
+	if len(list) == 0 {
+		return []contourv1.Condition{{Prefix: cr.makePrefix(canary)}}
+	}
+	list[0].Prefix = cr.makePrefix(canary)
+
 	return list
 }
 
+// sortedKeys returns the keys of the string matches in a stable order
+func sortedKeys(matches map[string]istiov1alpha1.StringMatch) []string {
+	keys := make([]string, 0, len(matches))
+	for k := range matches {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func (cr *ContourRouter) makeTimeoutPolicy(canary *flaggerv1.Canary) *contourv1.TimeoutPolicy {
 	if canary.Spec.Service.Timeout != "" {
 		return &contourv1.TimeoutPolicy{
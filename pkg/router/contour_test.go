@@ -4,6 +4,9 @@ import (
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
 )
 
 func TestContourRouter_Reconcile(t *testing.T) {
@@ -211,3 +214,76 @@ func TestContourRouter_Routes(t *testing.T) {
 		t.Errorf("Got primary weight %v wanted %v", primary.Weight, 100)
 	}
 }
+
+func TestContourRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ContourRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		contourClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	cd := newTestABTest()
+	cd.Spec.CanaryAnalysis.Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-user-type": {Exact: "test"},
+				"cookie":      {Prefix: "canary=always"},
+			},
+		},
+		{
+			QueryParams: map[string]istiov1alpha1.StringMatch{
+				"canary": {Exact: "true"},
+			},
+		},
+	}
+
+	err := router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(cd, 50, 50, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	proxy, err := router.contourClient.ProjectcontourV1().HTTPProxies("default").Get("abtest", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(proxy.Spec.Routes) != 3 {
+		t.Fatalf("Got %v routes wanted %v", len(proxy.Spec.Routes), 3)
+	}
+
+	// one prefix per route with the headers in order
+	conditions := proxy.Spec.Routes[0].Conditions
+	if len(conditions) != 2 {
+		t.Fatalf("Got %v conditions wanted %v", len(conditions), 2)
+	}
+	if conditions[0].Prefix != "/" || conditions[0].Header.Contains != "canary=always" {
+		t.Errorf("Got first condition %+v wanted prefix with cookie header", conditions[0])
+	}
+	if conditions[1].Prefix != "" || conditions[1].Header.Exact != "test" {
+		t.Errorf("Got second condition %+v wanted x-user-type header", conditions[1])
+	}
+
+	// query parameter match
+	param := proxy.Spec.Routes[1].Conditions[0].QueryParameter
+	if param == nil || param.Name != "canary" || param.Exact != "true" {
+		t.Errorf("Got query parameter condition %+v wanted canary=true", param)
+	}
+	if w := proxy.Spec.Routes[1].Services[1].Weight; w != 50 {
+		t.Errorf("Got canary weight %v wanted %v", w, 50)
+	}
+
+	// default route to primary
+	if w := proxy.Spec.Routes[2].Services[0].Weight; w != 100 {
+		t.Errorf("Got primary weight %v wanted %v", w, 100)
+	}
+	if c := proxy.Spec.Routes[2].Conditions; len(c) != 1 || c[0].Header != nil {
+		t.Errorf("Got default route conditions %+v wanted prefix only", c)
+	}
+}
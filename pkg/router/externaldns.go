@@ -0,0 +1,242 @@
+package router
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	externaldnsv1alpha1 "github.com/weaveworks/flagger/pkg/apis/externaldns/v1alpha1"
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// externalDNSWeightProperty is the Route53 weighted routing policy property
+const externalDNSWeightProperty = "aws/weight"
+
+// ExternalDNSRouter is managing the weighted DNS records that split the traffic between
+// the primary and canary endpoints, or the primary and canary clusters of a multi-cluster blue/green
+type ExternalDNSRouter struct {
+	kubeClient        kubernetes.Interface
+	externalDNSClient clientset.Interface
+	logger            *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the DNS endpoint holding the primary and canary records
+func (er *ExternalDNSRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+	dns, err := er.getDNS(canary)
+	if err != nil {
+		return err
+	}
+
+	spec := externaldnsv1alpha1.DNSEndpointSpec{
+		Endpoints: er.makeEndpoints(canary, dns, 100, 0),
+	}
+
+	endpoint, err := er.externalDNSClient.ExternaldnsV1alpha1().DNSEndpoints(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	// create DNS endpoint
+	if errors.IsNotFound(err) {
+		endpoint = &externaldnsv1alpha1.DNSEndpoint{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apexName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: spec,
+		}
+
+		_, err := er.externalDNSClient.ExternaldnsV1alpha1().DNSEndpoints(canary.Namespace).Create(endpoint)
+		if err != nil {
+			return fmt.Errorf("DNSEndpoint %s.%s create error %v", apexName, canary.Namespace, err)
+		}
+
+		er.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("DNSEndpoint %s.%s created", apexName, canary.Namespace)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("DNSEndpoint %s.%s query error %v", apexName, canary.Namespace, err)
+	}
+
+	// update DNS endpoint, the weights are managed by SetRoutes
+	primaryWeight, canaryWeight, _, err := er.GetRoutes(canary)
+	if err == nil {
+		spec.Endpoints = er.makeEndpoints(canary, dns, primaryWeight, canaryWeight)
+	}
+
+	if diff := cmp.Diff(spec, endpoint.Spec); diff != "" {
+		endpointClone := endpoint.DeepCopy()
+		endpointClone.Spec = spec
+
+		_, err := er.externalDNSClient.ExternaldnsV1alpha1().DNSEndpoints(canary.Namespace).Update(endpointClone)
+		if err != nil {
+			return fmt.Errorf("DNSEndpoint %s.%s update error %v", apexName, canary.Namespace, err)
+		}
+
+		er.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("DNSEndpoint %s.%s updated", apexName, canary.Namespace)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the weights of the primary and canary records
+func (er *ExternalDNSRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	dns, err := er.getDNS(canary)
+	if err != nil {
+		return
+	}
+
+	endpoint, err := er.externalDNSClient.ExternaldnsV1alpha1().DNSEndpoints(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			err = fmt.Errorf("DNSEndpoint %s.%s not found", apexName, canary.Namespace)
+			return
+		}
+		err = fmt.Errorf("DNSEndpoint %s.%s query error %v", apexName, canary.Namespace, err)
+		return
+	}
+
+	found := 0
+	for _, e := range endpoint.Spec.Endpoints {
+		if e.SetIdentifier != primaryName && e.SetIdentifier != canaryName {
+			continue
+		}
+		weight := -1
+		for _, p := range e.ProviderSpecific {
+			if p.Name == er.weightProperty(dns) {
+				weight, err = strconv.Atoi(p.Value)
+				if err != nil {
+					err = fmt.Errorf("DNSEndpoint %s.%s record %s has an invalid weight %s",
+						apexName, canary.Namespace, e.SetIdentifier, p.Value)
+					return
+				}
+			}
+		}
+		if weight < 0 {
+			continue
+		}
+		found++
+		if e.SetIdentifier == primaryName {
+			primaryWeight = weight
+		} else {
+			canaryWeight = weight
+		}
+	}
+
+	if found != 2 {
+		err = fmt.Errorf("DNSEndpoint %s.%s does not contain weighted records for %s and %s",
+			apexName, canary.Namespace, primaryName, canaryName)
+	}
+
+	mirrored = false
+
+	return
+}
+
+// SetRoutes updates the weights of the primary and canary records
+func (er *ExternalDNSRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+) error {
+	apexName, _, _ := canary.GetServiceNames()
+	dns, err := er.getDNS(canary)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := er.externalDNSClient.ExternaldnsV1alpha1().DNSEndpoints(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("DNSEndpoint %s.%s not found", apexName, canary.Namespace)
+		}
+		return fmt.Errorf("DNSEndpoint %s.%s query error %v", apexName, canary.Namespace, err)
+	}
+
+	endpointClone := endpoint.DeepCopy()
+	endpointClone.Spec.Endpoints = er.makeEndpoints(canary, dns, primaryWeight, canaryWeight)
+
+	_, err = er.externalDNSClient.ExternaldnsV1alpha1().DNSEndpoints(canary.Namespace).Update(endpointClone)
+	if err != nil {
+		return fmt.Errorf("DNSEndpoint %s.%s update error %v", apexName, canary.Namespace, err)
+	}
+
+	return nil
+}
+
+// getDNS returns the weighted records of the service, or of the clusters for a multi-cluster blue/green
+func (er *ExternalDNSRouter) getDNS(canary *flaggerv1.Canary) (*flaggerv1.CanaryDNS, error) {
+	dns := canary.Spec.Service.DNS
+	if dns == nil && canary.Spec.Cluster != nil {
+		dns = canary.Spec.Cluster.DNS
+	}
+	if dns == nil {
+		return nil, fmt.Errorf("canary %s.%s spec.service.dns or spec.cluster.dns is required by the externaldns provider",
+			canary.Name, canary.Namespace)
+	}
+	if dns.Hostname == "" || len(dns.PrimaryTargets) == 0 || len(dns.CanaryTargets) == 0 {
+		return nil, fmt.Errorf("canary %s.%s dns requires a hostname, primary targets and canary targets",
+			canary.Name, canary.Namespace)
+	}
+	return dns, nil
+}
+
+func (er *ExternalDNSRouter) weightProperty(dns *flaggerv1.CanaryDNS) string {
+	if dns.WeightProperty != "" {
+		return dns.WeightProperty
+	}
+	return externalDNSWeightProperty
+}
+
+// makeEndpoints returns the primary and canary records of the hostname,
+// the records are identified by the primary and canary service names
+func (er *ExternalDNSRouter) makeEndpoints(canary *flaggerv1.Canary, dns *flaggerv1.CanaryDNS,
+	primaryWeight int, canaryWeight int) []externaldnsv1alpha1.Endpoint {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	recordType := dns.RecordType
+	if recordType == "" {
+		recordType = "CNAME"
+	}
+
+	record := func(setIdentifier string, targets []string, weight int) externaldnsv1alpha1.Endpoint {
+		return externaldnsv1alpha1.Endpoint{
+			DNSName:       dns.Hostname,
+			Targets:       targets,
+			RecordType:    recordType,
+			SetIdentifier: setIdentifier,
+			RecordTTL:     dns.TTL,
+			ProviderSpecific: []externaldnsv1alpha1.ProviderSpecificProperty{
+				{
+					Name:  er.weightProperty(dns),
+					Value: strconv.Itoa(weight),
+				},
+			},
+		}
+	}
+
+	return []externaldnsv1alpha1.Endpoint{
+		record(primaryName, dns.PrimaryTargets, primaryWeight),
+		record(canaryName, dns.CanaryTargets, canaryWeight),
+	}
+}
@@ -0,0 +1,140 @@
+package router
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newExternalDNSTestCanary() *flaggerv1.Canary {
+	cd := newTestCanary()
+	cd.Spec.Cluster = &flaggerv1.CanaryCluster{
+		Name: "green",
+		DNS: &flaggerv1.CanaryDNS{
+			Hostname:       "app.example.com",
+			PrimaryTargets: []string{"blue.example.com"},
+			CanaryTargets:  []string{"green.example.com"},
+		},
+	}
+	return cd
+}
+
+func TestExternalDNSRouter_Reconcile(t *testing.T) {
+	canary := newExternalDNSTestCanary()
+	mocks := newFixture(canary)
+	router := &ExternalDNSRouter{
+		logger:            mocks.logger,
+		kubeClient:        mocks.kubeClient,
+		externalDNSClient: mocks.meshClient,
+	}
+
+	err := router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	endpoint, err := router.externalDNSClient.ExternaldnsV1alpha1().DNSEndpoints("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(endpoint.Spec.Endpoints) != 2 {
+		t.Fatalf("Got endpoints %v wanted 2", len(endpoint.Spec.Endpoints))
+	}
+	primary := endpoint.Spec.Endpoints[0]
+	if primary.DNSName != "app.example.com" || primary.RecordType != "CNAME" || primary.Targets[0] != "blue.example.com" {
+		t.Errorf("Got primary record %v", primary)
+	}
+	if primary.SetIdentifier != "podinfo-primary" || primary.ProviderSpecific[0].Name != "aws/weight" {
+		t.Errorf("Got primary record %v", primary)
+	}
+
+	err = router.SetRoutes(canary, 0, 100, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// reconcile doesn't reset the weights
+	err = router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, m, err := router.GetRoutes(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 0 || c != 100 || m {
+		t.Errorf("Got routes %v/%v/%v wanted %v/%v/%v", p, c, m, 0, 100, false)
+	}
+
+	// the targets are updated
+	canary.Spec.Cluster.DNS.CanaryTargets = []string{"green-v2.example.com"}
+	err = router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	endpoint, err = router.externalDNSClient.ExternaldnsV1alpha1().DNSEndpoints("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if endpoint.Spec.Endpoints[1].Targets[0] != "green-v2.example.com" {
+		t.Errorf("Got canary targets %v wanted green-v2.example.com", endpoint.Spec.Endpoints[1].Targets)
+	}
+}
+
+func TestExternalDNSRouter_MissingDNS(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ExternalDNSRouter{
+		logger:            mocks.logger,
+		kubeClient:        mocks.kubeClient,
+		externalDNSClient: mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	if err == nil {
+		t.Error("Expected error for canary without dns records")
+	}
+}
+
+func TestExternalDNSRouter_ServiceDNS(t *testing.T) {
+	canary := newTestCanary()
+	canary.Spec.Service.DNS = &flaggerv1.CanaryDNS{
+		Hostname:       "app.example.com",
+		RecordType:     "A",
+		TTL:            30,
+		PrimaryTargets: []string{"10.0.0.1", "10.0.0.2"},
+		CanaryTargets:  []string{"10.0.1.1"},
+	}
+	mocks := newFixture(canary)
+	router := &ExternalDNSRouter{
+		logger:            mocks.logger,
+		kubeClient:        mocks.kubeClient,
+		externalDNSClient: mocks.meshClient,
+	}
+
+	err := router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	endpoint, err := router.externalDNSClient.ExternaldnsV1alpha1().DNSEndpoints("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	canaryRecord := endpoint.Spec.Endpoints[1]
+	if canaryRecord.RecordType != "A" || canaryRecord.RecordTTL != 30 || canaryRecord.Targets[0] != "10.0.1.1" {
+		t.Errorf("Got canary record %v", canaryRecord)
+	}
+
+	for _, r := range RunConformance(router, canary) {
+		if r.Check == "mirror" || r.Check == "ab-testing" {
+			continue
+		}
+		if !r.Passed {
+			t.Errorf("Check %s failed %s", r.Check, r.Message)
+		}
+	}
+}
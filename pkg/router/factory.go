@@ -51,11 +51,49 @@ func (factory *Factory) KubernetesRouter(kind string, labelSelector string, anno
 		return deploymentRouter
 	case kind == "Service":
 		return noopRouter
+	case kind == "CronJob":
+		return noopRouter
+	case kind == "StatefulSet":
+		return noopRouter
 	default:
 		return deploymentRouter
 	}
 }
 
+// ClusterKubernetesRouter returns a router that manages the services of a multi-cluster canary,
+// the apex and primary services are created in the Flagger cluster and the canary service in the canary cluster
+func (factory *Factory) ClusterKubernetesRouter(clusterClient kubernetes.Interface, labelSelector string, ports map[string]int32) KubernetesRouter {
+	return &KubernetesClusterRouter{
+		primary: &KubernetesDeploymentRouter{
+			logger:        factory.logger,
+			flaggerClient: factory.flaggerClient,
+			kubeClient:    factory.kubeClient,
+			labelSelector: labelSelector,
+			annotations:   map[string]string{},
+			ports:         ports,
+		},
+		canary: &KubernetesDeploymentRouter{
+			logger:        factory.logger,
+			flaggerClient: factory.flaggerClient,
+			kubeClient:    clusterClient,
+			labelSelector: labelSelector,
+			annotations:   map[string]string{},
+			ports:         ports,
+			remote:        true,
+		},
+	}
+}
+
+// MonitorsRouter returns a router that clones the Prometheus Operator monitors of the target
+func (factory *Factory) MonitorsRouter(labelSelector string) *MonitorsRouter {
+	return &MonitorsRouter{
+		logger:        factory.logger,
+		flaggerClient: factory.flaggerClient,
+		kubeClient:    factory.kubeClient,
+		labelSelector: labelSelector,
+	}
+}
+
 // MeshRouter returns a service mesh router
 func (factory *Factory) MeshRouter(provider string) Interface {
 	switch {
@@ -63,12 +101,28 @@ func (factory *Factory) MeshRouter(provider string) Interface {
 		return &NopRouter{}
 	case provider == "kubernetes":
 		return &NopRouter{}
+	case provider == "partition":
+		return &StatefulSetRouter{
+			logger:     factory.logger,
+			kubeClient: factory.kubeClient,
+		}
 	case provider == "nginx":
 		return &IngressRouter{
 			logger:            factory.logger,
 			kubeClient:        factory.kubeClient,
 			annotationsPrefix: factory.ingressAnnotationsPrefix,
 		}
+	case provider == "haproxy":
+		return &IngressRouter{
+			logger:            factory.logger,
+			kubeClient:        factory.kubeClient,
+			annotationsPrefix: haproxyAnnotationsPrefix,
+		}
+	case provider == "alb":
+		return &ALBRouter{
+			logger:     factory.logger,
+			kubeClient: factory.kubeClient,
+		}
 	case provider == "appmesh":
 		return &AppMeshRouter{
 			logger:        factory.logger,
@@ -77,21 +131,28 @@ func (factory *Factory) MeshRouter(provider string) Interface {
 			appmeshClient: factory.meshClient,
 		}
 	case strings.HasPrefix(provider, "smi:"):
-		mesh := strings.TrimPrefix(provider, "smi:")
-		return &SmiRouter{
-			logger:        factory.logger,
-			flaggerClient: factory.flaggerClient,
-			kubeClient:    factory.kubeClient,
-			smiClient:     factory.meshClient,
-			targetMesh:    mesh,
+		mesh, version := splitSmiVersion(strings.TrimPrefix(provider, "smi:"))
+		return factory.smiRouter(mesh, version)
+	case provider == "linkerd" || strings.HasPrefix(provider, "linkerd:"):
+		_, version := splitSmiVersion(provider)
+		return factory.smiRouter("linkerd", version)
+	case provider == "osm":
+		return &OsmRouter{
+			logger:     factory.logger,
+			kubeClient: factory.kubeClient,
+			smiClient:  factory.meshClient,
 		}
-	case provider == "linkerd":
-		return &SmiRouter{
-			logger:        factory.logger,
-			flaggerClient: factory.flaggerClient,
-			kubeClient:    factory.kubeClient,
-			smiClient:     factory.meshClient,
-			targetMesh:    "linkerd",
+	case provider == "kuma":
+		return &KumaRouter{
+			logger:     factory.logger,
+			kubeClient: factory.kubeClient,
+			kumaClient: factory.meshClient,
+		}
+	case provider == "externaldns":
+		return &ExternalDNSRouter{
+			logger:            factory.logger,
+			kubeClient:        factory.kubeClient,
+			externalDNSClient: factory.meshClient,
 		}
 	case provider == "contour":
 		return &ContourRouter{
@@ -100,6 +161,24 @@ func (factory *Factory) MeshRouter(provider string) Interface {
 			kubeClient:    factory.kubeClient,
 			contourClient: factory.meshClient,
 		}
+	case provider == "kong":
+		return &KongRouter{
+			logger:     factory.logger,
+			kubeClient: factory.kubeClient,
+			kongClient: factory.meshClient,
+		}
+	case provider == "apisix":
+		return &ApisixRouter{
+			logger:       factory.logger,
+			kubeClient:   factory.kubeClient,
+			apisixClient: factory.meshClient,
+		}
+	case provider == "ambassador":
+		return &AmbassadorRouter{
+			logger:           factory.logger,
+			kubeClient:       factory.kubeClient,
+			ambassadorClient: factory.meshClient,
+		}
 	case strings.HasPrefix(provider, "gloo"):
 		upstreamDiscoveryNs := "gloo-system"
 		if strings.HasPrefix(provider, "gloo:") {
@@ -143,3 +222,80 @@ func (factory *Factory) MeshRouter(provider string) Interface {
 		}
 	}
 }
+
+// smiRouter returns the router of the SMI traffic split API version
+func (factory *Factory) smiRouter(mesh string, version string) Interface {
+	switch version {
+	case "v1alpha2":
+		return &SmiV1alpha2Router{
+			logger:     factory.logger,
+			kubeClient: factory.kubeClient,
+			smiClient:  factory.meshClient,
+		}
+	case "v1alpha3":
+		return &SmiV1alpha3Router{
+			logger:     factory.logger,
+			kubeClient: factory.kubeClient,
+			smiClient:  factory.meshClient,
+		}
+	default:
+		return &SmiRouter{
+			logger:        factory.logger,
+			flaggerClient: factory.flaggerClient,
+			kubeClient:    factory.kubeClient,
+			smiClient:     factory.meshClient,
+			targetMesh:    mesh,
+		}
+	}
+}
+
+// splitSmiVersion splits the SMI API version from a provider e.g. linkerd:v1alpha3 or istio:v1alpha2,
+// the version defaults to v1alpha1
+func splitSmiVersion(provider string) (mesh string, version string) {
+	version = "v1alpha1"
+	var parts []string
+	for _, p := range strings.Split(provider, ":") {
+		if strings.HasPrefix(p, "v1alpha") {
+			version = p
+			continue
+		}
+		parts = append(parts, p)
+	}
+	return strings.Join(parts, ":"), version
+}
+
+// SupportsMultiCluster returns true if the provider can split the traffic between the Flagger cluster
+// and the canary cluster, ExternalDNS weights the DNS records of the clusters while the Istio gateway
+// routes to the services of all the clusters of a multi-cluster mesh
+func SupportsMultiCluster(provider string) bool {
+	switch provider {
+	case "externaldns", "istio":
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsUDP returns true if the provider can route the datagrams of a UDP service,
+// the Kubernetes services are switched in blue/green fashion while ExternalDNS shifts the
+// weight of the DNS records, the StatefulSet partition doesn't route any traffic and
+// the service mesh and ingress routers handle only HTTP and TCP
+func SupportsUDP(provider string) bool {
+	switch provider {
+	case "none", "kubernetes", "externaldns", "partition":
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsGRPCMatch returns true if the provider can route the gRPC calls by service, method and metadata,
+// the Istio virtual service matches the HTTP/2 path and headers of the calls
+func SupportsGRPCMatch(provider string) bool {
+	switch provider {
+	case "istio":
+		return true
+	default:
+		return false
+	}
+}
@@ -2,10 +2,13 @@ package router
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	gloov1 "github.com/weaveworks/flagger/pkg/apis/gloo/v1"
+	gloogatewayv1 "github.com/weaveworks/flagger/pkg/apis/gloogateway/v1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,7 +19,7 @@ import (
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 )
 
-// GlooRouter is managing Istio virtual services
+// GlooRouter is managing Gloo upstream groups and route tables
 type GlooRouter struct {
 	kubeClient          kubernetes.Interface
 	glooClient          clientset.Interface
@@ -25,8 +28,22 @@ type GlooRouter struct {
 	upstreamDiscoveryNs string
 }
 
-// Reconcile creates or updates the Istio virtual service
+// Reconcile creates or updates the Gloo upstream group and route table
 func (gr *GlooRouter) Reconcile(canary *flaggerv1.Canary) error {
+	err := gr.reconcileUpstreamGroup(canary)
+	if err != nil {
+		return err
+	}
+
+	err = gr.reconcileRouteTable(canary)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (gr *GlooRouter) reconcileUpstreamGroup(canary *flaggerv1.Canary) error {
 	apexName, _, _ := canary.GetServiceNames()
 	canaryName := fmt.Sprintf("%s-%s-canary-%v", canary.Namespace, apexName, canary.Spec.Service.Port)
 	primaryName := fmt.Sprintf("%s-%s-primary-%v", canary.Namespace, apexName, canary.Spec.Service.Port)
@@ -106,6 +123,153 @@ func (gr *GlooRouter) Reconcile(canary *flaggerv1.Canary) error {
 	return nil
 }
 
+// reconcileRouteTable creates or updates the route table that routes the traffic to the upstream group,
+// for A/B testing the requests matching the canary headers are routed to the upstream group and
+// all other requests to primary. The route options set on the existing route table are kept.
+func (gr *GlooRouter) reconcileRouteTable(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+	primaryName := fmt.Sprintf("%s-%s-primary-%v", canary.Namespace, apexName, canary.Spec.Service.Port)
+
+	upstreamGroup := gloogatewayv1.RouteAction{
+		UpstreamGroup: &gloov1.ResourceRef{
+			Name:      apexName,
+			Namespace: canary.Namespace,
+		},
+	}
+
+	newSpec := gloogatewayv1.RouteTableSpec{
+		Routes: []gloogatewayv1.Route{
+			{
+				Matchers: []gloogatewayv1.Matcher{{Prefix: gr.makePrefix(canary)}},
+				Action:   upstreamGroup,
+			},
+		},
+	}
+
+	if len(canary.GetAnalysis().Match) > 0 {
+		newSpec.Routes = nil
+		for _, match := range canary.GetAnalysis().Match {
+			newSpec.Routes = append(newSpec.Routes, gloogatewayv1.Route{
+				Matchers: []gloogatewayv1.Matcher{
+					{
+						Prefix:  gr.makePrefix(canary),
+						Headers: gr.makeHeaderMatchers(match),
+					},
+				},
+				Action: upstreamGroup,
+			})
+		}
+		newSpec.Routes = append(newSpec.Routes, gloogatewayv1.Route{
+			Matchers: []gloogatewayv1.Matcher{{Prefix: gr.makePrefix(canary)}},
+			Action: gloogatewayv1.RouteAction{
+				Single: &gloov1.Destination{
+					Upstream: gloov1.ResourceRef{
+						Name:      primaryName,
+						Namespace: gr.upstreamDiscoveryNs,
+					},
+				},
+			},
+		})
+	}
+
+	routeTable, err := gr.glooClient.GatewayV1().RouteTables(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		routeTable = &gloogatewayv1.RouteTable{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apexName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: newSpec,
+		}
+
+		_, err = gr.glooClient.GatewayV1().RouteTables(canary.Namespace).Create(routeTable)
+		if err != nil {
+			return fmt.Errorf("RouteTable %s.%s create error %v", apexName, canary.Namespace, err)
+		}
+		gr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("RouteTable %s.%s created", routeTable.GetName(), canary.Namespace)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("RouteTable %s.%s query error %v", apexName, canary.Namespace, err)
+	}
+
+	// keep the timeouts, retries and transformations of the existing routes,
+	// the canary routes added for A/B testing get the options of the default route
+	if n := len(routeTable.Spec.Routes); n > 0 {
+		defaultOptions := routeTable.Spec.Routes[n-1].Options
+		for i := range newSpec.Routes {
+			options := defaultOptions
+			if i < n-1 && i < len(newSpec.Routes)-1 {
+				options = routeTable.Spec.Routes[i].Options
+			}
+			if options != nil {
+				newSpec.Routes[i].Options = options.DeepCopy()
+			}
+		}
+	}
+
+	if diff := cmp.Diff(newSpec, routeTable.Spec); diff != "" {
+		clone := routeTable.DeepCopy()
+		clone.Spec = newSpec
+
+		_, err = gr.glooClient.GatewayV1().RouteTables(canary.Namespace).Update(clone)
+		if err != nil {
+			return fmt.Errorf("RouteTable %s.%s update error %v", apexName, canary.Namespace, err)
+		}
+		gr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("RouteTable %s.%s updated", routeTable.GetName(), canary.Namespace)
+	}
+
+	return nil
+}
+
+func (gr *GlooRouter) makePrefix(canary *flaggerv1.Canary) string {
+	prefix := "/"
+
+	if len(canary.Spec.Service.Match) > 0 &&
+		canary.Spec.Service.Match[0].Uri != nil &&
+		canary.Spec.Service.Match[0].Uri.Prefix != "" {
+		prefix = canary.Spec.Service.Match[0].Uri.Prefix
+	}
+
+	return prefix
+}
+
+// makeHeaderMatchers converts the header match conditions to Gloo matchers,
+// the prefix and suffix conditions are converted to regular expressions
+func (gr *GlooRouter) makeHeaderMatchers(match istiov1alpha3.HTTPMatchRequest) []gloogatewayv1.HeaderMatcher {
+	var headers []gloogatewayv1.HeaderMatcher
+	for _, name := range sortedKeys(match.Headers) {
+		stringMatch := match.Headers[name]
+		h := gloogatewayv1.HeaderMatcher{
+			Name:  name,
+			Value: stringMatch.Exact,
+		}
+		switch {
+		case stringMatch.Prefix != "":
+			h.Value = regexp.QuoteMeta(stringMatch.Prefix) + ".*"
+			h.Regex = true
+		case stringMatch.Suffix != "":
+			h.Value = ".*" + regexp.QuoteMeta(stringMatch.Suffix)
+			h.Regex = true
+		case stringMatch.Regex != "":
+			h.Value = stringMatch.Regex
+			h.Regex = true
+		}
+		headers = append(headers, h)
+	}
+	return headers
+}
+
 // GetRoutes returns the destinations weight for primary and canary
 func (gr *GlooRouter) GetRoutes(canary *flaggerv1.Canary) (
 	primaryWeight int,
@@ -5,8 +5,11 @@ import (
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	gloov1 "github.com/weaveworks/flagger/pkg/apis/gloo/v1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
 )
 
 func TestGlooRouter_Sync(t *testing.T) {
@@ -130,3 +133,84 @@ func TestGlooRouter_GetRoutes(t *testing.T) {
 		t.Errorf("Got mirror %v wanted %v", m, false)
 	}
 }
+
+func TestGlooRouter_RouteTable(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &GlooRouter{
+		logger:              mocks.logger,
+		flaggerClient:       mocks.flaggerClient,
+		glooClient:          mocks.meshClient,
+		kubeClient:          mocks.kubeClient,
+		upstreamDiscoveryNs: "gloo-system",
+	}
+
+	err := router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	rt, err := router.glooClient.GatewayV1().RouteTables("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(rt.Spec.Routes) != 1 {
+		t.Fatalf("Got routes %v wanted %v", len(rt.Spec.Routes), 1)
+	}
+	if ug := rt.Spec.Routes[0].Action.UpstreamGroup; ug == nil || ug.Name != "podinfo" {
+		t.Errorf("Got upstream group %v wanted podinfo", ug)
+	}
+
+	// set route options on the route table
+	rtClone := rt.DeepCopy()
+	rtClone.Spec.Routes[0].Options = &runtime.RawExtension{Raw: []byte(`{"timeout":"30s","retries":{"numRetries":3}}`)}
+	_, err = router.glooClient.GatewayV1().RouteTables("default").Update(rtClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// switch to A/B testing
+	cd := mocks.canary.DeepCopy()
+	cd.Spec.CanaryAnalysis.Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-canary":   {Exact: "insider"},
+				"user-agent": {Prefix: "Android"},
+			},
+		},
+	}
+	err = router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	rt, err = router.glooClient.GatewayV1().RouteTables("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(rt.Spec.Routes) != 2 {
+		t.Fatalf("Got routes %v wanted %v", len(rt.Spec.Routes), 2)
+	}
+
+	headers := rt.Spec.Routes[0].Matchers[0].Headers
+	if len(headers) != 2 {
+		t.Fatalf("Got headers %v wanted %v", len(headers), 2)
+	}
+	if headers[0].Name != "user-agent" || headers[0].Value != "Android.*" || !headers[0].Regex {
+		t.Errorf("Got header matcher %+v wanted user-agent regex", headers[0])
+	}
+	if headers[1].Name != "x-canary" || headers[1].Value != "insider" || headers[1].Regex {
+		t.Errorf("Got header matcher %+v wanted x-canary exact", headers[1])
+	}
+
+	primary := rt.Spec.Routes[1].Action.Single
+	if primary == nil || primary.Upstream.Name != "default-podinfo-primary-9898" {
+		t.Errorf("Got default route %v wanted primary upstream", primary)
+	}
+
+	// the route options are kept for all routes
+	for i, route := range rt.Spec.Routes {
+		if route.Options == nil || string(route.Options.Raw) != `{"timeout":"30s","retries":{"numRetries":3}}` {
+			t.Errorf("Got route %v options %v wanted the route table options", i, route.Options)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -14,8 +15,14 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
 )
 
+// haproxyAnnotationsPrefix is the prefix of the HAProxy Ingress canary annotations
+const haproxyAnnotationsPrefix = "haproxy-ingress.github.io"
+
+// IngressRouter is managing the canary ingress of the NGINX and HAProxy ingress controllers
 type IngressRouter struct {
 	kubeClient        kubernetes.Interface
 	annotationsPrefix string
@@ -159,21 +166,12 @@ func (i *IngressRouter) SetRoutes(
 
 	// A/B testing
 	if len(canary.GetAnalysis().Match) > 0 {
-		cookie := ""
-		header := ""
-		headerValue := ""
-		for _, m := range canary.GetAnalysis().Match {
-			for k, v := range m.Headers {
-				if k == "cookie" {
-					cookie = v.Exact
-				} else {
-					header = k
-					headerValue = v.Exact
-				}
-			}
+		match, err := makeHeaderMatch(canary.GetAnalysis().Match)
+		if err != nil {
+			return fmt.Errorf("ingress %s %v", canaryIngressName, err)
 		}
 
-		iClone.Annotations = i.makeHeaderAnnotations(iClone.Annotations, header, headerValue, cookie)
+		iClone.Annotations = i.makeHeaderAnnotations(iClone.Annotations, match)
 	} else {
 		// canary
 		iClone.Annotations[i.GetAnnotationWithPrefix("canary-weight")] = fmt.Sprintf("%v", canaryWeight)
@@ -186,6 +184,15 @@ func (i *IngressRouter) SetRoutes(
 		iClone.Annotations = i.makeAnnotations(iClone.Annotations)
 	}
 
+	// session affinity
+	if name, maxAge := canary.GetSessionCookie(); name != "" && len(canary.GetAnalysis().Match) == 0 &&
+		i.annotationsPrefix != haproxyAnnotationsPrefix {
+		iClone.Annotations[i.GetAnnotationWithPrefix("affinity")] = "cookie"
+		iClone.Annotations[i.GetAnnotationWithPrefix("affinity-canary-behavior")] = "sticky"
+		iClone.Annotations[i.GetAnnotationWithPrefix("session-cookie-name")] = name
+		iClone.Annotations[i.GetAnnotationWithPrefix("session-cookie-max-age")] = fmt.Sprintf("%v", maxAge)
+	}
+
 	_, err = i.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Update(iClone)
 	if err != nil {
 		return fmt.Errorf("ingress %s update error %v", canaryIngressName, err)
@@ -209,11 +216,11 @@ func (i *IngressRouter) makeAnnotations(annotations map[string]string) map[strin
 	return res
 }
 
-func (i *IngressRouter) makeHeaderAnnotations(annotations map[string]string,
-	header string, headerValue string, cookie string) map[string]string {
+func (i *IngressRouter) makeHeaderAnnotations(annotations map[string]string, match headerMatch) map[string]string {
 	res := make(map[string]string)
 	for k, v := range annotations {
-		if !strings.Contains(v, i.GetAnnotationWithPrefix("canary")) {
+		if !strings.Contains(v, i.GetAnnotationWithPrefix("canary")) &&
+			!strings.HasPrefix(k, i.GetAnnotationWithPrefix("canary-by-")) {
 			res[k] = v
 		}
 	}
@@ -221,21 +228,102 @@ func (i *IngressRouter) makeHeaderAnnotations(annotations map[string]string,
 	res[i.GetAnnotationWithPrefix("canary")] = "true"
 	res[i.GetAnnotationWithPrefix("canary-weight")] = "0"
 
-	if cookie != "" {
-		res[i.GetAnnotationWithPrefix("canary-by-cookie")] = cookie
+	if match.cookie != "" {
+		res[i.GetAnnotationWithPrefix("canary-by-cookie")] = match.cookie
+	}
+
+	if match.header != "" {
+		res[i.GetAnnotationWithPrefix("canary-by-header")] = match.header
 	}
 
-	if header != "" {
-		res[i.GetAnnotationWithPrefix("canary-by-header")] = header
+	if match.value != "" {
+		res[i.GetAnnotationWithPrefix("canary-by-header-value")] = match.value
 	}
 
-	if headerValue != "" {
-		res[i.GetAnnotationWithPrefix("canary-by-header-value")] = headerValue
+	if match.pattern != "" {
+		res[i.GetAnnotationWithPrefix("canary-by-header-pattern")] = match.pattern
 	}
 
 	return res
 }
 
+// headerMatch holds the canary-by annotation values of the A/B testing conditions
+type headerMatch struct {
+	cookie  string
+	header  string
+	value   string
+	pattern string
+}
+
+// makeHeaderMatch translates the A/B testing conditions into the canary-by annotations values,
+// a single exact value is matched with canary-by-header-value while multiple values,
+// prefix, suffix and regex conditions are combined into the canary-by-header-pattern regex
+func makeHeaderMatch(matches []istiov1alpha3.HTTPMatchRequest) (headerMatch, error) {
+	var res headerMatch
+	var values []istiov1alpha1.StringMatch
+	for _, m := range matches {
+		for k, v := range m.Headers {
+			if strings.EqualFold(k, "cookie") {
+				cookie, err := makeCookieName(v)
+				if err != nil {
+					return res, err
+				}
+				if res.cookie != "" && res.cookie != cookie {
+					return res, fmt.Errorf("A/B testing supports a single cookie, found %s and %s", res.cookie, cookie)
+				}
+				res.cookie = cookie
+				continue
+			}
+			if res.header != "" && res.header != k {
+				return res, fmt.Errorf("A/B testing supports a single header, found %s and %s", res.header, k)
+			}
+			res.header = k
+			values = append(values, v)
+		}
+	}
+
+	if len(values) == 1 && values[0].Exact != "" {
+		res.value = values[0].Exact
+		return res, nil
+	}
+
+	var exprs []string
+	for _, v := range values {
+		switch {
+		case v.Exact != "":
+			exprs = append(exprs, "^"+regexp.QuoteMeta(v.Exact)+"$")
+		case v.Prefix != "":
+			exprs = append(exprs, "^"+regexp.QuoteMeta(v.Prefix))
+		case v.Suffix != "":
+			exprs = append(exprs, regexp.QuoteMeta(v.Suffix)+"$")
+		case v.Regex != "":
+			exprs = append(exprs, v.Regex)
+		}
+	}
+	res.pattern = strings.Join(exprs, "|")
+	return res, nil
+}
+
+// cookieRegex matches the cookie conditions used by the service mesh routers e.g. ^(.*?;)?(canary=always)(;.*)?$
+var cookieRegex = regexp.MustCompile(`^\^\(\.\*\?;\)\?\(([^=()]+)=always\)\(;\.\*\)\?\$$`)
+
+// makeCookieName returns the canary-by-cookie name of a cookie condition,
+// NGINX routes the requests to the canary when the cookie value is always
+// so the condition can be the cookie name, name=always or the service mesh regex
+func makeCookieName(match istiov1alpha1.StringMatch) (string, error) {
+	switch {
+	case match.Exact != "" && !strings.Contains(match.Exact, "="):
+		return match.Exact, nil
+	case strings.HasSuffix(match.Exact, "=always"):
+		return strings.TrimSuffix(match.Exact, "=always"), nil
+	case match.Regex != "":
+		if m := cookieRegex.FindStringSubmatch(match.Regex); m != nil {
+			return m[1], nil
+		}
+	}
+	return "", fmt.Errorf("A/B testing cookie condition %+v is not supported, the cookie value must be always", match)
+}
+
 func (i *IngressRouter) GetAnnotationWithPrefix(suffix string) string {
 	return fmt.Sprintf("%v/%v", i.annotationsPrefix, suffix)
 }
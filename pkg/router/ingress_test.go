@@ -2,9 +2,14 @@ package router
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
 )
 
 func TestIngressRouter_Reconcile(t *testing.T) {
@@ -115,3 +120,222 @@ func TestIngressRouter_GetSetRoutes(t *testing.T) {
 		t.Errorf("Got canary weight annotation %v wanted 0", inCanary.Annotations[canaryWeightAn])
 	}
 }
+
+func TestIngressRouter_SessionAffinity(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IngressRouter{
+		logger:            mocks.logger,
+		kubeClient:        mocks.kubeClient,
+		annotationsPrefix: "nginx.ingress.kubernetes.io",
+	}
+
+	canary := mocks.ingressCanary.DeepCopy()
+	canary.GetAnalysis().SessionAffinity = &flaggerv1.SessionAffinity{}
+
+	err := router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(canary, 80, 20, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	inCanary, err := router.kubeClient.ExtensionsV1beta1().Ingresses("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := map[string]string{
+		"nginx.ingress.kubernetes.io/affinity":                 "cookie",
+		"nginx.ingress.kubernetes.io/affinity-canary-behavior": "sticky",
+		"nginx.ingress.kubernetes.io/session-cookie-name":      "flagger-cookie",
+		"nginx.ingress.kubernetes.io/session-cookie-max-age":   "86400",
+		"nginx.ingress.kubernetes.io/canary-weight":            "20",
+	}
+	for k, v := range expected {
+		if inCanary.Annotations[k] != v {
+			t.Errorf("Got annotation %s=%s wanted %s", k, inCanary.Annotations[k], v)
+		}
+	}
+}
+
+func TestIngressRouter_HAProxy(t *testing.T) {
+	mocks := newFixture(nil)
+	factory := NewFactory(nil, mocks.kubeClient, mocks.flaggerClient, "nginx.ingress.kubernetes.io", mocks.logger, mocks.meshClient)
+	router, ok := factory.MeshRouter("haproxy").(*IngressRouter)
+	if !ok {
+		t.Fatalf("Expected an ingress router for the haproxy provider")
+	}
+
+	err := router.Reconcile(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(mocks.ingressCanary, 70, 30, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	inCanary, err := router.kubeClient.ExtensionsV1beta1().Ingresses("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if inCanary.Annotations["haproxy-ingress.github.io/canary"] != "true" {
+		t.Errorf("Got canary annotation %v wanted true", inCanary.Annotations["haproxy-ingress.github.io/canary"])
+	}
+
+	if inCanary.Annotations["haproxy-ingress.github.io/canary-weight"] != "30" {
+		t.Errorf("Got canary weight annotation %v wanted 30", inCanary.Annotations["haproxy-ingress.github.io/canary-weight"])
+	}
+
+	for k := range inCanary.Annotations {
+		if strings.HasPrefix(k, "nginx.ingress.kubernetes.io") {
+			t.Errorf("Unexpected nginx annotation %s", k)
+		}
+	}
+}
+
+func TestIngressRouter_ABTestHeaderMatch(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IngressRouter{
+		logger:            mocks.logger,
+		kubeClient:        mocks.kubeClient,
+		annotationsPrefix: "nginx.ingress.kubernetes.io",
+	}
+
+	cd := mocks.ingressCanary.DeepCopy()
+	err := router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	canaryName := fmt.Sprintf("%s-canary", cd.Spec.IngressRef.Name)
+	headerAn := "nginx.ingress.kubernetes.io/canary-by-header"
+	valueAn := "nginx.ingress.kubernetes.io/canary-by-header-value"
+	patternAn := "nginx.ingress.kubernetes.io/canary-by-header-pattern"
+
+	tests := []struct {
+		match   []istiov1alpha3.HTTPMatchRequest
+		value   string
+		pattern string
+	}{
+		{
+			match: []istiov1alpha3.HTTPMatchRequest{
+				{Headers: map[string]istiov1alpha1.StringMatch{"x-canary": {Exact: "insider"}}},
+			},
+			value: "insider",
+		},
+		{
+			match: []istiov1alpha3.HTTPMatchRequest{
+				{Headers: map[string]istiov1alpha1.StringMatch{"x-canary": {Exact: "insider"}}},
+				{Headers: map[string]istiov1alpha1.StringMatch{"x-canary": {Exact: "beta.tester"}}},
+			},
+			pattern: `^insider$|^beta\.tester$`,
+		},
+		{
+			match: []istiov1alpha3.HTTPMatchRequest{
+				{Headers: map[string]istiov1alpha1.StringMatch{"x-canary": {Regex: "^(insider|beta)-[0-9]+$"}}},
+				{Headers: map[string]istiov1alpha1.StringMatch{"x-canary": {Prefix: "qa-"}}},
+			},
+			pattern: `^(insider|beta)-[0-9]+$|^qa-`,
+		},
+	}
+
+	for _, tt := range tests {
+		cd.GetAnalysis().Match = tt.match
+		err = router.SetRoutes(cd, 0, 100, false)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		inCanary, err := router.kubeClient.ExtensionsV1beta1().Ingresses("default").Get(canaryName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if inCanary.Annotations[headerAn] != "x-canary" {
+			t.Errorf("Got header annotation %v wanted %v", inCanary.Annotations[headerAn], "x-canary")
+		}
+		if inCanary.Annotations[valueAn] != tt.value {
+			t.Errorf("Got header value annotation %v wanted %v", inCanary.Annotations[valueAn], tt.value)
+		}
+		if inCanary.Annotations[patternAn] != tt.pattern {
+			t.Errorf("Got header pattern annotation %v wanted %v", inCanary.Annotations[patternAn], tt.pattern)
+		}
+	}
+
+	// NGINX routes on a single header
+	cd.GetAnalysis().Match = []istiov1alpha3.HTTPMatchRequest{
+		{Headers: map[string]istiov1alpha1.StringMatch{"x-canary": {Exact: "insider"}}},
+		{Headers: map[string]istiov1alpha1.StringMatch{"x-user": {Exact: "test"}}},
+	}
+	err = router.SetRoutes(cd, 0, 100, false)
+	if err == nil {
+		t.Error("Expected error for multiple headers")
+	}
+}
+
+func TestIngressRouter_ABTestCookieMatch(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IngressRouter{
+		logger:            mocks.logger,
+		kubeClient:        mocks.kubeClient,
+		annotationsPrefix: "nginx.ingress.kubernetes.io",
+	}
+
+	cd := mocks.ingressCanary.DeepCopy()
+	err := router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	canaryName := fmt.Sprintf("%s-canary", cd.Spec.IngressRef.Name)
+	cookieAn := "nginx.ingress.kubernetes.io/canary-by-cookie"
+	headerAn := "nginx.ingress.kubernetes.io/canary-by-header"
+
+	for _, cookie := range []istiov1alpha1.StringMatch{
+		{Exact: "canary"},
+		{Exact: "canary=always"},
+		{Regex: "^(.*?;)?(canary=always)(;.*)?$"},
+	} {
+		cd.GetAnalysis().Match = []istiov1alpha3.HTTPMatchRequest{
+			{Headers: map[string]istiov1alpha1.StringMatch{"cookie": cookie}},
+		}
+		err = router.SetRoutes(cd, 0, 100, false)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		inCanary, err := router.kubeClient.ExtensionsV1beta1().Ingresses("default").Get(canaryName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if inCanary.Annotations[cookieAn] != "canary" {
+			t.Errorf("Got cookie annotation %v wanted %v", inCanary.Annotations[cookieAn], "canary")
+		}
+		if _, ok := inCanary.Annotations[headerAn]; ok {
+			t.Errorf("Unexpected header annotation %v", inCanary.Annotations[headerAn])
+		}
+
+		_, canaryWeight, _, err := router.GetRoutes(cd)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if canaryWeight != 100 {
+			t.Errorf("Got canary weight %v wanted %v", canaryWeight, 100)
+		}
+	}
+
+	// NGINX routes on the always cookie value
+	cd.GetAnalysis().Match = []istiov1alpha3.HTTPMatchRequest{
+		{Headers: map[string]istiov1alpha1.StringMatch{"cookie": {Exact: "canary=beta"}}},
+	}
+	err = router.SetRoutes(cd, 0, 100, false)
+	if err == nil {
+		t.Error("Expected error for cookie value")
+	}
+}
@@ -2,16 +2,20 @@ package router
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
 	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 )
@@ -38,6 +42,13 @@ func (ir *IstioRouter) Reconcile(canary *flaggerv1.Canary) error {
 		return err
 	}
 
+	if canary.GetAnalysis().MirrorTarget != nil {
+		err = ir.reconcileMirrorService(canary)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = ir.reconcileVirtualService(canary)
 	if err != nil {
 		return err
@@ -100,10 +111,21 @@ func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name s
 }
 
 func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames()
+
+	if err := validateMatchConditions(canary.GetAnalysis().Match); err != nil {
+		return fmt.Errorf("VirtualService %s.%s %v", apexName, canary.Namespace, err)
+	}
+
+	if err := validateTCPRouting(canary); err != nil {
+		return fmt.Errorf("VirtualService %s.%s %v", apexName, canary.Namespace, err)
+	}
 
 	// set hosts and add the ClusterIP service host if it doesn't exists
-	hosts := canary.Spec.Service.Hosts
+	var hosts []string
+	for _, group := range gatewayHosts(canary) {
+		hosts = appendUnique(hosts, group.Hosts...)
+	}
 	var hasServiceHost bool
 	for _, h := range hosts {
 		if h == apexName || h == "*" {
@@ -115,67 +137,23 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 		hosts = append(hosts, apexName)
 	}
 
-	// set gateways and add the mesh gateway if it doesn't exists
-	gateways := canary.Spec.Service.Gateways
-	var hasMeshGateway bool
-	for _, g := range gateways {
-		if g == "mesh" {
-			hasMeshGateway = true
-			break
-		}
-	}
-
-	// set default mesh gateway if no gateway is specified
-	if !hasMeshGateway && len(canary.Spec.Service.Gateways) == 0 {
-		gateways = append(gateways, "mesh")
-	}
-
 	// create destinations with primary weight 100% and canary weight 0%
-	canaryRoute := []istiov1alpha3.DestinationWeight{
-		makeDestination(canary, primaryName, 100),
-		makeDestination(canary, canaryName, 0),
-	}
-
 	newSpec := istiov1alpha3.VirtualServiceSpec{
 		Hosts:    hosts,
-		Gateways: gateways,
-		Http: []istiov1alpha3.HTTPRoute{
-			{
-				Match:      canary.Spec.Service.Match,
-				Rewrite:    canary.Spec.Service.Rewrite,
-				Timeout:    canary.Spec.Service.Timeout,
-				Retries:    canary.Spec.Service.Retries,
-				CorsPolicy: canary.Spec.Service.CorsPolicy,
-				Headers:    canary.Spec.Service.Headers,
-				Route:      canaryRoute,
-			},
-		},
+		Gateways: virtualServiceGateways(canary),
+		Http:     makeHTTPRoutes(canary, 100, 0, false),
+		Tcp:      makeTCPRoutes(canary, 100, 0),
 	}
 
-	if len(canary.GetAnalysis().Match) > 0 {
-		canaryMatch := mergeMatchConditions(canary.GetAnalysis().Match, canary.Spec.Service.Match)
-		newSpec.Http = []istiov1alpha3.HTTPRoute{
-			{
-				Match:      canaryMatch,
-				Rewrite:    canary.Spec.Service.Rewrite,
-				Timeout:    canary.Spec.Service.Timeout,
-				Retries:    canary.Spec.Service.Retries,
-				CorsPolicy: canary.Spec.Service.CorsPolicy,
-				Headers:    canary.Spec.Service.Headers,
-				Route:      canaryRoute,
-			},
-			{
-				Match:      canary.Spec.Service.Match,
-				Rewrite:    canary.Spec.Service.Rewrite,
-				Timeout:    canary.Spec.Service.Timeout,
-				Retries:    canary.Spec.Service.Retries,
-				CorsPolicy: canary.Spec.Service.CorsPolicy,
-				Headers:    canary.Spec.Service.Headers,
-				Route: []istiov1alpha3.DestinationWeight{
-					makeDestination(canary, primaryName, 100),
-				},
-			},
-		}
+	// the requests are routed only if a port of the service is an HTTP or gRPC port
+	if !hasHTTPPorts(canary) {
+		newSpec.Http = nil
+	}
+
+	// the hosts and gateways of a delegate are set by the virtual service that references it
+	if canary.Spec.Service.Delegation {
+		newSpec.Hosts = []string{}
+		newSpec.Gateways = nil
 	}
 
 	virtualService, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(apexName, metav1.GetOptions{})
@@ -214,7 +192,7 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 			newSpec,
 			virtualService.Spec,
 			cmpopts.IgnoreFields(istiov1alpha3.DestinationWeight{}, "Weight"),
-			cmpopts.IgnoreFields(istiov1alpha3.HTTPRoute{}, "Mirror"),
+			cmpopts.IgnoreFields(istiov1alpha3.HTTPRoute{}, "Mirror", "MirrorPercentage"),
 		); diff != "" {
 			vtClone := virtualService.DeepCopy()
 			vtClone.Spec = newSpec
@@ -250,26 +228,36 @@ func (ir *IstioRouter) GetRoutes(canary *flaggerv1.Canary) (
 		return
 	}
 
-	var httpRoute istiov1alpha3.HTTPRoute
+	var route []istiov1alpha3.DestinationWeight
 	for _, http := range vs.Spec.Http {
 		for _, r := range http.Route {
 			if r.Destination.Host == canaryName {
-				httpRoute = http
+				route = http.Route
+				mirrored = http.Mirror != nil && http.Mirror.Host != ""
 				break
 			}
 		}
 	}
-
-	for _, route := range httpRoute.Route {
-		if route.Destination.Host == primaryName {
-			primaryWeight = route.Weight
+	// the connections of a TCP service are routed by weight
+	for _, tcp := range vs.Spec.Tcp {
+		if route != nil {
+			break
 		}
-		if route.Destination.Host == canaryName {
-			canaryWeight = route.Weight
+		for _, r := range tcp.Route {
+			if r.Destination.Host == canaryName {
+				route = tcp.Route
+				break
+			}
 		}
 	}
-	if httpRoute.Mirror != nil && httpRoute.Mirror.Host != "" {
-		mirrored = true
+
+	for _, dst := range route {
+		if dst.Destination.Host == primaryName {
+			primaryWeight = dst.Weight
+		}
+		if dst.Destination.Host == canaryName {
+			canaryWeight = dst.Weight
+		}
 	}
 
 	if primaryWeight == 0 && canaryWeight == 0 {
@@ -287,7 +275,7 @@ func (ir *IstioRouter) SetRoutes(
 	canaryWeight int,
 	mirrored bool,
 ) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames()
 
 	vs, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(apexName, metav1.GetOptions{})
 	if err != nil {
@@ -299,9 +287,27 @@ func (ir *IstioRouter) SetRoutes(
 	}
 
 	vsCopy := vs.DeepCopy()
+	vsCopy.Spec.Http = nil
+	if hasHTTPPorts(canary) {
+		vsCopy.Spec.Http = makeHTTPRoutes(canary, primaryWeight, canaryWeight, mirrored)
+	}
+	vsCopy.Spec.Tcp = makeTCPRoutes(canary, primaryWeight, canaryWeight)
+
+	vs, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(vsCopy)
+	if err != nil {
+		return fmt.Errorf("VirtualService %s.%s update failed: %v", apexName, canary.Namespace, err)
+
+	}
+	return nil
+}
+
+// makeHTTPRoutes returns the weighted routes for the progressive canary or the header match routes for A/B testing,
+// when the service has more than one gateways group the routes are generated for each group
+func makeHTTPRoutes(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int, mirrored bool) []istiov1alpha3.HTTPRoute {
+	_, primaryName, canaryName := canary.GetServiceNames()
 
 	// weighted routing (progressive canary)
-	vsCopy.Spec.Http = []istiov1alpha3.HTTPRoute{
+	routes := []istiov1alpha3.HTTPRoute{
 		{
 			Match:      canary.Spec.Service.Match,
 			Rewrite:    canary.Spec.Service.Rewrite,
@@ -317,16 +323,24 @@ func (ir *IstioRouter) SetRoutes(
 	}
 
 	if mirrored {
-		vsCopy.Spec.Http[0].Mirror = &istiov1alpha3.Destination{
+		routes[0].Mirror = &istiov1alpha3.Destination{
 			Host: canaryName,
 		}
+		// shadow the traffic to a rewrite of the service running outside the cluster
+		if canary.GetAnalysis().MirrorTarget != nil {
+			routes[0].Mirror.Host = mirrorServiceName(canary)
+		}
+		// mirror only a fraction of the traffic
+		if w := canary.GetMirrorWeight(); w < 100 {
+			routes[0].MirrorPercentage = &istiov1alpha3.Percent{Value: float64(w)}
+		}
 	}
 
 	// fix routing (A/B testing)
 	if len(canary.GetAnalysis().Match) > 0 {
 		// merge the common routes with the canary ones
 		canaryMatch := mergeMatchConditions(canary.GetAnalysis().Match, canary.Spec.Service.Match)
-		vsCopy.Spec.Http = []istiov1alpha3.HTTPRoute{
+		routes = []istiov1alpha3.HTTPRoute{
 			{
 				Match:      canaryMatch,
 				Rewrite:    canary.Spec.Service.Rewrite,
@@ -351,27 +365,267 @@ func (ir *IstioRouter) SetRoutes(
 				},
 			},
 		}
+	} else if name, _ := canary.GetSessionCookie(); name != "" {
+		routes = makeSessionAffinityRoutes(canary, routes[0], primaryWeight, canaryWeight)
 	}
 
-	vs, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(vsCopy)
-	if err != nil {
-		return fmt.Errorf("VirtualService %s.%s update failed: %v", apexName, canary.Namespace, err)
+	groups := gatewayHosts(canary)
+	if len(groups) < 2 || canary.Spec.Service.Delegation {
+		return routes
+	}
 
+	// scope the routes to the gateways of each group
+	var scoped []istiov1alpha3.HTTPRoute
+	for _, group := range groups {
+		gateways := groupGateways(group)
+		for _, route := range routes {
+			r := route.DeepCopy()
+			if len(r.Match) == 0 {
+				r.Match = []istiov1alpha3.HTTPMatchRequest{{}}
+			}
+			for i := range r.Match {
+				r.Match[i].Gateways = gateways
+			}
+			scoped = append(scoped, *r)
+		}
+	}
+	return scoped
+}
+
+// makeTCPRoutes returns the weighted routes of the connections to each opaque TCP port of the service
+func makeTCPRoutes(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) []istiov1alpha3.TCPRoute {
+	_, primaryName, canaryName := canary.GetServiceNames()
+
+	var routes []istiov1alpha3.TCPRoute
+	if isTCPService(canary) {
+		routes = append(routes, istiov1alpha3.TCPRoute{
+			Match: []istiov1alpha3.L4MatchAttributes{
+				{Port: int(canary.Spec.Service.Port)},
+			},
+			Route: []istiov1alpha3.DestinationWeight{
+				makeDestination(canary, primaryName, primaryWeight),
+				makeDestination(canary, canaryName, canaryWeight),
+			},
+		})
+	}
+
+	// the services have more than one port, the destinations select the matched port
+	for _, p := range canary.Spec.Service.Ports {
+		if p.Protocol == corev1.ProtocolUDP || !isTCPPortName(p.Name) {
+			continue
+		}
+		routes = append(routes, istiov1alpha3.TCPRoute{
+			Match: []istiov1alpha3.L4MatchAttributes{
+				{Port: int(p.Port)},
+			},
+			Route: []istiov1alpha3.DestinationWeight{
+				{
+					Destination: istiov1alpha3.Destination{Host: primaryName, Port: &istiov1alpha3.PortSelector{Number: uint32(p.Port)}},
+					Weight:      primaryWeight,
+				},
+				{
+					Destination: istiov1alpha3.Destination{Host: canaryName, Port: &istiov1alpha3.PortSelector{Number: uint32(p.Port)}},
+					Weight:      canaryWeight,
+				},
+			},
+		})
+	}
+	return routes
+}
+
+// isTCPService returns true if the service port name selects an opaque TCP protocol,
+// Istio can't route the requests of these ports so the connections are shifted instead
+func isTCPService(canary *flaggerv1.Canary) bool {
+	return isTCPPortName(canary.Spec.Service.PortName)
+}
+
+// isTCPPortName returns true if the port name prefix selects an opaque TCP protocol
+func isTCPPortName(name string) bool {
+	protocol := strings.SplitN(strings.ToLower(name), "-", 2)[0]
+	switch protocol {
+	case "tcp", "mongo", "mysql", "redis":
+		return true
+	}
+	return false
+}
+
+// hasHTTPPorts returns true if the main port or one of the additional ports of the service
+// carries HTTP or gRPC requests, the HTTP routes apply to all these ports
+func hasHTTPPorts(canary *flaggerv1.Canary) bool {
+	if !isTCPService(canary) {
+		return true
+	}
+	for _, p := range canary.Spec.Service.Ports {
+		if p.Protocol != corev1.ProtocolUDP && !isTCPPortName(p.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTCPRouting checks that the analysis of a TCP service doesn't require HTTP routing
+func validateTCPRouting(canary *flaggerv1.Canary) error {
+	if hasHTTPPorts(canary) {
+		return nil
+	}
+	if len(canary.GetAnalysis().Match) > 0 {
+		return fmt.Errorf("A/B testing is not supported for the TCP port %s", canary.Spec.Service.PortName)
+	}
+	if canary.GetAnalysis().Mirror {
+		return fmt.Errorf("traffic mirroring is not supported for the TCP port %s", canary.Spec.Service.PortName)
+	}
+	if name, _ := canary.GetSessionCookie(); name != "" {
+		return fmt.Errorf("session affinity is not supported for the TCP port %s", canary.Spec.Service.PortName)
 	}
 	return nil
 }
 
-// mergeMatchConditions appends the URI match rules to canary conditions
-func mergeMatchConditions(canary, defaults []istiov1alpha3.HTTPMatchRequest) []istiov1alpha3.HTTPMatchRequest {
-	for i := range canary {
-		for _, d := range defaults {
-			if d.Uri != nil {
-				canary[i].Uri = d.Uri
+// makeSessionAffinityRoutes sets the session cookie on the canary responses and prepends a route
+// that sends the requests carrying the cookie to the canary, the users stay on the canary while it
+// receives traffic and return to primary once the canary weight is set to zero
+func makeSessionAffinityRoutes(canary *flaggerv1.Canary, weighted istiov1alpha3.HTTPRoute, primaryWeight int, canaryWeight int) []istiov1alpha3.HTTPRoute {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	name, maxAge := canary.GetSessionCookie()
+
+	weighted.Route[1].Headers = &istiov1alpha3.Headers{
+		Response: &istiov1alpha3.HeaderOperations{
+			Add: map[string]string{
+				"Set-Cookie": fmt.Sprintf("%s=%s; Max-Age=%d; Path=/", name, canaryName, maxAge),
+			},
+		},
+	}
+
+	stickyPrimary, stickyCanary := 100, 0
+	if canaryWeight > 0 {
+		stickyPrimary, stickyCanary = 0, 100
+	}
+	cookieMatch := []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"cookie": {
+					Regex: fmt.Sprintf("^(.*?;)?(%s=%s)(;.*)?$", regexp.QuoteMeta(name), regexp.QuoteMeta(canaryName)),
+				},
+			},
+		},
+	}
+	sticky := istiov1alpha3.HTTPRoute{
+		Match:      mergeMatchConditions(cookieMatch, canary.Spec.Service.Match),
+		Rewrite:    canary.Spec.Service.Rewrite,
+		Timeout:    canary.Spec.Service.Timeout,
+		Retries:    canary.Spec.Service.Retries,
+		CorsPolicy: canary.Spec.Service.CorsPolicy,
+		Headers:    canary.Spec.Service.Headers,
+		Route: []istiov1alpha3.DestinationWeight{
+			makeDestination(canary, primaryName, stickyPrimary),
+			makeDestination(canary, canaryName, stickyCanary),
+		},
+	}
+
+	return []istiov1alpha3.HTTPRoute{sticky, weighted}
+}
+
+// gatewayHosts returns the gateways groups of the service,
+// the service gateways and hosts are the first group if set or if there are no other groups
+func gatewayHosts(canary *flaggerv1.Canary) []flaggerv1.GatewayHosts {
+	var groups []flaggerv1.GatewayHosts
+	if len(canary.Spec.Service.Gateways) > 0 || len(canary.Spec.Service.Hosts) > 0 ||
+		len(canary.Spec.Service.GatewayHosts) == 0 {
+		groups = append(groups, flaggerv1.GatewayHosts{
+			Gateways: canary.Spec.Service.Gateways,
+			Hosts:    canary.Spec.Service.Hosts,
+		})
+	}
+	return append(groups, canary.Spec.Service.GatewayHosts...)
+}
+
+// groupGateways returns the gateways of a group, defaults to the mesh gateway
+func groupGateways(group flaggerv1.GatewayHosts) []string {
+	if len(group.Gateways) == 0 {
+		return []string{"mesh"}
+	}
+	return group.Gateways
+}
+
+// virtualServiceGateways returns the gateways of all the groups
+func virtualServiceGateways(canary *flaggerv1.Canary) []string {
+	var gateways []string
+	for _, group := range gatewayHosts(canary) {
+		gateways = appendUnique(gateways, groupGateways(group)...)
+	}
+	return gateways
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		var found bool
+		for _, item := range list {
+			if item == v {
+				found = true
+				break
 			}
 		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// mergeMatchConditions scopes the canary conditions to the URI rules of the service,
+// a condition with its own URI rule is kept as is and the others are copied for each service URI
+func mergeMatchConditions(canary, defaults []istiov1alpha3.HTTPMatchRequest) []istiov1alpha3.HTTPMatchRequest {
+	var uris []*istiov1alpha1.StringMatch
+	for _, d := range defaults {
+		if d.Uri != nil {
+			uris = append(uris, d.Uri)
+		}
+	}
+
+	var merged []istiov1alpha3.HTTPMatchRequest
+	for _, c := range canary {
+		if c.Uri != nil || len(uris) == 0 {
+			merged = append(merged, *c.DeepCopy())
+			continue
+		}
+		for _, uri := range uris {
+			u := *uri
+			m := c.DeepCopy()
+			m.Uri = &u
+			merged = append(merged, *m)
+		}
 	}
 
-	return canary
+	return merged
+}
+
+// validateMatchConditions checks that the A/B testing conditions are not empty
+// and that the regex values are valid RE2 expressions as required by Envoy
+func validateMatchConditions(matches []istiov1alpha3.HTTPMatchRequest) error {
+	for i, m := range matches {
+		if m.Uri == nil && m.Scheme == nil && m.Method == nil && m.Authority == nil &&
+			len(m.Headers) == 0 && m.Port == 0 && len(m.SourceLabels) == 0 {
+			return fmt.Errorf("analysis match condition %d is empty", i)
+		}
+		values := map[string]*istiov1alpha1.StringMatch{
+			"uri":       m.Uri,
+			"scheme":    m.Scheme,
+			"method":    m.Method,
+			"authority": m.Authority,
+		}
+		for name := range m.Headers {
+			header := m.Headers[name]
+			values["header "+name] = &header
+		}
+		for name, value := range values {
+			if value == nil || value.Regex == "" {
+				continue
+			}
+			if _, err := regexp.Compile(value.Regex); err != nil {
+				return fmt.Errorf("analysis match condition %d %s regex %q is invalid: %v", i, name, value.Regex, err)
+			}
+		}
+	}
+	return nil
 }
 
 // makeDestination returns a an destination weight for the specified host
@@ -385,8 +639,7 @@ func makeDestination(canary *flaggerv1.Canary, host string, weight int) istiov1a
 
 	// set destination port when an ingress gateway is specified
 	if canary.Spec.Service.PortDiscovery &&
-		len(canary.Spec.Service.Gateways) > 0 &&
-		canary.Spec.Service.Gateways[0] != "mesh" {
+		virtualServiceGateways(canary)[0] != "mesh" {
 		dest = istiov1alpha3.DestinationWeight{
 			Destination: istiov1alpha3.Destination{
 				Host: host,
@@ -0,0 +1,151 @@
+package router
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// mirrorServiceName returns the name of the service generated for the external mirror target
+func mirrorServiceName(canary *flaggerv1.Canary) string {
+	apexName, _, _ := canary.GetServiceNames()
+	return fmt.Sprintf("%s-mirror", apexName)
+}
+
+// reconcileMirrorService creates or updates the service that routes the mirrored traffic to the external target,
+// a DNS name is resolved with an ExternalName service while an IP address is the endpoint of a selectorless service
+func (ir *IstioRouter) reconcileMirrorService(canary *flaggerv1.Canary) error {
+	target := canary.GetAnalysis().MirrorTarget
+	name := mirrorServiceName(canary)
+
+	portName := canary.Spec.Service.PortName
+	if portName == "" {
+		portName = "http"
+	}
+	port := canary.GetMirrorTargetPort()
+
+	isIP := net.ParseIP(target.Host) != nil
+	svcSpec := corev1.ServiceSpec{
+		Type:         corev1.ServiceTypeExternalName,
+		ExternalName: target.Host,
+		Ports: []corev1.ServicePort{
+			{
+				Name:       portName,
+				Protocol:   corev1.ProtocolTCP,
+				Port:       port,
+				TargetPort: intstr.FromInt(int(port)),
+			},
+		},
+	}
+	if isIP {
+		svcSpec.Type = corev1.ServiceTypeClusterIP
+		svcSpec.ExternalName = ""
+	}
+
+	svc, err := ir.kubeClient.CoreV1().Services(canary.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("service %s.%s query error %v", name, canary.Namespace, err)
+	}
+
+	// the service type can't be switched between ExternalName and ClusterIP in place
+	if err == nil && svc.Spec.Type != svcSpec.Type {
+		err = ir.kubeClient.CoreV1().Services(canary.Namespace).Delete(name, &metav1.DeleteOptions{})
+		if err != nil {
+			return fmt.Errorf("service %s.%s delete error %v", name, canary.Namespace, err)
+		}
+		err = errors.NewNotFound(corev1.Resource("services"), name)
+	}
+
+	if errors.IsNotFound(err) {
+		svc = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       canary.Namespace,
+				OwnerReferences: mirrorOwnerReferences(canary),
+			},
+			Spec: svcSpec,
+		}
+		_, err = ir.kubeClient.CoreV1().Services(canary.Namespace).Create(svc)
+		if err != nil {
+			return fmt.Errorf("service %s.%s create error %v", name, canary.Namespace, err)
+		}
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Service %s.%s created", name, canary.Namespace)
+	} else if svc.Spec.ExternalName != svcSpec.ExternalName || !cmp.Equal(svc.Spec.Ports, svcSpec.Ports) {
+		svcClone := svc.DeepCopy()
+		svcClone.Spec.ExternalName = svcSpec.ExternalName
+		svcClone.Spec.Ports = svcSpec.Ports
+		_, err = ir.kubeClient.CoreV1().Services(canary.Namespace).Update(svcClone)
+		if err != nil {
+			return fmt.Errorf("service %s.%s update error %v", name, canary.Namespace, err)
+		}
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Service %s.%s updated", name, canary.Namespace)
+	}
+
+	if !isIP {
+		return nil
+	}
+
+	subsets := []corev1.EndpointSubset{
+		{
+			Addresses: []corev1.EndpointAddress{{IP: target.Host}},
+			Ports: []corev1.EndpointPort{
+				{
+					Name:     portName,
+					Protocol: corev1.ProtocolTCP,
+					Port:     port,
+				},
+			},
+		},
+	}
+
+	ep, err := ir.kubeClient.CoreV1().Endpoints(canary.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		ep = &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       canary.Namespace,
+				OwnerReferences: mirrorOwnerReferences(canary),
+			},
+			Subsets: subsets,
+		}
+		_, err = ir.kubeClient.CoreV1().Endpoints(canary.Namespace).Create(ep)
+		if err != nil {
+			return fmt.Errorf("endpoints %s.%s create error %v", name, canary.Namespace, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("endpoints %s.%s query error %v", name, canary.Namespace, err)
+	}
+
+	if !cmp.Equal(ep.Subsets, subsets) {
+		epClone := ep.DeepCopy()
+		epClone.Subsets = subsets
+		_, err = ir.kubeClient.CoreV1().Endpoints(canary.Namespace).Update(epClone)
+		if err != nil {
+			return fmt.Errorf("endpoints %s.%s update error %v", name, canary.Namespace, err)
+		}
+	}
+
+	return nil
+}
+
+func mirrorOwnerReferences(canary *flaggerv1.Canary) []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+			Group:   flaggerv1.SchemeGroupVersion.Group,
+			Version: flaggerv1.SchemeGroupVersion.Version,
+			Kind:    flaggerv1.CanaryKind,
+		}),
+	}
+}
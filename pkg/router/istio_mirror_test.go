@@ -0,0 +1,82 @@
+package router
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestIstioRouter_MirrorTarget(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	cd := mocks.canary.DeepCopy()
+	cd.Spec.CanaryAnalysis.Mirror = true
+	cd.Spec.CanaryAnalysis.MirrorTarget = &flaggerv1.CanaryMirrorTarget{
+		Host: "podinfo.legacy.example.com",
+		Port: 8080,
+	}
+
+	err := router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	svc, err := mocks.kubeClient.CoreV1().Services("default").Get("podinfo-mirror", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if svc.Spec.Type != corev1.ServiceTypeExternalName || svc.Spec.ExternalName != "podinfo.legacy.example.com" {
+		t.Errorf("Got service %s %s wanted ExternalName podinfo.legacy.example.com", svc.Spec.Type, svc.Spec.ExternalName)
+	}
+	if svc.Spec.Ports[0].Port != 8080 {
+		t.Errorf("Got port %v wanted %v", svc.Spec.Ports[0].Port, 8080)
+	}
+
+	err = router.SetRoutes(cd, 100, 0, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if m := vs.Spec.Http[0].Mirror; m == nil || m.Host != "podinfo-mirror" {
+		t.Errorf("Got mirror %v wanted podinfo-mirror", m)
+	}
+
+	// switch to a VM endpoint
+	cd.Spec.CanaryAnalysis.MirrorTarget.Host = "10.0.0.10"
+	err = router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	svc, err = mocks.kubeClient.CoreV1().Services("default").Get("podinfo-mirror", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if svc.Spec.Type != corev1.ServiceTypeClusterIP || svc.Spec.Selector != nil {
+		t.Errorf("Got service %s with selector %v wanted ClusterIP without selector", svc.Spec.Type, svc.Spec.Selector)
+	}
+
+	ep, err := mocks.kubeClient.CoreV1().Endpoints("default").Get("podinfo-mirror", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if ip := ep.Subsets[0].Addresses[0].IP; ip != "10.0.0.10" {
+		t.Errorf("Got endpoint %s wanted %s", ip, "10.0.0.10")
+	}
+	if port := ep.Subsets[0].Ports[0].Port; port != 8080 {
+		t.Errorf("Got endpoint port %v wanted %v", port, 8080)
+	}
+}
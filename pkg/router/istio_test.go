@@ -6,6 +6,8 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
 	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
 )
 
@@ -216,6 +218,71 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 	}
 }
 
+func TestIstioRouter_MirrorWeight(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	cd := mocks.canary.DeepCopy()
+	cd.Spec.CanaryAnalysis.Mirror = true
+	cd.Spec.CanaryAnalysis.MirrorWeight = 25
+
+	err := router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(cd, 100, 0, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// reconcile doesn't reset the mirror
+	err = router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	route := vs.Spec.Http[0]
+	if route.Mirror == nil || route.Mirror.Host != "podinfo-canary" {
+		t.Fatalf("Got mirror %v wanted podinfo-canary", route.Mirror)
+	}
+	if route.MirrorPercentage == nil || route.MirrorPercentage.Value != 25 {
+		t.Errorf("Got mirror percentage %v wanted 25", route.MirrorPercentage)
+	}
+
+	_, _, m, err := router.GetRoutes(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !m {
+		t.Errorf("Got mirrored %v wanted true", m)
+	}
+
+	// stopping the mirror removes the percentage
+	err = router.SetRoutes(cd, 90, 10, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if vs.Spec.Http[0].Mirror != nil || vs.Spec.Http[0].MirrorPercentage != nil {
+		t.Errorf("Got mirror %v percentage %v wanted nil", vs.Spec.Http[0].Mirror, vs.Spec.Http[0].MirrorPercentage)
+	}
+}
+
 func TestIstioRouter_GetRoutes(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &IstioRouter{
@@ -477,3 +544,466 @@ func TestIstioRouter_GatewayPort(t *testing.T) {
 		t.Fatalf("Got port %v wanted %v", port, mocks.canary.Spec.Service.Port)
 	}
 }
+
+func TestIstioRouter_GatewayHosts(t *testing.T) {
+	canary := newTestCanary()
+	canary.Spec.Service.Gateways = nil
+	canary.Spec.Service.GatewayHosts = []flaggerv1.GatewayHosts{
+		{
+			Gateways: []string{"mesh"},
+		},
+		{
+			Gateways: []string{"public-gateway.istio"},
+			Hosts:    []string{"app.example.com"},
+		},
+	}
+	mocks := newFixture(canary)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if hosts := fmt.Sprint(vs.Spec.Hosts); hosts != "[app.example.com podinfo]" {
+		t.Errorf("Got hosts %v wanted %v", hosts, "[app.example.com podinfo]")
+	}
+	if gateways := fmt.Sprint(vs.Spec.Gateways); gateways != "[mesh public-gateway.istio]" {
+		t.Errorf("Got gateways %v wanted %v", gateways, "[mesh public-gateway.istio]")
+	}
+
+	if len(vs.Spec.Http) != 2 {
+		t.Fatalf("Got Istio VS Http %v wanted %v", len(vs.Spec.Http), 2)
+	}
+	for i, gateway := range []string{"mesh", "public-gateway.istio"} {
+		http := vs.Spec.Http[i]
+		if gateways := fmt.Sprint(http.Match[0].Gateways); gateways != fmt.Sprintf("[%s]", gateway) {
+			t.Errorf("Got route %v gateways %v wanted [%v]", i, gateways, gateway)
+		}
+		if http.Match[0].Uri == nil || http.Match[0].Uri.Prefix != "/podinfo" {
+			t.Errorf("Got route %v URI match %v wanted prefix /podinfo", i, http.Match[0].Uri)
+		}
+		if http.Route[0].Weight != 60 || http.Route[1].Weight != 40 {
+			t.Errorf("Got route %v weights %v %v wanted %v %v", i, http.Route[0].Weight, http.Route[1].Weight, 60, 40)
+		}
+	}
+
+	// the gateway scoped matches don't change the service spec
+	if len(mocks.canary.Spec.Service.Match[0].Gateways) > 0 {
+		t.Errorf("Got service match gateways %v wanted none", mocks.canary.Spec.Service.Match[0].Gateways)
+	}
+}
+
+func TestIstioRouter_Delegation(t *testing.T) {
+	canary := newTestCanary()
+	canary.Spec.Service.Delegation = true
+	mocks := newFixture(canary)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(mocks.canary, 50, 50, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(vs.Spec.Hosts) > 0 {
+		t.Errorf("Got delegate hosts %v wanted none", vs.Spec.Hosts)
+	}
+	if len(vs.Spec.Gateways) > 0 {
+		t.Errorf("Got delegate gateways %v wanted none", vs.Spec.Gateways)
+	}
+
+	if len(vs.Spec.Http) != 1 {
+		t.Fatalf("Got Istio VS Http %v wanted %v", len(vs.Spec.Http), 1)
+	}
+	if w := vs.Spec.Http[0].Route[1].Weight; w != 50 {
+		t.Errorf("Got canary weight %v wanted %v", w, 50)
+	}
+}
+
+func TestIstioRouter_ABTestMatch(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	cd := mocks.abtest.DeepCopy()
+	cd.Spec.Service.Match = []istiov1alpha3.HTTPMatchRequest{
+		{Uri: &istiov1alpha1.StringMatch{Prefix: "/api"}},
+		{Uri: &istiov1alpha1.StringMatch{Prefix: "/v2"}},
+	}
+	cd.Spec.CanaryAnalysis.Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"cookie":      {Regex: "^(.*?;)?(canary=always)(;.*)?$"},
+				"x-user-type": {Exact: "test"},
+			},
+		},
+		{
+			Uri: &istiov1alpha1.StringMatch{Prefix: "/beta"},
+		},
+	}
+
+	err := router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("abtest", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the header condition is copied for each service URI
+	match := vs.Spec.Http[0].Match
+	if len(match) != 3 {
+		t.Fatalf("Got match conditions %v wanted %v", len(match), 3)
+	}
+	for i, prefix := range []string{"/api", "/v2", "/beta"} {
+		if match[i].Uri == nil || match[i].Uri.Prefix != prefix {
+			t.Errorf("Got match %v uri %v wanted prefix %s", i, match[i].Uri, prefix)
+		}
+	}
+	if len(match[0].Headers) != 2 || match[0].Headers["cookie"].Regex == "" {
+		t.Errorf("Got match headers %v wanted cookie regex and x-user-type", match[0].Headers)
+	}
+
+	// the canary spec is not modified
+	if cd.Spec.CanaryAnalysis.Match[0].Uri != nil {
+		t.Errorf("Got canary match uri %v wanted nil", cd.Spec.CanaryAnalysis.Match[0].Uri)
+	}
+
+	// invalid regex
+	cd.Spec.CanaryAnalysis.Match[0].Headers["cookie"] = istiov1alpha1.StringMatch{Regex: "^(canary"}
+	err = router.Reconcile(cd)
+	if err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+
+	// empty condition
+	cd.Spec.CanaryAnalysis.Match = []istiov1alpha3.HTTPMatchRequest{{}}
+	err = router.Reconcile(cd)
+	if err == nil {
+		t.Error("Expected error for empty match condition")
+	}
+}
+
+func TestIstioRouter_RetriesTimeoutCORS(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	remote := false
+	cd := mocks.abtest.DeepCopy()
+	cd.Spec.Service.Timeout = "30s"
+	cd.Spec.Service.Retries = &istiov1alpha3.HTTPRetry{
+		Attempts:              3,
+		PerTryTimeout:         "5s",
+		RetryOn:               "gateway-error,connect-failure",
+		RetryRemoteLocalities: &remote,
+	}
+	cd.Spec.Service.CorsPolicy = &istiov1alpha3.CorsPolicy{
+		AllowOrigins: []istiov1alpha1.StringMatch{
+			{Exact: "https://example.com"},
+			{Regex: "https://.*\\.example\\.com"},
+		},
+		AllowMethods: []string{"GET", "POST"},
+	}
+
+	err := router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(cd, 0, 100, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("abtest", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the policies are set on the A/B testing and the default routes
+	if len(vs.Spec.Http) != 2 {
+		t.Fatalf("Got HTTPRoute %v wanted %v", len(vs.Spec.Http), 2)
+	}
+	for i, route := range vs.Spec.Http {
+		if route.Timeout != "30s" {
+			t.Errorf("Got route %v timeout %v wanted %v", i, route.Timeout, "30s")
+		}
+		if route.Retries == nil || route.Retries.Attempts != 3 ||
+			route.Retries.RetryRemoteLocalities == nil || *route.Retries.RetryRemoteLocalities {
+			t.Errorf("Got route %v retries %+v", i, route.Retries)
+		}
+		if route.CorsPolicy == nil || len(route.CorsPolicy.AllowOrigins) != 2 {
+			t.Errorf("Got route %v CORS policy %+v", i, route.CorsPolicy)
+		}
+	}
+
+	// the policy changes are applied to the existing virtual service
+	cd.Spec.Service.Retries.Attempts = 5
+	err = router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("abtest", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if vs.Spec.Http[0].Retries.Attempts != 5 {
+		t.Errorf("Got retries attempts %v wanted %v", vs.Spec.Http[0].Retries.Attempts, 5)
+	}
+}
+
+func TestIstioRouter_SessionAffinity(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.GetAnalysis().SessionAffinity = &flaggerv1.SessionAffinity{
+		CookieName: "canary-session",
+		MaxAge:     3600,
+	}
+
+	err := router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(canary, 90, 10, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// reconcile doesn't reset the weights
+	err = router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, _, err := router.GetRoutes(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 90 || c != 10 {
+		t.Errorf("Got primary=%v canary=%v wanted primary=90 canary=10", p, c)
+	}
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(vs.Spec.Http) != 2 {
+		t.Fatalf("Got %v HTTP routes wanted 2", len(vs.Spec.Http))
+	}
+
+	// the requests with the cookie are routed to the canary
+	sticky := vs.Spec.Http[0]
+	regex := sticky.Match[0].Headers["cookie"].Regex
+	if regex != "^(.*?;)?(canary-session=podinfo-canary)(;.*)?$" {
+		t.Errorf("Got cookie match %s", regex)
+	}
+	if sticky.Route[0].Weight != 0 || sticky.Route[1].Weight != 100 {
+		t.Errorf("Got sticky weights primary=%v canary=%v wanted primary=0 canary=100",
+			sticky.Route[0].Weight, sticky.Route[1].Weight)
+	}
+
+	// the canary responses set the cookie
+	setCookie := vs.Spec.Http[1].Route[1].Headers.Response.Add["Set-Cookie"]
+	if setCookie != "canary-session=podinfo-canary; Max-Age=3600; Path=/" {
+		t.Errorf("Got Set-Cookie %s", setCookie)
+	}
+
+	// the users return to primary after promotion
+	err = router.SetRoutes(canary, 100, 0, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	sticky = vs.Spec.Http[0]
+	if sticky.Route[0].Weight != 100 || sticky.Route[1].Weight != 0 {
+		t.Errorf("Got sticky weights primary=%v canary=%v wanted primary=100 canary=0",
+			sticky.Route[0].Weight, sticky.Route[1].Weight)
+	}
+}
+
+func TestIstioRouter_TCPRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	cd := mocks.canary.DeepCopy()
+	cd.Spec.Service.PortName = "tcp-db"
+
+	err := router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(vs.Spec.Http) != 0 {
+		t.Errorf("Got %v HTTP routes wanted none", len(vs.Spec.Http))
+	}
+	if len(vs.Spec.Tcp) != 1 || vs.Spec.Tcp[0].Match[0].Port != 9898 {
+		t.Fatalf("Got TCP routes %+v wanted one route for port 9898", vs.Spec.Tcp)
+	}
+
+	err = router.SetRoutes(cd, 70, 30, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, m, err := router.GetRoutes(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 70 || c != 30 || m {
+		t.Errorf("Got primary %v canary %v mirrored %v wanted 70 30 false", p, c, m)
+	}
+
+	// reconcile keeps the weights
+	err = router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	_, c, _, err = router.GetRoutes(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c != 30 {
+		t.Errorf("Got canary weight %v wanted %v", c, 30)
+	}
+
+	// HTTP routing isn't available for the connections
+	cd.Spec.CanaryAnalysis.Mirror = true
+	err = router.Reconcile(cd)
+	if err == nil {
+		t.Errorf("Expected error for traffic mirroring of a TCP port")
+	}
+}
+
+func TestIstioRouter_MultiplePorts(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	cd := mocks.canary.DeepCopy()
+	cd.Spec.Service.Ports = []flaggerv1.CanaryServicePort{
+		{Name: "grpc", Port: 9999},
+		{Name: "tcp-admin", Port: 9090},
+	}
+
+	err := router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(cd, 70, 30, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the HTTP routes apply to the http and grpc ports
+	if len(vs.Spec.Http) != 1 || vs.Spec.Http[0].Route[1].Weight != 30 {
+		t.Errorf("Got HTTP routes %+v wanted one route with canary weight 30", vs.Spec.Http)
+	}
+
+	// the connections of the TCP port are routed by weight to the same port
+	if len(vs.Spec.Tcp) != 1 || vs.Spec.Tcp[0].Match[0].Port != 9090 {
+		t.Fatalf("Got TCP routes %+v wanted one route for port 9090", vs.Spec.Tcp)
+	}
+	for _, dst := range vs.Spec.Tcp[0].Route {
+		if dst.Destination.Port == nil || dst.Destination.Port.Number != 9090 {
+			t.Errorf("Got TCP destination %+v wanted port 9090", dst.Destination)
+		}
+		if dst.Destination.Host == "podinfo-canary" && dst.Weight != 30 {
+			t.Errorf("Got TCP canary weight %v wanted %v", dst.Weight, 30)
+		}
+	}
+
+	p, c, _, err := router.GetRoutes(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 70 || c != 30 {
+		t.Errorf("Got primary %v canary %v wanted 70 30", p, c)
+	}
+
+	// the grpc port is routed as HTTP/2 next to the main TCP port
+	cd.Spec.Service.PortName = "tcp-db"
+	err = router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(vs.Spec.Http) != 1 || len(vs.Spec.Tcp) != 2 {
+		t.Errorf("Got %v HTTP routes and %v TCP routes wanted 1 and 2", len(vs.Spec.Http), len(vs.Spec.Tcp))
+	}
+}
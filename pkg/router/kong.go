@@ -0,0 +1,406 @@
+package router
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	kongv1 "github.com/weaveworks/flagger/pkg/apis/kong/v1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+const (
+	kongPluginsAnnotation       = "konghq.com/plugins"
+	kongHeadersAnnotationPrefix = "konghq.com/headers."
+)
+
+// KongRouter is managing Kong canary plugins and ingresses
+type KongRouter struct {
+	kubeClient kubernetes.Interface
+	kongClient clientset.Interface
+	logger     *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the canary plugin and attaches it to the ingress,
+// for A/B testing it creates or updates the header based canary ingress
+func (kr *KongRouter) Reconcile(canary *flaggerv1.Canary) error {
+	if canary.Spec.IngressRef == nil || canary.Spec.IngressRef.Name == "" {
+		return fmt.Errorf("ingress selector is empty")
+	}
+
+	apexName, _, _ := canary.GetServiceNames()
+
+	ingress, err := kr.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Get(canary.Spec.IngressRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if !kr.hasBackend(ingress, apexName) {
+		return fmt.Errorf("backend %s not found in ingress %s", apexName, canary.Spec.IngressRef.Name)
+	}
+
+	if err := kr.reconcilePlugin(canary); err != nil {
+		return err
+	}
+
+	if err := kr.attachPlugin(canary, ingress); err != nil {
+		return err
+	}
+
+	if len(canary.GetAnalysis().Match) > 0 {
+		return kr.reconcileCanaryIngress(canary, ingress)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the canary plugin percentage or,
+// for A/B testing, if the canary ingress is routing to the canary service
+func (kr *KongRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	// A/B testing
+	if len(canary.GetAnalysis().Match) > 0 {
+		_, _, canaryName := canary.GetServiceNames()
+		canaryIngressName := fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name)
+		canaryIngress, err := kr.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Get(canaryIngressName, metav1.GetOptions{})
+		if err != nil {
+			return 0, 0, false, fmt.Errorf("ingress %s query error %v", canaryIngressName, err)
+		}
+
+		if kr.hasBackend(canaryIngress, canaryName) {
+			return 0, 100, false, nil
+		}
+		return 100, 0, false, nil
+	}
+
+	// Canary
+	pluginName := kr.pluginName(canary)
+	plugin, err := kr.kongClient.ConfigurationV1().KongPlugins(canary.Namespace).Get(pluginName, metav1.GetOptions{})
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("KongPlugin %s.%s query error %v", pluginName, canary.Namespace, err)
+	}
+
+	canaryWeight = plugin.Config.Percentage
+	primaryWeight = 100 - canaryWeight
+	mirrored = false
+	return
+}
+
+// SetRoutes updates the canary plugin percentage or,
+// for A/B testing, switches the canary ingress backend between the primary and canary services
+func (kr *KongRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	// A/B testing
+	if len(canary.GetAnalysis().Match) > 0 {
+		canaryIngressName := fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name)
+		canaryIngress, err := kr.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Get(canaryIngressName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("ingress %s query error %v", canaryIngressName, err)
+		}
+
+		_, primaryName, canaryName := canary.GetServiceNames()
+		from, to := canaryName, primaryName
+		if canaryWeight > 0 {
+			from, to = primaryName, canaryName
+		}
+
+		iClone := canaryIngress.DeepCopy()
+		iClone.Spec = kr.replaceBackend(iClone.Spec, from, to)
+		_, err = kr.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Update(iClone)
+		if err != nil {
+			return fmt.Errorf("ingress %s update error %v", canaryIngressName, err)
+		}
+		return nil
+	}
+
+	// Canary
+	pluginName := kr.pluginName(canary)
+	plugin, err := kr.kongClient.ConfigurationV1().KongPlugins(canary.Namespace).Get(pluginName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("KongPlugin %s.%s query error %v", pluginName, canary.Namespace, err)
+	}
+
+	pClone := plugin.DeepCopy()
+	pClone.Config.Percentage = canaryWeight
+	_, err = kr.kongClient.ConfigurationV1().KongPlugins(canary.Namespace).Update(pClone)
+	if err != nil {
+		return fmt.Errorf("KongPlugin %s.%s update error %v", pluginName, canary.Namespace, err)
+	}
+
+	return nil
+}
+
+// reconcilePlugin creates the canary plugin that routes a percentage of the requests
+// to the canary service or updates its upstream while keeping the current percentage
+func (kr *KongRouter) reconcilePlugin(canary *flaggerv1.Canary) error {
+	_, _, canaryName := canary.GetServiceNames()
+	pluginName := kr.pluginName(canary)
+
+	newConfig := kongv1.CanaryConfig{
+		UpstreamHost: fmt.Sprintf("%s.%s.svc", canaryName, canary.Namespace),
+		UpstreamPort: canary.Spec.Service.Port,
+		Hash:         "none",
+	}
+
+	plugin, err := kr.kongClient.ConfigurationV1().KongPlugins(canary.Namespace).Get(pluginName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		plugin = &kongv1.KongPlugin{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pluginName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			PluginName: "canary",
+			Config:     newConfig,
+		}
+
+		_, err = kr.kongClient.ConfigurationV1().KongPlugins(canary.Namespace).Create(plugin)
+		if err != nil {
+			return fmt.Errorf("KongPlugin %s.%s create error %v", pluginName, canary.Namespace, err)
+		}
+
+		kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("KongPlugin %s.%s created", pluginName, canary.Namespace)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("KongPlugin %s.%s query error %v", pluginName, canary.Namespace, err)
+	}
+
+	newConfig.Percentage = plugin.Config.Percentage
+	if diff := cmp.Diff(newConfig, plugin.Config); diff != "" || plugin.PluginName != "canary" {
+		pClone := plugin.DeepCopy()
+		pClone.PluginName = "canary"
+		pClone.Config = newConfig
+
+		_, err = kr.kongClient.ConfigurationV1().KongPlugins(canary.Namespace).Update(pClone)
+		if err != nil {
+			return fmt.Errorf("KongPlugin %s.%s update error %v", pluginName, canary.Namespace, err)
+		}
+
+		kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("KongPlugin %s.%s updated", pluginName, canary.Namespace)
+	}
+
+	return nil
+}
+
+// attachPlugin adds the canary plugin to the plugins annotation of the ingress
+func (kr *KongRouter) attachPlugin(canary *flaggerv1.Canary, ingress *v1beta1.Ingress) error {
+	pluginName := kr.pluginName(canary)
+
+	var plugins []string
+	for _, p := range strings.Split(ingress.Annotations[kongPluginsAnnotation], ",") {
+		p = strings.TrimSpace(p)
+		if p == pluginName {
+			return nil
+		}
+		if p != "" {
+			plugins = append(plugins, p)
+		}
+	}
+
+	iClone := ingress.DeepCopy()
+	if iClone.Annotations == nil {
+		iClone.Annotations = make(map[string]string)
+	}
+	iClone.Annotations[kongPluginsAnnotation] = strings.Join(append(plugins, pluginName), ",")
+
+	_, err := kr.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Update(iClone)
+	if err != nil {
+		return fmt.Errorf("ingress %s update error %v", ingress.Name, err)
+	}
+
+	kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("KongPlugin %s attached to ingress %s", pluginName, ingress.Name)
+	return nil
+}
+
+// reconcileCanaryIngress clones the ingress into <ingress>-canary with the header match annotations,
+// the clone routes to the primary service until the A/B test starts
+func (kr *KongRouter) reconcileCanaryIngress(canary *flaggerv1.Canary, ingress *v1beta1.Ingress) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	canaryIngressName := fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name)
+
+	headers, err := kr.makeHeaderAnnotations(canary)
+	if err != nil {
+		return err
+	}
+
+	// copy the ingress annotations without the canary plugin
+	annotations := make(map[string]string)
+	for k, v := range ingress.Annotations {
+		if !strings.HasPrefix(k, kongHeadersAnnotationPrefix) &&
+			!strings.Contains(k, "kubectl.kubernetes.io/last-applied-configuration") {
+			annotations[k] = v
+		}
+	}
+	if plugins := kr.removePlugin(annotations[kongPluginsAnnotation], kr.pluginName(canary)); plugins != "" {
+		annotations[kongPluginsAnnotation] = plugins
+	} else {
+		delete(annotations, kongPluginsAnnotation)
+	}
+	for k, v := range headers {
+		annotations[k] = v
+	}
+
+	canaryIngress, err := kr.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Get(canaryIngressName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		ing := &v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      canaryIngressName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+				Annotations: annotations,
+				Labels:      ingress.Labels,
+			},
+			Spec: kr.replaceBackend(ingress.Spec, apexName, primaryName),
+		}
+
+		_, err := kr.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Create(ing)
+		if err != nil {
+			return fmt.Errorf("ingress %s create error %v", canaryIngressName, err)
+		}
+
+		kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Ingress %s.%s created", ing.GetName(), canary.Namespace)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("ingress %s query error %v", canaryIngressName, err)
+	}
+
+	// keep routing to the canary service if the A/B test is running
+	backend := primaryName
+	if kr.hasBackend(canaryIngress, canaryName) {
+		backend = canaryName
+	}
+	newSpec := kr.replaceBackend(ingress.Spec, apexName, backend)
+
+	specDiff := cmp.Diff(newSpec, canaryIngress.Spec)
+	annotationsDiff := cmp.Diff(annotations, canaryIngress.Annotations)
+	if specDiff != "" || annotationsDiff != "" {
+		iClone := canaryIngress.DeepCopy()
+		iClone.Spec = newSpec
+		iClone.Annotations = annotations
+
+		_, err := kr.kubeClient.ExtensionsV1beta1().Ingresses(canary.Namespace).Update(iClone)
+		if err != nil {
+			return fmt.Errorf("ingress %s update error %v", canaryIngressName, err)
+		}
+
+		kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Ingress %s updated", canaryIngressName)
+	}
+
+	return nil
+}
+
+// makeHeaderAnnotations converts the A/B matches into Kong header annotations,
+// exact values are matched as is and regex values are prefixed with ~*
+func (kr *KongRouter) makeHeaderAnnotations(canary *flaggerv1.Canary) (map[string]string, error) {
+	values := make(map[string][]string)
+	for _, m := range canary.GetAnalysis().Match {
+		for k, v := range m.Headers {
+			if strings.ToLower(k) == "cookie" {
+				return nil, fmt.Errorf("cookie matching is not supported by Kong")
+			}
+
+			switch {
+			case v.Exact != "":
+				values[k] = append(values[k], v.Exact)
+			case v.Regex != "":
+				values[k] = append(values[k], "~*"+v.Regex)
+			default:
+				return nil, fmt.Errorf("header %s match must be exact or regex", k)
+			}
+		}
+	}
+
+	if len(values) < 1 {
+		return nil, fmt.Errorf("no header matches found in canary %s.%s", canary.Name, canary.Namespace)
+	}
+
+	res := make(map[string]string)
+	for k, v := range values {
+		sort.Strings(v)
+		res[kongHeadersAnnotationPrefix+k] = strings.Join(v, ",")
+	}
+	return res, nil
+}
+
+func (kr *KongRouter) pluginName(canary *flaggerv1.Canary) string {
+	apexName, _, _ := canary.GetServiceNames()
+	return fmt.Sprintf("%s-canary", apexName)
+}
+
+func (kr *KongRouter) removePlugin(plugins string, name string) string {
+	var res []string
+	for _, p := range strings.Split(plugins, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" && p != name {
+			res = append(res, p)
+		}
+	}
+	return strings.Join(res, ",")
+}
+
+func (kr *KongRouter) hasBackend(ingress *v1beta1.Ingress, serviceName string) bool {
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.ServiceName == serviceName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (kr *KongRouter) replaceBackend(spec v1beta1.IngressSpec, from string, to string) v1beta1.IngressSpec {
+	res := *spec.DeepCopy()
+	for k, rule := range res.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for x, path := range rule.HTTP.Paths {
+			if path.Backend.ServiceName == from {
+				res.Rules[k].HTTP.Paths[x].Backend.ServiceName = to
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,217 @@
+package router
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+func TestKongRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KongRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+		kongClient: mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	plugin, err := router.kongClient.ConfigurationV1().KongPlugins("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if plugin.PluginName != "canary" {
+		t.Errorf("Got plugin %s wanted %s", plugin.PluginName, "canary")
+	}
+
+	if plugin.Config.UpstreamHost != "podinfo-canary.default.svc" {
+		t.Errorf("Got upstream host %s wanted %s", plugin.Config.UpstreamHost, "podinfo-canary.default.svc")
+	}
+
+	if plugin.Config.UpstreamPort != 9898 {
+		t.Errorf("Got upstream port %v wanted %v", plugin.Config.UpstreamPort, 9898)
+	}
+
+	if plugin.Config.Percentage != 0 {
+		t.Errorf("Got percentage %v wanted %v", plugin.Config.Percentage, 0)
+	}
+
+	ingress, err := router.kubeClient.ExtensionsV1beta1().Ingresses("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if ingress.Annotations[kongPluginsAnnotation] != "podinfo-canary" {
+		t.Errorf("Got plugins annotation %s wanted %s", ingress.Annotations[kongPluginsAnnotation], "podinfo-canary")
+	}
+
+	// test idempotency
+	err = router.Reconcile(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ingress, err = router.kubeClient.ExtensionsV1beta1().Ingresses("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if ingress.Annotations[kongPluginsAnnotation] != "podinfo-canary" {
+		t.Errorf("Got plugins annotation %s wanted %s", ingress.Annotations[kongPluginsAnnotation], "podinfo-canary")
+	}
+}
+
+func TestKongRouter_GetSetRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KongRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+		kongClient: mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(mocks.ingressCanary, 60, 40, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, m, err := router.GetRoutes(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if p != 60 {
+		t.Errorf("Got primary weight %v wanted %v", p, 60)
+	}
+
+	if c != 40 {
+		t.Errorf("Got canary weight %v wanted %v", c, 40)
+	}
+
+	if m != false {
+		t.Errorf("Got mirrored %v wanted %v", m, false)
+	}
+
+	// test percentage is kept on reconcile
+	err = router.Reconcile(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, c, _, err = router.GetRoutes(mocks.ingressCanary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if c != 40 {
+		t.Errorf("Got canary weight %v wanted %v", c, 40)
+	}
+}
+
+func TestKongRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KongRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+		kongClient: mocks.meshClient,
+	}
+
+	canary := mocks.ingressCanary.DeepCopy()
+	canary.Spec.CanaryAnalysis.Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-user-type": {
+					Exact: "test",
+				},
+			},
+		},
+	}
+
+	err := router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	inCanary, err := router.kubeClient.ExtensionsV1beta1().Ingresses("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if inCanary.Annotations["konghq.com/headers.x-user-type"] != "test" {
+		t.Errorf("Got header annotation %s wanted %s", inCanary.Annotations["konghq.com/headers.x-user-type"], "test")
+	}
+
+	if _, ok := inCanary.Annotations[kongPluginsAnnotation]; ok {
+		t.Errorf("Canary ingress should not have the canary plugin")
+	}
+
+	if !router.hasBackend(inCanary, "podinfo-primary") {
+		t.Errorf("Canary ingress backend should be podinfo-primary")
+	}
+
+	err = router.SetRoutes(canary, 0, 100, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, _, err := router.GetRoutes(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if p != 0 || c != 100 {
+		t.Errorf("Got weights %v/%v wanted %v/%v", p, c, 0, 100)
+	}
+
+	// test the backend is kept on reconcile
+	err = router.Reconcile(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, c, _, err = router.GetRoutes(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if c != 100 {
+		t.Errorf("Got canary weight %v wanted %v", c, 100)
+	}
+
+	err = router.SetRoutes(canary, 100, 0, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, _, err = router.GetRoutes(canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if p != 100 || c != 0 {
+		t.Errorf("Got weights %v/%v wanted %v/%v", p, c, 100, 0)
+	}
+
+	// test cookie matching is rejected
+	canary.Spec.CanaryAnalysis.Match[0].Headers = map[string]istiov1alpha1.StringMatch{
+		"cookie": {
+			Regex: "^(.*?;)?(canary=always)(;.*)?$",
+		},
+	}
+
+	err = router.Reconcile(canary)
+	if err == nil {
+		t.Errorf("Expected error for cookie matching")
+	}
+}
@@ -11,3 +11,10 @@ type KubernetesRouter interface {
 	// Reconcile creates or updates the main service
 	Reconcile(canary *flaggerv1.Canary) error
 }
+
+// KubernetesFinalizer is implemented by the Kubernetes routers that can select the target pods
+// with the main service when the canary is deleted
+type KubernetesFinalizer interface {
+	// Finalize points the main service to the target pods
+	Finalize(canary *flaggerv1.Canary) error
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// KubernetesClusterRouter is managing the ClusterIP services of a multi-cluster canary routed by a shared gateway.
+// The primary is the target deployment of the Flagger cluster, so the apex and primary services select the
+// target pods of the Flagger cluster while the canary service selects the target pods of the canary cluster.
+// Each service exists in a single cluster, otherwise the mesh would merge the endpoints of both clusters.
+type KubernetesClusterRouter struct {
+	primary *KubernetesDeploymentRouter
+	canary  *KubernetesDeploymentRouter
+}
+
+// Initialize creates the primary service in the Flagger cluster and the canary service in the canary cluster
+func (c *KubernetesClusterRouter) Initialize(canary *flaggerv1.Canary) error {
+	_, primaryName, canaryName := canary.GetServiceNames()
+
+	// canary svc
+	err := c.canary.reconcileService(canary, canaryName, canary.Spec.TargetRef.Name, canary.Spec.Service.Canary)
+	if err != nil {
+		return err
+	}
+
+	// primary svc
+	err = c.primary.reconcileService(canary, primaryName, canary.Spec.TargetRef.Name, canary.Spec.Service.Primary)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile creates or updates the main service in the Flagger cluster
+func (c *KubernetesClusterRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+
+	// main svc
+	err := c.primary.reconcileService(canary, apexName, canary.Spec.TargetRef.Name, canary.Spec.Service.Apex)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
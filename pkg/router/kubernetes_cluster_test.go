@@ -0,0 +1,57 @@
+package router
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestKubernetesClusterRouter_Initialize(t *testing.T) {
+	mocks := newFixture(nil)
+	clusterClient := fake.NewSimpleClientset()
+	factory := NewFactory(nil, mocks.kubeClient, mocks.flaggerClient, "", mocks.logger, mocks.meshClient)
+	router := factory.ClusterKubernetesRouter(clusterClient, "app", nil)
+
+	err := router.Initialize(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the apex and primary services select the target pods of the Flagger cluster
+	for _, name := range []string{"podinfo", "podinfo-primary"} {
+		svc, err := mocks.kubeClient.CoreV1().Services("default").Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if svc.Spec.Selector["app"] != "podinfo" {
+			t.Errorf("Got %s selector %v wanted app=podinfo", name, svc.Spec.Selector)
+		}
+		if len(svc.OwnerReferences) != 1 {
+			t.Errorf("Got %s owner references %v wanted the canary", name, svc.OwnerReferences)
+		}
+		if _, err := clusterClient.CoreV1().Services("default").Get(name, metav1.GetOptions{}); err == nil {
+			t.Errorf("Service %s should not be created in the canary cluster", name)
+		}
+	}
+
+	// the canary service selects the target pods of the canary cluster
+	svc, err := clusterClient.CoreV1().Services("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if svc.Spec.Selector["app"] != "podinfo" {
+		t.Errorf("Got podinfo-canary selector %v wanted app=podinfo", svc.Spec.Selector)
+	}
+	if len(svc.OwnerReferences) != 0 {
+		t.Errorf("Got podinfo-canary owner references %v wanted none", svc.OwnerReferences)
+	}
+	if _, err := mocks.kubeClient.CoreV1().Services("default").Get("podinfo-canary", metav1.GetOptions{}); err == nil {
+		t.Errorf("Service podinfo-canary should not be created in the Flagger cluster")
+	}
+}
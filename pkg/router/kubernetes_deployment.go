@@ -2,6 +2,8 @@ package router
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -17,6 +19,13 @@ import (
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 )
 
+const (
+	// the keys of the labels and annotations applied by Flagger are recorded on the services
+	// so that the entries removed from the canary spec are removed from the services
+	managedLabelsAnnotation      = "flagger.app/managed-labels"
+	managedAnnotationsAnnotation = "flagger.app/managed-annotations"
+)
+
 // KubernetesDeploymentRouter is managing ClusterIP services
 type KubernetesDeploymentRouter struct {
 	kubeClient    kubernetes.Interface
@@ -25,6 +34,8 @@ type KubernetesDeploymentRouter struct {
 	labelSelector string
 	annotations   map[string]string
 	ports         map[string]int32
+	// remote services are created in another cluster and can't be owned by the canary
+	remote bool
 }
 
 // Initialize creates the primary and canary services
@@ -32,13 +43,13 @@ func (c *KubernetesDeploymentRouter) Initialize(canary *flaggerv1.Canary) error
 	_, primaryName, canaryName := canary.GetServiceNames()
 
 	// canary svc
-	err := c.reconcileService(canary, canaryName, canary.Spec.TargetRef.Name)
+	err := c.reconcileService(canary, canaryName, canary.Spec.TargetRef.Name, canary.Spec.Service.Canary)
 	if err != nil {
 		return err
 	}
 
 	// primary svc
-	err = c.reconcileService(canary, primaryName, fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name))
+	err = c.reconcileService(canary, primaryName, fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name), canary.Spec.Service.Primary)
 	if err != nil {
 		return err
 	}
@@ -51,7 +62,7 @@ func (c *KubernetesDeploymentRouter) Reconcile(canary *flaggerv1.Canary) error {
 	apexName, _, _ := canary.GetServiceNames()
 
 	// main svc
-	err := c.reconcileService(canary, apexName, fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name))
+	err := c.reconcileService(canary, apexName, fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name), canary.Spec.Service.Apex)
 	if err != nil {
 		return err
 	}
@@ -59,6 +70,13 @@ func (c *KubernetesDeploymentRouter) Reconcile(canary *flaggerv1.Canary) error {
 	return nil
 }
 
+// Finalize points the main service to the target pods, the service created by Flagger
+// is garbage collected with the canary while the one adopted from the target is kept
+func (c *KubernetesDeploymentRouter) Finalize(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+	return c.reconcileService(canary, apexName, canary.Spec.TargetRef.Name, canary.Spec.Service.Apex)
+}
+
 func (c *KubernetesDeploymentRouter) SetRoutes(canary *flaggerv1.Canary, primaryRoute int, canaryRoute int) error {
 	return nil
 }
@@ -67,7 +85,7 @@ func (c *KubernetesDeploymentRouter) GetRoutes(canary *flaggerv1.Canary) (primar
 	return 0, 0, nil
 }
 
-func (c *KubernetesDeploymentRouter) reconcileService(canary *flaggerv1.Canary, name string, podSelector string) error {
+func (c *KubernetesDeploymentRouter) reconcileService(canary *flaggerv1.Canary, name string, podSelector string, metadata *flaggerv1.CustomMetadata) error {
 	portName := canary.Spec.Service.PortName
 	if portName == "" {
 		portName = "http"
@@ -82,19 +100,45 @@ func (c *KubernetesDeploymentRouter) reconcileService(canary *flaggerv1.Canary,
 		targetPort = canary.Spec.Service.TargetPort
 	}
 
+	protocol := corev1.ProtocolTCP
+	if canary.Spec.Service.Protocol != "" {
+		protocol = canary.Spec.Service.Protocol
+	}
+
 	svcSpec := corev1.ServiceSpec{
 		Type:     corev1.ServiceTypeClusterIP,
 		Selector: map[string]string{c.labelSelector: podSelector},
 		Ports: []corev1.ServicePort{
 			{
 				Name:       portName,
-				Protocol:   corev1.ProtocolTCP,
+				Protocol:   protocol,
 				Port:       canary.Spec.Service.Port,
 				TargetPort: targetPort,
 			},
 		},
 	}
 
+	if canary.Spec.Service.Headless {
+		svcSpec.ClusterIP = corev1.ClusterIPNone
+	}
+
+	for _, p := range canary.Spec.Service.Ports {
+		cp := corev1.ServicePort{
+			Name:       p.Name,
+			Protocol:   corev1.ProtocolTCP,
+			Port:       p.Port,
+			TargetPort: intstr.FromInt(int(p.Port)),
+		}
+		if p.Protocol != "" {
+			cp.Protocol = p.Protocol
+		}
+		if p.TargetPort.String() != "0" {
+			cp.TargetPort = p.TargetPort
+		}
+
+		svcSpec.Ports = append(svcSpec.Ports, cp)
+	}
+
 	for n, p := range c.ports {
 		cp := corev1.ServicePort{
 			Name:     n,
@@ -109,24 +153,28 @@ func (c *KubernetesDeploymentRouter) reconcileService(canary *flaggerv1.Canary,
 		svcSpec.Ports = append(svcSpec.Ports, cp)
 	}
 
+	labels, annotations := c.makeServiceMetadata(name, metadata)
+
 	svc, err := c.kubeClient.CoreV1().Services(canary.Namespace).Get(name, metav1.GetOptions{})
 	if errors.IsNotFound(err) {
 		svc = &corev1.Service{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:        name,
 				Namespace:   canary.Namespace,
-				Labels:      map[string]string{c.labelSelector: name},
-				Annotations: c.annotations,
-				OwnerReferences: []metav1.OwnerReference{
-					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
-						Group:   flaggerv1.SchemeGroupVersion.Group,
-						Version: flaggerv1.SchemeGroupVersion.Version,
-						Kind:    flaggerv1.CanaryKind,
-					}),
-				},
+				Labels:      labels,
+				Annotations: annotations,
 			},
 			Spec: svcSpec,
 		}
+		if !c.remote {
+			svc.OwnerReferences = []metav1.OwnerReference{
+				*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			}
+		}
 
 		_, err = c.kubeClient.CoreV1().Services(canary.Namespace).Create(svc)
 		if err != nil {
@@ -143,16 +191,34 @@ func (c *KubernetesDeploymentRouter) reconcileService(canary *flaggerv1.Canary,
 	}
 
 	if svc != nil {
+		// the cluster IP is immutable, a headless service can't be converted in place
+		if (svcSpec.ClusterIP == corev1.ClusterIPNone) != (svc.Spec.ClusterIP == corev1.ClusterIPNone) {
+			return fmt.Errorf("service %s.%s headless mode can't be changed in place, delete the service to have it recreated",
+				name, canary.Namespace)
+		}
+
 		sortPorts := func(a, b interface{}) bool {
 			return a.(corev1.ServicePort).Port < b.(corev1.ServicePort).Port
 		}
 		portsDiff := cmp.Diff(svcSpec.Ports, svc.Spec.Ports, cmpopts.SortSlices(sortPorts))
 		selectorsDiff := cmp.Diff(svcSpec.Selector, svc.Spec.Selector)
+		staleLabels := staleKeys(svc.Annotations[managedLabelsAnnotation], labels)
+		staleAnnotations := staleKeys(svc.Annotations[managedAnnotationsAnnotation], annotations)
+		metadataChanged := !containsAll(svc.Labels, labels) || !containsAll(svc.Annotations, annotations) ||
+			len(staleLabels) > 0 || len(staleAnnotations) > 0
 
-		if portsDiff != "" || selectorsDiff != "" {
+		if portsDiff != "" || selectorsDiff != "" || metadataChanged {
 			svcClone := svc.DeepCopy()
 			svcClone.Spec.Ports = svcSpec.Ports
 			svcClone.Spec.Selector = svcSpec.Selector
+			svcClone.Labels = mergeMaps(svcClone.Labels, labels)
+			svcClone.Annotations = mergeMaps(svcClone.Annotations, annotations)
+			for _, k := range staleLabels {
+				delete(svcClone.Labels, k)
+			}
+			for _, k := range staleAnnotations {
+				delete(svcClone.Annotations, k)
+			}
 			_, err = c.kubeClient.CoreV1().Services(canary.Namespace).Update(svcClone)
 			if err != nil {
 				return fmt.Errorf("service %s update error %v", name, err)
@@ -164,3 +230,72 @@ func (c *KubernetesDeploymentRouter) reconcileService(canary *flaggerv1.Canary,
 
 	return nil
 }
+
+// makeServiceMetadata merges the router annotations with the per service custom metadata,
+// the selector label can't be overridden
+func (c *KubernetesDeploymentRouter) makeServiceMetadata(name string, metadata *flaggerv1.CustomMetadata) (map[string]string, map[string]string) {
+	labels := map[string]string{}
+	annotations := map[string]string{}
+	for k, v := range c.annotations {
+		annotations[k] = v
+	}
+	if metadata != nil {
+		for k, v := range metadata.Labels {
+			labels[k] = v
+		}
+		for k, v := range metadata.Annotations {
+			annotations[k] = v
+		}
+	}
+	labels[c.labelSelector] = name
+
+	managedLabels, managedAnnotations := managedKeys(labels), managedKeys(annotations)
+	annotations[managedLabelsAnnotation] = managedLabels
+	annotations[managedAnnotationsAnnotation] = managedAnnotations
+
+	return labels, annotations
+}
+
+// managedKeys returns the sorted keys of the map joined with commas,
+// the label and annotation keys can't contain commas
+func managedKeys(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// staleKeys returns the previously managed keys that are not in the current metadata
+func staleKeys(managed string, current map[string]string) []string {
+	var stale []string
+	for _, k := range strings.Split(managed, ",") {
+		if _, ok := current[k]; !ok && k != "" {
+			stale = append(stale, k)
+		}
+	}
+	return stale
+}
+
+// containsAll returns true if all the key value pairs of sub are found in m
+func containsAll(m map[string]string, sub map[string]string) bool {
+	for k, v := range sub {
+		if val, ok := m[k]; !ok || val != v {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeMaps returns a copy of dst with all the key value pairs of src set
+func mergeMaps(dst map[string]string, src map[string]string) map[string]string {
+	res := make(map[string]string, len(dst)+len(src))
+	for k, v := range dst {
+		res[k] = v
+	}
+	for k, v := range src {
+		res[k] = v
+	}
+	return res
+}
@@ -3,7 +3,11 @@ package router
 import (
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 )
 
 func TestServiceRouter_Create(t *testing.T) {
@@ -157,3 +161,243 @@ func TestServiceRouter_Undo(t *testing.T) {
 		t.Errorf("Got svc port %v wanted %v", canarySvc.Spec.Ports[0].Port, 9898)
 	}
 }
+
+func TestServiceRouter_Headless(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDeploymentRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+	}
+
+	err := router.Initialize(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// switching an existing service to headless must fail
+	canaryClone := mocks.canary.DeepCopy()
+	canaryClone.Spec.Service.Headless = true
+	err = router.Initialize(canaryClone)
+	if err == nil {
+		t.Errorf("Expected error when changing headless mode of existing services")
+	}
+
+	err = mocks.kubeClient.CoreV1().Services("default").Delete("podinfo-canary", &metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = mocks.kubeClient.CoreV1().Services("default").Delete("podinfo-primary", &metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.Initialize(canaryClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = router.Reconcile(canaryClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, name := range []string{"podinfo", "podinfo-canary", "podinfo-primary"} {
+		svc, err := mocks.kubeClient.CoreV1().Services("default").Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if svc.Spec.ClusterIP != "None" {
+			t.Errorf("Got svc %s cluster IP %s wanted %s", name, svc.Spec.ClusterIP, "None")
+		}
+	}
+}
+
+func TestServiceRouter_UDP(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDeploymentRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+	}
+
+	err := router.Initialize(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// changing the protocol updates the existing services
+	canaryClone := mocks.canary.DeepCopy()
+	canaryClone.Spec.Service.Protocol = corev1.ProtocolUDP
+	err = router.Initialize(canaryClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = router.Reconcile(canaryClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, name := range []string{"podinfo", "podinfo-canary", "podinfo-primary"} {
+		svc, err := mocks.kubeClient.CoreV1().Services("default").Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if svc.Spec.Ports[0].Protocol != corev1.ProtocolUDP {
+			t.Errorf("Got svc %s protocol %s wanted %s", name, svc.Spec.Ports[0].Protocol, corev1.ProtocolUDP)
+		}
+	}
+}
+
+func TestServiceRouter_MultiplePorts(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDeploymentRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+	}
+
+	canaryClone := mocks.canary.DeepCopy()
+	canaryClone.Spec.Service.Ports = []flaggerv1.CanaryServicePort{
+		{Name: "grpc", Port: 9999, TargetPort: intstr.FromString("grpc")},
+		{Name: "tcp-admin", Port: 9090},
+	}
+	err := router.Initialize(canaryClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = router.Reconcile(canaryClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, name := range []string{"podinfo", "podinfo-canary", "podinfo-primary"} {
+		svc, err := mocks.kubeClient.CoreV1().Services("default").Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if len(svc.Spec.Ports) != 3 {
+			t.Fatalf("Got svc %s ports %+v wanted 3 ports", name, svc.Spec.Ports)
+		}
+		if p := svc.Spec.Ports[1]; p.Name != "grpc" || p.Port != 9999 || p.TargetPort.StrVal != "grpc" {
+			t.Errorf("Got svc %s port %+v wanted grpc 9999 targeting grpc", name, p)
+		}
+		if p := svc.Spec.Ports[2]; p.Name != "tcp-admin" || p.Port != 9090 || p.TargetPort.IntVal != 9090 || p.Protocol != corev1.ProtocolTCP {
+			t.Errorf("Got svc %s port %+v wanted tcp-admin 9090 targeting 9090", name, p)
+		}
+	}
+}
+
+func TestServiceRouter_CustomMetadata(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDeploymentRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+	}
+
+	err := router.Initialize(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	canaryClone := mocks.canary.DeepCopy()
+	canaryClone.Spec.Service.Canary = &flaggerv1.CustomMetadata{
+		Labels:      map[string]string{"app": "override", "variant": "canary"},
+		Annotations: map[string]string{"linkerd.io/inject": "enabled"},
+	}
+	err = router.Initialize(canaryClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if canarySvc.Labels["app"] != "podinfo-canary" {
+		t.Errorf("Got svc selector label %s wanted %s", canarySvc.Labels["app"], "podinfo-canary")
+	}
+
+	if canarySvc.Labels["variant"] != "canary" {
+		t.Errorf("Got svc label %s wanted %s", canarySvc.Labels["variant"], "canary")
+	}
+
+	if canarySvc.Annotations["linkerd.io/inject"] != "enabled" {
+		t.Errorf("Got svc annotation %s wanted %s", canarySvc.Annotations["linkerd.io/inject"], "enabled")
+	}
+
+	primarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, ok := primarySvc.Annotations["linkerd.io/inject"]; ok {
+		t.Errorf("Canary annotations should not be set on the primary svc")
+	}
+}
+
+func TestServiceRouter_CustomMetadataRemoved(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDeploymentRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+	}
+
+	canaryClone := mocks.canary.DeepCopy()
+	canaryClone.Spec.Service.Canary = &flaggerv1.CustomMetadata{
+		Labels:      map[string]string{"variant": "canary", "team": "podinfo"},
+		Annotations: map[string]string{"linkerd.io/inject": "enabled", "owner": "podinfo"},
+	}
+	err := router.Initialize(canaryClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// labels and annotations set by other controllers are kept
+	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	canarySvc.Labels["external"] = "true"
+	canarySvc.Annotations["external"] = "true"
+	_, err = mocks.kubeClient.CoreV1().Services("default").Update(canarySvc)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	canaryClone.Spec.Service.Canary = &flaggerv1.CustomMetadata{
+		Labels:      map[string]string{"team": "podinfo"},
+		Annotations: map[string]string{"owner": "podinfo"},
+	}
+	err = router.Initialize(canaryClone)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	canarySvc, err = mocks.kubeClient.CoreV1().Services("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, ok := canarySvc.Labels["variant"]; ok {
+		t.Errorf("Removed label variant should have been deleted from the svc")
+	}
+	if _, ok := canarySvc.Annotations["linkerd.io/inject"]; ok {
+		t.Errorf("Removed annotation linkerd.io/inject should have been deleted from the svc")
+	}
+	if canarySvc.Labels["team"] != "podinfo" || canarySvc.Annotations["owner"] != "podinfo" {
+		t.Errorf("Got svc labels %v annotations %v", canarySvc.Labels, canarySvc.Annotations)
+	}
+	if canarySvc.Labels["external"] != "true" || canarySvc.Annotations["external"] != "true" {
+		t.Errorf("Unmanaged svc metadata should be kept, got labels %v annotations %v", canarySvc.Labels, canarySvc.Annotations)
+	}
+	if canarySvc.Labels["app"] != "podinfo-canary" {
+		t.Errorf("Got svc selector label %s wanted %s", canarySvc.Labels["app"], "podinfo-canary")
+	}
+}
@@ -0,0 +1,255 @@
+package router
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	kumav1alpha1 "github.com/weaveworks/flagger/pkg/apis/kuma/v1alpha1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+const (
+	// kumaMeshAnnotation selects the mesh of the namespace data planes
+	kumaMeshAnnotation = "kuma.io/mesh"
+	// kumaServiceTag is the data plane tag holding the Kubernetes service
+	kumaServiceTag = "kuma.io/service"
+)
+
+// KumaRouter is managing the Kuma traffic routes, the routes are cluster scoped
+// and can't be owned by the canary, they are named <service>.<namespace>
+type KumaRouter struct {
+	kubeClient kubernetes.Interface
+	kumaClient clientset.Interface
+	logger     *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the Kuma traffic route,
+// for A/B testing the weights apply to the HTTP routes matching the conditions
+func (kr *KumaRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	name := kr.routeName(canary)
+
+	mesh := "default"
+	ns, err := kr.kubeClient.CoreV1().Namespaces().Get(canary.Namespace, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("namespace %s query error %v", canary.Namespace, err)
+	}
+	if err == nil && ns.Annotations[kumaMeshAnnotation] != "" {
+		mesh = ns.Annotations[kumaMeshAnnotation]
+	}
+
+	split := kr.makeSplit(canary, primaryName, canaryName, 100, 0)
+	spec := kumav1alpha1.TrafficRouteSpec{
+		Sources: []kumav1alpha1.Selector{
+			{Match: map[string]string{kumaServiceTag: "*"}},
+		},
+		Destinations: []kumav1alpha1.Selector{
+			{Match: map[string]string{kumaServiceTag: kr.serviceTag(canary, apexName)}},
+		},
+		Conf: kumav1alpha1.TrafficRouteConf{
+			Split: split,
+			HTTP:  makeKumaHTTPRoutes(canary, split),
+		},
+	}
+
+	tr, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Get(name, metav1.GetOptions{})
+	// create traffic route
+	if errors.IsNotFound(err) {
+		t := &kumav1alpha1.TrafficRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Mesh: mesh,
+			Spec: spec,
+		}
+
+		_, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Create(t)
+		if err != nil {
+			return fmt.Errorf("TrafficRoute %s create error %v", name, err)
+		}
+
+		kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficRoute %s created", name)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("TrafficRoute %s query error %v", name, err)
+	}
+
+	// update traffic route, the weights are managed by SetRoutes
+	// and are reset to primary when the A/B testing conditions are added or removed
+	switch {
+	case len(spec.Conf.HTTP) > 0 && len(tr.Spec.Conf.HTTP) > 0:
+		spec.Conf.HTTP = makeKumaHTTPRoutes(canary, tr.Spec.Conf.HTTP[0].Split)
+	case len(spec.Conf.HTTP) == 0 && len(tr.Spec.Conf.HTTP) == 0 && len(tr.Spec.Conf.Split) > 0:
+		spec.Conf.Split = tr.Spec.Conf.Split
+	}
+
+	if diff := cmp.Diff(spec, tr.Spec); diff != "" || mesh != tr.Mesh {
+		trClone := tr.DeepCopy()
+		trClone.Mesh = mesh
+		trClone.Spec = spec
+
+		_, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Update(trClone)
+		if err != nil {
+			return fmt.Errorf("TrafficRoute %s update error %v", name, err)
+		}
+
+		kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficRoute %s updated", name)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the destinations weight for primary and canary
+func (kr *KumaRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	name := kr.routeName(canary)
+	tr, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Get(name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			err = fmt.Errorf("TrafficRoute %s not found", name)
+			return
+		}
+		err = fmt.Errorf("TrafficRoute %s query error %v", name, err)
+		return
+	}
+
+	split := tr.Spec.Conf.Split
+	if len(tr.Spec.Conf.HTTP) > 0 {
+		split = tr.Spec.Conf.HTTP[0].Split
+	}
+
+	primaryTag := kr.serviceTag(canary, primaryName)
+	canaryTag := kr.serviceTag(canary, canaryName)
+	for _, s := range split {
+		switch s.Destination[kumaServiceTag] {
+		case primaryTag:
+			primaryWeight = int(s.Weight)
+		case canaryTag:
+			canaryWeight = int(s.Weight)
+		}
+	}
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		err = fmt.Errorf("TrafficRoute %s does not contain routes for %s and %s",
+			name, primaryTag, canaryTag)
+	}
+
+	mirrored = false
+
+	return
+}
+
+// SetRoutes updates the destinations weight for primary and canary,
+// during A/B testing the requests that don't match the conditions are routed to primary
+func (kr *KumaRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+) error {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	name := kr.routeName(canary)
+	tr, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Get(name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("TrafficRoute %s not found", name)
+		}
+		return fmt.Errorf("TrafficRoute %s query error %v", name, err)
+	}
+
+	split := kr.makeSplit(canary, primaryName, canaryName, primaryWeight, canaryWeight)
+	trClone := tr.DeepCopy()
+	if len(trClone.Spec.Conf.HTTP) > 0 {
+		trClone.Spec.Conf.HTTP = makeKumaHTTPRoutes(canary, split)
+		trClone.Spec.Conf.Split = kr.makeSplit(canary, primaryName, canaryName, 100, 0)
+	} else {
+		trClone.Spec.Conf.Split = split
+	}
+
+	_, err = kr.kumaClient.KumaV1alpha1().TrafficRoutes().Update(trClone)
+	if err != nil {
+		return fmt.Errorf("TrafficRoute %s update error %v", name, err)
+	}
+
+	return nil
+}
+
+func (kr *KumaRouter) routeName(canary *flaggerv1.Canary) string {
+	apexName, _, _ := canary.GetServiceNames()
+	return fmt.Sprintf("%s.%s", apexName, canary.Namespace)
+}
+
+// serviceTag returns the Kuma service tag of a Kubernetes service port
+func (kr *KumaRouter) serviceTag(canary *flaggerv1.Canary, service string) string {
+	return fmt.Sprintf("%s_%s_svc_%d", service, canary.Namespace, canary.Spec.Service.Port)
+}
+
+func (kr *KumaRouter) makeSplit(canary *flaggerv1.Canary, primaryName string, canaryName string,
+	primaryWeight int, canaryWeight int) []kumav1alpha1.TrafficRouteSplit {
+	return []kumav1alpha1.TrafficRouteSplit{
+		{
+			Weight:      uint32(primaryWeight),
+			Destination: map[string]string{kumaServiceTag: kr.serviceTag(canary, primaryName)},
+		},
+		{
+			Weight:      uint32(canaryWeight),
+			Destination: map[string]string{kumaServiceTag: kr.serviceTag(canary, canaryName)},
+		},
+	}
+}
+
+// makeKumaHTTPRoutes converts the A/B testing conditions into HTTP routes with the given split,
+// the Kuma routes don't match suffixes, they are converted to regular expressions
+func makeKumaHTTPRoutes(canary *flaggerv1.Canary, split []kumav1alpha1.TrafficRouteSplit) []kumav1alpha1.TrafficRouteHTTP {
+	var routes []kumav1alpha1.TrafficRouteHTTP
+	for _, m := range canary.GetAnalysis().Match {
+		var match kumav1alpha1.TrafficRouteHTTPMatch
+		if m.Uri != nil {
+			match.Path = kumaStringMatch(*m.Uri)
+		}
+		if m.Method != nil {
+			match.Method = kumaStringMatch(*m.Method)
+		}
+		if len(m.Headers) > 0 {
+			match.Headers = make(map[string]kumav1alpha1.StringMatch, len(m.Headers))
+			for name, value := range m.Headers {
+				match.Headers[name] = *kumaStringMatch(value)
+			}
+		}
+		routes = append(routes, kumav1alpha1.TrafficRouteHTTP{
+			Match: match,
+			Split: split,
+		})
+	}
+	return routes
+}
+
+func kumaStringMatch(value istiov1alpha1.StringMatch) *kumav1alpha1.StringMatch {
+	switch {
+	case value.Exact != "":
+		return &kumav1alpha1.StringMatch{Exact: value.Exact}
+	case value.Prefix != "":
+		return &kumav1alpha1.StringMatch{Prefix: value.Prefix}
+	case value.Suffix != "":
+		return &kumav1alpha1.StringMatch{Regex: ".*" + regexp.QuoteMeta(value.Suffix)}
+	default:
+		return &kumav1alpha1.StringMatch{Regex: value.Regex}
+	}
+}
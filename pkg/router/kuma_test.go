@@ -0,0 +1,150 @@
+package router
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+func newKumaTestRouter(mocks fixture) *KumaRouter {
+	return &KumaRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+		kumaClient: mocks.meshClient,
+	}
+}
+
+func TestKumaRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	mocks.kubeClient.CoreV1().Namespaces().Create(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{kumaMeshAnnotation: "test"},
+		},
+	})
+	router := newKumaTestRouter(mocks)
+
+	err := router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tr, err := router.kumaClient.KumaV1alpha1().TrafficRoutes().Get("podinfo.default", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if tr.Mesh != "test" {
+		t.Errorf("Got mesh %v wanted %v", tr.Mesh, "test")
+	}
+	destination := tr.Spec.Destinations[0].Match[kumaServiceTag]
+	if destination != "podinfo_default_svc_9898" {
+		t.Errorf("Got destination %v wanted %v", destination, "podinfo_default_svc_9898")
+	}
+	if len(tr.Spec.Conf.Split) != 2 || tr.Spec.Conf.Split[0].Destination[kumaServiceTag] != "podinfo-primary_default_svc_9898" {
+		t.Errorf("Got split %v wanted podinfo-primary_default_svc_9898", tr.Spec.Conf.Split)
+	}
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// reconcile doesn't reset the weights
+	err = router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 60 || c != 40 || m {
+		t.Errorf("Got routes %v/%v/%v wanted %v/%v/%v", p, c, m, 60, 40, false)
+	}
+}
+
+func TestKumaRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newKumaTestRouter(mocks)
+
+	cd := mocks.canary.DeepCopy()
+	cd.GetAnalysis().Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-canary": {Suffix: "insider"},
+			},
+		},
+	}
+
+	err := router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = router.SetRoutes(cd, 0, 100, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tr, err := router.kumaClient.KumaV1alpha1().TrafficRoutes().Get("podinfo.default", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(tr.Spec.Conf.HTTP) != 1 {
+		t.Fatalf("Got HTTP routes %v wanted 1", len(tr.Spec.Conf.HTTP))
+	}
+	if header := tr.Spec.Conf.HTTP[0].Match.Headers["x-canary"]; header.Regex != ".*insider" {
+		t.Errorf("Got header regex %v wanted %v", header.Regex, ".*insider")
+	}
+	// the requests that don't match the conditions are routed to primary
+	if tr.Spec.Conf.Split[0].Weight != 100 || tr.Spec.Conf.Split[1].Weight != 0 {
+		t.Errorf("Got default split %v wanted primary 100", tr.Spec.Conf.Split)
+	}
+
+	p, c, _, err := router.GetRoutes(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 0 || c != 100 {
+		t.Errorf("Got routes %v/%v wanted %v/%v", p, c, 0, 100)
+	}
+
+	// removing the conditions removes the HTTP routes
+	err = router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tr, err = router.kumaClient.KumaV1alpha1().TrafficRoutes().Get("podinfo.default", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(tr.Spec.Conf.HTTP) != 0 {
+		t.Errorf("Got HTTP routes %v wanted none", tr.Spec.Conf.HTTP)
+	}
+}
+
+func TestRunConformance_Kuma(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newKumaTestRouter(mocks)
+
+	results := RunConformance(router, mocks.canary)
+
+	for _, result := range results {
+		if result.Check == "mirror" {
+			if !result.Skipped {
+				t.Errorf("Check mirror should be skipped")
+			}
+			continue
+		}
+		if !result.Passed {
+			t.Errorf("Check %s failed: %s", result.Check, result.Message)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	monitoringv1 "github.com/weaveworks/flagger/pkg/apis/monitoring/v1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// monitorVariantLabel is added to the targets scraped by the primary and canary monitor copies
+const monitorVariantLabel = "flagger_variant"
+
+// MonitorsRouter clones the Prometheus Operator ServiceMonitors and PodMonitors
+// that select the target so that the primary and canary are scraped separately
+type MonitorsRouter struct {
+	kubeClient    kubernetes.Interface
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+	labelSelector string
+}
+
+// Reconcile creates or updates the primary and canary copies of the monitors
+func (mr *MonitorsRouter) Reconcile(canary *flaggerv1.Canary) error {
+	if canary.Spec.TargetRef.Kind == "Service" {
+		return nil
+	}
+
+	if err := mr.reconcileServiceMonitors(canary); err != nil {
+		return err
+	}
+
+	return mr.reconcilePodMonitors(canary)
+}
+
+func (mr *MonitorsRouter) reconcileServiceMonitors(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	apex, err := mr.kubeClient.CoreV1().Services(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("service %s.%s get query error %v", apexName, canary.Namespace, err)
+	}
+
+	list, err := mr.flaggerClient.MonitoringV1().ServiceMonitors(canary.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		// the Prometheus Operator CRDs are not installed
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("ServiceMonitor %s list query error %v", canary.Namespace, err)
+	}
+
+	for _, sm := range list.Items {
+		if isOwnedByCanary(sm.ObjectMeta) || !matchesNamespace(sm.Spec.NamespaceSelector, canary.Namespace) {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(&sm.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(apex.Labels)) {
+			continue
+		}
+
+		for variant, svcName := range map[string]string{"primary": primaryName, "canary": canaryName} {
+			spec := sm.Spec.DeepCopy()
+			spec.Selector = metav1.LabelSelector{MatchLabels: map[string]string{mr.labelSelector: svcName}}
+			spec.NamespaceSelector = monitoringv1.NamespaceSelector{}
+			for i := range spec.Endpoints {
+				spec.Endpoints[i].RelabelConfigs = append(spec.Endpoints[i].RelabelConfigs, variantRelabelConfig(variant))
+			}
+
+			if err := mr.reconcileServiceMonitor(canary, sm, variant, *spec); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (mr *MonitorsRouter) reconcileServiceMonitor(canary *flaggerv1.Canary, sm monitoringv1.ServiceMonitor,
+	variant string, spec monitoringv1.ServiceMonitorSpec) error {
+	name := fmt.Sprintf("%s-%s", sm.Name, variant)
+	existing, err := mr.flaggerClient.MonitoringV1().ServiceMonitors(canary.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		clone := &monitoringv1.ServiceMonitor{
+			ObjectMeta: newMonitorMeta(canary, name, sm.Labels, variant),
+			Spec:       spec,
+		}
+		_, err = mr.flaggerClient.MonitoringV1().ServiceMonitors(canary.Namespace).Create(clone)
+		if err != nil {
+			return fmt.Errorf("ServiceMonitor %s.%s create error %v", name, canary.Namespace, err)
+		}
+		mr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceMonitor %s.%s created", name, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ServiceMonitor %s.%s get query error %v", name, canary.Namespace, err)
+	}
+
+	if diff := cmp.Diff(spec, existing.Spec); diff != "" {
+		clone := existing.DeepCopy()
+		clone.Spec = spec
+		_, err = mr.flaggerClient.MonitoringV1().ServiceMonitors(canary.Namespace).Update(clone)
+		if err != nil {
+			return fmt.Errorf("ServiceMonitor %s.%s update error %v", name, canary.Namespace, err)
+		}
+		mr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceMonitor %s.%s updated", name, canary.Namespace)
+	}
+
+	return nil
+}
+
+func (mr *MonitorsRouter) reconcilePodMonitors(canary *flaggerv1.Canary) error {
+	podLabels, err := mr.getTargetPodLabels(canary)
+	if err != nil {
+		return err
+	}
+
+	list, err := mr.flaggerClient.MonitoringV1().PodMonitors(canary.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		// the Prometheus Operator CRDs are not installed
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("PodMonitor %s list query error %v", canary.Namespace, err)
+	}
+
+	targetName := canary.Spec.TargetRef.Name
+	for _, pm := range list.Items {
+		if isOwnedByCanary(pm.ObjectMeta) || !matchesNamespace(pm.Spec.NamespaceSelector, canary.Namespace) {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(&pm.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(podLabels)) {
+			continue
+		}
+
+		for variant, podName := range map[string]string{"primary": fmt.Sprintf("%s-primary", targetName), "canary": targetName} {
+			spec := pm.Spec.DeepCopy()
+			spec.Selector = metav1.LabelSelector{MatchLabels: map[string]string{mr.labelSelector: podName}}
+			spec.NamespaceSelector = monitoringv1.NamespaceSelector{}
+			for i := range spec.PodMetricsEndpoints {
+				spec.PodMetricsEndpoints[i].RelabelConfigs = append(spec.PodMetricsEndpoints[i].RelabelConfigs, variantRelabelConfig(variant))
+			}
+
+			if err := mr.reconcilePodMonitor(canary, pm, variant, *spec); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (mr *MonitorsRouter) reconcilePodMonitor(canary *flaggerv1.Canary, pm monitoringv1.PodMonitor,
+	variant string, spec monitoringv1.PodMonitorSpec) error {
+	name := fmt.Sprintf("%s-%s", pm.Name, variant)
+	existing, err := mr.flaggerClient.MonitoringV1().PodMonitors(canary.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		clone := &monitoringv1.PodMonitor{
+			ObjectMeta: newMonitorMeta(canary, name, pm.Labels, variant),
+			Spec:       spec,
+		}
+		_, err = mr.flaggerClient.MonitoringV1().PodMonitors(canary.Namespace).Create(clone)
+		if err != nil {
+			return fmt.Errorf("PodMonitor %s.%s create error %v", name, canary.Namespace, err)
+		}
+		mr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("PodMonitor %s.%s created", name, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("PodMonitor %s.%s get query error %v", name, canary.Namespace, err)
+	}
+
+	if diff := cmp.Diff(spec, existing.Spec); diff != "" {
+		clone := existing.DeepCopy()
+		clone.Spec = spec
+		_, err = mr.flaggerClient.MonitoringV1().PodMonitors(canary.Namespace).Update(clone)
+		if err != nil {
+			return fmt.Errorf("PodMonitor %s.%s update error %v", name, canary.Namespace, err)
+		}
+		mr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("PodMonitor %s.%s updated", name, canary.Namespace)
+	}
+
+	return nil
+}
+
+// getTargetPodLabels returns the pod template labels of the target deployment or daemon set
+func (mr *MonitorsRouter) getTargetPodLabels(canary *flaggerv1.Canary) (map[string]string, error) {
+	targetName := canary.Spec.TargetRef.Name
+	switch canary.Spec.TargetRef.Kind {
+	case "DaemonSet":
+		ds, err := mr.kubeClient.AppsV1().DaemonSets(canary.Namespace).Get(targetName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("daemonset %s.%s get query error %v", targetName, canary.Namespace, err)
+		}
+		return ds.Spec.Template.Labels, nil
+	default:
+		dep, err := mr.kubeClient.AppsV1().Deployments(canary.Namespace).Get(targetName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("deployment %s.%s get query error %v", targetName, canary.Namespace, err)
+		}
+		return dep.Spec.Template.Labels, nil
+	}
+}
+
+func newMonitorMeta(canary *flaggerv1.Canary, name string, monitorLabels map[string]string, variant string) metav1.ObjectMeta {
+	// keep the labels so that the copies are picked up by the same Prometheus instances
+	l := make(map[string]string, len(monitorLabels)+1)
+	for k, v := range monitorLabels {
+		l[k] = v
+	}
+	l[monitorVariantLabel] = variant
+
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: canary.Namespace,
+		Labels:    l,
+		OwnerReferences: []metav1.OwnerReference{
+			*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+				Group:   flaggerv1.SchemeGroupVersion.Group,
+				Version: flaggerv1.SchemeGroupVersion.Version,
+				Kind:    flaggerv1.CanaryKind,
+			}),
+		},
+	}
+}
+
+func variantRelabelConfig(variant string) *monitoringv1.RelabelConfig {
+	return &monitoringv1.RelabelConfig{
+		TargetLabel: monitorVariantLabel,
+		Replacement: variant,
+	}
+}
+
+// isOwnedByCanary returns true for the monitors generated by Flagger
+func isOwnedByCanary(meta metav1.ObjectMeta) bool {
+	if ref := metav1.GetControllerOf(&meta); ref != nil && ref.Kind == flaggerv1.CanaryKind {
+		return true
+	}
+	return false
+}
+
+// matchesNamespace returns true if the monitor discovers targets in the given namespace,
+// an empty namespace selector means the monitor namespace
+func matchesNamespace(selector monitoringv1.NamespaceSelector, namespace string) bool {
+	if selector.Any || len(selector.MatchNames) == 0 {
+		return true
+	}
+	for _, ns := range selector.MatchNames {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
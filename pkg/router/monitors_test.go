@@ -0,0 +1,118 @@
+package router
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	monitoringv1 "github.com/weaveworks/flagger/pkg/apis/monitoring/v1"
+)
+
+func TestMonitorsRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDeploymentRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+	}
+
+	if err := router.Initialize(mocks.canary); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := router.Reconcile(mocks.canary); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	sm := &monitoringv1.ServiceMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default", Labels: map[string]string{"release": "prometheus"}},
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "podinfo"}},
+			Endpoints: []monitoringv1.Endpoint{{Port: "http", Path: "/metrics"}},
+		},
+	}
+	if _, err := mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Create(sm); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	pm := &monitoringv1.PodMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo-pods", Namespace: "default"},
+		Spec: monitoringv1.PodMonitorSpec{
+			Selector:            metav1.LabelSelector{MatchLabels: map[string]string{"app": "podinfo"}},
+			PodMetricsEndpoints: []monitoringv1.PodMetricsEndpoint{{Port: "http-metrics"}},
+		},
+	}
+	if _, err := mocks.flaggerClient.MonitoringV1().PodMonitors("default").Create(pm); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mr := &MonitorsRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+	}
+
+	if err := mr.Reconcile(mocks.canary); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	smPrimary, err := mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Get("podinfo-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if smPrimary.Spec.Selector.MatchLabels["app"] != "podinfo-primary" {
+		t.Errorf("Got selector %v wanted %s", smPrimary.Spec.Selector.MatchLabels, "podinfo-primary")
+	}
+
+	if smPrimary.Labels["release"] != "prometheus" || smPrimary.Labels[monitorVariantLabel] != "primary" {
+		t.Errorf("Got labels %v wanted release and variant labels", smPrimary.Labels)
+	}
+
+	relabelings := smPrimary.Spec.Endpoints[0].RelabelConfigs
+	if len(relabelings) != 1 || relabelings[0].Replacement != "primary" {
+		t.Errorf("Got relabelings %v wanted %s variant", relabelings, "primary")
+	}
+
+	smCanary, err := mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Get("podinfo-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if smCanary.Spec.Selector.MatchLabels["app"] != "podinfo-canary" {
+		t.Errorf("Got selector %v wanted %s", smCanary.Spec.Selector.MatchLabels, "podinfo-canary")
+	}
+
+	pmPrimary, err := mocks.flaggerClient.MonitoringV1().PodMonitors("default").Get("podinfo-pods-primary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if pmPrimary.Spec.Selector.MatchLabels["app"] != "podinfo-primary" {
+		t.Errorf("Got selector %v wanted %s", pmPrimary.Spec.Selector.MatchLabels, "podinfo-primary")
+	}
+
+	pmCanary, err := mocks.flaggerClient.MonitoringV1().PodMonitors("default").Get("podinfo-pods-canary", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if pmCanary.Spec.Selector.MatchLabels["app"] != "podinfo" {
+		t.Errorf("Got selector %v wanted %s", pmCanary.Spec.Selector.MatchLabels, "podinfo")
+	}
+
+	// the copies must not be cloned again
+	if err := mr.Reconcile(mocks.canary); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	list, err := mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(list.Items) != 3 {
+		t.Errorf("Got %v service monitors wanted %v", len(list.Items), 3)
+	}
+}
@@ -0,0 +1,248 @@
+package router
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	smiv1alpha3 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha3"
+	specsv1alpha3 "github.com/weaveworks/flagger/pkg/apis/smispecs/v1alpha3"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// osmMonitoredByLabel is set by the OSM CLI on the namespaces that are part of the mesh
+const osmMonitoredByLabel = "openservicemesh.io/monitored-by"
+
+// OsmRouter is managing the SMI traffic split and the A/B testing route group of Open Service Mesh
+type OsmRouter struct {
+	kubeClient kubernetes.Interface
+	smiClient  clientset.Interface
+	logger     *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the SMI traffic split, for A/B testing it creates or updates
+// the HTTP route group referenced by the traffic split matches
+func (om *OsmRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	// OSM ignores the traffic splits of the namespaces that are not part of the mesh
+	ns, err := om.kubeClient.CoreV1().Namespaces().Get(canary.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("namespace %s query error %v", canary.Namespace, err)
+	}
+	if _, ok := ns.Labels[osmMonitoredByLabel]; !ok {
+		return fmt.Errorf("namespace %s is not monitored by OSM, the %s label is missing", canary.Namespace, osmMonitoredByLabel)
+	}
+
+	var matches []corev1.TypedLocalObjectReference
+	if len(canary.GetAnalysis().Match) > 0 {
+		if err := reconcileSmiRouteGroup(om.smiClient, om.logger, canary); err != nil {
+			return err
+		}
+		apiGroup := specsv1alpha3.SchemeGroupVersion.Group
+		matches = []corev1.TypedLocalObjectReference{
+			{
+				APIGroup: &apiGroup,
+				Kind:     "HTTPRouteGroup",
+				Name:     smiRouteGroupName(canary),
+			},
+		}
+	} else if err := deleteSmiRouteGroup(om.smiClient, canary); err != nil {
+		return err
+	}
+
+	host := apexName
+	if len(canary.Spec.Service.Hosts) > 0 {
+		host = canary.Spec.Service.Hosts[0]
+	}
+
+	tsSpec := smiv1alpha3.TrafficSplitSpec{
+		// OSM expects the root service in the <name>.<namespace> format
+		Service: fmt.Sprintf("%s.%s", host, canary.Namespace),
+		Matches: matches,
+		Backends: []smiv1alpha3.TrafficSplitBackend{
+			{
+				Service: canaryName,
+				Weight:  0,
+			},
+			{
+				Service: primaryName,
+				Weight:  100,
+			},
+		},
+	}
+
+	ts, err := om.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	// create traffic split
+	if errors.IsNotFound(err) {
+		t := &smiv1alpha3.TrafficSplit{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apexName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: tsSpec,
+		}
+
+		_, err := om.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Create(t)
+		if err != nil {
+			return err
+		}
+
+		om.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficSplit %s.%s created", t.GetName(), canary.Namespace)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("TrafficSplit %s query error %v", apexName, err)
+	}
+
+	// update traffic split, the weights are managed by SetRoutes
+	if tsSpec.Service != ts.Spec.Service || !cmp.Equal(tsSpec.Matches, ts.Spec.Matches) {
+		tsClone := ts.DeepCopy()
+		tsClone.Spec.Service = tsSpec.Service
+		tsClone.Spec.Matches = tsSpec.Matches
+		if len(tsClone.Spec.Backends) == 0 {
+			tsClone.Spec.Backends = tsSpec.Backends
+		}
+
+		_, err := om.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Update(tsClone)
+		if err != nil {
+			return fmt.Errorf("TrafficSplit %s update error %v", apexName, err)
+		}
+
+		om.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficSplit %s.%s updated", apexName, canary.Namespace)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the destinations weight for primary and canary
+func (om *OsmRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	ts, err := om.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			err = fmt.Errorf("TrafficSplit %s.%s not found", apexName, canary.Namespace)
+			return
+		}
+		err = fmt.Errorf("TrafficSplit %s.%s query error %v", apexName, canary.Namespace, err)
+		return
+	}
+
+	for _, r := range ts.Spec.Backends {
+		if r.Service == primaryName {
+			primaryWeight = r.Weight
+		}
+		if r.Service == canaryName {
+			canaryWeight = r.Weight
+		}
+	}
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		err = fmt.Errorf("TrafficSplit %s.%s does not contain routes for %s and %s",
+			apexName, canary.Namespace, primaryName, canaryName)
+	}
+
+	mirrored = false
+
+	return
+}
+
+// SetRoutes updates the destinations weight for primary and canary,
+// during A/B testing the weights apply to the requests matching the route group
+func (om *OsmRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	ts, err := om.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("TrafficSplit %s.%s not found", apexName, canary.Namespace)
+		}
+		return fmt.Errorf("TrafficSplit %s.%s query error %v", apexName, canary.Namespace, err)
+	}
+
+	tsClone := ts.DeepCopy()
+	tsClone.Spec.Backends = []smiv1alpha3.TrafficSplitBackend{
+		{
+			Service: canaryName,
+			Weight:  canaryWeight,
+		},
+		{
+			Service: primaryName,
+			Weight:  primaryWeight,
+		},
+	}
+
+	_, err = om.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Update(tsClone)
+	if err != nil {
+		return fmt.Errorf("TrafficSplit %s update error %v", apexName, err)
+	}
+
+	return nil
+}
+
+// makeHTTPRouteGroupMatches converts the A/B testing conditions into route group matches,
+// the SMI header values and paths are regular expressions
+func makeHTTPRouteGroupMatches(canary *flaggerv1.Canary) []specsv1alpha3.HTTPMatch {
+	var matches []specsv1alpha3.HTTPMatch
+	for i, m := range canary.GetAnalysis().Match {
+		match := specsv1alpha3.HTTPMatch{
+			Name: fmt.Sprintf("ab-test-%d", i),
+		}
+		if m.Uri != nil {
+			match.PathRegex = stringMatchRegex(*m.Uri)
+		}
+		if m.Method != nil && m.Method.Exact != "" {
+			match.Methods = []string{m.Method.Exact}
+		}
+		if len(m.Headers) > 0 {
+			match.Headers = make(map[string]string, len(m.Headers))
+			for name, value := range m.Headers {
+				match.Headers[name] = stringMatchRegex(value)
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// stringMatchRegex returns the regular expression equivalent of a string match
+func stringMatchRegex(value istiov1alpha1.StringMatch) string {
+	switch {
+	case value.Exact != "":
+		return "^" + regexp.QuoteMeta(value.Exact) + "$"
+	case value.Prefix != "":
+		return "^" + regexp.QuoteMeta(value.Prefix)
+	case value.Suffix != "":
+		return regexp.QuoteMeta(value.Suffix) + "$"
+	default:
+		return value.Regex
+	}
+}
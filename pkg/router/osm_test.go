@@ -0,0 +1,174 @@
+package router
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	istiov1alpha1 "github.com/weaveworks/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+func newOsmTestRouter(mocks fixture) *OsmRouter {
+	mocks.kubeClient.CoreV1().Namespaces().Create(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "default",
+			Labels: map[string]string{osmMonitoredByLabel: "osm"},
+		},
+	})
+	return &OsmRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+		smiClient:  mocks.meshClient,
+	}
+}
+
+func TestOsmRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newOsmTestRouter(mocks)
+
+	err := router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ts, err := router.smiClient.SplitV1alpha3().TrafficSplits("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if ts.Spec.Service != "podinfo.default" {
+		t.Errorf("Got service %v wanted %v", ts.Spec.Service, "podinfo.default")
+	}
+	if len(ts.Spec.Matches) != 0 {
+		t.Errorf("Got matches %v wanted none", ts.Spec.Matches)
+	}
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 100 || c != 0 || m {
+		t.Errorf("Got routes %v/%v/%v wanted %v/%v/%v", p, c, m, 100, 0, false)
+	}
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// reconcile doesn't reset the weights
+	err = router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, _, err = router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 60 || c != 40 {
+		t.Errorf("Got routes %v/%v wanted %v/%v", p, c, 60, 40)
+	}
+}
+
+func TestOsmRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newOsmTestRouter(mocks)
+
+	cd := mocks.canary.DeepCopy()
+	cd.GetAnalysis().Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-canary": {Exact: "insider"},
+			},
+		},
+		{
+			Uri:    &istiov1alpha1.StringMatch{Prefix: "/api/v2"},
+			Method: &istiov1alpha1.StringMatch{Exact: "GET"},
+		},
+	}
+
+	err := router.Reconcile(cd)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	rg, err := router.smiClient.SpecsV1alpha3().HTTPRouteGroups("default").Get("podinfo-ab-test", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(rg.Spec.Matches) != 2 {
+		t.Fatalf("Got matches %v wanted 2", len(rg.Spec.Matches))
+	}
+	if rg.Spec.Matches[0].Headers["x-canary"] != "^insider$" {
+		t.Errorf("Got header regex %v wanted %v", rg.Spec.Matches[0].Headers["x-canary"], "^insider$")
+	}
+	if rg.Spec.Matches[1].PathRegex != "^/api/v2" || rg.Spec.Matches[1].Methods[0] != "GET" {
+		t.Errorf("Got path %v methods %v", rg.Spec.Matches[1].PathRegex, rg.Spec.Matches[1].Methods)
+	}
+
+	ts, err := router.smiClient.SplitV1alpha3().TrafficSplits("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(ts.Spec.Matches) != 1 || ts.Spec.Matches[0].Kind != "HTTPRouteGroup" || ts.Spec.Matches[0].Name != "podinfo-ab-test" {
+		t.Errorf("Got matches %v wanted podinfo-ab-test route group", ts.Spec.Matches)
+	}
+
+	// removing the conditions removes the route group
+	err = router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = router.smiClient.SpecsV1alpha3().HTTPRouteGroups("default").Get("podinfo-ab-test", metav1.GetOptions{})
+	if err == nil {
+		t.Error("Expected the route group to be deleted")
+	}
+
+	ts, err = router.smiClient.SplitV1alpha3().TrafficSplits("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(ts.Spec.Matches) != 0 {
+		t.Errorf("Got matches %v wanted none", ts.Spec.Matches)
+	}
+}
+
+func TestOsmRouter_UnmonitoredNamespace(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &OsmRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+		smiClient:  mocks.meshClient,
+	}
+	mocks.kubeClient.CoreV1().Namespaces().Create(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+	})
+
+	err := router.Reconcile(mocks.canary)
+	if err == nil {
+		t.Error("Expected error for namespace without the OSM label")
+	}
+}
+
+func TestRunConformance_Osm(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newOsmTestRouter(mocks)
+
+	results := RunConformance(router, mocks.canary)
+
+	for _, result := range results {
+		if result.Check == "mirror" {
+			if !result.Skipped {
+				t.Errorf("Check mirror should be skipped")
+			}
+			continue
+		}
+		if !result.Passed {
+			t.Errorf("Check %s failed: %s", result.Check, result.Message)
+		}
+	}
+}
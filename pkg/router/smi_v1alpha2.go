@@ -0,0 +1,176 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	smiv1alpha2 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha2"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// SmiV1alpha2Router is managing the split.smi-spec.io/v1alpha2 traffic split
+type SmiV1alpha2Router struct {
+	kubeClient kubernetes.Interface
+	smiClient  clientset.Interface
+	logger     *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the SMI traffic split
+func (sr *SmiV1alpha2Router) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	host := apexName
+	if len(canary.Spec.Service.Hosts) > 0 {
+		host = canary.Spec.Service.Hosts[0]
+	}
+
+	tsSpec := smiv1alpha2.TrafficSplitSpec{
+		Service: host,
+		Backends: []smiv1alpha2.TrafficSplitBackend{
+			{
+				Service: canaryName,
+				Weight:  0,
+			},
+			{
+				Service: primaryName,
+				Weight:  100,
+			},
+		},
+	}
+
+	ts, err := sr.smiClient.SplitV1alpha2().TrafficSplits(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	// create traffic split
+	if errors.IsNotFound(err) {
+		t := &smiv1alpha2.TrafficSplit{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apexName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: tsSpec,
+		}
+
+		_, err := sr.smiClient.SplitV1alpha2().TrafficSplits(canary.Namespace).Create(t)
+		if err != nil {
+			return err
+		}
+
+		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficSplit %s.%s created", t.GetName(), canary.Namespace)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("TrafficSplit %s query error %v", apexName, err)
+	}
+
+	// update traffic split, the weights are managed by SetRoutes
+	if tsSpec.Service != ts.Spec.Service || !cmp.Equal(backendServices(tsSpec.Backends), backendServices(ts.Spec.Backends)) {
+		tsClone := ts.DeepCopy()
+		tsClone.Spec = tsSpec
+
+		_, err := sr.smiClient.SplitV1alpha2().TrafficSplits(canary.Namespace).Update(tsClone)
+		if err != nil {
+			return fmt.Errorf("TrafficSplit %s update error %v", apexName, err)
+		}
+
+		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficSplit %s.%s updated", apexName, canary.Namespace)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the destinations weight for primary and canary
+func (sr *SmiV1alpha2Router) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	ts, err := sr.smiClient.SplitV1alpha2().TrafficSplits(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			err = fmt.Errorf("TrafficSplit %s.%s not found", apexName, canary.Namespace)
+			return
+		}
+		err = fmt.Errorf("TrafficSplit %s.%s query error %v", apexName, canary.Namespace, err)
+		return
+	}
+
+	for _, r := range ts.Spec.Backends {
+		if r.Service == primaryName {
+			primaryWeight = r.Weight
+		}
+		if r.Service == canaryName {
+			canaryWeight = r.Weight
+		}
+	}
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		err = fmt.Errorf("TrafficSplit %s.%s does not contain routes for %s and %s",
+			apexName, canary.Namespace, primaryName, canaryName)
+	}
+
+	mirrored = false
+
+	return
+}
+
+// SetRoutes updates the destinations weight for primary and canary
+func (sr *SmiV1alpha2Router) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	ts, err := sr.smiClient.SplitV1alpha2().TrafficSplits(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("TrafficSplit %s.%s not found", apexName, canary.Namespace)
+		}
+		return fmt.Errorf("TrafficSplit %s.%s query error %v", apexName, canary.Namespace, err)
+	}
+
+	tsClone := ts.DeepCopy()
+	tsClone.Spec.Backends = []smiv1alpha2.TrafficSplitBackend{
+		{
+			Service: canaryName,
+			Weight:  canaryWeight,
+		},
+		{
+			Service: primaryName,
+			Weight:  primaryWeight,
+		},
+	}
+
+	_, err = sr.smiClient.SplitV1alpha2().TrafficSplits(canary.Namespace).Update(tsClone)
+	if err != nil {
+		return fmt.Errorf("TrafficSplit %s update error %v", apexName, err)
+	}
+
+	return nil
+}
+
+func backendServices(backends []smiv1alpha2.TrafficSplitBackend) []string {
+	var services []string
+	for _, b := range backends {
+		services = append(services, b.Service)
+	}
+	return services
+}
@@ -0,0 +1,66 @@
+package router
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSmiV1alpha2Router_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &SmiV1alpha2Router{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+		smiClient:  mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ts, err := router.smiClient.SplitV1alpha2().TrafficSplits("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(ts.Spec.Backends) != 2 {
+		t.Errorf("Got backends %v wanted 2", len(ts.Spec.Backends))
+	}
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// reconcile doesn't reset the weights
+	err = router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 60 || c != 40 {
+		t.Errorf("Got routes %v/%v wanted %v/%v", p, c, 60, 40)
+	}
+}
+
+func TestFactory_SmiVersion(t *testing.T) {
+	mocks := newFixture(nil)
+	factory := NewFactory(nil, mocks.kubeClient, mocks.flaggerClient, "", mocks.logger, mocks.meshClient)
+
+	if _, ok := factory.MeshRouter("linkerd").(*SmiRouter); !ok {
+		t.Error("Expected the v1alpha1 router for linkerd")
+	}
+	if _, ok := factory.MeshRouter("linkerd:v1alpha2").(*SmiV1alpha2Router); !ok {
+		t.Error("Expected the v1alpha2 router for linkerd:v1alpha2")
+	}
+	if _, ok := factory.MeshRouter("smi:v1alpha3").(*SmiV1alpha3Router); !ok {
+		t.Error("Expected the v1alpha3 router for smi:v1alpha3")
+	}
+	if r, ok := factory.MeshRouter("smi:istio").(*SmiRouter); !ok || r.targetMesh != "istio" {
+		t.Error("Expected the v1alpha1 router with the istio target for smi:istio")
+	}
+}
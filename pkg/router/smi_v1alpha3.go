@@ -0,0 +1,263 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	smiv1alpha3 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha3"
+	specsv1alpha3 "github.com/weaveworks/flagger/pkg/apis/smispecs/v1alpha3"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// SmiV1alpha3Router is managing the split.smi-spec.io/v1alpha3 traffic split,
+// for A/B testing the traffic split matches the HTTP route group of the canary conditions
+type SmiV1alpha3Router struct {
+	kubeClient kubernetes.Interface
+	smiClient  clientset.Interface
+	logger     *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the SMI traffic split and the A/B testing route group
+func (sr *SmiV1alpha3Router) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	var matches []corev1.TypedLocalObjectReference
+	if len(canary.GetAnalysis().Match) > 0 {
+		if err := reconcileSmiRouteGroup(sr.smiClient, sr.logger, canary); err != nil {
+			return err
+		}
+		apiGroup := specsv1alpha3.SchemeGroupVersion.Group
+		matches = []corev1.TypedLocalObjectReference{
+			{
+				APIGroup: &apiGroup,
+				Kind:     "HTTPRouteGroup",
+				Name:     smiRouteGroupName(canary),
+			},
+		}
+	} else if err := deleteSmiRouteGroup(sr.smiClient, canary); err != nil {
+		return err
+	}
+
+	host := apexName
+	if len(canary.Spec.Service.Hosts) > 0 {
+		host = canary.Spec.Service.Hosts[0]
+	}
+
+	tsSpec := smiv1alpha3.TrafficSplitSpec{
+		Service: host,
+		Matches: matches,
+		Backends: []smiv1alpha3.TrafficSplitBackend{
+			{
+				Service: canaryName,
+				Weight:  0,
+			},
+			{
+				Service: primaryName,
+				Weight:  100,
+			},
+		},
+	}
+
+	ts, err := sr.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	// create traffic split
+	if errors.IsNotFound(err) {
+		t := &smiv1alpha3.TrafficSplit{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apexName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: tsSpec,
+		}
+
+		_, err := sr.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Create(t)
+		if err != nil {
+			return err
+		}
+
+		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficSplit %s.%s created", t.GetName(), canary.Namespace)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("TrafficSplit %s query error %v", apexName, err)
+	}
+
+	// update traffic split, the weights are managed by SetRoutes
+	if tsSpec.Service != ts.Spec.Service || !cmp.Equal(tsSpec.Matches, ts.Spec.Matches) {
+		tsClone := ts.DeepCopy()
+		tsClone.Spec.Service = tsSpec.Service
+		tsClone.Spec.Matches = tsSpec.Matches
+		if len(tsClone.Spec.Backends) == 0 {
+			tsClone.Spec.Backends = tsSpec.Backends
+		}
+
+		_, err := sr.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Update(tsClone)
+		if err != nil {
+			return fmt.Errorf("TrafficSplit %s update error %v", apexName, err)
+		}
+
+		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficSplit %s.%s updated", apexName, canary.Namespace)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the destinations weight for primary and canary
+func (sr *SmiV1alpha3Router) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	ts, err := sr.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			err = fmt.Errorf("TrafficSplit %s.%s not found", apexName, canary.Namespace)
+			return
+		}
+		err = fmt.Errorf("TrafficSplit %s.%s query error %v", apexName, canary.Namespace, err)
+		return
+	}
+
+	for _, r := range ts.Spec.Backends {
+		if r.Service == primaryName {
+			primaryWeight = r.Weight
+		}
+		if r.Service == canaryName {
+			canaryWeight = r.Weight
+		}
+	}
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		err = fmt.Errorf("TrafficSplit %s.%s does not contain routes for %s and %s",
+			apexName, canary.Namespace, primaryName, canaryName)
+	}
+
+	mirrored = false
+
+	return
+}
+
+// SetRoutes updates the destinations weight for primary and canary,
+// during A/B testing the weights apply to the requests matching the route group
+func (sr *SmiV1alpha3Router) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	ts, err := sr.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Get(apexName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("TrafficSplit %s.%s not found", apexName, canary.Namespace)
+		}
+		return fmt.Errorf("TrafficSplit %s.%s query error %v", apexName, canary.Namespace, err)
+	}
+
+	tsClone := ts.DeepCopy()
+	tsClone.Spec.Backends = []smiv1alpha3.TrafficSplitBackend{
+		{
+			Service: canaryName,
+			Weight:  canaryWeight,
+		},
+		{
+			Service: primaryName,
+			Weight:  primaryWeight,
+		},
+	}
+
+	_, err = sr.smiClient.SplitV1alpha3().TrafficSplits(canary.Namespace).Update(tsClone)
+	if err != nil {
+		return fmt.Errorf("TrafficSplit %s update error %v", apexName, err)
+	}
+
+	return nil
+}
+
+func smiRouteGroupName(canary *flaggerv1.Canary) string {
+	apexName, _, _ := canary.GetServiceNames()
+	return fmt.Sprintf("%s-ab-test", apexName)
+}
+
+// reconcileSmiRouteGroup creates or updates the HTTP route group holding the A/B testing conditions
+func reconcileSmiRouteGroup(smiClient clientset.Interface, logger *zap.SugaredLogger, canary *flaggerv1.Canary) error {
+	name := smiRouteGroupName(canary)
+	spec := specsv1alpha3.HTTPRouteGroupSpec{
+		Matches: makeHTTPRouteGroupMatches(canary),
+	}
+
+	rg, err := smiClient.SpecsV1alpha3().HTTPRouteGroups(canary.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		rg = &specsv1alpha3.HTTPRouteGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: spec,
+		}
+
+		_, err := smiClient.SpecsV1alpha3().HTTPRouteGroups(canary.Namespace).Create(rg)
+		if err != nil {
+			return fmt.Errorf("HTTPRouteGroup %s.%s create error %v", name, canary.Namespace, err)
+		}
+
+		logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("HTTPRouteGroup %s.%s created", name, canary.Namespace)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("HTTPRouteGroup %s.%s query error %v", name, canary.Namespace, err)
+	}
+
+	if diff := cmp.Diff(spec, rg.Spec); diff != "" {
+		rgClone := rg.DeepCopy()
+		rgClone.Spec = spec
+
+		_, err := smiClient.SpecsV1alpha3().HTTPRouteGroups(canary.Namespace).Update(rgClone)
+		if err != nil {
+			return fmt.Errorf("HTTPRouteGroup %s.%s update error %v", name, canary.Namespace, err)
+		}
+
+		logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("HTTPRouteGroup %s.%s updated", name, canary.Namespace)
+	}
+
+	return nil
+}
+
+// deleteSmiRouteGroup removes the A/B testing route group when the match conditions are removed
+func deleteSmiRouteGroup(smiClient clientset.Interface, canary *flaggerv1.Canary) error {
+	name := smiRouteGroupName(canary)
+	err := smiClient.SpecsV1alpha3().HTTPRouteGroups(canary.Namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("HTTPRouteGroup %s.%s delete error %v", name, canary.Namespace, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package router
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSmiV1alpha3Router_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &SmiV1alpha3Router{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+		smiClient:  mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ts, err := router.smiClient.SplitV1alpha3().TrafficSplits("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if ts.Spec.Service != "podinfo" {
+		t.Errorf("Got service %v wanted %v", ts.Spec.Service, "podinfo")
+	}
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// reconcile doesn't reset the weights
+	err = router.Reconcile(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if p != 60 || c != 40 {
+		t.Errorf("Got routes %v/%v wanted %v/%v", p, c, 60, 40)
+	}
+}
+
+func TestSmiV1alpha3Router_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &SmiV1alpha3Router{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+		smiClient:  mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.abtest)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	rg, err := router.smiClient.SpecsV1alpha3().HTTPRouteGroups("default").Get("abtest-ab-test", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(rg.Spec.Matches) != 1 {
+		t.Errorf("Got matches %v wanted 1", len(rg.Spec.Matches))
+	}
+
+	ts, err := router.smiClient.SplitV1alpha3().TrafficSplits("default").Get("abtest", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(ts.Spec.Matches) != 1 || ts.Spec.Matches[0].Name != "abtest-ab-test" {
+		t.Errorf("Got matches %v wanted abtest-ab-test route group", ts.Spec.Matches)
+	}
+}
+
+func TestRunConformance_SmiV1alpha3(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &SmiV1alpha3Router{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+		smiClient:  mocks.meshClient,
+	}
+
+	for _, result := range RunConformance(router, mocks.canary) {
+		if result.Check == "mirror" {
+			continue
+		}
+		if !result.Passed {
+			t.Errorf("Check %s failed: %s", result.Check, result.Message)
+		}
+	}
+}
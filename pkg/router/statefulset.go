@@ -0,0 +1,166 @@
+package router
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// holdPartition stops the rollout of the update revision to any pod, unlike the replicas count
+// it also holds the pods added by scaling up the StatefulSet after a rollback
+const holdPartition = math.MaxInt32
+
+// StatefulSetRouter shifts the canary weight by moving the rolling update partition of a StatefulSet,
+// the pods with an ordinal greater than or equal to the partition run the canary revision
+type StatefulSetRouter struct {
+	kubeClient kubernetes.Interface
+	logger     *zap.SugaredLogger
+}
+
+// Reconcile does nothing, the StatefulSet pods are not routed
+func (sr *StatefulSetRouter) Reconcile(canary *flaggerv1.Canary) error {
+	return nil
+}
+
+// SetRoutes sets the partition so that the canary weight percentage of pods is updated,
+// the canary pods below the new partition are deleted to be recreated at the current revision
+func (sr *StatefulSetRouter) SetRoutes(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int, _ bool) error {
+	// the promotion removes the partition, the rollout to all pods must not be reverted
+	if canary.Status.Phase == flaggerv1.CanaryPhasePromoting || canary.Status.Phase == flaggerv1.CanaryPhaseFinalising {
+		return nil
+	}
+
+	sts, err := sr.getStatefulSet(canary)
+	if err != nil {
+		return err
+	}
+
+	replicas := statefulSetReplicas(sts)
+	partition := partitionForWeight(replicas, canaryWeight)
+	current := sts.Spec.UpdateStrategy.RollingUpdate
+	if current == nil || current.Partition == nil || *current.Partition != partition {
+		stsCopy := sts.DeepCopy()
+		stsCopy.Spec.UpdateStrategy.Type = appsv1.RollingUpdateStatefulSetStrategyType
+		stsCopy.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{
+			Partition: &partition,
+		}
+		if _, err := sr.kubeClient.AppsV1().StatefulSets(sts.Namespace).Update(stsCopy); err != nil {
+			return fmt.Errorf("updating statefulset %s.%s partition to %v failed: %v", sts.Name, sts.Namespace, partition, err)
+		}
+	}
+
+	return sr.rollbackPods(canary, sts, partition)
+}
+
+// GetRoutes returns the canary weight recorded in the status if the partition matches it,
+// otherwise the percentage of pods above the partition
+func (sr *StatefulSetRouter) GetRoutes(canary *flaggerv1.Canary) (primaryWeight int, canaryWeight int, mirrored bool, err error) {
+	if canary.Status.Phase == flaggerv1.CanaryPhasePromoting || canary.Status.Phase == flaggerv1.CanaryPhaseFinalising {
+		return 100 - canary.Status.CanaryWeight, canary.Status.CanaryWeight, false, nil
+	}
+
+	sts, err := sr.getStatefulSet(canary)
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	replicas := statefulSetReplicas(sts)
+	// a partition above the replicas count holds all pods
+	partition := replicas
+	if ru := sts.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && *ru.Partition < replicas {
+		partition = *ru.Partition
+	}
+	expected := partitionForWeight(replicas, canary.Status.CanaryWeight)
+	if expected > replicas {
+		expected = replicas
+	}
+
+	canaryWeight = canary.Status.CanaryWeight
+	if expected != partition && replicas > 0 {
+		canaryWeight = int((replicas - partition) * 100 / replicas)
+	}
+	return 100 - canaryWeight, canaryWeight, false, nil
+}
+
+// rollbackPods deletes the pods below the partition that run the update revision,
+// the StatefulSet controller recreates them at the current revision
+func (sr *StatefulSetRouter) rollbackPods(canary *flaggerv1.Canary, sts *appsv1.StatefulSet, partition int32) error {
+	if sts.Status.UpdateRevision == "" || sts.Status.UpdateRevision == sts.Status.CurrentRevision {
+		return nil
+	}
+
+	pods, err := sr.kubeClient.CoreV1().Pods(sts.Namespace).List(metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(sts.Spec.Selector),
+	})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s pods query error %v", sts.Name, sts.Namespace, err)
+	}
+
+	for _, pod := range pods.Items {
+		ordinal, ok := podOrdinal(sts.Name, pod.Name)
+		if !ok || ordinal >= partition || pod.Labels[appsv1.StatefulSetRevisionLabel] != sts.Status.UpdateRevision {
+			continue
+		}
+		err := sr.kubeClient.CoreV1().Pods(pod.Namespace).Delete(pod.Name, &metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("deleting pod %s.%s failed: %v", pod.Name, pod.Namespace, err)
+		}
+		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Pod %s.%s deleted to roll back revision %s", pod.Name, pod.Namespace, sts.Status.UpdateRevision)
+	}
+	return nil
+}
+
+func (sr *StatefulSetRouter) getStatefulSet(canary *flaggerv1.Canary) (*appsv1.StatefulSet, error) {
+	targetName := canary.Spec.TargetRef.Name
+	sts, err := sr.kubeClient.AppsV1().StatefulSets(canary.Namespace).Get(targetName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("statefulset %s.%s not found", targetName, canary.Namespace)
+		}
+		return nil, fmt.Errorf("statefulset %s.%s query error %v", targetName, canary.Namespace, err)
+	}
+	return sts, nil
+}
+
+// partitionForWeight returns the partition that updates the canary weight percentage of pods,
+// rounded up so that any weight above zero updates at least one pod
+func partitionForWeight(replicas int32, canaryWeight int) int32 {
+	if canaryWeight <= 0 {
+		return holdPartition
+	}
+	if canaryWeight >= 100 {
+		return 0
+	}
+	canaryPods := (int(replicas)*canaryWeight + 99) / 100
+	return replicas - int32(canaryPods)
+}
+
+// statefulSetReplicas returns the desired replicas, one if not set
+func statefulSetReplicas(sts *appsv1.StatefulSet) int32 {
+	if sts.Spec.Replicas == nil {
+		return 1
+	}
+	return *sts.Spec.Replicas
+}
+
+// podOrdinal parses the ordinal of a StatefulSet pod from its name
+func podOrdinal(stsName string, podName string) (int32, bool) {
+	if !strings.HasPrefix(podName, stsName+"-") {
+		return 0, false
+	}
+	ordinal, err := strconv.ParseInt(strings.TrimPrefix(podName, stsName+"-"), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(ordinal), true
+}
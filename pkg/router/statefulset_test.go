@@ -0,0 +1,181 @@
+package router
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestStatefulSetRouter_SetRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &StatefulSetRouter{kubeClient: mocks.kubeClient, logger: mocks.logger}
+	createStatefulSetRouterTestPods(t, mocks, []string{"rev1", "rev1", "rev2", "rev2"})
+
+	// 30% of 4 pods is rounded up to 2 canary pods
+	err := router.SetRoutes(mocks.canary, 70, 30, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	assertStatefulSetRouterPartition(t, mocks, 2)
+
+	_, canaryWeight, _, err := router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if canaryWeight != 50 {
+		t.Errorf("Got canary weight %v wanted %v", canaryWeight, 50)
+	}
+
+	// the status weight is kept when it matches the partition
+	mocks.canary.Status.CanaryWeight = 30
+	_, canaryWeight, _, err = router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if canaryWeight != 30 {
+		t.Errorf("Got canary weight %v wanted %v", canaryWeight, 30)
+	}
+
+	// the rollback deletes the canary pods so that they are recreated at the current revision
+	err = router.SetRoutes(mocks.canary, 100, 0, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	assertStatefulSetRouterPartition(t, mocks, holdPartition)
+
+	pods, err := mocks.kubeClient.CoreV1().Pods("default").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(pods.Items) != 2 {
+		t.Fatalf("Got %v pods wanted %v", len(pods.Items), 2)
+	}
+	for _, pod := range pods.Items {
+		if pod.Labels[appsv1.StatefulSetRevisionLabel] != "rev1" {
+			t.Errorf("Pod %s at revision %s should have been deleted", pod.Name, pod.Labels[appsv1.StatefulSetRevisionLabel])
+		}
+	}
+
+	// scaling up after the rollback doesn't roll out the failed revision to the new pods
+	sts, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	replicas := int32(6)
+	sts.Spec.Replicas = &replicas
+	if _, err := mocks.kubeClient.AppsV1().StatefulSets("default").Update(sts); err != nil {
+		t.Fatal(err.Error())
+	}
+	if partition := *sts.Spec.UpdateStrategy.RollingUpdate.Partition; partition < replicas {
+		t.Errorf("Got partition %v below the %v replicas", partition, replicas)
+	}
+
+	mocks.canary.Status.CanaryWeight = 0
+	_, canaryWeight, _, err = router.GetRoutes(mocks.canary)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if canaryWeight != 0 {
+		t.Errorf("Got canary weight %v wanted %v", canaryWeight, 0)
+	}
+}
+
+func TestStatefulSetRouter_SetRoutesPromoting(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &StatefulSetRouter{kubeClient: mocks.kubeClient, logger: mocks.logger}
+	createStatefulSetRouterTestPods(t, mocks, []string{"rev2", "rev2", "rev2", "rev2"})
+
+	// the promotion rolls out the revision to all pods, routing the traffic to the primary must not revert it
+	mocks.canary.Status.Phase = flaggerv1.CanaryPhasePromoting
+	err := router.SetRoutes(mocks.canary, 100, 0, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	assertStatefulSetRouterPartition(t, mocks, 0)
+
+	pods, err := mocks.kubeClient.CoreV1().Pods("default").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(pods.Items) != 4 {
+		t.Errorf("Got %v pods wanted %v", len(pods.Items), 4)
+	}
+}
+
+func TestStatefulSetRouter_PartitionForWeight(t *testing.T) {
+	tests := []struct {
+		replicas  int32
+		weight    int
+		partition int32
+	}{
+		{replicas: 4, weight: 0, partition: holdPartition},
+		{replicas: 4, weight: 10, partition: 3},
+		{replicas: 4, weight: 25, partition: 3},
+		{replicas: 4, weight: 50, partition: 2},
+		{replicas: 4, weight: 100, partition: 0},
+		{replicas: 10, weight: 10, partition: 9},
+		{replicas: 1, weight: 50, partition: 0},
+	}
+	for _, tt := range tests {
+		if partition := partitionForWeight(tt.replicas, tt.weight); partition != tt.partition {
+			t.Errorf("Got partition %v for %v replicas at weight %v wanted %v", partition, tt.replicas, tt.weight, tt.partition)
+		}
+	}
+}
+
+// createStatefulSetRouterTestPods creates the podinfo StatefulSet with the current revision rev1,
+// the update revision rev2, a partition at 0 and one pod at the given revision per ordinal
+func createStatefulSetRouterTestPods(t *testing.T, mocks fixture, revisions []string) {
+	replicas := int32(len(revisions))
+	partition := int32(0)
+	sts := &appsv1.StatefulSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "podinfo"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "podinfo"}},
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{
+			CurrentRevision: "rev1",
+			UpdateRevision:  "rev2",
+		},
+	}
+	if _, err := mocks.kubeClient.AppsV1().StatefulSets("default").Create(sts); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for i, revision := range revisions {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      fmt.Sprintf("podinfo-%d", i),
+				Labels: map[string]string{
+					"app":                           "podinfo",
+					appsv1.StatefulSetRevisionLabel: revision,
+				},
+			},
+		}
+		if _, err := mocks.kubeClient.CoreV1().Pods("default").Create(pod); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+}
+
+func assertStatefulSetRouterPartition(t *testing.T, mocks fixture, expected int32) {
+	sts, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get("podinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if partition := *sts.Spec.UpdateStrategy.RollingUpdate.Partition; partition != expected {
+		t.Errorf("Got partition %v wanted %v", partition, expected)
+	}
+}
@@ -0,0 +1,49 @@
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var durationPart = regexp.MustCompile(`([0-9]*\.?[0-9]+)(ns|us|µs|ms|s|m|h|d|w)`)
+
+// ParseDuration parses a duration string like time.ParseDuration does
+// and also accepts the d (24h) and w (7d) units e.g. 1w, 1d12h
+func ParseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	var d time.Duration
+	rest := s
+	for rest != "" {
+		m := durationPart.FindStringSubmatchIndex(rest)
+		if m == nil || m[0] != 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		value, unit := rest[m[2]:m[3]], rest[m[4]:m[5]]
+		rest = rest[m[1]:]
+
+		switch unit {
+		case "d", "w":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			day := 24 * time.Hour
+			if unit == "w" {
+				day = 7 * day
+			}
+			d += time.Duration(f * float64(day))
+		default:
+			v, err := time.ParseDuration(value + unit)
+			if err != nil {
+				return 0, err
+			}
+			d += v
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,107 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"30s":     30 * time.Second,
+		"1m30s":   90 * time.Second,
+		"1d":      24 * time.Hour,
+		"1w":      7 * 24 * time.Hour,
+		"1d12h":   36 * time.Hour,
+		"0.5d":    12 * time.Hour,
+		"2w1d1ms": 15*24*time.Hour + time.Millisecond,
+	}
+
+	for s, want := range tests {
+		got, err := ParseDuration(s)
+		if err != nil {
+			t.Fatalf("%s error: %v", s, err)
+		}
+		if got != want {
+			t.Errorf("%s got %v wanted %v", s, got, want)
+		}
+	}
+
+	for _, s := range []string{"", "1", "d", "1y", "1d 2h", "-1d"} {
+		if _, err := ParseDuration(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	tests := []struct {
+		expr     string
+		timezone string
+		from     time.Time
+		want     time.Time
+	}{
+		{"*/15 * * * *", "", time.Date(2020, 3, 2, 10, 7, 30, 0, time.UTC), time.Date(2020, 3, 2, 10, 15, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", "", time.Date(2020, 3, 6, 9, 0, 0, 0, time.UTC), time.Date(2020, 3, 9, 9, 0, 0, 0, time.UTC)},
+		{"@daily", "", time.Date(2020, 12, 31, 23, 59, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", "", time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 * 0", "", time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2020, 3, 8, 12, 0, 0, 0, time.UTC)},
+		{"30 1 * * 7", "", time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2020, 3, 8, 1, 30, 0, 0, time.UTC)},
+		// 09:00 in London is 08:00 UTC during the summer time
+		{"0 9 * * *", "Europe/London", time.Date(2020, 6, 1, 7, 0, 0, 0, time.UTC), time.Date(2020, 6, 1, 9, 0, 0, 0, london)},
+		// 01:30 does not exist on the day the clocks go forward
+		{"30 1 * * *", "Europe/London", time.Date(2020, 3, 28, 12, 0, 0, 0, london), time.Date(2020, 3, 30, 1, 30, 0, 0, london)},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr, tt.timezone)
+		if err != nil {
+			t.Fatalf("%s error: %v", tt.expr, err)
+		}
+		if got := c.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%s from %v got %v wanted %v", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestCron_Matches(t *testing.T) {
+	tests := []struct {
+		expr string
+		at   time.Time
+		want bool
+	}{
+		// Friday 15:59 and 16:00
+		{"* 9-15 * * mon-fri", time.Date(2020, 3, 6, 15, 59, 30, 0, time.UTC), true},
+		{"* 9-15 * * mon-fri", time.Date(2020, 3, 6, 16, 0, 0, 0, time.UTC), false},
+		// Saturday
+		{"* 9-15 * * mon-fri", time.Date(2020, 3, 7, 10, 0, 0, 0, time.UTC), false},
+		{"*/15 * * * *", time.Date(2020, 3, 2, 10, 30, 0, 0, time.UTC), true},
+		{"*/15 * * * *", time.Date(2020, 3, 2, 10, 31, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr, "")
+		if err != nil {
+			t.Fatalf("%s error: %v", tt.expr, err)
+		}
+		if got := c.Matches(tt.at); got != tt.want {
+			t.Errorf("%s at %v got %v wanted %v", tt.expr, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestParseCron_Errors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "@often"} {
+		if _, err := ParseCron(expr, ""); err == nil {
+			t.Errorf("Expected an error for %q", expr)
+		}
+	}
+
+	if _, err := ParseCron("* * * * *", "Mars/Olympus"); err == nil {
+		t.Errorf("Expected an error for an unknown timezone")
+	}
+}
@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// AESGCMDecryptor decrypts the values encrypted with AES-256-GCM and wrapped in the envelope
+// ENC[AES256_GCM,data:<base64>,iv:<base64>,tag:<base64>,type:str]
+// The key is used as is and the values are sealed without additional data,
+// this is not SOPS: the values encrypted by the sops CLI or with a KMS data key can't be decrypted
+type AESGCMDecryptor struct {
+	key []byte
+}
+
+// NewAESGCMDecryptor reads a 32 bytes key, raw or base64 encoded, from the given file
+func NewAESGCMDecryptor(keyFile string) (*AESGCMDecryptor, error) {
+	b, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading decryption key: %s", err.Error())
+	}
+
+	key := b
+	if len(key) != 32 {
+		key = bytes.TrimRight(b, "\r\n")
+	}
+	if len(key) != 32 {
+		key, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("decryption key %s must contain 32 bytes, raw or base64 encoded", keyFile)
+		}
+	}
+
+	return &AESGCMDecryptor{key: key}, nil
+}
+
+// Decrypt parses the ENC[...] envelope and decrypts the data
+func (d *AESGCMDecryptor) Decrypt(value []byte) ([]byte, error) {
+	fields, err := parseEnvelope(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields["type"] != "str" && fields["type"] != "bytes" {
+		return nil, fmt.Errorf("unsupported value type %s", fields["type"])
+	}
+
+	parts := make(map[string][]byte)
+	for _, name := range []string{"data", "iv", "tag"} {
+		b, err := base64.StdEncoding.DecodeString(fields[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", name, err.Error())
+		}
+		parts[name] = b
+	}
+
+	block, err := aes.NewCipher(d.key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parts["iv"]) == 0 {
+		return nil, fmt.Errorf("invalid iv")
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(parts["iv"]))
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := gcm.Open(nil, parts["iv"], append(parts["data"], parts["tag"]...), nil)
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed, check the decryption key")
+	}
+	return plain, nil
+}
+
+// parseEnvelope returns the comma separated key:value fields of an AES256_GCM envelope
+func parseEnvelope(value []byte) (map[string]string, error) {
+	v := strings.TrimSpace(string(value))
+	v = strings.TrimSuffix(strings.TrimPrefix(v, encryptedPrefix), "]")
+
+	items := strings.Split(v, ",")
+	if len(items) < 1 || items[0] != "AES256_GCM" {
+		return nil, fmt.Errorf("unsupported encryption method %s", items[0])
+	}
+
+	fields := make(map[string]string)
+	for _, item := range items[1:] {
+		kv := strings.SplitN(item, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid envelope field %s", item)
+		}
+		fields[kv[0]] = kv[1]
+	}
+
+	for _, name := range []string{"data", "iv", "tag", "type"} {
+		if _, ok := fields[name]; !ok {
+			return nil, fmt.Errorf("envelope does not contain %s", name)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+)
+
+const encryptedPrefix = "ENC["
+
+// Decryptor decrypts the encrypted values of the provider credentials
+type Decryptor interface {
+	Decrypt(value []byte) ([]byte, error)
+}
+
+// IsEncrypted returns true if the value is wrapped in the ENC[...] envelope
+func IsEncrypted(value []byte) bool {
+	v := strings.TrimSpace(string(value))
+	return strings.HasPrefix(v, encryptedPrefix) && strings.HasSuffix(v, "]")
+}
+
+// DecryptData returns a copy of the secret data with the encrypted values decrypted in memory,
+// the plaintext values are copied as they are
+func DecryptData(decryptor Decryptor, data map[string][]byte) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(data))
+	for k, v := range data {
+		if !IsEncrypted(v) {
+			result[k] = v
+			continue
+		}
+
+		if decryptor == nil {
+			return nil, fmt.Errorf("%s is encrypted but no decryption key is configured", k)
+		}
+
+		plain, err := decryptor.Decrypt(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s decryption failed: %s", k, err.Error())
+		}
+		result[k] = plain
+	}
+	return result, nil
+}
@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func encrypt(t *testing.T, key []byte, plain string) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	iv := make([]byte, 32)
+	rand.Read(iv)
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	out := gcm.Seal(nil, iv, []byte(plain), nil)
+	data, tag := out[:len(out)-gcm.Overhead()], out[len(out)-gcm.Overhead():]
+	return []byte(fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:str]",
+		base64.StdEncoding.EncodeToString(data),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(tag)))
+}
+
+func writeKey(t *testing.T, key []byte) string {
+	dir, err := ioutil.TempDir("", "flagger-secrets")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	path := filepath.Join(dir, "key")
+	if err := ioutil.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+	return path
+}
+
+func TestAESGCMDecryptor_Decrypt(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	path := writeKey(t, key)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	d, err := NewAESGCMDecryptor(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	data := map[string][]byte{
+		"datadog_api_key": encrypt(t, key, "api-key"),
+		"address":         []byte("https://api.datadoghq.com"),
+	}
+
+	result, err := DecryptData(d, data)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if string(result["datadog_api_key"]) != "api-key" {
+		t.Errorf("Got %s wanted %s", result["datadog_api_key"], "api-key")
+	}
+
+	if string(result["address"]) != "https://api.datadoghq.com" {
+		t.Errorf("Got %s wanted the plaintext value", result["address"])
+	}
+
+	if !IsEncrypted(data["datadog_api_key"]) {
+		t.Errorf("Expected the source data to remain encrypted")
+	}
+}
+
+func TestAESGCMDecryptor_WrongKey(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	path := writeKey(t, key)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	d, err := NewAESGCMDecryptor(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	other := make([]byte, 32)
+	rand.Read(other)
+
+	_, err = d.Decrypt(encrypt(t, other, "api-key"))
+	if err == nil {
+		t.Errorf("Expected an authentication error")
+	}
+}
+
+func TestDecryptData_NoDecryptor(t *testing.T) {
+	_, err := DecryptData(nil, map[string][]byte{"token": []byte("ENC[AES256_GCM,data:a,iv:b,tag:c,type:str]")})
+	if err == nil {
+		t.Errorf("Expected an error for encrypted values without a decryptor")
+	}
+
+	result, err := DecryptData(nil, map[string][]byte{"token": []byte("plain")})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(result["token"]) != "plain" {
+		t.Errorf("Got %s wanted %s", result["token"], "plain")
+	}
+}
+
+func TestExecDecryptor_Decrypt(t *testing.T) {
+	d, err := NewExecDecryptor("tr a-z A-Z", 5*time.Second)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	result, err := d.Decrypt([]byte("ENC[kms,secret]\n"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if string(result) != "ENC[KMS,SECRET]" {
+		t.Errorf("Got %s wanted %s", result, "ENC[KMS,SECRET]")
+	}
+}
+
+func TestAESGCMDecryptor_RawKeyWithNewline(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	// make sure the raw key doesn't end in a newline itself
+	key[31] = 'k'
+
+	dir, err := ioutil.TempDir("", "flagger-secrets")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "key")
+	if err := ioutil.WriteFile(path, append(append([]byte{}, key...), '\n'), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	d, err := NewAESGCMDecryptor(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	plain, err := d.Decrypt(encrypt(t, key, "api-key"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(plain) != "api-key" {
+		t.Errorf("Got %s wanted %s", plain, "api-key")
+	}
+}
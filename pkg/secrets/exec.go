@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ExecDecryptor delegates the decryption to an external command,
+// the encrypted value is written to stdin and the plaintext is read from stdout
+type ExecDecryptor struct {
+	command string
+	args    []string
+	timeout time.Duration
+}
+
+// NewExecDecryptor splits the command line on spaces and returns an exec decryptor
+func NewExecDecryptor(command string, timeout time.Duration) (*ExecDecryptor, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty decryption command")
+	}
+
+	return &ExecDecryptor{
+		command: fields[0],
+		args:    fields[1:],
+		timeout: timeout,
+	}, nil
+}
+
+// Decrypt runs the command and returns its output
+func (d *ExecDecryptor) Decrypt(value []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, d.command, d.args...)
+	cmd.Stdin = bytes.NewReader(bytes.TrimSpace(value))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("decryption command failed: %s %s", err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
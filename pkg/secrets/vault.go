@@ -0,0 +1,221 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// VaultDefaultAuthMount is the mount path of the Vault Kubernetes auth method
+	VaultDefaultAuthMount = "kubernetes"
+	// VaultDefaultRolePrefix is prepended to the metric template namespace to get the Vault role
+	VaultDefaultRolePrefix = "flagger-"
+
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// VaultClient reads the provider credentials from Vault,
+// it authenticates with the Kubernetes auth method using the Flagger service account token
+// and caches the Vault tokens per role until they expire.
+// The server and the auth method are set on the controller and the role is derived from
+// the namespace of the metric template, so that a template can only read the secrets
+// granted to its namespace and the Flagger token is never sent to another server.
+type VaultClient struct {
+	address    string
+	authMount  string
+	rolePrefix string
+	tokenPath  string
+	timeout    time.Duration
+	client     *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]vaultToken
+}
+
+type vaultToken struct {
+	token   string
+	expires time.Time
+}
+
+type vaultLoginResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+	} `json:"auth"`
+}
+
+type vaultSecretResponse struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// NewVaultClient returns a client for the Vault server at the address,
+// the auth mount and role prefix default to kubernetes and flagger-
+func NewVaultClient(address string, authMount string, rolePrefix string, timeout time.Duration) *VaultClient {
+	if authMount == "" {
+		authMount = VaultDefaultAuthMount
+	}
+	if rolePrefix == "" {
+		rolePrefix = VaultDefaultRolePrefix
+	}
+	return &VaultClient{
+		address:    strings.TrimSuffix(address, "/"),
+		authMount:  strings.Trim(authMount, "/"),
+		rolePrefix: rolePrefix,
+		tokenPath:  serviceAccountTokenPath,
+		timeout:    timeout,
+		client:     http.DefaultClient,
+		tokens:     make(map[string]vaultToken),
+	}
+}
+
+// Role returns the Vault role used to read the secrets of the namespace
+func (v *VaultClient) Role(namespace string) string {
+	return v.rolePrefix + namespace
+}
+
+// Read logs in with the role of the namespace and returns the string values of the secret at path,
+// both KV version 1 and version 2 secret engines are supported
+func (v *VaultClient) Read(namespace string, path string) (map[string][]byte, error) {
+	if v.address == "" {
+		return nil, fmt.Errorf("vault address is not set")
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("vault secret %s namespace is not set", path)
+	}
+	role := v.Role(namespace)
+
+	token, err := v.login(role)
+	if err != nil {
+		return nil, err
+	}
+
+	b, status, err := v.do("GET", fmt.Sprintf("%s/v1/%s", v.address, strings.TrimPrefix(path, "/")), token, nil)
+	if err != nil {
+		return nil, fmt.Errorf("vault read %s failed: %s", path, err.Error())
+	}
+	if status == http.StatusForbidden {
+		// the token was revoked before its lease expired
+		v.forget(role)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("vault read %s failed with status %d: %s", path, status, strings.TrimSpace(string(b)))
+	}
+
+	var res vaultSecretResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, fmt.Errorf("error unmarshaling vault secret %s: %s", path, err.Error())
+	}
+
+	data := res.Data
+	// KV version 2 nests the values under data.data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("vault secret %s is empty", path)
+	}
+
+	result := make(map[string][]byte, len(data))
+	for k, val := range data {
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("vault secret %s key %s is not a string", path, k)
+		}
+		result[k] = []byte(s)
+	}
+	return result, nil
+}
+
+// login returns a cached token or authenticates with the Kubernetes auth method
+func (v *VaultClient) login(role string) (string, error) {
+	v.mu.Lock()
+	t, ok := v.tokens[role]
+	v.mu.Unlock()
+	if ok && time.Now().Before(t.expires) {
+		return t.token, nil
+	}
+
+	jwt, err := ioutil.ReadFile(v.tokenPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading service account token: %s", err.Error())
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"role": role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	b, status, err := v.do("POST", fmt.Sprintf("%s/v1/auth/%s/login", v.address, v.authMount), "", body)
+	if err != nil {
+		return "", fmt.Errorf("vault login failed: %s", err.Error())
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("vault login with role %s failed with status %d: %s", role, status, strings.TrimSpace(string(b)))
+	}
+
+	var res vaultLoginResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return "", fmt.Errorf("error unmarshaling vault login response: %s", err.Error())
+	}
+	if res.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault login with role %s returned no token", role)
+	}
+
+	// renew the token before the lease expires
+	lease := time.Duration(res.Auth.LeaseDuration) * time.Second
+	if lease <= 0 {
+		// tokens without a lease are renewed periodically
+		lease = 5 * time.Minute
+	}
+	v.mu.Lock()
+	v.tokens[role] = vaultToken{token: res.Auth.ClientToken, expires: time.Now().Add(lease * 9 / 10)}
+	v.mu.Unlock()
+
+	return res.Auth.ClientToken, nil
+}
+
+func (v *VaultClient) forget(role string) {
+	v.mu.Lock()
+	delete(v.tokens, role)
+	v.mu.Unlock()
+}
+
+func (v *VaultClient) do(method string, url string, token string, body []byte) ([]byte, int, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), v.timeout)
+	defer cancel()
+
+	r, err := v.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading body: %s", err.Error())
+	}
+	return b, r.StatusCode, nil
+}
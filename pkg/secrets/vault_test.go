@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newVaultServer(t *testing.T, logins *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			*logins++
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["role"] != "flagger-test" || body["jwt"] != "sa-token" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"auth":{"client_token":"s.token","lease_duration":3600}}`))
+		case "/v1/secret/data/datadog":
+			if r.Header.Get("X-Vault-Token") != "s.token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"data":{"data":{"datadog_api_key":"api","datadog_application_key":"app"},"metadata":{"version":1}}}`))
+		case "/v1/kv/datadog":
+			w.Write([]byte(`{"data":{"datadog_api_key":"api"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newVaultClient(t *testing.T, address string) (*VaultClient, func()) {
+	dir, err := ioutil.TempDir("", "vault")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tokenPath := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenPath, []byte("sa-token\n"), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	client := NewVaultClient(address, "", "", time.Second)
+	client.tokenPath = tokenPath
+	return client, func() { os.RemoveAll(dir) }
+}
+
+func TestVaultClient_Read(t *testing.T) {
+	logins := 0
+	ts := newVaultServer(t, &logins)
+	defer ts.Close()
+
+	client, cleanup := newVaultClient(t, ts.URL)
+	defer cleanup()
+
+	data, err := client.Read("test", "secret/data/datadog")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(data["datadog_api_key"]) != "api" || string(data["datadog_application_key"]) != "app" {
+		t.Errorf("Got %v wanted the KV v2 values", data)
+	}
+
+	data, err = client.Read("test", "/kv/datadog")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(data["datadog_api_key"]) != "api" {
+		t.Errorf("Got %v wanted the KV v1 values", data)
+	}
+
+	if logins != 1 {
+		t.Errorf("Got %d logins wanted the token to be cached", logins)
+	}
+}
+
+func TestVaultClient_ReadErrors(t *testing.T) {
+	logins := 0
+	ts := newVaultServer(t, &logins)
+	defer ts.Close()
+
+	client, cleanup := newVaultClient(t, ts.URL)
+	defer cleanup()
+
+	// the role of another namespace isn't bound in Vault
+	if _, err := client.Read("other", "secret/data/datadog"); err == nil {
+		t.Errorf("Expected a login error for the role of another namespace")
+	}
+
+	if _, err := client.Read("test", "secret/data/missing"); err == nil {
+		t.Errorf("Expected an error for a missing secret")
+	}
+
+	noAddress, cleanupNoAddress := newVaultClient(t, "")
+	defer cleanupNoAddress()
+	if _, err := noAddress.Read("test", "secret/data/datadog"); err == nil {
+		t.Errorf("Expected an error without a Vault address")
+	}
+}
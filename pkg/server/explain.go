@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	flaggerlisters "github.com/weaveworks/flagger/pkg/client/listers/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/controller"
+)
+
+// Explainer describes the decision inputs of the current canary phase
+type Explainer interface {
+	Explain(cd *flaggerv1.Canary) (controller.Explanation, error)
+}
+
+// ExplainResponse is the JSON document returned by the explain endpoint
+type ExplainResponse struct {
+	Name         string         `json:"name"`
+	Namespace    string         `json:"namespace"`
+	Phase        string         `json:"phase"`
+	CanaryWeight int            `json:"canaryWeight"`
+	Iterations   int            `json:"iterations"`
+	FailedChecks int            `json:"failedChecks"`
+	Threshold    int            `json:"threshold"`
+	Checks       []ExplainCheck `json:"checks"`
+	Gate         *ExplainGate   `json:"gate,omitempty"`
+	NextAction   string         `json:"nextAction"`
+	NextRun      *time.Time     `json:"nextRun,omitempty"`
+	PromotionETA *time.Time     `json:"promotionETA,omitempty"`
+}
+
+// ExplainCheck is the last result of a metric check
+type ExplainCheck struct {
+	Name      string    `json:"name"`
+	Passed    bool      `json:"passed"`
+	Value     float64   `json:"value"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ExplainGate is the gate or check blocking the canary advancement
+type ExplainGate struct {
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ExplainHandler serves the explanation of the current canary phase
+// on GET /explain?name=<name>&namespace=<namespace>, as JSON or as plain text with format=text
+func ExplainHandler(lister flaggerlisters.CanaryLister, explainer Explainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name, namespace := r.URL.Query().Get("name"), r.URL.Query().Get("namespace")
+		if name == "" || namespace == "" {
+			http.Error(w, "name and namespace are required", http.StatusBadRequest)
+			return
+		}
+
+		cd, err := lister.Canaries(namespace).Get(name)
+		if errors.IsNotFound(err) {
+			http.Error(w, fmt.Sprintf("canary %s.%s not found", name, namespace), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("canary %s.%s query error: %v", name, namespace, err), http.StatusInternalServerError)
+			return
+		}
+
+		e, err := explainer.Explain(cd)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		res := ExplainResponse{
+			Name:         e.Name,
+			Namespace:    e.Namespace,
+			Phase:        string(e.Phase),
+			CanaryWeight: e.CanaryWeight,
+			Iterations:   e.Iterations,
+			FailedChecks: e.FailedChecks,
+			Threshold:    e.Threshold,
+			Checks:       []ExplainCheck{},
+			NextAction:   e.NextAction,
+			NextRun:      e.NextRun,
+			PromotionETA: e.PromotionETA,
+		}
+		for _, check := range e.Checks {
+			res.Checks = append(res.Checks, ExplainCheck{
+				Name:      check.Name,
+				Passed:    check.Passed,
+				Value:     check.Value,
+				Error:     check.Error,
+				Timestamp: check.Timestamp,
+			})
+		}
+		if e.Gate != nil {
+			res.Gate = &ExplainGate{
+				Reason:    e.Gate.Reason,
+				Message:   e.Gate.Message,
+				Timestamp: e.Gate.Timestamp,
+			}
+		}
+
+		if r.URL.Query().Get("format") == "text" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			writeExplanation(w, res)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+// writeExplanation renders the explanation in a human-readable form
+func writeExplanation(w io.Writer, res ExplainResponse) {
+	fmt.Fprintf(w, "Canary %s.%s is %s", res.Name, res.Namespace, res.Phase)
+	if res.Phase == string(flaggerv1.CanaryPhaseProgressing) {
+		fmt.Fprintf(w, " at weight %v iteration %v", res.CanaryWeight, res.Iterations)
+	}
+	fmt.Fprintf(w, "\nFailed checks: %v/%v\n", res.FailedChecks, res.Threshold)
+
+	if len(res.Checks) > 0 {
+		fmt.Fprintf(w, "Checks:\n")
+	}
+	for _, check := range res.Checks {
+		switch {
+		case check.Error != "":
+			fmt.Fprintf(w, "  %s failed: %s\n", check.Name, check.Error)
+		case check.Passed:
+			fmt.Fprintf(w, "  %s passed: %v\n", check.Name, check.Value)
+		default:
+			fmt.Fprintf(w, "  %s failed: %v\n", check.Name, check.Value)
+		}
+	}
+
+	if res.Gate != nil {
+		fmt.Fprintf(w, "Blocked by %s: %s\n", res.Gate.Reason, res.Gate.Message)
+	}
+
+	fmt.Fprintf(w, "Next action: %s", res.NextAction)
+	if res.NextRun != nil {
+		fmt.Fprintf(w, " by %s", res.NextRun.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "\n")
+	if res.PromotionETA != nil {
+		fmt.Fprintf(w, "Promotion ETA: %s\n", res.PromotionETA.UTC().Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	flaggerlisters "github.com/weaveworks/flagger/pkg/client/listers/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/controller"
+)
+
+type fakeExplainer struct{}
+
+func (f fakeExplainer) Explain(cd *flaggerv1.Canary) (controller.Explanation, error) {
+	return controller.Explanation{
+		Name:         cd.Name,
+		Namespace:    cd.Namespace,
+		Phase:        cd.Status.Phase,
+		CanaryWeight: cd.Status.CanaryWeight,
+		FailedChecks: 1,
+		Threshold:    5,
+		Checks: []controller.MetricResult{
+			{Name: "request-duration", Value: 450, Passed: true, Timestamp: time.Now()},
+			{Name: "request-success-rate", Value: 95, Passed: false, Timestamp: time.Now()},
+		},
+		Gate:       &controller.Halt{Reason: "metrics", Message: "metric checks failed", Timestamp: time.Now()},
+		NextAction: "retry the analysis, the canary is rolled back after 4 more failed checks",
+	}, nil
+}
+
+func newTestExplainHandler(t *testing.T) http.HandlerFunc {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "test"},
+		Status:     flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, CanaryWeight: 10},
+	}
+	if err := indexer.Add(cd); err != nil {
+		t.Fatal(err.Error())
+	}
+	return ExplainHandler(flaggerlisters.NewCanaryLister(indexer), fakeExplainer{})
+}
+
+func TestExplainHandler(t *testing.T) {
+	handler := newTestExplainHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/explain?name=podinfo&namespace=test", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Got status %v wanted %v", rec.Code, http.StatusOK)
+	}
+
+	var res ExplainResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err.Error())
+	}
+	if res.Phase != string(flaggerv1.CanaryPhaseProgressing) || res.CanaryWeight != 10 {
+		t.Errorf("Got phase %v weight %v", res.Phase, res.CanaryWeight)
+	}
+	if len(res.Checks) != 2 || res.Gate == nil || res.Gate.Reason != "metrics" {
+		t.Errorf("Got checks %+v gate %+v", res.Checks, res.Gate)
+	}
+}
+
+func TestExplainHandler_Text(t *testing.T) {
+	handler := newTestExplainHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/explain?name=podinfo&namespace=test&format=text", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Got status %v wanted %v", rec.Code, http.StatusOK)
+	}
+	for _, want := range []string{
+		"Canary podinfo.test is Progressing at weight 10",
+		"Failed checks: 1/5",
+		"request-success-rate failed: 95",
+		"Blocked by metrics: metric checks failed",
+		"Next action: retry the analysis",
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("Got %q wanted it to contain %q", rec.Body.String(), want)
+		}
+	}
+}
+
+func TestExplainHandler_NotFound(t *testing.T) {
+	handler := newTestExplainHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/explain?name=missing&namespace=test", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Got status %v wanted %v", rec.Code, http.StatusNotFound)
+	}
+}
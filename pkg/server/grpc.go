@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"net"
+	"sort"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+
+	apiv1 "github.com/weaveworks/flagger/pkg/api/v1"
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	flaggerlisters "github.com/weaveworks/flagger/pkg/client/listers/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/controller"
+)
+
+// MetricResultsGetter returns the last metric results of a canary
+type MetricResultsGetter interface {
+	GetMetricResults(name string, namespace string) []controller.MetricResult
+}
+
+// CanariesServer implements the read-only canaries gRPC API,
+// the canaries are served from the informer cache
+type CanariesServer struct {
+	lister  flaggerlisters.CanaryLister
+	results MetricResultsGetter
+}
+
+// NewCanariesServer returns a gRPC canaries server backed by the given lister
+func NewCanariesServer(lister flaggerlisters.CanaryLister, results MetricResultsGetter) *CanariesServer {
+	return &CanariesServer{
+		lister:  lister,
+		results: results,
+	}
+}
+
+// List returns the canaries from the given namespace or from all namespaces
+func (s *CanariesServer) List(ctx context.Context, req *apiv1.ListRequest) (*apiv1.ListResponse, error) {
+	var canaries []*flaggerv1.Canary
+	var err error
+	if req.Namespace != "" {
+		canaries, err = s.lister.Canaries(req.Namespace).List(labels.Everything())
+	} else {
+		canaries, err = s.lister.List(labels.Everything())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "listing canaries failed: %v", err)
+	}
+
+	sort.Slice(canaries, func(i, j int) bool {
+		if canaries[i].Namespace != canaries[j].Namespace {
+			return canaries[i].Namespace < canaries[j].Namespace
+		}
+		return canaries[i].Name < canaries[j].Name
+	})
+
+	res := &apiv1.ListResponse{}
+	for _, cd := range canaries {
+		res.Canaries = append(res.Canaries, s.toCanary(cd))
+	}
+	return res, nil
+}
+
+// Get returns the canary with the given name and namespace
+func (s *CanariesServer) Get(ctx context.Context, req *apiv1.GetRequest) (*apiv1.Canary, error) {
+	if req.Name == "" || req.Namespace == "" {
+		return nil, status.Error(codes.InvalidArgument, "name and namespace are required")
+	}
+
+	cd, err := s.lister.Canaries(req.Namespace).Get(req.Name)
+	if errors.IsNotFound(err) {
+		return nil, status.Errorf(codes.NotFound, "canary %s.%s not found", req.Name, req.Namespace)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "canary %s.%s query error: %v", req.Name, req.Namespace, err)
+	}
+
+	return s.toCanary(cd), nil
+}
+
+func (s *CanariesServer) toCanary(cd *flaggerv1.Canary) *apiv1.Canary {
+	res := &apiv1.Canary{
+		Name:               cd.Name,
+		Namespace:          cd.Namespace,
+		TargetKind:         cd.Spec.TargetRef.Kind,
+		TargetName:         cd.Spec.TargetRef.Name,
+		Phase:              string(cd.Status.Phase),
+		CanaryWeight:       int32(cd.Status.CanaryWeight),
+		FailedChecks:       int32(cd.Status.FailedChecks),
+		Iterations:         int32(cd.Status.Iterations),
+		LastTransitionTime: cd.Status.LastTransitionTime.Unix(),
+	}
+
+	if s.results != nil {
+		results := s.results.GetMetricResults(cd.Name, cd.Namespace)
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].Name < results[j].Name
+		})
+		for _, r := range results {
+			res.Metrics = append(res.Metrics, &apiv1.MetricResult{
+				Name:      r.Name,
+				Value:     r.Value,
+				Passed:    r.Passed,
+				Error:     r.Error,
+				Timestamp: r.Timestamp.Unix(),
+			})
+		}
+	}
+
+	return res
+}
+
+// ListenAndServeGRPC starts the canaries gRPC API and waits for SIGTERM
+func ListenAndServeGRPC(port string, canaries *CanariesServer, logger *zap.SugaredLogger, stopCh <-chan struct{}) {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		logger.Fatalf("gRPC server failed to listen on port %s %v", port, err)
+	}
+
+	srv := grpc.NewServer()
+	apiv1.RegisterCanariesServer(srv, canaries)
+
+	logger.Infof("Starting gRPC server on port %s", port)
+
+	// run server in background
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			logger.Fatalf("gRPC server crashed %v", err)
+		}
+	}()
+
+	// wait for SIGTERM or SIGINT
+	<-stopCh
+	srv.GracefulStop()
+	logger.Info("gRPC server stopped")
+}
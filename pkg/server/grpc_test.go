@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	apiv1 "github.com/weaveworks/flagger/pkg/api/v1"
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	flaggerlisters "github.com/weaveworks/flagger/pkg/client/listers/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/controller"
+)
+
+type fakeResults map[string][]controller.MetricResult
+
+func (f fakeResults) GetMetricResults(name string, namespace string) []controller.MetricResult {
+	return f[name+"."+namespace]
+}
+
+func newTestCanariesServer(t *testing.T) *CanariesServer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, cd := range []*flaggerv1.Canary{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "test"},
+			Spec: flaggerv1.CanarySpec{
+				TargetRef: flaggerv1.CrossNamespaceObjectReference{Kind: "Deployment", Name: "podinfo"},
+			},
+			Status: flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, CanaryWeight: 20},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "prod"},
+			Spec: flaggerv1.CanarySpec{
+				TargetRef: flaggerv1.CrossNamespaceObjectReference{Kind: "Deployment", Name: "backend"},
+			},
+			Status: flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseSucceeded},
+		},
+	} {
+		if err := indexer.Add(cd); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	results := fakeResults{
+		"podinfo.test": {
+			{Name: "request-success-rate", Value: 99.5, Passed: true, Timestamp: time.Now()},
+		},
+	}
+
+	return NewCanariesServer(flaggerlisters.NewCanaryLister(indexer), results)
+}
+
+func TestCanariesServer_List(t *testing.T) {
+	srv := newTestCanariesServer(t)
+
+	res, err := srv.List(context.Background(), &apiv1.ListRequest{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(res.Canaries) != 2 {
+		t.Fatalf("Got %v canaries wanted %v", len(res.Canaries), 2)
+	}
+
+	if res.Canaries[0].Name != "backend" {
+		t.Errorf("Got first canary %s wanted %s", res.Canaries[0].Name, "backend")
+	}
+
+	res, err = srv.List(context.Background(), &apiv1.ListRequest{Namespace: "test"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(res.Canaries) != 1 {
+		t.Fatalf("Got %v canaries wanted %v", len(res.Canaries), 1)
+	}
+}
+
+func TestCanariesServer_Get(t *testing.T) {
+	srv := newTestCanariesServer(t)
+
+	cd, err := srv.Get(context.Background(), &apiv1.GetRequest{Name: "podinfo", Namespace: "test"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if cd.Phase != string(flaggerv1.CanaryPhaseProgressing) {
+		t.Errorf("Got phase %s wanted %s", cd.Phase, flaggerv1.CanaryPhaseProgressing)
+	}
+
+	if cd.CanaryWeight != 20 {
+		t.Errorf("Got weight %v wanted %v", cd.CanaryWeight, 20)
+	}
+
+	if len(cd.Metrics) != 1 || cd.Metrics[0].Value != 99.5 {
+		t.Errorf("Got metrics %v wanted %s value %v", cd.Metrics, "request-success-rate", 99.5)
+	}
+
+	_, err = srv.Get(context.Background(), &apiv1.GetRequest{Name: "missing", Namespace: "test"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Got error code %v wanted %v", status.Code(err), codes.NotFound)
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	flaggerlisters "github.com/weaveworks/flagger/pkg/client/listers/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/controller"
+)
+
+// TimeSeriesGetter returns the metric samples collected during the current analysis of a canary
+type TimeSeriesGetter interface {
+	GetTimeSeries(name string, namespace string) []controller.TimeSeries
+}
+
+// TimeSeriesResponse is the JSON document returned by the time series endpoint
+type TimeSeriesResponse struct {
+	Name         string             `json:"name"`
+	Namespace    string             `json:"namespace"`
+	Phase        string             `json:"phase"`
+	CanaryWeight int                `json:"canaryWeight"`
+	Metrics      []TimeSeriesMetric `json:"metrics"`
+}
+
+// TimeSeriesMetric holds the canary and primary values of a metric
+type TimeSeriesMetric struct {
+	Name    string             `json:"name"`
+	Samples []TimeSeriesSample `json:"samples"`
+}
+
+// TimeSeriesSample is a metric value measured at an analysis run,
+// the primary value is omitted if the provider can't measure the primary workload
+type TimeSeriesSample struct {
+	Timestamp    time.Time `json:"timestamp"`
+	CanaryWeight int       `json:"canaryWeight"`
+	Canary       float64   `json:"canary"`
+	Primary      *float64  `json:"primary,omitempty"`
+}
+
+// TimeSeriesHandler serves the canary and primary time series of the current analysis as JSON
+// on GET /timeseries?name=<name>&namespace=<namespace>
+func TimeSeriesHandler(lister flaggerlisters.CanaryLister, series TimeSeriesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name, namespace := r.URL.Query().Get("name"), r.URL.Query().Get("namespace")
+		if name == "" || namespace == "" {
+			http.Error(w, "name and namespace are required", http.StatusBadRequest)
+			return
+		}
+
+		cd, err := lister.Canaries(namespace).Get(name)
+		if errors.IsNotFound(err) {
+			http.Error(w, fmt.Sprintf("canary %s.%s not found", name, namespace), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("canary %s.%s query error: %v", name, namespace, err), http.StatusInternalServerError)
+			return
+		}
+
+		res := TimeSeriesResponse{
+			Name:         cd.Name,
+			Namespace:    cd.Namespace,
+			Phase:        string(cd.Status.Phase),
+			CanaryWeight: cd.Status.CanaryWeight,
+			Metrics:      []TimeSeriesMetric{},
+		}
+		for _, ts := range series.GetTimeSeries(cd.Name, cd.Namespace) {
+			metric := TimeSeriesMetric{Name: ts.Metric}
+			for _, s := range ts.Samples {
+				metric.Samples = append(metric.Samples, TimeSeriesSample{
+					Timestamp:    s.Timestamp,
+					CanaryWeight: s.CanaryWeight,
+					Canary:       s.Canary,
+					Primary:      s.Primary,
+				})
+			}
+			res.Metrics = append(res.Metrics, metric)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
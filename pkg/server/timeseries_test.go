@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	flaggerlisters "github.com/weaveworks/flagger/pkg/client/listers/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/controller"
+)
+
+type fakeTimeSeries map[string][]controller.TimeSeries
+
+func (f fakeTimeSeries) GetTimeSeries(name string, namespace string) []controller.TimeSeries {
+	return f[name+"."+namespace]
+}
+
+func newTestTimeSeriesHandler(t *testing.T) http.HandlerFunc {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "test"},
+		Status:     flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, CanaryWeight: 20},
+	}
+	if err := indexer.Add(cd); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	primary := 99.9
+	series := fakeTimeSeries{
+		"podinfo.test": {
+			{
+				Metric: "request-success-rate",
+				Samples: []controller.TimeSeriesSample{
+					{Timestamp: time.Now(), CanaryWeight: 10, Canary: 99.5, Primary: &primary},
+					{Timestamp: time.Now(), CanaryWeight: 20, Canary: 98},
+				},
+			},
+		},
+	}
+
+	return TimeSeriesHandler(flaggerlisters.NewCanaryLister(indexer), series)
+}
+
+func TestTimeSeriesHandler(t *testing.T) {
+	handler := newTestTimeSeriesHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/timeseries?name=podinfo&namespace=test", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Got status %v wanted %v", rec.Code, http.StatusOK)
+	}
+
+	var res TimeSeriesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err.Error())
+	}
+	if res.Phase != string(flaggerv1.CanaryPhaseProgressing) || res.CanaryWeight != 20 {
+		t.Errorf("Got phase %v weight %v", res.Phase, res.CanaryWeight)
+	}
+	if len(res.Metrics) != 1 || len(res.Metrics[0].Samples) != 2 {
+		t.Fatalf("Got metrics %+v", res.Metrics)
+	}
+
+	samples := res.Metrics[0].Samples
+	if samples[0].Primary == nil || *samples[0].Primary != 99.9 {
+		t.Errorf("Got primary %v wanted %v", samples[0].Primary, 99.9)
+	}
+	if samples[1].Primary != nil || samples[1].Canary != 98 {
+		t.Errorf("Got sample %+v", samples[1])
+	}
+}
+
+func TestTimeSeriesHandler_Errors(t *testing.T) {
+	handler := newTestTimeSeriesHandler(t)
+
+	tests := map[string]int{
+		"/timeseries?name=podinfo":                http.StatusBadRequest,
+		"/timeseries?name=backend&namespace=test": http.StatusNotFound,
+		"/timeseries?name=podinfo&namespace=prod": http.StatusNotFound,
+	}
+	for target, code := range tests {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != code {
+			t.Errorf("%s got status %v wanted %v", target, rec.Code, code)
+		}
+	}
+}